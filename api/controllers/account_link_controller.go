@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountLinkController struct {
+	usecase       usecases.IAccountLinkUsecase
+	refreshTokens usecases.IRefreshTokenUsecase
+}
+
+func NewAccountLinkController(usecase usecases.IAccountLinkUsecase, refreshTokens usecases.IRefreshTokenUsecase) *AccountLinkController {
+	return &AccountLinkController{usecase: usecase, refreshTokens: refreshTokens}
+}
+
+// LinkAccountDTO は外部アカウント（provider・code・state・flow_token）か、メールアドレスとパスワードのどちらかを指定する
+type LinkAccountDTO struct {
+	Provider  string `json:"provider"`
+	Code      string `json:"code"`
+	State     string `json:"state"`
+	FlowToken string `json:"flow_token"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+}
+
+type MergeAccountDTO struct {
+	MergeToken     string `json:"merge_token" binding:"required"`
+	ConflictPolicy string `json:"conflict_policy"`
+	// MFACode は統合先で二段階認証が有効な場合の認証アプリのコード、またはリカバリーコード
+	MFACode string `json:"mfa_code"`
+}
+
+type MergeAccountResponseDTO struct {
+	Token             string `json:"token,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	ID                string `json:"id"`
+	MovedDiaries      int    `json:"moved_diaries"`
+	ConflictedDiaries int    `json:"conflicted_diaries"`
+}
+
+func accountLinkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrNotGuest), errors.Is(err, user.ErrMergeGuestNotFound):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, auth.ErrInvalidMergeTicket):
+		return http.StatusBadRequest
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrMergeMFARequired):
+		return http.StatusForbidden
+	case errors.Is(err, auth.ErrInvalidTOTPCode):
+		return http.StatusBadRequest
+	case errors.Is(err, auth.ErrTOTPLocked):
+		return http.StatusTooManyRequests
+	case errors.Is(err, user.ErrInvalidEmail), errors.Is(err, auth.ErrInvalidPassword):
+		return http.StatusBadRequest
+	default:
+		return oidcErrorStatus(err)
+	}
+}
+
+// POST /me/link: ゲストユーザーに外部アカウントまたはメールアドレス・パスワードを連携するAPI（既に別ユーザーに連携済みの場合は統合用のトークンを返す）
+func (c *AccountLinkController) Link(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	var req LinkAccountDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	var result *usecases.LinkResult
+	var err error
+	switch {
+	case req.Email != "" && req.Password != "":
+		result, err = c.usecase.LinkPassword(ctx.Request.Context(), userIDStr, req.Email, req.Password)
+	case req.Provider != "" && req.Code != "" && req.State != "" && req.FlowToken != "":
+		result, err = c.usecase.LinkOIDC(ctx.Request.Context(), userIDStr, req.Provider, req.Code, req.State, req.FlowToken)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(accountLinkErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if result.NeedsMerge() {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":        "このアカウントは既に別のユーザーに連携されています。統合する場合はmerge_tokenを使って統合してください",
+			"merge_token":  result.MergeTicket,
+			"mfa_required": result.MFARequired,
+		})
+		return
+	}
+	// 連携前のアクセストークンはguestクレームがtrueのままのため、同じセッションで発行し直す
+	token, err := generateTokenForTest(result.User, ctx.GetString("sessionID"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"id":       result.User.ID,
+		"nickname": result.User.Nickname,
+		"is_guest": result.User.IsGuest,
+		"provider": result.User.Provider,
+		"email":    result.User.Email,
+		"token":    token,
+	}})
+}
+
+// POST /me/link/merge: ゲストの日記等を連携済みの既存ユーザーへ統合し、ゲストを削除するAPI
+func (c *AccountLinkController) Merge(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	var req MergeAccountDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	policy, err := diary.ParseConflictPolicy(req.ConflictPolicy)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	target, result, err := c.usecase.Merge(ctx.Request.Context(), userIDStr, req.MergeToken, req.MFACode, policy)
+	if err != nil {
+		status := accountLinkErrorStatus(err)
+		if status == http.StatusBadGateway {
+			status = http.StatusInternalServerError
+		}
+		ctx.JSON(status, gin.H{"error": "アカウントの統合に失敗しました: " + err.Error()})
+		return
+	}
+	// 統合後はゲストが削除されているため、統合先ユーザーとしてトークンを発行し直す
+	// （統合先で二段階認証が有効な場合は、統合の前に認証コードを確認済み）
+	tokens, err := issueLoginTokens(ctx, c.refreshTokens, target)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": MergeAccountResponseDTO{
+		Token:             tokens.Token,
+		RefreshToken:      tokens.RefreshToken,
+		ID:                tokens.ID,
+		MovedDiaries:      result.MovedDiaries,
+		ConflictedDiaries: result.ConflictedDiaries,
+	}})
+}
@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"tofunote-backend/domain/admin"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminController struct {
+	usecase usecases.IAdminUsecase
+}
+
+func NewAdminController(usecase usecases.IAdminUsecase) *AdminController {
+	return &AdminController{usecase: usecase}
+}
+
+// AdminUserDTO は管理画面のユーザー一覧の1件（日記の本文は含めない）
+type AdminUserDTO struct {
+	ID            string    `json:"id"`
+	Nickname      string    `json:"nickname"`
+	Email         string    `json:"email,omitempty"`
+	Provider      string    `json:"provider,omitempty"`
+	IsGuest       bool      `json:"is_guest"`
+	Roles         []string  `json:"roles"`
+	DiaryCount    int64     `json:"diary_count"`
+	LastDiaryDate string    `json:"last_diary_date,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AdminUserStatsDTO struct {
+	Total      int64            `json:"total"`
+	Guests     int64            `json:"guests"`
+	Members    int64            `json:"members"`
+	ByProvider map[string]int64 `json:"by_provider"`
+	NewSince   int64            `json:"new_since"`
+}
+
+type AdminDiaryStatsDTO struct {
+	Total            int64            `json:"total"`
+	NewSince         int64            `json:"new_since"`
+	ActiveWriters    int64            `json:"active_writers"`
+	MentalScoreUsers map[string]int64 `json:"mental_score_users"`
+}
+
+type AdminStatsDTO struct {
+	Since      time.Time          `json:"since"`
+	Users      AdminUserStatsDTO  `json:"users"`
+	Diaries    AdminDiaryStatsDTO `json:"diaries"`
+	Suppressed bool               `json:"suppressed"`
+}
+
+type AdminActionLogDTO struct {
+	ID           string    `json:"id"`
+	AdminUserID  string    `json:"admin_user_id"`
+	Action       string    `json:"action"`
+	TargetUserID string    `json:"target_user_id,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type AdminReasonDTO struct {
+	// Reason は監査ログに記録する操作の理由（10文字以上）
+	Reason string `json:"reason" binding:"required"`
+}
+
+func adminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrAdminReasonRequired), errors.Is(err, user.ErrInvalidRole):
+		return http.StatusBadRequest
+	case errors.Is(err, usecases.ErrCannotDeleteSelf), errors.Is(err, usecases.ErrCannotRevokeOwnRole):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parsePaging はクエリパラメータのlimit・offsetを読み取る（省略時は0）
+func parsePaging(ctx *gin.Context) (int, int, error) {
+	var limit, offset int
+	var err error
+	if v := ctx.Query("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			return 0, 0, errors.New("limitは0以上の整数で指定してください")
+		}
+	}
+	if v := ctx.Query("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, errors.New("offsetは0以上の整数で指定してください")
+		}
+	}
+	return limit, offset, nil
+}
+
+// GET /admin/users: ユーザーの検索API（q: メールアドレス・ニックネームの部分一致またはID、guest、role）
+func (c *AdminController) SearchUsers(ctx *gin.Context) {
+	limit, offset, err := parsePaging(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := user.SearchQuery{Keyword: ctx.Query("q"), Limit: limit, Offset: offset}
+	if v := ctx.Query("guest"); v != "" {
+		guest, err := strconv.ParseBool(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "guestはtrueまたはfalseで指定してください"})
+			return
+		}
+		query.Guest = &guest
+	}
+	if v := ctx.Query("role"); v != "" {
+		role, err := user.ParseRole(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query.Role = role
+	}
+	users, total, err := c.usecase.SearchUsers(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーの検索に失敗しました"})
+		return
+	}
+	res := make([]AdminUserDTO, len(users))
+	for i, u := range users {
+		roles := u.RoleNames()
+		if roles == nil {
+			roles = []string{}
+		}
+		res[i] = AdminUserDTO{
+			ID:            u.ID,
+			Nickname:      u.Nickname,
+			Email:         u.Email,
+			Provider:      u.Provider,
+			IsGuest:       u.IsGuest,
+			Roles:         roles,
+			DiaryCount:    u.DiaryCount,
+			LastDiaryDate: u.LastDiaryDate,
+			CreatedAt:     u.CreatedAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res, "total": total})
+}
+
+// GET /admin/stats: ユーザー数・日記数などの集計API（人数が少ない項目は非表示にする）
+func (c *AdminController) Stats(ctx *gin.Context) {
+	stats, err := c.usecase.Stats(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "集計に失敗しました"})
+		return
+	}
+	mental := make(map[string]int64, len(stats.Diary.MentalScoreUsers))
+	for score, users := range stats.Diary.MentalScoreUsers {
+		mental[strconv.Itoa(score)] = users
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": AdminStatsDTO{
+		Since: stats.Since,
+		Users: AdminUserStatsDTO{
+			Total:      stats.Users.Total,
+			Guests:     stats.Users.Guests,
+			Members:    stats.Users.Members,
+			ByProvider: stats.Users.ByProvider,
+			NewSince:   stats.Users.NewSince,
+		},
+		Diaries: AdminDiaryStatsDTO{
+			Total:            stats.Diary.Total,
+			NewSince:         stats.Diary.NewSince,
+			ActiveWriters:    stats.Diary.ActiveWriters,
+			MentalScoreUsers: mental,
+		},
+		Suppressed: stats.Suppressed,
+	}})
+}
+
+// DELETE /admin/users/:id: 規約違反などのアカウントを日記を含めて削除するAPI（理由は監査ログに記録する）
+func (c *AdminController) DeleteUser(ctx *gin.Context) {
+	var req AdminReasonDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	if err := c.usecase.ForceDeleteUser(ctx.Request.Context(), ctx.GetString("userID"), ctx.Param("id"), req.Reason); err != nil {
+		ctx.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ユーザーを削除しました"})
+}
+
+// PUT /admin/users/:id/roles/:role: ロールの付与API
+func (c *AdminController) GrantRole(ctx *gin.Context) {
+	role, err := user.ParseRole(ctx.Param("role"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.usecase.GrantRole(ctx.Request.Context(), ctx.GetString("userID"), ctx.Param("id"), role); err != nil {
+		ctx.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ロールを付与しました"})
+}
+
+// DELETE /admin/users/:id/roles/:role: ロールの取り消しAPI
+func (c *AdminController) RevokeRole(ctx *gin.Context) {
+	role, err := user.ParseRole(ctx.Param("role"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.usecase.RevokeRole(ctx.Request.Context(), ctx.GetString("userID"), ctx.Param("id"), role); err != nil {
+		ctx.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ロールを取り消しました"})
+}
+
+// POST /admin/users/:id/diaries/break-glass: 理由を監査ログに記録してユーザーの日記の本文を閲覧する緊急時のAPI
+func (c *AdminController) BreakGlassDiaries(ctx *gin.Context) {
+	var req AdminReasonDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	diaries, err := c.usecase.BreakGlassDiaries(ctx.Request.Context(), ctx.GetString("userID"), ctx.Param("id"), req.Reason)
+	if err != nil {
+		ctx.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	responseDTOs := make([]DiaryResponseDTO, 0, len(diaries))
+	for _, d := range diaries {
+		responseDTOs = append(responseDTOs, ToResponseDTO(&d))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+// POST /admin/analysis: 全ユーザーの日記を横断した分析API（結果に本文やユーザーIDは含めない）
+func (c *AdminController) RunGlobalAnalysis(ctx *gin.Context) {
+	result, err := c.usecase.RunGlobalAnalysis(ctx.Request.Context(), ctx.GetString("userID"))
+	if err != nil {
+		if errors.Is(err, usecases.ErrNoAnalyzableDiaries) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"analysis_result": result})
+}
+
+// GET /admin/action-logs: 管理者の操作の監査ログ取得API（target_user_idで絞り込める）
+func (c *AdminController) ListActionLogs(ctx *gin.Context) {
+	limit, offset, err := parsePaging(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logs, err := c.usecase.ListActionLogs(ctx.Request.Context(), ctx.Query("target_user_id"), limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "監査ログの取得に失敗しました"})
+		return
+	}
+	res := make([]AdminActionLogDTO, len(logs))
+	for i, l := range logs {
+		res[i] = toAdminActionLogDTO(l)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+func toAdminActionLogDTO(l *admin.ActionLog) AdminActionLogDTO {
+	return AdminActionLogDTO{
+		ID:           l.ID,
+		AdminUserID:  l.AdminUserID,
+		Action:       string(l.Action),
+		TargetUserID: l.TargetUserID,
+		Reason:       l.Reason,
+		CreatedAt:    l.CreatedAt,
+	}
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	usecase usecases.IAuditUsecase
+}
+
+func NewAuditController(usecase usecases.IAuditUsecase) *AuditController {
+	return &AuditController{usecase: usecase}
+}
+
+type AuditEventDTO struct {
+	ID           string    `json:"id"`
+	ActorUserID  string    `json:"actor_user_id,omitempty"`
+	Action       string    `json:"action"`
+	TargetUserID string    `json:"target_user_id,omitempty"`
+	Detail       string    `json:"detail,omitempty"`
+	IPAddress    string    `json:"ip_address,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	RequestID    string    `json:"request_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GET /me/audit-log: 自分が操作した、または自分が対象となったセキュリティに関わる操作の履歴取得API
+func (c *AuditController) FindMine(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	limit, offset, err := parsePaging(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	events, err := c.usecase.ListForUser(ctx.Request.Context(), userIDStr, limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "監査ログの取得に失敗しました"})
+		return
+	}
+	res := make([]AuditEventDTO, len(events))
+	for i, e := range events {
+		res[i] = toAuditEventDTO(e)
+		// 管理者など他のユーザーによる操作の場合は、操作した人の接続元を返さない
+		if e.ActorUserID != userIDStr {
+			res[i].ActorUserID = ""
+			res[i].IPAddress = ""
+			res[i].UserAgent = ""
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// GET /admin/audit-log: 全ユーザーの監査ログの検索API（user_id, action, since, untilで絞り込める）
+func (c *AuditController) Search(ctx *gin.Context) {
+	limit, offset, err := parsePaging(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := audit.Query{
+		UserID: ctx.Query("user_id"),
+		Action: audit.Action(ctx.Query("action")),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if v := ctx.Query("since"); v != "" {
+		if query.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "sinceはRFC 3339形式の日時で指定してください"})
+			return
+		}
+	}
+	if v := ctx.Query("until"); v != "" {
+		if query.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "untilはRFC 3339形式の日時で指定してください"})
+			return
+		}
+	}
+	events, err := c.usecase.Search(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "監査ログの取得に失敗しました"})
+		return
+	}
+	res := make([]AuditEventDTO, len(events))
+	for i, e := range events {
+		res[i] = toAuditEventDTO(e)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+func toAuditEventDTO(e *audit.Event) AuditEventDTO {
+	return AuditEventDTO{
+		ID:           e.ID,
+		ActorUserID:  e.ActorUserID,
+		Action:       string(e.Action),
+		TargetUserID: e.TargetUserID,
+		Detail:       e.Detail,
+		IPAddress:    e.IPAddress,
+		UserAgent:    e.UserAgent,
+		RequestID:    e.RequestID,
+		CreatedAt:    e.CreatedAt,
+	}
+}
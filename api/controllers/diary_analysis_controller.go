@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"tofunote-backend/usecases"
@@ -21,10 +22,22 @@ func NewDiaryAnalysisController(usecase *usecases.DiaryAnalysisUsecase) *DiaryAn
 
 // AnalyzeAllDiariesHandler は認証されたユーザーの日記を分析するエンドポイント
 func (c *DiaryAnalysisController) AnalyzeAllDiariesHandler(ctx *gin.Context) {
-	// ハードコードでuser_id=1を使用
-	userID := "1"
-	result, err := c.DiaryAnalysisUsecase.AnalyzeUserDiaries(ctx.Request.Context(), userID)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	result, err := c.DiaryAnalysisUsecase.AnalyzeUserDiaries(ctx.Request.Context(), userIDStr)
 	if err != nil {
+		if errors.Is(err, usecases.ErrAnalysisConsentRequired) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
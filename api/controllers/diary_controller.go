@@ -1,9 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
-	"time"
 	"tofunote-backend/domain/diary"
 	"tofunote-backend/usecases"
 
@@ -30,11 +31,17 @@ func (c *DiaryController) FindAll(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
 		return
 	}
+	filter, err := parseDiaryFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	diaries, err := c.usecase.FindByUserID(ctx.Request.Context(), userIDStr)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	diaries = filter.Apply(diaries)
 
 	responseDTOs := make([]DiaryResponseDTO, 0, len(diaries))
 	for _, d := range diaries {
@@ -56,9 +63,13 @@ func (c *DiaryController) FindByUserIDAndDate(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
 		return
 	}
-	date := ctx.Param("date")
+	date, err := diary.NewDate(ctx.Param("date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	diary, err := c.usecase.FindByUserIDAndDate(ctx.Request.Context(), userIDStr, date)
+	found, err := c.usecase.FindByUserIDAndDate(ctx.Request.Context(), userIDStr, date)
 	if err != nil {
 		if strings.Contains(err.Error(), "指定された日付の日記が見つかりません") {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -68,7 +79,7 @@ func (c *DiaryController) FindByUserIDAndDate(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": ToResponseDTO(diary)})
+	ctx.JSON(http.StatusOK, gin.H{"data": ToResponseDTO(found)})
 }
 
 func (c *DiaryController) FindByUserIDAndDateRange(ctx *gin.Context) {
@@ -83,20 +94,29 @@ func (c *DiaryController) FindByUserIDAndDateRange(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
 		return
 	}
-	startDate := ctx.Query("start_date")
-	endDate := ctx.Query("end_date")
-
 	// クエリパラメータのバリデーション
-	if startDate == "" || endDate == "" {
+	if ctx.Query("start_date") == "" || ctx.Query("end_date") == "" {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_dateとend_dateの両方が必要です"})
 		return
 	}
+	startDate, endDate, err := diary.NewDateRange(ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := parseDiaryFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	diaries, err := c.usecase.FindByUserIDAndDateRange(ctx.Request.Context(), userIDStr, startDate, endDate)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	diaries = filter.Apply(diaries)
 
 	responseDTOs := make([]DiaryResponseDTO, 0, len(diaries))
 	for _, d := range diaries {
@@ -106,38 +126,85 @@ func (c *DiaryController) FindByUserIDAndDateRange(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
 }
 
+type EmotionDTO struct {
+	Key       string `json:"key"`
+	Intensity int    `json:"intensity,omitempty"`
+}
+
 type CreateDiaryDTO struct {
-	Date   string `json:"date"`
-	Mental int    `json:"mental"`
-	Diary  string `json:"diary"`
+	// Date を省略した場合はユーザーが設定したタイムゾーンでの今日の日記にする
+	Date     string       `json:"date"`
+	Mental   int          `json:"mental"`
+	Diary    string       `json:"diary"`
+	Emotions []EmotionDTO `json:"emotions,omitempty"`
 }
 
 type UpdateDiaryDTO struct {
 	Mental int    `json:"mental"`
 	Diary  string `json:"diary"`
+	// Emotions を省略した場合は既存の感情ラベルを維持し、空配列の場合は全て外す
+	Emotions []EmotionDTO `json:"emotions,omitempty"`
 }
 
 type DiaryResponseDTO struct {
-	ID     string `json:"id"`
-	UserID string `json:"user_id"`
-	Date   string `json:"date"`
-	Mental int    `json:"mental"`
-	Diary  string `json:"diary"`
+	ID       string       `json:"id"`
+	UserID   string       `json:"user_id"`
+	Date     string       `json:"date"`
+	Mental   int          `json:"mental"`
+	Diary    string       `json:"diary"`
+	Emotions []EmotionDTO `json:"emotions,omitempty"`
 }
 
 // ToResponseDTO converts domain Diary to response DTO
 func ToResponseDTO(diary *diary.Diary) DiaryResponseDTO {
-	date := diary.Date
-	if t, err := time.Parse(time.RFC3339, diary.Date); err == nil {
-		date = t.Format("2006-01-02")
+	var emotions []EmotionDTO
+	for _, e := range diary.Emotions {
+		emotions = append(emotions, EmotionDTO{Key: e.Key, Intensity: e.Intensity})
 	}
 	return DiaryResponseDTO{
-		ID:     diary.ID,
-		UserID: diary.UserID,
-		Date:   date,
-		Mental: int(diary.Mental),
-		Diary:  diary.Diary,
+		ID:       diary.ID,
+		UserID:   diary.UserID,
+		Date:     diary.Date.String(),
+		Mental:   int(diary.Mental),
+		Diary:    diary.Diary,
+		Emotions: emotions,
+	}
+}
+
+// toDomainEmotions はリクエストの感情ラベルを値オブジェクトに変換する（nilはnilのまま返す）
+func toDomainEmotions(dtos []EmotionDTO) ([]diary.Emotion, error) {
+	if dtos == nil {
+		return nil, nil
+	}
+	emotions := make([]diary.Emotion, 0, len(dtos))
+	for _, dto := range dtos {
+		e, err := diary.NewEmotion(dto.Key, dto.Intensity)
+		if err != nil {
+			return nil, err
+		}
+		emotions = append(emotions, e)
 	}
+	return emotions, nil
+}
+
+// parseDiaryFilter は一覧取得のクエリパラメータ（emotions, min_intensity）から絞り込み条件を生成する
+func parseDiaryFilter(ctx *gin.Context) (diary.Filter, error) {
+	var filter diary.Filter
+	if emotions := ctx.Query("emotions"); emotions != "" {
+		for _, key := range strings.Split(emotions, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				filter.Emotions = append(filter.Emotions, key)
+			}
+		}
+	}
+	if minIntensity := ctx.Query("min_intensity"); minIntensity != "" {
+		v, err := strconv.Atoi(minIntensity)
+		if err != nil || v < diary.EmotionIntensityMin || v > diary.EmotionIntensityMax {
+			return filter, errors.New("min_intensityは1〜5で指定してください")
+		}
+		filter.MinIntensity = v
+	}
+	return filter, nil
 }
 
 func (c *DiaryController) Create(ctx *gin.Context) {
@@ -151,6 +218,18 @@ func (c *DiaryController) Create(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	emotions, err := toDomainEmotions(req.Emotions)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var date diary.Date
+	if req.Date != "" {
+		if date, err = diary.NewDate(req.Date); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	// JWTトークンからuserIDを取得
 	userID, exists := ctx.Get("userID")
@@ -164,10 +243,11 @@ func (c *DiaryController) Create(ctx *gin.Context) {
 		return
 	}
 	newDiary := diary.Diary{
-		UserID: userIDStr,
-		Date:   req.Date,
-		Mental: mental,
-		Diary:  req.Diary,
+		UserID:   userIDStr,
+		Date:     date,
+		Mental:   mental,
+		Diary:    req.Diary,
+		Emotions: emotions,
 	}
 	err = c.usecase.Create(ctx.Request.Context(), &newDiary)
 	if err != nil {
@@ -176,6 +256,10 @@ func (c *DiaryController) Create(ctx *gin.Context) {
 			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
+		if strings.Contains(err.Error(), "感情ラベル") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -194,7 +278,11 @@ func (c *DiaryController) Update(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
 		return
 	}
-	date := ctx.Param("date")
+	date, err := diary.NewDate(ctx.Param("date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	var req UpdateDiaryDTO
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -207,12 +295,18 @@ func (c *DiaryController) Update(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	emotions, err := toDomainEmotions(req.Emotions)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	updateDiary := diary.Diary{
-		UserID: userIDStr,
-		Date:   date,
-		Mental: mental,
-		Diary:  req.Diary,
+		UserID:   userIDStr,
+		Date:     date,
+		Mental:   mental,
+		Diary:    req.Diary,
+		Emotions: emotions,
 	}
 
 	err = c.usecase.Update(ctx.Request.Context(), userIDStr, date, &updateDiary)
@@ -221,6 +315,10 @@ func (c *DiaryController) Update(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if strings.Contains(err.Error(), "感情ラベル") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -240,9 +338,13 @@ func (c *DiaryController) Delete(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
 		return
 	}
-	date := ctx.Param("date")
+	date, err := diary.NewDate(ctx.Param("date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	err := c.usecase.Delete(ctx.Request.Context(), userIDStr, date)
+	err = c.usecase.Delete(ctx.Request.Context(), userIDStr, date)
 	if err != nil {
 		if strings.Contains(err.Error(), "指定された日付の日記が見つかりません") {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -31,11 +31,11 @@ func (m *mockDiaryUsecase) FindByUserID(ctx context.Context, userID string) ([]d
 	return m.diaries, m.err
 }
 
-func (m *mockDiaryUsecase) FindByUserIDAndDate(ctx context.Context, userID string, date string) (*diary.Diary, error) {
+func (m *mockDiaryUsecase) FindByUserIDAndDate(ctx context.Context, userID string, date diary.Date) (*diary.Diary, error) {
 	return m.diary, m.err
 }
 
-func (m *mockDiaryUsecase) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]diary.Diary, error) {
+func (m *mockDiaryUsecase) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate diary.Date) ([]diary.Diary, error) {
 	return m.diaries, m.err
 }
 
@@ -43,11 +43,11 @@ func (m *mockDiaryUsecase) Create(ctx context.Context, diary *diary.Diary) error
 	return m.err
 }
 
-func (m *mockDiaryUsecase) Update(ctx context.Context, userID string, date string, diary *diary.Diary) error {
+func (m *mockDiaryUsecase) Update(ctx context.Context, userID string, date diary.Date, diary *diary.Diary) error {
 	return m.err
 }
 
-func (m *mockDiaryUsecase) Delete(ctx context.Context, userID string, date string) error {
+func (m *mockDiaryUsecase) Delete(ctx context.Context, userID string, date diary.Date) error {
 	return m.err
 }
 
@@ -55,6 +55,10 @@ func (m *mockDiaryUsecase) DeleteByUserID(ctx context.Context, userID string) er
 	return nil
 }
 
+func (m *mockDiaryUsecase) Today(ctx context.Context, userID string) (diary.Date, error) {
+	return "2025-01-03", nil
+}
+
 // testDiaries はテスト用のダイアリーデータを定義します
 var testDiaries = func() []diary.Diary {
 	m5, _ := diary.NewMental(5)
@@ -72,7 +76,7 @@ type responseBody struct {
 }
 
 func generateTestToken() string {
-	token, _ := infra.GenerateToken("1")
+	token, _ := infra.GenerateToken(infra.AccessTokenSubject{UserID: "1"})
 	return token
 }
 
@@ -208,6 +212,73 @@ func TestDiaryController_Create(t *testing.T) {
 			expectedData:   nil,
 			expectedError:  "mental value must be between 1 and 10",
 		},
+		{
+			name: "異常系：存在しない日付",
+			setupMock: func() *mockDiaryUsecase {
+				return &mockDiaryUsecase{
+					err: nil,
+				}
+			},
+			requestBody: CreateDiaryDTO{
+				Date:   "2025-02-30",
+				Mental: 5,
+				Diary:  "良い日だった",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedData:   nil,
+			expectedError:  diary.ErrInvalidDate.Error(),
+		},
+		{
+			name: "正常系：感情ラベル付きで日記を作成できる",
+			setupMock: func() *mockDiaryUsecase {
+				return &mockDiaryUsecase{
+					err: nil,
+				}
+			},
+			requestBody: CreateDiaryDTO{
+				Date:     "2025-01-01",
+				Mental:   3,
+				Diary:    "不安な一日",
+				Emotions: []EmotionDTO{{Key: "anxiety", Intensity: 4}},
+			},
+			expectedStatus: http.StatusCreated,
+			expectedData:   &DiaryResponseDTO{ID: "", UserID: "1", Date: "2025-01-01", Mental: 3, Diary: "不安な一日", Emotions: []EmotionDTO{{Key: "anxiety", Intensity: 4}}},
+			expectedError:  "",
+		},
+		{
+			name: "異常系：感情の強度が範囲外",
+			setupMock: func() *mockDiaryUsecase {
+				return &mockDiaryUsecase{
+					err: nil,
+				}
+			},
+			requestBody: CreateDiaryDTO{
+				Date:     "2025-01-01",
+				Mental:   3,
+				Diary:    "不安な一日",
+				Emotions: []EmotionDTO{{Key: "anxiety", Intensity: 9}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedData:   nil,
+			expectedError:  "感情の強度は1〜5で指定してください",
+		},
+		{
+			name: "異常系：未登録の感情ラベル",
+			setupMock: func() *mockDiaryUsecase {
+				return &mockDiaryUsecase{
+					err: errors.New("未登録の感情ラベルです: unknown"),
+				}
+			},
+			requestBody: CreateDiaryDTO{
+				Date:     "2025-01-01",
+				Mental:   3,
+				Diary:    "不安な一日",
+				Emotions: []EmotionDTO{{Key: "unknown"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedData:   nil,
+			expectedError:  "未登録の感情ラベルです: unknown",
+		},
 		{
 			name: "異常系：複合ユニークキー制約違反",
 			setupMock: func() *mockDiaryUsecase {
@@ -516,6 +587,16 @@ func TestDiaryController_FindByUserIDAndDate(t *testing.T) {
 			expectedData:   nil,
 			expectedError:  "指定された日付の日記が見つかりません",
 		},
+		{
+			name: "異常系：日付の形式が不正",
+			setupMock: func() *mockDiaryUsecase {
+				return &mockDiaryUsecase{}
+			},
+			date:           "2025-1-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedData:   nil,
+			expectedError:  diary.ErrInvalidDate.Error(),
+		},
 		{
 			name: "異常系：サービスがエラーを返した場合は500を返す",
 			setupMock: func() *mockDiaryUsecase {
@@ -622,6 +703,17 @@ func TestDiaryController_FindByUserIDAndDateRange(t *testing.T) {
 			expectedData:   nil,
 			expectedError:  "start_dateとend_dateの両方が必要です",
 		},
+		{
+			name: "異常系：start_dateがend_dateより後",
+			setupMock: func() *mockDiaryUsecase {
+				return &mockDiaryUsecase{}
+			},
+			startDate:      "2025-01-31",
+			endDate:        "2025-01-01",
+			expectedStatus: http.StatusBadRequest,
+			expectedData:   nil,
+			expectedError:  diary.ErrInvalidDateRange.Error(),
+		},
 		{
 			name: "異常系：サービスがエラーを返した場合は500を返す",
 			setupMock: func() *mockDiaryUsecase {
@@ -681,3 +773,61 @@ func TestDiaryController_FindByUserIDAndDateRange(t *testing.T) {
 		})
 	}
 }
+
+func TestDiaryController_FindAll_EmotionFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	token := generateTestToken()
+
+	m3, _ := diary.NewMental(3)
+	m7, _ := diary.NewMental(7)
+	diaries := []diary.Diary{
+		{ID: "1", UserID: "1", Date: "2025-01-01", Mental: m3, Diary: "不安", Emotions: []diary.Emotion{{Key: "anxiety", Intensity: 4}}},
+		{ID: "2", UserID: "1", Date: "2025-01-02", Mental: m7, Diary: "安心", Emotions: []diary.Emotion{{Key: "relief", Intensity: 2}}},
+		{ID: "3", UserID: "1", Date: "2025-01-03", Mental: m7, Diary: "なし"},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+		expectedError  string
+	}{
+		{name: "正常系：絞り込みなし", query: "", expectedStatus: http.StatusOK, expectedIDs: []string{"1", "2", "3"}},
+		{name: "正常系：感情ラベルで絞り込み", query: "?emotions=anxiety,relief", expectedStatus: http.StatusOK, expectedIDs: []string{"1", "2"}},
+		{name: "正常系：強度で絞り込み", query: "?emotions=anxiety,relief&min_intensity=3", expectedStatus: http.StatusOK, expectedIDs: []string{"1"}},
+		{name: "異常系：強度が不正", query: "?emotions=anxiety&min_intensity=abc", expectedStatus: http.StatusBadRequest, expectedError: "min_intensityは1〜5で指定してください"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewDiaryController(&mockDiaryUsecase{diaries: diaries})
+
+			router := gin.New()
+			router.Use(middleware.JWTAuthMiddleware())
+			router.GET("/api/me/diaries", controller.FindAll)
+
+			req, _ := http.NewRequest("GET", "/api/me/diaries"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response struct {
+				Data  []DiaryResponseDTO `json:"data"`
+				Error string             `json:"error"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response.Error)
+				return
+			}
+			var ids []string
+			for _, d := range response.Data {
+				ids = append(ids, d.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
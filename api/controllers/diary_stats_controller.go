@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DiaryStatsController struct {
+	statsUsecase   usecases.IDiaryStatsUsecase
+	emotionUsecase usecases.IEmotionUsecase
+}
+
+func NewDiaryStatsController(statsUsecase usecases.IDiaryStatsUsecase, emotionUsecase usecases.IEmotionUsecase) *DiaryStatsController {
+	return &DiaryStatsController{statsUsecase: statsUsecase, emotionUsecase: emotionUsecase}
+}
+
+type EmotionStatResponseDTO struct {
+	Key              string  `json:"key"`
+	Name             string  `json:"name"`
+	Category         string  `json:"category"`
+	Count            int     `json:"count"`
+	AverageIntensity float64 `json:"average_intensity"`
+	AverageMental    float64 `json:"average_mental"`
+}
+
+// GET /me/stats/emotions: 感情ラベルの出現頻度を集計するAPI
+func (c *DiaryStatsController) EmotionFrequencies(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	startDate := ctx.Query("start_date")
+	endDate := ctx.Query("end_date")
+	if (startDate == "") != (endDate == "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_dateとend_dateは両方指定してください"})
+		return
+	}
+	if startDate != "" {
+		if _, _, err := diary.NewDateRange(startDate, endDate); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	locale := diary.ParseLocale(ctx.Query("locale"))
+
+	stats, err := c.statsUsecase.EmotionFrequencies(ctx.Request.Context(), userIDStr, startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	taxonomy, err := c.emotionUsecase.GetTaxonomy(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responseDTOs := make([]EmotionStatResponseDTO, 0, len(stats))
+	for _, s := range stats {
+		dto := EmotionStatResponseDTO{
+			Key:              s.Key,
+			Name:             s.Key,
+			Count:            s.Count,
+			AverageIntensity: s.AverageIntensity,
+			AverageMental:    s.AverageMental,
+		}
+		// 削除済みのユーザー定義ラベルはキーのみ返す
+		if label, ok := taxonomy.Lookup(s.Key); ok {
+			dto.Name = label.Name(locale)
+			dto.Category = label.Category
+		}
+		responseDTOs = append(responseDTOs, dto)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+type FactorInsightResponseDTO struct {
+	Key         string   `json:"key"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Unit        string   `json:"unit,omitempty"`
+	SampleSize  int      `json:"sample_size"`
+	Average     float64  `json:"average"`
+	Correlation *float64 `json:"correlation"`
+	Condition   string   `json:"condition"`
+	MatchedDays int      `json:"matched_days"`
+	MoodDelta   *float64 `json:"mood_delta"`
+	Summary     string   `json:"summary,omitempty"`
+}
+
+// GET /me/stats/correlations: 生活因子とメンタルスコアの相関を分析するAPI
+func (c *DiaryStatsController) FactorCorrelations(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	startDate := ctx.Query("start_date")
+	endDate := ctx.Query("end_date")
+	if (startDate == "") != (endDate == "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_dateとend_dateは両方指定してください"})
+		return
+	}
+	if startDate != "" {
+		if _, _, err := diary.NewDateRange(startDate, endDate); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	insights, err := c.statsUsecase.FactorCorrelations(ctx.Request.Context(), userIDStr, startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responseDTOs := make([]FactorInsightResponseDTO, 0, len(insights))
+	for _, i := range insights {
+		responseDTOs = append(responseDTOs, FactorInsightResponseDTO{
+			Key:         i.Definition.Key,
+			Name:        i.Definition.Name,
+			Type:        string(i.Definition.Type),
+			Unit:        i.Definition.Unit,
+			SampleSize:  i.SampleSize,
+			Average:     i.Average,
+			Correlation: i.Correlation,
+			Condition:   i.Definition.FormatCondition(i.Condition),
+			MatchedDays: i.MatchedDays,
+			MoodDelta:   i.MoodDelta,
+			Summary:     i.Summary(),
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmotionController struct {
+	usecase usecases.IEmotionUsecase
+}
+
+func NewEmotionController(usecase usecases.IEmotionUsecase) *EmotionController {
+	return &EmotionController{usecase: usecase}
+}
+
+type CreateEmotionLabelDTO struct {
+	Key      string `json:"key" binding:"required"`
+	Category string `json:"category" binding:"required"`
+	NameJa   string `json:"name_ja"`
+	NameEn   string `json:"name_en"`
+}
+
+type EmotionLabelResponseDTO struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	NameJa   string `json:"name_ja"`
+	NameEn   string `json:"name_en"`
+	Category string `json:"category"`
+	Builtin  bool   `json:"builtin"`
+}
+
+// ToEmotionLabelResponseDTO converts domain EmotionLabel to response DTO
+func ToEmotionLabelResponseDTO(label diary.EmotionLabel, locale diary.Locale) EmotionLabelResponseDTO {
+	return EmotionLabelResponseDTO{
+		Key:      label.Key,
+		Name:     label.Name(locale),
+		NameJa:   label.NameJa,
+		NameEn:   label.NameEn,
+		Category: label.Category,
+		Builtin:  label.IsBuiltin(),
+	}
+}
+
+// GET /me/emotions: 組み込み・ユーザー定義の感情ラベル一覧取得API
+func (c *EmotionController) FindAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	locale := diary.ParseLocale(ctx.Query("locale"))
+
+	taxonomy, err := c.usecase.GetTaxonomy(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	labels := taxonomy.Labels()
+	responseDTOs := make([]EmotionLabelResponseDTO, 0, len(labels))
+	for _, l := range labels {
+		responseDTOs = append(responseDTOs, ToEmotionLabelResponseDTO(l, locale))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+// POST /me/emotions: ユーザー定義の感情ラベル追加API
+func (c *EmotionController) Create(ctx *gin.Context) {
+	var req CreateEmotionLabelDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	label, err := diary.NewCustomEmotionLabel(userIDStr, req.Key, req.Category, req.NameJa, req.NameEn)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.usecase.CreateLabel(ctx.Request.Context(), &label); err != nil {
+		if strings.Contains(err.Error(), "この感情ラベルは既に登録されています") {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": ToEmotionLabelResponseDTO(label, diary.ParseLocale(ctx.Query("locale")))})
+}
+
+// DELETE /me/emotions/:key: ユーザー定義の感情ラベル削除API
+func (c *EmotionController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	key := ctx.Param("key")
+
+	if err := c.usecase.DeleteLabel(ctx.Request.Context(), userIDStr, key); err != nil {
+		if strings.Contains(err.Error(), "指定された感情ラベルが見つかりません") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "感情ラベルを削除しました"}})
+}
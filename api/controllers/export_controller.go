@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	usecase usecases.IExportUsecase
+}
+
+func NewExportController(usecase usecases.IExportUsecase) *ExportController {
+	return &ExportController{usecase: usecase}
+}
+
+type ExportResponseDTO struct {
+	ExportedAt     time.Time                    `json:"exported_at"`
+	Diaries        []DiaryResponseDTO           `json:"diaries"`
+	EmotionLabels  []EmotionLabelResponseDTO    `json:"emotion_labels"`
+	Factors        []DailyFactorsResponseDTO    `json:"factors"`
+	Medications    []MedicationResponseDTO      `json:"medications"`
+	DoseLogs       []DoseLogResponseDTO         `json:"dose_logs"`
+	ThoughtRecords []ThoughtRecordResponseDTO   `json:"thought_records"`
+	Highlights     []DailyHighlightsResponseDTO `json:"highlights"`
+}
+
+// ToExportResponseDTO converts UserExport to response DTO
+func ToExportResponseDTO(e *usecases.UserExport) ExportResponseDTO {
+	dto := ExportResponseDTO{
+		ExportedAt:     e.ExportedAt,
+		Diaries:        make([]DiaryResponseDTO, 0, len(e.Diaries)),
+		EmotionLabels:  make([]EmotionLabelResponseDTO, 0, len(e.EmotionLabels)),
+		Factors:        make([]DailyFactorsResponseDTO, 0, len(e.Factors)),
+		Medications:    make([]MedicationResponseDTO, 0, len(e.Medications)),
+		DoseLogs:       make([]DoseLogResponseDTO, 0, len(e.DoseLogs)),
+		ThoughtRecords: make([]ThoughtRecordResponseDTO, 0, len(e.ThoughtRecords)),
+		Highlights:     make([]DailyHighlightsResponseDTO, 0, len(e.Highlights)),
+	}
+	for _, d := range e.Diaries {
+		dto.Diaries = append(dto.Diaries, ToResponseDTO(&d))
+	}
+	for _, l := range e.EmotionLabels {
+		dto.EmotionLabels = append(dto.EmotionLabels, ToEmotionLabelResponseDTO(l, diary.LocaleJa))
+	}
+	for _, f := range e.Factors {
+		dto.Factors = append(dto.Factors, ToDailyFactorsResponseDTO(&f))
+	}
+	for _, m := range e.Medications {
+		dto.Medications = append(dto.Medications, ToMedicationResponseDTO(&m))
+	}
+	for _, l := range e.DoseLogs {
+		dto.DoseLogs = append(dto.DoseLogs, ToDoseLogResponseDTO(&l))
+	}
+	for _, r := range e.ThoughtRecords {
+		dto.ThoughtRecords = append(dto.ThoughtRecords, ToThoughtRecordResponseDTO(&r))
+	}
+	for _, h := range e.Highlights {
+		dto.Highlights = append(dto.Highlights, ToDailyHighlightsResponseDTO(&h))
+	}
+	return dto
+}
+
+// GET /me/export: 記録した全データのエクスポートAPI（JSONファイルとしてダウンロード）
+func (c *ExportController) Export(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	export, err := c.usecase.Export(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	filename := "tofunote-export-" + export.ExportedAt.Format("20060102") + ".json"
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.JSON(http.StatusOK, ToExportResponseDTO(export))
+}
@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"tofunote-backend/domain/factor"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FactorController struct {
+	usecase usecases.IFactorUsecase
+}
+
+func NewFactorController(usecase usecases.IFactorUsecase) *FactorController {
+	return &FactorController{usecase: usecase}
+}
+
+type FactorValueDTO struct {
+	Key    string   `json:"key"`
+	Number *float64 `json:"number,omitempty"`
+	Bool   *bool    `json:"bool,omitempty"`
+}
+
+type SaveDailyFactorsDTO struct {
+	SleepHours      *float64         `json:"sleep_hours"`
+	ExerciseMinutes *int             `json:"exercise_minutes"`
+	AlcoholUnits    *float64         `json:"alcohol_units"`
+	MedicationTaken *bool            `json:"medication_taken"`
+	Custom          []FactorValueDTO `json:"custom"`
+}
+
+type DailyFactorsResponseDTO struct {
+	ID              string           `json:"id"`
+	Date            string           `json:"date"`
+	SleepHours      *float64         `json:"sleep_hours"`
+	ExerciseMinutes *int             `json:"exercise_minutes"`
+	AlcoholUnits    *float64         `json:"alcohol_units"`
+	MedicationTaken *bool            `json:"medication_taken"`
+	Custom          []FactorValueDTO `json:"custom"`
+}
+
+type CreateFactorDefinitionDTO struct {
+	Key  string  `json:"key" binding:"required"`
+	Name string  `json:"name" binding:"required"`
+	Type string  `json:"type" binding:"required"`
+	Unit string  `json:"unit"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+}
+
+type FactorDefinitionResponseDTO struct {
+	Key     string   `json:"key"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Unit    string   `json:"unit,omitempty"`
+	Min     *float64 `json:"min,omitempty"`
+	Max     *float64 `json:"max,omitempty"`
+	Builtin bool     `json:"builtin"`
+}
+
+// ToDailyFactorsResponseDTO converts domain DailyFactors to response DTO
+func ToDailyFactorsResponseDTO(f *factor.DailyFactors) DailyFactorsResponseDTO {
+	custom := make([]FactorValueDTO, 0, len(f.Custom))
+	for _, v := range f.Custom {
+		custom = append(custom, FactorValueDTO{Key: v.Key, Number: v.Number, Bool: v.Bool})
+	}
+	return DailyFactorsResponseDTO{
+		ID:              f.ID,
+		Date:            f.Date,
+		SleepHours:      f.SleepHours,
+		ExerciseMinutes: f.ExerciseMinutes,
+		AlcoholUnits:    f.AlcoholUnits,
+		MedicationTaken: f.MedicationTaken,
+		Custom:          custom,
+	}
+}
+
+// ToFactorDefinitionResponseDTO converts domain Definition to response DTO
+func ToFactorDefinitionResponseDTO(d factor.Definition) FactorDefinitionResponseDTO {
+	dto := FactorDefinitionResponseDTO{
+		Key:     d.Key,
+		Name:    d.Name,
+		Type:    string(d.Type),
+		Unit:    d.Unit,
+		Builtin: d.IsBuiltin(),
+	}
+	if d.Type == factor.TypeNumeric {
+		min, max := d.Min, d.Max
+		dto.Min, dto.Max = &min, &max
+	}
+	return dto
+}
+
+// GET /me/factors/:date: 指定日付の因子記録取得API
+func (c *FactorController) FindByDate(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	date := ctx.Param("date")
+
+	f, err := c.usecase.FindByUserIDAndDate(ctx.Request.Context(), userIDStr, date)
+	if err != nil {
+		if strings.Contains(err.Error(), "指定された日付の因子記録が見つかりません") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToDailyFactorsResponseDTO(f)})
+}
+
+// GET /me/factors/range: 期間指定の因子記録取得API
+func (c *FactorController) FindByDateRange(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	startDate := ctx.Query("start_date")
+	endDate := ctx.Query("end_date")
+	if startDate == "" || endDate == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_dateとend_dateの両方が必要です"})
+		return
+	}
+
+	records, err := c.usecase.FindByUserIDAndDateRange(ctx.Request.Context(), userIDStr, startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responseDTOs := make([]DailyFactorsResponseDTO, 0, len(records))
+	for _, f := range records {
+		responseDTOs = append(responseDTOs, ToDailyFactorsResponseDTO(&f))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+// PUT /me/factors/:date: 指定日付の因子記録の作成・上書きAPI
+func (c *FactorController) Save(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	date := ctx.Param("date")
+
+	var req SaveDailyFactorsDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	f := factor.DailyFactors{
+		UserID:          userIDStr,
+		Date:            date,
+		SleepHours:      req.SleepHours,
+		ExerciseMinutes: req.ExerciseMinutes,
+		AlcoholUnits:    req.AlcoholUnits,
+		MedicationTaken: req.MedicationTaken,
+	}
+	for _, v := range req.Custom {
+		f.Custom = append(f.Custom, factor.Value{Key: v.Key, Number: v.Number, Bool: v.Bool})
+	}
+	if f.IsEmpty() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "記録する因子がありません"})
+		return
+	}
+
+	if err := c.usecase.Save(ctx.Request.Context(), &f); err != nil {
+		if strings.Contains(err.Error(), "因子") || strings.Contains(err.Error(), "日付の形式が不正です") ||
+			strings.Contains(err.Error(), "範囲で指定してください") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToDailyFactorsResponseDTO(&f)})
+}
+
+// DELETE /me/factors/:date: 指定日付の因子記録削除API
+func (c *FactorController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	date := ctx.Param("date")
+
+	if err := c.usecase.Delete(ctx.Request.Context(), userIDStr, date); err != nil {
+		if strings.Contains(err.Error(), "指定された日付の因子記録が見つかりません") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "因子記録を削除しました"}})
+}
+
+// GET /me/factor-definitions: 組み込み・ユーザー定義の因子一覧取得API
+func (c *FactorController) FindDefinitions(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	defs, err := c.usecase.ListDefinitions(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responseDTOs := make([]FactorDefinitionResponseDTO, 0, len(defs))
+	for _, d := range defs {
+		responseDTOs = append(responseDTOs, ToFactorDefinitionResponseDTO(d))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+// POST /me/factor-definitions: ユーザー定義の因子追加API
+func (c *FactorController) CreateDefinition(ctx *gin.Context) {
+	var req CreateFactorDefinitionDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	def, err := factor.NewCustomDefinition(userIDStr, req.Key, req.Name, factor.Type(req.Type), req.Unit, req.Min, req.Max)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.usecase.CreateDefinition(ctx.Request.Context(), &def); err != nil {
+		if strings.Contains(err.Error(), "この因子は既に登録されています") {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "まで登録できます") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": ToFactorDefinitionResponseDTO(def)})
+}
+
+// DELETE /me/factor-definitions/:key: ユーザー定義の因子削除API
+func (c *FactorController) DeleteDefinition(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	key := ctx.Param("key")
+
+	if err := c.usecase.DeleteDefinition(ctx.Request.Context(), userIDStr, key); err != nil {
+		if strings.Contains(err.Error(), "指定された因子が見つかりません") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "因子を削除しました"}})
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"tofunote-backend/domain/gratitude"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GratitudeController struct {
+	usecase usecases.IGratitudeUsecase
+}
+
+func NewGratitudeController(usecase usecases.IGratitudeUsecase) *GratitudeController {
+	return &GratitudeController{usecase: usecase}
+}
+
+type SaveDailyHighlightsDTO struct {
+	GoodThings []string `json:"good_things"`
+	Highlight  string   `json:"highlight"`
+	Lowlight   string   `json:"lowlight"`
+}
+
+type DailyHighlightsResponseDTO struct {
+	ID         string   `json:"id"`
+	Date       string   `json:"date"`
+	GoodThings []string `json:"good_things"`
+	Highlight  string   `json:"highlight"`
+	Lowlight   string   `json:"lowlight"`
+}
+
+type GratitudeMemoryDTO struct {
+	Date string `json:"date"`
+	Text string `json:"text"`
+}
+
+type ResurfacedGratitudeResponseDTO struct {
+	Triggered bool                `json:"triggered"`
+	Mental    *int                `json:"mental"`
+	Memory    *GratitudeMemoryDTO `json:"memory"`
+}
+
+// ToDailyHighlightsResponseDTO converts domain DailyHighlights to response DTO
+func ToDailyHighlightsResponseDTO(h *gratitude.DailyHighlights) DailyHighlightsResponseDTO {
+	goodThings := h.GoodThings
+	if goodThings == nil {
+		goodThings = []string{}
+	}
+	return DailyHighlightsResponseDTO{
+		ID:         h.ID,
+		Date:       h.Date,
+		GoodThings: goodThings,
+		Highlight:  h.Highlight,
+		Lowlight:   h.Lowlight,
+	}
+}
+
+// GET /me/highlights/:date: 指定日付の良かったこと・ハイライト・ローライト取得API
+func (c *GratitudeController) FindByDate(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	h, err := c.usecase.FindByUserIDAndDate(ctx.Request.Context(), userIDStr, ctx.Param("date"))
+	if err != nil {
+		if strings.Contains(err.Error(), "見つかりません") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToDailyHighlightsResponseDTO(h)})
+}
+
+// GET /me/highlights/range: 期間指定の良かったこと・ハイライト・ローライト取得API
+func (c *GratitudeController) FindByDateRange(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	startDate := ctx.Query("start_date")
+	endDate := ctx.Query("end_date")
+	if startDate == "" || endDate == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_dateとend_dateの両方が必要です"})
+		return
+	}
+
+	records, err := c.usecase.FindByUserIDAndDateRange(ctx.Request.Context(), userIDStr, startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responseDTOs := make([]DailyHighlightsResponseDTO, 0, len(records))
+	for _, h := range records {
+		responseDTOs = append(responseDTOs, ToDailyHighlightsResponseDTO(&h))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+// PUT /me/highlights/:date: 指定日付の良かったこと・ハイライト・ローライトの作成・上書きAPI
+func (c *GratitudeController) Save(ctx *gin.Context) {
+	var req SaveDailyHighlightsDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	h := gratitude.DailyHighlights{
+		UserID:     userIDStr,
+		Date:       ctx.Param("date"),
+		GoodThings: req.GoodThings,
+		Highlight:  req.Highlight,
+		Lowlight:   req.Lowlight,
+	}
+	if err := c.usecase.Save(ctx.Request.Context(), &h); err != nil {
+		if strings.Contains(err.Error(), "日付の形式が不正です") || strings.Contains(err.Error(), "までです") ||
+			strings.Contains(err.Error(), "入力してください") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToDailyHighlightsResponseDTO(&h)})
+}
+
+// DELETE /me/highlights/:date: 指定日付の良かったこと・ハイライト・ローライト削除API
+func (c *GratitudeController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	if err := c.usecase.Delete(ctx.Request.Context(), userIDStr, ctx.Param("date")); err != nil {
+		if strings.Contains(err.Error(), "見つかりません") {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "良かったことを削除しました"}})
+}
+
+// GET /me/gratitude/random: 今日（dateで指定可）のメンタルスコアが低い場合に過去の良かったことを1つ返すAPI
+func (c *GratitudeController) RandomPast(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	date := ctx.Query("date")
+	// 未指定の場合はユースケースがユーザーのタイムゾーンでの今日を使う
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "日付の形式が不正です: " + date})
+			return
+		}
+	}
+
+	result, err := c.usecase.RandomPastGratitude(ctx.Request.Context(), userIDStr, date)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	dto := ResurfacedGratitudeResponseDTO{Triggered: result.Triggered, Mental: result.Mental}
+	if result.Memory != nil {
+		dto.Memory = &GratitudeMemoryDTO{Date: result.Memory.Date, Text: result.Memory.Text}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": dto})
+}
@@ -0,0 +1,405 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"tofunote-backend/domain/medication"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MedicationController struct {
+	usecase usecases.IMedicationUsecase
+}
+
+func NewMedicationController(usecase usecases.IMedicationUsecase) *MedicationController {
+	return &MedicationController{usecase: usecase}
+}
+
+type MedicationScheduleDTO struct {
+	Times    []string `json:"times" binding:"required"`
+	Weekdays []int    `json:"weekdays"`
+}
+
+type SaveMedicationDTO struct {
+	Name             string                `json:"name" binding:"required"`
+	Dose             string                `json:"dose"`
+	Schedule         MedicationScheduleDTO `json:"schedule" binding:"required"`
+	StartDate        string                `json:"start_date" binding:"required"`
+	EndDate          string                `json:"end_date"`
+	RemindersEnabled bool                  `json:"reminders_enabled"`
+}
+
+type MedicationResponseDTO struct {
+	ID               string                `json:"id"`
+	Name             string                `json:"name"`
+	Dose             string                `json:"dose"`
+	Schedule         MedicationScheduleDTO `json:"schedule"`
+	StartDate        string                `json:"start_date"`
+	EndDate          string                `json:"end_date,omitempty"`
+	RemindersEnabled bool                  `json:"reminders_enabled"`
+}
+
+type RecordDoseDTO struct {
+	Date          string     `json:"date" binding:"required"`
+	ScheduledTime string     `json:"scheduled_time" binding:"required"`
+	Status        string     `json:"status" binding:"required"`
+	TakenAt       *time.Time `json:"taken_at"`
+	Note          string     `json:"note"`
+}
+
+type DoseLogResponseDTO struct {
+	ID            string     `json:"id"`
+	MedicationID  string     `json:"medication_id"`
+	Date          string     `json:"date"`
+	ScheduledTime string     `json:"scheduled_time"`
+	Status        string     `json:"status"`
+	TakenAt       *time.Time `json:"taken_at,omitempty"`
+	Note          string     `json:"note,omitempty"`
+}
+
+type DailyAdherenceResponseDTO struct {
+	Date      string   `json:"date"`
+	Scheduled int      `json:"scheduled"`
+	Taken     int      `json:"taken"`
+	Skipped   int      `json:"skipped"`
+	Missed    int      `json:"missed"`
+	Rate      *float64 `json:"rate"`
+	Mental    *int     `json:"mental"`
+}
+
+type AdherenceResponseDTO struct {
+	StartDate string                      `json:"start_date"`
+	EndDate   string                      `json:"end_date"`
+	Scheduled int                         `json:"scheduled"`
+	Taken     int                         `json:"taken"`
+	Skipped   int                         `json:"skipped"`
+	Missed    int                         `json:"missed"`
+	Rate      *float64                    `json:"rate"`
+	Days      []DailyAdherenceResponseDTO `json:"days"`
+}
+
+// ToMedicationResponseDTO converts domain Medication to response DTO
+func ToMedicationResponseDTO(m *medication.Medication) MedicationResponseDTO {
+	weekdays := make([]int, 0, len(m.Schedule.Weekdays))
+	for _, w := range m.Schedule.Weekdays {
+		weekdays = append(weekdays, int(w))
+	}
+	return MedicationResponseDTO{
+		ID:               m.ID,
+		Name:             m.Name,
+		Dose:             m.Dose,
+		Schedule:         MedicationScheduleDTO{Times: m.Schedule.Times, Weekdays: weekdays},
+		StartDate:        m.StartDate,
+		EndDate:          m.EndDate,
+		RemindersEnabled: m.RemindersEnabled,
+	}
+}
+
+// ToDoseLogResponseDTO converts domain DoseLog to response DTO
+func ToDoseLogResponseDTO(l *medication.DoseLog) DoseLogResponseDTO {
+	return DoseLogResponseDTO{
+		ID:            l.ID,
+		MedicationID:  l.MedicationID,
+		Date:          l.Date,
+		ScheduledTime: l.ScheduledTime,
+		Status:        string(l.Status),
+		TakenAt:       l.TakenAt,
+		Note:          l.Note,
+	}
+}
+
+// ToAdherenceResponseDTO converts domain AdherenceReport to response DTO
+func ToAdherenceResponseDTO(r *medication.AdherenceReport) AdherenceResponseDTO {
+	days := make([]DailyAdherenceResponseDTO, 0, len(r.Days))
+	for _, d := range r.Days {
+		days = append(days, DailyAdherenceResponseDTO{
+			Date:      d.Date,
+			Scheduled: d.Scheduled,
+			Taken:     d.Taken,
+			Skipped:   d.Skipped,
+			Missed:    d.Missed,
+			Rate:      d.Rate,
+			Mental:    d.Mental,
+		})
+	}
+	return AdherenceResponseDTO{
+		StartDate: r.StartDate,
+		EndDate:   r.EndDate,
+		Scheduled: r.Scheduled,
+		Taken:     r.Taken,
+		Skipped:   r.Skipped,
+		Missed:    r.Missed,
+		Rate:      r.Rate,
+		Days:      days,
+	}
+}
+
+func (req SaveMedicationDTO) toDomain(userID string) medication.Medication {
+	weekdays := make([]time.Weekday, 0, len(req.Schedule.Weekdays))
+	for _, w := range req.Schedule.Weekdays {
+		weekdays = append(weekdays, time.Weekday(w))
+	}
+	return medication.Medication{
+		UserID:           userID,
+		Name:             req.Name,
+		Dose:             req.Dose,
+		Schedule:         medication.Schedule{Times: req.Schedule.Times, Weekdays: weekdays},
+		StartDate:        req.StartDate,
+		EndDate:          req.EndDate,
+		RemindersEnabled: req.RemindersEnabled,
+	}
+}
+
+// medicationErrorStatus は服薬関連のエラーに対応するHTTPステータスを返す
+func medicationErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "見つかりません"):
+		return http.StatusNotFound
+	case strings.Contains(msg, "服用"), strings.Contains(msg, "薬の名前"), strings.Contains(msg, "用量"),
+		strings.Contains(msg, "曜日"), strings.Contains(msg, "日付の形式が不正です"),
+		strings.Contains(msg, "期間は"), strings.Contains(msg, "end_date"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// GET /me/medications: 登録した薬の一覧取得API
+func (c *MedicationController) FindAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	meds, err := c.usecase.FindAll(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responseDTOs := make([]MedicationResponseDTO, 0, len(meds))
+	for _, m := range meds {
+		responseDTOs = append(responseDTOs, ToMedicationResponseDTO(&m))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+// GET /me/medications/:id: 薬の取得API
+func (c *MedicationController) FindByID(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	m, err := c.usecase.FindByID(ctx.Request.Context(), userIDStr, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(medicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToMedicationResponseDTO(m)})
+}
+
+// POST /me/medications: 薬の登録API
+func (c *MedicationController) Create(ctx *gin.Context) {
+	var req SaveMedicationDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	m := req.toDomain(userIDStr)
+	if err := c.usecase.Create(ctx.Request.Context(), &m); err != nil {
+		ctx.JSON(medicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": ToMedicationResponseDTO(&m)})
+}
+
+// PUT /me/medications/:id: 薬の更新API
+func (c *MedicationController) Update(ctx *gin.Context) {
+	var req SaveMedicationDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	m := req.toDomain(userIDStr)
+	m.ID = ctx.Param("id")
+	if err := c.usecase.Update(ctx.Request.Context(), &m); err != nil {
+		ctx.JSON(medicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToMedicationResponseDTO(&m)})
+}
+
+// DELETE /me/medications/:id: 薬と服用記録の削除API
+func (c *MedicationController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	if err := c.usecase.Delete(ctx.Request.Context(), userIDStr, ctx.Param("id")); err != nil {
+		ctx.JSON(medicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "薬を削除しました"}})
+}
+
+// PUT /me/medications/:id/doses: 服用済み・スキップの記録API
+func (c *MedicationController) RecordDose(ctx *gin.Context) {
+	var req RecordDoseDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	status, err := medication.ParseDoseStatus(req.Status)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	log, err := c.usecase.RecordDose(ctx.Request.Context(), userIDStr, ctx.Param("id"), req.Date, req.ScheduledTime, status, req.TakenAt, req.Note)
+	if err != nil {
+		ctx.JSON(medicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToDoseLogResponseDTO(log)})
+}
+
+// DELETE /me/medications/:id/doses?date=YYYY-MM-DD&scheduled_time=HH:MM: 服用記録の取り消しAPI
+func (c *MedicationController) DeleteDose(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	date := ctx.Query("date")
+	scheduledTime := ctx.Query("scheduled_time")
+	if date == "" || scheduledTime == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "dateとscheduled_timeの両方が必要です"})
+		return
+	}
+
+	if err := c.usecase.DeleteDose(ctx.Request.Context(), userIDStr, ctx.Param("id"), date, scheduledTime); err != nil {
+		ctx.JSON(medicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "服用記録を取り消しました"}})
+}
+
+// GET /me/medication-doses: 期間指定の服用記録取得API
+func (c *MedicationController) FindDoses(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	startDate := ctx.Query("start_date")
+	endDate := ctx.Query("end_date")
+	if startDate == "" || endDate == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_dateとend_dateの両方が必要です"})
+		return
+	}
+
+	logs, err := c.usecase.FindDoses(ctx.Request.Context(), userIDStr, startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responseDTOs := make([]DoseLogResponseDTO, 0, len(logs))
+	for _, l := range logs {
+		responseDTOs = append(responseDTOs, ToDoseLogResponseDTO(&l))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+// GET /me/stats/adherence: 服用率の推移とメンタルスコアの取得API（期間未指定の場合は直近30日）
+func (c *MedicationController) Adherence(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	startDate := ctx.Query("start_date")
+	endDate := ctx.Query("end_date")
+	if (startDate == "") != (endDate == "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_dateとend_dateは両方指定してください"})
+		return
+	}
+
+	report, err := c.usecase.Adherence(ctx.Request.Context(), userIDStr, ctx.Query("medication_id"), startDate, endDate)
+	if err != nil {
+		ctx.JSON(medicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToAdherenceResponseDTO(report)})
+}
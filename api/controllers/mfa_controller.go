@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAController struct {
+	usecase       usecases.ITOTPUsecase
+	refreshTokens usecases.IRefreshTokenUsecase
+}
+
+func NewMFAController(usecase usecases.ITOTPUsecase, refreshTokens usecases.IRefreshTokenUsecase) *MFAController {
+	return &MFAController{usecase: usecase, refreshTokens: refreshTokens}
+}
+
+type MFAStatusDTO struct {
+	TOTPEnabled            bool `json:"totp_enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type TOTPEnrollmentDTO struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"otpauth_uri"`
+}
+
+type TOTPCodeDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFALoginDTO struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code は認証アプリの6桁のコード、またはリカバリーコード
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrInvalidTOTPCode):
+		return http.StatusBadRequest
+	case errors.Is(err, auth.ErrInvalidMFAChallenge):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrTOTPRequiresAccount):
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, auth.ErrTOTPAlreadyEnabled), errors.Is(err, auth.ErrTOTPNotEnabled), errors.Is(err, auth.ErrTOTPNotEnrolled):
+		return http.StatusConflict
+	case errors.Is(err, auth.ErrTOTPLocked):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GET /me/mfa: 二段階認証の設定状況取得API
+func (c *MFAController) Status(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	status, err := c.usecase.Status(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "二段階認証の設定の取得に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": MFAStatusDTO{TOTPEnabled: status.TOTPEnabled, RecoveryCodesRemaining: status.RecoveryCodesRemaining}})
+}
+
+// POST /me/mfa/totp/enroll: 認証アプリに登録する共有鍵・otpauth URIを発行するAPI
+func (c *MFAController) EnrollTOTP(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	enrollment, err := c.usecase.Enroll(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": TOTPEnrollmentDTO{Secret: enrollment.Secret, ProvisioningURI: enrollment.ProvisioningURI}})
+}
+
+// POST /me/mfa/totp/verify: 認証アプリのコードを確認して二段階認証を有効にするAPI（リカバリーコードを返す）
+func (c *MFAController) VerifyTOTP(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	var req TOTPCodeDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	codes, err := c.usecase.Activate(ctx.Request.Context(), userIDStr, req.Code)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": RecoveryCodesDTO{RecoveryCodes: codes}})
+}
+
+// POST /me/mfa/totp/disable: 認証コードまたはリカバリーコードを確認して二段階認証を無効にするAPI
+func (c *MFAController) DisableTOTP(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	var req TOTPCodeDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	if err := c.usecase.Disable(ctx.Request.Context(), userIDStr, req.Code); err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "二段階認証を無効にしました"})
+}
+
+// POST /me/mfa/recovery-codes: 認証コードを確認してリカバリーコードを作り直すAPI
+func (c *MFAController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	var req TOTPCodeDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	codes, err := c.usecase.RegenerateRecoveryCodes(ctx.Request.Context(), userIDStr, req.Code)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": RecoveryCodesDTO{RecoveryCodes: codes}})
+}
+
+// POST /mfa/verify: ログイン時に返した確認トークンと認証コードを検証し、JWTとリフレッシュトークンを発行するAPI
+func (c *MFAController) VerifyLogin(ctx *gin.Context) {
+	var req MFALoginDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	u, err := c.usecase.FinishLogin(ctx.Request.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		status := mfaErrorStatus(err)
+		if errors.Is(err, auth.ErrInvalidTOTPCode) {
+			status = http.StatusUnauthorized
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := issueLoginTokens(ctx, c.refreshTokens, u)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCController struct {
+	usecase       usecases.IOIDCLoginUsecase
+	refreshTokens usecases.IRefreshTokenUsecase
+	mfa           usecases.ITOTPUsecase
+	// frontendCallbackURL はform_postで受け取った認可レスポンスを転送するフロントエンドのURL
+	frontendCallbackURL string
+}
+
+func NewOIDCController(usecase usecases.IOIDCLoginUsecase, refreshTokens usecases.IRefreshTokenUsecase, mfa usecases.ITOTPUsecase, frontendCallbackURL string) *OIDCController {
+	return &OIDCController{usecase: usecase, refreshTokens: refreshTokens, mfa: mfa, frontendCallbackURL: frontendCallbackURL}
+}
+
+type OIDCStartResponseDTO struct {
+	AuthorizationURL string `json:"authorization_url"`
+	FlowToken        string `json:"flow_token"`
+}
+
+type OIDCCallbackDTO struct {
+	Code      string `json:"code" binding:"required"`
+	State     string `json:"state" binding:"required"`
+	FlowToken string `json:"flow_token" binding:"required"`
+}
+
+func oidcErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrUnknownProvider):
+		return http.StatusNotFound
+	case errors.Is(err, auth.ErrInvalidFlow):
+		return http.StatusBadRequest
+	case errors.Is(err, auth.ErrInvalidIDToken):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// GET /auth/oidc/providers: 利用可能なOIDCプロバイダ一覧取得API
+func (c *OIDCController) Providers(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"data": c.usecase.Providers()})
+}
+
+// POST /auth/oidc/:provider/start: 認可コードフロー（PKCE）の開始API
+func (c *OIDCController) Start(ctx *gin.Context) {
+	result, err := c.usecase.Start(ctx.Request.Context(), ctx.Param("provider"))
+	if err != nil {
+		ctx.JSON(oidcErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": OIDCStartResponseDTO{
+		AuthorizationURL: result.AuthorizationURL,
+		FlowToken:        result.FlowToken,
+	}})
+}
+
+// POST /auth/oidc/:provider/callback: 認可コードを検証してログインするAPI（ユーザーが存在しなければ作成する）
+func (c *OIDCController) Callback(ctx *gin.Context) {
+	var req OIDCCallbackDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	u, err := c.usecase.Callback(ctx.Request.Context(), ctx.Param("provider"), req.Code, req.State, req.FlowToken)
+	if err != nil {
+		ctx.JSON(oidcErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	res, err := completeLogin(ctx, c.refreshTokens, c.mfa, u)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// POST /auth/oidc/:provider/form-post: response_mode=form_postの認可レスポンスをフロントエンドへ転送する（Apple向け）
+func (c *OIDCController) FormPost(ctx *gin.Context) {
+	if c.frontendCallbackURL == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "転送先のURLが設定されていません"})
+		return
+	}
+	target, err := url.Parse(c.frontendCallbackURL)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "転送先のURLが不正です"})
+		return
+	}
+	q := target.Query()
+	q.Set("provider", ctx.Param("provider"))
+	for _, key := range []string{"code", "state", "error"} {
+		if v := ctx.PostForm(key); v != "" {
+			q.Set(key, v)
+		}
+	}
+	target.RawQuery = q.Encode()
+	ctx.Redirect(http.StatusSeeOther, target.String())
+}
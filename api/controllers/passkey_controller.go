@@ -0,0 +1,347 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasskeyController struct {
+	usecase       usecases.IPasskeyUsecase
+	refreshTokens usecases.IRefreshTokenUsecase
+}
+
+func NewPasskeyController(usecase usecases.IPasskeyUsecase, refreshTokens usecases.IRefreshTokenUsecase) *PasskeyController {
+	return &PasskeyController{usecase: usecase, refreshTokens: refreshTokens}
+}
+
+// 以下のオプションはWebAuthnのJSON形式（バイナリはbase64url）で返し、
+// フロントエンドはPublicKeyCredential.parseCreationOptionsFromJSON等でそのまま使える
+
+type PasskeyCredentialDescriptorDTO struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type PasskeyCreationOptionsDTO struct {
+	RP struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	Challenge        string `json:"challenge"`
+	PubKeyCredParams []struct {
+		Type string `json:"type"`
+		Alg  int    `json:"alg"`
+	} `json:"pubKeyCredParams"`
+	Timeout                int64                            `json:"timeout"`
+	ExcludeCredentials     []PasskeyCredentialDescriptorDTO `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		ResidentKey        string `json:"residentKey"`
+		RequireResidentKey bool   `json:"requireResidentKey"`
+		UserVerification   string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+type PasskeyRequestOptionsDTO struct {
+	Challenge        string                           `json:"challenge"`
+	RPID             string                           `json:"rpId"`
+	Timeout          int64                            `json:"timeout"`
+	AllowCredentials []PasskeyCredentialDescriptorDTO `json:"allowCredentials"`
+	UserVerification string                           `json:"userVerification"`
+}
+
+// PasskeyRegistrationDTO は登録の完了リクエスト（credentialはPublicKeyCredential.toJSON()の値）
+type PasskeyRegistrationDTO struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+	Name        string `json:"name"`
+	Credential  struct {
+		ID       string `json:"id"`
+		RawID    string `json:"rawId"`
+		Response struct {
+			ClientDataJSON    string   `json:"clientDataJSON" binding:"required"`
+			AttestationObject string   `json:"attestationObject" binding:"required"`
+			Transports        []string `json:"transports"`
+		} `json:"response" binding:"required"`
+	} `json:"credential" binding:"required"`
+}
+
+// PasskeyLoginDTO はログインの完了リクエスト（credentialはPublicKeyCredential.toJSON()の値）
+type PasskeyLoginDTO struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+	Credential  struct {
+		ID       string `json:"id"`
+		RawID    string `json:"rawId"`
+		Response struct {
+			ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+			AuthenticatorData string `json:"authenticatorData" binding:"required"`
+			Signature         string `json:"signature" binding:"required"`
+			UserHandle        string `json:"userHandle"`
+		} `json:"response" binding:"required"`
+	} `json:"credential" binding:"required"`
+}
+
+type RenamePasskeyDTO struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type PasskeyResponseDTO struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func toPasskeyResponse(p *auth.Passkey) PasskeyResponseDTO {
+	transports := p.Transports
+	if transports == nil {
+		transports = []string{}
+	}
+	return PasskeyResponseDTO{ID: p.ID, Name: p.Name, Transports: transports, CreatedAt: p.CreatedAt, LastUsedAt: p.LastUsedAt}
+}
+
+func passkeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrInvalidPasskey), errors.Is(err, auth.ErrPasskeySignCount):
+		return http.StatusUnauthorized
+	case errors.Is(err, auth.ErrInvalidPasskeyChallenge), errors.Is(err, usecases.ErrInvalidPasskeyName):
+		return http.StatusBadRequest
+	case errors.Is(err, usecases.ErrPasskeyRequiresAccount):
+		return http.StatusForbidden
+	case errors.Is(err, auth.ErrPasskeyAlreadyRegistered):
+		return http.StatusConflict
+	case errors.Is(err, auth.ErrPasskeyNotFound), errors.Is(err, usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// decodeBase64URL はbase64url（パディングの有無を問わない）をデコードする
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+func encodeBase64URL(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+// POST /me/passkeys/options: パスキーの登録を開始するAPI（認証器に渡すオプションを返す）
+func (c *PasskeyController) BeginRegistration(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	options, err := c.usecase.BeginRegistration(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(passkeyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	var publicKey PasskeyCreationOptionsDTO
+	publicKey.RP.ID = options.RelyingParty.ID
+	publicKey.RP.Name = options.RelyingParty.Name
+	publicKey.User.ID = encodeBase64URL(options.UserHandle)
+	publicKey.User.Name = options.UserName
+	publicKey.User.DisplayName = options.UserDisplayName
+	publicKey.Challenge = encodeBase64URL(options.Challenge)
+	for _, alg := range []int{auth.COSEAlgES256, auth.COSEAlgEdDSA, auth.COSEAlgRS256} {
+		publicKey.PubKeyCredParams = append(publicKey.PubKeyCredParams, struct {
+			Type string `json:"type"`
+			Alg  int    `json:"alg"`
+		}{Type: "public-key", Alg: alg})
+	}
+	publicKey.Timeout = options.Timeout.Milliseconds()
+	publicKey.ExcludeCredentials = make([]PasskeyCredentialDescriptorDTO, len(options.ExcludeCredentials))
+	for i, p := range options.ExcludeCredentials {
+		publicKey.ExcludeCredentials[i] = PasskeyCredentialDescriptorDTO{Type: "public-key", ID: encodeBase64URL(p.CredentialID), Transports: p.Transports}
+	}
+	publicKey.AuthenticatorSelection.ResidentKey = "required"
+	publicKey.AuthenticatorSelection.RequireResidentKey = true
+	publicKey.AuthenticatorSelection.UserVerification = "required"
+	publicKey.Attestation = "none"
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"challenge_id": options.ChallengeID, "public_key": publicKey}})
+}
+
+// POST /me/passkeys: 認証器のレスポンスを検証してパスキーを登録するAPI
+func (c *PasskeyController) FinishRegistration(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	var req PasskeyRegistrationDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	clientData, err1 := decodeBase64URL(req.Credential.Response.ClientDataJSON)
+	attestationObject, err2 := decodeBase64URL(req.Credential.Response.AttestationObject)
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	passkey, err := c.usecase.FinishRegistration(ctx.Request.Context(), userIDStr, req.ChallengeID, req.Name, &auth.PasskeyAttestation{
+		ClientDataJSON:    clientData,
+		AttestationObject: attestationObject,
+		Transports:        req.Credential.Response.Transports,
+	})
+	if err != nil {
+		status := passkeyErrorStatus(err)
+		if errors.Is(err, auth.ErrInvalidPasskey) {
+			// 登録時の検証の失敗はリクエストの誤りとして扱う
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": toPasskeyResponse(passkey)})
+}
+
+// POST /passkeys/login/options: パスキーでのログインを開始するAPI
+func (c *PasskeyController) BeginLogin(ctx *gin.Context) {
+	options, err := c.usecase.BeginLogin(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(passkeyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"challenge_id": options.ChallengeID,
+		"public_key": PasskeyRequestOptionsDTO{
+			Challenge:        encodeBase64URL(options.Challenge),
+			RPID:             options.RPID,
+			Timeout:          options.Timeout.Milliseconds(),
+			AllowCredentials: []PasskeyCredentialDescriptorDTO{},
+			UserVerification: "required",
+		},
+	}})
+}
+
+// POST /passkeys/login: 認証器の署名を検証し、ゲストログインと同じくJWTとリフレッシュトークンを発行するAPI
+func (c *PasskeyController) FinishLogin(ctx *gin.Context) {
+	var req PasskeyLoginDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	rawID := req.Credential.RawID
+	if rawID == "" {
+		rawID = req.Credential.ID
+	}
+	credentialID, err1 := decodeBase64URL(rawID)
+	clientData, err2 := decodeBase64URL(req.Credential.Response.ClientDataJSON)
+	authenticatorData, err3 := decodeBase64URL(req.Credential.Response.AuthenticatorData)
+	signature, err4 := decodeBase64URL(req.Credential.Response.Signature)
+	userHandle, err5 := decodeBase64URL(req.Credential.Response.UserHandle)
+	if err := errors.Join(err1, err2, err3, err4, err5); err != nil || len(credentialID) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	u, err := c.usecase.FinishLogin(ctx.Request.Context(), req.ChallengeID, &auth.PasskeyAssertion{
+		CredentialID:      credentialID,
+		ClientDataJSON:    clientData,
+		AuthenticatorData: authenticatorData,
+		Signature:         signature,
+		UserHandle:        userHandle,
+	})
+	if err != nil {
+		ctx.JSON(passkeyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	res, err := issueLoginTokens(ctx, c.refreshTokens, u)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GET /me/passkeys: 登録済みのパスキー一覧取得API
+func (c *PasskeyController) FindAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	passkeys, err := c.usecase.List(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "パスキーの取得に失敗しました"})
+		return
+	}
+	res := make([]PasskeyResponseDTO, len(passkeys))
+	for i, p := range passkeys {
+		res[i] = toPasskeyResponse(p)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// PATCH /me/passkeys/:id: パスキーの名前を変更するAPI
+func (c *PasskeyController) Rename(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	var req RenamePasskeyDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	if err := c.usecase.Rename(ctx.Request.Context(), userIDStr, ctx.Param("id"), req.Name); err != nil {
+		ctx.JSON(passkeyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "パスキーの名前を変更しました"})
+}
+
+// DELETE /me/passkeys/:id: パスキーを削除するAPI
+func (c *PasskeyController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	if err := c.usecase.Delete(ctx.Request.Context(), userIDStr, ctx.Param("id")); err != nil {
+		ctx.JSON(passkeyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "パスキーを削除しました"})
+}
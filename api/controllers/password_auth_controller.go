@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordAuthController struct {
+	usecase       usecases.IPasswordAuthUsecase
+	refreshTokens usecases.IRefreshTokenUsecase
+	mfa           usecases.ITOTPUsecase
+}
+
+func NewPasswordAuthController(usecase usecases.IPasswordAuthUsecase, refreshTokens usecases.IRefreshTokenUsecase, mfa usecases.ITOTPUsecase) *PasswordAuthController {
+	return &PasswordAuthController{usecase: usecase, refreshTokens: refreshTokens, mfa: mfa}
+}
+
+type RegisterDTO struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Nickname string `json:"nickname"`
+}
+
+type LoginDTO struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type VerifyEmailDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordDTO struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordDTO struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func passwordAuthErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		return http.StatusUnauthorized
+	case errors.Is(err, auth.ErrInvalidOneTimeToken),
+		errors.Is(err, user.ErrInvalidEmail),
+		errors.Is(err, auth.ErrInvalidPassword),
+		errors.Is(err, usecases.ErrNoEmail):
+		return http.StatusBadRequest
+	case errors.Is(err, usecases.ErrEmailAlreadyRegistered), errors.Is(err, usecases.ErrEmailAlreadyVerified):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// POST /register: メールアドレスとパスワードでユーザー登録するAPI（確認メールを送信する）
+func (c *PasswordAuthController) Register(ctx *gin.Context) {
+	var req RegisterDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	u, err := c.usecase.Register(ctx.Request.Context(), req.Email, req.Password, req.Nickname)
+	if err != nil {
+		ctx.JSON(passwordAuthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	res, err := issueLoginTokens(ctx, c.refreshTokens, u)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// POST /login: メールアドレスとパスワードでログインするAPI
+func (c *PasswordAuthController) Login(ctx *gin.Context) {
+	var req LoginDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	u, err := c.usecase.Login(ctx.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		ctx.JSON(passwordAuthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	res, err := completeLogin(ctx, c.refreshTokens, c.mfa, u)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// POST /email/verify: 確認メールのトークンでメールアドレスを確認済みにするAPI
+func (c *PasswordAuthController) VerifyEmail(ctx *gin.Context) {
+	var req VerifyEmailDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	if err := c.usecase.VerifyEmail(ctx.Request.Context(), req.Token); err != nil {
+		ctx.JSON(passwordAuthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "メールアドレスを確認しました"})
+}
+
+// POST /me/email/verification: 確認メールを再送するAPI
+func (c *PasswordAuthController) ResendVerification(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	if err := c.usecase.SendVerification(ctx.Request.Context(), userIDStr); err != nil {
+		ctx.JSON(passwordAuthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "確認メールを送信しました"})
+}
+
+// POST /password/forgot: パスワード再設定メールを送るAPI（登録の有無にかかわらず同じ応答を返す）
+func (c *PasswordAuthController) ForgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	if err := c.usecase.RequestPasswordReset(ctx.Request.Context(), req.Email); err != nil {
+		if errors.Is(err, user.ErrInvalidEmail) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "パスワード再設定メールの送信に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "登録されているメールアドレスであれば、パスワード再設定メールを送信しました"})
+}
+
+// POST /password/reset: 再設定メールのトークンで新しいパスワードを設定するAPI
+func (c *PasswordAuthController) ResetPassword(ctx *gin.Context) {
+	var req ResetPasswordDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	if err := c.usecase.ResetPassword(ctx.Request.Context(), req.Token, req.Password); err != nil {
+		ctx.JSON(passwordAuthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "パスワードを再設定しました。新しいパスワードでログインしてください"})
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PersonalAccessTokenController struct {
+	usecase usecases.IPersonalAccessTokenUsecase
+}
+
+func NewPersonalAccessTokenController(usecase usecases.IPersonalAccessTokenUsecase) *PersonalAccessTokenController {
+	return &PersonalAccessTokenController{usecase: usecase}
+}
+
+type CreatePersonalAccessTokenDTO struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// ExpiresInDays は有効期限までの日数（1〜365、省略時は90）
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+type PersonalAccessTokenResponseDTO struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreatedPersonalAccessTokenDTO struct {
+	PersonalAccessTokenResponseDTO
+	// Token は平文のトークン（この応答でのみ返す）
+	Token string `json:"token"`
+}
+
+func toPersonalAccessTokenResponseDTO(t *auth.PersonalAccessToken) PersonalAccessTokenResponseDTO {
+	scopes := make([]string, len(t.Scopes))
+	for i, s := range t.Scopes {
+		scopes[i] = string(s)
+	}
+	return PersonalAccessTokenResponseDTO{
+		ID:         t.ID,
+		Name:       t.Name,
+		Scopes:     scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+func personalAccessTokenErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidPersonalAccessTokenName), errors.Is(err, usecases.ErrInvalidPersonalAccessTokenExpiry), errors.Is(err, auth.ErrInvalidScope):
+		return http.StatusBadRequest
+	case errors.Is(err, usecases.ErrPersonalAccessTokenRequiresAccount):
+		return http.StatusForbidden
+	case errors.Is(err, auth.ErrPersonalAccessTokenNotFound), errors.Is(err, usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrTooManyPersonalAccessTokens):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GET /me/personal-access-tokens: 発行済みの個人用アクセストークン一覧取得API（平文のトークンは返さない）
+func (c *PersonalAccessTokenController) FindAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	tokens, err := c.usecase.List(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "アクセストークンの取得に失敗しました"})
+		return
+	}
+	res := make([]PersonalAccessTokenResponseDTO, len(tokens))
+	for i, t := range tokens {
+		res[i] = toPersonalAccessTokenResponseDTO(t)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// POST /me/personal-access-tokens: 個人用アクセストークンの発行API（平文のトークンを返すのはこの1回のみ）
+func (c *PersonalAccessTokenController) Create(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	var req CreatePersonalAccessTokenDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ExpiresInDays < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": usecases.ErrInvalidPersonalAccessTokenExpiry.Error()})
+		return
+	}
+	raw, token, err := c.usecase.Create(ctx.Request.Context(), userIDStr, req.Name, scopes, time.Duration(req.ExpiresInDays)*24*time.Hour)
+	if err != nil {
+		ctx.JSON(personalAccessTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": CreatedPersonalAccessTokenDTO{
+		PersonalAccessTokenResponseDTO: toPersonalAccessTokenResponseDTO(token),
+		Token:                          raw,
+	}})
+}
+
+// DELETE /me/personal-access-tokens/:id: 個人用アクセストークンの取り消しAPI
+func (c *PersonalAccessTokenController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	if err := c.usecase.Revoke(ctx.Request.Context(), userIDStr, ctx.Param("id")); err != nil {
+		ctx.JSON(personalAccessTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "アクセストークンを取り消しました"})
+}
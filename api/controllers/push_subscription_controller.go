@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PushSubscriptionController struct {
+	usecase usecases.IPushSubscriptionUsecase
+	// vapidPublicKey はブラウザの購読に使うVAPIDの公開鍵（未設定の場合はWeb Pushを利用できない）
+	vapidPublicKey string
+}
+
+func NewPushSubscriptionController(usecase usecases.IPushSubscriptionUsecase, vapidPublicKey string) *PushSubscriptionController {
+	return &PushSubscriptionController{usecase: usecase, vapidPublicKey: vapidPublicKey}
+}
+
+// RegisterPushSubscriptionDTO はブラウザの PushSubscription.toJSON() の値
+type RegisterPushSubscriptionDTO struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+// PushSubscriptionResponseDTO は登録した端末（送信先のURLと鍵は返さない）
+type PushSubscriptionResponseDTO struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toPushSubscriptionResponseDTO(s notification.PushSubscription) PushSubscriptionResponseDTO {
+	return PushSubscriptionResponseDTO{ID: s.ID, UserAgent: s.UserAgent, CreatedAt: s.CreatedAt}
+}
+
+func pushSubscriptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, notification.ErrInvalidPushSubscription):
+		return http.StatusBadRequest
+	case errors.Is(err, notification.ErrPushSubscriptionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrTooManyPushSubscriptions):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GET /push/vapid-public-key: ブラウザの購読に使うVAPIDの公開鍵の取得API
+func (c *PushSubscriptionController) PublicKey(ctx *gin.Context) {
+	if c.vapidPublicKey == "" {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Web Pushは利用できません"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"public_key": c.vapidPublicKey}})
+}
+
+// GET /me/push-subscriptions: 通知を受け取る端末の一覧取得API
+func (c *PushSubscriptionController) FindAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	subscriptions, err := c.usecase.List(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "端末の取得に失敗しました"})
+		return
+	}
+	res := make([]PushSubscriptionResponseDTO, len(subscriptions))
+	for i, s := range subscriptions {
+		res[i] = toPushSubscriptionResponseDTO(s)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// POST /me/push-subscriptions: 端末（ブラウザ）のWeb Pushの購読の登録API
+func (c *PushSubscriptionController) Register(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	var req RegisterPushSubscriptionDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	subscription, err := c.usecase.Register(ctx.Request.Context(), userIDStr, req.Endpoint, req.Keys.P256dh, req.Keys.Auth, ctx.Request.UserAgent())
+	if err != nil {
+		ctx.JSON(pushSubscriptionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": toPushSubscriptionResponseDTO(*subscription)})
+}
+
+// DELETE /me/push-subscriptions/:id: 端末のWeb Pushの購読の解除API
+func (c *PushSubscriptionController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	if err := c.usecase.Unregister(ctx.Request.Context(), userIDStr, ctx.Param("id")); err != nil {
+		ctx.JSON(pushSubscriptionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "端末の登録を解除しました"})
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	usecase usecases.ISessionUsecase
+}
+
+func NewSessionController(usecase usecases.ISessionUsecase) *SessionController {
+	return &SessionController{usecase: usecase}
+}
+
+type SessionResponseDTO struct {
+	ID          string    `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	UserAgent   string    `json:"user_agent"`
+	IPAddress   string    `json:"ip_address"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	// Current はこのリクエストのアクセストークンを発行したセッションかどうか
+	Current bool `json:"current"`
+}
+
+// GET /me/sessions: ログイン中の端末（セッション）一覧取得API
+func (c *SessionController) FindAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	sessions, err := c.usecase.List(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "セッションの取得に失敗しました"})
+		return
+	}
+	currentID := ctx.GetString("sessionID")
+	res := make([]SessionResponseDTO, len(sessions))
+	for i, s := range sessions {
+		res[i] = SessionResponseDTO{
+			ID:          s.ID,
+			DeviceLabel: s.DeviceLabel,
+			UserAgent:   s.UserAgent,
+			IPAddress:   s.IPAddress,
+			CreatedAt:   s.CreatedAt,
+			LastUsedAt:  s.LastUsedAt,
+			Current:     s.ID == currentID,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// DELETE /me/sessions/:id: 指定した端末のログインを解除するAPI
+func (c *SessionController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	if err := c.usecase.Revoke(ctx.Request.Context(), userIDStr, ctx.Param("id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "セッションの失効に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "セッションを失効させました"})
+}
+
+// DELETE /me/sessions: 全ての端末からログアウトするAPI（このリクエストの端末も含む）
+func (c *SessionController) DeleteAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	if err := c.usecase.RevokeAll(ctx.Request.Context(), userIDStr); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "セッションの失効に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "全ての端末からログアウトしました"})
+}
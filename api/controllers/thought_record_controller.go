@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"tofunote-backend/domain/thoughtrecord"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ThoughtRecordController struct {
+	usecase usecases.IThoughtRecordUsecase
+}
+
+func NewThoughtRecordController(usecase usecases.IThoughtRecordUsecase) *ThoughtRecordController {
+	return &ThoughtRecordController{usecase: usecase}
+}
+
+type EmotionRatingDTO struct {
+	Key    string `json:"key" binding:"required"`
+	Before int    `json:"before"`
+	After  *int   `json:"after,omitempty"`
+}
+
+type SaveThoughtRecordDTO struct {
+	Date             string             `json:"date"`
+	Situation        string             `json:"situation" binding:"required"`
+	AutomaticThought string             `json:"automatic_thought" binding:"required"`
+	Emotions         []EmotionRatingDTO `json:"emotions" binding:"required,dive"`
+	EvidenceFor      string             `json:"evidence_for"`
+	EvidenceAgainst  string             `json:"evidence_against"`
+	BalancedThought  string             `json:"balanced_thought"`
+}
+
+type ThoughtRecordResponseDTO struct {
+	ID               string             `json:"id"`
+	Date             string             `json:"date,omitempty"`
+	Situation        string             `json:"situation"`
+	AutomaticThought string             `json:"automatic_thought"`
+	Emotions         []EmotionRatingDTO `json:"emotions"`
+	EvidenceFor      string             `json:"evidence_for"`
+	EvidenceAgainst  string             `json:"evidence_against"`
+	BalancedThought  string             `json:"balanced_thought"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// ToThoughtRecordResponseDTO converts domain ThoughtRecord to response DTO
+func ToThoughtRecordResponseDTO(r *thoughtrecord.ThoughtRecord) ThoughtRecordResponseDTO {
+	emotions := make([]EmotionRatingDTO, 0, len(r.Emotions))
+	for _, e := range r.Emotions {
+		emotions = append(emotions, EmotionRatingDTO{Key: e.Key, Before: e.Before, After: e.After})
+	}
+	return ThoughtRecordResponseDTO{
+		ID:               r.ID,
+		Date:             r.Date,
+		Situation:        r.Situation,
+		AutomaticThought: r.AutomaticThought,
+		Emotions:         emotions,
+		EvidenceFor:      r.EvidenceFor,
+		EvidenceAgainst:  r.EvidenceAgainst,
+		BalancedThought:  r.BalancedThought,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+	}
+}
+
+func (req SaveThoughtRecordDTO) toDomain(userID string) thoughtrecord.ThoughtRecord {
+	emotions := make([]thoughtrecord.EmotionRating, 0, len(req.Emotions))
+	for _, e := range req.Emotions {
+		emotions = append(emotions, thoughtrecord.EmotionRating{Key: e.Key, Before: e.Before, After: e.After})
+	}
+	return thoughtrecord.ThoughtRecord{
+		UserID:           userID,
+		Date:             req.Date,
+		Situation:        req.Situation,
+		AutomaticThought: req.AutomaticThought,
+		Emotions:         emotions,
+		EvidenceFor:      req.EvidenceFor,
+		EvidenceAgainst:  req.EvidenceAgainst,
+		BalancedThought:  req.BalancedThought,
+	}
+}
+
+// thoughtRecordErrorStatus は思考記録関連のエラーに対応するHTTPステータスを返す
+func thoughtRecordErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "見つかりません"):
+		return http.StatusNotFound
+	case strings.Contains(msg, "入力してください"), strings.Contains(msg, "記入してください"),
+		strings.Contains(msg, "感情"), strings.Contains(msg, "日付の形式が不正です"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// GET /me/thought-records: 思考記録の一覧取得API（date または start_date/end_date で絞り込み）
+func (c *ThoughtRecordController) FindAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	date := ctx.Query("date")
+	startDate := ctx.Query("start_date")
+	endDate := ctx.Query("end_date")
+	if (startDate == "") != (endDate == "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_dateとend_dateは両方指定してください"})
+		return
+	}
+
+	var records []thoughtrecord.ThoughtRecord
+	var err error
+	switch {
+	case date != "":
+		records, err = c.usecase.FindByUserIDAndDate(ctx.Request.Context(), userIDStr, date)
+	case startDate != "":
+		records, err = c.usecase.FindByUserIDAndDateRange(ctx.Request.Context(), userIDStr, startDate, endDate)
+	default:
+		records, err = c.usecase.FindByUserID(ctx.Request.Context(), userIDStr)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	responseDTOs := make([]ThoughtRecordResponseDTO, 0, len(records))
+	for _, r := range records {
+		responseDTOs = append(responseDTOs, ToThoughtRecordResponseDTO(&r))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": responseDTOs})
+}
+
+// GET /me/thought-records/:id: 思考記録の取得API
+func (c *ThoughtRecordController) FindByID(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	record, err := c.usecase.FindByID(ctx.Request.Context(), userIDStr, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(thoughtRecordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToThoughtRecordResponseDTO(record)})
+}
+
+// POST /me/thought-records: 思考記録の作成API
+func (c *ThoughtRecordController) Create(ctx *gin.Context) {
+	var req SaveThoughtRecordDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	record := req.toDomain(userIDStr)
+	if err := c.usecase.Create(ctx.Request.Context(), &record); err != nil {
+		ctx.JSON(thoughtRecordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": ToThoughtRecordResponseDTO(&record)})
+}
+
+// PUT /me/thought-records/:id: 思考記録の更新API
+func (c *ThoughtRecordController) Update(ctx *gin.Context) {
+	var req SaveThoughtRecordDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	record := req.toDomain(userIDStr)
+	record.ID = ctx.Param("id")
+	if err := c.usecase.Update(ctx.Request.Context(), &record); err != nil {
+		ctx.JSON(thoughtRecordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": ToThoughtRecordResponseDTO(&record)})
+}
+
+// DELETE /me/thought-records/:id: 思考記録の削除API
+func (c *ThoughtRecordController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+
+	if err := c.usecase.Delete(ctx.Request.Context(), userIDStr, ctx.Param("id")); err != nil {
+		ctx.JSON(thoughtRecordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "思考記録を削除しました"}})
+}
@@ -1,9 +1,14 @@
 package controllers
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
 	"tofunote-backend/domain/user"
 	"tofunote-backend/infra"
 
@@ -16,16 +21,26 @@ import (
 type UserController struct {
 	repo            user.Repository
 	withdrawUsecase *usecases.UserWithdrawUsecase // 退会用のみ残す
+	refreshTokens   usecases.IRefreshTokenUsecase
+	// guests はゲストの作成のレート制限・計算課題（nilの場合は制限しない）
+	guests usecases.IGuestAdmissionUsecase
+	// retention は利用のないゲストの自動削除（nilの場合は削除予定日時を返さない）
+	retention usecases.IGuestRetentionUsecase
+	// profiles はプロフィールと設定の取得・更新（nilの場合はGET /meで設定を返さない）
+	profiles usecases.IProfileUsecase
 }
 
-func NewUserController(repo user.Repository, withdrawUsecase *usecases.UserWithdrawUsecase) *UserController {
-	return &UserController{repo: repo, withdrawUsecase: withdrawUsecase}
+func NewUserController(repo user.Repository, withdrawUsecase *usecases.UserWithdrawUsecase, refreshTokens usecases.IRefreshTokenUsecase, guests usecases.IGuestAdmissionUsecase, retention usecases.IGuestRetentionUsecase, profiles usecases.IProfileUsecase) *UserController {
+	return &UserController{repo: repo, withdrawUsecase: withdrawUsecase, refreshTokens: refreshTokens, guests: guests, retention: retention, profiles: profiles}
 }
 
 type GuestLoginResponse struct {
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 	ID           string `json:"id"`
+	// MFARequired は二段階認証が必要なため、トークンの代わりにMFATokenを返したことを表す
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
 }
 
 type RefreshTokenRequest struct {
@@ -34,71 +49,234 @@ type RefreshTokenRequest struct {
 
 type RefreshTokenResponse struct {
 	Token string `json:"token"`
+	// RefreshToken はローテーションで発行した新しいリフレッシュトークン（以前のトークンは使えなくなる）
+	RefreshToken string `json:"refresh_token"`
+}
+
+// accessTokenSubject はユーザーの情報からアクセストークンのクレームの内容を作る
+func accessTokenSubject(u *user.User, sessionID string) infra.AccessTokenSubject {
+	return infra.AccessTokenSubject{UserID: u.ID, SessionID: sessionID, Guest: u.IsGuest, Roles: u.RoleNames()}
+}
+
+var generateTokenForTest = func(u *user.User, sessionID string) (string, error) {
+	return infra.GenerateToken(accessTokenSubject(u, sessionID))
 }
 
-var generateTokenForTest = func(id string) (string, error) {
-	return infra.GenerateToken(id)
+// maxDeviceLabelLength は端末名として保存する最大文字数
+const maxDeviceLabelLength = 100
+
+// clientInfo はリクエストから端末の情報を取り出す（端末名はX-Device-Labelヘッダで任意に指定する）
+func clientInfo(ctx *gin.Context) auth.ClientInfo {
+	label := []rune(strings.TrimSpace(ctx.GetHeader("X-Device-Label")))
+	if len(label) > maxDeviceLabelLength {
+		label = label[:maxDeviceLabelLength]
+	}
+	return auth.ClientInfo{DeviceLabel: string(label), UserAgent: ctx.Request.UserAgent(), IPAddress: ctx.ClientIP()}
 }
 
-func generateRefreshToken() (string, error) {
-	b := make([]byte, 32)
-	_, err := rand.Read(b)
+// issueLoginTokens はログインしたユーザーに新しいリフレッシュトークンを発行し、JWTと合わせて返す（各ログインフローで共通）
+func issueLoginTokens(ctx *gin.Context, refreshTokens usecases.IRefreshTokenUsecase, u *user.User) (*GuestLoginResponse, error) {
+	refreshToken, issued, err := refreshTokens.Issue(ctx.Request.Context(), u.ID, clientInfo(ctx))
+	if err != nil {
+		return nil, err
+	}
+	token, err := generateTokenForTest(u, issued.FamilyID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return base64.URLEncoding.EncodeToString(b), nil
+	return &GuestLoginResponse{Token: token, RefreshToken: refreshToken, ID: u.ID}, nil
 }
 
-// GuestLogin: サーバー側でUUIDとリフレッシュトークンを生成し、ユーザー作成・トークン発行API
-func (c *UserController) GuestLogin(ctx *gin.Context) {
-	id, err := uuid.NewV7()
+// completeLogin は二段階認証が有効なユーザーにはトークンを発行せず、二段階認証の確認トークンを返す（パスワード・外部アカウントでのログインで共通）
+func completeLogin(ctx *gin.Context, refreshTokens usecases.IRefreshTokenUsecase, mfa usecases.ITOTPUsecase, u *user.User) (*GuestLoginResponse, error) {
+	if mfa != nil {
+		mfaToken, err := mfa.BeginLogin(ctx.Request.Context(), u)
+		if err != nil {
+			return nil, err
+		}
+		if mfaToken != "" {
+			return &GuestLoginResponse{ID: u.ID, MFARequired: true, MFAToken: mfaToken}, nil
+		}
+	}
+	return issueLoginTokens(ctx, refreshTokens, u)
+}
+
+// GuestLoginRequest はゲストの作成時に送る計算課題の解答（課題を求める設定の場合のみ必要）
+type GuestLoginRequest struct {
+	Challenge string `json:"challenge"`
+	Nonce     string `json:"nonce"`
+}
+
+type GuestChallengeDTO struct {
+	Required   bool       `json:"required"`
+	Challenge  string     `json:"challenge,omitempty"`
+	Difficulty int        `json:"difficulty,omitempty"`
+	Algorithm  string     `json:"algorithm,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+type GuestCreationMetricsDTO struct {
+	LastHour              int64 `json:"last_hour"`
+	LastDay               int64 `json:"last_day"`
+	DistinctIPsLastHour   int64 `json:"distinct_ips_last_hour"`
+	PerIPLimit            int   `json:"per_ip_limit"`
+	GlobalLimit           int   `json:"global_limit"`
+	WindowSeconds         int   `json:"window_seconds"`
+	ProofOfWorkDifficulty int   `json:"proof_of_work_difficulty"`
+}
+
+// POST /guest-login/challenge: ゲストの作成前に解く計算課題の発行API
+func (c *UserController) GuestChallenge(ctx *gin.Context) {
+	if c.guests == nil {
+		ctx.JSON(http.StatusOK, gin.H{"data": GuestChallengeDTO{}})
+		return
+	}
+	challenge, err := c.guests.IssueChallenge(ctx.Request.Context())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "UUID生成に失敗しました"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "計算課題の発行に失敗しました"})
+		return
+	}
+	res := GuestChallengeDTO{Required: challenge.Required}
+	if challenge.Required {
+		res.Challenge = challenge.Challenge
+		res.Difficulty = challenge.Difficulty
+		res.Algorithm = "sha256"
+		res.ExpiresAt = &challenge.ExpiresAt
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// GET /admin/guest-creations: ゲストの作成数の集計API（管理者）
+func (c *UserController) GuestCreationMetrics(ctx *gin.Context) {
+	if c.guests == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "ゲストの作成の制限は設定されていません"})
+		return
+	}
+	metrics, err := c.guests.Metrics(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "集計に失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": GuestCreationMetricsDTO{
+		LastHour:              metrics.LastHour,
+		LastDay:               metrics.LastDay,
+		DistinctIPsLastHour:   metrics.DistinctIPsLastHour,
+		PerIPLimit:            metrics.Config.PerIPLimit,
+		GlobalLimit:           metrics.Config.GlobalLimit,
+		WindowSeconds:         int(metrics.Config.Window / time.Second),
+		ProofOfWorkDifficulty: metrics.Config.ProofOfWorkDifficulty,
+	}})
+}
+
+// admitGuest はゲストの作成を制限する場合に、レート制限と計算課題の解答を確認する（制限した場合は応答してfalseを返す）
+func (c *UserController) admitGuest(ctx *gin.Context) bool {
+	if c.guests == nil {
+		return true
+	}
+	// 計算課題を求めない設定では、従来どおり本文なしのリクエストを受け付ける
+	var req GuestLoginRequest
+	if ctx.Request.Body != nil {
+		if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+			return false
+		}
+	}
+	err := c.guests.Admit(ctx.Request.Context(), ctx.ClientIP(), &usecases.GuestProof{Challenge: req.Challenge, Nonce: req.Nonce})
+	if err == nil {
+		return true
+	}
+	var limitErr *usecases.GuestRateLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		// 作成を制限した件数はログのメトリクスフィルタで集計する
+		log.Printf("[WARN] GuestLogin: ゲストの作成を制限しました scope=%s", limitErr.Scope)
+		ctx.Header("Retry-After", strconv.Itoa(int(limitErr.RetryAfter/time.Second)))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrProofOfWorkRequired):
+		log.Printf("[WARN] GuestLogin: ゲストの作成を制限しました scope=proof_of_work_required")
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrInvalidProofOfWork):
+		log.Printf("[WARN] GuestLogin: ゲストの作成を制限しました scope=invalid_proof_of_work")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザー作成に失敗しました"})
+	}
+	return false
+}
+
+// GuestLogin: サーバー側でUUIDを生成し、ゲストユーザー作成・トークン発行API
+func (c *UserController) GuestLogin(ctx *gin.Context) {
+	if !c.admitGuest(ctx) {
 		return
 	}
-	newUUID := id.String()
-	refreshToken, err := generateRefreshToken()
+	id, err := uuid.NewV7()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "リフレッシュトークン生成に失敗しました"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "UUID生成に失敗しました"})
 		return
 	}
 	u := &user.User{
-		ID:           newUUID,
-		Nickname:     "ゲスト",
-		IsGuest:      true,
-		RefreshToken: refreshToken,
+		ID:       id.String(),
+		Nickname: "ゲスト",
+		IsGuest:  true,
 	}
 	if err := c.repo.Create(ctx.Request.Context(), u); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザー作成に失敗しました"})
 		return
 	}
-	token, err := generateTokenForTest(u.ID)
+	res, err := issueLoginTokens(ctx, c.refreshTokens, u)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
 		return
 	}
-	ctx.JSON(http.StatusOK, GuestLoginResponse{Token: token, RefreshToken: refreshToken, ID: newUUID})
+	ctx.JSON(http.StatusOK, res)
 }
 
-// RefreshToken: リフレッシュトークンで新しいJWTを発行
+// RefreshToken: リフレッシュトークンを新しいものと交換し、新しいJWTを発行
 func (c *UserController) RefreshToken(ctx *gin.Context) {
 	var req RefreshTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
-	// リフレッシュトークンでユーザー検索
-	u, err := c.repo.FindByRefreshToken(ctx.Request.Context(), req.RefreshToken)
+	refreshToken, rotated, err := c.refreshTokens.Rotate(ctx.Request.Context(), req.RefreshToken, clientInfo(ctx))
+	if err != nil {
+		if errors.Is(err, auth.ErrRefreshTokenReused) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidRefreshToken) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "リフレッシュトークンの更新に失敗しました"})
+		return
+	}
+	// ゲストかどうかなどのクレームは更新時点のユーザー情報から作り直す
+	u, err := c.repo.FindByID(ctx.Request.Context(), rotated.UserID)
 	if err != nil || u == nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
 		return
 	}
-	token, err := infra.GenerateToken(u.ID)
+	token, err := generateTokenForTest(u, rotated.FamilyID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "トークン生成に失敗しました"})
 		return
 	}
-	ctx.JSON(http.StatusOK, RefreshTokenResponse{Token: token})
+	ctx.JSON(http.StatusOK, RefreshTokenResponse{Token: token, RefreshToken: refreshToken})
+}
+
+// Logout: リフレッシュトークンを失効させる（失効済み・不明なトークンでも成功として扱う）
+func (c *UserController) Logout(ctx *gin.Context) {
+	var req RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if err := c.refreshTokens.Revoke(ctx.Request.Context(), req.RefreshToken); err != nil && !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ログアウトに失敗しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ログアウトしました"})
 }
 
 // ユーザー自身によるアカウント削除API
@@ -113,17 +291,24 @@ func (c *UserController) DeleteMe(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
 		return
 	}
-	// 退会ユースケースで一括削除
-	err := c.withdrawUsecase.Withdraw(ctx.Request.Context(), userIDStr)
+	// 退会ユースケースで退会を申請（ゲストは即時に一括削除）
+	withdrawal, err := c.withdrawUsecase.Withdraw(ctx.Request.Context(), userIDStr)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "退会処理に失敗しました: " + err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "ユーザーと日記データを全て削除しました"})
+	if withdrawal.Purged {
+		ctx.JSON(http.StatusOK, gin.H{"message": "ユーザーと日記データを全て削除しました"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":            "退会を受け付けました。削除予定日時までにログインすると退会を取り消せます",
+		"purge_scheduled_at": withdrawal.PurgeScheduledAt,
+	})
 }
 
-// GET /me: ユーザー情報取得API
-func (c *UserController) GetMe(ctx *gin.Context) {
+// POST /me/restore: 猶予期間中の退会の取り消しAPI
+func (c *UserController) RestoreMe(ctx *gin.Context) {
 	userID, exists := ctx.Get("userID")
 	if !exists {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
@@ -134,20 +319,24 @@ func (c *UserController) GetMe(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
 		return
 	}
-	u, err := c.repo.FindByID(ctx.Request.Context(), userIDStr)
-	if err != nil || u == nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーが見つかりません"})
+	if err := c.withdrawUsecase.Restore(ctx.Request.Context(), userIDStr); err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrNotWithdrawn):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrWithdrawalExpired):
+			ctx.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "退会の取り消しに失敗しました"})
+		}
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{
-		"id":       u.ID,
-		"nickname": u.Nickname,
-		// 必要に応じて他の項目も追加
-	})
+	ctx.JSON(http.StatusOK, gin.H{"message": "退会を取り消しました"})
 }
 
-// PATCH /me: ユーザー情報部分更新API
-func (c *UserController) PatchMe(ctx *gin.Context) {
+// GET /me: ユーザー情報取得API
+func (c *UserController) GetMe(ctx *gin.Context) {
 	userID, exists := ctx.Get("userID")
 	if !exists {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
@@ -163,31 +352,73 @@ func (c *UserController) PatchMe(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーが見つかりません"})
 		return
 	}
-	var req map[string]interface{}
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+	res := gin.H{
+		"id":             u.ID,
+		"nickname":       u.Nickname,
+		"email":          u.Email,
+		"email_verified": u.IsEmailVerified(),
+	}
+	if c.profiles != nil {
+		prefs, err := c.profiles.Preferences(ctx.Request.Context(), u.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザー情報の取得に失敗しました"})
+			return
+		}
+		res["preferences"] = prefs
+	}
+	// 退会を申請したユーザーには完全に削除する予定日時を返し、取り消しを案内する
+	if c.withdrawUsecase != nil {
+		if scheduled := c.withdrawUsecase.PurgeScheduledAt(u); scheduled != nil {
+			res["purge_scheduled_at"] = scheduled
+		}
+	}
+	// 利用のないゲストには削除予定日時を返し、アプリ内で予告を表示する
+	if c.retention != nil {
+		if scheduled := c.retention.DeletionScheduledAt(u); scheduled != nil {
+			res["deletion_scheduled_at"] = scheduled
+		}
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// PATCH /me: ユーザー情報部分更新API（JSON Merge Patch。指定しない項目は変更せず、nullの項目は既定値に戻す）
+func (c *UserController) PatchMe(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
 		return
 	}
-	updated := false
-	if nickname, ok := req["nickname"].(string); ok {
-		u.Nickname = nickname
-		updated = true
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
 	}
-	// 他の項目もここで追加可能
-	if !updated {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "更新可能な項目がありません"})
+	var patch user.ProfilePatch
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
 		return
 	}
-	if err := c.repo.Update(ctx.Request.Context(), u); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザー情報の更新に失敗しました"})
+	profile, err := c.profiles.Update(ctx.Request.Context(), userIDStr, patch)
+	if err != nil {
+		var profileErr *user.ProfileError
+		switch {
+		case errors.As(err, &profileErr):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": profileErr.Message, "field": profileErr.Field})
+		case errors.Is(err, usecases.ErrEmptyProfilePatch):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrUserNotFound):
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーが見つかりません"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザー情報の更新に失敗しました"})
+		}
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "ユーザー情報を更新しました",
 		"user": gin.H{
-			"id":       u.ID,
-			"nickname": u.Nickname,
-			// 必要に応じて他の項目も追加
+			"id":          userIDStr,
+			"nickname":    profile.Nickname,
+			"preferences": profile.Preferences,
 		},
 	})
 }
@@ -7,8 +7,11 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"tofunote-backend/domain/auth"
 	"tofunote-backend/domain/user"
+	"tofunote-backend/usecases"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -37,7 +40,7 @@ func (m *mockUserRepo) Create(ctx context.Context, u *user.User) error {
 func (m *mockUserRepo) FindByProviderId(ctx context.Context, provider, providerId string) (*user.User, error) {
 	return nil, nil
 }
-func (m *mockUserRepo) FindByRefreshToken(ctx context.Context, refreshToken string) (*user.User, error) {
+func (m *mockUserRepo) FindByEmail(ctx context.Context, email string) (*user.User, error) {
 	return nil, nil
 }
 func (m *mockUserRepo) Update(ctx context.Context, u *user.User) error {
@@ -54,6 +57,27 @@ func (m *mockUserRepo) setUser(u *user.User) {
 	m.createdUser = u
 }
 
+type fakeRefreshTokenUsecase struct {
+	issuedFor string
+	rotateErr error
+	revoked   string
+}
+
+func (f *fakeRefreshTokenUsecase) Issue(ctx context.Context, userID string, client auth.ClientInfo) (string, *auth.RefreshToken, error) {
+	f.issuedFor = userID
+	return "dummy-refresh-token", &auth.RefreshToken{UserID: userID, DeviceLabel: client.DeviceLabel}, nil
+}
+func (f *fakeRefreshTokenUsecase) Rotate(ctx context.Context, token string, client auth.ClientInfo) (string, *auth.RefreshToken, error) {
+	if f.rotateErr != nil {
+		return "", nil, f.rotateErr
+	}
+	return "rotated-refresh-token", &auth.RefreshToken{UserID: "user-1"}, nil
+}
+func (f *fakeRefreshTokenUsecase) Revoke(ctx context.Context, token string) error {
+	f.revoked = token
+	return nil
+}
+
 func TestGuestLogin_TableDriven(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -94,14 +118,15 @@ func TestGuestLogin_TableDriven(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			generateTokenForTest = func(id string) (string, error) {
+			generateTokenForTest = func(u *user.User, sessionID string) (string, error) {
 				if tt.mockTokenErr {
 					return "", errors.New("fail")
 				}
 				return "dummy-token", nil
 			}
 
-			uc := NewUserController(tt.repo, nil) // withdrawUsecaseは不要なためnilでOK
+			refreshTokens := &fakeRefreshTokenUsecase{}
+			uc := NewUserController(tt.repo, nil, refreshTokens, nil, nil, nil) // withdrawUsecaseは不要なためnilでOK
 			r := gin.New()
 			r.POST("/api/guest-login", func(c *gin.Context) {
 				uc.GuestLogin(c)
@@ -117,6 +142,7 @@ func TestGuestLogin_TableDriven(t *testing.T) {
 				assert.NotEmpty(t, tt.repo.createdUser.ID)
 				assert.True(t, tt.repo.createdUser.IsGuest)
 				assert.Equal(t, "ゲスト", tt.repo.createdUser.Nickname)
+				assert.Equal(t, tt.repo.createdUser.ID, refreshTokens.issuedFor)
 			} else {
 				assert.Nil(t, tt.repo.createdUser)
 			}
@@ -125,6 +151,156 @@ func TestGuestLogin_TableDriven(t *testing.T) {
 	}
 }
 
+func TestRefreshToken_TableDriven(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		rotateErr  error
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "正常系: 新しいリフレッシュトークンと交換",
+			body:       `{"refresh_token": "current"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   "rotated-refresh-token",
+		},
+		{
+			name:       "異常系: 不正なリフレッシュトークン",
+			rotateErr:  auth.ErrInvalidRefreshToken,
+			body:       `{"refresh_token": "unknown"}`,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "invalid refresh token",
+		},
+		{
+			name:       "異常系: 交換済みのリフレッシュトークンの再利用",
+			rotateErr:  auth.ErrRefreshTokenReused,
+			body:       `{"refresh_token": "rotated"}`,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   auth.ErrRefreshTokenReused.Error(),
+		},
+		{
+			name:       "異常系: リフレッシュトークンなし",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid request",
+		},
+	}
+
+	origGenerateToken := generateTokenForTest
+	defer func() { generateTokenForTest = origGenerateToken }()
+	var tokenFor *user.User
+	generateTokenForTest = func(u *user.User, sessionID string) (string, error) {
+		tokenFor = u
+		return "token-for-" + u.ID, nil
+	}
+	repo := &mockUserRepo{FindByIDFunc: func(ctx context.Context, id string) (*user.User, error) {
+		return &user.User{ID: id, IsGuest: true}, nil
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenFor = nil
+			uc := NewUserController(repo, nil, &fakeRefreshTokenUsecase{rotateErr: tt.rotateErr}, nil, nil, nil)
+			r := gin.New()
+			r.POST("/api/refresh-token", uc.RefreshToken)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/refresh-token", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), "token-for-user-1")
+				// ゲストかどうかは更新時点のユーザー情報からクレームに含める
+				assert.True(t, tokenFor.IsGuest)
+			}
+		})
+	}
+}
+
+func TestRefreshToken_UserNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	uc := NewUserController(&mockUserRepo{}, nil, &fakeRefreshTokenUsecase{}, nil, nil, nil)
+	r := gin.New()
+	r.POST("/api/refresh-token", uc.RefreshToken)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/refresh-token", strings.NewReader(`{"refresh_token": "current"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	refreshTokens := &fakeRefreshTokenUsecase{}
+	uc := NewUserController(&mockUserRepo{}, nil, refreshTokens, nil, nil, nil)
+	r := gin.New()
+	r.POST("/api/logout", uc.Logout)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/logout", strings.NewReader(`{"refresh_token": "current"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "current", refreshTokens.revoked)
+}
+
+type fakeGuestAdmissionUsecase struct {
+	admitErr error
+	admitted int
+}
+
+func (f *fakeGuestAdmissionUsecase) IssueChallenge(ctx context.Context) (*usecases.GuestChallenge, error) {
+	return &usecases.GuestChallenge{}, nil
+}
+func (f *fakeGuestAdmissionUsecase) Admit(ctx context.Context, ipAddress string, proof *usecases.GuestProof) error {
+	if f.admitErr != nil {
+		return f.admitErr
+	}
+	f.admitted++
+	return nil
+}
+func (f *fakeGuestAdmissionUsecase) Metrics(ctx context.Context) (*usecases.GuestCreationMetrics, error) {
+	return &usecases.GuestCreationMetrics{}, nil
+}
+
+func TestGuestLogin_Admission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		admitErr       error
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{name: "正常系: 制限内", wantStatus: http.StatusOK},
+		{name: "異常系: レート制限", admitErr: &usecases.GuestRateLimitError{Scope: "ip", RetryAfter: 90 * time.Second}, wantStatus: http.StatusTooManyRequests, wantRetryAfter: "90"},
+		{name: "異常系: 計算課題の解答がない", admitErr: auth.ErrProofOfWorkRequired, wantStatus: http.StatusPreconditionRequired},
+		{name: "異常系: 計算課題の解答が誤り", admitErr: auth.ErrInvalidProofOfWork, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockUserRepo{}
+			guests := &fakeGuestAdmissionUsecase{admitErr: tt.admitErr}
+			uc := NewUserController(repo, nil, &fakeRefreshTokenUsecase{}, guests, nil, nil)
+			r := gin.New()
+			r.POST("/api/guest-login", uc.GuestLogin)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/guest-login", nil)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantRetryAfter, w.Header().Get("Retry-After"))
+			if tt.admitErr != nil {
+				assert.Nil(t, repo.createdUser, "制限した場合はユーザーを作成しない")
+			} else {
+				assert.Equal(t, 1, guests.admitted)
+			}
+		})
+	}
+}
+
 func TestGetMe_TableDriven(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -173,7 +349,7 @@ func TestGetMe_TableDriven(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &mockUserRepo{FindByIDFunc: tt.fields.findByIDFunc}
-			uc := NewUserController(mockRepo, nil)
+			uc := NewUserController(mockRepo, nil, nil, nil, nil, nil)
 			r := gin.New()
 			r.GET("/api/me", func(c *gin.Context) {
 				if tt.userID != nil {
@@ -190,6 +366,118 @@ func TestGetMe_TableDriven(t *testing.T) {
 	}
 }
 
+func TestGetMe_DeletionScheduled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	noticedAt := time.Date(2025, 5, 1, 3, 0, 0, 0, time.UTC)
+	mockRepo := &mockUserRepo{FindByIDFunc: func(ctx context.Context, id string) (*user.User, error) {
+		return &user.User{ID: id, IsGuest: true, DeletionNoticeAt: &noticedAt}, nil
+	}}
+	retention := usecases.NewGuestRetentionUsecase(nil, nil, nil, nil, usecases.DefaultGuestRetentionConfig())
+	uc := NewUserController(mockRepo, nil, nil, nil, retention, nil)
+	r := gin.New()
+	r.GET("/api/me", func(c *gin.Context) {
+		c.Set("userID", "guest-id")
+		uc.GetMe(c)
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/me", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deletion_scheduled_at":"2025-05-15T03:00:00Z"`)
+}
+
+func TestRestoreMe_TableDriven(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	withdrawnAt := time.Now().Add(-time.Hour)
+	expiredAt := time.Now().Add(-usecases.WithdrawalGracePeriod - time.Hour)
+
+	tests := []struct {
+		name        string
+		withdrawnAt *time.Time
+		wantStatus  int
+	}{
+		{name: "正常系: 猶予期間中の退会を取り消す", withdrawnAt: &withdrawnAt, wantStatus: http.StatusOK},
+		{name: "異常系: 退会を申請していない", withdrawnAt: nil, wantStatus: http.StatusConflict},
+		{name: "異常系: 猶予期間を過ぎている", withdrawnAt: &expiredAt, wantStatus: http.StatusGone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated *user.User
+			repo := &mockUserRepo{
+				FindByIDFunc: func(ctx context.Context, id string) (*user.User, error) {
+					return &user.User{ID: id, WithdrawnAt: tt.withdrawnAt}, nil
+				},
+				UpdateFunc: func(ctx context.Context, u *user.User) error {
+					updated = u
+					return nil
+				},
+			}
+			uc := NewUserController(repo, usecases.NewUserWithdrawUsecase(directUnitOfWork{}, repo, nil, nil, nil, nil, nil), nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/api/me/restore", func(c *gin.Context) {
+				c.Set("userID", "member-id")
+				uc.RestoreMe(c)
+			})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/me/restore", nil)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.False(t, updated.IsWithdrawn())
+			}
+		})
+	}
+}
+
+// directUnitOfWork はトランザクションを使わずにfnを実行するUnitOfWork
+type directUnitOfWork struct{}
+
+func (directUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// memoryPreferencesRepo は設定をメモリ上に保存するPreferencesRepository
+type memoryPreferencesRepo struct {
+	prefs map[string]user.Preferences
+}
+
+func (m *memoryPreferencesRepo) FindByUserID(ctx context.Context, userID string) (*user.Preferences, error) {
+	p, ok := m.prefs[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (m *memoryPreferencesRepo) Save(ctx context.Context, userID string, p *user.Preferences) error {
+	m.prefs[userID] = *p
+	return nil
+}
+
+func (m *memoryPreferencesRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	delete(m.prefs, userID)
+	return nil
+}
+
+func (m *memoryPreferencesRepo) FindAnalysisOptedInUserIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	for id, p := range m.prefs {
+		if p.AnalysisOptIn {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+func (m *memoryPreferencesRepo) FindRemindable(ctx context.Context) ([]user.RemindableUser, error) {
+	var users []user.RemindableUser
+	for userID, p := range m.prefs {
+		if len(p.ReminderTimes) > 0 {
+			users = append(users, user.RemindableUser{UserID: userID, Preferences: p})
+		}
+	}
+	return users, nil
+}
+
 func TestPatchMe_TableDriven(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -267,6 +555,42 @@ func TestPatchMe_TableDriven(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantBody:   "更新可能な項目がありません",
 		},
+		{
+			name: "正常系: 設定の部分更新",
+			fields: fields{
+				findByIDFunc: func(ctx context.Context, id string) (*user.User, error) {
+					return &user.User{ID: id, Nickname: "旧名"}, nil
+				},
+			},
+			userID:     "test-id",
+			body:       `{"preferences": {"timezone": "Europe/London", "reminder_times": ["21:00"], "theme": null}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"preferences":{"timezone":"Europe/London","locale":"ja-JP","first_day_of_week":0,"mood_scale":10,"reminder_times":["21:00"],"reminder_weekdays":[],"notification_channels":["web_push"],"analysis_opt_in":false,"theme":"system"}`,
+		},
+		{
+			name: "異常系: 設定の検証エラー",
+			fields: fields{
+				findByIDFunc: func(ctx context.Context, id string) (*user.User, error) {
+					return &user.User{ID: id, Nickname: "旧名"}, nil
+				},
+			},
+			userID:     "test-id",
+			body:       `{"preferences": {"mood_scale": 7}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"field":"preferences.mood_scale"`,
+		},
+		{
+			name: "異常系: 型が不正",
+			fields: fields{
+				findByIDFunc: func(ctx context.Context, id string) (*user.User, error) {
+					return &user.User{ID: id, Nickname: "旧名"}, nil
+				},
+			},
+			userID:     "test-id",
+			body:       `{"preferences": {"analysis_opt_in": "yes"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "無効なリクエストデータです",
+		},
 	}
 
 	for _, tt := range tests {
@@ -275,7 +599,7 @@ func TestPatchMe_TableDriven(t *testing.T) {
 				FindByIDFunc: tt.fields.findByIDFunc,
 				UpdateFunc:   tt.fields.updateFunc,
 			}
-			uc := NewUserController(mockRepo, nil)
+			uc := NewUserController(mockRepo, nil, nil, nil, nil, usecases.NewProfileUsecase(directUnitOfWork{}, mockRepo, &memoryPreferencesRepo{prefs: map[string]user.Preferences{}}))
 			r := gin.New()
 			r.PATCH("/api/me", func(c *gin.Context) {
 				if tt.userID != nil {
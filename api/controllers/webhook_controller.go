@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"tofunote-backend/domain/webhook"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	usecase usecases.IWebhookUsecase
+}
+
+func NewWebhookController(usecase usecases.IWebhookUsecase) *WebhookController {
+	return &WebhookController{usecase: usecase}
+}
+
+type CreateWebhookDTO struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events" binding:"required"`
+	Description string   `json:"description"`
+}
+
+type WebhookResponseDTO struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CreatedWebhookDTO struct {
+	WebhookResponseDTO
+	// Secret は署名の検証に使う鍵（この応答でのみ返す）
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryResponseDTO は配送の記録（送信したペイロードは返さない）
+type WebhookDeliveryResponseDTO struct {
+	ID             string     `json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func toWebhookResponseDTO(e *webhook.Endpoint) WebhookResponseDTO {
+	events := make([]string, len(e.Events))
+	for i, event := range e.Events {
+		events[i] = string(event)
+	}
+	return WebhookResponseDTO{ID: e.ID, URL: e.URL, Events: events, Description: e.Description, CreatedAt: e.CreatedAt}
+}
+
+func toWebhookDeliveryResponseDTO(d *webhook.Delivery) WebhookDeliveryResponseDTO {
+	res := WebhookDeliveryResponseDTO{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	// 次の送信日時は送信待ちの場合のみ返す
+	if d.Status == webhook.DeliveryPending {
+		next := d.NextAttemptAt
+		res.NextAttemptAt = &next
+	}
+	return res
+}
+
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidURL), errors.Is(err, webhook.ErrInvalidEventType), errors.Is(err, usecases.ErrInvalidWebhookDescription):
+		return http.StatusBadRequest
+	case errors.Is(err, webhook.ErrEndpointNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrTooManyWebhooks):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GET /me/webhooks: 登録したWebhookの一覧取得API（署名の鍵は返さない）
+func (c *WebhookController) FindAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	endpoints, err := c.usecase.List(ctx.Request.Context(), userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Webhookの取得に失敗しました"})
+		return
+	}
+	res := make([]WebhookResponseDTO, len(endpoints))
+	for i := range endpoints {
+		res[i] = toWebhookResponseDTO(&endpoints[i])
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// POST /me/webhooks: Webhookの登録API（署名の鍵を返すのはこの1回のみ）
+func (c *WebhookController) Create(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	var req CreateWebhookDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		return
+	}
+	events, err := webhook.ParseEventTypes(req.Events)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	endpoint, err := c.usecase.Create(ctx.Request.Context(), userIDStr, req.URL, events, req.Description)
+	if err != nil {
+		ctx.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": CreatedWebhookDTO{
+		WebhookResponseDTO: toWebhookResponseDTO(endpoint),
+		Secret:             endpoint.Secret,
+	}})
+}
+
+// DELETE /me/webhooks/:id: Webhookの削除API（送信待ちの配送も送らない）
+func (c *WebhookController) Delete(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	if err := c.usecase.Delete(ctx.Request.Context(), userIDStr, ctx.Param("id")); err != nil {
+		ctx.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Webhookを削除しました"})
+}
+
+// GET /me/webhooks/:id/deliveries: Webhookの配送の記録の取得API（新しい順）
+func (c *WebhookController) FindDeliveries(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	limit, offset, err := parsePaging(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	deliveries, err := c.usecase.ListDeliveries(ctx.Request.Context(), userIDStr, ctx.Param("id"), limit, offset)
+	if err != nil {
+		ctx.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	res := make([]WebhookDeliveryResponseDTO, len(deliveries))
+	for i, d := range deliveries {
+		res[i] = toWebhookDeliveryResponseDTO(d)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// POST /me/webhooks/:id/ping: 受信側の確認のためのテスト送信API（その場で1回だけ送り、結果を返す）
+func (c *WebhookController) Ping(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証情報が見つかりません"})
+		return
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーIDの形式が不正です"})
+		return
+	}
+	delivery, err := c.usecase.Ping(ctx.Request.Context(), userIDStr, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": toWebhookDeliveryResponseDTO(delivery)})
+}
@@ -0,0 +1,56 @@
+// 日記リマインダーの送信ジョブ。cron等から定期実行し、直近 -window の間にリマインダーの時刻を迎え、
+// 今日の日記をまだ書いていないユーザーに、設定で選んだ通知チャネル（Web Push・メール・Webhook）で通知する
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	notificationdomain "tofunote-backend/domain/notification"
+	"tofunote-backend/infra"
+	"tofunote-backend/infra/mail"
+	"tofunote-backend/infra/notification"
+	"tofunote-backend/infra/webhook"
+	"tofunote-backend/infra/webpush"
+	"tofunote-backend/repositories"
+	"tofunote-backend/usecases"
+)
+
+func main() {
+	window := flag.Duration("window", 15*time.Minute, "リマインダーの対象とする直近の期間（ジョブの実行間隔と合わせる）")
+	flag.Parse()
+
+	infra.Initialize()
+	dbConn := infra.SetupDB()
+
+	preferencesRepository := repositories.NewUserPreferencesRepository(dbConn)
+	// Webhookへの通知は配送キューに追加し、webhook-dispatcherが署名して送る
+	webhookEndpointRepository := repositories.NewWebhookEndpointRepository(dbConn)
+	senders := []notificationdomain.Sender{
+		notification.NewEmailSender(repositories.NewUserRepository(dbConn), mail.MailerFromEnv()),
+		notification.NewWebhookSender(webhookEndpointRepository, usecases.NewWebhookUsecase(webhookEndpointRepository, repositories.NewWebhookDeliveryRepository(dbConn), webhook.NewHTTPTransport(nil))),
+	}
+	vapidKeys, err := webpush.KeysFromEnv()
+	if err != nil {
+		log.Fatalf("[ERROR] VAPIDの鍵の読み込みに失敗しました: %v", err)
+	}
+	if vapidKeys != nil {
+		senders = append(senders, webpush.NewSender(vapidKeys, repositories.NewPushSubscriptionRepository(dbConn), nil))
+	} else {
+		log.Printf("[INFO] VAPID_PRIVATE_KEYが未設定のため、Web Pushでは通知しません")
+	}
+
+	reminderUsecase := usecases.NewDiaryReminderUsecase(
+		preferencesRepository,
+		repositories.NewDiaryRepository(dbConn),
+		usecases.NewNotificationUsecase(preferencesRepository, senders...),
+	)
+	now := time.Now()
+	sent, err := reminderUsecase.SendDueReminders(context.Background(), now.Add(-*window), now)
+	if err != nil {
+		log.Fatalf("[ERROR] 日記リマインダーの送信に失敗しました: %v", err)
+	}
+	log.Printf("[INFO] 日記リマインダーを%d件送信しました", sent)
+}
@@ -0,0 +1,53 @@
+// ロールの付与・取り消しを行う管理用コマンド。最初の管理者は管理APIを使えないため、このコマンドで付与する
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra"
+	"tofunote-backend/repositories"
+)
+
+func main() {
+	userID := flag.String("user", "", "ロールを付与・取り消すユーザーのID")
+	roleName := flag.String("role", string(user.RoleAdmin), "付与・取り消すロール")
+	revoke := flag.Bool("revoke", false, "付与の代わりに取り消す")
+	flag.Parse()
+
+	if *userID == "" {
+		log.Fatalf("[ERROR] -user でユーザーIDを指定してください")
+	}
+	role, err := user.ParseRole(*roleName)
+	if err != nil {
+		log.Fatalf("[ERROR] %v: %s", err, *roleName)
+	}
+
+	infra.Initialize()
+	dbConn := infra.SetupDB()
+	ctx := context.Background()
+
+	target, err := repositories.NewUserRepository(dbConn).FindByID(ctx, *userID)
+	if err != nil {
+		log.Fatalf("[ERROR] ユーザーの取得に失敗しました: %v", err)
+	}
+	if target == nil {
+		log.Fatalf("[ERROR] ユーザーが見つかりません: %s", *userID)
+	}
+
+	roleRepository := repositories.NewRoleRepository(dbConn)
+	if *revoke {
+		if err := roleRepository.Revoke(ctx, target.ID, role); err != nil {
+			log.Fatalf("[ERROR] ロールの取り消しに失敗しました: %v", err)
+		}
+		log.Printf("[INFO] %sのロール%sを取り消しました", target.ID, role)
+		return
+	}
+	if err := roleRepository.Grant(ctx, target.ID, role); err != nil {
+		log.Fatalf("[ERROR] ロールの付与に失敗しました: %v", err)
+	}
+	// 付与したロールは次回のログインまたはトークンの更新で発行するアクセストークンに含まれる
+	log.Printf("[INFO] %sにロール%sを付与しました", target.ID, role)
+}
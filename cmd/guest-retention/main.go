@@ -0,0 +1,107 @@
+// 利用のないゲストアカウントの自動削除ジョブ。cron等から1日1回実行し、利用のないゲストに削除を予告して、
+// 猶予期間を過ぎても利用のないゲストを日記と合わせて削除する。
+// Lambda（EventBridgeのスケジュール）として実行する場合は、入力の {"dry_run": true} でドライランにする
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"tofunote-backend/infra"
+	"tofunote-backend/infra/notification"
+	"tofunote-backend/repositories"
+	"tofunote-backend/usecases"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// retentionEvent はLambdaとして実行する場合の入力
+type retentionEvent struct {
+	DryRun bool `json:"dry_run"`
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "削除・予告せずに対象のゲストを表示する")
+	flag.Parse()
+
+	infra.Initialize()
+	retentionUsecase := newGuestRetentionUsecase()
+
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		lambda.Start(func(ctx context.Context, event retentionEvent) (*usecases.GuestRetentionReport, error) {
+			return run(ctx, retentionUsecase, event.DryRun)
+		})
+		return
+	}
+	if _, err := run(context.Background(), retentionUsecase, *dryRun); err != nil {
+		log.Fatalf("[ERROR] ゲストの自動削除に失敗しました: %v", err)
+	}
+}
+
+func newGuestRetentionUsecase() usecases.IGuestRetentionUsecase {
+	config, err := usecases.GuestRetentionConfigFromEnv()
+	if err != nil {
+		log.Fatalf("[ERROR] ゲストの自動削除の設定が不正です: %v", err)
+	}
+	dbConn := infra.SetupDB()
+	guestRetentionRepository := repositories.NewGuestRetentionRepository(dbConn)
+	refreshTokenRepository := repositories.NewRefreshTokenRepository(dbConn)
+	sessionRepository := repositories.NewSessionRepository(dbConn)
+	personalAccessTokenRepository := repositories.NewPersonalAccessTokenRepository(dbConn)
+	withdrawUsecase := usecases.NewUserWithdrawUsecase(
+		repositories.NewUnitOfWork(dbConn),
+		repositories.NewUserRepository(dbConn),
+		guestRetentionRepository,
+		usecases.NewSessionUsecase(sessionRepository, refreshTokenRepository, infra.AccessTokenTTL, usecases.DefaultRevocationRefreshInterval),
+		personalAccessTokenRepository,
+		usecases.NewAuditUsecase(repositories.NewAuditEventRepository(dbConn)),
+		repositories.NewDiaryRepository(dbConn),
+		repositories.NewEmotionLabelRepository(dbConn),
+		repositories.NewFactorRepository(dbConn),
+		repositories.NewFactorDefinitionRepository(dbConn),
+		repositories.NewDoseLogRepository(dbConn),
+		repositories.NewMedicationRepository(dbConn),
+		repositories.NewThoughtRecordRepository(dbConn),
+		repositories.NewGratitudeRepository(dbConn),
+		repositories.NewOneTimeTokenRepository(dbConn),
+		refreshTokenRepository,
+		sessionRepository,
+		repositories.NewPasskeyRepository(dbConn),
+		repositories.NewPasskeyChallengeRepository(dbConn),
+		repositories.NewTOTPRepository(dbConn),
+		repositories.NewRecoveryCodeRepository(dbConn),
+		repositories.NewRoleRepository(dbConn),
+		personalAccessTokenRepository,
+		repositories.NewUserPreferencesRepository(dbConn),
+		repositories.NewPushSubscriptionRepository(dbConn),
+		repositories.NewWebhookDeliveryRepository(dbConn),
+		repositories.NewWebhookEndpointRepository(dbConn),
+	)
+	return usecases.NewGuestRetentionUsecase(
+		guestRetentionRepository,
+		repositories.NewGuestCreationRepository(dbConn),
+		withdrawUsecase,
+		notification.NewLogNotifier(),
+		config,
+	)
+}
+
+func run(ctx context.Context, retentionUsecase usecases.IGuestRetentionUsecase, dryRun bool) (*usecases.GuestRetentionReport, error) {
+	report, err := retentionUsecase.Run(ctx, dryRun)
+	if err != nil {
+		return report, err
+	}
+	if dryRun {
+		log.Printf("[INFO] ドライラン: 削除を予告するゲスト%d件 %v", len(report.Noticed), report.Noticed)
+		log.Printf("[INFO] ドライラン: 削除するゲスト%d件 %v", len(report.Deleted), report.Deleted)
+		return report, nil
+	}
+	log.Printf("[INFO] ゲストの自動削除: 予告%d件・削除%d件・失敗%d件・作成の記録の削除%d件",
+		len(report.Noticed), len(report.Deleted), len(report.Failed), report.PrunedGuestCreations)
+	if len(report.Failed) > 0 {
+		log.Printf("[WARN] ゲストの削除に失敗しました（次回の実行で再度削除します） %v", report.Failed)
+	}
+	return report, nil
+}
@@ -1,7 +1,17 @@
 package main
 
 import (
+	"log"
+	"os"
 	"tofunote-backend/infra"
+	"tofunote-backend/infra/authtoken"
+	"tofunote-backend/infra/mail"
+	"tofunote-backend/infra/notification"
+	"tofunote-backend/infra/oidc"
+	"tofunote-backend/infra/password"
+	"tofunote-backend/infra/webauthn"
+	"tofunote-backend/infra/webhook"
+	"tofunote-backend/infra/webpush"
 	"tofunote-backend/routes"
 	"tofunote-backend/routes/middleware"
 
@@ -14,30 +24,148 @@ import (
 
 func main() {
 	infra.Initialize()
+	// 本番環境で署名鍵が未設定・既定値・弱い値の場合は起動しない
+	if err := infra.InitJWT(); err != nil {
+		log.Fatalf("JWTの署名鍵の設定が不正です: %v", err)
+	}
+	flowSecret, err := oidc.FlowSecretFromEnv()
+	if err != nil {
+		log.Fatalf("OIDCの署名鍵の設定が不正です: %v", err)
+	}
+	ticketSecret, err := authtoken.SecretFromEnv()
+	if err != nil {
+		log.Fatalf("認証用トークンの署名鍵の設定が不正です: %v", err)
+	}
 	dbConn := infra.SetupDB()
-	middleware.SetAuthDB(dbConn)
 
 	diaryRepository := repositories.NewDiaryRepository(dbConn)
-	diaryUsecase := usecases.NewDiaryUsecase(diaryRepository)
+	emotionLabelRepository := repositories.NewEmotionLabelRepository(dbConn)
+	userPreferencesRepository := repositories.NewUserPreferencesRepository(dbConn)
+	webhookEndpointRepository := repositories.NewWebhookEndpointRepository(dbConn)
+	webhookDeliveryRepository := repositories.NewWebhookDeliveryRepository(dbConn)
+	webhookUsecase := usecases.NewWebhookUsecase(webhookEndpointRepository, webhookDeliveryRepository, webhook.NewHTTPTransport(nil))
+	webhookController := controllers.NewWebhookController(webhookUsecase)
+	auditUsecase := usecases.NewAuditUsecase(repositories.NewAuditEventRepository(dbConn))
+	middleware.SetAuthFailureRecorder(auditUsecase)
+	auditController := controllers.NewAuditController(auditUsecase)
+	diaryUsecase := usecases.NewDiaryUsecase(diaryRepository, emotionLabelRepository, userPreferencesRepository, webhookUsecase)
 	diaryController := controllers.NewDiaryController(diaryUsecase)
 
-	diaryAnalysisUsecase := usecases.NewDiaryAnalysisUsecase(diaryRepository)
+	emotionUsecase := usecases.NewEmotionUsecase(emotionLabelRepository)
+	emotionController := controllers.NewEmotionController(emotionUsecase)
+
+	factorRepository := repositories.NewFactorRepository(dbConn)
+	factorDefinitionRepository := repositories.NewFactorDefinitionRepository(dbConn)
+	factorUsecase := usecases.NewFactorUsecase(factorRepository, factorDefinitionRepository)
+	factorController := controllers.NewFactorController(factorUsecase)
+
+	diaryStatsUsecase := usecases.NewDiaryStatsUsecase(diaryRepository, factorRepository, factorDefinitionRepository)
+	diaryStatsController := controllers.NewDiaryStatsController(diaryStatsUsecase, emotionUsecase)
+
+	medicationRepository := repositories.NewMedicationRepository(dbConn)
+	doseLogRepository := repositories.NewDoseLogRepository(dbConn)
+	medicationUsecase := usecases.NewMedicationUsecase(medicationRepository, doseLogRepository, diaryRepository, userPreferencesRepository)
+	medicationController := controllers.NewMedicationController(medicationUsecase)
+
+	thoughtRecordRepository := repositories.NewThoughtRecordRepository(dbConn)
+	thoughtRecordUsecase := usecases.NewThoughtRecordUsecase(thoughtRecordRepository, emotionLabelRepository)
+	thoughtRecordController := controllers.NewThoughtRecordController(thoughtRecordUsecase)
+
+	gratitudeRepository := repositories.NewGratitudeRepository(dbConn)
+	gratitudeUsecase := usecases.NewGratitudeUsecase(gratitudeRepository, diaryRepository, userPreferencesRepository)
+	gratitudeController := controllers.NewGratitudeController(gratitudeUsecase)
+
+	exportUsecase := usecases.NewExportUsecase(diaryRepository, emotionLabelRepository, factorRepository, medicationRepository, doseLogRepository, thoughtRecordRepository, gratitudeRepository, auditUsecase)
+	exportController := controllers.NewExportController(exportUsecase)
+
+	diaryAnalysisUsecase := usecases.NewDiaryAnalysisUsecase(diaryRepository, thoughtRecordRepository, userPreferencesRepository, webhookUsecase)
 	diaryAnalysisController := controllers.NewDiaryAnalysisController(diaryAnalysisUsecase)
 
 	userRepo := repositories.NewUserRepository(dbConn)
-	withdrawUsecase := usecases.NewUserWithdrawUsecase(userRepo, diaryRepository)
-	userController := controllers.NewUserController(userRepo, withdrawUsecase)
+	oneTimeTokenRepository := repositories.NewOneTimeTokenRepository(dbConn)
+	refreshTokenRepository := repositories.NewRefreshTokenRepository(dbConn)
+	sessionRepository := repositories.NewSessionRepository(dbConn)
+	passkeyRepository := repositories.NewPasskeyRepository(dbConn)
+	passkeyChallengeRepository := repositories.NewPasskeyChallengeRepository(dbConn)
+	totpRepository := repositories.NewTOTPRepository(dbConn)
+	recoveryCodeRepository := repositories.NewRecoveryCodeRepository(dbConn)
+	roleRepository := repositories.NewRoleRepository(dbConn)
+	personalAccessTokenRepository := repositories.NewPersonalAccessTokenRepository(dbConn)
+	pushSubscriptionRepository := repositories.NewPushSubscriptionRepository(dbConn)
+	sessionUsecase := usecases.NewSessionUsecase(sessionRepository, refreshTokenRepository, infra.AccessTokenTTL, usecases.DefaultRevocationRefreshInterval)
+	middleware.SetSessionRevocations(sessionUsecase)
+	sessionController := controllers.NewSessionController(sessionUsecase)
+	refreshTokenUsecase := usecases.NewRefreshTokenUsecase(refreshTokenRepository, sessionUsecase, auditUsecase, usecases.DefaultRefreshTokenTTL)
+	totpUsecase := usecases.NewTOTPUsecase(totpRepository, recoveryCodeRepository, userRepo, authtoken.NewSignedMFAChallengeStore(ticketSecret, authtoken.DefaultMFAChallengeTTL), os.Getenv("TOTP_ISSUER"))
+	mfaController := controllers.NewMFAController(totpUsecase, refreshTokenUsecase)
+	guestRetentionRepository := repositories.NewGuestRetentionRepository(dbConn)
+	withdrawUsecase := usecases.NewUserWithdrawUsecase(repositories.NewUnitOfWork(dbConn), userRepo, guestRetentionRepository, sessionUsecase, personalAccessTokenRepository, auditUsecase, diaryRepository, emotionLabelRepository, factorRepository, factorDefinitionRepository, doseLogRepository, medicationRepository, thoughtRecordRepository, gratitudeRepository, oneTimeTokenRepository, refreshTokenRepository, sessionRepository, passkeyRepository, passkeyChallengeRepository, totpRepository, recoveryCodeRepository, roleRepository, personalAccessTokenRepository, userPreferencesRepository, pushSubscriptionRepository, webhookDeliveryRepository, webhookEndpointRepository)
+	guestAdmissionConfig, err := usecases.GuestAdmissionConfigFromEnv()
+	if err != nil {
+		log.Fatalf("ゲストの作成の制限の設定に失敗しました: %v", err)
+	}
+	guestAdmissionUsecase := usecases.NewGuestAdmissionUsecase(repositories.NewGuestCreationRepository(dbConn), authtoken.NewSignedProofOfWorkChallengeStore(ticketSecret, authtoken.DefaultProofOfWorkTTL), guestAdmissionConfig)
+	guestRetentionConfig, err := usecases.GuestRetentionConfigFromEnv()
+	if err != nil {
+		log.Fatalf("ゲストの自動削除の設定に失敗しました: %v", err)
+	}
+	guestRetentionUsecase := usecases.NewGuestRetentionUsecase(guestRetentionRepository, repositories.NewGuestCreationRepository(dbConn), withdrawUsecase, notification.NewLogNotifier(), guestRetentionConfig)
+	middleware.SetLastSeenRecorder(guestRetentionUsecase)
+	profileUsecase := usecases.NewProfileUsecase(repositories.NewUnitOfWork(dbConn), userRepo, userPreferencesRepository)
+	userController := controllers.NewUserController(userRepo, withdrawUsecase, refreshTokenUsecase, guestAdmissionUsecase, guestRetentionUsecase, profileUsecase)
+	adminUsecase := usecases.NewAdminUsecase(repositories.NewUserDirectory(dbConn), userRepo, roleRepository, repositories.NewAdminStatsRepository(dbConn), repositories.NewAdminActionLogRepository(dbConn), diaryRepository, withdrawUsecase, diaryAnalysisUsecase, auditUsecase)
+	middleware.SetRoleVerifier(adminUsecase)
+	adminController := controllers.NewAdminController(adminUsecase)
+	personalAccessTokenUsecase := usecases.NewPersonalAccessTokenUsecase(personalAccessTokenRepository, userRepo)
+	middleware.SetPersonalAccessTokens(personalAccessTokenUsecase)
+	personalAccessTokenController := controllers.NewPersonalAccessTokenController(personalAccessTokenUsecase)
+
+	oidcProviders, err := oidc.ProvidersFromEnv()
+	if err != nil {
+		log.Fatalf("OIDCプロバイダの設定に失敗しました: %v", err)
+	}
+	oidcLoginUsecase := usecases.NewOIDCLoginUsecase(oidcProviders, oidc.NewSignedFlowStore(flowSecret, oidc.DefaultFlowTTL), userRepo)
+	oidcController := controllers.NewOIDCController(oidcLoginUsecase, refreshTokenUsecase, totpUsecase, os.Getenv("OIDC_FRONTEND_CALLBACK_URL"))
+	argon2Params, err := password.ParamsFromEnv()
+	if err != nil {
+		log.Fatalf("パスワードハッシュの設定に失敗しました: %v", err)
+	}
+	passwordAuthUsecase := usecases.NewPasswordAuthUsecase(userRepo, oneTimeTokenRepository, sessionUsecase, password.NewArgon2idHasher(argon2Params), mail.MailerFromEnv(), usecases.DefaultPasswordAuthConfig(os.Getenv("FRONTEND_URL")), auditUsecase)
+	passwordAuthController := controllers.NewPasswordAuthController(passwordAuthUsecase, refreshTokenUsecase, totpUsecase)
+	accountLinkUsecase := usecases.NewAccountLinkUsecase(oidcLoginUsecase, passwordAuthUsecase, userRepo, repositories.NewAccountMergeRepository(dbConn), authtoken.NewSignedMergeTicketStore(ticketSecret, authtoken.DefaultMergeTicketTTL), totpUsecase, auditUsecase)
+	accountLinkController := controllers.NewAccountLinkController(accountLinkUsecase, refreshTokenUsecase)
+	webauthnConfig, err := webauthn.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("パスキーの設定に失敗しました: %v", err)
+	}
+	passkeyUsecase := usecases.NewPasskeyUsecase(passkeyRepository, passkeyChallengeRepository, userRepo, webauthn.NewVerifier(webauthnConfig), usecases.DefaultPasskeyChallengeTTL)
+	passkeyController := controllers.NewPasskeyController(passkeyUsecase, refreshTokenUsecase)
+
+	vapidPublicKey := ""
+	vapidKeys, err := webpush.KeysFromEnv()
+	if err != nil {
+		log.Fatalf("VAPIDの鍵の設定に失敗しました: %v", err)
+	}
+	if vapidKeys != nil {
+		vapidPublicKey = vapidKeys.PublicKey()
+	}
+	pushSubscriptionController := controllers.NewPushSubscriptionController(usecases.NewPushSubscriptionUsecase(pushSubscriptionRepository), vapidPublicKey)
 
 	router := gin.Default()
+	if err := routes.SetupClientIP(router); err != nil {
+		log.Fatalf("クライアントのIPアドレスの設定に失敗しました: %v", err)
+	}
 
 	// CORS設定を追加
 	routes.SetupCORS(router)
 
 	// SwaggerUIとOpenAPI仕様書のエンドポイントを設定
 	routes.SetupSwaggerEndpoints(router)
+	// JWT検証用の公開鍵（JWKS）のエンドポイントを設定
+	routes.SetupWellKnownEndpoints(router, infra.KeyManager())
 
 	// APIエンドポイントを設定
-	routes.SetupAPIEndpoints(router, diaryController, diaryAnalysisController, userController)
+	routes.SetupAPIEndpoints(router, diaryController, diaryAnalysisController, userController, emotionController, diaryStatsController, factorController, medicationController, thoughtRecordController, exportController, gratitudeController, oidcController, accountLinkController, passwordAuthController, sessionController, passkeyController, mfaController, adminController, personalAccessTokenController, auditController, pushSubscriptionController, webhookController)
 
 	router.Run()
 }
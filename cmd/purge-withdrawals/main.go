@@ -0,0 +1,91 @@
+// 退会の猶予期間を過ぎたユーザーの完全削除ジョブ。cron等から1日1回実行し、日記・関連データとアカウントを削除する。
+// Lambda（EventBridgeのスケジュール）として実行する場合は、入力の {"dry_run": true} でドライランにする
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"tofunote-backend/infra"
+	"tofunote-backend/repositories"
+	"tofunote-backend/usecases"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// purgeEvent はLambdaとして実行する場合の入力
+type purgeEvent struct {
+	DryRun bool `json:"dry_run"`
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "削除せずに対象のユーザーを表示する")
+	flag.Parse()
+
+	infra.Initialize()
+	withdrawUsecase := newUserWithdrawUsecase()
+
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		lambda.Start(func(ctx context.Context, event purgeEvent) (*usecases.WithdrawalPurgeReport, error) {
+			return run(ctx, withdrawUsecase, event.DryRun)
+		})
+		return
+	}
+	if _, err := run(context.Background(), withdrawUsecase, *dryRun); err != nil {
+		log.Fatalf("[ERROR] 退会したユーザーの削除に失敗しました: %v", err)
+	}
+}
+
+func newUserWithdrawUsecase() *usecases.UserWithdrawUsecase {
+	dbConn := infra.SetupDB()
+	refreshTokenRepository := repositories.NewRefreshTokenRepository(dbConn)
+	sessionRepository := repositories.NewSessionRepository(dbConn)
+	personalAccessTokenRepository := repositories.NewPersonalAccessTokenRepository(dbConn)
+	return usecases.NewUserWithdrawUsecase(
+		repositories.NewUnitOfWork(dbConn),
+		repositories.NewUserRepository(dbConn),
+		repositories.NewGuestRetentionRepository(dbConn),
+		usecases.NewSessionUsecase(sessionRepository, refreshTokenRepository, infra.AccessTokenTTL, usecases.DefaultRevocationRefreshInterval),
+		personalAccessTokenRepository,
+		usecases.NewAuditUsecase(repositories.NewAuditEventRepository(dbConn)),
+		repositories.NewDiaryRepository(dbConn),
+		repositories.NewEmotionLabelRepository(dbConn),
+		repositories.NewFactorRepository(dbConn),
+		repositories.NewFactorDefinitionRepository(dbConn),
+		repositories.NewDoseLogRepository(dbConn),
+		repositories.NewMedicationRepository(dbConn),
+		repositories.NewThoughtRecordRepository(dbConn),
+		repositories.NewGratitudeRepository(dbConn),
+		repositories.NewOneTimeTokenRepository(dbConn),
+		refreshTokenRepository,
+		sessionRepository,
+		repositories.NewPasskeyRepository(dbConn),
+		repositories.NewPasskeyChallengeRepository(dbConn),
+		repositories.NewTOTPRepository(dbConn),
+		repositories.NewRecoveryCodeRepository(dbConn),
+		repositories.NewRoleRepository(dbConn),
+		personalAccessTokenRepository,
+		repositories.NewUserPreferencesRepository(dbConn),
+		repositories.NewPushSubscriptionRepository(dbConn),
+		repositories.NewWebhookDeliveryRepository(dbConn),
+		repositories.NewWebhookEndpointRepository(dbConn),
+	)
+}
+
+func run(ctx context.Context, withdrawUsecase *usecases.UserWithdrawUsecase, dryRun bool) (*usecases.WithdrawalPurgeReport, error) {
+	report, err := withdrawUsecase.PurgeExpired(ctx, dryRun)
+	if err != nil {
+		return report, err
+	}
+	if dryRun {
+		log.Printf("[INFO] ドライラン: 削除するユーザー%d件 %v", len(report.Purged), report.Purged)
+		return report, nil
+	}
+	log.Printf("[INFO] 退会したユーザーの削除: 削除%d件・失敗%d件", len(report.Purged), len(report.Failed))
+	if len(report.Failed) > 0 {
+		log.Printf("[WARN] ユーザーの削除に失敗しました（次回の実行で再度削除します） %v", report.Failed)
+	}
+	return report, nil
+}
@@ -0,0 +1,40 @@
+// Webhookの配送ジョブ。cron等から1分ごとに実行し、送信日時を迎えた配送（初回の送信と、失敗した配送の再送）を送る。
+// 合わせて、送信を終えてから30日を過ぎた配送の記録を削除する
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"tofunote-backend/infra"
+	"tofunote-backend/infra/webhook"
+	"tofunote-backend/repositories"
+	"tofunote-backend/usecases"
+)
+
+func main() {
+	limit := flag.Int("limit", 500, "1回の実行で送る配送の最大件数")
+	flag.Parse()
+
+	infra.Initialize()
+	dbConn := infra.SetupDB()
+
+	webhookUsecase := usecases.NewWebhookUsecase(
+		repositories.NewWebhookEndpointRepository(dbConn),
+		repositories.NewWebhookDeliveryRepository(dbConn),
+		webhook.NewHTTPTransport(nil),
+	)
+	ctx := context.Background()
+	delivered, err := webhookUsecase.DeliverDue(ctx, *limit)
+	if err != nil {
+		log.Fatalf("[ERROR] Webhookの配送に失敗しました: %v", err)
+	}
+	log.Printf("[INFO] Webhookを%d件送信しました", delivered)
+
+	pruned, err := webhookUsecase.PruneDeliveries(ctx)
+	if err != nil {
+		log.Fatalf("[ERROR] Webhookの配送の記録の削除に失敗しました: %v", err)
+	}
+	log.Printf("[INFO] Webhookの配送の記録を%d件削除しました", pruned)
+}
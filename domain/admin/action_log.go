@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"context"
+	"time"
+)
+
+// Action は監査ログに記録する管理者の操作
+type Action string
+
+const (
+	ActionForceDeleteUser Action = "force_delete_user"
+	ActionGrantRole       Action = "grant_role"
+	ActionRevokeRole      Action = "revoke_role"
+	// ActionBreakGlassDiaries はユーザーの日記の本文を閲覧する緊急時の操作（理由の入力を必須にする）
+	ActionBreakGlassDiaries Action = "break_glass_read_diaries"
+	ActionGlobalAnalysis    Action = "global_analysis"
+)
+
+// ActionLog は管理者の操作の監査ログ（追記のみで、更新・削除しない）
+type ActionLog struct {
+	ID           string
+	AdminUserID  string
+	Action       Action
+	TargetUserID string
+	Reason       string
+	CreatedAt    time.Time
+}
+
+// ActionLogRepository は監査ログの永続化インターフェース
+type ActionLogRepository interface {
+	Create(ctx context.Context, log *ActionLog) error
+	// List は新しい順に監査ログを返す（targetUserIDが空の場合は全件が対象）
+	List(ctx context.Context, targetUserID string, limit, offset int) ([]*ActionLog, error)
+}
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"context"
+	"time"
+)
+
+// MinCohortSize より少ない人数の集計値は、個人を推測できないよう非表示にする
+const MinCohortSize = 5
+
+// UserStats はユーザー数の集計
+type UserStats struct {
+	Total   int64
+	Guests  int64
+	Members int64
+	// ByProvider は連携した外部アカウントの種類ごとのユーザー数（メールアドレスのみの場合は"email"）
+	ByProvider map[string]int64
+	NewSince   int64
+}
+
+// DiaryStats は日記の集計（本文は扱わない）
+type DiaryStats struct {
+	Total    int64
+	NewSince int64
+	// ActiveWriters は集計期間内に日記を書いたユーザー数
+	ActiveWriters int64
+	// MentalScoreUsers はメンタルスコアごとの、集計期間内にそのスコアを記録したユーザー数
+	MentalScoreUsers map[int]int64
+}
+
+// StatsRepository は管理者向けの集計を行うインターフェース
+type StatsRepository interface {
+	UserStats(ctx context.Context, since time.Time) (*UserStats, error)
+	DiaryStats(ctx context.Context, since time.Time) (*DiaryStats, error)
+}
+
+// SuppressSmallCohorts は人数がMinCohortSize未満の項目を取り除き、取り除いた項目があったかどうかを返す
+func SuppressSmallCohorts[K comparable](counts map[K]int64) (map[K]int64, bool) {
+	suppressed := false
+	result := make(map[K]int64, len(counts))
+	for k, v := range counts {
+		if v > 0 && v < MinCohortSize {
+			suppressed = true
+			continue
+		}
+		result[k] = v
+	}
+	return result, suppressed
+}
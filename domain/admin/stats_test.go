@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuppressSmallCohorts(t *testing.T) {
+	counts, suppressed := SuppressSmallCohorts(map[int]int64{1: 4, 5: 5, 8: 12, 10: 0})
+	assert.True(t, suppressed)
+	assert.Equal(t, map[int]int64{5: 5, 8: 12, 10: 0}, counts)
+
+	_, suppressed = SuppressSmallCohorts(map[string]int64{"google": 30})
+	assert.False(t, suppressed)
+}
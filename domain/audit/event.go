@@ -0,0 +1,96 @@
+// Eventエンティティ: セキュリティに関わる操作の監査ログを表現するモデル
+
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Action は監査ログに記録する操作
+type Action string
+
+const (
+	// ActionLogin はログイン（ログイン方法によらず、リフレッシュトークンを発行したとき）
+	ActionLogin Action = "login"
+	// ActionLoginFailed はメールアドレス・パスワードなどの認証情報の誤り
+	ActionLoginFailed  Action = "login_failed"
+	ActionTokenRefresh Action = "token_refresh"
+	// ActionTokenReuseDetected は交換済みのリフレッシュトークンの再利用を検知してログインを失効させたこと
+	ActionTokenReuseDetected Action = "token_reuse_detected"
+	// ActionAuthFailed はAPIの呼び出しで提示されたトークンを受け付けなかったこと
+	ActionAuthFailed     Action = "auth_failed"
+	ActionAccountLink    Action = "account_link"
+	ActionAccountMerge   Action = "account_merge"
+	ActionWithdraw       Action = "withdraw"
+	ActionWithdrawCancel Action = "withdraw_cancel"
+	// ActionAccountPurge は日記を含むアカウントの完全な削除（退会の猶予期間の経過・管理者による削除など）
+	ActionAccountPurge Action = "account_purge"
+	ActionDataExport   Action = "data_export"
+	// ActionAdminAccess は管理APIの利用（Detailに操作の種類を記録する）
+	ActionAdminAccess Action = "admin_access"
+)
+
+// Event は監査ログの1件（追記のみで、更新・削除しない）
+type Event struct {
+	ID string
+	// ActorUserID は操作したユーザー（未認証の操作・ジョブによる操作の場合は空）
+	ActorUserID string
+	Action      Action
+	// TargetUserID は操作の対象のユーザー（自分自身に対する操作の場合はActorUserIDと同じ）
+	TargetUserID string
+	// Detail は操作の補足（ログイン方法・失敗の理由など。日記の本文やトークンは含めない）
+	Detail    string
+	IPAddress string
+	UserAgent string
+	RequestID string
+	CreatedAt time.Time
+}
+
+// Query は監査ログの検索条件
+type Query struct {
+	// UserID は操作したユーザーまたは対象のユーザーで絞り込む（空の場合は全ユーザー）
+	UserID string
+	Action Action
+	// Since・Until は記録日時の範囲（ゼロ値の場合は制限しない。Untilは含まない）
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
+
+// Repository は監査ログの永続化インターフェース（追記と検索のみ）
+type Repository interface {
+	Append(ctx context.Context, event *Event) error
+	// List は新しい順に監査ログを返す
+	List(ctx context.Context, query Query) ([]*Event, error)
+}
+
+// RequestInfo は監査ログに記録するリクエストの情報
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+	// ActorUserID は認証したユーザー（未認証のリクエストの場合は空）
+	ActorUserID string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo はリクエストの情報をコンテキストに設定する（ユースケースが監査ログを記録するときに使う）
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom はコンテキストに設定されたリクエストの情報を返す（ジョブなどリクエストによらない場合はゼロ値）
+func RequestInfoFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
+
+// WithActor は認証したユーザーをリクエストの情報に設定する
+func WithActor(ctx context.Context, userID string) context.Context {
+	info := RequestInfoFrom(ctx)
+	info.ActorUserID = userID
+	return WithRequestInfo(ctx, info)
+}
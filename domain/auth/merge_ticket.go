@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidMergeTicket = errors.New("アカウント統合の確認トークンが不正または期限切れです")
+
+// MergeTicket は連携しようとした外部アカウントが既に別ユーザーのものだった場合に、
+// そのユーザーへの統合を後から確定するための情報（外部アカウントでの認証済みであることを表す）
+type MergeTicket struct {
+	GuestUserID  string
+	TargetUserID string
+	ExpiresAt    time.Time
+}
+
+// MergeTicketStore はMergeTicketをクライアントに預けるためのトークンを発行・検証する
+type MergeTicketStore interface {
+	Issue(ticket MergeTicket) (string, error)
+	Verify(token string) (*MergeTicket, error)
+}
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidMFAChallenge = errors.New("二段階認証の確認トークンが不正または期限切れです。もう一度ログインしてください")
+
+// MFAChallenge は1つ目の要素（パスワード・外部アカウント）での認証が済み、
+// 二段階認証の認証コードの入力を待っているログインの情報
+type MFAChallenge struct {
+	UserID    string
+	ExpiresAt time.Time
+}
+
+// MFAChallengeStore はMFAChallengeをクライアントに預けるためのトークンを発行・検証する。
+// このトークンはアクセストークンとしては使えない
+type MFAChallengeStore interface {
+	Issue(challenge MFAChallenge) (string, error)
+	Verify(token string) (*MFAChallenge, error)
+}
@@ -0,0 +1,48 @@
+// OIDC認証: 外部のOpenID Connectプロバイダ（Google・Apple・LINE等）によるログインを抽象化する
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownProvider = errors.New("未対応の認証プロバイダです")
+	ErrInvalidFlow     = errors.New("認証フローが不正または期限切れです")
+	ErrInvalidIDToken  = errors.New("IDトークンの検証に失敗しました")
+)
+
+// Identity はIDトークンから得られた外部アカウントの情報
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCProvider は認可コードフロー（PKCE付き）を実行するOpenID Connectプロバイダ
+type OIDCProvider interface {
+	Name() string
+	// AuthCodeURL は認可リクエストのURLを組み立てる（codeChallengeはS256で計算済みの値）
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange は認可コードをトークンに交換し、JWKSで検証したIDトークンの内容を返す
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
+
+// FlowState は認可リクエストからコールバックまでの間に保持する値
+type FlowState struct {
+	Provider     string
+	State        string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+// FlowStore はFlowStateをクライアントに預けるためのトークンを発行・検証する
+type FlowStore interface {
+	Issue(state FlowState) (string, error)
+	Verify(token string) (*FlowState, error)
+}
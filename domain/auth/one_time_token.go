@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var ErrInvalidOneTimeToken = errors.New("トークンが不正、使用済みまたは期限切れです")
+
+// TokenPurpose はワンタイムトークンの用途
+type TokenPurpose string
+
+const (
+	PurposeVerifyEmail   TokenPurpose = "verify_email"
+	PurposeResetPassword TokenPurpose = "reset_password"
+)
+
+// OneTimeToken はメールで送る1回限り・期限付きのトークン（平文は保存せずハッシュのみ保持する）
+type OneTimeToken struct {
+	ID        string
+	UserID    string
+	Purpose   TokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// HashToken はトークンの保存・照合に使うSHA-256ハッシュ（16進数）を返す
+func HashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// OneTimeTokenRepository はワンタイムトークンの永続化インターフェース
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, token *OneTimeToken) error
+	// Consume は未使用かつ期限内のトークンを使用済みにして返す（該当しない場合はErrInvalidOneTimeToken）
+	Consume(ctx context.Context, purpose TokenPurpose, tokenHash string, now time.Time) (*OneTimeToken, error)
+	// InvalidateByUserID はユーザーの未使用のトークンを用途ごとに無効化する（再発行時に古いトークンを使えなくする）
+	InvalidateByUserID(ctx context.Context, userID string, purpose TokenPurpose, now time.Time) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
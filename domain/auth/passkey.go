@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrPasskeyNotFound = errors.New("パスキーが見つかりません")
+	// ErrInvalidPasskey は署名・オリジン・RP IDなどの検証に失敗した場合のエラー
+	ErrInvalidPasskey = errors.New("パスキーの検証に失敗しました")
+	// ErrPasskeySignCount は署名カウンタが巻き戻った（認証器が複製された疑いがある）場合のエラー
+	ErrPasskeySignCount         = errors.New("パスキーの署名カウンタが不正です。認証器が複製された可能性があります")
+	ErrPasskeyAlreadyRegistered = errors.New("このパスキーは既に登録されています")
+	ErrInvalidPasskeyChallenge  = errors.New("パスキーの登録・ログインの要求が不正、使用済みまたは期限切れです")
+)
+
+// COSEアルゴリズム識別子（RFC 9053）
+const (
+	COSEAlgES256 = -7
+	COSEAlgEdDSA = -8
+	COSEAlgRS256 = -257
+)
+
+// Passkey はユーザーが登録したWebAuthnの認証器の公開鍵
+type Passkey struct {
+	ID           string
+	UserID       string
+	Name         string
+	CredentialID []byte
+	// PublicKey はCOSE_Key形式の公開鍵
+	PublicKey []byte
+	Algorithm int
+	// SignCount は最後の認証で認証器が返した署名カウンタ（対応していない認証器では常に0）
+	SignCount  uint32
+	Transports []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// PasskeyCredential は登録時の検証で取り出した認証器の情報
+type PasskeyCredential struct {
+	CredentialID []byte
+	PublicKey    []byte
+	Algorithm    int
+	SignCount    uint32
+}
+
+// PasskeyAttestation は登録時に認証器が返したレスポンス
+type PasskeyAttestation struct {
+	ClientDataJSON    []byte
+	AttestationObject []byte
+	Transports        []string
+}
+
+// PasskeyAssertion はログイン時に認証器が返したレスポンス
+type PasskeyAssertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	// UserHandle は登録時に渡したユーザーハンドル（認証器が返さない場合は空）
+	UserHandle []byte
+}
+
+// PasskeyCeremony はチャレンジを発行した手続きの種類
+type PasskeyCeremony string
+
+const (
+	PasskeyRegistration PasskeyCeremony = "registration"
+	PasskeyLogin        PasskeyCeremony = "login"
+)
+
+// PasskeyChallenge は登録・ログインの開始時に発行し、完了時に1回だけ使えるチャレンジ
+type PasskeyChallenge struct {
+	ID       string
+	Ceremony PasskeyCeremony
+	// UserID は登録のチャレンジを発行したユーザー（ログインのチャレンジでは空）
+	UserID    string
+	Challenge []byte
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// RelyingParty はWebAuthnの依存者（このサービス）の情報
+type RelyingParty struct {
+	ID   string
+	Name string
+}
+
+// PasskeyVerifier はWebAuthnの登録・ログインのレスポンスを検証する
+type PasskeyVerifier interface {
+	RelyingParty() RelyingParty
+	// VerifyRegistration は登録のレスポンスを検証し、認証器の公開鍵を返す
+	VerifyRegistration(challenge []byte, attestation *PasskeyAttestation) (*PasskeyCredential, error)
+	// VerifyAssertion はログインのレスポンスの署名を登録済みの公開鍵で検証し、新しい署名カウンタを返す
+	VerifyAssertion(challenge []byte, passkey *Passkey, assertion *PasskeyAssertion) (uint32, error)
+}
+
+// PasskeyRepository はパスキーの永続化インターフェース
+type PasskeyRepository interface {
+	Create(ctx context.Context, passkey *Passkey) error
+	// FindByCredentialID は認証器のクレデンシャルIDでパスキーを取得する（該当しない場合はErrPasskeyNotFound）
+	FindByCredentialID(ctx context.Context, credentialID []byte) (*Passkey, error)
+	ListByUserID(ctx context.Context, userID string) ([]*Passkey, error)
+	// UpdateSignCount はログイン成功時に署名カウンタと最終利用日時を更新する
+	UpdateSignCount(ctx context.Context, id string, signCount uint32, now time.Time) error
+	Rename(ctx context.Context, userID, id, name string) error
+	Delete(ctx context.Context, userID, id string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// PasskeyChallengeRepository はチャレンジの永続化インターフェース
+type PasskeyChallengeRepository interface {
+	Create(ctx context.Context, challenge *PasskeyChallenge) error
+	// Consume は未使用かつ期限内のチャレンジを使用済みにして返す（該当しない場合はErrInvalidPasskeyChallenge）
+	Consume(ctx context.Context, id string, ceremony PasskeyCeremony, now time.Time) (*PasskeyChallenge, error)
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+)
+
+var (
+	ErrInvalidCredentials = errors.New("メールアドレスまたはパスワードが正しくありません")
+	ErrInvalidPassword    = errors.New("パスワードが条件を満たしていません")
+)
+
+// ValidatePassword はパスワードの長さを検証する
+func ValidatePassword(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < MinPasswordLength {
+		return fmt.Errorf("%w: %d文字以上で入力してください", ErrInvalidPassword, MinPasswordLength)
+	}
+	if n > MaxPasswordLength {
+		return fmt.Errorf("%w: %d文字以内で入力してください", ErrInvalidPassword, MaxPasswordLength)
+	}
+	return nil
+}
+
+// PasswordHasher はパスワードのハッシュ化と照合を行う
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify はパスワードを照合し、ハッシュのパラメータが現在の設定より古い場合はneedsRehashをtrueで返す
+	Verify(password, encoded string) (ok bool, needsRehash bool, err error)
+}
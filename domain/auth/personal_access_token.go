@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPersonalAccessTokenNotFound = errors.New("アクセストークンが見つかりません")
+	// ErrInvalidPersonalAccessToken はトークンが存在しない・期限切れ・取り消し済みの場合のエラー
+	ErrInvalidPersonalAccessToken = errors.New("アクセストークンが不正または期限切れです")
+	ErrInvalidScope               = errors.New("スコープが不正です")
+	// ErrInsufficientScope はトークンにAPIの利用に必要なスコープが含まれていない場合のエラー
+	ErrInsufficientScope = errors.New("このアクセストークンには必要なスコープがありません")
+)
+
+// PersonalAccessTokenPrefix は個人用アクセストークンの先頭に付ける文字列（JWTと区別し、漏えい時に検出しやすくする）
+const PersonalAccessTokenPrefix = "tfn_pat_"
+
+// Scope は個人用アクセストークンで利用できる操作の範囲
+type Scope string
+
+const (
+	ScopeDiariesRead  Scope = "diaries:read"
+	ScopeDiariesWrite Scope = "diaries:write"
+	ScopeAnalysisRun  Scope = "analysis:run"
+)
+
+// ParseScopes は文字列のスコープの一覧を定義済みのスコープに変換する（重複は取り除く）
+func ParseScopes(values []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(values))
+	seen := map[Scope]bool{}
+	for _, v := range values {
+		scope := Scope(strings.TrimSpace(v))
+		switch scope {
+		case ScopeDiariesRead, ScopeDiariesWrite, ScopeAnalysisRun:
+		default:
+			return nil, ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+	return scopes, nil
+}
+
+// IsPersonalAccessToken はBearerトークンが個人用アクセストークンの形式かどうかを返す
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// PersonalAccessToken はスクリプトや外部連携のためにユーザーが発行するアクセストークン（平文は保存せずハッシュのみ保持する）
+type PersonalAccessToken struct {
+	ID        string
+	UserID    string
+	Name      string
+	TokenHash string
+	Scopes    []Scope
+	ExpiresAt time.Time
+	// LastUsedAt は最後にAPIの呼び出しに使われた日時（一定間隔でのみ更新する）
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// HasScope はトークンにスコープが含まれているかどうかを返す
+func (t *PersonalAccessToken) HasScope(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired はトークンが期限切れかどうかを返す
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// PersonalAccessTokenRepository は個人用アクセストークンの永続化インターフェース
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *PersonalAccessToken) error
+	// FindByHash はトークンのハッシュで取得する（該当しない場合はErrInvalidPersonalAccessToken）
+	FindByHash(ctx context.Context, tokenHash string) (*PersonalAccessToken, error)
+	ListByUserID(ctx context.Context, userID string) ([]*PersonalAccessToken, error)
+	// TouchLastUsed は最終利用日時がstaleBeforeより古い場合のみnowに更新する（呼び出しごとの書き込みを避ける）
+	TouchLastUsed(ctx context.Context, id string, now, staleBefore time.Time) error
+	// Delete はトークンを取り消す（該当しない場合はErrPersonalAccessTokenNotFound）
+	Delete(ctx context.Context, userID, id string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
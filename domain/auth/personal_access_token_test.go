@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"diaries:read", " analysis:run", "diaries:read"})
+	require.NoError(t, err)
+	assert.Equal(t, []Scope{ScopeDiariesRead, ScopeAnalysisRun}, scopes)
+
+	_, err = ParseScopes([]string{"diaries:read", "admin"})
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	_, err = ParseScopes(nil)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/bits"
+	"time"
+)
+
+var (
+	// ErrProofOfWorkRequired はゲストの作成に計算課題の解答が必要な場合のエラー
+	ErrProofOfWorkRequired = errors.New("計算課題の解答が必要です")
+	ErrInvalidProofOfWork  = errors.New("計算課題の解答が不正、使用済みまたは期限切れです")
+)
+
+// MaxProofOfWorkDifficulty は計算課題の難易度の上限（解答に必要なハッシュ計算の回数は2^難易度程度）
+const MaxProofOfWorkDifficulty = 32
+
+// ProofOfWorkChallenge はゲストの作成前にクライアントに解かせる計算課題（外部のCAPTCHAを使わずに大量作成のコストを上げる）
+type ProofOfWorkChallenge struct {
+	ID string
+	// Difficulty はSHA-256ハッシュの先頭に必要な0のビット数
+	Difficulty int
+	ExpiresAt  time.Time
+}
+
+// ProofOfWorkChallengeStore は計算課題を発行し、クライアントから返された課題を検証する
+type ProofOfWorkChallengeStore interface {
+	// Issue は課題を発行し、クライアントに渡す課題の文字列を返す
+	Issue(challenge ProofOfWorkChallenge) (string, error)
+	// Verify は課題の文字列を検証する（改ざん・期限切れの場合はErrInvalidProofOfWork）
+	Verify(token string) (*ProofOfWorkChallenge, error)
+}
+
+// SolvesProofOfWork はSHA-256(課題の文字列 + nonce)の先頭difficultyビットが0かどうかを返す
+func SolvesProofOfWork(challenge, nonce string, difficulty int) bool {
+	sum := sha256.Sum256([]byte(challenge + nonce))
+	return leadingZeroBits(sum[:]) >= difficulty
+}
+
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, v := range b {
+		if v != 0 {
+			return n + bits.LeadingZeros8(v)
+		}
+		n += 8
+	}
+	return n
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolvesProofOfWork(t *testing.T) {
+	challenge := "challenge"
+	nonce := ""
+	for i := 0; ; i++ {
+		if SolvesProofOfWork(challenge, strconv.Itoa(i), 8) {
+			nonce = strconv.Itoa(i)
+			break
+		}
+	}
+	assert.True(t, SolvesProofOfWork(challenge, nonce, 8))
+	assert.True(t, SolvesProofOfWork(challenge, nonce, 0))
+	assert.Equal(t, 12, leadingZeroBits([]byte{0x00, 0x0f, 0xff}))
+	assert.Equal(t, 0, leadingZeroBits([]byte{0x80}))
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("リフレッシュトークンが不正、失効済みまたは期限切れです")
+	// ErrRefreshTokenReused はローテーション済みのトークンが再度使われた（漏えいの疑いがある）場合のエラー
+	ErrRefreshTokenReused = errors.New("使用済みのリフレッシュトークンが再利用されたため、この端末のログインを無効にしました")
+)
+
+// ClientInfo はトークンを発行・更新した端末の情報
+type ClientInfo struct {
+	DeviceLabel string
+	UserAgent   string
+	IPAddress   string
+}
+
+// RefreshToken はリフレッシュトークン（平文は保存せずSHA-256ハッシュのみ保持する）。
+// ログインごとにファミリーを作り、ローテーションで発行したトークンは同じファミリーに属する
+type RefreshToken struct {
+	ID          string
+	UserID      string
+	FamilyID    string
+	TokenHash   string
+	DeviceLabel string
+	ExpiresAt   time.Time
+	// RotatedAt は新しいトークンと交換済みになった日時（以降に使われた場合は再利用とみなす）
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// IsExpired は有効期限を過ぎているかどうかを返す
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// RefreshTokenRepository はリフレッシュトークンの永続化インターフェース
+type RefreshTokenRepository interface {
+	// Create はトークンを保存する（FamilyIDが空の場合は新しいファミリーとして作成する）
+	Create(ctx context.Context, token *RefreshToken) error
+	// FindByHash はハッシュでトークンを取得する（該当しない場合はErrInvalidRefreshToken）
+	FindByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// Rotate は現在のトークンを交換済みにして次のトークンを保存する。
+	// 現在のトークンが既に交換済み・失効済みの場合はErrRefreshTokenReusedを返し、何も保存しない
+	Rotate(ctx context.Context, currentID string, next *RefreshToken, now time.Time) error
+	RevokeFamily(ctx context.Context, familyID string, now time.Time) error
+	RevokeByUserID(ctx context.Context, userID string, now time.Time) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("指定されたセッションが見つかりません")
+
+// Session はログインした端末ごとのセッション。IDはリフレッシュトークンのファミリーIDと同じ
+type Session struct {
+	ID          string
+	UserID      string
+	DeviceLabel string
+	UserAgent   string
+	IPAddress   string
+	CreatedAt   time.Time
+	LastUsedAt  time.Time
+	// ExpiresAt は最新のリフレッシュトークンの有効期限（これを過ぎたセッションは使えない）
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// SessionRepository はセッションの永続化インターフェース
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	// Touch はリフレッシュトークンの更新時に最終利用日時・端末の情報・有効期限を更新する
+	Touch(ctx context.Context, id string, client ClientInfo, expiresAt, now time.Time) error
+	// ListActiveByUserID は失効しておらず期限内のセッションを最終利用日時の新しい順に返す
+	ListActiveByUserID(ctx context.Context, userID string, now time.Time) ([]*Session, error)
+	// Revoke はユーザーのセッションを失効させる（該当しない・失効済みの場合はErrSessionNotFound）
+	Revoke(ctx context.Context, userID, id string, now time.Time) error
+	RevokeAllByUserID(ctx context.Context, userID string, now time.Time) error
+	// ListRevokedIDsSince は指定日時以降に失効したセッションのIDを返す
+	ListRevokedIDsSince(ctx context.Context, since time.Time) ([]string, error)
+	// DeleteByUserID はユーザーのセッションを削除する。失効済みのセッションは発行済みのアクセストークンを
+	// 全てのインスタンスで拒否できるよう、端末の情報のみ消してIDと失効日時を残す（先にRevokeAllByUserIDで失効させる）
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTOTPNotEnabled     = errors.New("二段階認証が有効になっていません")
+	ErrTOTPAlreadyEnabled = errors.New("二段階認証は既に有効です")
+	ErrTOTPNotEnrolled    = errors.New("二段階認証の設定が開始されていません")
+	ErrInvalidTOTPCode    = errors.New("認証コードが正しくありません")
+	// ErrTOTPLocked は認証コードの入力に続けて失敗したため、一定時間入力を受け付けない場合のエラー
+	ErrTOTPLocked = errors.New("認証コードの入力に続けて失敗したため、しばらくしてから再度お試しください")
+)
+
+// TOTP（RFC 6238）のパラメータ。認証アプリの多くが対応している既定値（HMAC-SHA1・6桁・30秒）を使う
+const (
+	TOTPDigits      = 6
+	TOTPPeriod      = 30 * time.Second
+	TOTPSecretBytes = 20
+)
+
+// TOTPFactor はユーザーの二段階認証（TOTP）の設定
+type TOTPFactor struct {
+	UserID string
+	Secret []byte
+	// EnabledAt は最初の認証コードを確認して有効にした日時（設定の途中ではnil）
+	EnabledAt *time.Time
+	// LastUsedStep は最後に受け付けたコードの時間ステップ（同じコードの再利用を防ぐ）
+	LastUsedStep   int64
+	FailedAttempts int
+	LockedUntil    *time.Time
+	CreatedAt      time.Time
+}
+
+// IsEnabled は二段階認証が有効かどうかを返す
+func (f *TOTPFactor) IsEnabled() bool {
+	return f != nil && f.EnabledAt != nil
+}
+
+// IsLocked は認証コードの入力を一時的に受け付けない状態かどうかを返す
+func (f *TOTPFactor) IsLocked(now time.Time) bool {
+	return f.LockedUntil != nil && now.Before(*f.LockedUntil)
+}
+
+// GenerateTOTPSecret はTOTPの共有鍵を生成する
+func GenerateTOTPSecret() ([]byte, error) {
+	secret := make([]byte, TOTPSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// EncodeTOTPSecret は共有鍵を認証アプリに手入力するためのBase32（パディングなし）にする
+func EncodeTOTPSecret(secret []byte) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+}
+
+// TOTPStep は時刻に対応する時間ステップ（UNIX時間を30秒で割った値）を返す
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod/time.Second)
+}
+
+// TOTPCode は時間ステップに対応する認証コードを計算する（RFC 4226のHOTP）
+func TOTPCode(secret []byte, step int64) string {
+	mac := hmac.New(sha1.New, secret)
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TOTPDigits, value%1000000)
+}
+
+// MatchTOTPCode は前後skewステップまでの時刻のずれを許容してコードを照合し、一致した時間ステップを返す
+func MatchTOTPCode(secret []byte, code string, now time.Time, skew int64) (int64, bool) {
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+	current := TOTPStep(now)
+	for step := current - skew; step <= current+skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(TOTPCode(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPProvisioningURI は認証アプリのQRコードに埋め込むotpauth URIを返す
+func TOTPProvisioningURI(issuer, account string, secret []byte) string {
+	q := url.Values{}
+	q.Set("secret", EncodeTOTPSecret(secret))
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(TOTPDigits))
+	q.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// RecoveryCode は認証アプリを使えなくなった場合に一度だけ使えるリカバリーコード（平文は保存せずハッシュのみ保持する）
+type RecoveryCode struct {
+	ID        string
+	UserID    string
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// recoveryCodeAlphabet は読み間違えやすい文字（0・1・l・o）を除いた小文字のBase32
+const recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCode は「xxxxx-xxxxx」形式のリカバリーコードを生成する
+func GenerateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := make([]byte, 0, 11)
+	for i, v := range b {
+		if i == 5 {
+			code = append(code, '-')
+		}
+		code = append(code, recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)])
+	}
+	return string(code), nil
+}
+
+// NormalizeRecoveryCode は入力されたリカバリーコードの区切り・空白・大文字小文字の違いを取り除く
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// TOTPRepository は二段階認証の設定の永続化インターフェース
+type TOTPRepository interface {
+	// FindByUserID は設定を取得する（存在しない場合はnil）
+	FindByUserID(ctx context.Context, userID string) (*TOTPFactor, error)
+	// Save は設定を作成または更新する
+	Save(ctx context.Context, factor *TOTPFactor) error
+	// UseStep は前回より新しい時間ステップのコードを受け付けたことを記録し、失敗回数を戻す。
+	// 同じか古い時間ステップの場合（コードの再利用）はErrInvalidTOTPCodeを返す
+	UseStep(ctx context.Context, userID string, step int64) error
+	// RecordFailure は失敗回数を1つ増やし、maxAttempts回に達した場合はlockedUntilまでロックして回数を戻す。
+	// 同時に失敗しても回数を数え漏らさないよう、失敗の記録に関わる列のみ1回のUPDATEで更新する
+	RecordFailure(ctx context.Context, userID string, maxAttempts int, lockedUntil time.Time) error
+	// ResetFailures は失敗回数とロックを戻す（失敗の記録に関わる列のみ更新する）
+	ResetFailures(ctx context.Context, userID string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// RecoveryCodeRepository はリカバリーコードの永続化インターフェース
+type RecoveryCodeRepository interface {
+	// ReplaceByUserID はユーザーのリカバリーコードを全て新しいものに置き換える
+	ReplaceByUserID(ctx context.Context, userID string, codes []*RecoveryCode) error
+	// Consume は未使用のリカバリーコードを使用済みにする（該当しない場合はErrInvalidTOTPCode）
+	Consume(ctx context.Context, userID, codeHash string, now time.Time) error
+	CountUnused(ctx context.Context, userID string) (int, error)
+	DeleteByUserID(ctx context.Context, userID string) error
+}
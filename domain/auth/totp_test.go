@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 Appendix BのSHA1のテストベクタ（8桁の下6桁）
+func TestTOTPCode_RFC6238(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0))), "T=%d", tt.unix)
+	}
+}
+
+func TestMatchTOTPCode(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+
+	step, ok := MatchTOTPCode(secret, TOTPCode(secret, current), now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, current, step)
+
+	step, ok = MatchTOTPCode(secret, TOTPCode(secret, current-1), now, 1)
+	assert.True(t, ok, "1ステップ前のコードは時刻のずれとして許容")
+	assert.Equal(t, current-1, step)
+
+	_, ok = MatchTOTPCode(secret, TOTPCode(secret, current-2), now, 1)
+	assert.False(t, ok)
+	_, ok = MatchTOTPCode(secret, "12345", now, 1)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	uri := TOTPProvisioningURI("Tofunote", "user@example.com", secret)
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Tofunote:user@example.com", u.Path)
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", u.Query().Get("secret"))
+	assert.Equal(t, "Tofunote", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := GenerateRecoveryCode()
+	require.NoError(t, err)
+	assert.Len(t, code, 11)
+	assert.Equal(t, byte('-'), code[5])
+	assert.Equal(t, strings.ReplaceAll(code, "-", ""), NormalizeRecoveryCode(" "+strings.ToUpper(code)))
+
+	other, err := GenerateRecoveryCode()
+	require.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
@@ -0,0 +1,105 @@
+// Date値オブジェクト: 日記を書いた日（ユーザーのタイムゾーンでの暦日）を表現するモデル
+
+package diary
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DateLayout は日付の形式（YYYY-MM-DD）
+const DateLayout = "2006-01-02"
+
+// MaxDateRangeDays は範囲指定で取得できる最大の日数
+const MaxDateRangeDays = 366 * 5
+
+var (
+	// ErrInvalidDate は日付の形式が不正、または存在しない日付であることを表す
+	ErrInvalidDate = errors.New("日付はYYYY-MM-DD形式の存在する日付で指定してください")
+	// ErrInvalidDateRange は範囲指定の開始日・終了日が不正であることを表す
+	ErrInvalidDateRange = errors.New("start_dateはend_date以前の日付を指定してください")
+	// ErrDateRangeTooLong は範囲指定の日数が上限を超えていることを表す
+	ErrDateRangeTooLong = errors.New("取得できる期間が長すぎます")
+)
+
+// Date はタイムゾーンを持たない暦日（YYYY-MM-DD形式の文字列として比較・保存できる）
+type Date string
+
+// NewDate はYYYY-MM-DD形式の日付を検証する（2025-02-30のような存在しない日付や、ゼロ埋めしていない形式は受け付けない）
+func NewDate(value string) (Date, error) {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil || t.Format(DateLayout) != value {
+		return "", ErrInvalidDate
+	}
+	return Date(value), nil
+}
+
+// DateOf は時刻をそのタイムゾーンでの暦日にする
+func DateOf(t time.Time) Date {
+	return Date(t.Format(DateLayout))
+}
+
+// Today はnowをユーザーのタイムゾーンで見たときの今日の日付を返す（locがnilの場合はnowのタイムゾーン）
+func Today(now time.Time, loc *time.Location) Date {
+	if loc != nil {
+		now = now.In(loc)
+	}
+	return DateOf(now)
+}
+
+// NewDateRange は範囲指定の開始日・終了日を検証する
+func NewDateRange(start, end string) (Date, Date, error) {
+	startDate, err := NewDate(start)
+	if err != nil {
+		return "", "", err
+	}
+	endDate, err := NewDate(end)
+	if err != nil {
+		return "", "", err
+	}
+	if endDate.Before(startDate) {
+		return "", "", ErrInvalidDateRange
+	}
+	if endDate.Time().Sub(startDate.Time()) > MaxDateRangeDays*24*time.Hour {
+		return "", "", ErrDateRangeTooLong
+	}
+	return startDate, endDate, nil
+}
+
+func (d Date) String() string {
+	return string(d)
+}
+
+// Time はUTCの0時として日付を返す（日数の計算用）
+func (d Date) Time() time.Time {
+	t, _ := time.Parse(DateLayout, string(d))
+	return t
+}
+
+// AddDays はn日後（負の場合はn日前）の日付を返す
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.Time().AddDate(0, 0, n))
+}
+
+// Before はdがotherより前の日付かどうかを返す
+func (d Date) Before(other Date) bool {
+	return d < other
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	date, err := NewDate(value)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
@@ -0,0 +1,71 @@
+package diary
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "正常系", value: "2025-05-01"},
+		{name: "正常系：うるう日", value: "2024-02-29"},
+		{name: "異常系：うるう年でない年の2月29日", value: "2025-02-29", wantErr: true},
+		{name: "異常系：存在しない日", value: "2025-04-31", wantErr: true},
+		{name: "異常系：ゼロ埋めしていない", value: "2025-5-1", wantErr: true},
+		{name: "異常系：時刻付き", value: "2025-05-01T00:00:00Z", wantErr: true},
+		{name: "異常系：空", value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDate(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDate)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, d.String())
+		})
+	}
+}
+
+func TestToday(t *testing.T) {
+	now := time.Date(2025, 5, 1, 20, 0, 0, 0, time.UTC)
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	newYork, _ := time.LoadLocation("America/New_York")
+	assert.Equal(t, Date("2025-05-02"), Today(now, tokyo))
+	assert.Equal(t, Date("2025-05-01"), Today(now, newYork))
+	assert.Equal(t, Date("2025-05-01"), Today(now, nil))
+}
+
+func TestNewDateRange(t *testing.T) {
+	start, end, err := NewDateRange("2025-05-01", "2025-05-31")
+	assert.NoError(t, err)
+	assert.Equal(t, Date("2025-05-01"), start)
+	assert.Equal(t, Date("2025-05-31"), end)
+
+	_, _, err = NewDateRange("2025-05-31", "2025-05-01")
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+	_, _, err = NewDateRange("2025-05-01", "2025-13-01")
+	assert.ErrorIs(t, err, ErrInvalidDate)
+	_, _, err = NewDateRange("2000-01-01", "2025-01-01")
+	assert.ErrorIs(t, err, ErrDateRangeTooLong)
+}
+
+func TestDate_AddDays(t *testing.T) {
+	assert.Equal(t, Date("2025-03-01"), Date("2025-02-28").AddDays(1))
+	assert.Equal(t, Date("2024-12-31"), Date("2025-01-01").AddDays(-1))
+}
+
+func TestDate_UnmarshalJSON(t *testing.T) {
+	var d Date
+	assert.NoError(t, json.Unmarshal([]byte(`"2025-05-01"`), &d))
+	assert.Equal(t, Date("2025-05-01"), d)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`"2025-02-30"`), &d), ErrInvalidDate)
+}
@@ -3,9 +3,10 @@
 package diary
 
 type Diary struct {
-	ID     string
-	UserID string
-	Date   string
-	Mental Mental
-	Diary  string
+	ID       string
+	UserID   string
+	Date     Date
+	Mental   Mental
+	Diary    string
+	Emotions []Emotion
 }
@@ -0,0 +1,184 @@
+// Emotion値オブジェクト: 日記に付与する感情ラベル（Plutchikの感情の輪ベース）を表現するモデル
+
+package diary
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Locale は感情ラベルの表示言語
+type Locale string
+
+const (
+	LocaleJa Locale = "ja"
+	LocaleEn Locale = "en"
+)
+
+// ParseLocale は文字列からLocaleを生成する（未対応の値はjaにフォールバック）
+func ParseLocale(value string) Locale {
+	if Locale(value) == LocaleEn {
+		return LocaleEn
+	}
+	return LocaleJa
+}
+
+const (
+	// EmotionIntensityNone は強度が未指定であることを表す
+	EmotionIntensityNone = 0
+	EmotionIntensityMin  = 1
+	EmotionIntensityMax  = 5
+	// MaxEmotionsPerDiary は1つの日記に付与できる感情ラベルの上限
+	MaxEmotionsPerDiary = 10
+)
+
+var emotionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// Emotion は日記に付与された感情ラベルと強度
+type Emotion struct {
+	Key       string `json:"key"`
+	Intensity int    `json:"intensity,omitempty"`
+}
+
+// NewEmotion はキーの形式と強度の範囲を検証してEmotionを生成する
+func NewEmotion(key string, intensity int) (Emotion, error) {
+	if !emotionKeyPattern.MatchString(key) {
+		return Emotion{}, fmt.Errorf("感情ラベルのキーが不正です: %s", key)
+	}
+	if intensity != EmotionIntensityNone && (intensity < EmotionIntensityMin || intensity > EmotionIntensityMax) {
+		return Emotion{}, errors.New("感情の強度は1〜5で指定してください")
+	}
+	return Emotion{Key: key, Intensity: intensity}, nil
+}
+
+// EmotionLabel は感情ラベルの定義（組み込み・ユーザー定義の両方）
+type EmotionLabel struct {
+	Key      string
+	UserID   string // 組み込みラベルの場合は空
+	Category string // Plutchikの基本感情のキー
+	NameJa   string
+	NameEn   string
+}
+
+// Name は指定ロケールでのラベル名を返す
+func (l EmotionLabel) Name(locale Locale) string {
+	if locale == LocaleEn && l.NameEn != "" {
+		return l.NameEn
+	}
+	return l.NameJa
+}
+
+// IsBuiltin は組み込みラベルかどうかを返す
+func (l EmotionLabel) IsBuiltin() bool {
+	return l.UserID == ""
+}
+
+// 基本感情（Plutchikの8つの基本感情）
+var primaryEmotions = []EmotionLabel{
+	{Key: "joy", Category: "joy", NameJa: "喜び", NameEn: "Joy"},
+	{Key: "trust", Category: "trust", NameJa: "信頼", NameEn: "Trust"},
+	{Key: "fear", Category: "fear", NameJa: "恐れ", NameEn: "Fear"},
+	{Key: "surprise", Category: "surprise", NameJa: "驚き", NameEn: "Surprise"},
+	{Key: "sadness", Category: "sadness", NameJa: "悲しみ", NameEn: "Sadness"},
+	{Key: "disgust", Category: "disgust", NameJa: "嫌悪", NameEn: "Disgust"},
+	{Key: "anger", Category: "anger", NameJa: "怒り", NameEn: "Anger"},
+	{Key: "anticipation", Category: "anticipation", NameJa: "期待", NameEn: "Anticipation"},
+}
+
+// 日常的に使われる派生感情（基本感情のいずれかに分類される）
+var derivedEmotions = []EmotionLabel{
+	{Key: "anxiety", Category: "fear", NameJa: "不安", NameEn: "Anxiety"},
+	{Key: "fatigue", Category: "sadness", NameJa: "疲労", NameEn: "Fatigue"},
+	{Key: "relief", Category: "trust", NameJa: "安心", NameEn: "Relief"},
+	{Key: "calm", Category: "joy", NameJa: "穏やか", NameEn: "Calm"},
+	{Key: "gratitude", Category: "trust", NameJa: "感謝", NameEn: "Gratitude"},
+	{Key: "loneliness", Category: "sadness", NameJa: "孤独", NameEn: "Loneliness"},
+	{Key: "irritation", Category: "anger", NameJa: "苛立ち", NameEn: "Irritation"},
+	{Key: "shame", Category: "disgust", NameJa: "恥ずかしさ", NameEn: "Shame"},
+}
+
+// BuiltinEmotionLabels は組み込みの感情ラベル一覧を返す
+func BuiltinEmotionLabels() []EmotionLabel {
+	labels := make([]EmotionLabel, 0, len(primaryEmotions)+len(derivedEmotions))
+	labels = append(labels, primaryEmotions...)
+	labels = append(labels, derivedEmotions...)
+	return labels
+}
+
+// IsPrimaryEmotion はキーが基本感情かどうかを返す
+func IsPrimaryEmotion(key string) bool {
+	for _, l := range primaryEmotions {
+		if l.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCustomEmotionLabel はユーザー定義の感情ラベルを検証して生成する
+func NewCustomEmotionLabel(userID, key, category, nameJa, nameEn string) (EmotionLabel, error) {
+	if !emotionKeyPattern.MatchString(key) {
+		return EmotionLabel{}, fmt.Errorf("感情ラベルのキーが不正です: %s", key)
+	}
+	for _, l := range BuiltinEmotionLabels() {
+		if l.Key == key {
+			return EmotionLabel{}, fmt.Errorf("組み込みの感情ラベルと重複しています: %s", key)
+		}
+	}
+	if !IsPrimaryEmotion(category) {
+		return EmotionLabel{}, fmt.Errorf("感情ラベルの分類が不正です: %s", category)
+	}
+	if nameJa == "" && nameEn == "" {
+		return EmotionLabel{}, errors.New("感情ラベルの名前が必要です")
+	}
+	if nameJa == "" {
+		nameJa = nameEn
+	}
+	return EmotionLabel{Key: key, UserID: userID, Category: category, NameJa: nameJa, NameEn: nameEn}, nil
+}
+
+// Taxonomy は組み込みラベルとユーザー定義ラベルを合わせた感情ラベル体系
+type Taxonomy struct {
+	labels []EmotionLabel
+	index  map[string]EmotionLabel
+}
+
+// NewTaxonomy は組み込みラベルにユーザー定義ラベルを追加した体系を生成する
+func NewTaxonomy(custom []EmotionLabel) *Taxonomy {
+	labels := append(BuiltinEmotionLabels(), custom...)
+	index := make(map[string]EmotionLabel, len(labels))
+	for _, l := range labels {
+		index[l.Key] = l
+	}
+	return &Taxonomy{labels: labels, index: index}
+}
+
+// Labels は体系に含まれる全ラベルを返す
+func (t *Taxonomy) Labels() []EmotionLabel {
+	return t.labels
+}
+
+// Lookup はキーに対応するラベルを返す
+func (t *Taxonomy) Lookup(key string) (EmotionLabel, bool) {
+	l, ok := t.index[key]
+	return l, ok
+}
+
+// Validate は感情ラベルの組み合わせが体系に沿っているかを検証する
+func (t *Taxonomy) Validate(emotions []Emotion) error {
+	if len(emotions) > MaxEmotionsPerDiary {
+		return fmt.Errorf("感情ラベルは%d個までです", MaxEmotionsPerDiary)
+	}
+	seen := make(map[string]bool, len(emotions))
+	for _, e := range emotions {
+		if _, ok := t.index[e.Key]; !ok {
+			return fmt.Errorf("未登録の感情ラベルです: %s", e.Key)
+		}
+		if seen[e.Key] {
+			return fmt.Errorf("感情ラベルが重複しています: %s", e.Key)
+		}
+		seen[e.Key] = true
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package diary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEmotion(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		intensity int
+		wantErr   bool
+	}{
+		{name: "正常系: 強度なし", key: "joy", intensity: 0},
+		{name: "正常系: 強度あり", key: "anxiety", intensity: 5},
+		{name: "異常系: 強度が範囲外", key: "joy", intensity: 6, wantErr: true},
+		{name: "異常系: 負の強度", key: "joy", intensity: -1, wantErr: true},
+		{name: "異常系: キーが不正", key: "不安", intensity: 1, wantErr: true},
+		{name: "異常系: 空のキー", key: "", intensity: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEmotion(tt.key, tt.intensity)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, Emotion{Key: tt.key, Intensity: tt.intensity}, e)
+		})
+	}
+}
+
+func TestNewCustomEmotionLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		category string
+		nameJa   string
+		nameEn   string
+		wantErr  bool
+	}{
+		{name: "正常系", key: "homesick", category: "sadness", nameJa: "ホームシック", nameEn: "Homesick"},
+		{name: "正常系: 英語名のみ", key: "homesick", category: "sadness", nameEn: "Homesick"},
+		{name: "異常系: 組み込みラベルと重複", key: "anxiety", category: "fear", nameJa: "不安", wantErr: true},
+		{name: "異常系: 分類が基本感情でない", key: "homesick", category: "anxiety", nameJa: "ホームシック", wantErr: true},
+		{name: "異常系: 名前なし", key: "homesick", category: "sadness", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewCustomEmotionLabel("user-1", tt.key, tt.category, tt.nameJa, tt.nameEn)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, l.IsBuiltin())
+			assert.NotEmpty(t, l.Name(LocaleJa))
+		})
+	}
+}
+
+func TestTaxonomy_Validate(t *testing.T) {
+	taxonomy := NewTaxonomy([]EmotionLabel{{Key: "homesick", UserID: "user-1", Category: "sadness", NameJa: "ホームシック"}})
+
+	tests := []struct {
+		name     string
+		emotions []Emotion
+		wantErr  string
+	}{
+		{name: "正常系: 組み込みラベル", emotions: []Emotion{{Key: "joy"}, {Key: "anxiety", Intensity: 3}}},
+		{name: "正常系: ユーザー定義ラベル", emotions: []Emotion{{Key: "homesick"}}},
+		{name: "異常系: 未登録のラベル", emotions: []Emotion{{Key: "unknown"}}, wantErr: "未登録の感情ラベルです: unknown"},
+		{name: "異常系: 重複", emotions: []Emotion{{Key: "joy"}, {Key: "joy", Intensity: 2}}, wantErr: "感情ラベルが重複しています: joy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := taxonomy.Validate(tt.emotions)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEmotionLabel_Name(t *testing.T) {
+	taxonomy := NewTaxonomy(nil)
+	l, ok := taxonomy.Lookup("anxiety")
+	assert.True(t, ok)
+	assert.Equal(t, "不安", l.Name(LocaleJa))
+	assert.Equal(t, "Anxiety", l.Name(ParseLocale("en")))
+	assert.Equal(t, "不安", l.Name(ParseLocale("fr")))
+}
+
+func TestFilter_Apply(t *testing.T) {
+	m5, _ := NewMental(5)
+	diaries := []Diary{
+		{ID: "1", Mental: m5, Emotions: []Emotion{{Key: "joy", Intensity: 4}}},
+		{ID: "2", Mental: m5, Emotions: []Emotion{{Key: "anxiety", Intensity: 2}}},
+		{ID: "3", Mental: m5},
+	}
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantIDs []string
+	}{
+		{name: "条件なしは全件", filter: Filter{}, wantIDs: []string{"1", "2", "3"}},
+		{name: "感情ラベルで絞り込み", filter: Filter{Emotions: []string{"joy", "anxiety"}}, wantIDs: []string{"1", "2"}},
+		{name: "強度で絞り込み", filter: Filter{Emotions: []string{"joy", "anxiety"}, MinIntensity: 3}, wantIDs: []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for _, d := range tt.filter.Apply(diaries) {
+				ids = append(ids, d.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestEmotionFrequencies(t *testing.T) {
+	m2, _ := NewMental(2)
+	m4, _ := NewMental(4)
+	m8, _ := NewMental(8)
+	diaries := []Diary{
+		{Mental: m2, Emotions: []Emotion{{Key: "anxiety", Intensity: 4}, {Key: "fatigue"}}},
+		{Mental: m4, Emotions: []Emotion{{Key: "anxiety", Intensity: 2}}},
+		{Mental: m8, Emotions: []Emotion{{Key: "joy", Intensity: 5}}},
+	}
+	stats := EmotionFrequencies(diaries)
+	assert.Equal(t, []EmotionStat{
+		{Key: "anxiety", Count: 2, AverageIntensity: 3, AverageMental: 3},
+		{Key: "fatigue", Count: 1, AverageIntensity: 0, AverageMental: 2},
+		{Key: "joy", Count: 1, AverageIntensity: 5, AverageMental: 8},
+	}, stats)
+}
@@ -0,0 +1,45 @@
+// Filter: 日記一覧の絞り込み条件を表現するモデル
+
+package diary
+
+// Filter は日記一覧の絞り込み条件（空の場合は全件一致）
+type Filter struct {
+	// Emotions のいずれかの感情ラベルが付与された日記に一致する
+	Emotions []string
+	// MinIntensity 以上の強度で Emotions が付与された日記に一致する（0は強度を問わない）
+	MinIntensity int
+}
+
+// IsEmpty は絞り込み条件が指定されていないかを返す
+func (f Filter) IsEmpty() bool {
+	return len(f.Emotions) == 0
+}
+
+// Match は日記が絞り込み条件に一致するかを返す
+func (f Filter) Match(d Diary) bool {
+	if len(f.Emotions) == 0 {
+		return true
+	}
+	for _, e := range d.Emotions {
+		for _, key := range f.Emotions {
+			if e.Key == key && e.Intensity >= f.MinIntensity {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply は条件に一致する日記のみを返す
+func (f Filter) Apply(diaries []Diary) []Diary {
+	if f.IsEmpty() {
+		return diaries
+	}
+	filtered := make([]Diary, 0, len(diaries))
+	for _, d := range diaries {
+		if f.Match(d) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
@@ -53,7 +53,7 @@ func TestDiary_MarshalJSON(t *testing.T) {
 	if jsonStr == `{"ID":"1","UserID":"200","Date":"2025-01-20","Mental":{"Value":7},"Diary":"テスト日記"}` {
 		t.Errorf("Mental should be serialized as int, not object. Got: %s", jsonStr)
 	}
-	if jsonStr == `{"ID":"1","UserID":"200","Date":"2025-01-20","Mental":7,"Diary":"テスト日記"}` {
+	if jsonStr == `{"ID":"1","UserID":"200","Date":"2025-01-20","Mental":7,"Diary":"テスト日記","Emotions":null}` {
 		t.Logf("Mental is correctly serialized as int: %s", jsonStr)
 	} else {
 		t.Errorf("Unexpected JSON format: %s", jsonStr)
@@ -0,0 +1,71 @@
+package diary
+
+import "fmt"
+
+// ConflictPolicy はアカウント統合時に同じ日付の日記が両方にある場合の解決方針
+type ConflictPolicy string
+
+const (
+	// ConflictKeepExisting は統合先の日記を残し、ゲストの日記を破棄する
+	ConflictKeepExisting ConflictPolicy = "keep_existing"
+	// ConflictKeepGuest はゲストの日記で統合先の日記を上書きする
+	ConflictKeepGuest ConflictPolicy = "keep_guest"
+	// ConflictConcatenate は本文を連結し、メンタルスコアは平均、感情ラベルは和集合にする
+	ConflictConcatenate ConflictPolicy = "concatenate"
+)
+
+// ParseConflictPolicy は文字列から解決方針を生成する（未指定の場合は統合先を優先）
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch ConflictPolicy(value) {
+	case "":
+		return ConflictKeepExisting, nil
+	case ConflictKeepExisting, ConflictKeepGuest, ConflictConcatenate:
+		return ConflictPolicy(value), nil
+	default:
+		return "", fmt.Errorf("日付の重複時の解決方針が不正です: %s", value)
+	}
+}
+
+// ResolveConflict は同じ日付の2つの日記から、統合後に残す日記を返す（ID・ユーザー・日付は統合先のものを引き継ぐ）
+func ResolveConflict(existing, incoming Diary, policy ConflictPolicy) Diary {
+	resolved := existing
+	switch policy {
+	case ConflictKeepGuest:
+		resolved.Mental = incoming.Mental
+		resolved.Diary = incoming.Diary
+		resolved.Emotions = incoming.Emotions
+	case ConflictConcatenate:
+		resolved.Mental = Mental((int(existing.Mental) + int(incoming.Mental) + 1) / 2)
+		switch {
+		case existing.Diary == "":
+			resolved.Diary = incoming.Diary
+		case incoming.Diary != "":
+			resolved.Diary = existing.Diary + "\n\n" + incoming.Diary
+		}
+		resolved.Emotions = mergeEmotions(existing.Emotions, incoming.Emotions)
+	}
+	return resolved
+}
+
+// mergeEmotions は感情ラベルの和集合を返す（同じキーは強い方を採用し、上限を超える分は切り捨てる）
+func mergeEmotions(a, b []Emotion) []Emotion {
+	merged := make([]Emotion, 0, len(a)+len(b))
+	index := make(map[string]int, len(a)+len(b))
+	for _, e := range append(append([]Emotion{}, a...), b...) {
+		if i, ok := index[e.Key]; ok {
+			if e.Intensity > merged[i].Intensity {
+				merged[i].Intensity = e.Intensity
+			}
+			continue
+		}
+		if len(merged) >= MaxEmotionsPerDiary {
+			continue
+		}
+		index[e.Key] = len(merged)
+		merged = append(merged, e)
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
@@ -0,0 +1,55 @@
+package diary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	p, err := ParseConflictPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictKeepExisting, p)
+
+	p, err = ParseConflictPolicy("concatenate")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictConcatenate, p)
+
+	_, err = ParseConflictPolicy("newest")
+	assert.Error(t, err)
+}
+
+func TestResolveConflict(t *testing.T) {
+	existing := Diary{ID: "d1", UserID: "u1", Date: "2025-05-01", Mental: 8, Diary: "朝は良かった",
+		Emotions: []Emotion{{Key: "joy", Intensity: 2}, {Key: "calm"}}}
+	incoming := Diary{ID: "d2", UserID: "guest", Date: "2025-05-01", Mental: 3, Diary: "夜に落ち込んだ",
+		Emotions: []Emotion{{Key: "joy", Intensity: 4}, {Key: "sadness", Intensity: 3}}}
+
+	tests := []struct {
+		name   string
+		policy ConflictPolicy
+		want   Diary
+	}{
+		{
+			name:   "統合先を優先",
+			policy: ConflictKeepExisting,
+			want:   existing,
+		},
+		{
+			name:   "ゲストを優先",
+			policy: ConflictKeepGuest,
+			want:   Diary{ID: "d1", UserID: "u1", Date: "2025-05-01", Mental: 3, Diary: "夜に落ち込んだ", Emotions: incoming.Emotions},
+		},
+		{
+			name:   "連結",
+			policy: ConflictConcatenate,
+			want: Diary{ID: "d1", UserID: "u1", Date: "2025-05-01", Mental: 6, Diary: "朝は良かった\n\n夜に落ち込んだ",
+				Emotions: []Emotion{{Key: "joy", Intensity: 4}, {Key: "calm"}, {Key: "sadness", Intensity: 3}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ResolveConflict(existing, incoming, tt.policy))
+		})
+	}
+}
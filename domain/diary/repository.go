@@ -2,15 +2,30 @@
 
 package diary
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrDiaryNotFound は指定した日付の日記がないことを表す
+var ErrDiaryNotFound = errors.New("指定された日付の日記が見つかりません")
 
 type DiaryRepository interface {
 	FindAll(ctx context.Context) ([]Diary, error)
 	FindByUserID(ctx context.Context, userID string) ([]Diary, error)
-	FindByUserIDAndDate(ctx context.Context, userID string, date string) (*Diary, error)
-	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]Diary, error)
+	// FindByUserIDAndDate は日記がない場合にErrDiaryNotFoundを返す
+	FindByUserIDAndDate(ctx context.Context, userID string, date Date) (*Diary, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate Date) ([]Diary, error)
 	Create(ctx context.Context, diary *Diary) error
-	Update(ctx context.Context, userID string, date string, diary *Diary) error
-	Delete(ctx context.Context, userID string, date string) error
+	Update(ctx context.Context, userID string, date Date, diary *Diary) error
+	Delete(ctx context.Context, userID string, date Date) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// EmotionLabelRepositoryインターフェース: ユーザー定義の感情ラベルの永続化を抽象化する
+type EmotionLabelRepository interface {
+	FindByUserID(ctx context.Context, userID string) ([]EmotionLabel, error)
+	Create(ctx context.Context, label *EmotionLabel) error
+	Delete(ctx context.Context, userID string, key string) error
 	DeleteByUserID(ctx context.Context, userID string) error
 }
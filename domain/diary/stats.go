@@ -0,0 +1,62 @@
+// 日記の統計: 感情ラベルの出現頻度などの集計ロジック
+
+package diary
+
+import "sort"
+
+// EmotionStat は感情ラベルごとの出現頻度の集計結果
+type EmotionStat struct {
+	Key string
+	// Count はラベルが付与された日記の件数
+	Count int
+	// AverageIntensity は強度が指定された日記のみの平均強度（指定がなければ0）
+	AverageIntensity float64
+	// AverageMental はラベルが付与された日記のメンタルスコアの平均
+	AverageMental float64
+}
+
+// EmotionFrequencies は日記に付与された感情ラベルの出現頻度を件数の多い順に集計する
+func EmotionFrequencies(diaries []Diary) []EmotionStat {
+	type acc struct {
+		count          int
+		intensitySum   int
+		intensityCount int
+		mentalSum      int
+	}
+	accs := make(map[string]*acc)
+	for _, d := range diaries {
+		for _, e := range d.Emotions {
+			a, ok := accs[e.Key]
+			if !ok {
+				a = &acc{}
+				accs[e.Key] = a
+			}
+			a.count++
+			a.mentalSum += d.Mental.Value()
+			if e.Intensity != EmotionIntensityNone {
+				a.intensitySum += e.Intensity
+				a.intensityCount++
+			}
+		}
+	}
+
+	stats := make([]EmotionStat, 0, len(accs))
+	for key, a := range accs {
+		stat := EmotionStat{
+			Key:           key,
+			Count:         a.count,
+			AverageMental: float64(a.mentalSum) / float64(a.count),
+		}
+		if a.intensityCount > 0 {
+			stat.AverageIntensity = float64(a.intensitySum) / float64(a.intensityCount)
+		}
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Key < stats[j].Key
+	})
+	return stats
+}
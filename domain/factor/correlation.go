@@ -0,0 +1,132 @@
+// 因子とメンタルスコアの相関分析: 「睡眠 < 6時間 → メンタル −1.8」のような傾向を算出する
+
+package factor
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// MinSamples は相関・差分を算出するのに必要な最小日数（条件一致・不一致それぞれ）
+const MinSamples = 3
+
+// Insight は1つの因子についての相関分析の結果
+type Insight struct {
+	Definition Definition
+	// SampleSize は因子とメンタルスコアの両方が記録された日数
+	SampleSize int
+	// Average は因子の平均値（真偽値の場合は「あり」の割合）
+	Average float64
+	// Correlation はメンタルスコアとのピアソン相関係数（算出できない場合はnil）
+	Correlation *float64
+	Condition   Condition
+	// MatchedDays は条件に一致した日数
+	MatchedDays int
+	// MoodDelta は条件に一致した日の平均メンタルスコアと一致しない日の差（算出できない場合はnil）
+	MoodDelta *float64
+}
+
+// Summary は分析結果を一文で表す（例: "睡眠時間 < 6時間 → メンタル -1.8"）
+func (i Insight) Summary() string {
+	if i.MoodDelta == nil {
+		return ""
+	}
+	delta := strconv.FormatFloat(*i.MoodDelta, 'f', 1, 64)
+	if *i.MoodDelta >= 0 {
+		delta = "+" + delta
+	}
+	return i.Definition.Name + " " + i.Definition.FormatCondition(i.Condition) + " → メンタル " + delta
+}
+
+// Correlate は日付ごとのメンタルスコアと因子の記録から因子ごとの分析結果を算出する
+func Correlate(moods map[string]float64, records []DailyFactors, defs []Definition) []Insight {
+	type pair struct{ factor, mood float64 }
+	pairs := make(map[string][]pair)
+	for _, r := range records {
+		mood, ok := moods[r.Date]
+		if !ok {
+			continue
+		}
+		for key, v := range r.Values() {
+			pairs[key] = append(pairs[key], pair{factor: v, mood: mood})
+		}
+	}
+
+	insights := make([]Insight, 0, len(defs))
+	for _, def := range defs {
+		ps := pairs[def.Key]
+		if len(ps) == 0 {
+			continue
+		}
+		xs := make([]float64, len(ps))
+		ys := make([]float64, len(ps))
+		for i, p := range ps {
+			xs[i], ys[i] = p.factor, p.mood
+		}
+
+		insight := Insight{Definition: def, SampleSize: len(ps), Average: math.Round(mean(xs)*100) / 100, Condition: def.Condition}
+		if def.Type == TypeNumeric {
+			if insight.Condition.Op == "" {
+				insight.Condition = Condition{Op: "<", Value: median(xs)}
+			}
+			if len(ps) >= MinSamples {
+				insight.Correlation = pearson(xs, ys)
+			}
+		}
+
+		var matched, others []float64
+		for _, p := range ps {
+			if insight.Condition.Match(p.factor) {
+				matched = append(matched, p.mood)
+			} else {
+				others = append(others, p.mood)
+			}
+		}
+		insight.MatchedDays = len(matched)
+		if len(matched) >= MinSamples && len(others) >= MinSamples {
+			delta := round1(mean(matched) - mean(others))
+			insight.MoodDelta = &delta
+		}
+		insights = append(insights, insight)
+	}
+	return insights
+}
+
+func mean(xs []float64) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func median(xs []float64) float64 {
+	sorted := append([]float64(nil), xs...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func pearson(xs, ys []float64) *float64 {
+	mx, my := mean(xs), mean(ys)
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return nil
+	}
+	r := math.Round(cov/math.Sqrt(vx*vy)*100) / 100
+	return &r
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
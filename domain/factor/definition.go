@@ -0,0 +1,139 @@
+// Definition値オブジェクト: 因子の種類・値の範囲・相関分析で用いる条件を表現するモデル
+
+package factor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// Type は因子の値の型
+type Type string
+
+const (
+	TypeNumeric Type = "numeric"
+	TypeBoolean Type = "boolean"
+)
+
+// 組み込み因子のキー
+const (
+	KeySleepHours      = "sleep_hours"
+	KeyExerciseMinutes = "exercise_minutes"
+	KeyAlcoholUnits    = "alcohol_units"
+	KeyMedicationTaken = "medication_taken"
+)
+
+// MaxCustomDefinitions はユーザーが定義できる因子の上限
+const MaxCustomDefinitions = 20
+
+var definitionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// Condition は相関分析で「条件に一致する日」を判定するための比較条件
+type Condition struct {
+	Op    string // "<", ">=", "=="
+	Value float64
+}
+
+// Match は値が条件に一致するかを返す
+func (c Condition) Match(v float64) bool {
+	switch c.Op {
+	case "<":
+		return v < c.Value
+	case ">=":
+		return v >= c.Value
+	case "==":
+		return v == c.Value
+	}
+	return false
+}
+
+// Definition は因子の定義（組み込み・ユーザー定義の両方）
+type Definition struct {
+	Key    string
+	UserID string // 組み込み因子の場合は空
+	Name   string
+	Type   Type
+	Unit   string
+	// 数値型の値の範囲
+	Min float64
+	Max float64
+	// 相関分析で用いる条件（Opが空の場合は中央値未満を条件とする）
+	Condition Condition
+}
+
+// IsBuiltin は組み込み因子かどうかを返す
+func (d Definition) IsBuiltin() bool {
+	return d.UserID == ""
+}
+
+// FormatCondition は条件を表示用の文字列にする（例: "< 6時間", "= なし"）
+func (d Definition) FormatCondition(c Condition) string {
+	if d.Type == TypeBoolean {
+		if c.Value == 1 {
+			return "= あり"
+		}
+		return "= なし"
+	}
+	return c.Op + " " + strconv.FormatFloat(c.Value, 'f', -1, 64) + d.Unit
+}
+
+func (d Definition) validateNumber(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) || v < d.Min || v > d.Max {
+		return fmt.Errorf("%sは%s〜%sの範囲で指定してください", d.Name,
+			strconv.FormatFloat(d.Min, 'f', -1, 64), strconv.FormatFloat(d.Max, 'f', -1, 64))
+	}
+	return nil
+}
+
+// BuiltinDefinitions は組み込み因子の定義一覧を返す
+func BuiltinDefinitions() []Definition {
+	return []Definition{
+		{Key: KeySleepHours, Name: "睡眠時間", Type: TypeNumeric, Unit: "時間", Min: 0, Max: 24, Condition: Condition{Op: "<", Value: 6}},
+		{Key: KeyExerciseMinutes, Name: "運動時間", Type: TypeNumeric, Unit: "分", Min: 0, Max: 1440, Condition: Condition{Op: ">=", Value: 30}},
+		{Key: KeyAlcoholUnits, Name: "飲酒量", Type: TypeNumeric, Unit: "単位", Min: 0, Max: 50, Condition: Condition{Op: ">=", Value: 2}},
+		{Key: KeyMedicationTaken, Name: "服薬", Type: TypeBoolean, Condition: Condition{Op: "==", Value: 0}},
+	}
+}
+
+// NewCustomDefinition はユーザー定義の因子を検証して生成する
+func NewCustomDefinition(userID, key, name string, typ Type, unit string, min, max float64) (Definition, error) {
+	if !definitionKeyPattern.MatchString(key) {
+		return Definition{}, fmt.Errorf("因子のキーが不正です: %s", key)
+	}
+	if _, ok := indexDefinitions(BuiltinDefinitions())[key]; ok {
+		return Definition{}, fmt.Errorf("組み込みの因子と重複しています: %s", key)
+	}
+	if name == "" {
+		return Definition{}, errors.New("因子の名前が必要です")
+	}
+	def := Definition{Key: key, UserID: userID, Name: name, Type: typ, Unit: unit}
+	switch typ {
+	case TypeNumeric:
+		if math.IsNaN(min) || math.IsNaN(max) || min >= max {
+			return Definition{}, errors.New("因子の範囲が不正です（min < max）")
+		}
+		def.Min, def.Max = min, max
+	case TypeBoolean:
+		def.Unit = ""
+		def.Condition = Condition{Op: "==", Value: 1}
+	default:
+		return Definition{}, fmt.Errorf("因子の型が不正です: %s", typ)
+	}
+	return def, nil
+}
+
+// AllDefinitions は組み込み因子にユーザー定義因子を追加した一覧を返す
+func AllDefinitions(custom []Definition) []Definition {
+	return append(BuiltinDefinitions(), custom...)
+}
+
+func indexDefinitions(defs []Definition) map[string]Definition {
+	index := make(map[string]Definition, len(defs))
+	for _, d := range defs {
+		index[d.Key] = d
+	}
+	return index
+}
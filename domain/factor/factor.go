@@ -0,0 +1,118 @@
+// DailyFactorsエンティティ: 睡眠・運動・飲酒・服薬など日ごとの生活因子を表現するモデル
+
+package factor
+
+import (
+	"fmt"
+	"time"
+)
+
+// DailyFactors は特定の日付に紐づく生活因子の記録
+type DailyFactors struct {
+	ID     string
+	UserID string
+	Date   string
+	// 組み込み因子（nilは未記録）
+	SleepHours      *float64
+	ExerciseMinutes *int
+	AlcoholUnits    *float64
+	MedicationTaken *bool
+	// ユーザー定義因子
+	Custom []Value
+}
+
+// Value はユーザー定義因子の値（Typeに応じてNumberかBoolのどちらかを持つ）
+type Value struct {
+	Key    string   `json:"key"`
+	Number *float64 `json:"number,omitempty"`
+	Bool   *bool    `json:"bool,omitempty"`
+}
+
+// Validate は日付の形式と各因子の値が定義に沿っているかを検証する
+func (f DailyFactors) Validate(custom []Definition) error {
+	if _, err := time.Parse("2006-01-02", f.Date); err != nil {
+		return fmt.Errorf("日付の形式が不正です: %s", f.Date)
+	}
+	builtin := indexDefinitions(BuiltinDefinitions())
+	if f.SleepHours != nil {
+		if err := builtin[KeySleepHours].validateNumber(*f.SleepHours); err != nil {
+			return err
+		}
+	}
+	if f.ExerciseMinutes != nil {
+		if err := builtin[KeyExerciseMinutes].validateNumber(float64(*f.ExerciseMinutes)); err != nil {
+			return err
+		}
+	}
+	if f.AlcoholUnits != nil {
+		if err := builtin[KeyAlcoholUnits].validateNumber(*f.AlcoholUnits); err != nil {
+			return err
+		}
+	}
+
+	defs := indexDefinitions(custom)
+	seen := make(map[string]bool, len(f.Custom))
+	for _, v := range f.Custom {
+		def, ok := defs[v.Key]
+		if !ok {
+			return fmt.Errorf("未登録の因子です: %s", v.Key)
+		}
+		if seen[v.Key] {
+			return fmt.Errorf("因子が重複しています: %s", v.Key)
+		}
+		seen[v.Key] = true
+		switch def.Type {
+		case TypeNumeric:
+			if v.Number == nil || v.Bool != nil {
+				return fmt.Errorf("因子%sには数値を指定してください", v.Key)
+			}
+			if err := def.validateNumber(*v.Number); err != nil {
+				return err
+			}
+		case TypeBoolean:
+			if v.Bool == nil || v.Number != nil {
+				return fmt.Errorf("因子%sには真偽値を指定してください", v.Key)
+			}
+		}
+	}
+	return nil
+}
+
+// IsEmpty はどの因子も記録されていないかを返す
+func (f DailyFactors) IsEmpty() bool {
+	return f.SleepHours == nil && f.ExerciseMinutes == nil && f.AlcoholUnits == nil &&
+		f.MedicationTaken == nil && len(f.Custom) == 0
+}
+
+// Values は記録された因子を数値（真偽値は1/0）としてキーごとに返す
+func (f DailyFactors) Values() map[string]float64 {
+	values := make(map[string]float64)
+	if f.SleepHours != nil {
+		values[KeySleepHours] = *f.SleepHours
+	}
+	if f.ExerciseMinutes != nil {
+		values[KeyExerciseMinutes] = float64(*f.ExerciseMinutes)
+	}
+	if f.AlcoholUnits != nil {
+		values[KeyAlcoholUnits] = *f.AlcoholUnits
+	}
+	if f.MedicationTaken != nil {
+		values[KeyMedicationTaken] = boolToFloat(*f.MedicationTaken)
+	}
+	for _, v := range f.Custom {
+		switch {
+		case v.Number != nil:
+			values[v.Key] = *v.Number
+		case v.Bool != nil:
+			values[v.Key] = boolToFloat(*v.Bool)
+		}
+	}
+	return values
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
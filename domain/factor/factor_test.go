@@ -0,0 +1,139 @@
+package factor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptrFloat(v float64) *float64 { return &v }
+func ptrInt(v int) *int           { return &v }
+func ptrBool(v bool) *bool        { return &v }
+
+func TestDailyFactors_Validate(t *testing.T) {
+	custom := []Definition{
+		{Key: "caffeine_cups", UserID: "u1", Name: "カフェイン", Type: TypeNumeric, Min: 0, Max: 10},
+		{Key: "meditation", UserID: "u1", Name: "瞑想", Type: TypeBoolean},
+	}
+
+	tests := []struct {
+		name    string
+		factors DailyFactors
+		wantErr string
+	}{
+		{
+			name:    "正常系: 組み込み因子",
+			factors: DailyFactors{Date: "2025-05-01", SleepHours: ptrFloat(7.5), ExerciseMinutes: ptrInt(30), AlcoholUnits: ptrFloat(0), MedicationTaken: ptrBool(true)},
+		},
+		{
+			name:    "正常系: ユーザー定義因子",
+			factors: DailyFactors{Date: "2025-05-01", Custom: []Value{{Key: "caffeine_cups", Number: ptrFloat(3)}, {Key: "meditation", Bool: ptrBool(false)}}},
+		},
+		{
+			name:    "異常系: 日付の形式",
+			factors: DailyFactors{Date: "2025/05/01", SleepHours: ptrFloat(7)},
+			wantErr: "日付の形式が不正です: 2025/05/01",
+		},
+		{
+			name:    "異常系: 睡眠時間が範囲外",
+			factors: DailyFactors{Date: "2025-05-01", SleepHours: ptrFloat(25)},
+			wantErr: "睡眠時間は0〜24の範囲で指定してください",
+		},
+		{
+			name:    "異常系: 運動時間が負",
+			factors: DailyFactors{Date: "2025-05-01", ExerciseMinutes: ptrInt(-1)},
+			wantErr: "運動時間は0〜1440の範囲で指定してください",
+		},
+		{
+			name:    "異常系: 未登録の因子",
+			factors: DailyFactors{Date: "2025-05-01", Custom: []Value{{Key: "unknown", Number: ptrFloat(1)}}},
+			wantErr: "未登録の因子です: unknown",
+		},
+		{
+			name:    "異常系: 型の不一致",
+			factors: DailyFactors{Date: "2025-05-01", Custom: []Value{{Key: "meditation", Number: ptrFloat(1)}}},
+			wantErr: "因子meditationには真偽値を指定してください",
+		},
+		{
+			name:    "異常系: ユーザー定義因子の範囲外",
+			factors: DailyFactors{Date: "2025-05-01", Custom: []Value{{Key: "caffeine_cups", Number: ptrFloat(11)}}},
+			wantErr: "カフェインは0〜10の範囲で指定してください",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.factors.Validate(custom)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewCustomDefinition(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		typ     Type
+		min     float64
+		max     float64
+		wantErr bool
+	}{
+		{name: "正常系: 数値", key: "caffeine_cups", typ: TypeNumeric, min: 0, max: 10},
+		{name: "正常系: 真偽値", key: "meditation", typ: TypeBoolean},
+		{name: "異常系: 組み込み因子と重複", key: KeySleepHours, typ: TypeNumeric, min: 0, max: 24, wantErr: true},
+		{name: "異常系: 範囲が不正", key: "caffeine_cups", typ: TypeNumeric, min: 10, max: 0, wantErr: true},
+		{name: "異常系: 型が不正", key: "caffeine_cups", typ: Type("text"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := NewCustomDefinition("u1", tt.key, "名前", tt.typ, "", tt.min, tt.max)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, def.IsBuiltin())
+		})
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	moods := map[string]float64{
+		"2025-05-01": 3, "2025-05-02": 4, "2025-05-03": 3,
+		"2025-05-04": 6, "2025-05-05": 5, "2025-05-06": 4,
+	}
+	records := []DailyFactors{
+		{Date: "2025-05-01", SleepHours: ptrFloat(5), MedicationTaken: ptrBool(true)},
+		{Date: "2025-05-02", SleepHours: ptrFloat(5.5), MedicationTaken: ptrBool(true)},
+		{Date: "2025-05-03", SleepHours: ptrFloat(4)},
+		{Date: "2025-05-04", SleepHours: ptrFloat(8)},
+		{Date: "2025-05-05", SleepHours: ptrFloat(7)},
+		{Date: "2025-05-06", SleepHours: ptrFloat(7.5)},
+		// メンタルスコアが記録されていない日は除外される
+		{Date: "2025-05-07", SleepHours: ptrFloat(3)},
+	}
+
+	insights := Correlate(moods, records, BuiltinDefinitions())
+	assert.Len(t, insights, 2)
+
+	sleep := insights[0]
+	assert.Equal(t, KeySleepHours, sleep.Definition.Key)
+	assert.Equal(t, 6, sleep.SampleSize)
+	assert.Equal(t, 3, sleep.MatchedDays)
+	if assert.NotNil(t, sleep.MoodDelta) {
+		assert.Equal(t, -1.7, *sleep.MoodDelta)
+	}
+	if assert.NotNil(t, sleep.Correlation) {
+		assert.Greater(t, *sleep.Correlation, 0.8)
+	}
+	assert.Equal(t, "睡眠時間 < 6時間 → メンタル -1.7", sleep.Summary())
+
+	medication := insights[1]
+	assert.Equal(t, KeyMedicationTaken, medication.Definition.Key)
+	assert.Equal(t, 2, medication.SampleSize)
+	assert.Nil(t, medication.MoodDelta, "サンプル数が不足する場合は差分を算出しない")
+	assert.Equal(t, "", medication.Summary())
+}
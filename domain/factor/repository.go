@@ -0,0 +1,22 @@
+// Repositoryインターフェース: DailyFactorsエンティティと因子定義の永続化を抽象化する
+
+package factor
+
+import "context"
+
+type Repository interface {
+	FindByUserID(ctx context.Context, userID string) ([]DailyFactors, error)
+	FindByUserIDAndDate(ctx context.Context, userID string, date string) (*DailyFactors, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]DailyFactors, error)
+	// Upsert は同じ日付の記録があれば上書きし、なければ作成する
+	Upsert(ctx context.Context, factors *DailyFactors) error
+	Delete(ctx context.Context, userID string, date string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+type DefinitionRepository interface {
+	FindByUserID(ctx context.Context, userID string) ([]Definition, error)
+	Create(ctx context.Context, def *Definition) error
+	Delete(ctx context.Context, userID string, key string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,98 @@
+// DailyHighlightsエンティティ: 日ごとの「良かったこと」リストとハイライト・ローライトを表現するモデル
+
+package gratitude
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxGoodThings は1日に記録できる「良かったこと」の上限
+	MaxGoodThings = 5
+	// MaxTextLength は各項目の最大文字数
+	MaxTextLength = 200
+	// LowMentalThreshold はこの値以下のメンタルスコアを「調子が悪い日」とみなす（1〜10のスコア）
+	LowMentalThreshold = 4
+)
+
+// DailyHighlights は特定の日付の良かったこと・ハイライト・ローライト
+type DailyHighlights struct {
+	ID         string
+	UserID     string
+	Date       string
+	GoodThings []string
+	Highlight  string
+	Lowlight   string
+}
+
+// Normalize は各項目の前後の空白を取り除き、空の「良かったこと」を除外する
+func (h *DailyHighlights) Normalize() {
+	goodThings := make([]string, 0, len(h.GoodThings))
+	for _, g := range h.GoodThings {
+		if g = strings.TrimSpace(g); g != "" {
+			goodThings = append(goodThings, g)
+		}
+	}
+	h.GoodThings = goodThings
+	h.Highlight = strings.TrimSpace(h.Highlight)
+	h.Lowlight = strings.TrimSpace(h.Lowlight)
+}
+
+// Validate は日付の形式・件数・文字数を検証する
+func (h DailyHighlights) Validate() error {
+	if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+		return fmt.Errorf("日付の形式が不正です: %s", h.Date)
+	}
+	if len(h.GoodThings) > MaxGoodThings {
+		return fmt.Errorf("良かったことは%d個までです", MaxGoodThings)
+	}
+	for _, g := range h.GoodThings {
+		if utf8.RuneCountInString(g) > MaxTextLength {
+			return fmt.Errorf("良かったことは%d文字以内で入力してください", MaxTextLength)
+		}
+	}
+	if utf8.RuneCountInString(h.Highlight) > MaxTextLength {
+		return fmt.Errorf("ハイライトは%d文字以内で入力してください", MaxTextLength)
+	}
+	if utf8.RuneCountInString(h.Lowlight) > MaxTextLength {
+		return fmt.Errorf("ローライトは%d文字以内で入力してください", MaxTextLength)
+	}
+	if h.IsEmpty() {
+		return errors.New("良かったこと・ハイライト・ローライトのいずれかを入力してください")
+	}
+	return nil
+}
+
+// IsEmpty は何も記録されていないかを返す
+func (h DailyHighlights) IsEmpty() bool {
+	return len(h.GoodThings) == 0 && h.Highlight == "" && h.Lowlight == ""
+}
+
+// Memory は振り返り用に取り出した過去の良かったこと
+type Memory struct {
+	Date string
+	Text string
+}
+
+// Memories は良かったこととハイライトを振り返り用の一覧にする（ローライトは含めない）
+func Memories(records []DailyHighlights) []Memory {
+	var memories []Memory
+	for _, h := range records {
+		for _, g := range h.GoodThings {
+			memories = append(memories, Memory{Date: h.Date, Text: g})
+		}
+		if h.Highlight != "" {
+			memories = append(memories, Memory{Date: h.Date, Text: h.Highlight})
+		}
+	}
+	return memories
+}
+
+// IsLowMental はメンタルスコアが過去の良かったことを振り返る目安を下回っているかを返す
+func IsLowMental(mental int) bool {
+	return mental <= LowMentalThreshold
+}
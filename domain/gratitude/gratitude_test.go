@@ -0,0 +1,54 @@
+package gratitude
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyHighlights_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		h       DailyHighlights
+		wantErr string
+	}{
+		{name: "正常系：良かったことのみ", h: DailyHighlights{Date: "2025-05-01", GoodThings: []string{"散歩した"}}},
+		{name: "正常系：ローライトのみ", h: DailyHighlights{Date: "2025-05-01", Lowlight: "寝坊した"}},
+		{name: "異常系：日付が不正", h: DailyHighlights{Date: "2025/05/01", Highlight: "晴れた"}, wantErr: "日付の形式が不正です: 2025/05/01"},
+		{name: "異常系：件数超過", h: DailyHighlights{Date: "2025-05-01", GoodThings: []string{"1", "2", "3", "4", "5", "6"}}, wantErr: "良かったことは5個までです"},
+		{name: "異常系：文字数超過", h: DailyHighlights{Date: "2025-05-01", Highlight: strings.Repeat("あ", MaxTextLength+1)}, wantErr: "ハイライトは200文字以内で入力してください"},
+		{name: "異常系：空", h: DailyHighlights{Date: "2025-05-01"}, wantErr: "良かったこと・ハイライト・ローライトのいずれかを入力してください"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.h.Validate()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDailyHighlights_Normalize(t *testing.T) {
+	h := DailyHighlights{GoodThings: []string{" 散歩した ", "", "  "}, Highlight: " 晴れた\n"}
+	h.Normalize()
+	assert.Equal(t, []string{"散歩した"}, h.GoodThings)
+	assert.Equal(t, "晴れた", h.Highlight)
+}
+
+func TestMemories(t *testing.T) {
+	records := []DailyHighlights{
+		{Date: "2025-05-01", GoodThings: []string{"散歩した", "友人と話した"}, Lowlight: "寝坊した"},
+		{Date: "2025-05-02", Highlight: "昇進した"},
+	}
+	memories := Memories(records)
+	assert.Equal(t, []Memory{
+		{Date: "2025-05-01", Text: "散歩した"},
+		{Date: "2025-05-01", Text: "友人と話した"},
+		{Date: "2025-05-02", Text: "昇進した"},
+	}, memories)
+}
@@ -0,0 +1,17 @@
+// Repositoryインターフェース: DailyHighlightsエンティティの永続化を抽象化する
+
+package gratitude
+
+import "context"
+
+type Repository interface {
+	FindByUserID(ctx context.Context, userID string) ([]DailyHighlights, error)
+	FindByUserIDAndDate(ctx context.Context, userID string, date string) (*DailyHighlights, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]DailyHighlights, error)
+	// FindBefore は指定日より前の記録を返す
+	FindBefore(ctx context.Context, userID string, date string) ([]DailyHighlights, error)
+	// Upsert は同じ日付の記録があれば上書きし、なければ作成する
+	Upsert(ctx context.Context, highlights *DailyHighlights) error
+	Delete(ctx context.Context, userID string, date string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
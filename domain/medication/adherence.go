@@ -0,0 +1,111 @@
+// 服薬アドヒアランス: 予定された服用に対して実際に服用できた割合を集計する
+
+package medication
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxAdherenceDays は一度に集計できる期間の上限日数
+const MaxAdherenceDays = 366
+
+// DailyAdherence は1日分の服薬状況
+type DailyAdherence struct {
+	Date      string
+	Scheduled int
+	Taken     int
+	Skipped   int
+	// Missed は記録のない予定服用の数
+	Missed int
+	// Rate は服用率（%）。予定がない日はnil
+	Rate *float64
+	// Mental はその日の日記のメンタルスコア（日記がない日はnil）
+	Mental *int
+}
+
+// AdherenceReport は期間全体の服薬状況と日ごとの推移
+type AdherenceReport struct {
+	StartDate string
+	EndDate   string
+	Scheduled int
+	Taken     int
+	Skipped   int
+	Missed    int
+	Rate      *float64
+	Days      []DailyAdherence
+}
+
+// ComputeAdherence は期間内の予定服用と服用記録から日ごとの服用率を算出する
+func ComputeAdherence(meds []Medication, logs []DoseLog, startDate, endDate string) (*AdherenceReport, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("日付の形式が不正です: %s", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("日付の形式が不正です: %s", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end_dateはstart_date以降を指定してください")
+	}
+	if int(end.Sub(start).Hours()/24) >= MaxAdherenceDays {
+		return nil, fmt.Errorf("期間は%d日以内で指定してください", MaxAdherenceDays)
+	}
+
+	// 薬ID・日付・時刻ごとの記録
+	recorded := make(map[string]DoseStatus, len(logs))
+	for _, l := range logs {
+		recorded[doseKey(l.MedicationID, l.Date, l.ScheduledTime)] = l.Status
+	}
+
+	report := &AdherenceReport{StartDate: startDate, EndDate: endDate}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		day := DailyAdherence{Date: date}
+		for _, m := range meds {
+			for _, t := range m.ScheduledTimesOn(date) {
+				day.Scheduled++
+				switch recorded[doseKey(m.ID, date, t)] {
+				case DoseTaken:
+					day.Taken++
+				case DoseSkipped:
+					day.Skipped++
+				default:
+					day.Missed++
+				}
+			}
+		}
+		day.Rate = adherenceRate(day.Taken, day.Scheduled)
+		report.Scheduled += day.Scheduled
+		report.Taken += day.Taken
+		report.Skipped += day.Skipped
+		report.Missed += day.Missed
+		report.Days = append(report.Days, day)
+	}
+	report.Rate = adherenceRate(report.Taken, report.Scheduled)
+	return report, nil
+}
+
+// OverlayMood は日ごとの服薬状況にメンタルスコアを重ねる
+func (r *AdherenceReport) OverlayMood(moods map[string]int) {
+	for i := range r.Days {
+		if mental, ok := moods[r.Days[i].Date]; ok {
+			mental := mental
+			r.Days[i].Mental = &mental
+		}
+	}
+}
+
+func adherenceRate(taken, scheduled int) *float64 {
+	if scheduled == 0 {
+		return nil
+	}
+	rate := math.Round(float64(taken)/float64(scheduled)*1000) / 10
+	return &rate
+}
+
+func doseKey(medicationID, date, scheduledTime string) string {
+	return medicationID + "|" + date + "|" + scheduledTime
+}
@@ -0,0 +1,65 @@
+// DoseLogエンティティ: 予定された1回分の服用の記録（服用済み・スキップ）を表現するモデル
+
+package medication
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DoseStatus は服用記録の状態
+type DoseStatus string
+
+const (
+	DoseTaken   DoseStatus = "taken"
+	DoseSkipped DoseStatus = "skipped"
+)
+
+// ParseDoseStatus は文字列からDoseStatusを生成する
+func ParseDoseStatus(value string) (DoseStatus, error) {
+	switch DoseStatus(value) {
+	case DoseTaken, DoseSkipped:
+		return DoseStatus(value), nil
+	}
+	return "", fmt.Errorf("服用記録の状態が不正です: %s", value)
+}
+
+// DoseLog は予定された服用1回分の記録
+type DoseLog struct {
+	ID            string
+	UserID        string
+	MedicationID  string
+	Date          string // YYYY-MM-DD形式
+	ScheduledTime string // HH:MM形式
+	Status        DoseStatus
+	// TakenAt は実際に服用した日時（スキップの場合はnil）
+	TakenAt *time.Time
+	Note    string
+}
+
+// NewDoseLog は服用記録が薬のスケジュールに沿っているかを検証して生成する
+func NewDoseLog(m Medication, date, scheduledTime string, status DoseStatus, takenAt *time.Time, note string) (DoseLog, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return DoseLog{}, fmt.Errorf("日付の形式が不正です: %s", date)
+	}
+	if !m.Schedule.HasTime(scheduledTime) {
+		return DoseLog{}, fmt.Errorf("服用時刻がスケジュールにありません: %s", scheduledTime)
+	}
+	times := m.ScheduledTimesOn(date)
+	if len(times) == 0 {
+		return DoseLog{}, errors.New("指定された日は服用予定日ではありません")
+	}
+	if status == DoseSkipped {
+		takenAt = nil
+	}
+	return DoseLog{
+		UserID:        m.UserID,
+		MedicationID:  m.ID,
+		Date:          date,
+		ScheduledTime: scheduledTime,
+		Status:        status,
+		TakenAt:       takenAt,
+		Note:          note,
+	}, nil
+}
@@ -0,0 +1,140 @@
+// Medicationエンティティ: ユーザーが服用している薬と服用スケジュールを表現するモデル
+
+package medication
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxNameLength は薬の名前の最大文字数
+	MaxNameLength = 100
+	// MaxDoseLength は用量の最大文字数
+	MaxDoseLength = 100
+	// MaxTimesPerDay は1日に設定できる服用時刻の上限
+	MaxTimesPerDay = 12
+)
+
+// Schedule は服用スケジュール（服用時刻と曜日）
+type Schedule struct {
+	// Times は服用時刻（HH:MM形式）
+	Times []string `json:"times"`
+	// Weekdays は服用する曜日（0=日曜〜6=土曜）。空の場合は毎日
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+}
+
+// Validate は服用時刻と曜日の形式を検証し、服用時刻を昇順に並べ替える
+func (s *Schedule) Validate() error {
+	if len(s.Times) == 0 {
+		return errors.New("服用時刻を1つ以上指定してください")
+	}
+	if len(s.Times) > MaxTimesPerDay {
+		return fmt.Errorf("服用時刻は%d個までです", MaxTimesPerDay)
+	}
+	seen := make(map[string]bool, len(s.Times))
+	for _, t := range s.Times {
+		if _, err := time.Parse("15:04", t); err != nil {
+			return fmt.Errorf("服用時刻の形式が不正です: %s", t)
+		}
+		if seen[t] {
+			return fmt.Errorf("服用時刻が重複しています: %s", t)
+		}
+		seen[t] = true
+	}
+	sort.Strings(s.Times)
+	for _, w := range s.Weekdays {
+		if w < time.Sunday || w > time.Saturday {
+			return fmt.Errorf("曜日の指定が不正です: %d", w)
+		}
+	}
+	return nil
+}
+
+// IncludesWeekday は指定の曜日が服用日かどうかを返す
+func (s Schedule) IncludesWeekday(w time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range s.Weekdays {
+		if d == w {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTime は指定の時刻がスケジュールに含まれるかを返す
+func (s Schedule) HasTime(t string) bool {
+	for _, st := range s.Times {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Medication はユーザーが登録した薬
+type Medication struct {
+	ID       string
+	UserID   string
+	Name     string
+	Dose     string // 例: "5mg 1錠"
+	Schedule Schedule
+	// StartDate は服用開始日、EndDate は服用終了日（空の場合は継続中）。いずれもYYYY-MM-DD形式
+	StartDate string
+	EndDate   string
+	// RemindersEnabled が true の場合、服用時刻にリマインダーを送信する
+	RemindersEnabled bool
+}
+
+// Validate は薬の登録内容を検証する
+func (m *Medication) Validate() error {
+	if m.Name == "" {
+		return errors.New("薬の名前を入力してください")
+	}
+	if utf8.RuneCountInString(m.Name) > MaxNameLength {
+		return fmt.Errorf("薬の名前は%d文字以内で入力してください", MaxNameLength)
+	}
+	if utf8.RuneCountInString(m.Dose) > MaxDoseLength {
+		return fmt.Errorf("用量は%d文字以内で入力してください", MaxDoseLength)
+	}
+	if err := m.Schedule.Validate(); err != nil {
+		return err
+	}
+	if _, err := time.Parse("2006-01-02", m.StartDate); err != nil {
+		return fmt.Errorf("日付の形式が不正です: %s", m.StartDate)
+	}
+	if m.EndDate != "" {
+		if _, err := time.Parse("2006-01-02", m.EndDate); err != nil {
+			return fmt.Errorf("日付の形式が不正です: %s", m.EndDate)
+		}
+		if m.EndDate < m.StartDate {
+			return errors.New("服用終了日は服用開始日以降を指定してください")
+		}
+	}
+	return nil
+}
+
+// IsActiveOn は指定日（YYYY-MM-DD形式）が服用期間内かどうかを返す
+func (m Medication) IsActiveOn(date string) bool {
+	if date < m.StartDate {
+		return false
+	}
+	return m.EndDate == "" || date <= m.EndDate
+}
+
+// ScheduledTimesOn は指定日に予定されている服用時刻を返す
+func (m Medication) ScheduledTimesOn(date string) []string {
+	if !m.IsActiveOn(date) {
+		return nil
+	}
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil || !m.Schedule.IncludesWeekday(d.Weekday()) {
+		return nil
+	}
+	return m.Schedule.Times
+}
@@ -0,0 +1,167 @@
+package medication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedication_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		med     Medication
+		wantErr string
+	}{
+		{
+			name: "正常系：毎日2回",
+			med:  Medication{Name: "薬A", Schedule: Schedule{Times: []string{"20:00", "08:00"}}, StartDate: "2025-05-01"},
+		},
+		{
+			name:    "異常系：名前が空",
+			med:     Medication{Schedule: Schedule{Times: []string{"08:00"}}, StartDate: "2025-05-01"},
+			wantErr: "薬の名前を入力してください",
+		},
+		{
+			name:    "異常系：服用時刻がない",
+			med:     Medication{Name: "薬A", StartDate: "2025-05-01"},
+			wantErr: "服用時刻を1つ以上指定してください",
+		},
+		{
+			name:    "異常系：服用時刻の形式が不正",
+			med:     Medication{Name: "薬A", Schedule: Schedule{Times: []string{"25:00"}}, StartDate: "2025-05-01"},
+			wantErr: "服用時刻の形式が不正です: 25:00",
+		},
+		{
+			name:    "異常系：服用時刻が重複",
+			med:     Medication{Name: "薬A", Schedule: Schedule{Times: []string{"08:00", "08:00"}}, StartDate: "2025-05-01"},
+			wantErr: "服用時刻が重複しています: 08:00",
+		},
+		{
+			name:    "異常系：曜日が不正",
+			med:     Medication{Name: "薬A", Schedule: Schedule{Times: []string{"08:00"}, Weekdays: []time.Weekday{7}}, StartDate: "2025-05-01"},
+			wantErr: "曜日の指定が不正です: 7",
+		},
+		{
+			name:    "異常系：終了日が開始日より前",
+			med:     Medication{Name: "薬A", Schedule: Schedule{Times: []string{"08:00"}}, StartDate: "2025-05-01", EndDate: "2025-04-30"},
+			wantErr: "服用終了日は服用開始日以降を指定してください",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.med.Validate()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	med := Medication{Name: "薬A", Schedule: Schedule{Times: []string{"20:00", "08:00"}}, StartDate: "2025-05-01"}
+	assert.NoError(t, med.Validate())
+	assert.Equal(t, []string{"08:00", "20:00"}, med.Schedule.Times)
+}
+
+func TestMedication_ScheduledTimesOn(t *testing.T) {
+	// 月・水・金のみ、5月末まで
+	med := Medication{
+		Schedule:  Schedule{Times: []string{"08:00"}, Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday}},
+		StartDate: "2025-05-01",
+		EndDate:   "2025-05-31",
+	}
+	assert.Nil(t, med.ScheduledTimesOn("2025-04-30"))                      // 開始前
+	assert.Equal(t, []string{"08:00"}, med.ScheduledTimesOn("2025-05-02")) // 金曜
+	assert.Nil(t, med.ScheduledTimesOn("2025-05-03"))                      // 土曜
+	assert.Nil(t, med.ScheduledTimesOn("2025-06-02"))                      // 終了後の月曜
+}
+
+func TestNewDoseLog(t *testing.T) {
+	med := Medication{ID: "m1", UserID: "u1", Schedule: Schedule{Times: []string{"08:00"}}, StartDate: "2025-05-01"}
+	now := time.Now()
+
+	log, err := NewDoseLog(med, "2025-05-01", "08:00", DoseSkipped, &now, "")
+	assert.NoError(t, err)
+	assert.Nil(t, log.TakenAt)
+	assert.Equal(t, "u1", log.UserID)
+
+	_, err = NewDoseLog(med, "2025-05-01", "09:00", DoseTaken, &now, "")
+	assert.EqualError(t, err, "服用時刻がスケジュールにありません: 09:00")
+
+	_, err = NewDoseLog(med, "2025-04-30", "08:00", DoseTaken, &now, "")
+	assert.EqualError(t, err, "指定された日は服用予定日ではありません")
+
+	_, err = ParseDoseStatus("forgot")
+	assert.Error(t, err)
+}
+
+func TestComputeAdherence(t *testing.T) {
+	meds := []Medication{
+		{ID: "m1", Schedule: Schedule{Times: []string{"08:00", "20:00"}}, StartDate: "2025-05-01"},
+		{ID: "m2", Schedule: Schedule{Times: []string{"12:00"}}, StartDate: "2025-05-02"},
+	}
+	logs := []DoseLog{
+		{MedicationID: "m1", Date: "2025-05-01", ScheduledTime: "08:00", Status: DoseTaken},
+		{MedicationID: "m1", Date: "2025-05-01", ScheduledTime: "20:00", Status: DoseTaken},
+		{MedicationID: "m1", Date: "2025-05-02", ScheduledTime: "08:00", Status: DoseTaken},
+		{MedicationID: "m1", Date: "2025-05-02", ScheduledTime: "20:00", Status: DoseSkipped},
+	}
+
+	report, err := ComputeAdherence(meds, logs, "2025-05-01", "2025-05-02")
+	assert.NoError(t, err)
+	assert.Len(t, report.Days, 2)
+
+	day1 := report.Days[0]
+	assert.Equal(t, 2, day1.Scheduled)
+	assert.Equal(t, 100.0, *day1.Rate)
+
+	day2 := report.Days[1]
+	assert.Equal(t, 3, day2.Scheduled)
+	assert.Equal(t, 1, day2.Taken)
+	assert.Equal(t, 1, day2.Skipped)
+	assert.Equal(t, 1, day2.Missed)
+	assert.Equal(t, 33.3, *day2.Rate)
+
+	assert.Equal(t, 5, report.Scheduled)
+	assert.Equal(t, 60.0, *report.Rate)
+
+	report.OverlayMood(map[string]int{"2025-05-02": 4})
+	assert.Nil(t, report.Days[0].Mental)
+	assert.Equal(t, 4, *report.Days[1].Mental)
+
+	_, err = ComputeAdherence(meds, logs, "2025-05-02", "2025-05-01")
+	assert.Error(t, err)
+	_, err = ComputeAdherence(meds, logs, "2024-01-01", "2025-05-01")
+	assert.EqualError(t, err, "期間は366日以内で指定してください")
+}
+
+func TestDueReminders(t *testing.T) {
+	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
+	meds := []Medication{
+		{ID: "m1", UserID: "u1", Name: "薬A", Dose: "1錠", Schedule: Schedule{Times: []string{"08:00", "20:00"}}, StartDate: "2025-05-01", RemindersEnabled: true},
+		{ID: "m2", UserID: "u1", Name: "薬B", Schedule: Schedule{Times: []string{"08:00"}}, StartDate: "2025-05-01"},
+		{ID: "m3", UserID: "u1", Name: "薬C", Schedule: Schedule{Times: []string{"08:05"}}, StartDate: "2025-05-01", RemindersEnabled: true},
+	}
+	logs := []DoseLog{{MedicationID: "m3", Date: "2025-05-01", ScheduledTime: "08:05", Status: DoseTaken}}
+
+	from := time.Date(2025, 5, 1, 7, 55, 0, 0, jst)
+	to := time.Date(2025, 5, 1, 8, 10, 0, 0, jst)
+	reminders := DueReminders(meds, logs, from, to)
+
+	// リマインダー無効の薬Bと記録済みの薬Cは通知しない
+	assert.Len(t, reminders, 1)
+	assert.Equal(t, "m1", reminders[0].Medication.ID)
+	assert.Equal(t, "08:00", reminders[0].ScheduledTime)
+
+	msg := reminders[0].Message()
+	assert.Equal(t, "u1", msg.UserID)
+	assert.Equal(t, "薬A（1錠）の服用時刻です", msg.Body)
+
+	// 日付をまたぐ期間
+	from = time.Date(2025, 5, 1, 19, 0, 0, 0, jst)
+	to = time.Date(2025, 5, 2, 8, 0, 0, 0, jst)
+	reminders = DueReminders(meds, nil, from, to)
+	assert.Len(t, reminders, 2) // 1日の20:00と2日の08:00
+}
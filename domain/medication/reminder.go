@@ -0,0 +1,68 @@
+// Reminder: 服用時刻を迎えた未記録の服用に対するリマインダーを生成する
+
+package medication
+
+import (
+	"fmt"
+	"time"
+	"tofunote-backend/domain/notification"
+)
+
+// Reminder は通知すべき1回分の服用
+type Reminder struct {
+	Medication    Medication
+	Date          string
+	ScheduledTime string
+}
+
+// DueReminders は (from, to] の間に服用時刻を迎え、まだ記録されていない服用を返す。
+// 服用時刻は to のタイムゾーン（薬を登録したユーザーのタイムゾーン）で解釈する
+func DueReminders(meds []Medication, logs []DoseLog, from, to time.Time) []Reminder {
+	loc := to.Location()
+	from = from.In(loc)
+	recorded := make(map[string]bool, len(logs))
+	for _, l := range logs {
+		recorded[doseKey(l.MedicationID, l.Date, l.ScheduledTime)] = true
+	}
+
+	var reminders []Reminder
+	firstDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	for d := firstDay; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		for _, m := range meds {
+			if !m.RemindersEnabled {
+				continue
+			}
+			for _, t := range m.ScheduledTimesOn(date) {
+				at, err := time.ParseInLocation("2006-01-02 15:04", date+" "+t, loc)
+				if err != nil || !at.After(from) || at.After(to) {
+					continue
+				}
+				if recorded[doseKey(m.ID, date, t)] {
+					continue
+				}
+				reminders = append(reminders, Reminder{Medication: m, Date: date, ScheduledTime: t})
+			}
+		}
+	}
+	return reminders
+}
+
+// Message はリマインダーを通知メッセージに変換する
+func (r Reminder) Message() notification.Message {
+	body := fmt.Sprintf("%s の服用時刻です", r.Medication.Name)
+	if r.Medication.Dose != "" {
+		body = fmt.Sprintf("%s（%s）の服用時刻です", r.Medication.Name, r.Medication.Dose)
+	}
+	return notification.Message{
+		UserID: r.Medication.UserID,
+		Kind:   notification.KindMedicationReminder,
+		Title:  fmt.Sprintf("お薬の時間です（%s）", r.ScheduledTime),
+		Body:   body,
+		Data: map[string]string{
+			"medication_id":  r.Medication.ID,
+			"date":           r.Date,
+			"scheduled_time": r.ScheduledTime,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+// Repositoryインターフェース: Medicationエンティティと服用記録の永続化を抽象化する
+
+package medication
+
+import "context"
+
+type Repository interface {
+	FindByUserID(ctx context.Context, userID string) ([]Medication, error)
+	FindByID(ctx context.Context, userID string, id string) (*Medication, error)
+	// FindRemindable はリマインダーが有効な全ユーザーの薬を返す
+	FindRemindable(ctx context.Context) ([]Medication, error)
+	Create(ctx context.Context, m *Medication) error
+	Update(ctx context.Context, m *Medication) error
+	Delete(ctx context.Context, userID string, id string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+type DoseLogRepository interface {
+	FindByUserID(ctx context.Context, userID string) ([]DoseLog, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]DoseLog, error)
+	// Upsert は同じ薬・日付・時刻の記録があれば上書きし、なければ作成する
+	Upsert(ctx context.Context, log *DoseLog) error
+	Delete(ctx context.Context, userID string, medicationID string, date string, scheduledTime string) error
+	DeleteByMedicationID(ctx context.Context, userID string, medicationID string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoRecipient はチャネルの宛先（Web Pushの購読・確認済みのメールアドレス・通知のイベントを購読しているWebhook）がないことを表す
+var ErrNoRecipient = errors.New("通知の宛先が登録されていません")
+
+// Channel は通知を配送する手段
+type Channel string
+
+const (
+	ChannelWebPush Channel = "web_push"
+	ChannelEmail   Channel = "email"
+	ChannelWebhook Channel = "webhook"
+)
+
+// SupportedChannels はユーザーが選べる通知チャネル
+var SupportedChannels = []Channel{ChannelWebPush, ChannelEmail, ChannelWebhook}
+
+// Sender は1つのチャネルで通知を配送する（宛先はmsg.UserIDからチャネルごとに解決する）
+type Sender interface {
+	Channel() Channel
+	// Send は宛先がない場合にErrNoRecipientを返す
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,15 @@
+package notification
+
+import "context"
+
+// Mail は送信するメール（本文はプレーンテキスト）
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer はメールを送信するチャネル
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
@@ -0,0 +1,31 @@
+// Notificationインターフェース: ユーザーへの通知（リマインダー等）の送信を抽象化する
+
+package notification
+
+import "context"
+
+// Kind は通知の種類
+type Kind string
+
+const (
+	KindMedicationReminder Kind = "medication_reminder"
+	// KindGuestDeletionNotice は利用のないゲストアカウントの自動削除の予告
+	KindGuestDeletionNotice Kind = "guest_deletion_notice"
+	// KindDiaryReminder はその日の日記をまだ書いていないユーザーへのリマインダー
+	KindDiaryReminder Kind = "diary_reminder"
+)
+
+// Message はユーザーに送信する通知の内容
+type Message struct {
+	UserID string
+	Kind   Kind
+	Title  string
+	Body   string
+	// Data は通知を受け取ったクライアントが画面遷移等に使う付加情報
+	Data map[string]string
+}
+
+// Notifier は通知を配送するチャネル
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,88 @@
+package notification
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidPushSubscription  = errors.New("Web Pushの購読情報が不正です")
+	ErrPushSubscriptionNotFound = errors.New("指定された通知先の端末が見つかりません")
+)
+
+const (
+	maxPushEndpointLength = 2048
+	maxPushUserAgent      = 255
+	// pushAuthSecretLength はブラウザが発行する認証用の秘密のバイト数（RFC 8291）
+	pushAuthSecretLength = 16
+)
+
+// PushSubscription はブラウザ（端末）ごとのWeb Pushの購読
+type PushSubscription struct {
+	ID     string
+	UserID string
+	// Endpoint はブラウザのプッシュサービスが発行した送信先のURL
+	Endpoint string
+	// P256dh・Auth はペイロードの暗号化に使うブラウザの公開鍵と認証用の秘密（Base64URL）
+	P256dh string
+	Auth   string
+	// UserAgent は端末の一覧に表示するための登録時のUser-Agent
+	UserAgent string
+	CreatedAt time.Time
+}
+
+// NewPushSubscription はブラウザの PushSubscription.toJSON() の値を検証して購読を作る
+func NewPushSubscription(userID, endpoint, p256dh, auth, userAgent string) (*PushSubscription, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil || len(endpoint) > maxPushEndpointLength {
+		return nil, ErrInvalidPushSubscription
+	}
+	publicKey, err := DecodePushKey(p256dh)
+	if err != nil {
+		return nil, ErrInvalidPushSubscription
+	}
+	if _, err := ecdh.P256().NewPublicKey(publicKey); err != nil {
+		return nil, ErrInvalidPushSubscription
+	}
+	secret, err := DecodePushKey(auth)
+	if err != nil || len(secret) != pushAuthSecretLength {
+		return nil, ErrInvalidPushSubscription
+	}
+	return &PushSubscription{
+		UserID:    userID,
+		Endpoint:  endpoint,
+		P256dh:    p256dh,
+		Auth:      auth,
+		UserAgent: truncateRunes(userAgent, maxPushUserAgent),
+	}, nil
+}
+
+// DecodePushKey はブラウザが返すBase64URLの鍵をデコードする（パディングの有無どちらも受け付ける）
+func DecodePushKey(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+// PushSubscriptionRepository はWeb Pushの購読の永続化インターフェース
+type PushSubscriptionRepository interface {
+	FindByUserID(ctx context.Context, userID string) ([]PushSubscription, error)
+	// Save は購読を保存する（同じEndpointの購読は上書きし、別のユーザーが登録していた場合も付け替える）
+	Save(ctx context.Context, s *PushSubscription) error
+	// Delete はユーザーの購読を削除する（見つからない場合はErrPushSubscriptionNotFound）
+	Delete(ctx context.Context, userID, id string) error
+	// DeleteByEndpoint はプッシュサービスが失効を返した購読を削除する
+	DeleteByEndpoint(ctx context.Context, endpoint string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
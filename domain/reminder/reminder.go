@@ -0,0 +1,61 @@
+// Reminder: 設定した時刻を迎えたユーザーに日記を書くリマインダーを生成する
+
+package reminder
+
+import (
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+)
+
+// Reminder は通知すべき1回分のリマインダー
+type Reminder struct {
+	UserID string
+	// Date はユーザーのタイムゾーンでのリマインダーの日付（この日の日記を書いていれば送らない）
+	Date diary.Date
+	// Time はリマインダーの時刻（HH:MM形式）
+	Time string
+}
+
+// DueReminders は (from, to] の間にリマインダーの時刻を迎えたユーザーのリマインダーを返す。
+// 時刻・曜日はユーザーごとのタイムゾーンで解釈し、通知チャネルを選んでいないユーザーには送らない
+func DueReminders(users []user.RemindableUser, from, to time.Time) []Reminder {
+	var reminders []Reminder
+	for _, u := range users {
+		if len(u.Preferences.NotificationChannels) == 0 {
+			continue
+		}
+		loc := u.Preferences.Location()
+		localFrom := from.In(loc)
+		firstDay := time.Date(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, 0, loc)
+		for d := firstDay; !d.After(to); d = d.AddDate(0, 0, 1) {
+			if !u.Preferences.RemindsOn(d.Weekday()) {
+				continue
+			}
+			date := diary.DateOf(d)
+			for _, t := range u.Preferences.ReminderTimes {
+				at, err := time.ParseInLocation(diary.DateLayout+" 15:04", date.String()+" "+t, loc)
+				if err != nil || !at.After(from) || at.After(to) {
+					continue
+				}
+				reminders = append(reminders, Reminder{UserID: u.UserID, Date: date, Time: t})
+			}
+		}
+	}
+	return reminders
+}
+
+// Message はリマインダーを通知メッセージに変換する
+func (r Reminder) Message() notification.Message {
+	return notification.Message{
+		UserID: r.UserID,
+		Kind:   notification.KindDiaryReminder,
+		Title:  "今日の日記を書きましょう",
+		Body:   "今日の気分とできごとを記録しませんか？",
+		Data: map[string]string{
+			"date": r.Date.String(),
+			"time": r.Time,
+		},
+	}
+}
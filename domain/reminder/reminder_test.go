@@ -0,0 +1,72 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func remindable(userID, timezone string, times []string, weekdays ...time.Weekday) user.RemindableUser {
+	p := user.DefaultPreferences()
+	p.Timezone = timezone
+	p.ReminderTimes = times
+	p.ReminderWeekdays = weekdays
+	return user.RemindableUser{UserID: userID, Preferences: p}
+}
+
+func TestDueReminders(t *testing.T) {
+	// 2025-06-02（月）12:00 UTC = 東京 21:00 = ニューヨーク 08:00
+	to := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-15 * time.Minute)
+
+	noChannels := remindable("silent", "Asia/Tokyo", []string{"21:00"})
+	noChannels.Preferences.NotificationChannels = []notification.Channel{}
+
+	tests := []struct {
+		name  string
+		users []user.RemindableUser
+		want  []Reminder
+	}{
+		{
+			name:  "ユーザーのタイムゾーンで時刻を判定する",
+			users: []user.RemindableUser{remindable("tokyo", "Asia/Tokyo", []string{"08:00", "21:00"}), remindable("ny", "America/New_York", []string{"08:00", "21:00"})},
+			want: []Reminder{
+				{UserID: "tokyo", Date: "2025-06-02", Time: "21:00"},
+				{UserID: "ny", Date: "2025-06-02", Time: "08:00"},
+			},
+		},
+		{
+			name:  "期間の開始時刻ちょうどは含めない",
+			users: []user.RemindableUser{remindable("tokyo", "Asia/Tokyo", []string{"20:45"})},
+		},
+		{
+			name:  "曜日を指定した場合はその曜日のみ",
+			users: []user.RemindableUser{remindable("weekend", "Asia/Tokyo", []string{"21:00"}, time.Saturday, time.Sunday), remindable("monday", "Asia/Tokyo", []string{"21:00"}, time.Monday)},
+			want:  []Reminder{{UserID: "monday", Date: "2025-06-02", Time: "21:00"}},
+		},
+		{
+			name:  "通知チャネルを選んでいないユーザーには送らない",
+			users: []user.RemindableUser{noChannels},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DueReminders(tt.users, from, to))
+		})
+	}
+}
+
+func TestDueReminders_AcrossMidnight(t *testing.T) {
+	// 東京の日付が変わる前後をまたぐ期間では、それぞれの日付のリマインダーを返す
+	to := time.Date(2025, 6, 2, 15, 30, 0, 0, time.UTC) // 東京 6/3 00:30
+	from := to.Add(-time.Hour)
+	got := DueReminders([]user.RemindableUser{remindable("tokyo", "Asia/Tokyo", []string{"00:15", "23:45"})}, from, to)
+	assert.Equal(t, []Reminder{
+		{UserID: "tokyo", Date: diary.Date("2025-06-02"), Time: "23:45"},
+		{UserID: "tokyo", Date: diary.Date("2025-06-03"), Time: "00:15"},
+	}, got)
+}
@@ -0,0 +1,16 @@
+// Repositoryインターフェース: ThoughtRecordエンティティの永続化を抽象化する
+
+package thoughtrecord
+
+import "context"
+
+type Repository interface {
+	FindByUserID(ctx context.Context, userID string) ([]ThoughtRecord, error)
+	FindByUserIDAndDate(ctx context.Context, userID string, date string) ([]ThoughtRecord, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]ThoughtRecord, error)
+	FindByID(ctx context.Context, userID string, id string) (*ThoughtRecord, error)
+	Create(ctx context.Context, record *ThoughtRecord) error
+	Update(ctx context.Context, record *ThoughtRecord) error
+	Delete(ctx context.Context, userID string, id string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,133 @@
+// ThoughtRecordエンティティ: 認知行動療法（CBT）の思考記録（コラム法）を表現するモデル
+
+package thoughtrecord
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tofunote-backend/domain/diary"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTextLength は各記述欄の最大文字数
+	MaxTextLength = 2000
+	// MaxEmotions は1つの思考記録に記入できる感情の上限
+	MaxEmotions = 10
+	// 感情の強さは思考記録の慣例に合わせて0〜100（%）で記録する
+	RatingMin = 0
+	RatingMax = 100
+)
+
+// EmotionRating は思考記録に記入する感情と、バランス思考の前後での強さ
+type EmotionRating struct {
+	Key    string `json:"key"`
+	Before int    `json:"before"`
+	// After はバランス思考を検討した後の強さ（未記入の場合はnil）
+	After *int `json:"after,omitempty"`
+}
+
+// ThoughtRecord は1件の思考記録
+type ThoughtRecord struct {
+	ID     string
+	UserID string
+	// Date は紐づける日記の日付（YYYY-MM-DD形式）。空の場合は日記に紐づけない
+	Date             string
+	Situation        string
+	AutomaticThought string
+	Emotions         []EmotionRating
+	EvidenceFor      string
+	EvidenceAgainst  string
+	BalancedThought  string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Validate は必須項目・文字数・感情の強さを検証する。感情のキーは感情ラベル体系に登録されている必要がある
+func (r *ThoughtRecord) Validate(taxonomy *diary.Taxonomy) error {
+	if r.Date != "" {
+		if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+			return fmt.Errorf("日付の形式が不正です: %s", r.Date)
+		}
+	}
+	if strings.TrimSpace(r.Situation) == "" {
+		return errors.New("状況を入力してください")
+	}
+	if strings.TrimSpace(r.AutomaticThought) == "" {
+		return errors.New("自動思考を入力してください")
+	}
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"状況", r.Situation},
+		{"自動思考", r.AutomaticThought},
+		{"根拠", r.EvidenceFor},
+		{"反証", r.EvidenceAgainst},
+		{"バランス思考", r.BalancedThought},
+	}
+	for _, f := range fields {
+		if utf8.RuneCountInString(f.value) > MaxTextLength {
+			return fmt.Errorf("%sは%d文字以内で入力してください", f.name, MaxTextLength)
+		}
+	}
+
+	if len(r.Emotions) == 0 {
+		return errors.New("感情を1つ以上記入してください")
+	}
+	if len(r.Emotions) > MaxEmotions {
+		return fmt.Errorf("感情は%d個までです", MaxEmotions)
+	}
+	seen := make(map[string]bool, len(r.Emotions))
+	for _, e := range r.Emotions {
+		if _, ok := taxonomy.Lookup(e.Key); !ok {
+			return fmt.Errorf("未登録の感情ラベルです: %s", e.Key)
+		}
+		if seen[e.Key] {
+			return fmt.Errorf("感情ラベルが重複しています: %s", e.Key)
+		}
+		seen[e.Key] = true
+		if !validRating(e.Before) || (e.After != nil && !validRating(*e.After)) {
+			return fmt.Errorf("感情の強さは%d〜%dで指定してください", RatingMin, RatingMax)
+		}
+	}
+	return nil
+}
+
+// Summary はLLMによる分析やエクスポートで使うテキスト表現を返す
+func (r ThoughtRecord) Summary(taxonomy *diary.Taxonomy) string {
+	emotions := make([]string, 0, len(r.Emotions))
+	for _, e := range r.Emotions {
+		name := e.Key
+		if label, ok := taxonomy.Lookup(e.Key); ok {
+			name = label.Name(diary.LocaleJa)
+		}
+		rating := name + " " + strconv.Itoa(e.Before) + "%"
+		if e.After != nil {
+			rating += "→" + strconv.Itoa(*e.After) + "%"
+		}
+		emotions = append(emotions, rating)
+	}
+	lines := []string{
+		"状況: " + r.Situation,
+		"自動思考: " + r.AutomaticThought,
+		"感情: " + strings.Join(emotions, ", "),
+	}
+	if r.EvidenceFor != "" {
+		lines = append(lines, "根拠: "+r.EvidenceFor)
+	}
+	if r.EvidenceAgainst != "" {
+		lines = append(lines, "反証: "+r.EvidenceAgainst)
+	}
+	if r.BalancedThought != "" {
+		lines = append(lines, "バランス思考: "+r.BalancedThought)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func validRating(v int) bool {
+	return v >= RatingMin && v <= RatingMax
+}
@@ -0,0 +1,66 @@
+package thoughtrecord
+
+import (
+	"strings"
+	"testing"
+	"tofunote-backend/domain/diary"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validRecord() ThoughtRecord {
+	after := 40
+	return ThoughtRecord{
+		Date:             "2025-05-01",
+		Situation:        "会議で発言を遮られた",
+		AutomaticThought: "自分の意見には価値がない",
+		Emotions:         []EmotionRating{{Key: "anxiety", Before: 80, After: &after}},
+		EvidenceFor:      "最後まで話せなかった",
+		EvidenceAgainst:  "後で上司から意見を求められた",
+		BalancedThought:  "時間が押していただけかもしれない",
+	}
+}
+
+func TestThoughtRecord_Validate(t *testing.T) {
+	taxonomy := diary.NewTaxonomy(nil)
+	tooStrong := 120
+
+	tests := []struct {
+		name    string
+		modify  func(r *ThoughtRecord)
+		wantErr string
+	}{
+		{name: "正常系", modify: func(r *ThoughtRecord) {}},
+		{name: "正常系：日記に紐づけない", modify: func(r *ThoughtRecord) { r.Date = "" }},
+		{name: "正常系：バランス思考は未記入でもよい", modify: func(r *ThoughtRecord) { r.BalancedThought = ""; r.Emotions[0].After = nil }},
+		{name: "異常系：存在しない日付", modify: func(r *ThoughtRecord) { r.Date = "2025-02-30" }, wantErr: "日付の形式が不正です: 2025-02-30"},
+		{name: "異常系：状況が空", modify: func(r *ThoughtRecord) { r.Situation = " " }, wantErr: "状況を入力してください"},
+		{name: "異常系：自動思考が空", modify: func(r *ThoughtRecord) { r.AutomaticThought = "" }, wantErr: "自動思考を入力してください"},
+		{name: "異常系：文字数超過", modify: func(r *ThoughtRecord) { r.EvidenceFor = strings.Repeat("あ", MaxTextLength+1) }, wantErr: "根拠は2000文字以内で入力してください"},
+		{name: "異常系：感情がない", modify: func(r *ThoughtRecord) { r.Emotions = nil }, wantErr: "感情を1つ以上記入してください"},
+		{name: "異常系：未登録の感情", modify: func(r *ThoughtRecord) { r.Emotions[0].Key = "unknown" }, wantErr: "未登録の感情ラベルです: unknown"},
+		{name: "異常系：感情の重複", modify: func(r *ThoughtRecord) { r.Emotions = append(r.Emotions, r.Emotions[0]) }, wantErr: "感情ラベルが重複しています: anxiety"},
+		{name: "異常系：強さが範囲外", modify: func(r *ThoughtRecord) { r.Emotions[0].After = &tooStrong }, wantErr: "感情の強さは0〜100で指定してください"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validRecord()
+			tt.modify(&r)
+			err := r.Validate(taxonomy)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestThoughtRecord_Summary(t *testing.T) {
+	r := validRecord()
+	summary := r.Summary(diary.NewTaxonomy(nil))
+	assert.Contains(t, summary, "自動思考: 自分の意見には価値がない")
+	assert.Contains(t, summary, "感情: 不安 80%→40%")
+	assert.Contains(t, summary, "バランス思考: 時間が押していただけかもしれない")
+}
@@ -0,0 +1,12 @@
+// UnitOfWorkインターフェース: 複数のリポジトリへの変更を1つのトランザクションにまとめる
+
+package transaction
+
+import "context"
+
+// UnitOfWork は複数のリポジトリへの変更をまとめて確定・取り消しする
+type UnitOfWork interface {
+	// Do はfnを1つのトランザクションで実行する。fnの中ではfnに渡したctxをリポジトリに渡す。
+	// fnがエラーを返した場合（またはpanicした場合）は全ての変更を取り消す
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,26 @@
+package user
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+// ErrInvalidEmail はメールアドレスが空・長すぎる・形式が不正な場合のエラー
+var ErrInvalidEmail = errors.New("メールアドレスの形式が不正です")
+
+// MaxEmailLength はメールアドレスの最大長（RFC 5321のパスの上限）
+const MaxEmailLength = 254
+
+// NormalizeEmail はメールアドレスの形式を検証し、前後の空白を除いて小文字にそろえる
+func NormalizeEmail(value string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(value))
+	if email == "" || len(email) > MaxEmailLength {
+		return "", ErrInvalidEmail
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
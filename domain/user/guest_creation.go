@@ -0,0 +1,30 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrGuestChallengeUsed は計算課題の解答を別のゲストの作成に使い回そうとした場合のエラー
+var ErrGuestChallengeUsed = errors.New("計算課題の解答は既に使われています")
+
+// GuestCreation はゲストアカウントの作成の記録（作成元のIPアドレスはハッシュ化して保持する）
+type GuestCreation struct {
+	ID     string
+	IPHash string
+	// ChallengeID は作成時に解いた計算課題のID（課題を求めていない場合は空）
+	ChallengeID string
+	CreatedAt   time.Time
+}
+
+// GuestCreationRepository はゲストアカウントの作成の記録の永続化インターフェース
+type GuestCreationRepository interface {
+	// Record は作成を記録する（ChallengeIDが記録済みの場合はErrGuestChallengeUsed）
+	Record(ctx context.Context, creation *GuestCreation) error
+	CountSince(ctx context.Context, since time.Time) (int64, error)
+	CountByIPSince(ctx context.Context, ipHash string, since time.Time) (int64, error)
+	CountDistinctIPsSince(ctx context.Context, since time.Time) (int64, error)
+	// DeleteBefore はbeforeより前の記録を削除し、削除した件数を返す
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
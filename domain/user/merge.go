@@ -0,0 +1,25 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tofunote-backend/domain/diary"
+)
+
+// ErrMergeGuestNotFound は統合の途中でゲストが削除された・ゲストでなくなったことを表す（統合は巻き戻す）
+var ErrMergeGuestNotFound = errors.New("統合するゲストユーザーが見つかりません")
+
+// MergeResult はゲストアカウントを既存アカウントへ統合した結果
+type MergeResult struct {
+	// MovedDiaries は統合先へそのまま移動した日記の件数
+	MovedDiaries int
+	// ConflictedDiaries は同じ日付の日記があり、解決方針に従って統合した件数
+	ConflictedDiaries int
+}
+
+// AccountMerger はゲストのデータを既存アカウントへ移し、ゲストを削除する処理を1トランザクションで行う
+type AccountMerger interface {
+	// MergeGuestInto はゲストのセッションをnowで失効させ、発行済みのアクセストークンを使えなくする
+	MergeGuestInto(ctx context.Context, guestID, targetID string, policy diary.ConflictPolicy, now time.Time) (*MergeResult, error)
+}
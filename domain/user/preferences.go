@@ -0,0 +1,386 @@
+// Preferencesエンティティ: ユーザーのプロフィールと表示・通知などの設定を表現するモデル
+
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"tofunote-backend/domain/notification"
+	"unicode"
+	"unicode/utf8"
+
+	// 実行環境にタイムゾーンのデータがなくても同じ基準で検証できるよう埋め込む
+	_ "time/tzdata"
+)
+
+const (
+	// MaxNicknameLength はニックネームの最大文字数
+	MaxNicknameLength = 50
+	// MaxReminderTimes は1日に設定できるリマインダーの時刻の上限
+	MaxReminderTimes = 5
+	// DefaultTimezone は設定していないユーザーのタイムゾーン
+	DefaultTimezone = "Asia/Tokyo"
+	// DefaultLocale は設定していないユーザーの言語・地域
+	DefaultLocale = "ja-JP"
+)
+
+// SupportedLocales はアプリが対応している言語・地域（BCP 47）
+var SupportedLocales = []string{"ja-JP", "en-US"}
+
+// SupportedMoodScales は気分を記録する段階（記録は1〜10で保存し、5段階の場合はアプリが換算して表示する）
+var SupportedMoodScales = []int{5, 10}
+
+// ErrInvalidProfile はプロフィール・設定の内容が不正であることを表す（詳細はProfileErrorで返す）
+var ErrInvalidProfile = errors.New("プロフィールの内容が不正です")
+
+// ProfileError は不正な項目とその理由を表す
+type ProfileError struct {
+	// Field は不正な項目のJSONでの名前（例: preferences.timezone）
+	Field   string
+	Message string
+}
+
+func (e *ProfileError) Error() string {
+	return e.Message
+}
+
+func (e *ProfileError) Unwrap() error {
+	return ErrInvalidProfile
+}
+
+func invalidField(field, format string, args ...interface{}) error {
+	return &ProfileError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// Theme はアプリの配色
+type Theme string
+
+const (
+	// ThemeSystem は端末の設定に合わせる
+	ThemeSystem Theme = "system"
+	ThemeLight  Theme = "light"
+	ThemeDark   Theme = "dark"
+)
+
+// Preferences はユーザーごとの設定
+type Preferences struct {
+	// Timezone は日付の区切りとリマインダーの時刻の基準にするタイムゾーン（IANAの名前）
+	Timezone string `json:"timezone"`
+	// Locale は表示する言語・地域（BCP 47）
+	Locale string `json:"locale"`
+	// FirstDayOfWeek はカレンダー・週次の集計で週の始まりにする曜日（0=日曜〜6=土曜）
+	FirstDayOfWeek time.Weekday `json:"first_day_of_week"`
+	// MoodScale は気分を記録する段階（5または10）
+	MoodScale int `json:"mood_scale"`
+	// ReminderTimes は日記を書くリマインダーの時刻（HH:MM形式、Timezone基準）。空の場合は送らない
+	ReminderTimes []string `json:"reminder_times"`
+	// ReminderWeekdays はリマインダーを送る曜日（0=日曜〜6=土曜）。空の場合は毎日
+	ReminderWeekdays []time.Weekday `json:"reminder_weekdays"`
+	// NotificationChannels はリマインダーなどの通知を送るチャネル
+	NotificationChannels []notification.Channel `json:"notification_channels"`
+	// AnalysisOptIn は日記の内容をAIによる分析に使うことに同意しているかどうか
+	AnalysisOptIn bool  `json:"analysis_opt_in"`
+	Theme         Theme `json:"theme"`
+}
+
+// DefaultPreferences は設定を保存していないユーザーの設定を返す
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Timezone:             DefaultTimezone,
+		Locale:               DefaultLocale,
+		FirstDayOfWeek:       time.Sunday,
+		MoodScale:            10,
+		ReminderTimes:        []string{},
+		AnalysisOptIn:        false,
+		Theme:                ThemeSystem,
+		ReminderWeekdays:     []time.Weekday{},
+		NotificationChannels: []notification.Channel{notification.ChannelWebPush},
+	}
+}
+
+// Validate は設定の内容を検証し、リマインダーの時刻を昇順に並べ替える
+func (p *Preferences) Validate() error {
+	if p.Timezone == "" || p.Timezone == "Local" {
+		return invalidField("preferences.timezone", "タイムゾーンを指定してください")
+	}
+	if _, err := time.LoadLocation(p.Timezone); err != nil {
+		return invalidField("preferences.timezone", "タイムゾーンが不正です: %s", p.Timezone)
+	}
+	if !containsString(SupportedLocales, p.Locale) {
+		return invalidField("preferences.locale", "対応していない言語です: %s", p.Locale)
+	}
+	if p.FirstDayOfWeek < time.Sunday || p.FirstDayOfWeek > time.Saturday {
+		return invalidField("preferences.first_day_of_week", "曜日の指定が不正です: %d", p.FirstDayOfWeek)
+	}
+	if !containsInt(SupportedMoodScales, p.MoodScale) {
+		return invalidField("preferences.mood_scale", "気分の段階は5または10を指定してください")
+	}
+	if len(p.ReminderTimes) > MaxReminderTimes {
+		return invalidField("preferences.reminder_times", "リマインダーの時刻は%d個までです", MaxReminderTimes)
+	}
+	seen := make(map[string]bool, len(p.ReminderTimes))
+	for _, t := range p.ReminderTimes {
+		if _, err := time.Parse("15:04", t); err != nil {
+			return invalidField("preferences.reminder_times", "リマインダーの時刻の形式が不正です: %s", t)
+		}
+		if seen[t] {
+			return invalidField("preferences.reminder_times", "リマインダーの時刻が重複しています: %s", t)
+		}
+		seen[t] = true
+	}
+	if p.ReminderTimes == nil {
+		p.ReminderTimes = []string{}
+	}
+	sort.Strings(p.ReminderTimes)
+	if err := p.validateReminderWeekdays(); err != nil {
+		return err
+	}
+	switch p.Theme {
+	case ThemeSystem, ThemeLight, ThemeDark:
+	default:
+		return invalidField("preferences.theme", "テーマの指定が不正です: %s", p.Theme)
+	}
+	return p.validateNotification()
+}
+
+// validateReminderWeekdays はリマインダーの曜日を検証し、日曜から順に並べ替える
+func (p *Preferences) validateReminderWeekdays() error {
+	seen := make(map[time.Weekday]bool, len(p.ReminderWeekdays))
+	for _, d := range p.ReminderWeekdays {
+		if d < time.Sunday || d > time.Saturday {
+			return invalidField("preferences.reminder_weekdays", "曜日の指定が不正です: %d", d)
+		}
+		if seen[d] {
+			return invalidField("preferences.reminder_weekdays", "曜日が重複しています: %d", d)
+		}
+		seen[d] = true
+	}
+	if p.ReminderWeekdays == nil {
+		p.ReminderWeekdays = []time.Weekday{}
+	}
+	sort.Slice(p.ReminderWeekdays, func(i, j int) bool { return p.ReminderWeekdays[i] < p.ReminderWeekdays[j] })
+	return nil
+}
+
+// validateNotification は通知チャネルを検証する
+func (p *Preferences) validateNotification() error {
+	seen := make(map[notification.Channel]bool, len(p.NotificationChannels))
+	for _, c := range p.NotificationChannels {
+		if !containsChannel(notification.SupportedChannels, c) {
+			return invalidField("preferences.notification_channels", "対応していない通知チャネルです: %s", c)
+		}
+		if seen[c] {
+			return invalidField("preferences.notification_channels", "通知チャネルが重複しています: %s", c)
+		}
+		seen[c] = true
+	}
+	if p.NotificationChannels == nil {
+		p.NotificationChannels = []notification.Channel{}
+	}
+	return nil
+}
+
+// RemindsOn はその曜日にリマインダーを送るかどうかを返す
+func (p Preferences) RemindsOn(day time.Weekday) bool {
+	if len(p.ReminderWeekdays) == 0 {
+		return true
+	}
+	for _, d := range p.ReminderWeekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Location は設定したタイムゾーンを返す（読み込めない場合は既定のタイムゾーン）
+func (p Preferences) Location() *time.Location {
+	if loc, err := time.LoadLocation(p.Timezone); err == nil {
+		return loc
+	}
+	loc, _ := time.LoadLocation(DefaultTimezone)
+	return loc
+}
+
+// Profile はユーザーが自分で変更できるプロフィールと設定
+type Profile struct {
+	Nickname    string      `json:"nickname"`
+	Preferences Preferences `json:"preferences"`
+}
+
+// Validate はプロフィールと設定の内容を検証する
+func (p *Profile) Validate() error {
+	if utf8.RuneCountInString(p.Nickname) > MaxNicknameLength {
+		return invalidField("nickname", "ニックネームは%d文字以内で入力してください", MaxNicknameLength)
+	}
+	for _, r := range p.Nickname {
+		if unicode.IsControl(r) {
+			return invalidField("nickname", "ニックネームに使えない文字が含まれています")
+		}
+	}
+	return p.Preferences.Validate()
+}
+
+// PatchField はJSON Merge Patch（RFC 7396）の1項目を表す
+// 項目を指定しない場合は変更せず、nullの場合は既定値に戻し、値の場合はその値にする
+type PatchField[T any] struct {
+	// Set は項目を指定したかどうか（nullを指定した場合もtrue）
+	Set bool
+	// Value は指定した値（nullを指定した場合はnil）
+	Value *T
+}
+
+// UnmarshalJSON implements json.Unmarshaler（encoding/jsonは指定した項目の場合のみ呼び出す）
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	f.Value = nil
+	if string(data) == "null" {
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	f.Value = &v
+	return nil
+}
+
+// SetField は値を指定した項目を返す
+func SetField[T any](v T) PatchField[T] {
+	return PatchField[T]{Set: true, Value: &v}
+}
+
+// apply は指定した内容をcurrentに反映する
+func (f PatchField[T]) apply(current *T, def T) {
+	if !f.Set {
+		return
+	}
+	if f.Value == nil {
+		*current = def
+		return
+	}
+	*current = *f.Value
+}
+
+// PreferencesPatch は設定のうち変更する項目
+type PreferencesPatch struct {
+	Timezone             PatchField[string]                 `json:"timezone"`
+	Locale               PatchField[string]                 `json:"locale"`
+	FirstDayOfWeek       PatchField[time.Weekday]           `json:"first_day_of_week"`
+	MoodScale            PatchField[int]                    `json:"mood_scale"`
+	ReminderTimes        PatchField[[]string]               `json:"reminder_times"`
+	AnalysisOptIn        PatchField[bool]                   `json:"analysis_opt_in"`
+	Theme                PatchField[Theme]                  `json:"theme"`
+	ReminderWeekdays     PatchField[[]time.Weekday]         `json:"reminder_weekdays"`
+	NotificationChannels PatchField[[]notification.Channel] `json:"notification_channels"`
+}
+
+// IsEmpty は変更する項目がないかどうかを返す
+func (p PreferencesPatch) IsEmpty() bool {
+	return !p.Timezone.Set && !p.Locale.Set && !p.FirstDayOfWeek.Set && !p.MoodScale.Set &&
+		!p.ReminderTimes.Set && !p.AnalysisOptIn.Set && !p.Theme.Set &&
+		!p.ReminderWeekdays.Set && !p.NotificationChannels.Set
+}
+
+// ProfilePatch はPATCH /api/meで受け付ける変更内容
+// preferencesにnullを指定した場合はすべての設定を既定値に戻す
+type ProfilePatch struct {
+	Nickname    PatchField[string]           `json:"nickname"`
+	Preferences PatchField[PreferencesPatch] `json:"preferences"`
+}
+
+// IsEmpty は変更する項目がないかどうかを返す
+func (p ProfilePatch) IsEmpty() bool {
+	if p.Nickname.Set {
+		return false
+	}
+	if !p.Preferences.Set {
+		return true
+	}
+	return p.Preferences.Value != nil && p.Preferences.Value.IsEmpty()
+}
+
+// Apply は変更内容を反映して検証する（検証に失敗した場合はプロフィールを変更しない）
+func (p *Profile) Apply(patch ProfilePatch) error {
+	next := *p
+	next.Preferences.ReminderTimes = append([]string{}, p.Preferences.ReminderTimes...)
+	next.Preferences.ReminderWeekdays = append([]time.Weekday{}, p.Preferences.ReminderWeekdays...)
+	next.Preferences.NotificationChannels = append([]notification.Channel{}, p.Preferences.NotificationChannels...)
+	if patch.Nickname.Set {
+		patch.Nickname.apply(&next.Nickname, "")
+		next.Nickname = strings.TrimSpace(next.Nickname)
+	}
+	if patch.Preferences.Set {
+		if patch.Preferences.Value == nil {
+			next.Preferences = DefaultPreferences()
+		} else {
+			def := DefaultPreferences()
+			pp := patch.Preferences.Value
+			pp.Timezone.apply(&next.Preferences.Timezone, def.Timezone)
+			pp.Locale.apply(&next.Preferences.Locale, def.Locale)
+			pp.FirstDayOfWeek.apply(&next.Preferences.FirstDayOfWeek, def.FirstDayOfWeek)
+			pp.MoodScale.apply(&next.Preferences.MoodScale, def.MoodScale)
+			pp.ReminderTimes.apply(&next.Preferences.ReminderTimes, def.ReminderTimes)
+			pp.AnalysisOptIn.apply(&next.Preferences.AnalysisOptIn, def.AnalysisOptIn)
+			pp.Theme.apply(&next.Preferences.Theme, def.Theme)
+			pp.ReminderWeekdays.apply(&next.Preferences.ReminderWeekdays, def.ReminderWeekdays)
+			pp.NotificationChannels.apply(&next.Preferences.NotificationChannels, def.NotificationChannels)
+		}
+	}
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	*p = next
+	return nil
+}
+
+// RemindableUser はリマインダーの時刻を設定しているユーザーとその設定
+type RemindableUser struct {
+	UserID      string
+	Preferences Preferences
+}
+
+// PreferencesRepository は設定の永続化インターフェース
+type PreferencesRepository interface {
+	// FindByUserID は保存した設定を返す（保存していない場合はnil）
+	FindByUserID(ctx context.Context, userID string) (*Preferences, error)
+	// FindRemindable はリマインダーの時刻を設定しているユーザーの設定を返す
+	FindRemindable(ctx context.Context) ([]RemindableUser, error)
+	// FindAnalysisOptedInUserIDs はAIによる分析に同意しているユーザーのIDを返す
+	FindAnalysisOptedInUserIDs(ctx context.Context) ([]string, error)
+	// Save は設定を保存する（保存済みの場合は上書きする）
+	Save(ctx context.Context, userID string, p *Preferences) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsChannel(values []notification.Channel, v notification.Channel) bool {
+	for _, c := range values {
+		if c == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, v int) bool {
+	for _, n := range values {
+		if n == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+	"tofunote-backend/domain/notification"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferences_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(p *Preferences)
+		wantField string
+	}{
+		{name: "正常系：既定値", modify: func(p *Preferences) {}},
+		{name: "正常系：すべて指定", modify: func(p *Preferences) {
+			p.Timezone = "America/New_York"
+			p.Locale = "en-US"
+			p.FirstDayOfWeek = time.Monday
+			p.MoodScale = 5
+			p.ReminderTimes = []string{"21:30", "08:00"}
+			p.AnalysisOptIn = true
+			p.Theme = ThemeDark
+			p.ReminderWeekdays = []time.Weekday{time.Saturday, time.Monday}
+			p.NotificationChannels = []notification.Channel{notification.ChannelEmail, notification.ChannelWebhook}
+		}},
+		{name: "正常系：通知を送らない", modify: func(p *Preferences) { p.NotificationChannels = nil }},
+		{name: "異常系：存在しないタイムゾーン", modify: func(p *Preferences) { p.Timezone = "Mars/Olympus" }, wantField: "preferences.timezone"},
+		{name: "異常系：Localは指定できない", modify: func(p *Preferences) { p.Timezone = "Local" }, wantField: "preferences.timezone"},
+		{name: "異常系：対応していない言語", modify: func(p *Preferences) { p.Locale = "fr-FR" }, wantField: "preferences.locale"},
+		{name: "異常系：曜日が範囲外", modify: func(p *Preferences) { p.FirstDayOfWeek = 7 }, wantField: "preferences.first_day_of_week"},
+		{name: "異常系：気分の段階", modify: func(p *Preferences) { p.MoodScale = 7 }, wantField: "preferences.mood_scale"},
+		{name: "異常系：時刻の形式", modify: func(p *Preferences) { p.ReminderTimes = []string{"24:00"} }, wantField: "preferences.reminder_times"},
+		{name: "異常系：時刻の重複", modify: func(p *Preferences) { p.ReminderTimes = []string{"08:00", "08:00"} }, wantField: "preferences.reminder_times"},
+		{name: "異常系：時刻が多すぎる", modify: func(p *Preferences) {
+			p.ReminderTimes = []string{"01:00", "02:00", "03:00", "04:00", "05:00", "06:00"}
+		}, wantField: "preferences.reminder_times"},
+		{name: "異常系：テーマ", modify: func(p *Preferences) { p.Theme = "sepia" }, wantField: "preferences.theme"},
+		{name: "異常系：リマインダーの曜日", modify: func(p *Preferences) { p.ReminderWeekdays = []time.Weekday{-1} }, wantField: "preferences.reminder_weekdays"},
+		{name: "異常系：リマインダーの曜日の重複", modify: func(p *Preferences) {
+			p.ReminderWeekdays = []time.Weekday{time.Monday, time.Monday}
+		}, wantField: "preferences.reminder_weekdays"},
+		{name: "異常系：対応していない通知チャネル", modify: func(p *Preferences) {
+			p.NotificationChannels = []notification.Channel{"sms"}
+		}, wantField: "preferences.notification_channels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultPreferences()
+			tt.modify(&p)
+			err := p.Validate()
+			if tt.wantField == "" {
+				require.NoError(t, err)
+				return
+			}
+			var perr *ProfileError
+			require.True(t, errors.As(err, &perr))
+			assert.Equal(t, tt.wantField, perr.Field)
+			assert.ErrorIs(t, err, ErrInvalidProfile)
+		})
+	}
+}
+
+func TestPreferences_ValidateSortsReminderTimes(t *testing.T) {
+	p := DefaultPreferences()
+	p.ReminderTimes = []string{"21:30", "08:00"}
+	p.ReminderWeekdays = []time.Weekday{time.Saturday, time.Monday}
+	require.NoError(t, p.Validate())
+	assert.Equal(t, []string{"08:00", "21:30"}, p.ReminderTimes)
+	assert.Equal(t, []time.Weekday{time.Monday, time.Saturday}, p.ReminderWeekdays)
+}
+
+func TestPreferences_RemindsOn(t *testing.T) {
+	p := DefaultPreferences()
+	assert.True(t, p.RemindsOn(time.Sunday), "曜日を指定しない場合は毎日")
+	p.ReminderWeekdays = []time.Weekday{time.Monday, time.Friday}
+	assert.True(t, p.RemindsOn(time.Friday))
+	assert.False(t, p.RemindsOn(time.Sunday))
+}
+
+func TestProfile_ApplyMergePatch(t *testing.T) {
+	current := Profile{Nickname: "旧名", Preferences: DefaultPreferences()}
+	current.Preferences.Theme = ThemeDark
+	current.Preferences.ReminderTimes = []string{"21:00"}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    func() Profile
+		wantErr bool
+	}{
+		{
+			name: "指定しない項目は変更しない",
+			body: `{"preferences":{"locale":"en-US"}}`,
+			want: func() Profile {
+				p := current
+				p.Preferences.Locale = "en-US"
+				return p
+			},
+		},
+		{
+			name: "nullの項目は既定値に戻す",
+			body: `{"preferences":{"theme":null,"reminder_times":null}}`,
+			want: func() Profile {
+				p := current
+				p.Preferences.Theme = ThemeSystem
+				p.Preferences.ReminderTimes = []string{}
+				return p
+			},
+		},
+		{
+			name: "preferencesがnullの場合はすべて既定値に戻す",
+			body: `{"nickname":"  新しい名 ","preferences":null}`,
+			want: func() Profile {
+				return Profile{Nickname: "新しい名", Preferences: DefaultPreferences()}
+			},
+		},
+		{
+			name:    "検証に失敗した場合は変更しない",
+			body:    `{"nickname":"新しい名","preferences":{"mood_scale":3}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch ProfilePatch
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &patch))
+			assert.False(t, patch.IsEmpty())
+			p := current
+			p.Preferences.ReminderTimes = append([]string{}, current.Preferences.ReminderTimes...)
+			err := p.Apply(patch)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidProfile)
+				assert.Equal(t, current, p)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want(), p)
+		})
+	}
+}
+
+func TestProfilePatch_IsEmpty(t *testing.T) {
+	for _, body := range []string{`{}`, `{"unknown":"x"}`, `{"preferences":{}}`} {
+		var patch ProfilePatch
+		require.NoError(t, json.Unmarshal([]byte(body), &patch))
+		assert.True(t, patch.IsEmpty(), body)
+	}
+}
@@ -7,9 +7,34 @@ import "context"
 
 type Repository interface {
 	FindByProviderId(ctx context.Context, provider, providerId string) (*User, error)
-	FindByRefreshToken(ctx context.Context, refreshToken string) (*User, error)
+	// FindByEmail は正規化済みのメールアドレスでユーザーを取得する（存在しない場合はnil）
+	FindByEmail(ctx context.Context, email string) (*User, error)
 	Create(ctx context.Context, user *User) error
 	FindByID(ctx context.Context, id string) (*User, error)
 	Update(ctx context.Context, user *User) error
 	DeleteByID(ctx context.Context, id string) error
 }
+
+// SearchQuery は管理者によるユーザー検索の条件
+type SearchQuery struct {
+	// Keyword はID・メールアドレス・ニックネームの部分一致で絞り込む
+	Keyword string
+	Guest   *bool
+	Role    Role
+	Limit   int
+	Offset  int
+}
+
+// Summary は管理者向けのユーザー一覧の項目（日記などの記録の内容は含めない）
+type Summary struct {
+	User
+	DiaryCount int64
+	// LastDiaryDate は最後に日記を書いた日付（YYYY-MM-DD、書いていない場合は空）
+	LastDiaryDate string
+}
+
+// Directory は管理者がユーザーを検索するためのインターフェース
+type Directory interface {
+	// Search は条件に合うユーザーを作成日時の新しい順に返し、合わせて条件に合う総件数を返す
+	Search(ctx context.Context, query SearchQuery) ([]*Summary, int64, error)
+}
@@ -0,0 +1,37 @@
+package user
+
+import (
+	"context"
+	"time"
+)
+
+// GuestRetentionPolicy は利用のないゲストアカウントを自動削除する方針
+type GuestRetentionPolicy struct {
+	// InactiveAfter の間利用のないゲストに削除を予告する
+	InactiveAfter time.Duration
+	// NoticePeriod は予告してから削除するまでの猶予（この間に利用すると予告を取り消す）
+	NoticePeriod time.Duration
+}
+
+// DeletionScheduledAt は削除を予告したゲストの削除予定日時を返す（予告していない場合はnil）
+func (p GuestRetentionPolicy) DeletionScheduledAt(u *User) *time.Time {
+	if !u.IsGuest || u.DeletionNoticeAt == nil {
+		return nil
+	}
+	scheduled := u.DeletionNoticeAt.Add(p.NoticePeriod)
+	return &scheduled
+}
+
+// RetentionRepository は最終利用日時・利用のないゲストアカウントの削除の予告・退会の猶予期間の永続化インターフェース
+type RetentionRepository interface {
+	// TouchLastSeen は最終利用日時がstaleBeforeより前（または削除を予告済み）の場合のみ最終利用日時を更新し、削除の予告を取り消す
+	TouchLastSeen(ctx context.Context, userID string, now, staleBefore time.Time) error
+	// FindGuestsToNotify は最終利用日時（記録がない場合は作成日時）がinactiveBeforeより前で、まだ予告していないゲストを古い順に返す
+	FindGuestsToNotify(ctx context.Context, inactiveBefore time.Time, limit int) ([]*User, error)
+	// FindGuestsToDelete はnoticedBeforeより前に削除を予告し、その後利用していないゲストを古い順に返す
+	FindGuestsToDelete(ctx context.Context, noticedBefore time.Time, limit int) ([]*User, error)
+	// MarkDeletionNoticed は予告していないゲストが今もinactiveBeforeより前から利用していない場合のみ予告日時を記録し、記録したかどうかを返す
+	MarkDeletionNoticed(ctx context.Context, userID string, now, inactiveBefore time.Time) (bool, error)
+	// FindWithdrawnBefore はwithdrawnBeforeより前に退会を申請したユーザーを古い順に返す
+	FindWithdrawnBefore(ctx context.Context, withdrawnBefore time.Time, limit int) ([]*User, error)
+}
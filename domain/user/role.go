@@ -0,0 +1,58 @@
+package user
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInvalidRole = errors.New("ロールが不正です")
+
+// Role はユーザーに付与する権限
+type Role string
+
+const (
+	// RoleAdmin は管理API（/api/admin）を利用できる管理者
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole は文字列を定義済みのロールに変換する
+func ParseRole(value string) (Role, error) {
+	switch Role(value) {
+	case RoleAdmin:
+		return RoleAdmin, nil
+	default:
+		return "", ErrInvalidRole
+	}
+}
+
+// HasRole はユーザーにロールが付与されているかどうかを返す
+func (u *User) HasRole(role Role) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleNames はロールを文字列の一覧にする（アクセストークンのrolesクレームに使う）
+func (u *User) RoleNames() []string {
+	if len(u.Roles) == 0 {
+		return nil
+	}
+	names := make([]string, len(u.Roles))
+	for i, r := range u.Roles {
+		names[i] = string(r)
+	}
+	return names
+}
+
+// RoleRepository はユーザーのロールの永続化インターフェース
+type RoleRepository interface {
+	ListByUserID(ctx context.Context, userID string) ([]Role, error)
+	// Grant はロールを付与する（付与済みの場合は何もしない）
+	Grant(ctx context.Context, userID string, role Role) error
+	// Revoke はロールを取り消す（付与されていない場合は何もしない）
+	Revoke(ctx context.Context, userID string, role Role) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
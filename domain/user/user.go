@@ -4,11 +4,46 @@ import "time"
 
 // Userエンティティ
 type User struct {
-	ID           string
-	Nickname     string
-	Provider     string
-	ProviderID   string
-	IsGuest      bool
-	RefreshToken string
-	CreatedAt    time.Time
+	ID         string
+	Nickname   string
+	Provider   string
+	ProviderID string
+	IsGuest    bool
+	// Email はメールアドレス・パスワード認証で使うメールアドレス（小文字に正規化済み）
+	Email           string
+	EmailVerifiedAt *time.Time
+	// PasswordHash はargon2idでハッシュ化したパスワード（PHC文字列形式）
+	PasswordHash string
+	// Roles はuser_rolesテーブルに保存し、リポジトリが取得時に読み込む
+	Roles []Role `gorm:"-"`
+	// LastSeenAt は認証したリクエストを最後に受け付けた日時（更新は一定間隔ごと。未記録の場合はnil）
+	LastSeenAt *time.Time
+	// DeletionNoticeAt は利用のないゲストに自動削除を予告した日時（再び利用した場合はnilに戻す）
+	DeletionNoticeAt *time.Time
+	// WithdrawnAt は退会を申請した日時（猶予期間を過ぎると完全に削除する。取り消した場合はnilに戻す）
+	WithdrawnAt *time.Time
+	CreatedAt   time.Time
+}
+
+// HasPassword はパスワードでログインできるユーザーかどうかを返す
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// IsEmailVerified はメールアドレスの確認が済んでいるかどうかを返す
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// LastActiveAt は最後に利用した日時を返す（記録がない場合は作成日時）
+func (u *User) LastActiveAt() time.Time {
+	if u.LastSeenAt != nil {
+		return *u.LastSeenAt
+	}
+	return u.CreatedAt
+}
+
+// IsWithdrawn は退会を申請して完全な削除を待っている状態かどうかを返す
+func (u *User) IsWithdrawn() bool {
+	return u.WithdrawnAt != nil
 }
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// DeliveryStatus は配送の状態
+type DeliveryStatus string
+
+const (
+	// DeliveryPending は送信待ち（再送待ちを含む）
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed は再送の上限まで失敗したこと
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+const (
+	// MaxAttempts は1件の配送を試みる最大の回数
+	MaxAttempts = 8
+	// initialBackoff・maxBackoff は再送までの間隔（失敗するごとに2倍にする）
+	initialBackoff = 30 * time.Second
+	maxBackoff     = 6 * time.Hour
+	// maxErrorLength は記録するエラーの最大の文字数
+	maxErrorLength = 500
+)
+
+// Delivery は送信先ごとのイベントの配送（送信待ちの間はキュー、送信後は配送の記録になる）
+type Delivery struct {
+	ID         string
+	EndpointID string
+	UserID     string
+	// EventID はイベントごとのID（受信側が重複を除くために使う。再送しても変わらない）
+	EventID   string
+	EventType EventType
+	// Payload は送信するJSON（再送しても同じ内容・署名対象を送る）
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	// LastStatusCode は最後の送信で受信側が返したステータス（接続できなかった場合は0）
+	LastStatusCode int
+	LastError      string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+// RecordAttempt は送信の結果を記録し、失敗した場合は指数バックオフで次の送信日時を決める
+func (d *Delivery) RecordAttempt(now time.Time, statusCode int, err error) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	if err == nil && statusCode >= 200 && statusCode < 300 {
+		d.Status = DeliverySucceeded
+		d.DeliveredAt = &now
+		return
+	}
+	if err != nil {
+		d.LastError = err.Error()
+		if r := []rune(d.LastError); len(r) > maxErrorLength {
+			d.LastError = string(r[:maxErrorLength])
+		}
+	}
+	if d.Attempts >= MaxAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+	d.Status = DeliveryPending
+	d.NextAttemptAt = now.Add(Backoff(d.Attempts))
+}
+
+// Backoff は attempts 回失敗した後に再送するまでの間隔を返す
+func Backoff(attempts int) time.Duration {
+	backoff := initialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
+
+// DeliveryRepository は配送のキューと記録の永続化インターフェース
+type DeliveryRepository interface {
+	Enqueue(ctx context.Context, deliveries []*Delivery) error
+	// ClaimDue は送信日時を迎えた送信待ちの配送を古い順に返し、lease まで他のジョブが取得しないようにする
+	ClaimDue(ctx context.Context, now, lease time.Time, limit int) ([]*Delivery, error)
+	// Update は送信の結果を保存する
+	Update(ctx context.Context, delivery *Delivery) error
+	// FindByEndpoint は送信先の配送の記録を新しい順に返す
+	FindByEndpoint(ctx context.Context, userID, endpointID string, limit, offset int) ([]*Delivery, error)
+	// DeleteFinishedBefore は送信を終えた配送の記録のうち、before より前に作成したものを削除する
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// Request は受信側に送るHTTPリクエスト
+type Request struct {
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+// Transport は受信側にリクエストを送り、ステータスを返す（テストではhttptestの受信側に送る）
+type Transport interface {
+	Post(ctx context.Context, req Request) (int, error)
+}
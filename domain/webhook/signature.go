@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader は署名を送るヘッダー（t=<UNIX秒>,v1=<HMAC-SHA256の16進数>）
+	SignatureHeader = "X-Tofunote-Signature"
+	EventHeader     = "X-Tofunote-Event"
+	// DeliveryHeader はイベントのID（再送しても変わらない）を送るヘッダー
+	DeliveryHeader = "X-Tofunote-Delivery"
+)
+
+// Sign は "<UNIX秒>.<ボディ>" をsecretでHMAC-SHA256により署名し、SignatureHeaderの値を返す。
+// 時刻を署名に含めるため、受信側は古い時刻のリクエストを拒否して再送攻撃を防げる
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify はSignatureHeaderの値を検証する（受信側の実装の例。tolerance より古い署名は拒否する）
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) bool {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return false
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return false
+	}
+	signature, err := hex.DecodeString(v1)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(signature, mac(secret, t, body))
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,107 @@
+// Endpointエンティティ: ユーザーが登録した外部連携（Slack・Notionなど）へのWebhookの送信先を表現するモデル
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrInvalidURL       = errors.New("WebhookのURLはhttpsのURLで指定してください")
+	ErrInvalidEventType = errors.New("Webhookのイベントの種類が不正です")
+	ErrEndpointNotFound = errors.New("指定されたWebhookが見つかりません")
+)
+
+const maxURLLength = 2048
+
+// EventType はWebhookで通知するイベントの種類
+type EventType string
+
+const (
+	EventDiaryCreated      EventType = "diary.created"
+	EventDiaryUpdated      EventType = "diary.updated"
+	EventDiaryDeleted      EventType = "diary.deleted"
+	EventAnalysisCompleted EventType = "analysis.completed"
+	// EventNotification はリマインダーなどの通知（設定の通知チャネルでwebhookを選んだ場合に送る）
+	EventNotification EventType = "notification"
+	// EventPing は受信側の確認のために送るイベント（購読の有無によらず送る）
+	EventPing EventType = "ping"
+)
+
+// SubscribableEvents はユーザーが購読できるイベント
+var SubscribableEvents = []EventType{EventDiaryCreated, EventDiaryUpdated, EventDiaryDeleted, EventAnalysisCompleted, EventNotification}
+
+// ParseEventTypes は購読するイベントを検証する（空・未対応・重複の場合はErrInvalidEventType）
+func ParseEventTypes(values []string) ([]EventType, error) {
+	if len(values) == 0 {
+		return nil, ErrInvalidEventType
+	}
+	events := make([]EventType, 0, len(values))
+	seen := map[EventType]bool{}
+	for _, v := range values {
+		event := EventType(v)
+		if seen[event] || !isSubscribable(event) {
+			return nil, ErrInvalidEventType
+		}
+		seen[event] = true
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func isSubscribable(event EventType) bool {
+	for _, e := range SubscribableEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateURL はWebhookの送信先のURLを検証する
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil || len(raw) > maxURLLength {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// Endpoint はWebhookの送信先
+type Endpoint struct {
+	ID     string
+	UserID string
+	URL    string
+	// Secret はペイロードの署名（HMAC-SHA256）に使う共通鍵（登録時にのみ平文で返す）
+	Secret      string
+	Events      []EventType
+	Description string
+	CreatedAt   time.Time
+}
+
+// Subscribes は送信先がイベントを購読しているかどうかを返す
+func (e *Endpoint) Subscribes(event EventType) bool {
+	if event == EventPing {
+		return true
+	}
+	for _, s := range e.Events {
+		if s == event {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointRepository はWebhookの送信先の永続化インターフェース
+type EndpointRepository interface {
+	Create(ctx context.Context, endpoint *Endpoint) error
+	FindByUserID(ctx context.Context, userID string) ([]Endpoint, error)
+	// FindByID はユーザーの送信先を返す（見つからない場合はErrEndpointNotFound）
+	FindByID(ctx context.Context, userID, id string) (*Endpoint, error)
+	// Delete は送信先と配送の記録を削除する（見つからない場合はErrEndpointNotFound）
+	Delete(ctx context.Context, userID, id string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"diary.created"}`)
+
+	header := Sign("secret", now, body)
+	assert.Regexp(t, `^t=1748768400,v1=[0-9a-f]{64}$`, header)
+	assert.True(t, Verify("secret", header, body, now.Add(time.Minute), 5*time.Minute))
+
+	assert.False(t, Verify("other", header, body, now, 5*time.Minute), "鍵が異なる")
+	assert.False(t, Verify("secret", header, []byte(`{"type":"diary.deleted"}`), now, 5*time.Minute), "ボディが改ざんされている")
+	assert.False(t, Verify("secret", header, body, now.Add(10*time.Minute), 5*time.Minute), "古い署名")
+	assert.False(t, Verify("secret", "v1=abc", body, now, 5*time.Minute), "時刻がない")
+}
+
+func TestParseEventTypes(t *testing.T) {
+	events, err := ParseEventTypes([]string{"diary.created", "analysis.completed"})
+	require.NoError(t, err)
+	assert.Equal(t, []EventType{EventDiaryCreated, EventAnalysisCompleted}, events)
+
+	for _, values := range [][]string{nil, {"ping"}, {"diary.read"}, {"diary.created", "diary.created"}} {
+		_, err := ParseEventTypes(values)
+		assert.ErrorIs(t, err, ErrInvalidEventType, "%v", values)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, 6*time.Hour, Backoff(20))
+}
+
+func TestDelivery_RecordAttempt(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	d := &Delivery{Status: DeliveryPending}
+
+	d.RecordAttempt(now, 500, nil)
+	assert.Equal(t, DeliveryPending, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, now.Add(30*time.Second), d.NextAttemptAt)
+
+	d.RecordAttempt(now, 0, errors.New("connection refused"))
+	assert.Equal(t, "connection refused", d.LastError)
+	assert.Equal(t, now.Add(time.Minute), d.NextAttemptAt)
+
+	d.RecordAttempt(now, 204, nil)
+	assert.Equal(t, DeliverySucceeded, d.Status)
+	assert.Equal(t, 204, d.LastStatusCode)
+	assert.Empty(t, d.LastError)
+	require.NotNil(t, d.DeliveredAt)
+
+	failing := &Delivery{Status: DeliveryPending, Attempts: MaxAttempts - 1}
+	failing.RecordAttempt(now, 503, nil)
+	assert.Equal(t, DeliveryFailed, failing.Status)
+}
@@ -0,0 +1,74 @@
+package authtoken
+
+import (
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/jwtkeys"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultMergeTicketTTL はアカウント統合の確認トークンの有効期限
+const DefaultMergeTicketTTL = 10 * time.Minute
+
+// 他の用途の署名付きトークンと取り違えないための種別
+const mergeTicketAudience = "account-merge"
+
+type mergeTicketClaims struct {
+	jwt.RegisteredClaims
+	TargetUserID string `json:"target_user_id"`
+}
+
+// SignedMergeTicketStore はMergeTicketを署名付きトークンとしてクライアントに預ける
+type SignedMergeTicketStore struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewSignedMergeTicketStore(secret []byte, ttl time.Duration) auth.MergeTicketStore {
+	return &SignedMergeTicketStore{secret: secret, ttl: ttl, now: time.Now}
+}
+
+func (s *SignedMergeTicketStore) Issue(ticket auth.MergeTicket) (string, error) {
+	now := s.now()
+	claims := mergeTicketClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   ticket.GuestUserID,
+			Audience:  jwt.ClaimStrings{mergeTicketAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+		TargetUserID: ticket.TargetUserID,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+func (s *SignedMergeTicketStore) Verify(token string) (*auth.MergeTicket, error) {
+	claims := &mergeTicketClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(mergeTicketAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if _, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}); err != nil {
+		return nil, auth.ErrInvalidMergeTicket
+	}
+	if claims.Subject == "" || claims.TargetUserID == "" {
+		return nil, auth.ErrInvalidMergeTicket
+	}
+	return &auth.MergeTicket{
+		GuestUserID:  claims.Subject,
+		TargetUserID: claims.TargetUserID,
+		ExpiresAt:    claims.ExpiresAt.Time,
+	}, nil
+}
+
+// SecretFromEnv は認証用の一時トークンの署名鍵を返す（本番環境では未設定・既定値・弱い値の場合にエラー）。
+// JWT_SECRETを廃止できるよう、アクセストークンの鍵とは共有しない
+func SecretFromEnv() ([]byte, error) {
+	return jwtkeys.SecretFromEnv("AUTH_TICKET_SECRET")
+}
@@ -0,0 +1,55 @@
+package authtoken
+
+import (
+	"time"
+	"tofunote-backend/domain/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultMFAChallengeTTL は二段階認証の確認トークンの有効期限
+const DefaultMFAChallengeTTL = 5 * time.Minute
+
+// アクセストークン・アカウント統合の確認トークンと取り違えないための種別
+const mfaChallengeAudience = "mfa-pending"
+
+// SignedMFAChallengeStore はMFAChallengeを署名付きトークンとしてクライアントに預ける
+type SignedMFAChallengeStore struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewSignedMFAChallengeStore(secret []byte, ttl time.Duration) auth.MFAChallengeStore {
+	return &SignedMFAChallengeStore{secret: secret, ttl: ttl, now: time.Now}
+}
+
+func (s *SignedMFAChallengeStore) Issue(challenge auth.MFAChallenge) (string, error) {
+	now := s.now()
+	claims := jwt.RegisteredClaims{
+		Subject:   challenge.UserID,
+		Audience:  jwt.ClaimStrings{mfaChallengeAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+func (s *SignedMFAChallengeStore) Verify(token string) (*auth.MFAChallenge, error) {
+	claims := &jwt.RegisteredClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(mfaChallengeAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if _, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}); err != nil {
+		return nil, auth.ErrInvalidMFAChallenge
+	}
+	if claims.Subject == "" {
+		return nil, auth.ErrInvalidMFAChallenge
+	}
+	return &auth.MFAChallenge{UserID: claims.Subject, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
@@ -0,0 +1,63 @@
+package authtoken
+
+import (
+	"time"
+	"tofunote-backend/domain/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultProofOfWorkTTL は計算課題の有効期限
+const DefaultProofOfWorkTTL = 5 * time.Minute
+
+// アクセストークン・他の確認トークンと取り違えないための種別
+const proofOfWorkAudience = "guest-pow"
+
+type proofOfWorkClaims struct {
+	Difficulty int `json:"difficulty"`
+	jwt.RegisteredClaims
+}
+
+// SignedProofOfWorkChallengeStore は計算課題を署名付きトークンとして発行する（サーバー側に課題を保存しない）
+type SignedProofOfWorkChallengeStore struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewSignedProofOfWorkChallengeStore(secret []byte, ttl time.Duration) auth.ProofOfWorkChallengeStore {
+	return &SignedProofOfWorkChallengeStore{secret: secret, ttl: ttl, now: time.Now}
+}
+
+func (s *SignedProofOfWorkChallengeStore) Issue(challenge auth.ProofOfWorkChallenge) (string, error) {
+	now := s.now()
+	claims := proofOfWorkClaims{
+		Difficulty: challenge.Difficulty,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        challenge.ID,
+			Audience:  jwt.ClaimStrings{proofOfWorkAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+func (s *SignedProofOfWorkChallengeStore) Verify(token string) (*auth.ProofOfWorkChallenge, error) {
+	claims := &proofOfWorkClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(proofOfWorkAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if _, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}); err != nil {
+		return nil, auth.ErrInvalidProofOfWork
+	}
+	if claims.ID == "" {
+		return nil, auth.ErrInvalidProofOfWork
+	}
+	return &auth.ProofOfWorkChallenge{ID: claims.ID, Difficulty: claims.Difficulty, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
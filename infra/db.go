@@ -37,7 +37,7 @@ func SetupDB() *gorm.DB {
 	}
 
 	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Tokyo connect_timeout=10 statement_timeout=30000 idle_in_transaction_session_timeout=30000",
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s connect_timeout=10 statement_timeout=30000 idle_in_transaction_session_timeout=30000",
 		dbHost,
 		dbUser,
 		dbPassword,
@@ -80,7 +80,7 @@ func SetupDB() *gorm.DB {
 
 	log.Println("[DEBUG] SetupDB: AutoMigrate開始")
 	// AutoMigrateでテーブルを作成
-	err = database.AutoMigrate(&db.DiaryModel{}, &db.UserModel{})
+	err = database.AutoMigrate(&db.DiaryModel{}, &db.UserModel{}, &db.EmotionLabelModel{}, &db.DailyFactorModel{}, &db.FactorDefinitionModel{}, &db.MedicationModel{}, &db.DoseLogModel{}, &db.ThoughtRecordModel{}, &db.DailyHighlightsModel{}, &db.OneTimeTokenModel{}, &db.RefreshTokenModel{}, &db.SessionModel{}, &db.PasskeyModel{}, &db.PasskeyChallengeModel{}, &db.TOTPFactorModel{}, &db.RecoveryCodeModel{}, &db.UserRoleModel{}, &db.AdminActionLogModel{}, &db.PersonalAccessTokenModel{}, &db.GuestCreationModel{}, &db.UserPreferencesModel{}, &db.AuditEventModel{}, &db.PushSubscriptionModel{}, &db.WebhookEndpointModel{}, &db.WebhookDeliveryModel{})
 	if err != nil {
 		log.Printf("[ERROR] SetupDB: マイグレーション失敗: %v", err)
 		panic(fmt.Sprintf("Failed to migrate database: %v", err))
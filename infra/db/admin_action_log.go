@@ -0,0 +1,43 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/admin"
+)
+
+type AdminActionLogModel struct {
+	ID           string `gorm:"primaryKey;type:uuid"`
+	AdminUserID  string `gorm:"not null;type:uuid;index"`
+	Action       string `gorm:"not null;type:varchar(64)"`
+	TargetUserID string `gorm:"type:varchar(36);index"`
+	Reason       string `gorm:"type:text"`
+	CreatedAt    time.Time
+}
+
+func (AdminActionLogModel) TableName() string {
+	return "admin_action_logs"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *AdminActionLogModel) ToDomain() *admin.ActionLog {
+	return &admin.ActionLog{
+		ID:           m.ID,
+		AdminUserID:  m.AdminUserID,
+		Action:       admin.Action(m.Action),
+		TargetUserID: m.TargetUserID,
+		Reason:       m.Reason,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+// AdminActionLogFromDomain converts the domain model to the persistence model.
+func AdminActionLogFromDomain(l *admin.ActionLog) *AdminActionLogModel {
+	return &AdminActionLogModel{
+		ID:           l.ID,
+		AdminUserID:  l.AdminUserID,
+		Action:       string(l.Action),
+		TargetUserID: l.TargetUserID,
+		Reason:       l.Reason,
+		CreatedAt:    l.CreatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/audit"
+)
+
+type AuditEventModel struct {
+	ID           string    `gorm:"primaryKey;type:uuid"`
+	ActorUserID  string    `gorm:"type:varchar(36);index"`
+	Action       string    `gorm:"not null;type:varchar(64);index"`
+	TargetUserID string    `gorm:"type:varchar(36);index"`
+	Detail       string    `gorm:"type:varchar(255)"`
+	IPAddress    string    `gorm:"type:varchar(64)"`
+	UserAgent    string    `gorm:"type:varchar(512)"`
+	RequestID    string    `gorm:"type:varchar(128)"`
+	CreatedAt    time.Time `gorm:"index"`
+}
+
+func (AuditEventModel) TableName() string {
+	return "audit_events"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *AuditEventModel) ToDomain() *audit.Event {
+	return &audit.Event{
+		ID:           m.ID,
+		ActorUserID:  m.ActorUserID,
+		Action:       audit.Action(m.Action),
+		TargetUserID: m.TargetUserID,
+		Detail:       m.Detail,
+		IPAddress:    m.IPAddress,
+		UserAgent:    m.UserAgent,
+		RequestID:    m.RequestID,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+// AuditEventFromDomain converts the domain model to the persistence model.
+func AuditEventFromDomain(e *audit.Event) *AuditEventModel {
+	return &AuditEventModel{
+		ID:           e.ID,
+		ActorUserID:  e.ActorUserID,
+		Action:       string(e.Action),
+		TargetUserID: e.TargetUserID,
+		Detail:       e.Detail,
+		IPAddress:    e.IPAddress,
+		UserAgent:    e.UserAgent,
+		RequestID:    e.RequestID,
+		CreatedAt:    e.CreatedAt,
+	}
+}
@@ -1,6 +1,9 @@
 package db
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"tofunote-backend/domain/diary"
 
 	"gorm.io/gorm"
@@ -8,36 +11,80 @@ import (
 
 type DiaryModel struct {
 	gorm.Model
-	ID     string `gorm:"primaryKey;type:uuid"`
-	UserID string `gorm:"not null;type:uuid;uniqueIndex:idx_user_date,priority:1" json:"user_id"`
-	Date   string `gorm:"not null;type:date;uniqueIndex:idx_user_date,priority:2" json:"date"`
-	Mental int    `gorm:"not null;type:integer" json:"mental"`
-	Diary  string `gorm:"not null;type:text" json:"diary"`
+	ID       string      `gorm:"primaryKey;type:uuid"`
+	UserID   string      `gorm:"not null;type:uuid;uniqueIndex:idx_user_date,priority:1" json:"user_id"`
+	Date     string      `gorm:"not null;type:date;uniqueIndex:idx_user_date,priority:2" json:"date"`
+	Mental   int         `gorm:"not null;type:integer" json:"mental"`
+	Diary    string      `gorm:"not null;type:text" json:"diary"`
+	Emotions EmotionList `gorm:"type:jsonb" json:"emotions"`
 }
 
 func (DiaryModel) TableName() string {
 	return "diaries"
 }
 
+// EmotionList は感情ラベルをJSONとして1カラムに保存するための型
+type EmotionList []diary.Emotion
+
+// Value implements driver.Valuer.
+func (l EmotionList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal([]diary.Emotion(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (l *EmotionList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("emotions カラムの型が不正です")
+	}
+	var emotions []diary.Emotion
+	if err := json.Unmarshal(data, &emotions); err != nil {
+		return err
+	}
+	*l = emotions
+	return nil
+}
+
 // ToDomain converts the persistence model to the domain model.
 func (d *DiaryModel) ToDomain() *diary.Diary {
 	mental, _ := diary.NewMental(d.Mental)
+	var emotions []diary.Emotion
+	if len(d.Emotions) > 0 {
+		emotions = []diary.Emotion(d.Emotions)
+	}
 	return &diary.Diary{
-		ID:     d.ID,
-		UserID: d.UserID,
-		Date:   d.Date,
-		Mental: mental,
-		Diary:  d.Diary,
+		ID:       d.ID,
+		UserID:   d.UserID,
+		Date:     diary.Date(NormalizeDate(d.Date)),
+		Mental:   mental,
+		Diary:    d.Diary,
+		Emotions: emotions,
 	}
 }
 
 // FromDomain converts the domain model to the persistence model.
 func FromDomain(d *diary.Diary) *DiaryModel {
 	return &DiaryModel{
-		ID:     d.ID,
-		UserID: d.UserID,
-		Date:   d.Date,
-		Mental: int(d.Mental),
-		Diary:  d.Diary,
+		ID:       d.ID,
+		UserID:   d.UserID,
+		Date:     d.Date.String(),
+		Mental:   int(d.Mental),
+		Diary:    d.Diary,
+		Emotions: EmotionList(d.Emotions),
 	}
 }
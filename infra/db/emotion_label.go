@@ -0,0 +1,42 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/diary"
+)
+
+type EmotionLabelModel struct {
+	ID        string    `gorm:"primaryKey;type:uuid"`
+	UserID    string    `gorm:"not null;type:uuid;uniqueIndex:idx_emotion_label_user_key,priority:1"`
+	Key       string    `gorm:"not null;type:varchar(32);uniqueIndex:idx_emotion_label_user_key,priority:2"`
+	Category  string    `gorm:"not null;type:varchar(32)"`
+	NameJa    string    `gorm:"type:varchar(64)"`
+	NameEn    string    `gorm:"type:varchar(64)"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (EmotionLabelModel) TableName() string {
+	return "emotion_labels"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *EmotionLabelModel) ToDomain() diary.EmotionLabel {
+	return diary.EmotionLabel{
+		Key:      m.Key,
+		UserID:   m.UserID,
+		Category: m.Category,
+		NameJa:   m.NameJa,
+		NameEn:   m.NameEn,
+	}
+}
+
+// EmotionLabelFromDomain converts the domain model to the persistence model.
+func EmotionLabelFromDomain(l *diary.EmotionLabel) *EmotionLabelModel {
+	return &EmotionLabelModel{
+		UserID:   l.UserID,
+		Key:      l.Key,
+		Category: l.Category,
+		NameJa:   l.NameJa,
+		NameEn:   l.NameEn,
+	}
+}
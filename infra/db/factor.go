@@ -0,0 +1,149 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+	"tofunote-backend/domain/factor"
+)
+
+type DailyFactorModel struct {
+	ID              string          `gorm:"primaryKey;type:uuid"`
+	UserID          string          `gorm:"not null;type:uuid;uniqueIndex:idx_factor_user_date,priority:1"`
+	Date            string          `gorm:"not null;type:date;uniqueIndex:idx_factor_user_date,priority:2"`
+	SleepHours      *float64        `gorm:"type:numeric(4,2)"`
+	ExerciseMinutes *int            `gorm:"type:integer"`
+	AlcoholUnits    *float64        `gorm:"type:numeric(5,2)"`
+	MedicationTaken *bool           `gorm:"type:boolean"`
+	Custom          FactorValueList `gorm:"type:jsonb"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (DailyFactorModel) TableName() string {
+	return "daily_factors"
+}
+
+// FactorValueList はユーザー定義因子の値をJSONとして1カラムに保存するための型
+type FactorValueList []factor.Value
+
+// Value implements driver.Valuer.
+func (l FactorValueList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal([]factor.Value(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (l *FactorValueList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("custom カラムの型が不正です")
+	}
+	var values []factor.Value
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *DailyFactorModel) ToDomain() *factor.DailyFactors {
+	var custom []factor.Value
+	if len(m.Custom) > 0 {
+		custom = []factor.Value(m.Custom)
+	}
+	return &factor.DailyFactors{
+		ID:              m.ID,
+		UserID:          m.UserID,
+		Date:            NormalizeDate(m.Date),
+		SleepHours:      m.SleepHours,
+		ExerciseMinutes: m.ExerciseMinutes,
+		AlcoholUnits:    m.AlcoholUnits,
+		MedicationTaken: m.MedicationTaken,
+		Custom:          custom,
+	}
+}
+
+// DailyFactorFromDomain converts the domain model to the persistence model.
+func DailyFactorFromDomain(f *factor.DailyFactors) *DailyFactorModel {
+	return &DailyFactorModel{
+		ID:              f.ID,
+		UserID:          f.UserID,
+		Date:            f.Date,
+		SleepHours:      f.SleepHours,
+		ExerciseMinutes: f.ExerciseMinutes,
+		AlcoholUnits:    f.AlcoholUnits,
+		MedicationTaken: f.MedicationTaken,
+		Custom:          FactorValueList(f.Custom),
+	}
+}
+
+type FactorDefinitionModel struct {
+	ID        string    `gorm:"primaryKey;type:uuid"`
+	UserID    string    `gorm:"not null;type:uuid;uniqueIndex:idx_factor_definition_user_key,priority:1"`
+	Key       string    `gorm:"not null;type:varchar(32);uniqueIndex:idx_factor_definition_user_key,priority:2"`
+	Name      string    `gorm:"not null;type:varchar(64)"`
+	Type      string    `gorm:"not null;type:varchar(16)"`
+	Unit      string    `gorm:"type:varchar(16)"`
+	Min       float64   `gorm:"type:double precision"`
+	Max       float64   `gorm:"type:double precision"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (FactorDefinitionModel) TableName() string {
+	return "factor_definitions"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *FactorDefinitionModel) ToDomain() factor.Definition {
+	def := factor.Definition{
+		Key:    m.Key,
+		UserID: m.UserID,
+		Name:   m.Name,
+		Type:   factor.Type(m.Type),
+		Unit:   m.Unit,
+		Min:    m.Min,
+		Max:    m.Max,
+	}
+	if def.Type == factor.TypeBoolean {
+		def.Condition = factor.Condition{Op: "==", Value: 1}
+	}
+	return def
+}
+
+// FactorDefinitionFromDomain converts the domain model to the persistence model.
+func FactorDefinitionFromDomain(d *factor.Definition) *FactorDefinitionModel {
+	return &FactorDefinitionModel{
+		UserID: d.UserID,
+		Key:    d.Key,
+		Name:   d.Name,
+		Type:   string(d.Type),
+		Unit:   d.Unit,
+		Min:    d.Min,
+		Max:    d.Max,
+	}
+}
+
+// NormalizeDate はDBから読み出したdate型の値（ドライバによってはRFC3339形式）をYYYY-MM-DD形式にそろえる
+func NormalizeDate(date string) string {
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return date
+}
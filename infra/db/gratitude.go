@@ -0,0 +1,85 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+	"tofunote-backend/domain/gratitude"
+)
+
+type DailyHighlightsModel struct {
+	ID         string     `gorm:"primaryKey;type:uuid"`
+	UserID     string     `gorm:"not null;type:uuid;uniqueIndex:idx_highlights_user_date,priority:1"`
+	Date       string     `gorm:"not null;type:date;uniqueIndex:idx_highlights_user_date,priority:2"`
+	GoodThings StringList `gorm:"type:jsonb"`
+	Highlight  string     `gorm:"type:varchar(200)"`
+	Lowlight   string     `gorm:"type:varchar(200)"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (DailyHighlightsModel) TableName() string {
+	return "daily_highlights"
+}
+
+// StringList は文字列の一覧をJSONとして1カラムに保存するための型
+type StringList []string
+
+// Value implements driver.Valuer.
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+	b, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("文字列リストのカラムの型が不正です")
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *DailyHighlightsModel) ToDomain() *gratitude.DailyHighlights {
+	return &gratitude.DailyHighlights{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Date:       NormalizeDate(m.Date),
+		GoodThings: []string(m.GoodThings),
+		Highlight:  m.Highlight,
+		Lowlight:   m.Lowlight,
+	}
+}
+
+// DailyHighlightsFromDomain converts the domain model to the persistence model.
+func DailyHighlightsFromDomain(h *gratitude.DailyHighlights) *DailyHighlightsModel {
+	return &DailyHighlightsModel{
+		ID:         h.ID,
+		UserID:     h.UserID,
+		Date:       h.Date,
+		GoodThings: StringList(h.GoodThings),
+		Highlight:  h.Highlight,
+		Lowlight:   h.Lowlight,
+	}
+}
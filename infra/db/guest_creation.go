@@ -0,0 +1,37 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/user"
+)
+
+type GuestCreationModel struct {
+	ID     string `gorm:"primaryKey;type:uuid"`
+	IPHash string `gorm:"not null;type:varchar(64);index:idx_guest_creations_ip_created,priority:1"`
+	// ChallengeID は計算課題を求めていない場合はNULL（同じ解答の使い回しを一意制約で防ぐ）
+	ChallengeID *string   `gorm:"type:varchar(64);uniqueIndex"`
+	CreatedAt   time.Time `gorm:"index;index:idx_guest_creations_ip_created,priority:2"`
+}
+
+func (GuestCreationModel) TableName() string {
+	return "guest_creations"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *GuestCreationModel) ToDomain() *user.GuestCreation {
+	creation := &user.GuestCreation{ID: m.ID, IPHash: m.IPHash, CreatedAt: m.CreatedAt}
+	if m.ChallengeID != nil {
+		creation.ChallengeID = *m.ChallengeID
+	}
+	return creation
+}
+
+// GuestCreationFromDomain converts the domain model to the persistence model.
+func GuestCreationFromDomain(c *user.GuestCreation) *GuestCreationModel {
+	model := &GuestCreationModel{ID: c.ID, IPHash: c.IPHash, CreatedAt: c.CreatedAt}
+	if c.ChallengeID != "" {
+		challengeID := c.ChallengeID
+		model.ChallengeID = &challengeID
+	}
+	return model
+}
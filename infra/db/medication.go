@@ -0,0 +1,137 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+	"tofunote-backend/domain/medication"
+)
+
+type MedicationModel struct {
+	ID               string       `gorm:"primaryKey;type:uuid"`
+	UserID           string       `gorm:"not null;type:uuid;index"`
+	Name             string       `gorm:"not null;type:varchar(100)"`
+	Dose             string       `gorm:"type:varchar(100)"`
+	Schedule         ScheduleJSON `gorm:"not null;type:jsonb"`
+	StartDate        string       `gorm:"not null;type:date"`
+	EndDate          *string      `gorm:"type:date"`
+	RemindersEnabled bool         `gorm:"not null;default:false"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+func (MedicationModel) TableName() string {
+	return "medications"
+}
+
+// ScheduleJSON は服用スケジュールをJSONとして1カラムに保存するための型
+type ScheduleJSON medication.Schedule
+
+// Value implements driver.Valuer.
+func (s ScheduleJSON) Value() (driver.Value, error) {
+	b, err := json.Marshal(medication.Schedule(s))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *ScheduleJSON) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("schedule カラムの型が不正です")
+	}
+	var schedule medication.Schedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return err
+	}
+	*s = ScheduleJSON(schedule)
+	return nil
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *MedicationModel) ToDomain() *medication.Medication {
+	med := &medication.Medication{
+		ID:               m.ID,
+		UserID:           m.UserID,
+		Name:             m.Name,
+		Dose:             m.Dose,
+		Schedule:         medication.Schedule(m.Schedule),
+		StartDate:        NormalizeDate(m.StartDate),
+		RemindersEnabled: m.RemindersEnabled,
+	}
+	if m.EndDate != nil {
+		med.EndDate = NormalizeDate(*m.EndDate)
+	}
+	return med
+}
+
+// MedicationFromDomain converts the domain model to the persistence model.
+func MedicationFromDomain(m *medication.Medication) *MedicationModel {
+	model := &MedicationModel{
+		ID:               m.ID,
+		UserID:           m.UserID,
+		Name:             m.Name,
+		Dose:             m.Dose,
+		Schedule:         ScheduleJSON(m.Schedule),
+		StartDate:        m.StartDate,
+		RemindersEnabled: m.RemindersEnabled,
+	}
+	if m.EndDate != "" {
+		endDate := m.EndDate
+		model.EndDate = &endDate
+	}
+	return model
+}
+
+type DoseLogModel struct {
+	ID            string `gorm:"primaryKey;type:uuid"`
+	UserID        string `gorm:"not null;type:uuid;index:idx_dose_log_user_date,priority:1"`
+	MedicationID  string `gorm:"not null;type:uuid;uniqueIndex:idx_dose_log_medication_slot,priority:1"`
+	Date          string `gorm:"not null;type:date;index:idx_dose_log_user_date,priority:2;uniqueIndex:idx_dose_log_medication_slot,priority:2"`
+	ScheduledTime string `gorm:"not null;type:varchar(5);uniqueIndex:idx_dose_log_medication_slot,priority:3"`
+	Status        string `gorm:"not null;type:varchar(16)"`
+	TakenAt       *time.Time
+	Note          string `gorm:"type:text"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (DoseLogModel) TableName() string {
+	return "medication_dose_logs"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *DoseLogModel) ToDomain() *medication.DoseLog {
+	return &medication.DoseLog{
+		ID:            m.ID,
+		UserID:        m.UserID,
+		MedicationID:  m.MedicationID,
+		Date:          NormalizeDate(m.Date),
+		ScheduledTime: m.ScheduledTime,
+		Status:        medication.DoseStatus(m.Status),
+		TakenAt:       m.TakenAt,
+		Note:          m.Note,
+	}
+}
+
+// DoseLogFromDomain converts the domain model to the persistence model.
+func DoseLogFromDomain(l *medication.DoseLog) *DoseLogModel {
+	return &DoseLogModel{
+		ID:            l.ID,
+		UserID:        l.UserID,
+		MedicationID:  l.MedicationID,
+		Date:          l.Date,
+		ScheduledTime: l.ScheduledTime,
+		Status:        string(l.Status),
+		TakenAt:       l.TakenAt,
+		Note:          l.Note,
+	}
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/auth"
+)
+
+type OneTimeTokenModel struct {
+	ID        string    `gorm:"primaryKey;type:uuid"`
+	UserID    string    `gorm:"not null;type:uuid;index:idx_one_time_tokens_user_purpose,priority:1"`
+	Purpose   string    `gorm:"not null;type:varchar(32);index:idx_one_time_tokens_user_purpose,priority:2"`
+	TokenHash string    `gorm:"not null;type:varchar(64);uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (OneTimeTokenModel) TableName() string {
+	return "one_time_tokens"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *OneTimeTokenModel) ToDomain() *auth.OneTimeToken {
+	return &auth.OneTimeToken{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Purpose:   auth.TokenPurpose(m.Purpose),
+		TokenHash: m.TokenHash,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    m.UsedAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// OneTimeTokenFromDomain converts the domain model to the persistence model.
+func OneTimeTokenFromDomain(t *auth.OneTimeToken) *OneTimeTokenModel {
+	return &OneTimeTokenModel{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Purpose:   string(t.Purpose),
+		TokenHash: t.TokenHash,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
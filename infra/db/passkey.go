@@ -0,0 +1,110 @@
+package db
+
+import (
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
+)
+
+type PasskeyModel struct {
+	ID           string `gorm:"primaryKey;type:uuid"`
+	UserID       string `gorm:"not null;type:uuid;index"`
+	Name         string `gorm:"not null;type:varchar(100)"`
+	CredentialID []byte `gorm:"not null;uniqueIndex"`
+	PublicKey    []byte `gorm:"not null"`
+	Algorithm    int    `gorm:"not null"`
+	SignCount    int64  `gorm:"not null;default:0"`
+	// Transports は認証器の接続方式（usb・internal等）をカンマ区切りで保存する
+	Transports string `gorm:"type:varchar(100)"`
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+func (PasskeyModel) TableName() string {
+	return "passkeys"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *PasskeyModel) ToDomain() *auth.Passkey {
+	var transports []string
+	if m.Transports != "" {
+		transports = strings.Split(m.Transports, ",")
+	}
+	return &auth.Passkey{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		Name:         m.Name,
+		CredentialID: m.CredentialID,
+		PublicKey:    m.PublicKey,
+		Algorithm:    m.Algorithm,
+		SignCount:    uint32(m.SignCount),
+		Transports:   transports,
+		CreatedAt:    m.CreatedAt,
+		LastUsedAt:   m.LastUsedAt,
+	}
+}
+
+// PasskeyFromDomain converts the domain model to the persistence model.
+func PasskeyFromDomain(p *auth.Passkey) *PasskeyModel {
+	return &PasskeyModel{
+		ID:           p.ID,
+		UserID:       p.UserID,
+		Name:         p.Name,
+		CredentialID: p.CredentialID,
+		PublicKey:    p.PublicKey,
+		Algorithm:    p.Algorithm,
+		SignCount:    int64(p.SignCount),
+		Transports:   strings.Join(p.Transports, ","),
+		CreatedAt:    p.CreatedAt,
+		LastUsedAt:   p.LastUsedAt,
+	}
+}
+
+type PasskeyChallengeModel struct {
+	ID       string `gorm:"primaryKey;type:uuid"`
+	Ceremony string `gorm:"not null;type:varchar(16)"`
+	// UserID はログインのチャレンジでは空
+	UserID    *string   `gorm:"type:uuid;index"`
+	Challenge []byte    `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (PasskeyChallengeModel) TableName() string {
+	return "passkey_challenges"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *PasskeyChallengeModel) ToDomain() *auth.PasskeyChallenge {
+	var userID string
+	if m.UserID != nil {
+		userID = *m.UserID
+	}
+	return &auth.PasskeyChallenge{
+		ID:        m.ID,
+		Ceremony:  auth.PasskeyCeremony(m.Ceremony),
+		UserID:    userID,
+		Challenge: m.Challenge,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    m.UsedAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// PasskeyChallengeFromDomain converts the domain model to the persistence model.
+func PasskeyChallengeFromDomain(c *auth.PasskeyChallenge) *PasskeyChallengeModel {
+	var userID *string
+	if c.UserID != "" {
+		userID = &c.UserID
+	}
+	return &PasskeyChallengeModel{
+		ID:        c.ID,
+		Ceremony:  string(c.Ceremony),
+		UserID:    userID,
+		Challenge: c.Challenge,
+		ExpiresAt: c.ExpiresAt,
+		UsedAt:    c.UsedAt,
+		CreatedAt: c.CreatedAt,
+	}
+}
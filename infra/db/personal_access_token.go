@@ -0,0 +1,61 @@
+package db
+
+import (
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
+)
+
+type PersonalAccessTokenModel struct {
+	ID        string `gorm:"primaryKey;type:uuid"`
+	UserID    string `gorm:"not null;type:uuid;index"`
+	Name      string `gorm:"not null;type:varchar(100)"`
+	TokenHash string `gorm:"not null;type:varchar(64);uniqueIndex"`
+	// Scopes はスコープをカンマ区切りで保存する
+	Scopes     string    `gorm:"not null;type:varchar(255)"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+func (PersonalAccessTokenModel) TableName() string {
+	return "personal_access_tokens"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *PersonalAccessTokenModel) ToDomain() *auth.PersonalAccessToken {
+	var scopes []auth.Scope
+	if m.Scopes != "" {
+		for _, s := range strings.Split(m.Scopes, ",") {
+			scopes = append(scopes, auth.Scope(s))
+		}
+	}
+	return &auth.PersonalAccessToken{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Name:       m.Name,
+		TokenHash:  m.TokenHash,
+		Scopes:     scopes,
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// PersonalAccessTokenFromDomain converts the domain model to the persistence model.
+func PersonalAccessTokenFromDomain(t *auth.PersonalAccessToken) *PersonalAccessTokenModel {
+	scopes := make([]string, len(t.Scopes))
+	for i, s := range t.Scopes {
+		scopes[i] = string(s)
+	}
+	return &PersonalAccessTokenModel{
+		ID:         t.ID,
+		UserID:     t.UserID,
+		Name:       t.Name,
+		TokenHash:  t.TokenHash,
+		Scopes:     strings.Join(scopes, ","),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
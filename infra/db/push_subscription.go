@@ -0,0 +1,47 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/notification"
+)
+
+type PushSubscriptionModel struct {
+	ID     string `gorm:"primaryKey;type:uuid"`
+	UserID string `gorm:"not null;type:uuid;index"`
+	// Endpoint はブラウザごとに一意のため、同じブラウザの再登録は上書きする
+	Endpoint  string `gorm:"not null;type:varchar(2048);uniqueIndex"`
+	P256dh    string `gorm:"not null;type:varchar(128)"`
+	Auth      string `gorm:"not null;type:varchar(64)"`
+	UserAgent string `gorm:"not null;type:varchar(255);default:''"`
+	CreatedAt time.Time
+}
+
+func (PushSubscriptionModel) TableName() string {
+	return "push_subscriptions"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *PushSubscriptionModel) ToDomain() notification.PushSubscription {
+	return notification.PushSubscription{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Endpoint:  m.Endpoint,
+		P256dh:    m.P256dh,
+		Auth:      m.Auth,
+		UserAgent: m.UserAgent,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// PushSubscriptionFromDomain converts the domain model to the persistence model.
+func PushSubscriptionFromDomain(s *notification.PushSubscription) *PushSubscriptionModel {
+	return &PushSubscriptionModel{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		Endpoint:  s.Endpoint,
+		P256dh:    s.P256dh,
+		Auth:      s.Auth,
+		UserAgent: s.UserAgent,
+		CreatedAt: s.CreatedAt,
+	}
+}
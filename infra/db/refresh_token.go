@@ -0,0 +1,52 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/auth"
+)
+
+type RefreshTokenModel struct {
+	ID          string    `gorm:"primaryKey;type:uuid"`
+	UserID      string    `gorm:"not null;type:uuid;index"`
+	FamilyID    string    `gorm:"not null;type:uuid;index"`
+	TokenHash   string    `gorm:"not null;type:varchar(64);uniqueIndex"`
+	DeviceLabel string    `gorm:"type:varchar(100)"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	RotatedAt   *time.Time
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+func (RefreshTokenModel) TableName() string {
+	return "refresh_tokens"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *RefreshTokenModel) ToDomain() *auth.RefreshToken {
+	return &auth.RefreshToken{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		FamilyID:    m.FamilyID,
+		TokenHash:   m.TokenHash,
+		DeviceLabel: m.DeviceLabel,
+		ExpiresAt:   m.ExpiresAt,
+		RotatedAt:   m.RotatedAt,
+		RevokedAt:   m.RevokedAt,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// RefreshTokenFromDomain converts the domain model to the persistence model.
+func RefreshTokenFromDomain(t *auth.RefreshToken) *RefreshTokenModel {
+	return &RefreshTokenModel{
+		ID:          t.ID,
+		UserID:      t.UserID,
+		FamilyID:    t.FamilyID,
+		TokenHash:   t.TokenHash,
+		DeviceLabel: t.DeviceLabel,
+		ExpiresAt:   t.ExpiresAt,
+		RotatedAt:   t.RotatedAt,
+		RevokedAt:   t.RevokedAt,
+		CreatedAt:   t.CreatedAt,
+	}
+}
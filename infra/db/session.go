@@ -0,0 +1,52 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/auth"
+)
+
+type SessionModel struct {
+	ID          string `gorm:"primaryKey;type:uuid"`
+	UserID      string `gorm:"not null;type:uuid;index"`
+	DeviceLabel string `gorm:"type:varchar(100)"`
+	UserAgent   string `gorm:"type:varchar(512)"`
+	IPAddress   string `gorm:"type:varchar(45)"`
+	CreatedAt   time.Time
+	LastUsedAt  time.Time  `gorm:"not null"`
+	ExpiresAt   time.Time  `gorm:"not null"`
+	RevokedAt   *time.Time `gorm:"index"`
+}
+
+func (SessionModel) TableName() string {
+	return "sessions"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *SessionModel) ToDomain() *auth.Session {
+	return &auth.Session{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		DeviceLabel: m.DeviceLabel,
+		UserAgent:   m.UserAgent,
+		IPAddress:   m.IPAddress,
+		CreatedAt:   m.CreatedAt,
+		LastUsedAt:  m.LastUsedAt,
+		ExpiresAt:   m.ExpiresAt,
+		RevokedAt:   m.RevokedAt,
+	}
+}
+
+// SessionFromDomain converts the domain model to the persistence model.
+func SessionFromDomain(s *auth.Session) *SessionModel {
+	return &SessionModel{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		DeviceLabel: s.DeviceLabel,
+		UserAgent:   s.UserAgent,
+		IPAddress:   s.IPAddress,
+		CreatedAt:   s.CreatedAt,
+		LastUsedAt:  s.LastUsedAt,
+		ExpiresAt:   s.ExpiresAt,
+		RevokedAt:   s.RevokedAt,
+	}
+}
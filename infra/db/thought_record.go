@@ -0,0 +1,105 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+	"tofunote-backend/domain/thoughtrecord"
+)
+
+type ThoughtRecordModel struct {
+	ID               string            `gorm:"primaryKey;type:uuid"`
+	UserID           string            `gorm:"not null;type:uuid;index:idx_thought_record_user_date,priority:1"`
+	Date             *string           `gorm:"type:date;index:idx_thought_record_user_date,priority:2"`
+	Situation        string            `gorm:"not null;type:text"`
+	AutomaticThought string            `gorm:"not null;type:text"`
+	Emotions         EmotionRatingList `gorm:"not null;type:jsonb"`
+	EvidenceFor      string            `gorm:"type:text"`
+	EvidenceAgainst  string            `gorm:"type:text"`
+	BalancedThought  string            `gorm:"type:text"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+func (ThoughtRecordModel) TableName() string {
+	return "thought_records"
+}
+
+// EmotionRatingList は思考記録の感情をJSONとして1カラムに保存するための型
+type EmotionRatingList []thoughtrecord.EmotionRating
+
+// Value implements driver.Valuer.
+func (l EmotionRatingList) Value() (driver.Value, error) {
+	if l == nil {
+		l = EmotionRatingList{}
+	}
+	b, err := json.Marshal([]thoughtrecord.EmotionRating(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (l *EmotionRatingList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("emotions カラムの型が不正です")
+	}
+	var ratings []thoughtrecord.EmotionRating
+	if err := json.Unmarshal(data, &ratings); err != nil {
+		return err
+	}
+	*l = ratings
+	return nil
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *ThoughtRecordModel) ToDomain() *thoughtrecord.ThoughtRecord {
+	r := &thoughtrecord.ThoughtRecord{
+		ID:               m.ID,
+		UserID:           m.UserID,
+		Situation:        m.Situation,
+		AutomaticThought: m.AutomaticThought,
+		Emotions:         []thoughtrecord.EmotionRating(m.Emotions),
+		EvidenceFor:      m.EvidenceFor,
+		EvidenceAgainst:  m.EvidenceAgainst,
+		BalancedThought:  m.BalancedThought,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+	if m.Date != nil {
+		r.Date = NormalizeDate(*m.Date)
+	}
+	return r
+}
+
+// ThoughtRecordFromDomain converts the domain model to the persistence model.
+func ThoughtRecordFromDomain(r *thoughtrecord.ThoughtRecord) *ThoughtRecordModel {
+	model := &ThoughtRecordModel{
+		ID:               r.ID,
+		UserID:           r.UserID,
+		Situation:        r.Situation,
+		AutomaticThought: r.AutomaticThought,
+		Emotions:         EmotionRatingList(r.Emotions),
+		EvidenceFor:      r.EvidenceFor,
+		EvidenceAgainst:  r.EvidenceAgainst,
+		BalancedThought:  r.BalancedThought,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+	}
+	if r.Date != "" {
+		date := r.Date
+		model.Date = &date
+	}
+	return model
+}
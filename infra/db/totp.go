@@ -0,0 +1,80 @@
+package db
+
+import (
+	"time"
+	"tofunote-backend/domain/auth"
+)
+
+type TOTPFactorModel struct {
+	UserID         string `gorm:"primaryKey;type:uuid"`
+	Secret         []byte `gorm:"not null"`
+	EnabledAt      *time.Time
+	LastUsedStep   int64 `gorm:"not null;default:0"`
+	FailedAttempts int   `gorm:"not null;default:0"`
+	LockedUntil    *time.Time
+	CreatedAt      time.Time
+}
+
+func (TOTPFactorModel) TableName() string {
+	return "totp_factors"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *TOTPFactorModel) ToDomain() *auth.TOTPFactor {
+	return &auth.TOTPFactor{
+		UserID:         m.UserID,
+		Secret:         m.Secret,
+		EnabledAt:      m.EnabledAt,
+		LastUsedStep:   m.LastUsedStep,
+		FailedAttempts: m.FailedAttempts,
+		LockedUntil:    m.LockedUntil,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// TOTPFactorFromDomain converts the domain model to the persistence model.
+func TOTPFactorFromDomain(f *auth.TOTPFactor) *TOTPFactorModel {
+	return &TOTPFactorModel{
+		UserID:         f.UserID,
+		Secret:         f.Secret,
+		EnabledAt:      f.EnabledAt,
+		LastUsedStep:   f.LastUsedStep,
+		FailedAttempts: f.FailedAttempts,
+		LockedUntil:    f.LockedUntil,
+		CreatedAt:      f.CreatedAt,
+	}
+}
+
+type RecoveryCodeModel struct {
+	ID        string `gorm:"primaryKey;type:uuid"`
+	UserID    string `gorm:"not null;type:uuid;index"`
+	CodeHash  string `gorm:"not null;type:char(64)"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (RecoveryCodeModel) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *RecoveryCodeModel) ToDomain() *auth.RecoveryCode {
+	return &auth.RecoveryCode{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		CodeHash:  m.CodeHash,
+		UsedAt:    m.UsedAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// RecoveryCodeFromDomain converts the domain model to the persistence model.
+func RecoveryCodeFromDomain(c *auth.RecoveryCode) *RecoveryCodeModel {
+	return &RecoveryCodeModel{
+		ID:        c.ID,
+		UserID:    c.UserID,
+		CodeHash:  c.CodeHash,
+		UsedAt:    c.UsedAt,
+		CreatedAt: c.CreatedAt,
+	}
+}
@@ -5,13 +5,18 @@ import (
 )
 
 type UserModel struct {
-	ID           string    `gorm:"primaryKey;type:uuid"`
-	Nickname     string    `gorm:"type:varchar(255)"`
-	Provider     string    `gorm:"type:varchar(50)"`
-	ProviderID   string    `gorm:"type:varchar(255)"`
-	IsGuest      bool      `gorm:"default:true"`
-	RefreshToken string    `gorm:"type:varchar(255)"`
-	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	ID               string     `gorm:"primaryKey;type:uuid"`
+	Nickname         string     `gorm:"type:varchar(255)"`
+	Provider         string     `gorm:"type:varchar(50);uniqueIndex:idx_users_provider_subject,where:provider <> ''"`
+	ProviderID       string     `gorm:"type:varchar(255);uniqueIndex:idx_users_provider_subject,where:provider <> ''"`
+	IsGuest          bool       `gorm:"default:true"`
+	Email            string     `gorm:"type:varchar(254);uniqueIndex:idx_users_email,where:email <> ''"`
+	EmailVerifiedAt  *time.Time `gorm:"type:timestamp"`
+	PasswordHash     string     `gorm:"type:varchar(255)"`
+	LastSeenAt       *time.Time `gorm:"type:timestamp"`
+	DeletionNoticeAt *time.Time `gorm:"type:timestamp;index"`
+	WithdrawnAt      *time.Time `gorm:"type:timestamp;index"`
+	CreatedAt        time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 func (UserModel) TableName() string {
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+)
+
+type UserPreferencesModel struct {
+	UserID         string `gorm:"primaryKey;type:uuid"`
+	Timezone       string `gorm:"not null;type:varchar(64)"`
+	Locale         string `gorm:"not null;type:varchar(16)"`
+	FirstDayOfWeek int    `gorm:"not null;type:smallint"`
+	MoodScale      int    `gorm:"not null;type:smallint"`
+	// ReminderTimes はHH:MM形式の時刻をカンマ区切りで保存する（設定しない場合は空文字）
+	ReminderTimes string `gorm:"not null;type:varchar(64);default:''"`
+	AnalysisOptIn bool   `gorm:"not null;default:false"`
+	Theme         string `gorm:"not null;type:varchar(16)"`
+	// ReminderWeekdays は曜日（0〜6）をカンマ区切りで保存する（毎日の場合は空文字）
+	ReminderWeekdays string `gorm:"not null;type:varchar(16);default:''"`
+	// NotificationChannels は通知チャネルをカンマ区切りで保存する（列を追加する前の設定はWeb Pushのみとするため既定値を持つ。
+	// GORMは既定値のある列の空文字を既定値に置き換えるため、チャネルなしを保存できるようポインタにする）
+	NotificationChannels *string `gorm:"not null;type:varchar(64);default:'web_push'"`
+	UpdatedAt            time.Time
+}
+
+func (UserPreferencesModel) TableName() string {
+	return "user_preferences"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *UserPreferencesModel) ToDomain() *user.Preferences {
+	times := []string{}
+	if m.ReminderTimes != "" {
+		times = strings.Split(m.ReminderTimes, ",")
+	}
+	weekdays := []time.Weekday{}
+	if m.ReminderWeekdays != "" {
+		for _, v := range strings.Split(m.ReminderWeekdays, ",") {
+			if d, err := strconv.Atoi(v); err == nil {
+				weekdays = append(weekdays, time.Weekday(d))
+			}
+		}
+	}
+	channels := []notification.Channel{}
+	if m.NotificationChannels != nil && *m.NotificationChannels != "" {
+		for _, v := range strings.Split(*m.NotificationChannels, ",") {
+			channels = append(channels, notification.Channel(v))
+		}
+	}
+	return &user.Preferences{
+		Timezone:             m.Timezone,
+		Locale:               m.Locale,
+		FirstDayOfWeek:       time.Weekday(m.FirstDayOfWeek),
+		MoodScale:            m.MoodScale,
+		ReminderTimes:        times,
+		AnalysisOptIn:        m.AnalysisOptIn,
+		Theme:                user.Theme(m.Theme),
+		ReminderWeekdays:     weekdays,
+		NotificationChannels: channels,
+	}
+}
+
+// UserPreferencesFromDomain converts the domain model to the persistence model.
+func UserPreferencesFromDomain(userID string, p *user.Preferences) *UserPreferencesModel {
+	weekdays := make([]string, len(p.ReminderWeekdays))
+	for i, d := range p.ReminderWeekdays {
+		weekdays[i] = strconv.Itoa(int(d))
+	}
+	channels := make([]string, len(p.NotificationChannels))
+	for i, c := range p.NotificationChannels {
+		channels[i] = string(c)
+	}
+	joinedChannels := strings.Join(channels, ",")
+	return &UserPreferencesModel{
+		UserID:               userID,
+		Timezone:             p.Timezone,
+		Locale:               p.Locale,
+		FirstDayOfWeek:       int(p.FirstDayOfWeek),
+		MoodScale:            p.MoodScale,
+		ReminderTimes:        strings.Join(p.ReminderTimes, ","),
+		AnalysisOptIn:        p.AnalysisOptIn,
+		Theme:                string(p.Theme),
+		ReminderWeekdays:     strings.Join(weekdays, ","),
+		NotificationChannels: &joinedChannels,
+	}
+}
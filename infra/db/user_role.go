@@ -0,0 +1,13 @@
+package db
+
+import "time"
+
+type UserRoleModel struct {
+	UserID    string `gorm:"primaryKey;type:uuid"`
+	Role      string `gorm:"primaryKey;type:varchar(32)"`
+	CreatedAt time.Time
+}
+
+func (UserRoleModel) TableName() string {
+	return "user_roles"
+}
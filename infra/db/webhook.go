@@ -0,0 +1,118 @@
+package db
+
+import (
+	"strings"
+	"time"
+	"tofunote-backend/domain/webhook"
+)
+
+type WebhookEndpointModel struct {
+	ID     string `gorm:"primaryKey;type:uuid"`
+	UserID string `gorm:"not null;type:uuid;index"`
+	URL    string `gorm:"not null;type:varchar(2048)"`
+	// Secret は署名に使うため平文で保存する（ハッシュにはできない）
+	Secret string `gorm:"not null;type:varchar(64)"`
+	// Events は購読するイベントをカンマ区切りで保存する
+	Events      string `gorm:"not null;type:varchar(255)"`
+	Description string `gorm:"not null;type:varchar(100);default:''"`
+	CreatedAt   time.Time
+}
+
+func (WebhookEndpointModel) TableName() string {
+	return "webhook_endpoints"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *WebhookEndpointModel) ToDomain() webhook.Endpoint {
+	var events []webhook.EventType
+	if m.Events != "" {
+		for _, e := range strings.Split(m.Events, ",") {
+			events = append(events, webhook.EventType(e))
+		}
+	}
+	return webhook.Endpoint{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		URL:         m.URL,
+		Secret:      m.Secret,
+		Events:      events,
+		Description: m.Description,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// WebhookEndpointFromDomain converts the domain model to the persistence model.
+func WebhookEndpointFromDomain(e *webhook.Endpoint) *WebhookEndpointModel {
+	events := make([]string, len(e.Events))
+	for i, event := range e.Events {
+		events[i] = string(event)
+	}
+	return &WebhookEndpointModel{
+		ID:          e.ID,
+		UserID:      e.UserID,
+		URL:         e.URL,
+		Secret:      e.Secret,
+		Events:      strings.Join(events, ","),
+		Description: e.Description,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+type WebhookDeliveryModel struct {
+	ID         string `gorm:"primaryKey;type:uuid"`
+	EndpointID string `gorm:"not null;type:uuid;index"`
+	UserID     string `gorm:"not null;type:uuid;index"`
+	EventID    string `gorm:"not null;type:uuid"`
+	EventType  string `gorm:"not null;type:varchar(32)"`
+	Payload    []byte `gorm:"not null"`
+	// Status・NextAttemptAt で送信待ちのキューを引く（idx_webhook_deliveries_due）
+	Status         string    `gorm:"not null;type:varchar(16);index:idx_webhook_deliveries_due,priority:1"`
+	NextAttemptAt  time.Time `gorm:"not null;index:idx_webhook_deliveries_due,priority:2"`
+	Attempts       int       `gorm:"not null;default:0"`
+	LastStatusCode int       `gorm:"not null;default:0"`
+	LastError      string    `gorm:"not null;type:varchar(500);default:''"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+func (WebhookDeliveryModel) TableName() string {
+	return "webhook_deliveries"
+}
+
+// ToDomain converts the persistence model to the domain model.
+func (m *WebhookDeliveryModel) ToDomain() *webhook.Delivery {
+	return &webhook.Delivery{
+		ID:             m.ID,
+		EndpointID:     m.EndpointID,
+		UserID:         m.UserID,
+		EventID:        m.EventID,
+		EventType:      webhook.EventType(m.EventType),
+		Payload:        m.Payload,
+		Status:         webhook.DeliveryStatus(m.Status),
+		Attempts:       m.Attempts,
+		NextAttemptAt:  m.NextAttemptAt,
+		LastStatusCode: m.LastStatusCode,
+		LastError:      m.LastError,
+		DeliveredAt:    m.DeliveredAt,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// WebhookDeliveryFromDomain converts the domain model to the persistence model.
+func WebhookDeliveryFromDomain(d *webhook.Delivery) *WebhookDeliveryModel {
+	return &WebhookDeliveryModel{
+		ID:             d.ID,
+		EndpointID:     d.EndpointID,
+		UserID:         d.UserID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Payload:        d.Payload,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
@@ -1,48 +1,152 @@
 package infra
 
 import (
+	"log"
 	"os"
+	"sync"
 	"time"
+	"tofunote-backend/infra/jwtkeys"
 
+	"github.com/cmackenzie1/go-uuid"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte(getJWTSecret())
+// AccessTokenTTL はアクセストークン（JWT）の有効期限
+const AccessTokenTTL = 24 * time.Hour
 
-func getJWTSecret() string {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		// デフォルト値（開発用）を返す
-		return "your-secret-key"
+var (
+	keyManagerMu sync.RWMutex
+	keyManager   *jwtkeys.Manager
+)
+
+// AccessTokenClaims はアクセストークンのクレーム（sub・iat・nbf・exp・iss・aud・jtiの登録済みクレームと独自クレーム）
+type AccessTokenClaims struct {
+	jwt.RegisteredClaims
+	// Guest はゲストユーザーかどうか（認証ミドルウェアがDBを参照せずに判定できるようトークンに含める）
+	Guest bool     `json:"guest"`
+	Roles []string `json:"roles,omitempty"`
+	// SessionID はトークンを発行したセッションのID（セッション導入前のトークンでは空）
+	SessionID string `json:"sid,omitempty"`
+}
+
+// UserID はトークンの主体（subクレーム）のユーザーIDを返す
+func (c *AccessTokenClaims) UserID() string {
+	return c.Subject
+}
+
+// AccessTokenSubject はアクセストークンを発行する対象の情報
+type AccessTokenSubject struct {
+	UserID    string
+	SessionID string
+	Guest     bool
+	Roles     []string
+}
+
+const (
+	// ClockSkew はexp・nbf・iatの検証で許容するサーバー間の時刻のずれ
+	ClockSkew = 30 * time.Second
+
+	defaultTokenIssuer   = "tofunote-backend"
+	defaultTokenAudience = "tofunote-api"
+)
+
+// TokenIssuer はissクレームの値を返す（JWT_ISSUERで変更できる）
+func TokenIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return defaultTokenIssuer
+}
+
+// TokenAudience はaudクレームの値を返す（JWT_AUDIENCEで変更できる）
+func TokenAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
 	}
-	return secret
+	return defaultTokenAudience
+}
+
+// InitJWT は環境変数から署名鍵を読み込む。本番環境で鍵が未設定・既定値・弱い値の場合はエラーを返す
+func InitJWT() error {
+	manager, err := jwtkeys.FromEnv()
+	if err != nil {
+		return err
+	}
+	SetKeyManager(manager)
+	return nil
+}
+
+// SetKeyManager はJWTの署名・検証に使う鍵を設定する
+func SetKeyManager(manager *jwtkeys.Manager) {
+	keyManagerMu.Lock()
+	defer keyManagerMu.Unlock()
+	keyManager = manager
+}
+
+// KeyManager は現在の鍵を返す（InitJWTの前に呼ばれた場合は環境変数から読み込む）
+func KeyManager() *jwtkeys.Manager {
+	keyManagerMu.RLock()
+	manager := keyManager
+	keyManagerMu.RUnlock()
+	if manager != nil {
+		return manager
+	}
+	if err := InitJWT(); err != nil {
+		log.Fatalf("JWTの署名鍵を読み込めません: %v", err)
+	}
+	return KeyManager()
 }
 
 // JWT生成
-func GenerateToken(userID string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+func GenerateToken(subject AccessTokenSubject) (string, error) {
+	jti, err := uuid.NewV7()
+	if err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	now := time.Now()
+	claims := &AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject.UserID,
+			Issuer:    TokenIssuer(),
+			Audience:  jwt.ClaimStrings{TokenAudience()},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			ID:        jti.String(),
+		},
+		Guest:     subject.Guest,
+		Roles:     subject.Roles,
+		SessionID: subject.SessionID,
+	}
+	return KeyManager().Sign(claims)
 }
 
 // JWT検証
 func ParseToken(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return jwtSecret, nil
-	})
+	claims, err := ParseTokenClaims(tokenString)
 	if err != nil {
 		return "", err
 	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if uid, ok := claims["user_id"].(string); ok {
-			return uid, nil
-		}
+	return claims.UserID(), nil
+}
+
+// ParseTokenClaims はJWTの署名・発行者・対象者・有効期間を検証し、クレームを返す
+func ParseTokenClaims(tokenString string) (*AccessTokenClaims, error) {
+	manager := KeyManager()
+	claims := &AccessTokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, manager.Keyfunc,
+		jwt.WithValidMethods(manager.ValidMethods()),
+		jwt.WithIssuer(TokenIssuer()),
+		jwt.WithAudience(TokenAudience()),
+		jwt.WithLeeway(ClockSkew),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, jwt.ErrTokenMalformed
 	}
-	return "", jwt.ErrTokenMalformed
+	return claims, nil
 }
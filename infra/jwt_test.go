@@ -2,26 +2,88 @@ package infra
 
 import (
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateAndParseToken_OK(t *testing.T) {
 	userID := "123"
-	token, err := GenerateToken(userID)
+	token, err := GenerateToken(AccessTokenSubject{UserID: userID})
 	assert.NoError(t, err)
 	parsedID, err := ParseToken(token)
 	assert.NoError(t, err)
 	assert.Equal(t, userID, parsedID)
 }
 
+func TestParseTokenClaims_Claims(t *testing.T) {
+	token, err := GenerateToken(AccessTokenSubject{UserID: "123", SessionID: "session-1", Guest: true, Roles: []string{"admin"}})
+	require.NoError(t, err)
+	claims, err := ParseTokenClaims(token)
+	require.NoError(t, err)
+	assert.Equal(t, "123", claims.UserID())
+	assert.Equal(t, "session-1", claims.SessionID)
+	assert.True(t, claims.Guest)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+	assert.Equal(t, TokenIssuer(), claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{TokenAudience()}, claims.Audience)
+	assert.NotEmpty(t, claims.ID)
+	assert.NotNil(t, claims.IssuedAt)
+	assert.NotNil(t, claims.NotBefore)
+
+	other, err := GenerateToken(AccessTokenSubject{UserID: "123"})
+	require.NoError(t, err)
+	otherClaims, err := ParseTokenClaims(other)
+	require.NoError(t, err)
+	assert.NotEqual(t, claims.ID, otherClaims.ID, "jtiはトークンごとに異なる")
+}
+
+func TestParseTokenClaims_Validation(t *testing.T) {
+	now := time.Now()
+	valid := func() *AccessTokenClaims {
+		return &AccessTokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "u1",
+			Issuer:    TokenIssuer(),
+			Audience:  jwt.ClaimStrings{TokenAudience()},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		}}
+	}
+	tests := []struct {
+		name    string
+		modify  func(c *AccessTokenClaims)
+		wantErr bool
+	}{
+		{name: "正常", modify: func(c *AccessTokenClaims) {}},
+		{name: "発行者が異なる", modify: func(c *AccessTokenClaims) { c.Issuer = "https://evil.example.com" }, wantErr: true},
+		{name: "対象者が異なる", modify: func(c *AccessTokenClaims) { c.Audience = jwt.ClaimStrings{"other-api"} }, wantErr: true},
+		{name: "期限切れ", modify: func(c *AccessTokenClaims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) }, wantErr: true},
+		{name: "期限切れ直後は時刻のずれとして許容", modify: func(c *AccessTokenClaims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-10 * time.Second)) }},
+		{name: "有効期間の開始前", modify: func(c *AccessTokenClaims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute)) }, wantErr: true},
+		{name: "未来の発行日時は時刻のずれの範囲内なら許容", modify: func(c *AccessTokenClaims) { c.IssuedAt = jwt.NewNumericDate(now.Add(10 * time.Second)) }},
+		{name: "有効期限なし", modify: func(c *AccessTokenClaims) { c.ExpiresAt = nil }, wantErr: true},
+		{name: "subなし", modify: func(c *AccessTokenClaims) { c.Subject = "" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(claims)
+			token, err := KeyManager().Sign(claims)
+			require.NoError(t, err)
+			_, err = ParseTokenClaims(token)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestParseToken_InvalidToken(t *testing.T) {
 	_, err := ParseToken("invalid.token.value")
 	assert.Error(t, err)
 }
-
-func TestParseToken_Expired(t *testing.T) {
-	// 有効期限を過去にしたトークンを生成
-	// ここではGenerateTokenのexpを一時的に過去にして生成する方法が必要
-	// 省略: 実装例ではexpを直接書き換えたトークンを用意するのが一般的
-}
@@ -0,0 +1,194 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultSecret は開発用の共通鍵（本番環境では使用できない）
+	DefaultSecret = "your-secret-key"
+	// MinSecretLength は本番環境で共通鍵に求める最小のバイト数
+	MinSecretLength = 32
+	// LegacyKeyID はJWT_SECRETから作るHS256鍵のkid（kidなしのトークンの検証にも使う）
+	LegacyKeyID = "hs256"
+)
+
+// keyConfig はJWT_KEYSに設定する鍵の1件分
+type keyConfig struct {
+	ID          string `json:"kid"`
+	Algorithm   string `json:"alg"`
+	PrivateKey  string `json:"private_key"`
+	PublicKey   string `json:"public_key"`
+	ActivatesAt string `json:"activates_at"`
+	RetiresAt   string `json:"retires_at"`
+}
+
+// IsProduction は本番環境（ENV=prod）で動作しているかどうかを返す
+func IsProduction() bool {
+	return os.Getenv("ENV") == "prod"
+}
+
+// CheckSecret は本番環境で使う共通鍵が既定値や短すぎる値でないかを確認する
+func CheckSecret(name string, secret []byte) error {
+	if string(secret) == DefaultSecret {
+		return fmt.Errorf("%s に開発用の既定値が設定されています", name)
+	}
+	if len(secret) < MinSecretLength {
+		return fmt.Errorf("%s は%dバイト以上のランダムな値にしてください", name, MinSecretLength)
+	}
+	return nil
+}
+
+// SecretFromEnv は環境変数を順に参照してHS256の共通鍵を返す。
+// どれも未設定の場合は開発用の既定値を返すが、本番環境では既定値や弱い値の場合にエラーを返す
+func SecretFromEnv(names ...string) ([]byte, error) {
+	for _, name := range names {
+		if secret := os.Getenv(name); secret != "" {
+			if IsProduction() {
+				if err := CheckSecret(name, []byte(secret)); err != nil {
+					return nil, err
+				}
+			}
+			return []byte(secret), nil
+		}
+	}
+	if IsProduction() && len(names) > 0 {
+		return nil, fmt.Errorf("本番環境では %s を設定してください", names[0])
+	}
+	return []byte(DefaultSecret), nil
+}
+
+// FromEnv は環境変数からManagerを作成する。
+// JWT_KEYS（JSON配列）の非対称鍵を優先して署名に使い、JWT_SECRETはHS256鍵として
+// 非対称鍵が有効になるまでの署名と、kidなしの既存トークンの検証に使う。
+// 共通鍵を知っていればトークンを発行できてしまうため、JWT_SECRET_RETIRES_AT以降は検証にも使わない
+func FromEnv() (*Manager, error) {
+	keys, err := ParseKeys(os.Getenv("JWT_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" && len(keys) == 0 {
+		if IsProduction() {
+			return nil, errors.New("本番環境では JWT_KEYS または JWT_SECRET を設定してください")
+		}
+		log.Printf("[WARN] JWT_KEYS・JWT_SECRETが未設定のため開発用の共通鍵で署名します")
+		secret = DefaultSecret
+	}
+	if secret != "" {
+		if IsProduction() {
+			if err := CheckSecret("JWT_SECRET", []byte(secret)); err != nil {
+				return nil, err
+			}
+		}
+		retiresAt, err := parseTime(os.Getenv("JWT_SECRET_RETIRES_AT"))
+		if err != nil {
+			return nil, fmt.Errorf("JWT_SECRET_RETIRES_ATが不正です: %w", err)
+		}
+		// 非対称鍵へ移行した後も共通鍵で発行したトークンを受け付け続けないよう、本番環境では検証を終える日時を求める
+		if IsProduction() && len(keys) > 0 && retiresAt.IsZero() {
+			return nil, errors.New("JWT_KEYSを設定した場合は JWT_SECRET_RETIRES_AT に JWT_SECRET の検証を終える日時を設定してください")
+		}
+		keys = append(keys, &Key{
+			ID:              LegacyKeyID,
+			Algorithm:       AlgHS256,
+			SigningKey:      []byte(secret),
+			VerificationKey: []byte(secret),
+			RetiresAt:       retiresAt,
+		})
+	}
+	return NewManager(keys, LegacyKeyID)
+}
+
+// ParseKeys はJWT_KEYSの値（鍵設定のJSON配列）を解析する。
+// private_keyを省略してpublic_keyのみ指定した鍵は、ローテーション後の検証専用になる
+func ParseKeys(value string) ([]*Key, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var configs []keyConfig
+	if err := json.Unmarshal([]byte(value), &configs); err != nil {
+		return nil, fmt.Errorf("JWT_KEYSの形式が不正です: %w", err)
+	}
+	keys := make([]*Key, 0, len(configs))
+	for _, c := range configs {
+		key, err := c.toKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (c keyConfig) toKey() (*Key, error) {
+	if c.Algorithm != AlgRS256 && c.Algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("鍵%sのアルゴリズムはRS256またはEdDSAを指定してください", c.ID)
+	}
+	key := &Key{ID: c.ID, Algorithm: c.Algorithm}
+	var err error
+	if key.ActivatesAt, err = parseTime(c.ActivatesAt); err != nil {
+		return nil, fmt.Errorf("鍵%sのactivates_atが不正です: %w", c.ID, err)
+	}
+	if key.RetiresAt, err = parseTime(c.RetiresAt); err != nil {
+		return nil, fmt.Errorf("鍵%sのretires_atが不正です: %w", c.ID, err)
+	}
+	switch {
+	case c.PrivateKey != "":
+		private, err := parsePEM(c.PrivateKey, x509.ParsePKCS8PrivateKey, func(der []byte) (interface{}, error) {
+			return x509.ParsePKCS1PrivateKey(der)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("鍵%sの秘密鍵を読み込めません: %w", c.ID, err)
+		}
+		key.SigningKey = private
+		switch k := private.(type) {
+		case *rsa.PrivateKey:
+			key.VerificationKey = &k.PublicKey
+		case ed25519.PrivateKey:
+			key.VerificationKey = k.Public()
+		}
+	case c.PublicKey != "":
+		public, err := parsePEM(c.PublicKey, x509.ParsePKIXPublicKey, func(der []byte) (interface{}, error) {
+			return x509.ParsePKCS1PublicKey(der)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("鍵%sの公開鍵を読み込めません: %w", c.ID, err)
+		}
+		key.VerificationKey = public
+	default:
+		return nil, fmt.Errorf("鍵%sにprivate_keyまたはpublic_keyを指定してください", c.ID)
+	}
+	return key, nil
+}
+
+func parsePEM(value string, parsers ...func([]byte) (interface{}, error)) (interface{}, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, errors.New("PEM形式ではありません")
+	}
+	var err error
+	for _, parse := range parsers {
+		var key interface{}
+		if key, err = parse(block.Bytes); err == nil {
+			return key, nil
+		}
+	}
+	return nil, err
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
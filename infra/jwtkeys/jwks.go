@@ -0,0 +1,53 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JSONWebKey は公開鍵のJWK表現（RFC 7517・RFC 8037）
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP（Ed25519）
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS は検証に使う公開鍵の一覧を返す。
+// 有効になる前の鍵も含めるため、ローテーションの前に他のサービスが新しい鍵を取得できる
+func (m *Manager) JWKS() JSONWebKeySet {
+	now := m.now()
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, k := range m.keys {
+		if !k.IsPublic() || k.retired(now) {
+			continue
+		}
+		jwk := JSONWebKey{Kid: k.ID, Alg: k.Algorithm, Use: "sig"}
+		switch pub := k.VerificationKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,187 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+	AlgHS256 = "HS256"
+)
+
+// Key はJWTの署名・検証に使う鍵。
+// ActivatesAt以降に署名に使われ（新しい鍵が有効になると切り替わる）、RetiresAtまでは検証に使われる
+type Key struct {
+	ID        string
+	Algorithm string
+	// SigningKey は署名用の鍵（検証専用の鍵ではnil）。*rsa.PrivateKey、ed25519.PrivateKey、[]byte（HS256）のいずれか
+	SigningKey interface{}
+	// VerificationKey は検証用の鍵。*rsa.PublicKey、ed25519.PublicKey、[]byte（HS256）のいずれか
+	VerificationKey interface{}
+	ActivatesAt     time.Time
+	// RetiresAt は検証に使わなくなる日時（ゼロ値の場合は無期限）
+	RetiresAt time.Time
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// IsPublic はJWKSで公開できる（非対称鍵の）鍵かどうかを返す
+func (k *Key) IsPublic() bool {
+	return k.Algorithm != AlgHS256
+}
+
+func (k *Key) retired(now time.Time) bool {
+	return !k.RetiresAt.IsZero() && !now.Before(k.RetiresAt)
+}
+
+func (k *Key) validate() error {
+	if k.ID == "" {
+		return errors.New("鍵のkidが指定されていません")
+	}
+	switch k.Algorithm {
+	case AlgRS256:
+		pub, ok := k.VerificationKey.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("鍵%sはRSAの公開鍵ではありません", k.ID)
+		}
+		if pub.N.BitLen() < 2048 {
+			return fmt.Errorf("鍵%sのRSA鍵長は2048ビット以上にしてください", k.ID)
+		}
+		if k.SigningKey != nil {
+			if _, ok := k.SigningKey.(*rsa.PrivateKey); !ok {
+				return fmt.Errorf("鍵%sはRSAの秘密鍵ではありません", k.ID)
+			}
+		}
+	case AlgEdDSA:
+		if _, ok := k.VerificationKey.(ed25519.PublicKey); !ok {
+			return fmt.Errorf("鍵%sはEd25519の公開鍵ではありません", k.ID)
+		}
+		if k.SigningKey != nil {
+			if _, ok := k.SigningKey.(ed25519.PrivateKey); !ok {
+				return fmt.Errorf("鍵%sはEd25519の秘密鍵ではありません", k.ID)
+			}
+		}
+	case AlgHS256:
+		if _, ok := k.VerificationKey.([]byte); !ok {
+			return fmt.Errorf("鍵%sはHS256の共通鍵ではありません", k.ID)
+		}
+	default:
+		return fmt.Errorf("鍵%sのアルゴリズム%sには対応していません", k.ID, k.Algorithm)
+	}
+	return nil
+}
+
+// Manager は複数の署名鍵を管理し、日時に応じた鍵で署名し、kidで検証用の鍵を選ぶ
+type Manager struct {
+	keys []*Key
+	// legacyKey はkidを含まない（鍵管理の導入前に発行した）トークンの検証に使う鍵
+	legacyKey *Key
+	now       func() time.Time
+}
+
+// NewManager は鍵を検証してManagerを作成する。legacyKeyIDにはkidなしのトークンを検証する鍵を指定する（不要なら空）
+func NewManager(keys []*Key, legacyKeyID string) (*Manager, error) {
+	m := &Manager{now: time.Now}
+	seen := map[string]bool{}
+	for _, k := range keys {
+		if err := k.validate(); err != nil {
+			return nil, err
+		}
+		if seen[k.ID] {
+			return nil, fmt.Errorf("kid %s が重複しています", k.ID)
+		}
+		seen[k.ID] = true
+		if k.ID == legacyKeyID {
+			m.legacyKey = k
+		}
+		m.keys = append(m.keys, k)
+	}
+	// 有効になる日時の新しい順に並べ、署名時は最初に見つかった有効な鍵を使う
+	sort.SliceStable(m.keys, func(i, j int) bool { return m.keys[i].ActivatesAt.After(m.keys[j].ActivatesAt) })
+	if _, err := m.SigningKey(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SigningKey は現在署名に使う鍵を返す
+func (m *Manager) SigningKey() (*Key, error) {
+	now := m.now()
+	for _, k := range m.keys {
+		if k.SigningKey != nil && !now.Before(k.ActivatesAt) && !k.retired(now) {
+			return k, nil
+		}
+	}
+	return nil, errors.New("現在有効な署名鍵がありません")
+}
+
+// Sign はクレームに現在の鍵で署名し、ヘッダにkidを付ける
+func (m *Manager) Sign(claims jwt.Claims) (string, error) {
+	key, err := m.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.SigningKey)
+}
+
+// Keyfunc はトークンのkidとアルゴリズムに一致する検証用の鍵を返す（jwt.Parseに渡す）
+func (m *Manager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	key, err := m.verificationKey(token)
+	if err != nil {
+		return nil, err
+	}
+	// ヘッダのalgを信用せず、鍵に設定したアルゴリズムと一致する場合のみ検証する
+	if token.Method.Alg() != key.Algorithm {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.VerificationKey, nil
+}
+
+func (m *Manager) verificationKey(token *jwt.Token) (*Key, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if m.legacyKey == nil || m.legacyKey.retired(m.now()) {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+		return m.legacyKey, nil
+	}
+	now := m.now()
+	for _, k := range m.keys {
+		if k.ID == kid && !k.retired(now) {
+			return k, nil
+		}
+	}
+	return nil, jwt.ErrTokenUnverifiable
+}
+
+// ValidMethods は検証で受け付けるアルゴリズムの一覧を返す（jwt.WithValidMethodsに渡す）
+func (m *Manager) ValidMethods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, k := range m.keys {
+		if !seen[k.Algorithm] {
+			seen[k.Algorithm] = true
+			methods = append(methods, k.Algorithm)
+		}
+	}
+	return methods
+}
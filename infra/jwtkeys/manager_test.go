@@ -0,0 +1,204 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEd25519Key(t *testing.T, id string, activatesAt time.Time) *Key {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &Key{ID: id, Algorithm: AlgEdDSA, SigningKey: priv, VerificationKey: pub, ActivatesAt: activatesAt}
+}
+
+func newRSAKey(t *testing.T, id string, activatesAt time.Time) *Key {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &Key{ID: id, Algorithm: AlgRS256, SigningKey: priv, VerificationKey: &priv.PublicKey, ActivatesAt: activatesAt}
+}
+
+func parse(m *Manager, token string) (*jwt.Token, error) {
+	return jwt.Parse(token, m.Keyfunc, jwt.WithValidMethods(m.ValidMethods()))
+}
+
+func TestManager_ScheduledRotation(t *testing.T) {
+	now := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	oldKey := newRSAKey(t, "old", now.Add(-30*24*time.Hour))
+	newKey := newEd25519Key(t, "new", now.Add(time.Hour))
+	m, err := NewManager([]*Key{oldKey, newKey}, "")
+	require.NoError(t, err)
+	m.now = func() time.Time { return now }
+
+	// 新しい鍵が有効になるまでは古い鍵で署名する
+	before, err := m.Sign(jwt.MapClaims{"user_id": "u1"})
+	require.NoError(t, err)
+	token, err := parse(m, before)
+	require.NoError(t, err)
+	assert.Equal(t, "old", token.Header["kid"])
+
+	// 有効日時を過ぎると新しい鍵に切り替わり、古い鍵のトークンも引き続き検証できる
+	m.now = func() time.Time { return now.Add(2 * time.Hour) }
+	after, err := m.Sign(jwt.MapClaims{"user_id": "u1"})
+	require.NoError(t, err)
+	token, err = parse(m, after)
+	require.NoError(t, err)
+	assert.Equal(t, "new", token.Header["kid"])
+	_, err = parse(m, before)
+	assert.NoError(t, err)
+
+	// 退役した鍵のトークンは拒否する
+	oldKey.RetiresAt = now.Add(3 * time.Hour)
+	m.now = func() time.Time { return now.Add(4 * time.Hour) }
+	_, err = parse(m, before)
+	assert.Error(t, err)
+}
+
+func TestManager_RejectsUnknownKidAndAlgorithmMismatch(t *testing.T) {
+	key := newEd25519Key(t, "k1", time.Time{})
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	m, err := NewManager([]*Key{key, {ID: LegacyKeyID, Algorithm: AlgHS256, VerificationKey: secret}}, LegacyKeyID)
+	require.NoError(t, err)
+
+	other := newEd25519Key(t, "k2", time.Time{})
+	unknown := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"user_id": "u1"})
+	unknown.Header["kid"] = "k2"
+	signed, err := unknown.SignedString(other.SigningKey)
+	require.NoError(t, err)
+	_, err = parse(m, signed)
+	assert.Error(t, err, "未知のkidは拒否する")
+
+	// EdDSA鍵のkidを指定したHS256トークンは、アルゴリズムが一致しないため拒否する
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "u1"})
+	confused.Header["kid"] = "k1"
+	signed, err = confused.SignedString(secret)
+	require.NoError(t, err)
+	_, err = parse(m, signed)
+	assert.Error(t, err)
+
+	// kidなしの既存トークンはレガシー鍵で検証する
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "u1"}).SignedString(secret)
+	require.NoError(t, err)
+	_, err = parse(m, legacy)
+	assert.NoError(t, err)
+}
+
+func TestManager_JWKS(t *testing.T) {
+	ed := newEd25519Key(t, "ed", time.Time{})
+	rs := newRSAKey(t, "rs", time.Time{})
+	m, err := NewManager([]*Key{ed, rs, {ID: LegacyKeyID, Algorithm: AlgHS256, SigningKey: []byte("s"), VerificationKey: []byte("s")}}, LegacyKeyID)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(m.JWKS())
+	require.NoError(t, err)
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(body, &set))
+	require.Len(t, set.Keys, 2, "共通鍵は公開しない")
+	byKid := map[string]map[string]string{}
+	for _, k := range set.Keys {
+		byKid[k["kid"]] = k
+	}
+	assert.Equal(t, "OKP", byKid["ed"]["kty"])
+	assert.Equal(t, "Ed25519", byKid["ed"]["crv"])
+	assert.NotEmpty(t, byKid["ed"]["x"])
+	assert.Equal(t, "RSA", byKid["rs"]["kty"])
+	assert.Equal(t, "AQAB", byKid["rs"]["e"])
+	assert.Equal(t, "sig", byKid["rs"]["use"])
+}
+
+func TestFromEnv(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	keys, err := json.Marshal([]map[string]string{{
+		"kid":         "ed-2025",
+		"alg":         AlgEdDSA,
+		"private_key": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}})
+	require.NoError(t, err)
+
+	t.Run("非対称鍵を優先して署名する", func(t *testing.T) {
+		t.Setenv("ENV", "prod")
+		t.Setenv("JWT_KEYS", string(keys))
+		t.Setenv("JWT_SECRET", "")
+		m, err := FromEnv()
+		require.NoError(t, err)
+		key, err := m.SigningKey()
+		require.NoError(t, err)
+		assert.Equal(t, "ed-2025", key.ID)
+	})
+
+	t.Run("本番環境では既定値・弱い共通鍵を拒否する", func(t *testing.T) {
+		t.Setenv("ENV", "prod")
+		t.Setenv("JWT_KEYS", "")
+		for _, secret := range []string{"", DefaultSecret, "short-secret"} {
+			t.Setenv("JWT_SECRET", secret)
+			_, err := FromEnv()
+			assert.Error(t, err, secret)
+		}
+		t.Setenv("JWT_KEYS", string(keys))
+		t.Setenv("JWT_SECRET", DefaultSecret)
+		_, err := FromEnv()
+		assert.Error(t, err, "非対称鍵があっても既定値の共通鍵は拒否する")
+	})
+
+	t.Run("共通鍵はJWT_SECRET_RETIRES_ATを過ぎると検証に使わない", func(t *testing.T) {
+		secret := "0123456789abcdef0123456789abcdef"
+		t.Setenv("ENV", "prod")
+		t.Setenv("JWT_KEYS", string(keys))
+		t.Setenv("JWT_SECRET", secret)
+		t.Setenv("JWT_SECRET_RETIRES_AT", "")
+		_, err := FromEnv()
+		assert.Error(t, err, "非対称鍵へ移行する場合は検証を終える日時を求める")
+
+		t.Setenv("JWT_SECRET_RETIRES_AT", "2025-06-01T00:00:00Z")
+		m, err := FromEnv()
+		require.NoError(t, err)
+		forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "u1"}).SignedString([]byte(secret))
+		require.NoError(t, err)
+		m.now = func() time.Time { return time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC) }
+		_, err = jwt.Parse(forged, m.Keyfunc)
+		assert.NoError(t, err, "検証を終えるまではkidのない既存のトークンを受け付ける")
+		m.now = func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }
+		_, err = jwt.Parse(forged, m.Keyfunc)
+		assert.Error(t, err)
+	})
+
+	t.Run("開発環境では既定値で起動できる", func(t *testing.T) {
+		t.Setenv("ENV", "")
+		t.Setenv("JWT_KEYS", "")
+		t.Setenv("JWT_SECRET", "")
+		m, err := FromEnv()
+		require.NoError(t, err)
+		key, err := m.SigningKey()
+		require.NoError(t, err)
+		assert.Equal(t, LegacyKeyID, key.ID)
+	})
+}
+
+func TestSecretFromEnv(t *testing.T) {
+	t.Setenv("ENV", "prod")
+	t.Setenv("AUTH_TICKET_SECRET", "")
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	_, err := SecretFromEnv("AUTH_TICKET_SECRET")
+	assert.Error(t, err, "JWT_SECRETは使わない")
+
+	t.Setenv("AUTH_TICKET_SECRET", "0123456789abcdef0123456789abcdef")
+	secret, err := SecretFromEnv("AUTH_TICKET_SECRET")
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", string(secret))
+}
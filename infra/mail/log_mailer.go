@@ -0,0 +1,25 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"os"
+	"tofunote-backend/domain/notification"
+)
+
+// LogMailer はメールをログに出力するだけのMailer（ローカル開発やSMTP未設定時に使う）
+type LogMailer struct{}
+
+func NewLogMailer() notification.Mailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, mail notification.Mail) error {
+	// 本文には確認用のトークン等が含まれるため、本番環境では出力しない
+	if os.Getenv("ENV") == "prod" {
+		log.Printf("[INFO] Mail: to=%s subject=%s", mail.To, mail.Subject)
+		return nil
+	}
+	log.Printf("[INFO] Mail: to=%s subject=%s\n%s", mail.To, mail.Subject, mail.Body)
+	return nil
+}
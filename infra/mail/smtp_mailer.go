@@ -0,0 +1,114 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+	"tofunote-backend/domain/notification"
+
+	"github.com/cmackenzie1/go-uuid"
+)
+
+// SMTPConfig はSMTPサーバーへの接続設定
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer はSMTPでメールを送信するMailer（サーバーがSTARTTLSに対応していれば暗号化して送る）
+type SMTPMailer struct {
+	config SMTPConfig
+	now    func() time.Time
+}
+
+func NewSMTPMailer(config SMTPConfig) notification.Mailer {
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	return &SMTPMailer{config: config, now: time.Now}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, mail notification.Mail) error {
+	if strings.ContainsAny(mail.To, "\r\n") || strings.ContainsAny(mail.Subject, "\r\n") {
+		return errors.New("メールのヘッダーに改行を含めることはできません")
+	}
+	msg, err := m.buildMessage(mail)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+
+	// net/smtpはcontextに対応していないため、キャンセル時は結果を待たずに戻る
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, m.config.From, []string{mail.To}, msg)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("メールの送信に失敗しました: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *SMTPMailer) buildMessage(mail notification.Mail) ([]byte, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	domain := m.config.From[strings.LastIndex(m.config.From, "@")+1:]
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", mail.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", m.now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", id.String(), strings.Trim(domain, "<>"))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	encoded := base64.StdEncoding.EncodeToString([]byte(mail.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes(), nil
+}
+
+// MailerFromEnv は環境変数（SMTP_HOST / SMTP_PORT / SMTP_USERNAME / SMTP_PASSWORD / MAIL_FROM）からMailerを生成する。
+// SMTP_HOSTが未設定の場合はログに出力するだけのMailerを返す
+func MailerFromEnv() notification.Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return NewLogMailer()
+	}
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@tofunote.takoscreamo.com"
+	}
+	return NewSMTPMailer(SMTPConfig{
+		Host:     host,
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	})
+}
@@ -0,0 +1,119 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"tofunote-backend/domain/notification"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSMTPServer は1通分のSMTPセッションを受け付けて内容を記録するローカルのSMTPサーバー
+type stubSMTPServer struct {
+	addr     string
+	received chan receivedMail
+}
+
+type receivedMail struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+func newStubSMTPServer(t *testing.T) *stubSMTPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	s := &stubSMTPServer{addr: ln.Addr().String(), received: make(chan receivedMail, 1)}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.serve(textproto.NewConn(conn))
+	}()
+	return s
+}
+
+func (s *stubSMTPServer) serve(c *textproto.Conn) {
+	var mail receivedMail
+	_ = c.PrintfLine("220 localhost ESMTP stub")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			_ = c.PrintfLine("250-localhost")
+			_ = c.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			mail.auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+			_ = c.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			mail.from = line
+			_ = c.PrintfLine("250 OK")
+		case "RCPT":
+			mail.to = append(mail.to, line)
+			_ = c.PrintfLine("250 OK")
+		case "DATA":
+			_ = c.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mail.data = string(data)
+			_ = c.PrintfLine("250 OK")
+		case "QUIT":
+			_ = c.PrintfLine("221 Bye")
+			s.received <- mail
+			return
+		default:
+			_ = c.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	server := newStubSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.addr)
+	mailer := NewSMTPMailer(SMTPConfig{Host: host, Port: port, Username: "user", Password: "pass", From: "no-reply@example.com"})
+
+	err := mailer.Send(context.Background(), notification.Mail{
+		To:      "someone@example.com",
+		Subject: "メールアドレスの確認",
+		Body:    "以下のURLを開いてください\nhttps://example.com/verify?token=abc",
+	})
+	require.NoError(t, err)
+
+	got := <-server.received
+	auth, _ := base64.StdEncoding.DecodeString(got.auth)
+	assert.Equal(t, "\x00user\x00pass", string(auth))
+	assert.Equal(t, "MAIL FROM:<no-reply@example.com>", strings.SplitN(got.from, " BODY", 2)[0])
+	assert.Equal(t, []string{"RCPT TO:<someone@example.com>"}, got.to)
+
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(got.data))).ReadMIMEHeader()
+	require.NoError(t, err)
+	assert.Equal(t, "someone@example.com", msg.Get("To"))
+	assert.Equal(t, "=?UTF-8?b?44Oh44O844Or44Ki44OJ44Os44K544Gu56K66KqN?=", msg.Get("Subject"))
+	// ReadDotBytesにより改行はLFに変換されている
+	body := got.data[strings.Index(got.data, "\n\n")+2:]
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\n", ""))
+	require.NoError(t, err)
+	assert.Contains(t, string(decoded), "https://example.com/verify?token=abc")
+}
+
+func TestSMTPMailer_RejectsHeaderInjection(t *testing.T) {
+	mailer := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: "1", From: "no-reply@example.com"})
+	err := mailer.Send(context.Background(), notification.Mail{To: "a@example.com\r\nBcc: b@example.com", Subject: "x"})
+	assert.Error(t, err)
+}
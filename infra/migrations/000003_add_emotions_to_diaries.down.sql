@@ -0,0 +1 @@
+ALTER TABLE diaries DROP COLUMN IF EXISTS emotions;
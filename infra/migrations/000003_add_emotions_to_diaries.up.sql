@@ -0,0 +1 @@
+ALTER TABLE diaries ADD COLUMN IF NOT EXISTS emotions JSONB;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS emotion_labels (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    key VARCHAR(32) NOT NULL,
+    category VARCHAR(32) NOT NULL,
+    name_ja VARCHAR(64),
+    name_en VARCHAR(64),
+    created_at timestamp with time zone DEFAULT now(),
+    UNIQUE (user_id, key)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS factor_definitions;
+DROP TABLE IF EXISTS daily_factors;
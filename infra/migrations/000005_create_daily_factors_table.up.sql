@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS daily_factors (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    date DATE NOT NULL,
+    sleep_hours NUMERIC(4,2),
+    exercise_minutes INTEGER,
+    alcohol_units NUMERIC(5,2),
+    medication_taken BOOLEAN,
+    custom JSONB,
+    created_at timestamp with time zone DEFAULT now(),
+    updated_at timestamp with time zone DEFAULT now(),
+    UNIQUE (user_id, date)
+);
+
+CREATE TABLE IF NOT EXISTS factor_definitions (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    key VARCHAR(32) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    unit VARCHAR(16),
+    min DOUBLE PRECISION,
+    max DOUBLE PRECISION,
+    created_at timestamp with time zone DEFAULT now(),
+    UNIQUE (user_id, key)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS medication_dose_logs;
+DROP TABLE IF EXISTS medications;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS medications (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    dose VARCHAR(100),
+    schedule JSONB NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE,
+    reminders_enabled BOOLEAN NOT NULL DEFAULT false,
+    created_at timestamp with time zone DEFAULT now(),
+    updated_at timestamp with time zone DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_medications_user_id ON medications (user_id);
+
+CREATE TABLE IF NOT EXISTS medication_dose_logs (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    medication_id uuid NOT NULL REFERENCES medications (id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    scheduled_time VARCHAR(5) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    taken_at timestamp with time zone,
+    note TEXT,
+    created_at timestamp with time zone DEFAULT now(),
+    updated_at timestamp with time zone DEFAULT now(),
+    UNIQUE (medication_id, date, scheduled_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dose_log_user_date ON medication_dose_logs (user_id, date);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS thought_records (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    date DATE,
+    situation TEXT NOT NULL,
+    automatic_thought TEXT NOT NULL,
+    emotions JSONB NOT NULL,
+    evidence_for TEXT,
+    evidence_against TEXT,
+    balanced_thought TEXT,
+    created_at timestamp with time zone DEFAULT now(),
+    updated_at timestamp with time zone DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_thought_record_user_date ON thought_records (user_id, date);
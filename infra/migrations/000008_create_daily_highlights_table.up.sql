@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS daily_highlights (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    date DATE NOT NULL,
+    good_things JSONB,
+    highlight VARCHAR(200),
+    lowlight VARCHAR(200),
+    created_at timestamp with time zone DEFAULT now(),
+    updated_at timestamp with time zone DEFAULT now(),
+    UNIQUE (user_id, date)
+);
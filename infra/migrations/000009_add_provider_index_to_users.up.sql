@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_provider_subject ON users (provider, provider_id) WHERE provider <> '';
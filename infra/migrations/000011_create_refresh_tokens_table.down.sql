@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS refresh_token VARCHAR(255);
+DROP TABLE IF EXISTS refresh_tokens;
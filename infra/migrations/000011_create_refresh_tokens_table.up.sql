@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    family_id uuid NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    device_label VARCHAR(100),
+    expires_at timestamp with time zone NOT NULL,
+    rotated_at timestamp with time zone,
+    revoked_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+-- 既存の平文のリフレッシュトークンはハッシュにして移行する（ログイン状態を維持するため）
+INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at)
+SELECT t.id, u.id, t.id, encode(sha256(convert_to(u.refresh_token, 'UTF8')), 'hex'), now() + interval '30 days'
+FROM users u
+CROSS JOIN LATERAL (SELECT gen_random_uuid() AS id) t
+WHERE u.refresh_token IS NOT NULL AND u.refresh_token <> '';
+
+ALTER TABLE users DROP COLUMN IF EXISTS refresh_token;
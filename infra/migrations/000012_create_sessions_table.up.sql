@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    device_label VARCHAR(100),
+    user_agent VARCHAR(512),
+    ip_address VARCHAR(45),
+    created_at timestamp with time zone DEFAULT now(),
+    last_used_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    revoked_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_revoked_at ON sessions (revoked_at);
+
+-- 既存のリフレッシュトークンのファミリーをセッションとして登録する
+INSERT INTO sessions (id, user_id, device_label, created_at, last_used_at, expires_at, revoked_at)
+SELECT family_id, user_id, max(device_label), min(created_at), max(created_at), max(expires_at),
+       CASE WHEN bool_or(revoked_at IS NULL) THEN NULL ELSE max(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id, user_id
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS passkeys (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    credential_id bytea NOT NULL,
+    public_key bytea NOT NULL,
+    algorithm INTEGER NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports VARCHAR(100),
+    created_at timestamp with time zone DEFAULT now(),
+    last_used_at timestamp with time zone
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_passkeys_credential_id ON passkeys (credential_id);
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys (user_id);
+
+CREATE TABLE IF NOT EXISTS passkey_challenges (
+    id uuid PRIMARY KEY,
+    ceremony VARCHAR(16) NOT NULL,
+    user_id uuid,
+    challenge bytea NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    used_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_passkey_challenges_user_id ON passkey_challenges (user_id);
+CREATE INDEX IF NOT EXISTS idx_passkey_challenges_expires_at ON passkey_challenges (expires_at);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS totp_factors (
+    user_id uuid PRIMARY KEY,
+    secret bytea NOT NULL,
+    enabled_at timestamp with time zone,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
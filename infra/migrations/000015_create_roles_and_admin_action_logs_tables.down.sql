@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS admin_action_logs;
+DROP TABLE IF EXISTS user_roles;
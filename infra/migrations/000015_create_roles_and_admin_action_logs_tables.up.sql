@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id uuid NOT NULL,
+    role VARCHAR(32) NOT NULL,
+    created_at timestamp with time zone DEFAULT now(),
+    PRIMARY KEY (user_id, role)
+);
+
+-- 管理者の操作の監査ログ（対象ユーザーの削除後も残すため、外部キーは張らない）
+CREATE TABLE IF NOT EXISTS admin_action_logs (
+    id uuid PRIMARY KEY,
+    admin_user_id uuid NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_user_id VARCHAR(36),
+    reason TEXT,
+    created_at timestamp with time zone DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_admin_action_logs_admin_user_id ON admin_action_logs (admin_user_id);
+CREATE INDEX IF NOT EXISTS idx_admin_action_logs_target_user_id ON admin_action_logs (target_user_id);
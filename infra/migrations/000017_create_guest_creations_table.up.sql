@@ -0,0 +1,10 @@
+-- ゲストアカウントの作成の記録（IPアドレスごと・全体のレート制限と作成数の集計に使う）
+CREATE TABLE IF NOT EXISTS guest_creations (
+    id uuid PRIMARY KEY,
+    ip_hash VARCHAR(64) NOT NULL,
+    challenge_id VARCHAR(64),
+    created_at timestamp with time zone DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_guest_creations_ip_created ON guest_creations (ip_hash, created_at);
+CREATE INDEX IF NOT EXISTS idx_guest_creations_created_at ON guest_creations (created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guest_creations_challenge_id ON guest_creations (challenge_id);
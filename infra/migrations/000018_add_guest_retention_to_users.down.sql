@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_deletion_notice_at;
+DROP INDEX IF EXISTS idx_users_guest_last_seen;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_notice_at;
+ALTER TABLE users DROP COLUMN IF EXISTS last_seen_at;
@@ -0,0 +1,5 @@
+-- 利用のないゲストアカウントの自動削除（最終利用日時と削除の予告日時）
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_notice_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_users_guest_last_seen ON users (COALESCE(last_seen_at, created_at)) WHERE is_guest;
+CREATE INDEX IF NOT EXISTS idx_users_deletion_notice_at ON users (deletion_notice_at) WHERE deletion_notice_at IS NOT NULL;
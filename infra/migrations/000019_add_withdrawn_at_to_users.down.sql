@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_withdrawn_at;
+ALTER TABLE users DROP COLUMN IF EXISTS withdrawn_at;
@@ -0,0 +1,3 @@
+-- 退会の猶予期間（申請から一定期間は取り消せるようにし、期間を過ぎたユーザーをジョブで完全に削除する）
+ALTER TABLE users ADD COLUMN IF NOT EXISTS withdrawn_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_users_withdrawn_at ON users (withdrawn_at) WHERE withdrawn_at IS NOT NULL;
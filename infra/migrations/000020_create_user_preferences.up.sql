@@ -0,0 +1,12 @@
+-- ユーザーごとの設定（保存していないユーザーは既定値として扱う）
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY,
+    timezone VARCHAR(64) NOT NULL,
+    locale VARCHAR(16) NOT NULL,
+    first_day_of_week SMALLINT NOT NULL,
+    mood_scale SMALLINT NOT NULL,
+    reminder_times VARCHAR(64) NOT NULL DEFAULT '',
+    analysis_opt_in BOOLEAN NOT NULL DEFAULT FALSE,
+    theme VARCHAR(16) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
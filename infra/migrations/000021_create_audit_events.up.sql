@@ -0,0 +1,26 @@
+-- セキュリティに関わる操作の監査ログ（追記のみ。アプリからは更新・削除しない）
+CREATE TABLE IF NOT EXISTS audit_events (
+    id uuid PRIMARY KEY,
+    actor_user_id VARCHAR(36),
+    action VARCHAR(64) NOT NULL,
+    target_user_id VARCHAR(36),
+    detail VARCHAR(255),
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(512),
+    request_id VARCHAR(128),
+    created_at timestamp with time zone DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_user_id ON audit_events (actor_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target_user_id ON audit_events (target_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+
+-- 追記のみとするため、更新・削除を拒否する
+CREATE OR REPLACE FUNCTION reject_audit_event_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
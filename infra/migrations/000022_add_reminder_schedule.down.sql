@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS push_subscriptions;
+DROP INDEX IF EXISTS idx_user_preferences_remindable;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS webhook_url;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS notification_channels;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS reminder_weekdays;
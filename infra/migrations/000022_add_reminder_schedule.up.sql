@@ -0,0 +1,17 @@
+-- リマインダーの曜日と通知チャネル（保存済みの設定はWeb Pushのみ・毎日として扱う）
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS reminder_weekdays VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS notification_channels VARCHAR(64) NOT NULL DEFAULT 'web_push';
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS webhook_url VARCHAR(2048) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_user_preferences_remindable ON user_preferences (user_id) WHERE reminder_times <> '';
+
+-- ブラウザ（端末）ごとのWeb Pushの購読
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    endpoint VARCHAR(2048) NOT NULL,
+    p256dh VARCHAR(128) NOT NULL,
+    auth VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_push_subscriptions_endpoint ON push_subscriptions (endpoint);
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user_id ON push_subscriptions (user_id);
@@ -0,0 +1 @@
+ALTER TABLE push_subscriptions DROP COLUMN IF EXISTS user_agent;
@@ -0,0 +1,2 @@
+-- 端末の一覧に表示するため、購読を登録したブラウザのUser-Agentを保存する
+ALTER TABLE push_subscriptions ADD COLUMN IF NOT EXISTS user_agent VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS webhook_url VARCHAR(2048) NOT NULL DEFAULT '';
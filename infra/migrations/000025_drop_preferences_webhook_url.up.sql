@@ -0,0 +1,2 @@
+-- Webhookへの通知は登録したWebhook（webhook_endpoints）に署名して送るため、設定の送信先は使わない
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS webhook_url;
@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"context"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+)
+
+// EmailSender は確認済みのメールアドレスに通知をメールで送るSender
+type EmailSender struct {
+	users  user.Repository
+	mailer notification.Mailer
+}
+
+func NewEmailSender(users user.Repository, mailer notification.Mailer) notification.Sender {
+	return &EmailSender{users: users, mailer: mailer}
+}
+
+func (s *EmailSender) Channel() notification.Channel {
+	return notification.ChannelEmail
+}
+
+func (s *EmailSender) Send(ctx context.Context, msg notification.Message) error {
+	recipient, err := s.users.FindByID(ctx, msg.UserID)
+	if err != nil {
+		return err
+	}
+	// 確認していないメールアドレスは本人のものとは限らないため送らない
+	if recipient == nil || recipient.Email == "" || !recipient.IsEmailVerified() {
+		return notification.ErrNoRecipient
+	}
+	return s.mailer.Send(ctx, notification.Mail{
+		To:      recipient.Email,
+		Subject: msg.Title,
+		Body:    msg.Body,
+	})
+}
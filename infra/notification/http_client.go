@@ -0,0 +1,55 @@
+package notification
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errNonPublicAddress はユーザーが指定したURLの接続先がインターネット上のアドレスでないことを表す
+var errNonPublicAddress = errors.New("接続先がインターネット上のアドレスではありません")
+
+// NewPublicHTTPClient はインターネット上のアドレスにのみ接続するHTTPクライアントを返す。
+// ユーザーが指定したURL（Webhook等）に送る際に、内部のネットワーク・メタデータサービスへ接続されないようにする
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// 名前解決の後、実際に接続するアドレスで判定する（DNSの応答を差し替えられても内部に接続しない）
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return errNonPublicAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// リダイレクト先で内部のアドレスに誘導されないよう、リダイレクトは追わない
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	// 100.64.0.0/10（キャリアグレードNAT）もインターネット上のアドレスではない
+	if v4 := ip.To4(); v4 != nil && v4[0] == 100 && v4[1]&0xc0 == 64 {
+		return false
+	}
+	return true
+}
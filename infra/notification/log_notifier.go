@@ -0,0 +1,20 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"tofunote-backend/domain/notification"
+)
+
+// LogNotifier は通知をログに出力するだけのNotifier（ローカル開発や配送チャネル未設定時に使う）
+type LogNotifier struct{}
+
+func NewLogNotifier() notification.Notifier {
+	return &LogNotifier{}
+}
+
+// Notify は宛先と種類のみ出力する（服薬リマインダーのタイトル・本文には薬の名前や用量が含まれるため出力しない）
+func (n *LogNotifier) Notify(ctx context.Context, msg notification.Message) error {
+	log.Printf("[INFO] Notification: user_id=%s kind=%s", msg.UserID, msg.Kind)
+	return nil
+}
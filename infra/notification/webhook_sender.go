@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"context"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/webhook"
+)
+
+// WebhookPublisher はイベントを購読しているWebhookの配送キューに追加する（usecases.IWebhookPublisher）
+type WebhookPublisher interface {
+	Publish(ctx context.Context, userID string, event webhook.EventType, data interface{}) error
+}
+
+// WebhookSender はユーザーが登録したWebhookのうち通知のイベントを購読しているものに通知を送るSender。
+// 送信は他のイベントと同じく署名して配送キューから行い、失敗した場合は再送する
+type WebhookSender struct {
+	endpoints webhook.EndpointRepository
+	publisher WebhookPublisher
+}
+
+func NewWebhookSender(endpoints webhook.EndpointRepository, publisher WebhookPublisher) notification.Sender {
+	return &WebhookSender{endpoints: endpoints, publisher: publisher}
+}
+
+// WebhookNotification は通知のイベントの data に入れるJSON
+type WebhookNotification struct {
+	Kind  string            `json:"kind"`
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+func (s *WebhookSender) Channel() notification.Channel {
+	return notification.ChannelWebhook
+}
+
+// Send は通知のイベントを購読しているWebhookがない場合にErrNoRecipientを返す
+func (s *WebhookSender) Send(ctx context.Context, msg notification.Message) error {
+	endpoints, err := s.endpoints.FindByUserID(ctx, msg.UserID)
+	if err != nil {
+		return err
+	}
+	subscribed := false
+	for _, e := range endpoints {
+		if e.Subscribes(webhook.EventNotification) {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		return notification.ErrNoRecipient
+	}
+	return s.publisher.Publish(ctx, msg.UserID, webhook.EventNotification, WebhookNotification{
+		Kind:  string(msg.Kind),
+		Title: msg.Title,
+		Body:  msg.Body,
+		Data:  msg.Data,
+	})
+}
@@ -0,0 +1,88 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubEndpointRepo struct {
+	endpoints []webhook.Endpoint
+}
+
+func (s *stubEndpointRepo) Create(ctx context.Context, endpoint *webhook.Endpoint) error {
+	return nil
+}
+
+func (s *stubEndpointRepo) FindByUserID(ctx context.Context, userID string) ([]webhook.Endpoint, error) {
+	return s.endpoints, nil
+}
+
+func (s *stubEndpointRepo) FindByID(ctx context.Context, userID, id string) (*webhook.Endpoint, error) {
+	return nil, webhook.ErrEndpointNotFound
+}
+
+func (s *stubEndpointRepo) Delete(ctx context.Context, userID, id string) error {
+	return nil
+}
+
+func (s *stubEndpointRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	return nil
+}
+
+type publishedEvent struct {
+	userID string
+	event  webhook.EventType
+	data   interface{}
+}
+
+type recordingPublisher struct {
+	published []publishedEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, userID string, event webhook.EventType, data interface{}) error {
+	p.published = append(p.published, publishedEvent{userID: userID, event: event, data: data})
+	return nil
+}
+
+func TestWebhookSender_Send(t *testing.T) {
+	endpoints := &stubEndpointRepo{endpoints: []webhook.Endpoint{
+		{ID: "ep1", UserID: "u1", Events: []webhook.EventType{webhook.EventNotification}},
+	}}
+	publisher := &recordingPublisher{}
+	sender := NewWebhookSender(endpoints, publisher)
+
+	err := sender.Send(context.Background(), notification.Message{
+		UserID: "u1",
+		Kind:   notification.KindDiaryReminder,
+		Title:  "今日の日記を書きましょう",
+		Data:   map[string]string{"date": "2025-05-01"},
+	})
+	require.NoError(t, err)
+	// 署名と再送はWebhookの配送キューで行う
+	require.Len(t, publisher.published, 1)
+	assert.Equal(t, "u1", publisher.published[0].userID)
+	assert.Equal(t, webhook.EventNotification, publisher.published[0].event)
+	assert.Equal(t, WebhookNotification{
+		Kind:  "diary_reminder",
+		Title: "今日の日記を書きましょう",
+		Data:  map[string]string{"date": "2025-05-01"},
+	}, publisher.published[0].data)
+}
+
+func TestWebhookSender_NoSubscribedEndpoint(t *testing.T) {
+	// 通知のイベントを購読していないWebhookには送らない
+	endpoints := &stubEndpointRepo{endpoints: []webhook.Endpoint{
+		{ID: "ep1", UserID: "u1", Events: []webhook.EventType{webhook.EventDiaryCreated}},
+	}}
+	publisher := &recordingPublisher{}
+	sender := NewWebhookSender(endpoints, publisher)
+
+	err := sender.Send(context.Background(), notification.Message{UserID: "u1"})
+	assert.ErrorIs(t, err, notification.ErrNoRecipient)
+	assert.Empty(t, publisher.published)
+}
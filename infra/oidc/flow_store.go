@@ -0,0 +1,69 @@
+package oidc
+
+import (
+	"time"
+	"tofunote-backend/domain/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultFlowTTL は認可リクエストを開始してからコールバックまでの有効期限
+const DefaultFlowTTL = 10 * time.Minute
+
+type flowClaims struct {
+	jwt.RegisteredClaims
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// SignedFlowStore はFlowStateを署名付きトークンとしてクライアントに預ける（Lambdaでもサーバー側の保存領域が不要）
+type SignedFlowStore struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewSignedFlowStore(secret []byte, ttl time.Duration) auth.FlowStore {
+	return &SignedFlowStore{secret: secret, ttl: ttl, now: time.Now}
+}
+
+func (s *SignedFlowStore) Issue(state auth.FlowState) (string, error) {
+	now := s.now()
+	claims := flowClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+		Provider:     state.Provider,
+		State:        state.State,
+		Nonce:        state.Nonce,
+		CodeVerifier: state.CodeVerifier,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+func (s *SignedFlowStore) Verify(token string) (*auth.FlowState, error) {
+	claims := &flowClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if _, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}); err != nil {
+		return nil, auth.ErrInvalidFlow
+	}
+	if claims.State == "" || claims.CodeVerifier == "" {
+		return nil, auth.ErrInvalidFlow
+	}
+	return &auth.FlowState{
+		Provider:     claims.Provider,
+		State:        claims.State,
+		Nonce:        claims.Nonce,
+		CodeVerifier: claims.CodeVerifier,
+		ExpiresAt:    claims.ExpiresAt.Time,
+	}, nil
+}
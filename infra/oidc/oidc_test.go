@@ -0,0 +1,328 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOIDCServer はDiscovery・JWKS・トークンエンドポイントを持つローカルのOIDCプロバイダ
+type mockOIDCServer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu          sync.Mutex
+	signer      interface{}
+	method      jwt.SigningMethod
+	kid         string
+	publicJWK   map[string]string
+	codes       map[string]authCode
+	jwksFetches int
+	// idTokenClaims はIDトークンのクレームを上書きするためのフック
+	idTokenClaims func(claims jwt.MapClaims)
+}
+
+type authCode struct {
+	challenge string
+	nonce     string
+	subject   string
+}
+
+func newMockOIDCServer(t *testing.T) *mockOIDCServer {
+	m := &mockOIDCServer{t: t, codes: map[string]authCode{}}
+	m.rotateRSAKey("key-1")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.jwksFetches++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{m.publicJWK}})
+	})
+	mux.HandleFunc("/token", m.handleToken)
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockOIDCServer) rotateRSAKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(m.t, err)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.signer, m.method, m.kid = key, jwt.SigningMethodRS256, kid
+	m.publicJWK = map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+		"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func (m *mockOIDCServer) useECKey(kid string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(m.t, err)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.signer, m.method, m.kid = key, jwt.SigningMethodES256, kid
+	m.publicJWK = map[string]string{
+		"kty": "EC", "kid": kid, "use": "sig", "crv": "P-256",
+		"x": base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y": base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// authorize はブラウザでの認可操作の代わりに、認可コードを直接発行する
+func (m *mockOIDCServer) authorize(authURL, subject string) (code, state string) {
+	u, err := url.Parse(authURL)
+	require.NoError(m.t, err)
+	q := u.Query()
+	assert.Equal(m.t, "S256", q.Get("code_challenge_method"))
+	code = "code-" + subject
+	m.mu.Lock()
+	m.codes[code] = authCode{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), subject: subject}
+	m.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func (m *mockOIDCServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.codes[r.PostForm.Get("code")]
+	if !ok || r.PostForm.Get("client_id") != "client-123" || r.PostForm.Get("client_secret") != "secret" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if auth.CodeChallengeS256(r.PostForm.Get("code_verifier")) != c.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+	delete(m.codes, r.PostForm.Get("code"))
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.URL,
+		"sub":            c.subject,
+		"aud":            "client-123",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          c.nonce,
+		"email":          c.subject + "@example.com",
+		"email_verified": "true",
+		"name":           "テストユーザー",
+	}
+	if m.idTokenClaims != nil {
+		m.idTokenClaims(claims)
+	}
+	token := jwt.NewWithClaims(m.method, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.signer)
+	require.NoError(m.t, err)
+	_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": signed})
+}
+
+func newTestProvider(m *mockOIDCServer) auth.OIDCProvider {
+	return NewProvider(Config{
+		Name:         "mock",
+		Issuer:       m.URL,
+		ClientID:     "client-123",
+		ClientSecret: "secret",
+		RedirectURL:  "https://app.example.com/callback",
+	}, m.Client())
+}
+
+func startFlow(t *testing.T, p auth.OIDCProvider) (verifier, nonce, authURL string) {
+	verifier, _ = auth.RandomToken(32)
+	nonce, _ = auth.RandomToken(16)
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", nonce, auth.CodeChallengeS256(verifier))
+	require.NoError(t, err)
+	return verifier, nonce, authURL
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	m := newMockOIDCServer(t)
+	p := newTestProvider(m)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "st", "nn", "challenge")
+	require.NoError(t, err)
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	assert.Equal(t, m.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "client-123", q.Get("client_id"))
+	assert.Equal(t, "https://app.example.com/callback", q.Get("redirect_uri"))
+	assert.Equal(t, "openid profile email", q.Get("scope"))
+	assert.Equal(t, "st", q.Get("state"))
+	assert.Equal(t, "nn", q.Get("nonce"))
+	assert.Equal(t, "challenge", q.Get("code_challenge"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(m *mockOIDCServer)
+		badVerifer bool
+		badNonce   bool
+		wantErr    error
+	}{
+		{name: "正常系: RS256"},
+		{name: "正常系: ES256", setup: func(m *mockOIDCServer) { m.useECKey("ec-1") }},
+		{name: "異常系: code_verifierが一致しない", badVerifer: true},
+		{name: "異常系: nonceが一致しない", badNonce: true, wantErr: auth.ErrInvalidIDToken},
+		{
+			name:    "異常系: audienceが異なる",
+			setup:   func(m *mockOIDCServer) { m.idTokenClaims = func(c jwt.MapClaims) { c["aud"] = "other-client" } },
+			wantErr: auth.ErrInvalidIDToken,
+		},
+		{
+			name: "異常系: issuerが異なる",
+			setup: func(m *mockOIDCServer) {
+				m.idTokenClaims = func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }
+			},
+			wantErr: auth.ErrInvalidIDToken,
+		},
+		{
+			name: "異常系: 期限切れ",
+			setup: func(m *mockOIDCServer) {
+				m.idTokenClaims = func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }
+			},
+			wantErr: auth.ErrInvalidIDToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDCServer(t)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+			p := newTestProvider(m)
+			verifier, nonce, authURL := startFlow(t, p)
+			code, state := m.authorize(authURL, "sub-1")
+			assert.Equal(t, "state-1", state)
+			if tt.badVerifer {
+				verifier = "wrong-verifier"
+			}
+			if tt.badNonce {
+				nonce = "other-nonce"
+			}
+
+			identity, err := p.Exchange(context.Background(), code, verifier, nonce)
+			if tt.badVerifer {
+				assert.Error(t, err)
+				return
+			}
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "err=%v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "mock", identity.Provider)
+			assert.Equal(t, "sub-1", identity.Subject)
+			assert.Equal(t, "sub-1@example.com", identity.Email)
+			assert.True(t, identity.EmailVerified)
+			assert.Equal(t, "テストユーザー", identity.Name)
+		})
+	}
+}
+
+func TestProvider_Exchange_KeyRotation(t *testing.T) {
+	m := newMockOIDCServer(t)
+	p := newTestProvider(m).(*Provider)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	verifier, nonce, authURL := startFlow(t, p)
+	code, _ := m.authorize(authURL, "sub-1")
+	_, err := p.Exchange(context.Background(), code, verifier, nonce)
+	require.NoError(t, err)
+
+	// 鍵がローテーションされても、未知のkidでJWKSを再取得して検証できる
+	m.rotateRSAKey("key-2")
+	now = now.Add(2 * jwksMinRefreshInterval)
+	verifier, nonce, authURL = startFlow(t, p)
+	code, _ = m.authorize(authURL, "sub-2")
+	identity, err := p.Exchange(context.Background(), code, verifier, nonce)
+	require.NoError(t, err)
+	assert.Equal(t, "sub-2", identity.Subject)
+	assert.Equal(t, 2, m.jwksFetches)
+
+	// 再取得の直後は未知のkidでもJWKSを取りに行かない
+	m.rotateRSAKey("key-3")
+	verifier, nonce, authURL = startFlow(t, p)
+	code, _ = m.authorize(authURL, "sub-3")
+	_, err = p.Exchange(context.Background(), code, verifier, nonce)
+	assert.ErrorIs(t, err, auth.ErrInvalidIDToken)
+	assert.Equal(t, 2, m.jwksFetches)
+}
+
+func TestSignedFlowStore(t *testing.T) {
+	store := NewSignedFlowStore([]byte("test-secret"), time.Minute).(*SignedFlowStore)
+	state := auth.FlowState{Provider: "google", State: "s", Nonce: "n", CodeVerifier: "v"}
+
+	token, err := store.Issue(state)
+	require.NoError(t, err)
+	got, err := store.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "google", got.Provider)
+	assert.Equal(t, "s", got.State)
+	assert.Equal(t, "n", got.Nonce)
+	assert.Equal(t, "v", got.CodeVerifier)
+
+	t.Run("異常系: 別の鍵で署名されたトークン", func(t *testing.T) {
+		other := NewSignedFlowStore([]byte("other-secret"), time.Minute)
+		forged, _ := other.Issue(state)
+		_, err := store.Verify(forged)
+		assert.ErrorIs(t, err, auth.ErrInvalidFlow)
+	})
+	t.Run("異常系: 期限切れ", func(t *testing.T) {
+		store.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		defer func() { store.now = time.Now }()
+		_, err := store.Verify(token)
+		assert.ErrorIs(t, err, auth.ErrInvalidFlow)
+	})
+}
+
+func TestAppleClientSecret(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	now := time.Now()
+
+	secret, err := appleClientSecret("com.example.web", "TEAM123", "KEY123", key, now)
+	require.NoError(t, err)
+
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(secret, claims, func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil })
+	require.NoError(t, err)
+	assert.Equal(t, "KEY123", token.Header["kid"])
+	assert.Equal(t, "ES256", token.Header["alg"])
+	assert.Equal(t, "TEAM123", claims.Issuer)
+	assert.Equal(t, "com.example.web", claims.Subject)
+	assert.Equal(t, jwt.ClaimStrings{AppleIssuer}, claims.Audience)
+}
@@ -0,0 +1,156 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/jwtkeys"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	GoogleIssuer = "https://accounts.google.com"
+	AppleIssuer  = "https://appleid.apple.com"
+	LINEIssuer   = "https://access.line.me"
+)
+
+// GoogleConfig はGoogleアカウントでのログイン設定
+func GoogleConfig(clientID, clientSecret, redirectURL string) Config {
+	return Config{
+		Name:            "google",
+		Issuer:          GoogleIssuer,
+		ClientID:        clientID,
+		ClientSecret:    clientSecret,
+		RedirectURL:     redirectURL,
+		Scopes:          []string{"openid", "profile", "email"},
+		AcceptedIssuers: []string{"accounts.google.com"},
+	}
+}
+
+// AppleConfig はSign in with Appleの設定（client_secretはTeam IDと秘密鍵から都度生成する）
+func AppleConfig(servicesID, teamID, keyID string, privateKey *ecdsa.PrivateKey, redirectURL string) Config {
+	return Config{
+		Name:        "apple",
+		Issuer:      AppleIssuer,
+		ClientID:    servicesID,
+		RedirectURL: redirectURL,
+		Scopes:      []string{"openid", "name", "email"},
+		// name/emailを要求する場合、Appleはform_postでしか認可レスポンスを返さない
+		ResponseMode: "form_post",
+		ClientSecretFunc: func() (string, error) {
+			return appleClientSecret(servicesID, teamID, keyID, privateKey, time.Now())
+		},
+	}
+}
+
+// LINEConfig はLINEログインの設定
+func LINEConfig(channelID, channelSecret, redirectURL string) Config {
+	return Config{
+		Name:         "line",
+		Issuer:       LINEIssuer,
+		ClientID:     channelID,
+		ClientSecret: channelSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "profile", "email"},
+	}
+}
+
+func appleClientSecret(servicesID, teamID, keyID string, privateKey *ecdsa.PrivateKey, now time.Time) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Issuer:    teamID,
+		Subject:   servicesID,
+		Audience:  jwt.ClaimStrings{AppleIssuer},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(privateKey)
+}
+
+func parseECPrivateKey(pemData string) (*ecdsa.PrivateKey, error) {
+	// 環境変数では改行を\nで表現することが多いので復元する
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(pemData, `\n`, "\n")))
+	if block == nil {
+		return nil, errors.New("秘密鍵のPEM形式が不正です")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("秘密鍵がECDSA鍵ではありません")
+	}
+	return ecKey, nil
+}
+
+// ConfigsFromEnv は環境変数から設定済みのプロバイダ設定を読み込む（CLIENT_IDが未設定のプロバイダは無効）
+//
+//	OIDC_GOOGLE_CLIENT_ID / OIDC_GOOGLE_CLIENT_SECRET / OIDC_GOOGLE_REDIRECT_URL
+//	OIDC_APPLE_CLIENT_ID / OIDC_APPLE_TEAM_ID / OIDC_APPLE_KEY_ID / OIDC_APPLE_PRIVATE_KEY / OIDC_APPLE_REDIRECT_URL
+//	OIDC_LINE_CLIENT_ID / OIDC_LINE_CLIENT_SECRET / OIDC_LINE_REDIRECT_URL
+//	OIDC_GENERIC_NAME / OIDC_GENERIC_ISSUER / OIDC_GENERIC_CLIENT_ID / OIDC_GENERIC_CLIENT_SECRET / OIDC_GENERIC_REDIRECT_URL / OIDC_GENERIC_SCOPES
+func ConfigsFromEnv() ([]Config, error) {
+	var configs []Config
+	if id := os.Getenv("OIDC_GOOGLE_CLIENT_ID"); id != "" {
+		configs = append(configs, GoogleConfig(id, os.Getenv("OIDC_GOOGLE_CLIENT_SECRET"), os.Getenv("OIDC_GOOGLE_REDIRECT_URL")))
+	}
+	if id := os.Getenv("OIDC_APPLE_CLIENT_ID"); id != "" {
+		key, err := parseECPrivateKey(os.Getenv("OIDC_APPLE_PRIVATE_KEY"))
+		if err != nil {
+			return nil, fmt.Errorf("OIDC_APPLE_PRIVATE_KEYの読み込みに失敗しました: %w", err)
+		}
+		configs = append(configs, AppleConfig(id, os.Getenv("OIDC_APPLE_TEAM_ID"), os.Getenv("OIDC_APPLE_KEY_ID"), key, os.Getenv("OIDC_APPLE_REDIRECT_URL")))
+	}
+	if id := os.Getenv("OIDC_LINE_CLIENT_ID"); id != "" {
+		configs = append(configs, LINEConfig(id, os.Getenv("OIDC_LINE_CLIENT_SECRET"), os.Getenv("OIDC_LINE_REDIRECT_URL")))
+	}
+	if id := os.Getenv("OIDC_GENERIC_CLIENT_ID"); id != "" {
+		issuer := os.Getenv("OIDC_GENERIC_ISSUER")
+		if issuer == "" {
+			return nil, errors.New("OIDC_GENERIC_ISSUERが設定されていません")
+		}
+		name := os.Getenv("OIDC_GENERIC_NAME")
+		if name == "" {
+			name = "oidc"
+		}
+		configs = append(configs, Config{
+			Name:         name,
+			Issuer:       issuer,
+			ClientID:     id,
+			ClientSecret: os.Getenv("OIDC_GENERIC_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("OIDC_GENERIC_REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv("OIDC_GENERIC_SCOPES")),
+		})
+	}
+	return configs, nil
+}
+
+// ProvidersFromEnv は環境変数の設定からプロバイダを生成する
+func ProvidersFromEnv() ([]auth.OIDCProvider, error) {
+	configs, err := ConfigsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	providers := make([]auth.OIDCProvider, 0, len(configs))
+	for _, c := range configs {
+		providers = append(providers, NewProvider(c, client))
+	}
+	return providers, nil
+}
+
+// FlowSecretFromEnv は認可フロー用トークンの署名鍵を返す（本番環境では未設定・既定値・弱い値の場合にエラー）。
+// JWT_SECRETを廃止できるよう、アクセストークンの鍵とは共有しない
+func FlowSecretFromEnv() ([]byte, error) {
+	return jwtkeys.SecretFromEnv("OIDC_STATE_SECRET")
+}
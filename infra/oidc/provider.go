@@ -0,0 +1,236 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"tofunote-backend/domain/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDトークンの時刻検証で許容する時計のずれ
+const clockSkew = 2 * time.Minute
+
+// Config はOIDCプロバイダの接続設定
+type Config struct {
+	// Name はAPIのパスやusers.providerに使う識別子（google, apple, line等）
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// ClientSecretFunc が設定されている場合はClientSecretの代わりに毎回生成する（AppleのJWT形式のclient_secret等）
+	ClientSecretFunc func() (string, error)
+	RedirectURL      string
+	Scopes           []string
+	// ResponseMode は認可レスポンスの返し方（Appleでname/emailを要求する場合はform_post）
+	ResponseMode string
+	// AcceptedIssuers はIssuer以外に許容するissの値（Googleのaccounts.google.com等）
+	AcceptedIssuers []string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider はDiscoveryとJWKSを使って認可コードフローを実行するauth.OIDCProviderの実装
+type Provider struct {
+	config     Config
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *keySet
+}
+
+func NewProvider(config Config, httpClient *http.Client) auth.OIDCProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	return &Provider{config: config, httpClient: httpClient, now: time.Now}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL はPKCE(S256)とnonceを含む認可リクエストのURLを組み立てる
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, _, err := p.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("認可エンドポイントが不正です: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("scope", strings.Join(p.config.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	if p.config.ResponseMode != "" {
+		q.Set("response_mode", p.config.ResponseMode)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange は認可コードとcode_verifierをトークンエンドポイントに送り、返ってきたIDトークンを検証する
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*auth.Identity, error) {
+	doc, keys, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	secret := p.config.ClientSecret
+	if p.config.ClientSecretFunc != nil {
+		if secret, err = p.config.ClientSecretFunc(); err != nil {
+			return nil, fmt.Errorf("client_secretの生成に失敗しました: %w", err)
+		}
+	}
+	if secret != "" {
+		form.Set("client_secret", secret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("トークンエンドポイントへのリクエストに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("トークンレスポンスの形式が不正です: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("認可コードの交換に失敗しました: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, errors.New("トークンレスポンスにid_tokenがありません")
+	}
+	return p.verifyIDToken(ctx, keys, doc.Issuer, body.IDToken, nonce)
+}
+
+// idTokenClaims はIDトークンのクレーム（Appleはemail_verifiedを文字列で返すため両方受け付ける）
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string      `json:"nonce"`
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"`
+	Name          string      `json:"name"`
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, keys *keySet, issuer, rawToken, nonce string) (*auth.Identity, error) {
+	claims := &idTokenClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.now),
+	)
+	_, err := parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidIDToken, err)
+	}
+	if !p.acceptsIssuer(issuer, claims.Issuer) {
+		return nil, fmt.Errorf("%w: issが一致しません", auth.ErrInvalidIDToken)
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonceが一致しません", auth.ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: subがありません", auth.ErrInvalidIDToken)
+	}
+	return &auth.Identity{
+		Provider:      p.config.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+		Name:          claims.Name,
+	}, nil
+}
+
+func (p *Provider) acceptsIssuer(discovered, iss string) bool {
+	if iss == discovered {
+		return true
+	}
+	for _, accepted := range p.config.AcceptedIssuers {
+		if iss == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// load はDiscoveryドキュメントを取得してキャッシュする（失敗した場合は次回呼び出し時に再試行する）
+func (p *Provider) load(ctx context.Context) (*discoveryDocument, *keySet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, p.keys, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("OIDC Discoveryの取得に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("OIDC Discoveryの取得に失敗しました: status=%d", resp.StatusCode)
+	}
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("OIDC Discoveryの形式が不正です: %w", err)
+	}
+	if doc.Issuer != strings.TrimSuffix(p.config.Issuer, "/") && doc.Issuer != p.config.Issuer {
+		return nil, nil, fmt.Errorf("OIDC Discoveryのissuerが設定と一致しません: %s", doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, nil, errors.New("OIDC Discoveryに必要なエンドポイントがありません")
+	}
+	p.discovery = &doc
+	p.keys = newKeySet(doc.JWKSURI, p.httpClient, p.now)
+	return p.discovery, p.keys, nil
+}
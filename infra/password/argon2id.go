@@ -0,0 +1,122 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"tofunote-backend/domain/auth"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Params はargon2idのパラメータ
+type Params struct {
+	// Memory は使用するメモリ量（KiB）
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams はOWASPの推奨値（m=64MiB, t=3, p=2）を基準にした既定値
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// ParamsFromEnv は環境変数（ARGON2_MEMORY_KIB / ARGON2_ITERATIONS / ARGON2_PARALLELISM）で既定値を上書きする
+func ParamsFromEnv() (Params, error) {
+	p := DefaultParams
+	if v := os.Getenv("ARGON2_MEMORY_KIB"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n < 8*1024 {
+			return p, fmt.Errorf("ARGON2_MEMORY_KIBは8192以上の整数で指定してください: %s", v)
+		}
+		p.Memory = uint32(n)
+	}
+	if v := os.Getenv("ARGON2_ITERATIONS"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("ARGON2_ITERATIONSは1以上の整数で指定してください: %s", v)
+		}
+		p.Iterations = uint32(n)
+	}
+	if v := os.Getenv("ARGON2_PARALLELISM"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 8)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("ARGON2_PARALLELISMは1〜255の整数で指定してください: %s", v)
+		}
+		p.Parallelism = uint8(n)
+	}
+	return p, nil
+}
+
+// Argon2idHasher はargon2idでパスワードをハッシュ化し、PHC文字列形式
+// （$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>）で保存する
+type Argon2idHasher struct {
+	params Params
+}
+
+func NewArgon2idHasher(params Params) auth.PasswordHasher {
+	return &Argon2idHasher{params: params}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, bool, error) {
+	p, salt, key, err := decode(encoded)
+	if err != nil {
+		return false, false, err
+	}
+	actual := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, false, nil
+	}
+	needsRehash := p.Memory != h.params.Memory || p.Iterations != h.params.Iterations ||
+		p.Parallelism != h.params.Parallelism || uint32(len(key)) != h.params.KeyLength
+	return true, needsRehash, nil
+}
+
+func decode(encoded string) (Params, []byte, []byte, error) {
+	var p Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errors.New("パスワードハッシュの形式が不正です")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errors.New("未対応のargon2のバージョンです")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, errors.New("パスワードハッシュのパラメータが不正です")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errors.New("パスワードハッシュのソルトが不正です")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, errors.New("パスワードハッシュの値が不正です")
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
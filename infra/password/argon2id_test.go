@@ -0,0 +1,70 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// テストでは計算量を抑えたパラメータを使う
+var testParams = Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := NewArgon2idHasher(testParams)
+
+	encoded, err := hasher.Hash("correct horse battery staple")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=8192,t=1,p=1$"))
+
+	other, err := hasher.Hash("correct horse battery staple")
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, other, "ソルトが毎回異なる")
+
+	ok, needsRehash, err := hasher.Verify("correct horse battery staple", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+
+	ok, _, err = hasher.Verify("wrong password", encoded)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// パラメータを強化した後は再ハッシュが必要と判定される
+	stronger := NewArgon2idHasher(Params{Memory: 16 * 1024, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	ok, needsRehash, err = stronger.Verify("correct horse battery staple", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+}
+
+func TestArgon2idHasher_InvalidHash(t *testing.T) {
+	hasher := NewArgon2idHasher(testParams)
+	for _, encoded := range []string{
+		"",
+		"plaintext",
+		"$bcrypt$v=19$m=8192,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=16$m=8192,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=8192,t=1,p=1$!!$aGFzaA",
+	} {
+		_, _, err := hasher.Verify("password", encoded)
+		assert.Error(t, err, encoded)
+	}
+}
+
+func TestParamsFromEnv(t *testing.T) {
+	t.Setenv("ARGON2_MEMORY_KIB", "131072")
+	t.Setenv("ARGON2_ITERATIONS", "4")
+	t.Setenv("ARGON2_PARALLELISM", "1")
+	p, err := ParamsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(131072), p.Memory)
+	assert.Equal(t, uint32(4), p.Iterations)
+	assert.Equal(t, uint8(1), p.Parallelism)
+
+	t.Setenv("ARGON2_MEMORY_KIB", "1024")
+	_, err = ParamsFromEnv()
+	assert.Error(t, err)
+}
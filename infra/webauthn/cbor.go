@@ -0,0 +1,142 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// maxCBORDepth は入れ子の深さの上限（認証器のデータは浅い構造のみ）
+const maxCBORDepth = 8
+
+var errInvalidCBOR = errors.New("CBORの形式が不正です")
+
+// decodeCBOR はWebAuthnで使われるCBOR（RFC 8949）の値を1つ読み取り、読み取ったバイト数を返す。
+// 整数はint64、バイト列は[]byte、文字列はstring、配列は[]interface{}、マップはmap[interface{}]interface{}になる。
+// 認証器のデータは長さ不定の値を使わないため、長さ不定の値は不正として扱う
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	return decodeCBORValue(data, 0)
+}
+
+func decodeCBORValue(data []byte, depth int) (interface{}, int, error) {
+	if depth > maxCBORDepth || len(data) == 0 {
+		return nil, 0, errInvalidCBOR
+	}
+	major := data[0] >> 5
+	arg, n, err := decodeCBORArgument(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, 0, errInvalidCBOR
+		}
+		return int64(arg), n, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, 0, errInvalidCBOR
+		}
+		return -1 - int64(arg), n, nil
+	case 2, 3:
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errInvalidCBOR
+		}
+		end := n + int(arg)
+		if major == 3 {
+			return string(data[n:end]), end, nil
+		}
+		return append([]byte(nil), data[n:end]...), end, nil
+	case 4:
+		// 要素は最低1バイトのため、残りのバイト数より多い要素数は不正
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errInvalidCBOR
+		}
+		items := make([]interface{}, 0, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			item, m, err := decodeCBORValue(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			n += m
+		}
+		return items, n, nil
+	case 5:
+		if arg > uint64(len(data)-n)/2 {
+			return nil, 0, errInvalidCBOR
+		}
+		entries := make(map[interface{}]interface{}, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			key, m, err := decodeCBORValue(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += m
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, 0, errInvalidCBOR
+			}
+			value, m, err := decodeCBORValue(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += m
+			entries[key] = value
+		}
+		return entries, n, nil
+	case 6:
+		// タグは無視して中身の値を返す
+		value, m, err := decodeCBORValue(data[n:], depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return value, n + m, nil
+	default:
+		switch data[0] & 0x1f {
+		case 20:
+			return false, n, nil
+		case 21:
+			return true, n, nil
+		case 22, 23:
+			return nil, n, nil
+		case 26:
+			return float64(math.Float32frombits(uint32(arg))), n, nil
+		case 27:
+			return math.Float64frombits(arg), n, nil
+		}
+		return nil, 0, errInvalidCBOR
+	}
+}
+
+// decodeCBORArgument は先頭バイトに続く引数（長さや整数値）を読み取る
+func decodeCBORArgument(data []byte) (uint64, int, error) {
+	info := data[0] & 0x1f
+	switch {
+	case info < 24:
+		return uint64(info), 1, nil
+	case info == 24:
+		if len(data) < 2 {
+			return 0, 0, errInvalidCBOR
+		}
+		return uint64(data[1]), 2, nil
+	case info == 25:
+		if len(data) < 3 {
+			return 0, 0, errInvalidCBOR
+		}
+		return uint64(binary.BigEndian.Uint16(data[1:3])), 3, nil
+	case info == 26:
+		if len(data) < 5 {
+			return 0, 0, errInvalidCBOR
+		}
+		return uint64(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	case info == 27:
+		if len(data) < 9 {
+			return 0, 0, errInvalidCBOR
+		}
+		return binary.BigEndian.Uint64(data[1:9]), 9, nil
+	default:
+		return 0, 0, errInvalidCBOR
+	}
+}
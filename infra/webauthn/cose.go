@@ -0,0 +1,103 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"tofunote-backend/domain/auth"
+)
+
+// COSE_Keyのパラメータ（RFC 9052・RFC 9053）
+const (
+	coseKeyType    = 1
+	coseKeyAlg     = 3
+	coseKeyCrv     = -1
+	coseKeyX       = -2
+	coseKeyY       = -3
+	coseKeyRSAN    = -1
+	coseKeyRSAE    = -2
+	coseKtyOKP     = 1
+	coseKtyEC2     = 2
+	coseKtyRSA     = 3
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// parseCOSEKey はCOSE_Key形式の公開鍵を読み取り、対応しているアルゴリズムの公開鍵を返す
+func parseCOSEKey(data []byte) (crypto.PublicKey, int, error) {
+	value, _, err := decodeCBOR(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	key, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, errors.New("COSE_Keyがマップではありません")
+	}
+	kty, _ := key[int64(coseKeyType)].(int64)
+	alg, _ := key[int64(coseKeyAlg)].(int64)
+	switch {
+	case kty == coseKtyEC2 && alg == auth.COSEAlgES256:
+		crv, _ := key[int64(coseKeyCrv)].(int64)
+		x, _ := key[int64(coseKeyX)].([]byte)
+		y, _ := key[int64(coseKeyY)].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("P-256の公開鍵が不正です")
+		}
+		// 曲線上の点であることをecdhで確認してからecdsaの公開鍵にする
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, fmt.Errorf("P-256の公開鍵が不正です: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, auth.COSEAlgES256, nil
+	case kty == coseKtyOKP && alg == auth.COSEAlgEdDSA:
+		crv, _ := key[int64(coseKeyCrv)].(int64)
+		x, _ := key[int64(coseKeyX)].([]byte)
+		if crv != coseCrvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("Ed25519の公開鍵が不正です")
+		}
+		return ed25519.PublicKey(x), auth.COSEAlgEdDSA, nil
+	case kty == coseKtyRSA && alg == auth.COSEAlgRS256:
+		n, _ := key[int64(coseKeyRSAN)].([]byte)
+		e, _ := key[int64(coseKeyRSAE)].([]byte)
+		if len(e) == 0 || len(e) > 4 {
+			return nil, 0, errors.New("RSAの公開鍵が不正です")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if pub.N.BitLen() < 2048 || pub.E < 3 {
+			return nil, 0, errors.New("RSAの公開鍵が不正です")
+		}
+		return pub, auth.COSEAlgRS256, nil
+	default:
+		return nil, 0, fmt.Errorf("対応していない公開鍵の形式です（kty=%d, alg=%d）", kty, alg)
+	}
+}
+
+// verifySignature は認証器の署名を検証する
+func verifySignature(publicKey crypto.PublicKey, data, signature []byte) error {
+	digest := sha256.Sum256(data)
+	switch pub := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		// WebAuthnのES256の署名はASN.1 DER形式
+		if !ecdsa.VerifyASN1(pub, digest[:], signature) {
+			return auth.ErrInvalidPasskey
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, data, signature) {
+			return auth.ErrInvalidPasskey
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature); err != nil {
+			return auth.ErrInvalidPasskey
+		}
+	default:
+		return auth.ErrInvalidPasskey
+	}
+	return nil
+}
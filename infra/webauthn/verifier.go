@@ -0,0 +1,237 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"tofunote-backend/domain/auth"
+)
+
+// 認証器データのフラグ（WebAuthn Level 2 §6.1）
+const (
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagAttestedCredData = 0x40
+)
+
+// maxCredentialIDLength はクレデンシャルIDの最大長（WebAuthnの仕様上の上限）
+const maxCredentialIDLength = 1023
+
+// Config はWebAuthnの依存者（このサービス）の設定
+type Config struct {
+	RPID   string
+	RPName string
+	// Origins は登録・ログインを受け付けるフロントエンドのオリジン
+	Origins []string
+	// RequireUserVerification は生体認証・PINなどによる本人確認（UVフラグ）を必須にするかどうか
+	RequireUserVerification bool
+}
+
+// ConfigFromEnv は環境変数から設定を読み込む（WEBAUTHN_ORIGINSが未設定の場合はCORS_ORIGINのオリジンを使い、
+// WEBAUTHN_RP_IDが未設定の場合は最初のオリジンのホスト名をRP IDにする）
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		RPID:                    os.Getenv("WEBAUTHN_RP_ID"),
+		RPName:                  os.Getenv("WEBAUTHN_RP_NAME"),
+		RequireUserVerification: true,
+	}
+	if config.RPName == "" {
+		config.RPName = "Tofunote"
+	}
+	origins := os.Getenv("WEBAUTHN_ORIGINS")
+	if origins == "" {
+		origins = os.Getenv("CORS_ORIGIN")
+	}
+	for _, origin := range strings.Split(origins, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			config.Origins = append(config.Origins, origin)
+		}
+	}
+	if len(config.Origins) == 0 {
+		return config, errors.New("WEBAUTHN_ORIGINS または CORS_ORIGIN を設定してください")
+	}
+	if config.RPID == "" {
+		u, err := url.Parse(config.Origins[0])
+		if err != nil || u.Hostname() == "" {
+			return config, fmt.Errorf("オリジン %s からRP IDを決められません", config.Origins[0])
+		}
+		config.RPID = u.Hostname()
+	}
+	return config, nil
+}
+
+// Verifier はWebAuthnの登録・ログインのレスポンスを検証する。
+// アテステーション（認証器の製造元の証明）は要求しないため、登録時の証明書は評価しない
+type Verifier struct {
+	config   Config
+	rpIDHash [32]byte
+}
+
+func NewVerifier(config Config) *Verifier {
+	return &Verifier{config: config, rpIDHash: sha256.Sum256([]byte(config.RPID))}
+}
+
+func (v *Verifier) RelyingParty() auth.RelyingParty {
+	return auth.RelyingParty{ID: v.config.RPID, Name: v.config.RPName}
+}
+
+func (v *Verifier) VerifyRegistration(challenge []byte, attestation *auth.PasskeyAttestation) (*auth.PasskeyCredential, error) {
+	if err := v.verifyClientData(attestation.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+	value, _, err := decodeCBOR(attestation.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidPasskey, err)
+	}
+	object, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: attestationObjectの形式が不正です", auth.ErrInvalidPasskey)
+	}
+	rawAuthData, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: authDataがありません", auth.ErrInvalidPasskey)
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttestedCredData == 0 {
+		return nil, fmt.Errorf("%w: 認証器の公開鍵が含まれていません", auth.ErrInvalidPasskey)
+	}
+	_, alg, err := parseCOSEKey(authData.credentialPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidPasskey, err)
+	}
+	return &auth.PasskeyCredential{
+		CredentialID: authData.credentialID,
+		PublicKey:    authData.credentialPublicKey,
+		Algorithm:    alg,
+		SignCount:    authData.signCount,
+	}, nil
+}
+
+func (v *Verifier) VerifyAssertion(challenge []byte, passkey *auth.Passkey, assertion *auth.PasskeyAssertion) (uint32, error) {
+	if !bytes.Equal(passkey.CredentialID, assertion.CredentialID) {
+		return 0, auth.ErrInvalidPasskey
+	}
+	if len(assertion.UserHandle) > 0 && string(assertion.UserHandle) != passkey.UserID {
+		return 0, fmt.Errorf("%w: ユーザーハンドルが一致しません", auth.ErrInvalidPasskey)
+	}
+	if err := v.verifyClientData(assertion.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+	authData, err := parseAuthenticatorData(assertion.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	if err := v.verifyAuthenticatorData(authData); err != nil {
+		return 0, err
+	}
+	publicKey, _, err := parseCOSEKey(passkey.PublicKey)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", auth.ErrInvalidPasskey, err)
+	}
+	// 署名対象は認証器データとclientDataJSONのハッシュを連結したもの
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	signed := append(append([]byte(nil), assertion.AuthenticatorData...), clientDataHash[:]...)
+	if err := verifySignature(publicKey, signed, assertion.Signature); err != nil {
+		return 0, err
+	}
+	// 署名カウンタに対応した認証器では、前回より大きい値でなければ複製を疑う
+	if (authData.signCount != 0 || passkey.SignCount != 0) && authData.signCount <= passkey.SignCount {
+		return 0, auth.ErrPasskeySignCount
+	}
+	return authData.signCount, nil
+}
+
+type collectedClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func (v *Verifier) verifyClientData(raw []byte, ceremonyType string, challenge []byte) error {
+	var clientData collectedClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return fmt.Errorf("%w: clientDataJSONの形式が不正です", auth.ErrInvalidPasskey)
+	}
+	if clientData.Type != ceremonyType {
+		return fmt.Errorf("%w: clientDataJSONの種類が不正です", auth.ErrInvalidPasskey)
+	}
+	received, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(clientData.Challenge, "="))
+	if err != nil || subtle.ConstantTimeCompare(received, challenge) != 1 {
+		return fmt.Errorf("%w: チャレンジが一致しません", auth.ErrInvalidPasskey)
+	}
+	if !slices.Contains(v.config.Origins, clientData.Origin) || clientData.CrossOrigin {
+		return fmt.Errorf("%w: オリジン %s は許可されていません", auth.ErrInvalidPasskey, clientData.Origin)
+	}
+	return nil
+}
+
+func (v *Verifier) verifyAuthenticatorData(authData *authenticatorData) error {
+	if subtle.ConstantTimeCompare(authData.rpIDHash, v.rpIDHash[:]) != 1 {
+		return fmt.Errorf("%w: RP IDが一致しません", auth.ErrInvalidPasskey)
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return fmt.Errorf("%w: ユーザーの操作が確認できません", auth.ErrInvalidPasskey)
+	}
+	if v.config.RequireUserVerification && authData.flags&flagUserVerified == 0 {
+		return fmt.Errorf("%w: 本人確認（生体認証・PIN）が行われていません", auth.ErrInvalidPasskey)
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	rpIDHash            []byte
+	flags               byte
+	signCount           uint32
+	credentialID        []byte
+	credentialPublicKey []byte
+}
+
+// parseAuthenticatorData は認証器データ（RP IDのハッシュ・フラグ・署名カウンタ・登録時は公開鍵）を読み取る
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	invalid := fmt.Errorf("%w: 認証器データの形式が不正です", auth.ErrInvalidPasskey)
+	if len(data) < 37 {
+		return nil, invalid
+	}
+	authData := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if authData.flags&flagAttestedCredData == 0 {
+		return authData, nil
+	}
+	// AAGUID（16バイト）・クレデンシャルIDの長さ（2バイト）・クレデンシャルID・COSE_Keyの順に続く
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, invalid
+	}
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || idLength > maxCredentialIDLength || len(rest) < idLength {
+		return nil, invalid
+	}
+	authData.credentialID = append([]byte(nil), rest[:idLength]...)
+	rest = rest[idLength:]
+	_, n, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, invalid
+	}
+	authData.credentialPublicKey = append([]byte(nil), rest[:n]...)
+	return authData, nil
+}
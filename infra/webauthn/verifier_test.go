@@ -0,0 +1,159 @@
+package webauthn
+
+import (
+	"testing"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/webauthn/webauthntest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "tofunote.example.com"
+	testOrigin = "https://tofunote.example.com"
+)
+
+func newTestVerifier() *Verifier {
+	return NewVerifier(Config{RPID: testRPID, RPName: "Tofunote", Origins: []string{testOrigin}, RequireUserVerification: true})
+}
+
+// register はソフトウェア認証器で登録し、保存されるパスキーを返す
+func register(t *testing.T, v *Verifier, a *webauthntest.Authenticator) *auth.Passkey {
+	t.Helper()
+	challenge := []byte("registration-challenge-0123456789")
+	attestation, err := a.Register(challenge, []byte("user-1"))
+	require.NoError(t, err)
+	credential, err := v.VerifyRegistration(challenge, attestation)
+	require.NoError(t, err)
+	return &auth.Passkey{UserID: "user-1", CredentialID: credential.CredentialID, PublicKey: credential.PublicKey, Algorithm: credential.Algorithm, SignCount: credential.SignCount}
+}
+
+func TestVerifier_RegistrationAndAssertion(t *testing.T) {
+	v := newTestVerifier()
+	a, err := webauthntest.New(testRPID, testOrigin)
+	require.NoError(t, err)
+
+	passkey := register(t, v, a)
+	assert.Equal(t, a.CredentialID, passkey.CredentialID)
+	assert.Equal(t, a.COSEKey(), passkey.PublicKey)
+	assert.Equal(t, auth.COSEAlgES256, passkey.Algorithm)
+
+	challenge := []byte("login-challenge-0123456789abcdef")
+	assertion, err := a.Login(challenge)
+	require.NoError(t, err)
+	count, err := v.VerifyAssertion(challenge, passkey, assertion)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), count)
+}
+
+func TestVerifier_RegistrationRejected(t *testing.T) {
+	challenge := []byte("registration-challenge-0123456789")
+	tests := []struct {
+		name      string
+		setup     func(a *webauthntest.Authenticator)
+		challenge []byte
+		config    func(c *Config)
+	}{
+		{name: "チャレンジが異なる", challenge: []byte("other-challenge")},
+		{name: "許可されていないオリジン", setup: func(a *webauthntest.Authenticator) { a.Origin = "https://evil.example.com" }},
+		{name: "RP IDが異なる", setup: func(a *webauthntest.Authenticator) { a.RPID = "evil.example.com" }},
+		{name: "本人確認なし", setup: func(a *webauthntest.Authenticator) { a.SkipUserVerification = true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := webauthntest.New(testRPID, testOrigin)
+			require.NoError(t, err)
+			if tt.setup != nil {
+				tt.setup(a)
+			}
+			attestation, err := a.Register(challenge, []byte("user-1"))
+			require.NoError(t, err)
+			expected := challenge
+			if tt.challenge != nil {
+				expected = tt.challenge
+			}
+			_, err = newTestVerifier().VerifyRegistration(expected, attestation)
+			assert.ErrorIs(t, err, auth.ErrInvalidPasskey)
+		})
+	}
+}
+
+func TestVerifier_AssertionRejected(t *testing.T) {
+	challenge := []byte("login-challenge-0123456789abcdef")
+	tests := []struct {
+		name    string
+		modify  func(a *webauthntest.Authenticator, p *auth.Passkey, as *auth.PasskeyAssertion)
+		wantErr error
+	}{
+		{
+			name: "署名の改ざん",
+			modify: func(a *webauthntest.Authenticator, p *auth.Passkey, as *auth.PasskeyAssertion) {
+				as.Signature[len(as.Signature)-1] ^= 0xff
+			},
+			wantErr: auth.ErrInvalidPasskey,
+		},
+		{
+			name: "別の認証器の公開鍵",
+			modify: func(a *webauthntest.Authenticator, p *auth.Passkey, as *auth.PasskeyAssertion) {
+				other, _ := webauthntest.New(testRPID, testOrigin)
+				p.PublicKey = other.COSEKey()
+			},
+			wantErr: auth.ErrInvalidPasskey,
+		},
+		{
+			name: "ユーザーハンドルが異なる",
+			modify: func(a *webauthntest.Authenticator, p *auth.Passkey, as *auth.PasskeyAssertion) {
+				as.UserHandle = []byte("user-2")
+			},
+			wantErr: auth.ErrInvalidPasskey,
+		},
+		{
+			name:    "署名カウンタの巻き戻り",
+			modify:  func(a *webauthntest.Authenticator, p *auth.Passkey, as *auth.PasskeyAssertion) { p.SignCount = 5 },
+			wantErr: auth.ErrPasskeySignCount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier()
+			a, err := webauthntest.New(testRPID, testOrigin)
+			require.NoError(t, err)
+			passkey := register(t, v, a)
+			assertion, err := a.Login(challenge)
+			require.NoError(t, err)
+			tt.modify(a, passkey, assertion)
+			_, err = v.VerifyAssertion(challenge, passkey, assertion)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifier_CounterlessAuthenticator(t *testing.T) {
+	// 署名カウンタに対応していない認証器（常に0）は、保存済みの値も0であれば受け付ける
+	v := newTestVerifier()
+	a, err := webauthntest.New(testRPID, testOrigin)
+	require.NoError(t, err)
+	a.CounterStep = 0
+	passkey := register(t, v, a)
+	for i := 0; i < 2; i++ {
+		challenge := []byte("login-challenge-0123456789abcdef")
+		assertion, err := a.Login(challenge)
+		require.NoError(t, err)
+		count, err := v.VerifyAssertion(challenge, passkey, assertion)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(0), count)
+	}
+}
+
+func TestDecodeCBOR_Invalid(t *testing.T) {
+	for _, data := range [][]byte{
+		{},
+		{0x5a, 0xff, 0xff, 0xff, 0xff}, // 長さが残りのバイト数を超えるバイト列
+		{0x9f},                         // 長さ不定の配列
+		{0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, // 要素数が大きすぎるマップ
+	} {
+		_, _, err := decodeCBOR(data)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Package webauthntest はWebAuthnの登録・ログインをテストするためのソフトウェア認証器を提供する
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"tofunote-backend/domain/auth"
+)
+
+// Authenticator はES256の鍵をメモリ上に持つソフトウェア認証器
+type Authenticator struct {
+	RPID   string
+	Origin string
+	// CredentialID は登録時に生成したクレデンシャルID
+	CredentialID []byte
+	// UserHandle は登録時に依存者から渡されたユーザーハンドル
+	UserHandle []byte
+	// SignCount は認証のたびに増やす署名カウンタ（CounterStepが0の場合は常に0）
+	SignCount   uint32
+	CounterStep uint32
+	// SkipUserVerification をtrueにすると本人確認（UVフラグ）を行わない
+	SkipUserVerification bool
+	key                  *ecdsa.PrivateKey
+}
+
+// New は署名カウンタに対応したソフトウェア認証器を作成する
+func New(rpID, origin string) (*Authenticator, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		return nil, err
+	}
+	return &Authenticator{RPID: rpID, Origin: origin, CredentialID: credentialID, CounterStep: 1, key: key}, nil
+}
+
+// Register は登録のチャレンジに応答し、アテステーションなし（fmt=none）のレスポンスを返す
+func (a *Authenticator) Register(challenge, userHandle []byte) (*auth.PasskeyAttestation, error) {
+	a.UserHandle = userHandle
+	clientData, err := a.clientData("webauthn.create", challenge)
+	if err != nil {
+		return nil, err
+	}
+	authData := a.authenticatorData(0x40)
+	// AAGUIDは0埋め（アテステーションなしの場合と同じ）
+	authData = append(authData, make([]byte, 16)...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.CredentialID)))
+	authData = append(authData, a.CredentialID...)
+	authData = append(authData, a.COSEKey()...)
+	attestationObject := encodeMap(
+		encodeText("fmt"), encodeText("none"),
+		encodeText("attStmt"), encodeMap(),
+		encodeText("authData"), encodeBytes(authData),
+	)
+	return &auth.PasskeyAttestation{ClientDataJSON: clientData, AttestationObject: attestationObject, Transports: []string{"internal"}}, nil
+}
+
+// Login はログインのチャレンジに署名したレスポンスを返す
+func (a *Authenticator) Login(challenge []byte) (*auth.PasskeyAssertion, error) {
+	clientData, err := a.clientData("webauthn.get", challenge)
+	if err != nil {
+		return nil, err
+	}
+	a.SignCount += a.CounterStep
+	authData := a.authenticatorData(0)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	return &auth.PasskeyAssertion{
+		CredentialID:      a.CredentialID,
+		ClientDataJSON:    clientData,
+		AuthenticatorData: authData,
+		Signature:         signature,
+		UserHandle:        a.UserHandle,
+	}, nil
+}
+
+// COSEKey は公開鍵をCOSE_Key形式（EC2・P-256・ES256）で返す
+func (a *Authenticator) COSEKey() []byte {
+	x := a.key.PublicKey.X.FillBytes(make([]byte, 32))
+	y := a.key.PublicKey.Y.FillBytes(make([]byte, 32))
+	return encodeMap(
+		encodeInt(1), encodeInt(2),
+		encodeInt(3), encodeInt(auth.COSEAlgES256),
+		encodeInt(-1), encodeInt(1),
+		encodeInt(-2), encodeBytes(x),
+		encodeInt(-3), encodeBytes(y),
+	)
+}
+
+func (a *Authenticator) clientData(ceremonyType string, challenge []byte) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"type":        ceremonyType,
+		"challenge":   base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+}
+
+func (a *Authenticator) authenticatorData(extraFlags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.RPID))
+	flags := byte(0x01) | extraFlags
+	if !a.SkipUserVerification {
+		flags |= 0x04
+	}
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, a.SignCount)
+}
+
+func encodeHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+	}
+}
+
+func encodeInt(n int64) []byte {
+	if n < 0 {
+		return encodeHead(1, uint64(-1-n))
+	}
+	return encodeHead(0, uint64(n))
+}
+
+func encodeBytes(b []byte) []byte {
+	return append(encodeHead(2, uint64(len(b))), b...)
+}
+
+func encodeText(s string) []byte {
+	return append(encodeHead(3, uint64(len(s))), s...)
+}
+
+// encodeMap はエンコード済みのキーと値を交互に並べたものをマップにする
+func encodeMap(pairs ...[]byte) []byte {
+	out := encodeHead(5, uint64(len(pairs)/2))
+	for _, p := range pairs {
+		out = append(out, p...)
+	}
+	return out
+}
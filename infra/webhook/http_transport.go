@@ -0,0 +1,44 @@
+// Package webhook はユーザーが登録したWebhookの受信側にHTTPでイベントを送る
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+	"tofunote-backend/domain/webhook"
+	"tofunote-backend/infra/notification"
+)
+
+// timeout は受信側が応答するまで待つ時間
+const timeout = 10 * time.Second
+
+type HTTPTransport struct {
+	httpClient *http.Client
+}
+
+// NewHTTPTransport はhttpClientがnilの場合にインターネット上のアドレスにのみ接続するクライアントを使う
+func NewHTTPTransport(httpClient *http.Client) webhook.Transport {
+	if httpClient == nil {
+		httpClient = notification.NewPublicHTTPClient(timeout)
+	}
+	return &HTTPTransport{httpClient: httpClient}
+}
+
+func (t *HTTPTransport) Post(ctx context.Context, r webhook.Request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return 0, err
+	}
+	for key, value := range r.Headers {
+		req.Header.Set(key, value)
+	}
+	res, err := t.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	return res.StatusCode, nil
+}
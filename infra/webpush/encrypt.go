@@ -0,0 +1,95 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"tofunote-backend/domain/notification"
+)
+
+const (
+	// recordSize はaes128gcm（RFC 8188）のレコードのサイズ。ペイロードは1レコードに収める
+	recordSize = 4096
+	saltLength = 16
+	// maxPayloadLength はレコードに収まるペイロードの最大のバイト数（区切りの1バイトと認証タグの16バイトを除く）
+	maxPayloadLength = recordSize - 1 - 16
+)
+
+var errPayloadTooLarge = errors.New("Web Pushのペイロードが大きすぎます")
+
+// encrypt はペイロードを購読したブラウザの鍵でRFC 8291（aes128gcm）により暗号化し、リクエストのボディを返す
+func encrypt(plaintext []byte, subscription notification.PushSubscription) ([]byte, error) {
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWith(plaintext, subscription, serverKey, salt)
+}
+
+// encryptWith は送信ごとの鍵ペアとsaltを指定して暗号化する（テストでRFCの例と照合するため分けている）
+func encryptWith(plaintext []byte, subscription notification.PushSubscription, serverKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	if len(plaintext) > maxPayloadLength {
+		return nil, errPayloadTooLarge
+	}
+	rawUAPublic, err := notification.DecodePushKey(subscription.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("ブラウザの公開鍵の形式が不正です: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(rawUAPublic)
+	if err != nil {
+		return nil, fmt.Errorf("ブラウザの公開鍵の形式が不正です: %w", err)
+	}
+	authSecret, err := notification.DecodePushKey(subscription.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("ブラウザの認証用の秘密の形式が不正です: %w", err)
+	}
+	sharedSecret, err := serverKey.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := serverKey.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append([]byte("WebPush: info\x00"), rawUAPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, string(keyInfo), 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// ヘッダー: salt(16) || rs(4) || idlen(1) || keyid（送信ごとの公開鍵）
+	body := make([]byte, 0, saltLength+4+1+len(asPublic)+len(plaintext)+1+gcm.Overhead())
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, recordSize)
+	body = append(body, byte(len(asPublic)))
+	body = append(body, asPublic...)
+	// 最後のレコードであることを表す区切り（0x02）を付けて暗号化する
+	record := append(append([]byte{}, plaintext...), 0x02)
+	return gcm.Seal(body, nonce, record, nil), nil
+}
@@ -0,0 +1,124 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"tofunote-backend/domain/notification"
+	infranotification "tofunote-backend/infra/notification"
+)
+
+const (
+	// defaultTTL はプッシュサービスが端末に届けられるまで通知を保持する秒数
+	defaultTTL = 24 * time.Hour
+	// pushTimeout はプッシュサービスへの1回の送信のタイムアウト
+	pushTimeout = 10 * time.Second
+)
+
+// errSubscriptionGone はプッシュサービスが購読の失効（404・410）を返したことを表す
+var errSubscriptionGone = errors.New("Web Pushの購読が失効しています")
+
+// Payload はService Workerが受け取る通知の内容（RFC 8291で暗号化して送る）
+type Payload struct {
+	Kind  string            `json:"kind"`
+	Title string            `json:"title"`
+	Body  string            `json:"body,omitempty"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// Sender は購読しているすべてのブラウザにWeb Pushで通知を送るSender。
+// プッシュサービスが失効を返した購読は削除する
+type Sender struct {
+	keys          *Keys
+	subscriptions notification.PushSubscriptionRepository
+	httpClient    *http.Client
+	now           func() time.Time
+}
+
+func NewSender(keys *Keys, subscriptions notification.PushSubscriptionRepository, httpClient *http.Client) notification.Sender {
+	// 購読のエンドポイントはブラウザから登録されたURLのため、内部のネットワークには接続しない
+	if httpClient == nil {
+		httpClient = infranotification.NewPublicHTTPClient(pushTimeout)
+	}
+	return &Sender{keys: keys, subscriptions: subscriptions, httpClient: httpClient, now: time.Now}
+}
+
+func (s *Sender) Channel() notification.Channel {
+	return notification.ChannelWebPush
+}
+
+// Send はいずれかのブラウザに届けば成功とする。全ての購読が失効していた場合はErrNoRecipientを返す
+func (s *Sender) Send(ctx context.Context, msg notification.Message) error {
+	subscriptions, err := s.subscriptions.FindByUserID(ctx, msg.UserID)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(Payload{Kind: string(msg.Kind), Title: msg.Title, Body: msg.Body, Data: msg.Data})
+	if err != nil {
+		return err
+	}
+	delivered := false
+	var errs []error
+	for _, subscription := range subscriptions {
+		err := s.push(ctx, subscription, payload)
+		if errors.Is(err, errSubscriptionGone) {
+			if err := s.subscriptions.DeleteByEndpoint(ctx, subscription.Endpoint); err != nil {
+				log.Printf("[ERROR] WebPushSender: 失効した購読の削除に失敗しました subscription=%s: %v", subscription.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("[ERROR] WebPushSender: 送信に失敗しました subscription=%s: %v", subscription.ID, err)
+			errs = append(errs, err)
+			continue
+		}
+		delivered = true
+	}
+	if delivered {
+		return nil
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return notification.ErrNoRecipient
+}
+
+func (s *Sender) push(ctx context.Context, subscription notification.PushSubscription, payload []byte) error {
+	body, err := encrypt(payload, subscription)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.keys.authorization(subscription.Endpoint, s.now())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(defaultTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone:
+		return errSubscriptionGone
+	case res.StatusCode < 200 || res.StatusCode >= 300:
+		return fmt.Errorf("プッシュサービスがステータス%dを返しました", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"tofunote-backend/domain/notification"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySubscriptionRepo struct {
+	subscriptions []notification.PushSubscription
+}
+
+func (m *memorySubscriptionRepo) FindByUserID(ctx context.Context, userID string) ([]notification.PushSubscription, error) {
+	var found []notification.PushSubscription
+	for _, s := range m.subscriptions {
+		if s.UserID == userID {
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+func (m *memorySubscriptionRepo) Save(ctx context.Context, s *notification.PushSubscription) error {
+	m.subscriptions = append(m.subscriptions, *s)
+	return nil
+}
+
+func (m *memorySubscriptionRepo) Delete(ctx context.Context, userID, id string) error {
+	return nil
+}
+
+func (m *memorySubscriptionRepo) DeleteByEndpoint(ctx context.Context, endpoint string) error {
+	kept := m.subscriptions[:0]
+	for _, s := range m.subscriptions {
+		if s.Endpoint != endpoint {
+			kept = append(kept, s)
+		}
+	}
+	m.subscriptions = kept
+	return nil
+}
+
+func (m *memorySubscriptionRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	return nil
+}
+
+// testBrowser はプッシュを受け取るブラウザの鍵（購読のp256dh・auth）
+type testBrowser struct {
+	key    *ecdh.PrivateKey
+	secret []byte
+}
+
+func newTestBrowser(t *testing.T) *testBrowser {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	secret := make([]byte, 16)
+	_, err = rand.Read(secret)
+	require.NoError(t, err)
+	return &testBrowser{key: key, secret: secret}
+}
+
+func (b *testBrowser) subscription(id, userID, endpoint string) notification.PushSubscription {
+	return notification.PushSubscription{
+		ID:       id,
+		UserID:   userID,
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.secret),
+	}
+}
+
+// decrypt はブラウザと同じ手順でaes128gcmのボディを復号する
+func (b *testBrowser) decrypt(t *testing.T, body []byte) []byte {
+	require.Greater(t, len(body), 21)
+	salt := body[:16]
+	assert.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+	idlen := int(body[20])
+	asPublicRaw := body[21 : 21+idlen]
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	require.NoError(t, err)
+	sharedSecret, err := b.key.ECDH(asPublic)
+	require.NoError(t, err)
+
+	keyInfo := append([]byte("WebPush: info\x00"), b.key.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicRaw...)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, b.secret, string(keyInfo), 32)
+	require.NoError(t, err)
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	require.NoError(t, err)
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	record, err := gcm.Open(nil, nonce, body[21+idlen:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), record[len(record)-1])
+	return record[:len(record)-1]
+}
+
+func TestSender_SendsEncryptedVAPIDSignedPush(t *testing.T) {
+	keys, err := GenerateKeys("mailto:admin@example.com")
+	require.NoError(t, err)
+	browser := newTestBrowser(t)
+
+	var received *http.Request
+	var body []byte
+	pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer pushService.Close()
+
+	repo := &memorySubscriptionRepo{subscriptions: []notification.PushSubscription{
+		browser.subscription("s1", "u1", pushService.URL+"/push/abc"),
+	}}
+	sender := NewSender(keys, repo, pushService.Client())
+
+	err = sender.Send(context.Background(), notification.Message{
+		UserID: "u1",
+		Kind:   notification.KindDiaryReminder,
+		Title:  "今日の日記を書きましょう",
+		Data:   map[string]string{"date": "2025-05-01"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, received)
+	assert.Equal(t, "/push/abc", received.URL.Path)
+	assert.Equal(t, "86400", received.Header.Get("TTL"))
+	assert.Equal(t, "aes128gcm", received.Header.Get("Content-Encoding"))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(browser.decrypt(t, body), &payload))
+	assert.Equal(t, "diary_reminder", payload.Kind)
+	assert.Equal(t, "今日の日記を書きましょう", payload.Title)
+	assert.Equal(t, "2025-05-01", payload.Data["date"])
+
+	// Authorization: vapid t=<JWT>, k=<公開鍵> をプッシュサービスと同じ手順で検証する
+	authorization := received.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(authorization, "vapid t="))
+	params := strings.Split(strings.TrimPrefix(authorization, "vapid "), ", ")
+	require.Len(t, params, 2)
+	assert.Equal(t, "k="+keys.PublicKey(), params[1])
+	token, err := jwt.Parse(strings.TrimPrefix(params[0], "t="), func(token *jwt.Token) (interface{}, error) {
+		return &keys.privateKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(pushService.URL))
+	require.NoError(t, err)
+	claims := token.Claims.(jwt.MapClaims)
+	assert.Equal(t, "mailto:admin@example.com", claims["sub"])
+	exp, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(12*time.Hour), exp.Time, time.Minute)
+}
+
+func TestSender_NoSubscription(t *testing.T) {
+	keys, err := GenerateKeys("mailto:admin@example.com")
+	require.NoError(t, err)
+	sender := NewSender(keys, &memorySubscriptionRepo{}, nil)
+
+	err = sender.Send(context.Background(), notification.Message{UserID: "u1"})
+	assert.ErrorIs(t, err, notification.ErrNoRecipient)
+}
+
+func TestSender_RefusesNonPublicEndpoint(t *testing.T) {
+	keys, err := GenerateKeys("mailto:admin@example.com")
+	require.NoError(t, err)
+	browser := newTestBrowser(t)
+	called := false
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer internal.Close()
+
+	// 既定のクライアントはループバック等の内部のアドレスに登録された購読には送らない
+	repo := &memorySubscriptionRepo{subscriptions: []notification.PushSubscription{
+		browser.subscription("s1", "u1", internal.URL+"/push/abc"),
+	}}
+	sender := NewSender(keys, repo, nil)
+	err = sender.Send(context.Background(), notification.Message{UserID: "u1", Title: "今日の日記を書きましょう"})
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestSender_PrunesExpiredSubscriptions(t *testing.T) {
+	keys, err := GenerateKeys("mailto:admin@example.com")
+	require.NoError(t, err)
+	browser := newTestBrowser(t)
+	pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/not-found":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer pushService.Close()
+
+	repo := &memorySubscriptionRepo{subscriptions: []notification.PushSubscription{
+		browser.subscription("s1", "u1", pushService.URL+"/gone"),
+		browser.subscription("s2", "u1", pushService.URL+"/ok"),
+		browser.subscription("s3", "u2", pushService.URL+"/not-found"),
+		browser.subscription("s4", "u3", pushService.URL+"/broken"),
+	}}
+	sender := NewSender(keys, repo, pushService.Client())
+
+	assert.NoError(t, sender.Send(context.Background(), notification.Message{UserID: "u1"}))
+	// 全ての購読が失効していた場合は宛先なし、失効以外の失敗はエラー
+	assert.ErrorIs(t, sender.Send(context.Background(), notification.Message{UserID: "u2"}), notification.ErrNoRecipient)
+	err = sender.Send(context.Background(), notification.Message{UserID: "u3"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, notification.ErrNoRecipient)
+
+	remaining := make([]string, len(repo.subscriptions))
+	for i, s := range repo.subscriptions {
+		remaining[i] = s.ID
+	}
+	assert.Equal(t, []string{"s2", "s4"}, remaining)
+}
+
+func TestNewKeys(t *testing.T) {
+	keys, err := GenerateKeys("https://tofunote.example.com")
+	require.NoError(t, err)
+	assert.Len(t, keys.PublicKey(), 87) // 非圧縮形式の65バイト
+
+	_, err = NewKeys("invalid", "mailto:admin@example.com")
+	assert.Error(t, err)
+
+	_, err = GenerateKeys("admin@example.com")
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+// Package webpush はWeb Push（RFC 8030）でブラウザに通知を送る。
+// プッシュサービスへの送信はVAPID（RFC 8292）で署名する
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidTokenTTL はVAPIDのJWTの有効期間（RFC 8292では24時間以内）
+const vapidTokenTTL = 12 * time.Hour
+
+// Keys はアプリケーションサーバーを識別するVAPIDの鍵ペア
+type Keys struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  []byte
+	// Subject はプッシュサービスからの連絡先（mailto: または https: のURL）
+	Subject string
+}
+
+// NewKeys はbase64url形式のP-256の秘密鍵（32バイト）から鍵ペアを作る
+func NewKeys(privateKey, subject string) (*Keys, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("VAPIDの秘密鍵の形式が不正です: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("VAPIDの秘密鍵の形式が不正です: %w", err)
+	}
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, errors.New("VAPIDのsubjectは mailto: または https: で始まる必要があります")
+	}
+	publicKey := key.PublicKey().Bytes()
+	return &Keys{
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(publicKey[1:33]),
+				Y:     new(big.Int).SetBytes(publicKey[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		publicKey: publicKey,
+		Subject:   subject,
+	}, nil
+}
+
+// GenerateKeys は新しい鍵ペアを作る（開発環境・テスト用）
+func GenerateKeys(subject string) (*Keys, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeys(base64.RawURLEncoding.EncodeToString(key.Bytes()), subject)
+}
+
+// KeysFromEnv は環境変数から鍵ペアを読み込む（VAPID_PRIVATE_KEYが未設定の場合はnilを返し、Web Pushを無効にする）
+//
+//	VAPID_PRIVATE_KEY / VAPID_PUBLIC_KEY / VAPID_SUBJECT
+func KeysFromEnv() (*Keys, error) {
+	privateKey := os.Getenv("VAPID_PRIVATE_KEY")
+	if privateKey == "" {
+		return nil, nil
+	}
+	keys, err := NewKeys(privateKey, os.Getenv("VAPID_SUBJECT"))
+	if err != nil {
+		return nil, err
+	}
+	// 公開鍵はブラウザの購読に使うため、秘密鍵と組になっていない値の設定ミスを起動時に検出する
+	if publicKey := os.Getenv("VAPID_PUBLIC_KEY"); publicKey != "" && publicKey != keys.PublicKey() {
+		return nil, errors.New("VAPID_PUBLIC_KEYがVAPID_PRIVATE_KEYと組になっていません")
+	}
+	return keys, nil
+}
+
+// PublicKey はブラウザの購読（applicationServerKey）に使う公開鍵をbase64url形式で返す
+func (k *Keys) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(k.publicKey)
+}
+
+// authorization はプッシュサービスのエンドポイントに送るAuthorizationヘッダーの値を返す
+func (k *Keys) authorization(endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidTokenTTL).Unix(),
+		"sub": k.Subject,
+	}).SignedString(k.privateKey)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + token + ", k=" + k.PublicKey(), nil
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -8,6 +8,14 @@ import (
 	"time"
 	"tofunote-backend/api/controllers"
 	"tofunote-backend/infra"
+	"tofunote-backend/infra/authtoken"
+	"tofunote-backend/infra/mail"
+	"tofunote-backend/infra/notification"
+	"tofunote-backend/infra/oidc"
+	"tofunote-backend/infra/password"
+	"tofunote-backend/infra/webauthn"
+	"tofunote-backend/infra/webhook"
+	"tofunote-backend/infra/webpush"
 	"tofunote-backend/repositories"
 	"tofunote-backend/routes"
 	"tofunote-backend/routes/middleware"
@@ -65,33 +73,128 @@ func initializeApp() {
 			infra.Initialize()
 			log.Println("[DEBUG] Lambda initializeApp: infra.Initialize() 完了")
 
+			// 本番環境で署名鍵が未設定・既定値・弱い値の場合は起動しない
+			if err := infra.InitJWT(); err != nil {
+				log.Fatalf("[ERROR] Lambda initializeApp: JWTの署名鍵の設定が不正です: %v", err)
+			}
+			flowSecret, err := oidc.FlowSecretFromEnv()
+			if err != nil {
+				log.Fatalf("[ERROR] Lambda initializeApp: OIDCの署名鍵の設定が不正です: %v", err)
+			}
+			ticketSecret, err := authtoken.SecretFromEnv()
+			if err != nil {
+				log.Fatalf("[ERROR] Lambda initializeApp: 認証用トークンの署名鍵の設定が不正です: %v", err)
+			}
+
 			log.Println("[DEBUG] Lambda initializeApp: infra.SetupDB() 開始")
 			db := infra.SetupDB()
-			middleware.SetAuthDB(db)
 			log.Println("[DEBUG] Lambda initializeApp: infra.SetupDB() 完了")
 
 			log.Println("[DEBUG] Lambda initializeApp: repositories.NewDiaryRepository 開始")
 			diaryRepository := repositories.NewDiaryRepository(db)
 			log.Println("[DEBUG] Lambda initializeApp: repositories.NewDiaryRepository 完了")
 
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewEmotionLabelRepository 開始")
+			emotionLabelRepository := repositories.NewEmotionLabelRepository(db)
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewEmotionLabelRepository 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewUserPreferencesRepository 開始")
+			userPreferencesRepository := repositories.NewUserPreferencesRepository(db)
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewUserPreferencesRepository 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewWebhookEndpointRepository 開始")
+			webhookEndpointRepository := repositories.NewWebhookEndpointRepository(db)
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewWebhookEndpointRepository 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewWebhookDeliveryRepository 開始")
+			webhookDeliveryRepository := repositories.NewWebhookDeliveryRepository(db)
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewWebhookDeliveryRepository 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: usecases.NewWebhookUsecase 開始")
+			webhookUsecase := usecases.NewWebhookUsecase(webhookEndpointRepository, webhookDeliveryRepository, webhook.NewHTTPTransport(nil))
+			log.Println("[DEBUG] Lambda initializeApp: usecases.NewWebhookUsecase 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewWebhookController 開始")
+			webhookController := controllers.NewWebhookController(webhookUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewWebhookController 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: usecases.NewAuditUsecase 開始")
+			auditUsecase := usecases.NewAuditUsecase(repositories.NewAuditEventRepository(db))
+			log.Println("[DEBUG] Lambda initializeApp: usecases.NewAuditUsecase 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: middleware.SetAuthFailureRecorder 開始")
+			middleware.SetAuthFailureRecorder(auditUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: middleware.SetAuthFailureRecorder 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewAuditController 開始")
+			auditController := controllers.NewAuditController(auditUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewAuditController 完了")
+
 			log.Println("[DEBUG] Lambda initializeApp: usecases.NewDiaryUsecase 開始")
-			diaryUsecase := usecases.NewDiaryUsecase(diaryRepository)
+			diaryUsecase := usecases.NewDiaryUsecase(diaryRepository, emotionLabelRepository, userPreferencesRepository, webhookUsecase)
 			log.Println("[DEBUG] Lambda initializeApp: usecases.NewDiaryUsecase 完了")
 
 			log.Println("[DEBUG] Lambda initializeApp: controllers.NewDiaryController 開始")
 			diaryController := controllers.NewDiaryController(diaryUsecase)
 			log.Println("[DEBUG] Lambda initializeApp: controllers.NewDiaryController 完了")
 
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewThoughtRecordRepository 開始")
+			thoughtRecordRepository := repositories.NewThoughtRecordRepository(db)
+			log.Println("[DEBUG] Lambda initializeApp: repositories.NewThoughtRecordRepository 完了")
+
 			log.Println("[DEBUG] Lambda initializeApp: usecases.NewDiaryAnalysisUsecase 開始")
-			diaryAnalysisUsecase := usecases.NewDiaryAnalysisUsecase(diaryRepository)
+			diaryAnalysisUsecase := usecases.NewDiaryAnalysisUsecase(diaryRepository, thoughtRecordRepository, userPreferencesRepository, webhookUsecase)
 			log.Println("[DEBUG] Lambda initializeApp: usecases.NewDiaryAnalysisUsecase 完了")
 
 			log.Println("[DEBUG] Lambda initializeApp: controllers.NewDiaryAnalysisController 開始")
 			diaryAnalysisController := controllers.NewDiaryAnalysisController(diaryAnalysisUsecase)
 			log.Println("[DEBUG] Lambda initializeApp: controllers.NewDiaryAnalysisController 完了")
 
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewEmotionController 開始")
+			emotionUsecase := usecases.NewEmotionUsecase(emotionLabelRepository)
+			emotionController := controllers.NewEmotionController(emotionUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewEmotionController 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewFactorController 開始")
+			factorRepository := repositories.NewFactorRepository(db)
+			factorDefinitionRepository := repositories.NewFactorDefinitionRepository(db)
+			factorUsecase := usecases.NewFactorUsecase(factorRepository, factorDefinitionRepository)
+			factorController := controllers.NewFactorController(factorUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewFactorController 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewMedicationController 開始")
+			medicationRepository := repositories.NewMedicationRepository(db)
+			doseLogRepository := repositories.NewDoseLogRepository(db)
+			medicationUsecase := usecases.NewMedicationUsecase(medicationRepository, doseLogRepository, diaryRepository, userPreferencesRepository)
+			medicationController := controllers.NewMedicationController(medicationUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewMedicationController 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewThoughtRecordController 開始")
+			thoughtRecordUsecase := usecases.NewThoughtRecordUsecase(thoughtRecordRepository, emotionLabelRepository)
+			thoughtRecordController := controllers.NewThoughtRecordController(thoughtRecordUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewThoughtRecordController 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewGratitudeController 開始")
+			gratitudeRepository := repositories.NewGratitudeRepository(db)
+			gratitudeUsecase := usecases.NewGratitudeUsecase(gratitudeRepository, diaryRepository, userPreferencesRepository)
+			gratitudeController := controllers.NewGratitudeController(gratitudeUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewGratitudeController 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewExportController 開始")
+			exportUsecase := usecases.NewExportUsecase(diaryRepository, emotionLabelRepository, factorRepository, medicationRepository, doseLogRepository, thoughtRecordRepository, gratitudeRepository, auditUsecase)
+			exportController := controllers.NewExportController(exportUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewExportController 完了")
+
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewDiaryStatsController 開始")
+			diaryStatsUsecase := usecases.NewDiaryStatsUsecase(diaryRepository, factorRepository, factorDefinitionRepository)
+			diaryStatsController := controllers.NewDiaryStatsController(diaryStatsUsecase, emotionUsecase)
+			log.Println("[DEBUG] Lambda initializeApp: controllers.NewDiaryStatsController 完了")
+
 			log.Println("[DEBUG] Lambda initializeApp: gin.Default() 開始")
 			router := gin.Default()
+			if err := routes.SetupClientIP(router); err != nil {
+				log.Fatalf("[ERROR] Lambda initializeApp: クライアントのIPアドレスの設定が不正です: %v", err)
+			}
 
 			// CORSミドルウェアを一番最初に登録
 			routes.SetupCORS(router)
@@ -121,11 +224,79 @@ func initializeApp() {
 			routes.SetupSwaggerEndpoints(router)
 			log.Println("[DEBUG] Lambda initializeApp: routes.SetupSwaggerEndpoints 完了")
 
+			routes.SetupWellKnownEndpoints(router, infra.KeyManager())
+
 			log.Println("[DEBUG] Lambda initializeApp: routes.SetupAPIEndpoints 開始")
 			userRepo := repositories.NewUserRepository(db)
-			withdrawUsecase := usecases.NewUserWithdrawUsecase(userRepo, diaryRepository)
-			userController := controllers.NewUserController(userRepo, withdrawUsecase)
-			routes.SetupAPIEndpoints(router, diaryController, diaryAnalysisController, userController)
+			oneTimeTokenRepository := repositories.NewOneTimeTokenRepository(db)
+			refreshTokenRepository := repositories.NewRefreshTokenRepository(db)
+			sessionRepository := repositories.NewSessionRepository(db)
+			passkeyRepository := repositories.NewPasskeyRepository(db)
+			passkeyChallengeRepository := repositories.NewPasskeyChallengeRepository(db)
+			totpRepository := repositories.NewTOTPRepository(db)
+			recoveryCodeRepository := repositories.NewRecoveryCodeRepository(db)
+			roleRepository := repositories.NewRoleRepository(db)
+			personalAccessTokenRepository := repositories.NewPersonalAccessTokenRepository(db)
+			pushSubscriptionRepository := repositories.NewPushSubscriptionRepository(db)
+			sessionUsecase := usecases.NewSessionUsecase(sessionRepository, refreshTokenRepository, infra.AccessTokenTTL, usecases.DefaultRevocationRefreshInterval)
+			middleware.SetSessionRevocations(sessionUsecase)
+			sessionController := controllers.NewSessionController(sessionUsecase)
+			refreshTokenUsecase := usecases.NewRefreshTokenUsecase(refreshTokenRepository, sessionUsecase, auditUsecase, usecases.DefaultRefreshTokenTTL)
+			totpUsecase := usecases.NewTOTPUsecase(totpRepository, recoveryCodeRepository, userRepo, authtoken.NewSignedMFAChallengeStore(ticketSecret, authtoken.DefaultMFAChallengeTTL), os.Getenv("TOTP_ISSUER"))
+			mfaController := controllers.NewMFAController(totpUsecase, refreshTokenUsecase)
+			guestRetentionRepository := repositories.NewGuestRetentionRepository(db)
+			withdrawUsecase := usecases.NewUserWithdrawUsecase(repositories.NewUnitOfWork(db), userRepo, guestRetentionRepository, sessionUsecase, personalAccessTokenRepository, auditUsecase, diaryRepository, emotionLabelRepository, factorRepository, factorDefinitionRepository, doseLogRepository, medicationRepository, thoughtRecordRepository, gratitudeRepository, oneTimeTokenRepository, refreshTokenRepository, sessionRepository, passkeyRepository, passkeyChallengeRepository, totpRepository, recoveryCodeRepository, roleRepository, personalAccessTokenRepository, userPreferencesRepository, pushSubscriptionRepository, webhookDeliveryRepository, webhookEndpointRepository)
+			guestAdmissionConfig, err := usecases.GuestAdmissionConfigFromEnv()
+			if err != nil {
+				log.Printf("[ERROR] Lambda initializeApp: ゲストの作成の制限の設定に失敗したため既定値を使います: %v", err)
+				guestAdmissionConfig = usecases.DefaultGuestAdmissionConfig()
+			}
+			guestAdmissionUsecase := usecases.NewGuestAdmissionUsecase(repositories.NewGuestCreationRepository(db), authtoken.NewSignedProofOfWorkChallengeStore(ticketSecret, authtoken.DefaultProofOfWorkTTL), guestAdmissionConfig)
+			guestRetentionConfig, err := usecases.GuestRetentionConfigFromEnv()
+			if err != nil {
+				log.Printf("[ERROR] Lambda initializeApp: ゲストの自動削除の設定に失敗したため既定値を使います: %v", err)
+				guestRetentionConfig = usecases.DefaultGuestRetentionConfig()
+			}
+			guestRetentionUsecase := usecases.NewGuestRetentionUsecase(guestRetentionRepository, repositories.NewGuestCreationRepository(db), withdrawUsecase, notification.NewLogNotifier(), guestRetentionConfig)
+			middleware.SetLastSeenRecorder(guestRetentionUsecase)
+			profileUsecase := usecases.NewProfileUsecase(repositories.NewUnitOfWork(db), userRepo, userPreferencesRepository)
+			userController := controllers.NewUserController(userRepo, withdrawUsecase, refreshTokenUsecase, guestAdmissionUsecase, guestRetentionUsecase, profileUsecase)
+			adminUsecase := usecases.NewAdminUsecase(repositories.NewUserDirectory(db), userRepo, roleRepository, repositories.NewAdminStatsRepository(db), repositories.NewAdminActionLogRepository(db), diaryRepository, withdrawUsecase, diaryAnalysisUsecase, auditUsecase)
+			middleware.SetRoleVerifier(adminUsecase)
+			adminController := controllers.NewAdminController(adminUsecase)
+			personalAccessTokenUsecase := usecases.NewPersonalAccessTokenUsecase(personalAccessTokenRepository, userRepo)
+			middleware.SetPersonalAccessTokens(personalAccessTokenUsecase)
+			personalAccessTokenController := controllers.NewPersonalAccessTokenController(personalAccessTokenUsecase)
+			oidcProviders, err := oidc.ProvidersFromEnv()
+			if err != nil {
+				log.Printf("[ERROR] Lambda initializeApp: OIDCプロバイダの設定に失敗しました: %v", err)
+			}
+			oidcLoginUsecase := usecases.NewOIDCLoginUsecase(oidcProviders, oidc.NewSignedFlowStore(flowSecret, oidc.DefaultFlowTTL), userRepo)
+			oidcController := controllers.NewOIDCController(oidcLoginUsecase, refreshTokenUsecase, totpUsecase, os.Getenv("OIDC_FRONTEND_CALLBACK_URL"))
+			argon2Params, err := password.ParamsFromEnv()
+			if err != nil {
+				log.Printf("[ERROR] Lambda initializeApp: パスワードハッシュの設定に失敗したため既定値を使います: %v", err)
+				argon2Params = password.DefaultParams
+			}
+			passwordAuthUsecase := usecases.NewPasswordAuthUsecase(userRepo, oneTimeTokenRepository, sessionUsecase, password.NewArgon2idHasher(argon2Params), mail.MailerFromEnv(), usecases.DefaultPasswordAuthConfig(os.Getenv("FRONTEND_URL")), auditUsecase)
+			passwordAuthController := controllers.NewPasswordAuthController(passwordAuthUsecase, refreshTokenUsecase, totpUsecase)
+			accountLinkUsecase := usecases.NewAccountLinkUsecase(oidcLoginUsecase, passwordAuthUsecase, userRepo, repositories.NewAccountMergeRepository(db), authtoken.NewSignedMergeTicketStore(ticketSecret, authtoken.DefaultMergeTicketTTL), totpUsecase, auditUsecase)
+			accountLinkController := controllers.NewAccountLinkController(accountLinkUsecase, refreshTokenUsecase)
+			webauthnConfig, err := webauthn.ConfigFromEnv()
+			if err != nil {
+				log.Printf("[ERROR] Lambda initializeApp: パスキーの設定に失敗したためパスキーは利用できません: %v", err)
+			}
+			passkeyUsecase := usecases.NewPasskeyUsecase(passkeyRepository, passkeyChallengeRepository, userRepo, webauthn.NewVerifier(webauthnConfig), usecases.DefaultPasskeyChallengeTTL)
+			passkeyController := controllers.NewPasskeyController(passkeyUsecase, refreshTokenUsecase)
+			vapidPublicKey := ""
+			vapidKeys, err := webpush.KeysFromEnv()
+			if err != nil {
+				log.Printf("[ERROR] Lambda initializeApp: VAPIDの鍵の設定に失敗したためWeb Pushは利用できません: %v", err)
+			} else if vapidKeys != nil {
+				vapidPublicKey = vapidKeys.PublicKey()
+			}
+			pushSubscriptionController := controllers.NewPushSubscriptionController(usecases.NewPushSubscriptionUsecase(pushSubscriptionRepository), vapidPublicKey)
+			routes.SetupAPIEndpoints(router, diaryController, diaryAnalysisController, userController, emotionController, diaryStatsController, factorController, medicationController, thoughtRecordController, exportController, gratitudeController, oidcController, accountLinkController, passwordAuthController, sessionController, passkeyController, mfaController, adminController, personalAccessTokenController, auditController, pushSubscriptionController, webhookController)
 			log.Println("[DEBUG] Lambda initializeApp: routes.SetupAPIEndpoints 完了")
 
 			log.Println("[DEBUG] Lambda initializeApp: ginadapter.New(router) 開始")
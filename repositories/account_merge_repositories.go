@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"gorm.io/gorm"
+)
+
+type AccountMergeRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountMergeRepository(db *gorm.DB) user.AccountMerger {
+	return &AccountMergeRepository{db: db}
+}
+
+// MergeGuestInto はゲストの日記と関連データを統合先ユーザーへ移し、ゲストを削除する。
+// 日記の日付が重複した場合はpolicyに従って統合し、日付・キーが一意の関連データ（生活因子・良かったこと・
+// ユーザー定義の感情ラベル・因子定義）が重複した場合は統合先のものを残す。途中で失敗した場合は全て巻き戻す。
+func (r *AccountMergeRepository) MergeGuestInto(ctx context.Context, guestID, targetID string, policy diary.ConflictPolicy, now time.Time) (*user.MergeResult, error) {
+	result := &user.MergeResult{}
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var guestDiaries []db.DiaryModel
+		if err := tx.Where("user_id = ?", guestID).Order("date").Find(&guestDiaries).Error; err != nil {
+			return err
+		}
+		for _, g := range guestDiaries {
+			var existing db.DiaryModel
+			err := tx.Where("user_id = ? AND date = ?", targetID, db.NormalizeDate(g.Date)).First(&existing).Error
+			if err == gorm.ErrRecordNotFound {
+				if err := tx.Model(&db.DiaryModel{}).Where("id = ?", g.ID).Update("user_id", targetID).Error; err != nil {
+					return err
+				}
+				result.MovedDiaries++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			resolved := db.FromDomain(ptrDiary(diary.ResolveConflict(*existing.ToDomain(), *g.ToDomain(), policy)))
+			if err := tx.Model(&db.DiaryModel{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+				"mental":   resolved.Mental,
+				"diary":    resolved.Diary,
+				"emotions": resolved.Emotions,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("id = ?", g.ID).Delete(&db.DiaryModel{}).Error; err != nil {
+				return err
+			}
+			result.ConflictedDiaries++
+		}
+
+		// 統合先と日付・キーが重複する関連データはゲスト側を削除してから移す
+		uniqueColumns := []struct {
+			model  interface{}
+			column string
+		}{
+			{&db.DailyFactorModel{}, "date"},
+			{&db.DailyHighlightsModel{}, "date"},
+			{&db.EmotionLabelModel{}, "key"},
+			{&db.FactorDefinitionModel{}, "key"},
+		}
+		for _, u := range uniqueColumns {
+			targetKeys := tx.Model(u.model).Select(u.column).Where("user_id = ?", targetID)
+			if err := tx.Unscoped().Where("user_id = ? AND "+u.column+" IN (?)", guestID, targetKeys).Delete(u.model).Error; err != nil {
+				return err
+			}
+		}
+		for _, model := range []interface{}{
+			&db.DailyFactorModel{},
+			&db.DailyHighlightsModel{},
+			&db.EmotionLabelModel{},
+			&db.FactorDefinitionModel{},
+			&db.MedicationModel{},
+			&db.DoseLogModel{},
+			&db.ThoughtRecordModel{},
+		} {
+			if err := tx.Model(model).Where("user_id = ?", guestID).Update("user_id", targetID).Error; err != nil {
+				return err
+			}
+		}
+
+		// ゲストのログイン用のトークンは統合後に使えないよう削除する（設定は統合先のものを残す）。
+		// 通知・Webhookの送信先はゲスト側で誰でも登録できるため、移すと統合先の日記や通知が第三者に届く。統合せずに削除する
+		for _, model := range []interface{}{
+			&db.OneTimeTokenModel{},
+			&db.RefreshTokenModel{},
+			&db.UserPreferencesModel{},
+			&db.PushSubscriptionModel{},
+			&db.WebhookEndpointModel{},
+			&db.WebhookDeliveryModel{},
+		} {
+			if err := tx.Where("user_id = ?", guestID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		// セッションは発行済みのアクセストークンを拒否できるよう、失効させて残す
+		if err := tx.Model(&db.SessionModel{}).Where("user_id = ? AND revoked_at IS NULL", guestID).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		if err := scrubRevokedSessions(tx, guestID); err != nil {
+			return err
+		}
+		deleted := tx.Unscoped().Where("id = ? AND is_guest = ?", guestID, true).Delete(&db.UserModel{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		// 他の処理がゲストを削除・連携していた場合は、移したデータを残さないよう全て巻き戻す
+		if deleted.RowsAffected != 1 {
+			return user.ErrMergeGuestNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func ptrDiary(d diary.Diary) *diary.Diary {
+	return &d
+}
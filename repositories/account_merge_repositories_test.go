@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAccountMergeTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&user.User{}, &db.DiaryModel{}, &db.DailyFactorModel{}, &db.DailyHighlightsModel{},
+		&db.EmotionLabelModel{}, &db.FactorDefinitionModel{}, &db.MedicationModel{}, &db.DoseLogModel{}, &db.ThoughtRecordModel{}, &db.OneTimeTokenModel{}, &db.RefreshTokenModel{}, &db.SessionModel{}, &db.UserRoleModel{}, &db.UserPreferencesModel{}, &db.PushSubscriptionModel{}, &db.WebhookEndpointModel{}, &db.WebhookDeliveryModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestAccountMergeRepository_MergeGuestInto(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     diary.ConflictPolicy
+		wantMental int
+		wantText   string
+	}{
+		{name: "統合先を優先", policy: diary.ConflictKeepExisting, wantMental: 8, wantText: "本アカウントの日記"},
+		{name: "ゲストを優先", policy: diary.ConflictKeepGuest, wantMental: 2, wantText: "ゲストの日記"},
+		{name: "連結", policy: diary.ConflictConcatenate, wantMental: 5, wantText: "本アカウントの日記\n\nゲストの日記"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gormDB := setupAccountMergeTestDB(t)
+			ctx := context.Background()
+			users := NewUserRepository(gormDB)
+			diaries := NewDiaryRepository(gormDB)
+			highlights := NewGratitudeRepository(gormDB)
+			require.NoError(t, users.Create(ctx, &user.User{ID: "guest", IsGuest: true}))
+			require.NoError(t, users.Create(ctx, &user.User{ID: "owner", Provider: "google", ProviderID: "sub-1"}))
+			require.NoError(t, diaries.Create(ctx, &diary.Diary{UserID: "owner", Date: "2025-05-01", Mental: 8, Diary: "本アカウントの日記"}))
+			require.NoError(t, diaries.Create(ctx, &diary.Diary{UserID: "guest", Date: "2025-05-01", Mental: 2, Diary: "ゲストの日記"}))
+			require.NoError(t, diaries.Create(ctx, &diary.Diary{UserID: "guest", Date: "2025-05-02", Mental: 5, Diary: "ゲストだけの日記"}))
+			require.NoError(t, gormDB.Create(&db.DailyHighlightsModel{ID: "h1", UserID: "owner", Date: "2025-05-01", Highlight: "本"}).Error)
+			require.NoError(t, gormDB.Create(&db.DailyHighlightsModel{ID: "h2", UserID: "guest", Date: "2025-05-01", Highlight: "ゲスト"}).Error)
+			require.NoError(t, gormDB.Create(&db.DailyHighlightsModel{ID: "h3", UserID: "guest", Date: "2025-05-02", Highlight: "ゲスト2"}).Error)
+			sessions := NewSessionRepository(gormDB)
+			now := time.Date(2025, 5, 3, 12, 0, 0, 0, time.UTC)
+			guestSession := &auth.Session{UserID: "guest", UserAgent: "Mozilla/5.0", IPAddress: "192.0.2.1", LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+			require.NoError(t, sessions.Create(ctx, guestSession))
+
+			result, err := NewAccountMergeRepository(gormDB).MergeGuestInto(ctx, "guest", "owner", tt.policy, now)
+			require.NoError(t, err)
+			assert.Equal(t, &user.MergeResult{MovedDiaries: 1, ConflictedDiaries: 1}, result)
+
+			merged, err := diaries.FindByUserID(ctx, "owner")
+			require.NoError(t, err)
+			require.Len(t, merged, 2)
+			conflicted, err := diaries.FindByUserIDAndDate(ctx, "owner", "2025-05-01")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMental, int(conflicted.Mental))
+			assert.Equal(t, tt.wantText, conflicted.Diary)
+
+			guestDiaries, err := diaries.FindByUserID(ctx, "guest")
+			require.NoError(t, err)
+			assert.Empty(t, guestDiaries)
+
+			// 日付が重複した良かったことは統合先のものが残り、重複しないものは移動する
+			h, err := highlights.FindByUserIDAndDate(ctx, "owner", "2025-05-01")
+			require.NoError(t, err)
+			assert.Equal(t, "本", h.Highlight)
+			h, err = highlights.FindByUserIDAndDate(ctx, "owner", "2025-05-02")
+			require.NoError(t, err)
+			assert.Equal(t, "ゲスト2", h.Highlight)
+
+			guest, err := users.FindByID(ctx, "guest")
+			require.NoError(t, err)
+			assert.Nil(t, guest)
+
+			// ゲストのアクセストークンを拒否できるよう、セッションは失効させて端末の情報のみ消す
+			revoked, err := sessions.ListRevokedIDsSince(ctx, now.Add(-time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, []string{guestSession.ID}, revoked)
+			var scrubbed db.SessionModel
+			require.NoError(t, gormDB.First(&scrubbed, "id = ?", guestSession.ID).Error)
+			assert.Empty(t, scrubbed.UserAgent)
+			assert.Empty(t, scrubbed.IPAddress)
+		})
+	}
+}
+
+func TestAccountMergeRepository_MergeGuestInto_DeletesGuestDestinations(t *testing.T) {
+	gormDB := setupAccountMergeTestDB(t)
+	ctx := context.Background()
+	users := NewUserRepository(gormDB)
+	require.NoError(t, users.Create(ctx, &user.User{ID: "guest", IsGuest: true}))
+	require.NoError(t, users.Create(ctx, &user.User{ID: "owner", Provider: "google", ProviderID: "sub-1"}))
+	// ゲスト側で登録したWebhook・Web Pushの購読
+	require.NoError(t, gormDB.Create(&db.WebhookEndpointModel{ID: "ep-guest", UserID: "guest", URL: "https://attacker.example.com/hook", Secret: "whsec_guest", Events: "diary.created"}).Error)
+	require.NoError(t, gormDB.Create(&db.WebhookDeliveryModel{ID: "dl-guest", EndpointID: "ep-guest", UserID: "guest", EventID: "ev1", EventType: "diary.created", Payload: []byte("{}"), Status: "pending", NextAttemptAt: time.Now()}).Error)
+	require.NoError(t, gormDB.Create(&db.PushSubscriptionModel{ID: "ps-guest", UserID: "guest", Endpoint: "https://push.example.com/guest", P256dh: "p", Auth: "a"}).Error)
+	require.NoError(t, gormDB.Create(&db.WebhookEndpointModel{ID: "ep-owner", UserID: "owner", URL: "https://hooks.example.com/owner", Secret: "whsec_owner", Events: "diary.created"}).Error)
+
+	_, err := NewAccountMergeRepository(gormDB).MergeGuestInto(ctx, "guest", "owner", diary.ConflictKeepExisting, time.Now())
+	require.NoError(t, err)
+
+	endpoints, err := NewWebhookEndpointRepository(gormDB).FindByUserID(ctx, "owner")
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "ep-owner", endpoints[0].ID)
+	subscriptions, err := NewPushSubscriptionRepository(gormDB).FindByUserID(ctx, "owner")
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions)
+	var remaining int64
+	require.NoError(t, gormDB.Model(&db.WebhookDeliveryModel{}).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+	require.NoError(t, gormDB.Model(&db.WebhookEndpointModel{}).Where("user_id = ?", "guest").Count(&remaining).Error)
+	assert.Zero(t, remaining)
+	require.NoError(t, gormDB.Model(&db.PushSubscriptionModel{}).Where("user_id = ?", "guest").Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
+
+func TestAccountMergeRepository_MergeGuestInto_Rollback(t *testing.T) {
+	gormDB := setupAccountMergeTestDB(t)
+	ctx := context.Background()
+	users := NewUserRepository(gormDB)
+	diaries := NewDiaryRepository(gormDB)
+	require.NoError(t, users.Create(ctx, &user.User{ID: "guest", IsGuest: true}))
+	require.NoError(t, diaries.Create(ctx, &diary.Diary{UserID: "guest", Date: "2025-05-02", Mental: 5, Diary: "ゲストの日記"}))
+	// 関連データのテーブルがない状態で失敗させ、日記の移動も巻き戻ることを確認する
+	require.NoError(t, gormDB.Migrator().DropTable(&db.ThoughtRecordModel{}))
+
+	_, err := NewAccountMergeRepository(gormDB).MergeGuestInto(ctx, "guest", "owner", diary.ConflictKeepExisting, time.Now())
+	assert.Error(t, err)
+
+	guestDiaries, err := diaries.FindByUserID(ctx, "guest")
+	require.NoError(t, err)
+	assert.Len(t, guestDiaries, 1)
+	guest, err := users.FindByID(ctx, "guest")
+	require.NoError(t, err)
+	assert.NotNil(t, guest)
+}
+
+func TestAccountMergeRepository_MergeGuestInto_GuestAlreadyLinked(t *testing.T) {
+	gormDB := setupAccountMergeTestDB(t)
+	ctx := context.Background()
+	users := NewUserRepository(gormDB)
+	diaries := NewDiaryRepository(gormDB)
+	// 統合の前に別の処理でゲストでなくなった場合
+	require.NoError(t, users.Create(ctx, &user.User{ID: "guest", IsGuest: false}))
+	require.NoError(t, diaries.Create(ctx, &diary.Diary{UserID: "guest", Date: "2025-05-02", Mental: 5, Diary: "ゲストの日記"}))
+
+	_, err := NewAccountMergeRepository(gormDB).MergeGuestInto(ctx, "guest", "owner", diary.ConflictKeepExisting, time.Now())
+	assert.ErrorIs(t, err, user.ErrMergeGuestNotFound)
+
+	guestDiaries, err := diaries.FindByUserID(ctx, "guest")
+	require.NoError(t, err)
+	assert.Len(t, guestDiaries, 1)
+	ownerDiaries, err := diaries.FindByUserID(ctx, "owner")
+	require.NoError(t, err)
+	assert.Empty(t, ownerDiaries)
+}
@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+	"tofunote-backend/domain/admin"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type UserDirectory struct {
+	db *gorm.DB
+}
+
+func NewUserDirectory(db *gorm.DB) user.Directory {
+	return &UserDirectory{db: db}
+}
+
+// likeEscaper はLIKEの検索語に含まれるワイルドカードを文字として扱うためのエスケープ
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *UserDirectory) Search(ctx context.Context, query user.SearchQuery) ([]*user.Summary, int64, error) {
+	tx := conn(ctx, r.db).Model(&user.User{})
+	if keyword := strings.ToLower(strings.TrimSpace(query.Keyword)); keyword != "" {
+		pattern := "%" + likeEscaper.Replace(keyword) + "%"
+		// PostgreSQLはUUID型の列とUUIDの形式でない文字列を比較するとエラーになるため、形式が合う場合のみIDでも検索する
+		if isUUIDString(keyword) {
+			tx = tx.Where(`id = ? OR LOWER(email) LIKE ? ESCAPE '\' OR LOWER(nickname) LIKE ? ESCAPE '\'`, keyword, pattern, pattern)
+		} else {
+			tx = tx.Where(`LOWER(email) LIKE ? ESCAPE '\' OR LOWER(nickname) LIKE ? ESCAPE '\'`, pattern, pattern)
+		}
+	}
+	if query.Guest != nil {
+		tx = tx.Where("is_guest = ?", *query.Guest)
+	}
+	if query.Role != "" {
+		tx = tx.Where("id IN (?)", r.db.Model(&db.UserRoleModel{}).Select("user_id").Where("role = ?", string(query.Role)))
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []user.User
+	if err := tx.Order("created_at DESC, id DESC").Limit(query.Limit).Offset(query.Offset).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(users) == 0 {
+		return []*user.Summary{}, total, nil
+	}
+
+	ids := make([]string, len(users))
+	for i := range users {
+		ids[i] = users[i].ID
+	}
+	var roles []db.UserRoleModel
+	if err := conn(ctx, r.db).Where("user_id IN ?", ids).Order("created_at ASC, role ASC").Find(&roles).Error; err != nil {
+		return nil, 0, err
+	}
+	// 日記は件数と最終日のみ集計し、本文は読み込まない
+	var activity []struct {
+		UserID   string
+		Count    int64
+		LastDate string
+	}
+	if err := conn(ctx, r.db).Model(&db.DiaryModel{}).
+		Select("user_id, COUNT(*) AS count, CAST(MAX(date) AS TEXT) AS last_date").
+		Where("user_id IN ?", ids).Group("user_id").Scan(&activity).Error; err != nil {
+		return nil, 0, err
+	}
+
+	summaries := make([]*user.Summary, len(users))
+	byID := make(map[string]*user.Summary, len(users))
+	for i := range users {
+		summaries[i] = &user.Summary{User: users[i]}
+		byID[users[i].ID] = summaries[i]
+	}
+	for _, role := range roles {
+		if s, ok := byID[role.UserID]; ok {
+			s.Roles = append(s.Roles, user.Role(role.Role))
+		}
+	}
+	for _, a := range activity {
+		if s, ok := byID[a.UserID]; ok {
+			s.DiaryCount = a.Count
+			s.LastDiaryDate = truncateDate(a.LastDate)
+		}
+	}
+	return summaries, total, nil
+}
+
+// truncateDate は日付の文字列表現から時刻の部分を取り除く（SQLiteでは時刻付きで保存される場合がある）
+func truncateDate(value string) string {
+	if len(value) > len("2006-01-02") {
+		return value[:len("2006-01-02")]
+	}
+	return value
+}
+
+// isUUIDString は文字列が8-4-4-4-12桁の16進数の形式かどうかを返す
+func isUUIDString(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+	for i, c := range value {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type AdminStatsRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminStatsRepository(db *gorm.DB) admin.StatsRepository {
+	return &AdminStatsRepository{db: db}
+}
+
+func (r *AdminStatsRepository) UserStats(ctx context.Context, since time.Time) (*admin.UserStats, error) {
+	stats := &admin.UserStats{ByProvider: map[string]int64{}}
+	users := func() *gorm.DB { return conn(ctx, r.db).Model(&user.User{}) }
+	if err := users().Count(&stats.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := users().Where("is_guest = ?", true).Count(&stats.Guests).Error; err != nil {
+		return nil, err
+	}
+	stats.Members = stats.Total - stats.Guests
+	if err := users().Where("created_at >= ?", since).Count(&stats.NewSince).Error; err != nil {
+		return nil, err
+	}
+	var providers []struct {
+		Provider string
+		Count    int64
+	}
+	if err := users().Select("provider, COUNT(*) AS count").Where("is_guest = ?", false).Group("provider").Scan(&providers).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range providers {
+		name := p.Provider
+		if name == "" {
+			name = "email"
+		}
+		stats.ByProvider[name] += p.Count
+	}
+	return stats, nil
+}
+
+func (r *AdminStatsRepository) DiaryStats(ctx context.Context, since time.Time) (*admin.DiaryStats, error) {
+	stats := &admin.DiaryStats{MentalScoreUsers: map[int]int64{}}
+	diaries := func() *gorm.DB { return conn(ctx, r.db).Model(&db.DiaryModel{}) }
+	sinceDate := since.Format("2006-01-02")
+	if err := diaries().Count(&stats.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := diaries().Where("created_at >= ?", since).Count(&stats.NewSince).Error; err != nil {
+		return nil, err
+	}
+	if err := diaries().Where("date >= ?", sinceDate).Distinct("user_id").Count(&stats.ActiveWriters).Error; err != nil {
+		return nil, err
+	}
+	var scores []struct {
+		Mental int
+		Users  int64
+	}
+	if err := diaries().Select("mental, COUNT(DISTINCT user_id) AS users").Where("date >= ?", sinceDate).Group("mental").Scan(&scores).Error; err != nil {
+		return nil, err
+	}
+	for _, s := range scores {
+		stats.MentalScoreUsers[s.Mental] = s.Users
+	}
+	return stats, nil
+}
+
+type AdminActionLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminActionLogRepository(db *gorm.DB) admin.ActionLogRepository {
+	return &AdminActionLogRepository{db: db}
+}
+
+func (r *AdminActionLogRepository) Create(ctx context.Context, log *admin.ActionLog) error {
+	if log.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		log.ID = id.String()
+	}
+	model := db.AdminActionLogFromDomain(log)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	log.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *AdminActionLogRepository) List(ctx context.Context, targetUserID string, limit, offset int) ([]*admin.ActionLog, error) {
+	tx := conn(ctx, r.db)
+	if targetUserID != "" {
+		tx = tx.Where("target_user_id = ?", targetUserID)
+	}
+	var models []db.AdminActionLogModel
+	if err := tx.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	logs := make([]*admin.ActionLog, len(models))
+	for i := range models {
+		logs[i] = models[i].ToDomain()
+	}
+	return logs, nil
+}
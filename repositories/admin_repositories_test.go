@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/admin"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAdminTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&user.User{}, &db.UserRoleModel{}, &db.DiaryModel{}, &db.AdminActionLogModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func seedAdminTestData(t *testing.T, gormDB *gorm.DB) {
+	t.Helper()
+	base := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	users := []user.User{
+		{ID: "00000000-0000-0000-0000-000000000001", Nickname: "たろう", Email: "taro@example.com", CreatedAt: base},
+		{ID: "00000000-0000-0000-0000-000000000002", Nickname: "ゲスト", IsGuest: true, CreatedAt: base.Add(time.Hour)},
+		{ID: "00000000-0000-0000-0000-000000000003", Nickname: "100%_user", Provider: "google", ProviderID: "g1", CreatedAt: base.Add(2 * time.Hour)},
+	}
+	require.NoError(t, gormDB.Create(&users).Error)
+	require.NoError(t, NewRoleRepository(gormDB).Grant(context.Background(), users[0].ID, user.RoleAdmin))
+	diaries := []db.DiaryModel{
+		{ID: "d1", UserID: users[0].ID, Date: "2025-05-01", Mental: 5, Diary: "本文1"},
+		{ID: "d2", UserID: users[0].ID, Date: "2025-05-03", Mental: 7, Diary: "本文2"},
+		{ID: "d3", UserID: users[2].ID, Date: "2025-04-01", Mental: 7, Diary: "本文3"},
+	}
+	require.NoError(t, gormDB.Create(&diaries).Error)
+}
+
+func TestUserDirectory_Search(t *testing.T) {
+	gormDB := setupAdminTestDB(t)
+	seedAdminTestData(t, gormDB)
+	directory := NewUserDirectory(gormDB)
+	ctx := context.Background()
+
+	t.Run("新しい順に日記の件数と最終日を返す", func(t *testing.T) {
+		results, total, err := directory.Search(ctx, user.SearchQuery{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, results, 3)
+		assert.Equal(t, "100%_user", results[0].Nickname)
+		taro := results[2]
+		assert.Equal(t, []user.Role{user.RoleAdmin}, taro.Roles)
+		assert.Equal(t, int64(2), taro.DiaryCount)
+		assert.Equal(t, "2025-05-03", taro.LastDiaryDate)
+		assert.Equal(t, int64(0), results[1].DiaryCount)
+	})
+
+	t.Run("キーワードはワイルドカードを文字として扱う", func(t *testing.T) {
+		results, total, err := directory.Search(ctx, user.SearchQuery{Keyword: "%_", Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, "100%_user", results[0].Nickname)
+
+		results, _, err = directory.Search(ctx, user.SearchQuery{Keyword: "TARO@", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "たろう", results[0].Nickname)
+	})
+
+	t.Run("IDとゲスト・ロールで絞り込める", func(t *testing.T) {
+		guest := true
+		results, _, err := directory.Search(ctx, user.SearchQuery{Guest: &guest, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].IsGuest)
+
+		results, _, err = directory.Search(ctx, user.SearchQuery{Role: user.RoleAdmin, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "たろう", results[0].Nickname)
+
+		results, _, err = directory.Search(ctx, user.SearchQuery{Keyword: "00000000-0000-0000-0000-000000000002", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "ゲスト", results[0].Nickname)
+	})
+}
+
+func TestAdminStatsRepository(t *testing.T) {
+	gormDB := setupAdminTestDB(t)
+	seedAdminTestData(t, gormDB)
+	stats := NewAdminStatsRepository(gormDB)
+	ctx := context.Background()
+	since := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+
+	users, err := stats.UserStats(ctx, since)
+	require.NoError(t, err)
+	assert.Equal(t, &admin.UserStats{Total: 3, Guests: 1, Members: 2, ByProvider: map[string]int64{"email": 1, "google": 1}, NewSince: 3}, users)
+
+	diaries, err := stats.DiaryStats(ctx, since)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), diaries.Total)
+	assert.Equal(t, int64(1), diaries.ActiveWriters)
+	assert.Equal(t, map[int]int64{5: 1, 7: 1}, diaries.MentalScoreUsers)
+}
+
+func TestAdminActionLogRepository(t *testing.T) {
+	repo := NewAdminActionLogRepository(setupAdminTestDB(t))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &admin.ActionLog{AdminUserID: "a1", Action: admin.ActionGlobalAnalysis}))
+	log := &admin.ActionLog{AdminUserID: "a1", Action: admin.ActionBreakGlassDiaries, TargetUserID: "u1", Reason: "通報内容の確認のため"}
+	require.NoError(t, repo.Create(ctx, log))
+	assert.NotEmpty(t, log.ID)
+
+	logs, err := repo.List(ctx, "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+
+	logs, err = repo.List(ctx, "u1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "通報内容の確認のため", logs[0].Reason)
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type AuditEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditEventRepository(db *gorm.DB) audit.Repository {
+	return &AuditEventRepository{db: db}
+}
+
+func (r *AuditEventRepository) Append(ctx context.Context, event *audit.Event) error {
+	if event.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		event.ID = id.String()
+	}
+	model := db.AuditEventFromDomain(event)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	event.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *AuditEventRepository) List(ctx context.Context, query audit.Query) ([]*audit.Event, error) {
+	tx := conn(ctx, r.db)
+	if query.UserID != "" {
+		tx = tx.Where("actor_user_id = ? OR target_user_id = ?", query.UserID, query.UserID)
+	}
+	if query.Action != "" {
+		tx = tx.Where("action = ?", string(query.Action))
+	}
+	if !query.Since.IsZero() {
+		tx = tx.Where("created_at >= ?", query.Since)
+	}
+	if !query.Until.IsZero() {
+		tx = tx.Where("created_at < ?", query.Until)
+	}
+	var models []db.AuditEventModel
+	if err := tx.Order("created_at DESC, id DESC").Limit(query.Limit).Offset(query.Offset).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	events := make([]*audit.Event, len(models))
+	for i := range models {
+		events[i] = models[i].ToDomain()
+	}
+	return events, nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAuditEventTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.AuditEventModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestAuditEventRepository(t *testing.T) {
+	repo := NewAuditEventRepository(setupAuditEventTestDB(t))
+	ctx := context.Background()
+	alice := "00000000-0000-0000-0000-00000000000a"
+	bob := "00000000-0000-0000-0000-00000000000b"
+	admin := "00000000-0000-0000-0000-0000000000ad"
+	base := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	events := []*audit.Event{
+		{ActorUserID: alice, TargetUserID: alice, Action: audit.ActionLogin, Detail: "password", IPAddress: "203.0.113.1", UserAgent: "test", RequestID: "req-1", CreatedAt: base},
+		{ActorUserID: bob, TargetUserID: bob, Action: audit.ActionLogin, CreatedAt: base.Add(time.Hour)},
+		{ActorUserID: admin, TargetUserID: alice, Action: audit.ActionAdminAccess, Detail: "break_glass_diaries", CreatedAt: base.Add(2 * time.Hour)},
+		{ActorUserID: alice, TargetUserID: alice, Action: audit.ActionDataExport, CreatedAt: base.Add(3 * time.Hour)},
+	}
+	for _, e := range events {
+		require.NoError(t, repo.Append(ctx, e))
+		assert.NotEmpty(t, e.ID)
+	}
+
+	t.Run("操作したユーザーまたは対象のユーザーで絞り込み、新しい順に返す", func(t *testing.T) {
+		got, err := repo.List(ctx, audit.Query{UserID: alice, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, audit.ActionDataExport, got[0].Action)
+		assert.Equal(t, admin, got[1].ActorUserID)
+		assert.Equal(t, "203.0.113.1", got[2].IPAddress)
+		assert.Equal(t, "req-1", got[2].RequestID)
+	})
+
+	t.Run("操作と期間で絞り込める", func(t *testing.T) {
+		got, err := repo.List(ctx, audit.Query{Action: audit.ActionLogin, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+
+		got, err = repo.List(ctx, audit.Query{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour), Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, audit.ActionAdminAccess, got[0].Action)
+		assert.Equal(t, bob, got[1].ActorUserID)
+	})
+
+	t.Run("件数と開始位置を指定できる", func(t *testing.T) {
+		got, err := repo.List(ctx, audit.Query{Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, audit.ActionAdminAccess, got[0].Action)
+	})
+}
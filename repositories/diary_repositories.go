@@ -21,7 +21,7 @@ func NewDiaryRepository(db *gorm.DB) diary.DiaryRepository {
 
 func (r *DiaryRepository) FindAll(ctx context.Context) ([]diary.Diary, error) {
 	var diaryModels []db.DiaryModel
-	if err := r.db.WithContext(ctx).Find(&diaryModels).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&diaryModels).Error; err != nil {
 		return nil, err
 	}
 
@@ -34,7 +34,7 @@ func (r *DiaryRepository) FindAll(ctx context.Context) ([]diary.Diary, error) {
 
 func (r *DiaryRepository) FindByUserID(ctx context.Context, userID string) ([]diary.Diary, error) {
 	var diaryModels []db.DiaryModel
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&diaryModels).Error; err != nil {
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Find(&diaryModels).Error; err != nil {
 		return nil, err
 	}
 
@@ -45,20 +45,20 @@ func (r *DiaryRepository) FindByUserID(ctx context.Context, userID string) ([]di
 	return diaries, nil
 }
 
-func (r *DiaryRepository) FindByUserIDAndDate(ctx context.Context, userID string, date string) (*diary.Diary, error) {
+func (r *DiaryRepository) FindByUserIDAndDate(ctx context.Context, userID string, date diary.Date) (*diary.Diary, error) {
 	var diaryModel db.DiaryModel
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND date = ?", userID, date).First(&diaryModel).Error; err != nil {
+	if err := conn(ctx, r.db).Where("user_id = ? AND date = ?", userID, date.String()).First(&diaryModel).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("指定された日付の日記が見つかりません")
+			return nil, diary.ErrDiaryNotFound
 		}
 		return nil, err
 	}
 	return diaryModel.ToDomain(), nil
 }
 
-func (r *DiaryRepository) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]diary.Diary, error) {
+func (r *DiaryRepository) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate diary.Date) ([]diary.Diary, error) {
 	var diaryModels []db.DiaryModel
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).Find(&diaryModels).Error; err != nil {
+	if err := conn(ctx, r.db).Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate.String(), endDate.String()).Find(&diaryModels).Error; err != nil {
 		return nil, err
 	}
 
@@ -78,7 +78,7 @@ func (r *DiaryRepository) Create(ctx context.Context, diary *diary.Diary) error
 		diary.ID = id.String()
 	}
 	model := db.FromDomain(diary)
-	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
 		// 複合ユニークキー制約違反のエラーハンドリング
 		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") ||
 			strings.Contains(err.Error(), "UNIQUE constraint failed") {
@@ -89,9 +89,9 @@ func (r *DiaryRepository) Create(ctx context.Context, diary *diary.Diary) error
 	return nil
 }
 
-func (r *DiaryRepository) Update(ctx context.Context, userID string, date string, diary *diary.Diary) error {
+func (r *DiaryRepository) Update(ctx context.Context, userID string, date diary.Date, diary *diary.Diary) error {
 	model := db.FromDomain(diary)
-	result := r.db.WithContext(ctx).Where("user_id = ? AND date = ?", userID, date).Updates(model)
+	result := conn(ctx, r.db).Where("user_id = ? AND date = ?", userID, date.String()).Updates(model)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -101,8 +101,8 @@ func (r *DiaryRepository) Update(ctx context.Context, userID string, date string
 	return nil
 }
 
-func (r *DiaryRepository) Delete(ctx context.Context, userID string, date string) error {
-	result := r.db.WithContext(ctx).Unscoped().Where("user_id = ? AND date = ?", userID, date).Delete(&db.DiaryModel{})
+func (r *DiaryRepository) Delete(ctx context.Context, userID string, date diary.Date) error {
+	result := conn(ctx, r.db).Unscoped().Where("user_id = ? AND date = ?", userID, date.String()).Delete(&db.DiaryModel{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -114,7 +114,7 @@ func (r *DiaryRepository) Delete(ctx context.Context, userID string, date string
 
 // 指定ユーザーの全日記を削除
 func (r *DiaryRepository) DeleteByUserID(ctx context.Context, userID string) error {
-	result := r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&db.DiaryModel{})
+	result := conn(ctx, r.db).Unscoped().Where("user_id = ?", userID).Delete(&db.DiaryModel{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -211,7 +211,7 @@ func TestUpdate(t *testing.T) {
 		name         string
 		setupMock    func(sqlmock.Sqlmock)
 		userID       string
-		date         string
+		date         diary.Date
 		updateDiary  diary.Diary
 		expectError  bool
 		errorMessage string
@@ -295,7 +295,7 @@ func TestDelete(t *testing.T) {
 		name         string
 		setupMock    func(sqlmock.Sqlmock)
 		userID       string
-		date         string
+		date         diary.Date
 		expectError  bool
 		errorMessage string
 	}{
@@ -379,7 +379,7 @@ func TestFindByUserIDAndDate(t *testing.T) {
 		name          string
 		setupMock     func(sqlmock.Sqlmock)
 		userID        string
-		date          string
+		date          diary.Date
 		expectedDiary *diary.Diary
 		expectError   bool
 		errorMessage  string
@@ -470,8 +470,8 @@ func TestFindByUserIDAndDateRange(t *testing.T) {
 		name            string
 		setupMock       func(sqlmock.Sqlmock)
 		userID          string
-		startDate       string
-		endDate         string
+		startDate       diary.Date
+		endDate         diary.Date
 		expectedDiaries []diary.Diary
 		expectError     bool
 		errorMessage    string
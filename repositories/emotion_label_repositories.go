@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type EmotionLabelRepository struct {
+	db *gorm.DB
+}
+
+func NewEmotionLabelRepository(db *gorm.DB) diary.EmotionLabelRepository {
+	return &EmotionLabelRepository{db: db}
+}
+
+func (r *EmotionLabelRepository) FindByUserID(ctx context.Context, userID string) ([]diary.EmotionLabel, error) {
+	var models []db.EmotionLabelModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	labels := make([]diary.EmotionLabel, 0, len(models))
+	for _, model := range models {
+		labels = append(labels, model.ToDomain())
+	}
+	return labels, nil
+}
+
+func (r *EmotionLabelRepository) Create(ctx context.Context, label *diary.EmotionLabel) error {
+	model := db.EmotionLabelFromDomain(label)
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	model.ID = id.String()
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") ||
+			strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return errors.New("この感情ラベルは既に登録されています")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *EmotionLabelRepository) Delete(ctx context.Context, userID string, key string) error {
+	result := conn(ctx, r.db).Where("user_id = ? AND key = ?", userID, key).Delete(&db.EmotionLabelModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された感情ラベルが見つかりません")
+	}
+	return nil
+}
+
+// 指定ユーザーの全感情ラベルを削除
+func (r *EmotionLabelRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.EmotionLabelModel{}).Error
+}
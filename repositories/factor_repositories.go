@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"tofunote-backend/domain/factor"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FactorRepository struct {
+	db *gorm.DB
+}
+
+func NewFactorRepository(db *gorm.DB) factor.Repository {
+	return &FactorRepository{db: db}
+}
+
+func (r *FactorRepository) FindByUserID(ctx context.Context, userID string) ([]factor.DailyFactors, error) {
+	var models []db.DailyFactorModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("date").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainDailyFactors(models), nil
+}
+
+func (r *FactorRepository) FindByUserIDAndDate(ctx context.Context, userID string, date string) (*factor.DailyFactors, error) {
+	var model db.DailyFactorModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND date = ?", userID, date).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("指定された日付の因子記録が見つかりません")
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *FactorRepository) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]factor.DailyFactors, error) {
+	var models []db.DailyFactorModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).Order("date").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainDailyFactors(models), nil
+}
+
+func (r *FactorRepository) Upsert(ctx context.Context, f *factor.DailyFactors) error {
+	if f.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		f.ID = id.String()
+	}
+	model := db.DailyFactorFromDomain(f)
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"sleep_hours", "exercise_minutes", "alcohol_units", "medication_taken", "custom", "updated_at",
+		}),
+	}).Create(model).Error
+	if err != nil {
+		return err
+	}
+	// 既存の記録を上書きした場合はそのIDを返す
+	var saved db.DailyFactorModel
+	if err := conn(ctx, r.db).Select("id").Where("user_id = ? AND date = ?", f.UserID, f.Date).First(&saved).Error; err != nil {
+		return err
+	}
+	f.ID = saved.ID
+	return nil
+}
+
+func (r *FactorRepository) Delete(ctx context.Context, userID string, date string) error {
+	result := conn(ctx, r.db).Where("user_id = ? AND date = ?", userID, date).Delete(&db.DailyFactorModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された日付の因子記録が見つかりません")
+	}
+	return nil
+}
+
+// 指定ユーザーの全因子記録を削除
+func (r *FactorRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.DailyFactorModel{}).Error
+}
+
+func toDomainDailyFactors(models []db.DailyFactorModel) []factor.DailyFactors {
+	records := make([]factor.DailyFactors, 0, len(models))
+	for _, model := range models {
+		records = append(records, *model.ToDomain())
+	}
+	return records
+}
+
+type FactorDefinitionRepository struct {
+	db *gorm.DB
+}
+
+func NewFactorDefinitionRepository(db *gorm.DB) factor.DefinitionRepository {
+	return &FactorDefinitionRepository{db: db}
+}
+
+func (r *FactorDefinitionRepository) FindByUserID(ctx context.Context, userID string) ([]factor.Definition, error) {
+	var models []db.FactorDefinitionModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	defs := make([]factor.Definition, 0, len(models))
+	for _, model := range models {
+		defs = append(defs, model.ToDomain())
+	}
+	return defs, nil
+}
+
+func (r *FactorDefinitionRepository) Create(ctx context.Context, def *factor.Definition) error {
+	model := db.FactorDefinitionFromDomain(def)
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	model.ID = id.String()
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") ||
+			strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return errors.New("この因子は既に登録されています")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *FactorDefinitionRepository) Delete(ctx context.Context, userID string, key string) error {
+	result := conn(ctx, r.db).Where("user_id = ? AND key = ?", userID, key).Delete(&db.FactorDefinitionModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された因子が見つかりません")
+	}
+	return nil
+}
+
+// 指定ユーザーの全因子定義を削除
+func (r *FactorDefinitionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.FactorDefinitionModel{}).Error
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"tofunote-backend/domain/factor"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupFactorTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.DailyFactorModel{}, &db.FactorDefinitionModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestFactorRepository_Upsert(t *testing.T) {
+	repo := NewFactorRepository(setupFactorTestDB(t))
+	ctx := context.Background()
+	sleep, exercise := 5.5, 20
+
+	first := &factor.DailyFactors{UserID: "u1", Date: "2025-05-01", SleepHours: &sleep}
+	assert.NoError(t, repo.Upsert(ctx, first))
+	assert.NotEmpty(t, first.ID)
+
+	// 同じ日付は上書きされ、IDは維持される
+	second := &factor.DailyFactors{UserID: "u1", Date: "2025-05-01", ExerciseMinutes: &exercise}
+	assert.NoError(t, repo.Upsert(ctx, second))
+	assert.Equal(t, first.ID, second.ID)
+
+	found, err := repo.FindByUserIDAndDate(ctx, "u1", "2025-05-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-05-01", found.Date)
+	assert.Nil(t, found.SleepHours)
+	if assert.NotNil(t, found.ExerciseMinutes) {
+		assert.Equal(t, 20, *found.ExerciseMinutes)
+	}
+
+	_, err = repo.FindByUserIDAndDate(ctx, "u2", "2025-05-01")
+	assert.EqualError(t, err, "指定された日付の因子記録が見つかりません")
+}
+
+func TestFactorRepository_FindByUserIDAndDateRange(t *testing.T) {
+	repo := NewFactorRepository(setupFactorTestDB(t))
+	ctx := context.Background()
+	meditation := true
+	for _, date := range []string{"2025-05-01", "2025-05-02", "2025-06-01"} {
+		f := &factor.DailyFactors{UserID: "u1", Date: date, Custom: []factor.Value{{Key: "meditation", Bool: &meditation}}}
+		assert.NoError(t, repo.Upsert(ctx, f))
+	}
+
+	records, err := repo.FindByUserIDAndDateRange(ctx, "u1", "2025-05-01", "2025-05-31")
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, []factor.Value{{Key: "meditation", Bool: &meditation}}, records[0].Custom)
+
+	assert.NoError(t, repo.Delete(ctx, "u1", "2025-05-01"))
+	assert.EqualError(t, repo.Delete(ctx, "u1", "2025-05-01"), "指定された日付の因子記録が見つかりません")
+}
+
+func TestFactorDefinitionRepository_Create(t *testing.T) {
+	repo := NewFactorDefinitionRepository(setupFactorTestDB(t))
+	ctx := context.Background()
+
+	def, err := factor.NewCustomDefinition("u1", "meditation", "瞑想", factor.TypeBoolean, "", 0, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Create(ctx, &def))
+	assert.EqualError(t, repo.Create(ctx, &def), "この因子は既に登録されています")
+
+	defs, err := repo.FindByUserID(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, []factor.Definition{def}, defs)
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"tofunote-backend/domain/gratitude"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GratitudeRepository struct {
+	db *gorm.DB
+}
+
+func NewGratitudeRepository(db *gorm.DB) gratitude.Repository {
+	return &GratitudeRepository{db: db}
+}
+
+func (r *GratitudeRepository) FindByUserID(ctx context.Context, userID string) ([]gratitude.DailyHighlights, error) {
+	var models []db.DailyHighlightsModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("date").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainDailyHighlights(models), nil
+}
+
+func (r *GratitudeRepository) FindByUserIDAndDate(ctx context.Context, userID string, date string) (*gratitude.DailyHighlights, error) {
+	var model db.DailyHighlightsModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND date = ?", userID, date).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("指定された日付の良かったことが見つかりません")
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *GratitudeRepository) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]gratitude.DailyHighlights, error) {
+	var models []db.DailyHighlightsModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).Order("date").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainDailyHighlights(models), nil
+}
+
+func (r *GratitudeRepository) FindBefore(ctx context.Context, userID string, date string) ([]gratitude.DailyHighlights, error) {
+	var models []db.DailyHighlightsModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND date < ?", userID, date).Order("date").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainDailyHighlights(models), nil
+}
+
+func (r *GratitudeRepository) Upsert(ctx context.Context, h *gratitude.DailyHighlights) error {
+	if h.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		h.ID = id.String()
+	}
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"good_things", "highlight", "lowlight", "updated_at"}),
+	}).Create(db.DailyHighlightsFromDomain(h)).Error
+	if err != nil {
+		return err
+	}
+	// 既存の記録を上書きした場合はそのIDを返す
+	var saved db.DailyHighlightsModel
+	if err := conn(ctx, r.db).Select("id").Where("user_id = ? AND date = ?", h.UserID, h.Date).First(&saved).Error; err != nil {
+		return err
+	}
+	h.ID = saved.ID
+	return nil
+}
+
+func (r *GratitudeRepository) Delete(ctx context.Context, userID string, date string) error {
+	result := conn(ctx, r.db).Where("user_id = ? AND date = ?", userID, date).Delete(&db.DailyHighlightsModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された日付の良かったことが見つかりません")
+	}
+	return nil
+}
+
+// 指定ユーザーの全記録を削除
+func (r *GratitudeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.DailyHighlightsModel{}).Error
+}
+
+func toDomainDailyHighlights(models []db.DailyHighlightsModel) []gratitude.DailyHighlights {
+	records := make([]gratitude.DailyHighlights, 0, len(models))
+	for _, model := range models {
+		records = append(records, *model.ToDomain())
+	}
+	return records
+}
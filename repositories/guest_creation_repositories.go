@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type GuestCreationRepository struct {
+	db *gorm.DB
+}
+
+func NewGuestCreationRepository(db *gorm.DB) user.GuestCreationRepository {
+	return &GuestCreationRepository{db: db}
+}
+
+func (r *GuestCreationRepository) Record(ctx context.Context, creation *user.GuestCreation) error {
+	if creation.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		creation.ID = id.String()
+	}
+	model := db.GuestCreationFromDomain(creation)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") ||
+			strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return user.ErrGuestChallengeUsed
+		}
+		return err
+	}
+	creation.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *GuestCreationRepository) CountSince(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&db.GuestCreationModel{}).Where("created_at >= ?", since).Count(&count).Error
+	return count, err
+}
+
+func (r *GuestCreationRepository) CountByIPSince(ctx context.Context, ipHash string, since time.Time) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&db.GuestCreationModel{}).Where("ip_hash = ? AND created_at >= ?", ipHash, since).Count(&count).Error
+	return count, err
+}
+
+func (r *GuestCreationRepository) CountDistinctIPsSince(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&db.GuestCreationModel{}).Where("created_at >= ?", since).Distinct("ip_hash").Count(&count).Error
+	return count, err
+}
+
+func (r *GuestCreationRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("created_at < ?", before).Delete(&db.GuestCreationModel{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupGuestCreationTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.GuestCreationModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestGuestCreationRepository(t *testing.T) {
+	repo := NewGuestCreationRepository(setupGuestCreationTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.Record(ctx, &user.GuestCreation{IPHash: "ip1", CreatedAt: now.Add(-2 * time.Hour)}))
+	require.NoError(t, repo.Record(ctx, &user.GuestCreation{IPHash: "ip1", CreatedAt: now.Add(-time.Minute)}))
+	require.NoError(t, repo.Record(ctx, &user.GuestCreation{IPHash: "ip2", ChallengeID: "c1", CreatedAt: now}))
+	// 計算課題を求めていない作成（ChallengeIDが空）は一意制約の対象外
+	require.NoError(t, repo.Record(ctx, &user.GuestCreation{IPHash: "ip3", CreatedAt: now}))
+	assert.ErrorIs(t, repo.Record(ctx, &user.GuestCreation{IPHash: "ip3", ChallengeID: "c1", CreatedAt: now}), user.ErrGuestChallengeUsed)
+
+	since := now.Add(-time.Hour)
+	count, err := repo.CountSince(ctx, since)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	count, err = repo.CountByIPSince(ctx, "ip1", since)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = repo.CountDistinctIPsSince(ctx, since)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	deleted, err := repo.DeleteBefore(ctx, since)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	count, err = repo.CountSince(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"gorm.io/gorm"
+)
+
+// lastActiveExpr は最終利用日時（記録がない場合は作成日時）
+const lastActiveExpr = "COALESCE(last_seen_at, created_at)"
+
+type GuestRetentionRepository struct {
+	db *gorm.DB
+}
+
+func NewGuestRetentionRepository(db *gorm.DB) user.RetentionRepository {
+	return &GuestRetentionRepository{db: db}
+}
+
+func (r *GuestRetentionRepository) TouchLastSeen(ctx context.Context, userID string, now, staleBefore time.Time) error {
+	return conn(ctx, r.db).Model(&db.UserModel{}).
+		Where("id = ? AND (last_seen_at IS NULL OR last_seen_at < ? OR deletion_notice_at IS NOT NULL)", userID, staleBefore).
+		Updates(map[string]interface{}{"last_seen_at": now, "deletion_notice_at": nil}).Error
+}
+
+func (r *GuestRetentionRepository) FindGuestsToNotify(ctx context.Context, inactiveBefore time.Time, limit int) ([]*user.User, error) {
+	var users []*user.User
+	err := conn(ctx, r.db).
+		Where("is_guest = ? AND deletion_notice_at IS NULL AND "+lastActiveExpr+" < ?", true, inactiveBefore).
+		Order(lastActiveExpr + " ASC, id ASC").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func (r *GuestRetentionRepository) FindGuestsToDelete(ctx context.Context, noticedBefore time.Time, limit int) ([]*user.User, error) {
+	var users []*user.User
+	err := conn(ctx, r.db).
+		Where("is_guest = ? AND deletion_notice_at IS NOT NULL AND deletion_notice_at < ?", true, noticedBefore).
+		Order("deletion_notice_at ASC, id ASC").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func (r *GuestRetentionRepository) MarkDeletionNoticed(ctx context.Context, userID string, now, inactiveBefore time.Time) (bool, error) {
+	// 検索してから記録するまでの間に利用を再開したゲストには予告しない
+	result := conn(ctx, r.db).Model(&db.UserModel{}).
+		Where("id = ? AND is_guest = ? AND deletion_notice_at IS NULL AND "+lastActiveExpr+" < ?", userID, true, inactiveBefore).
+		Update("deletion_notice_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *GuestRetentionRepository) FindWithdrawnBefore(ctx context.Context, withdrawnBefore time.Time, limit int) ([]*user.User, error) {
+	var users []*user.User
+	err := conn(ctx, r.db).
+		Where("withdrawn_at IS NOT NULL AND withdrawn_at < ?", withdrawnBefore).
+		Order("withdrawn_at ASC, id ASC").Limit(limit).Find(&users).Error
+	return users, err
+}
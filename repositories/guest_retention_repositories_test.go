@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupGuestRetentionTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&user.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func userIDs(users []*user.User) []string {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+func TestGuestRetentionRepository(t *testing.T) {
+	gormDB := setupGuestRetentionTestDB(t)
+	repo := NewGuestRetentionRepository(gormDB)
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+
+	for _, u := range []*user.User{
+		{ID: "never-seen", IsGuest: true, CreatedAt: *daysAgo(120)},
+		{ID: "seen-long-ago", IsGuest: true, CreatedAt: *daysAgo(200), LastSeenAt: daysAgo(100)},
+		{ID: "active", IsGuest: true, CreatedAt: *daysAgo(200), LastSeenAt: daysAgo(1)},
+		{ID: "member", IsGuest: false, CreatedAt: *daysAgo(200)},
+	} {
+		require.NoError(t, gormDB.Create(u).Error)
+	}
+	inactiveBefore := now.AddDate(0, 0, -90)
+
+	candidates, err := repo.FindGuestsToNotify(ctx, inactiveBefore, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"never-seen", "seen-long-ago"}, userIDs(candidates), "最終利用日時の古い順")
+
+	noticed, err := repo.MarkDeletionNoticed(ctx, "never-seen", now, inactiveBefore)
+	require.NoError(t, err)
+	assert.True(t, noticed)
+	noticed, err = repo.MarkDeletionNoticed(ctx, "active", now, inactiveBefore)
+	require.NoError(t, err)
+	assert.False(t, noticed, "利用中のゲストには予告しない")
+
+	candidates, err = repo.FindGuestsToNotify(ctx, inactiveBefore, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"seen-long-ago"}, userIDs(candidates), "予告済みのゲストは対象外")
+
+	toDelete, err := repo.FindGuestsToDelete(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, toDelete, "猶予期間中は削除しない")
+	toDelete, err = repo.FindGuestsToDelete(ctx, now.Add(time.Second), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"never-seen"}, userIDs(toDelete))
+
+	t.Run("利用を再開すると予告を取り消す", func(t *testing.T) {
+		require.NoError(t, repo.TouchLastSeen(ctx, "never-seen", now, now.Add(-time.Hour)))
+		toDelete, err := repo.FindGuestsToDelete(ctx, now.Add(time.Second), 10)
+		require.NoError(t, err)
+		assert.Empty(t, toDelete)
+	})
+
+	t.Run("最終利用日時は一定間隔でのみ更新する", func(t *testing.T) {
+		later := now.Add(10 * time.Minute)
+		require.NoError(t, repo.TouchLastSeen(ctx, "active", later, later.Add(-time.Hour)))
+		require.NoError(t, repo.TouchLastSeen(ctx, "active", later.Add(time.Minute), later.Add(time.Minute-time.Hour)))
+		var found user.User
+		require.NoError(t, gormDB.First(&found, "id = ?", "active").Error)
+		require.NotNil(t, found.LastSeenAt)
+		assert.True(t, found.LastSeenAt.Equal(later))
+	})
+
+	t.Run("猶予期間を過ぎた退会の申請", func(t *testing.T) {
+		require.NoError(t, gormDB.Model(&user.User{}).Where("id = ?", "member").Update("withdrawn_at", now.AddDate(0, 0, -31)).Error)
+		withdrawn, err := repo.FindWithdrawnBefore(ctx, now.AddDate(0, 0, -30), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"member"}, userIDs(withdrawn))
+		withdrawn, err = repo.FindWithdrawnBefore(ctx, now.AddDate(0, 0, -32), 10)
+		require.NoError(t, err)
+		assert.Empty(t, withdrawn)
+	})
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"tofunote-backend/domain/medication"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MedicationRepository struct {
+	db *gorm.DB
+}
+
+func NewMedicationRepository(db *gorm.DB) medication.Repository {
+	return &MedicationRepository{db: db}
+}
+
+func (r *MedicationRepository) FindByUserID(ctx context.Context, userID string) ([]medication.Medication, error) {
+	var models []db.MedicationModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainMedications(models), nil
+}
+
+func (r *MedicationRepository) FindByID(ctx context.Context, userID string, id string) (*medication.Medication, error) {
+	var model db.MedicationModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND id = ?", userID, id).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("指定された薬が見つかりません")
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *MedicationRepository) FindRemindable(ctx context.Context) ([]medication.Medication, error) {
+	var models []db.MedicationModel
+	// 退会を申請したユーザーには猶予期間中も送らない
+	withdrawn := conn(ctx, r.db).Model(&db.UserModel{}).Select("id").Where("withdrawn_at IS NOT NULL")
+	if err := conn(ctx, r.db).Where("reminders_enabled = ? AND user_id NOT IN (?)", true, withdrawn).Order("user_id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainMedications(models), nil
+}
+
+func (r *MedicationRepository) Create(ctx context.Context, m *medication.Medication) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	m.ID = id.String()
+	return conn(ctx, r.db).Create(db.MedicationFromDomain(m)).Error
+}
+
+func (r *MedicationRepository) Update(ctx context.Context, m *medication.Medication) error {
+	model := db.MedicationFromDomain(m)
+	// 終了日の削除やリマインダーの無効化も反映するため、ゼロ値を含めて更新する
+	result := conn(ctx, r.db).Model(&db.MedicationModel{}).
+		Where("user_id = ? AND id = ?", m.UserID, m.ID).
+		Select("name", "dose", "schedule", "start_date", "end_date", "reminders_enabled", "updated_at").
+		Updates(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された薬が見つかりません")
+	}
+	return nil
+}
+
+func (r *MedicationRepository) Delete(ctx context.Context, userID string, id string) error {
+	result := conn(ctx, r.db).Where("user_id = ? AND id = ?", userID, id).Delete(&db.MedicationModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された薬が見つかりません")
+	}
+	return nil
+}
+
+// 指定ユーザーの全ての薬を削除
+func (r *MedicationRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.MedicationModel{}).Error
+}
+
+func toDomainMedications(models []db.MedicationModel) []medication.Medication {
+	meds := make([]medication.Medication, 0, len(models))
+	for _, model := range models {
+		meds = append(meds, *model.ToDomain())
+	}
+	return meds
+}
+
+type DoseLogRepository struct {
+	db *gorm.DB
+}
+
+func NewDoseLogRepository(db *gorm.DB) medication.DoseLogRepository {
+	return &DoseLogRepository{db: db}
+}
+
+func (r *DoseLogRepository) FindByUserID(ctx context.Context, userID string) ([]medication.DoseLog, error) {
+	var models []db.DoseLogModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("date").Order("scheduled_time").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainDoseLogs(models), nil
+}
+
+func (r *DoseLogRepository) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]medication.DoseLog, error) {
+	var models []db.DoseLogModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).
+		Order("date").Order("scheduled_time").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainDoseLogs(models), nil
+}
+
+func (r *DoseLogRepository) Upsert(ctx context.Context, l *medication.DoseLog) error {
+	if l.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		l.ID = id.String()
+	}
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "medication_id"}, {Name: "date"}, {Name: "scheduled_time"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "taken_at", "note", "updated_at"}),
+	}).Create(db.DoseLogFromDomain(l)).Error
+	if err != nil {
+		return err
+	}
+	// 既存の記録を上書きした場合はそのIDを返す
+	var saved db.DoseLogModel
+	if err := conn(ctx, r.db).Select("id").
+		Where("medication_id = ? AND date = ? AND scheduled_time = ?", l.MedicationID, l.Date, l.ScheduledTime).
+		First(&saved).Error; err != nil {
+		return err
+	}
+	l.ID = saved.ID
+	return nil
+}
+
+func (r *DoseLogRepository) Delete(ctx context.Context, userID string, medicationID string, date string, scheduledTime string) error {
+	result := conn(ctx, r.db).
+		Where("user_id = ? AND medication_id = ? AND date = ? AND scheduled_time = ?", userID, medicationID, date, scheduledTime).
+		Delete(&db.DoseLogModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された服用記録が見つかりません")
+	}
+	return nil
+}
+
+// 指定した薬の全服用記録を削除
+func (r *DoseLogRepository) DeleteByMedicationID(ctx context.Context, userID string, medicationID string) error {
+	return conn(ctx, r.db).Where("user_id = ? AND medication_id = ?", userID, medicationID).Delete(&db.DoseLogModel{}).Error
+}
+
+// 指定ユーザーの全服用記録を削除
+func (r *DoseLogRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.DoseLogModel{}).Error
+}
+
+func toDomainDoseLogs(models []db.DoseLogModel) []medication.DoseLog {
+	logs := make([]medication.DoseLog, 0, len(models))
+	for _, model := range models {
+		logs = append(logs, *model.ToDomain())
+	}
+	return logs
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/medication"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupMedicationTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.UserModel{}, &db.MedicationModel{}, &db.DoseLogModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestMedicationRepository_CreateAndUpdate(t *testing.T) {
+	repo := NewMedicationRepository(setupMedicationTestDB(t))
+	ctx := context.Background()
+
+	med := &medication.Medication{
+		UserID:           "u1",
+		Name:             "薬A",
+		Dose:             "1錠",
+		Schedule:         medication.Schedule{Times: []string{"08:00"}, Weekdays: []time.Weekday{time.Monday}},
+		StartDate:        "2025-05-01",
+		EndDate:          "2025-05-31",
+		RemindersEnabled: true,
+	}
+	assert.NoError(t, repo.Create(ctx, med))
+	assert.NotEmpty(t, med.ID)
+
+	found, err := repo.FindByID(ctx, "u1", med.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, med, found)
+
+	remindable, err := repo.FindRemindable(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, remindable, 1)
+
+	// 終了日の削除とリマインダーの無効化が反映される
+	med.EndDate = ""
+	med.RemindersEnabled = false
+	assert.NoError(t, repo.Update(ctx, med))
+	found, err = repo.FindByID(ctx, "u1", med.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", found.EndDate)
+	assert.False(t, found.RemindersEnabled)
+
+	// 他のユーザーの薬は取得・削除できない
+	_, err = repo.FindByID(ctx, "u2", med.ID)
+	assert.EqualError(t, err, "指定された薬が見つかりません")
+	assert.EqualError(t, repo.Delete(ctx, "u2", med.ID), "指定された薬が見つかりません")
+}
+
+func TestMedicationRepository_FindRemindableExcludesWithdrawn(t *testing.T) {
+	gormDB := setupMedicationTestDB(t)
+	repo := NewMedicationRepository(gormDB)
+	ctx := context.Background()
+	for _, userID := range []string{"u1", "u2"} {
+		assert.NoError(t, repo.Create(ctx, &medication.Medication{
+			UserID:           userID,
+			Name:             "薬A",
+			Schedule:         medication.Schedule{Times: []string{"08:00"}},
+			StartDate:        "2025-05-01",
+			RemindersEnabled: true,
+		}))
+	}
+	// 退会を申請したユーザーには猶予期間中も送らない
+	withdrawnAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, gormDB.Create(&db.UserModel{ID: "u2", WithdrawnAt: &withdrawnAt}).Error)
+
+	remindable, err := repo.FindRemindable(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, remindable, 1) {
+		assert.Equal(t, "u1", remindable[0].UserID)
+	}
+}
+
+func TestDoseLogRepository_Upsert(t *testing.T) {
+	repo := NewDoseLogRepository(setupMedicationTestDB(t))
+	ctx := context.Background()
+
+	first := &medication.DoseLog{UserID: "u1", MedicationID: "m1", Date: "2025-05-01", ScheduledTime: "08:00", Status: medication.DoseSkipped}
+	assert.NoError(t, repo.Upsert(ctx, first))
+	assert.NotEmpty(t, first.ID)
+
+	// 同じ服用は上書きされ、IDは維持される
+	second := &medication.DoseLog{UserID: "u1", MedicationID: "m1", Date: "2025-05-01", ScheduledTime: "08:00", Status: medication.DoseTaken}
+	assert.NoError(t, repo.Upsert(ctx, second))
+	assert.Equal(t, first.ID, second.ID)
+
+	logs, err := repo.FindByUserIDAndDateRange(ctx, "u1", "2025-05-01", "2025-05-31")
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, medication.DoseTaken, logs[0].Status)
+
+	assert.NoError(t, repo.Delete(ctx, "u1", "m1", "2025-05-01", "08:00"))
+	assert.EqualError(t, repo.Delete(ctx, "u1", "m1", "2025-05-01", "08:00"), "指定された服用記録が見つかりません")
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type OneTimeTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewOneTimeTokenRepository(db *gorm.DB) auth.OneTimeTokenRepository {
+	return &OneTimeTokenRepository{db: db}
+}
+
+func (r *OneTimeTokenRepository) Create(ctx context.Context, token *auth.OneTimeToken) error {
+	if token.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		token.ID = id.String()
+	}
+	model := db.OneTimeTokenFromDomain(token)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	token.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// Consume は条件付きのUPDATEで使用済みにするため、同じトークンが同時に使われても成功するのは1回だけになる
+func (r *OneTimeTokenRepository) Consume(ctx context.Context, purpose auth.TokenPurpose, tokenHash string, now time.Time) (*auth.OneTimeToken, error) {
+	result := conn(ctx, r.db).Model(&db.OneTimeTokenModel{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, string(purpose), now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, auth.ErrInvalidOneTimeToken
+	}
+	var model db.OneTimeTokenModel
+	if err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidOneTimeToken
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *OneTimeTokenRepository) InvalidateByUserID(ctx context.Context, userID string, purpose auth.TokenPurpose, now time.Time) error {
+	return conn(ctx, r.db).Model(&db.OneTimeTokenModel{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, string(purpose)).
+		Update("used_at", now).Error
+}
+
+func (r *OneTimeTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.OneTimeTokenModel{}).Error
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupOneTimeTokenTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.OneTimeTokenModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestOneTimeTokenRepository_Consume(t *testing.T) {
+	repo := NewOneTimeTokenRepository(setupOneTimeTokenTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.Create(ctx, &auth.OneTimeToken{UserID: "u1", Purpose: auth.PurposeResetPassword, TokenHash: auth.HashToken("valid"), ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.Create(ctx, &auth.OneTimeToken{UserID: "u1", Purpose: auth.PurposeResetPassword, TokenHash: auth.HashToken("expired"), ExpiresAt: now.Add(-time.Minute)}))
+
+	// 用途が異なる場合は使えない
+	_, err := repo.Consume(ctx, auth.PurposeVerifyEmail, auth.HashToken("valid"), now)
+	assert.ErrorIs(t, err, auth.ErrInvalidOneTimeToken)
+
+	token, err := repo.Consume(ctx, auth.PurposeResetPassword, auth.HashToken("valid"), now)
+	require.NoError(t, err)
+	assert.Equal(t, "u1", token.UserID)
+	assert.NotNil(t, token.UsedAt)
+
+	// 2回目は使用済みのため失敗する
+	_, err = repo.Consume(ctx, auth.PurposeResetPassword, auth.HashToken("valid"), now)
+	assert.ErrorIs(t, err, auth.ErrInvalidOneTimeToken)
+
+	_, err = repo.Consume(ctx, auth.PurposeResetPassword, auth.HashToken("expired"), now)
+	assert.ErrorIs(t, err, auth.ErrInvalidOneTimeToken)
+}
+
+func TestOneTimeTokenRepository_InvalidateByUserID(t *testing.T) {
+	repo := NewOneTimeTokenRepository(setupOneTimeTokenTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.Create(ctx, &auth.OneTimeToken{UserID: "u1", Purpose: auth.PurposeVerifyEmail, TokenHash: auth.HashToken("old"), ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.Create(ctx, &auth.OneTimeToken{UserID: "u1", Purpose: auth.PurposeResetPassword, TokenHash: auth.HashToken("reset"), ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.InvalidateByUserID(ctx, "u1", auth.PurposeVerifyEmail, now))
+
+	_, err := repo.Consume(ctx, auth.PurposeVerifyEmail, auth.HashToken("old"), now)
+	assert.ErrorIs(t, err, auth.ErrInvalidOneTimeToken)
+	_, err = repo.Consume(ctx, auth.PurposeResetPassword, auth.HashToken("reset"), now)
+	assert.NoError(t, err)
+}
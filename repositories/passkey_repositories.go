@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type PasskeyRepository struct {
+	db *gorm.DB
+}
+
+func NewPasskeyRepository(db *gorm.DB) auth.PasskeyRepository {
+	return &PasskeyRepository{db: db}
+}
+
+func (r *PasskeyRepository) Create(ctx context.Context, passkey *auth.Passkey) error {
+	if passkey.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		passkey.ID = id.String()
+	}
+	model := db.PasskeyFromDomain(passkey)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") ||
+			strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return auth.ErrPasskeyAlreadyRegistered
+		}
+		return err
+	}
+	passkey.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *PasskeyRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*auth.Passkey, error) {
+	var model db.PasskeyModel
+	if err := conn(ctx, r.db).Where("credential_id = ?", credentialID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrPasskeyNotFound
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *PasskeyRepository) ListByUserID(ctx context.Context, userID string) ([]*auth.Passkey, error) {
+	var models []db.PasskeyModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	passkeys := make([]*auth.Passkey, len(models))
+	for i := range models {
+		passkeys[i] = models[i].ToDomain()
+	}
+	return passkeys, nil
+}
+
+func (r *PasskeyRepository) UpdateSignCount(ctx context.Context, id string, signCount uint32, now time.Time) error {
+	return conn(ctx, r.db).Model(&db.PasskeyModel{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"sign_count": int64(signCount), "last_used_at": now}).Error
+}
+
+func (r *PasskeyRepository) Rename(ctx context.Context, userID, id, name string) error {
+	result := conn(ctx, r.db).Model(&db.PasskeyModel{}).Where("id = ? AND user_id = ?", id, userID).Update("name", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (r *PasskeyRepository) Delete(ctx context.Context, userID, id string) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&db.PasskeyModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (r *PasskeyRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.PasskeyModel{}).Error
+}
+
+type PasskeyChallengeRepository struct {
+	db *gorm.DB
+}
+
+func NewPasskeyChallengeRepository(db *gorm.DB) auth.PasskeyChallengeRepository {
+	return &PasskeyChallengeRepository{db: db}
+}
+
+func (r *PasskeyChallengeRepository) Create(ctx context.Context, challenge *auth.PasskeyChallenge) error {
+	if challenge.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		challenge.ID = id.String()
+	}
+	model := db.PasskeyChallengeFromDomain(challenge)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	challenge.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// Consume は条件付きのUPDATEで使用済みにするため、同じチャレンジで同時に完了しても成功するのは1回だけになる
+func (r *PasskeyChallengeRepository) Consume(ctx context.Context, id string, ceremony auth.PasskeyCeremony, now time.Time) (*auth.PasskeyChallenge, error) {
+	result := conn(ctx, r.db).Model(&db.PasskeyChallengeModel{}).
+		Where("id = ? AND ceremony = ? AND used_at IS NULL AND expires_at > ?", id, string(ceremony), now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, auth.ErrInvalidPasskeyChallenge
+	}
+	var model db.PasskeyChallengeModel
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidPasskeyChallenge
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *PasskeyChallengeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.PasskeyChallengeModel{}).Error
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPasskeyTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.PasskeyModel{}, &db.PasskeyChallengeModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestPasskeyRepository(t *testing.T) {
+	repo := NewPasskeyRepository(setupPasskeyTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	passkey := &auth.Passkey{UserID: "u1", Name: "iPhone", CredentialID: []byte{1, 2, 3}, PublicKey: []byte{0xa5}, Algorithm: auth.COSEAlgES256, Transports: []string{"internal", "hybrid"}}
+	require.NoError(t, repo.Create(ctx, passkey))
+	assert.NotEmpty(t, passkey.ID)
+
+	t.Run("異常系: 同じクレデンシャルIDは登録できない", func(t *testing.T) {
+		err := repo.Create(ctx, &auth.Passkey{UserID: "u2", Name: "key", CredentialID: []byte{1, 2, 3}, PublicKey: []byte{0xa5}})
+		assert.ErrorIs(t, err, auth.ErrPasskeyAlreadyRegistered)
+	})
+
+	found, err := repo.FindByCredentialID(ctx, []byte{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, passkey.ID, found.ID)
+	assert.Equal(t, []string{"internal", "hybrid"}, found.Transports)
+	_, err = repo.FindByCredentialID(ctx, []byte{9})
+	assert.ErrorIs(t, err, auth.ErrPasskeyNotFound)
+
+	require.NoError(t, repo.UpdateSignCount(ctx, passkey.ID, 7, now))
+	require.NoError(t, repo.Rename(ctx, "u1", passkey.ID, "仕事用iPhone"))
+	list, err := repo.ListByUserID(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, uint32(7), list[0].SignCount)
+	assert.Equal(t, "仕事用iPhone", list[0].Name)
+	require.NotNil(t, list[0].LastUsedAt)
+
+	t.Run("異常系: 他のユーザーのパスキーは変更・削除できない", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Rename(ctx, "u2", passkey.ID, "x"), auth.ErrPasskeyNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, "u2", passkey.ID), auth.ErrPasskeyNotFound)
+	})
+
+	require.NoError(t, repo.Delete(ctx, "u1", passkey.ID))
+	list, err = repo.ListByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestPasskeyChallengeRepository_Consume(t *testing.T) {
+	repo := NewPasskeyChallengeRepository(setupPasskeyTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	login := &auth.PasskeyChallenge{Ceremony: auth.PasskeyLogin, Challenge: []byte("c1"), ExpiresAt: now.Add(time.Minute)}
+	expired := &auth.PasskeyChallenge{Ceremony: auth.PasskeyRegistration, UserID: "u1", Challenge: []byte("c2"), ExpiresAt: now.Add(-time.Second)}
+	require.NoError(t, repo.Create(ctx, login))
+	require.NoError(t, repo.Create(ctx, expired))
+
+	_, err := repo.Consume(ctx, login.ID, auth.PasskeyRegistration, now)
+	assert.ErrorIs(t, err, auth.ErrInvalidPasskeyChallenge, "種類が異なるチャレンジは使えない")
+
+	consumed, err := repo.Consume(ctx, login.ID, auth.PasskeyLogin, now)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("c1"), consumed.Challenge)
+	assert.Empty(t, consumed.UserID)
+
+	_, err = repo.Consume(ctx, login.ID, auth.PasskeyLogin, now)
+	assert.ErrorIs(t, err, auth.ErrInvalidPasskeyChallenge, "使用済み")
+	_, err = repo.Consume(ctx, expired.ID, auth.PasskeyRegistration, now)
+	assert.ErrorIs(t, err, auth.ErrInvalidPasskeyChallenge, "期限切れ")
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) auth.PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{db: db}
+}
+
+func (r *PersonalAccessTokenRepository) Create(ctx context.Context, token *auth.PersonalAccessToken) error {
+	if token.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		token.ID = id.String()
+	}
+	model := db.PersonalAccessTokenFromDomain(token)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	token.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *PersonalAccessTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*auth.PersonalAccessToken, error) {
+	var model db.PersonalAccessTokenModel
+	if err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidPersonalAccessToken
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *PersonalAccessTokenRepository) ListByUserID(ctx context.Context, userID string) ([]*auth.PersonalAccessToken, error) {
+	var models []db.PersonalAccessTokenModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	tokens := make([]*auth.PersonalAccessToken, len(models))
+	for i := range models {
+		tokens[i] = models[i].ToDomain()
+	}
+	return tokens, nil
+}
+
+func (r *PersonalAccessTokenRepository) TouchLastUsed(ctx context.Context, id string, now, staleBefore time.Time) error {
+	return conn(ctx, r.db).Model(&db.PersonalAccessTokenModel{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, staleBefore).
+		Update("last_used_at", now).Error
+}
+
+func (r *PersonalAccessTokenRepository) Delete(ctx context.Context, userID, id string) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&db.PersonalAccessTokenModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrPersonalAccessTokenNotFound
+	}
+	return nil
+}
+
+func (r *PersonalAccessTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.PersonalAccessTokenModel{}).Error
+}
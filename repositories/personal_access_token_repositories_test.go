@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPersonalAccessTokenTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.PersonalAccessTokenModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestPersonalAccessTokenRepository(t *testing.T) {
+	repo := NewPersonalAccessTokenRepository(setupPersonalAccessTokenTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	token := &auth.PersonalAccessToken{
+		UserID:    "u1",
+		Name:      "インポート用",
+		TokenHash: auth.HashToken("raw"),
+		Scopes:    []auth.Scope{auth.ScopeDiariesRead, auth.ScopeDiariesWrite},
+		ExpiresAt: now.Add(24 * time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, token))
+	assert.NotEmpty(t, token.ID)
+
+	found, err := repo.FindByHash(ctx, auth.HashToken("raw"))
+	require.NoError(t, err)
+	assert.Equal(t, []auth.Scope{auth.ScopeDiariesRead, auth.ScopeDiariesWrite}, found.Scopes)
+	assert.Nil(t, found.LastUsedAt)
+	_, err = repo.FindByHash(ctx, auth.HashToken("other"))
+	assert.ErrorIs(t, err, auth.ErrInvalidPersonalAccessToken)
+
+	t.Run("最終利用日時は一定間隔でのみ更新する", func(t *testing.T) {
+		require.NoError(t, repo.TouchLastUsed(ctx, token.ID, now, now.Add(-time.Minute)))
+		require.NoError(t, repo.TouchLastUsed(ctx, token.ID, now.Add(30*time.Second), now.Add(-30*time.Second)))
+		found, err := repo.FindByHash(ctx, auth.HashToken("raw"))
+		require.NoError(t, err)
+		require.NotNil(t, found.LastUsedAt)
+		assert.True(t, found.LastUsedAt.Equal(now))
+	})
+
+	t.Run("他のユーザーのトークンは取り消せない", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Delete(ctx, "u2", token.ID), auth.ErrPersonalAccessTokenNotFound)
+		require.NoError(t, repo.Delete(ctx, "u1", token.ID))
+		tokens, err := repo.ListByUserID(ctx, "u1")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type PushSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewPushSubscriptionRepository(db *gorm.DB) notification.PushSubscriptionRepository {
+	return &PushSubscriptionRepository{db: db}
+}
+
+func (r *PushSubscriptionRepository) FindByUserID(ctx context.Context, userID string) ([]notification.PushSubscription, error) {
+	var models []db.PushSubscriptionModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	subscriptions := make([]notification.PushSubscription, len(models))
+	for i := range models {
+		subscriptions[i] = models[i].ToDomain()
+	}
+	return subscriptions, nil
+}
+
+func (r *PushSubscriptionRepository) Save(ctx context.Context, s *notification.PushSubscription) error {
+	var existing db.PushSubscriptionModel
+	err := conn(ctx, r.db).Where("endpoint = ?", s.Endpoint).First(&existing).Error
+	if err == nil {
+		// 同じブラウザの再登録（鍵の更新・別のユーザーでのログイン）は既存の購読を上書きする
+		s.ID = existing.ID
+		s.CreatedAt = existing.CreatedAt
+		return conn(ctx, r.db).Model(&existing).Updates(map[string]interface{}{
+			"user_id":    s.UserID,
+			"p256dh":     s.P256dh,
+			"auth":       s.Auth,
+			"user_agent": s.UserAgent,
+		}).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if s.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		s.ID = id.String()
+	}
+	model := db.PushSubscriptionFromDomain(s)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	s.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *PushSubscriptionRepository) Delete(ctx context.Context, userID, id string) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&db.PushSubscriptionModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notification.ErrPushSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *PushSubscriptionRepository) DeleteByEndpoint(ctx context.Context, endpoint string) error {
+	return conn(ctx, r.db).Where("endpoint = ?", endpoint).Delete(&db.PushSubscriptionModel{}).Error
+}
+
+func (r *PushSubscriptionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.PushSubscriptionModel{}).Error
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPushSubscriptionTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.PushSubscriptionModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestPushSubscriptionRepository(t *testing.T) {
+	gormDB := setupPushSubscriptionTestDB(t)
+	repo := NewPushSubscriptionRepository(gormDB)
+	ctx := context.Background()
+	base := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	models := []db.PushSubscriptionModel{
+		{ID: "00000000-0000-0000-0000-000000000002", UserID: "u1", Endpoint: "https://push.example.com/2", P256dh: "key2", Auth: "auth2", CreatedAt: base.Add(time.Hour)},
+		{ID: "00000000-0000-0000-0000-000000000001", UserID: "u1", Endpoint: "https://push.example.com/1", P256dh: "key1", Auth: "auth1", CreatedAt: base},
+		{ID: "00000000-0000-0000-0000-000000000003", UserID: "u2", Endpoint: "https://push.example.com/3", P256dh: "key3", Auth: "auth3", CreatedAt: base},
+	}
+	require.NoError(t, gormDB.Create(&models).Error)
+
+	got, err := repo.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "https://push.example.com/1", got[0].Endpoint)
+	assert.Equal(t, "key1", got[0].P256dh)
+	assert.Equal(t, "auth1", got[0].Auth)
+
+	require.NoError(t, repo.DeleteByUserID(ctx, "u1"))
+	got, err = repo.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = repo.FindByUserID(ctx, "u2")
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestPushSubscriptionRepository_SaveAndDelete(t *testing.T) {
+	gormDB := setupPushSubscriptionTestDB(t)
+	repo := NewPushSubscriptionRepository(gormDB)
+	ctx := context.Background()
+
+	first := &notification.PushSubscription{UserID: "u1", Endpoint: "https://push.example.com/1", P256dh: "key1", Auth: "auth1", UserAgent: "Firefox"}
+	require.NoError(t, repo.Save(ctx, first))
+	assert.NotEmpty(t, first.ID)
+
+	// 同じブラウザで別のユーザーが登録し直すと、既存の購読を付け替える
+	again := &notification.PushSubscription{UserID: "u2", Endpoint: "https://push.example.com/1", P256dh: "key1b", Auth: "auth1b"}
+	require.NoError(t, repo.Save(ctx, again))
+	assert.Equal(t, first.ID, again.ID)
+	got, err := repo.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = repo.FindByUserID(ctx, "u2")
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "key1b", got[0].P256dh)
+
+	assert.ErrorIs(t, repo.Delete(ctx, "u1", first.ID), notification.ErrPushSubscriptionNotFound)
+	require.NoError(t, repo.Delete(ctx, "u2", first.ID))
+
+	require.NoError(t, repo.Save(ctx, &notification.PushSubscription{UserID: "u1", Endpoint: "https://push.example.com/2", P256dh: "key2", Auth: "auth2"}))
+	require.NoError(t, repo.DeleteByEndpoint(ctx, "https://push.example.com/2"))
+	got, err = repo.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
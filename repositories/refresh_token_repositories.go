@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) auth.RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *auth.RefreshToken) error {
+	return createRefreshToken(conn(ctx, r.db), token)
+}
+
+func createRefreshToken(tx *gorm.DB, token *auth.RefreshToken) error {
+	if token.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		token.ID = id.String()
+	}
+	// 新しいファミリーは最初のトークンのIDをファミリーIDにする
+	if token.FamilyID == "" {
+		token.FamilyID = token.ID
+	}
+	model := db.RefreshTokenFromDomain(token)
+	if err := tx.Create(model).Error; err != nil {
+		return err
+	}
+	token.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*auth.RefreshToken, error) {
+	var model db.RefreshTokenModel
+	if err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+// Rotate は条件付きのUPDATEで交換済みにするため、同じトークンで同時に更新されても成功するのは1回だけになる
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, currentID string, next *auth.RefreshToken, now time.Time) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&db.RefreshTokenModel{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", currentID).
+			Update("rotated_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return auth.ErrRefreshTokenReused
+		}
+		return createRefreshToken(tx, next)
+	})
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	return conn(ctx, r.db).Model(&db.RefreshTokenModel{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+func (r *RefreshTokenRepository) RevokeByUserID(ctx context.Context, userID string, now time.Time) error {
+	return conn(ctx, r.db).Model(&db.RefreshTokenModel{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+func (r *RefreshTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.RefreshTokenModel{}).Error
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRefreshTokenTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.RefreshTokenModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestRefreshTokenRepository_Rotate(t *testing.T) {
+	repo := NewRefreshTokenRepository(setupRefreshTokenTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first := &auth.RefreshToken{UserID: "u1", TokenHash: auth.HashToken("first"), DeviceLabel: "iPhone", ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, repo.Create(ctx, first))
+	assert.Equal(t, first.ID, first.FamilyID)
+
+	second := &auth.RefreshToken{UserID: "u1", FamilyID: first.FamilyID, TokenHash: auth.HashToken("second"), ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, repo.Rotate(ctx, first.ID, second, now))
+
+	found, err := repo.FindByHash(ctx, auth.HashToken("first"))
+	require.NoError(t, err)
+	require.NotNil(t, found.RotatedAt)
+	assert.True(t, found.RotatedAt.Equal(now))
+
+	// 交換済みのトークンは再度交換できず、次のトークンも保存されない
+	third := &auth.RefreshToken{UserID: "u1", FamilyID: first.FamilyID, TokenHash: auth.HashToken("third"), ExpiresAt: now.Add(time.Hour)}
+	assert.ErrorIs(t, repo.Rotate(ctx, first.ID, third, now), auth.ErrRefreshTokenReused)
+	_, err = repo.FindByHash(ctx, auth.HashToken("third"))
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+
+	require.NoError(t, repo.RevokeFamily(ctx, first.FamilyID, now))
+	found, err = repo.FindByHash(ctx, auth.HashToken("second"))
+	require.NoError(t, err)
+	assert.NotNil(t, found.RevokedAt)
+	assert.Equal(t, first.FamilyID, found.FamilyID)
+}
+
+func TestRefreshTokenRepository_RevokeByUserID(t *testing.T) {
+	repo := NewRefreshTokenRepository(setupRefreshTokenTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Create(ctx, &auth.RefreshToken{UserID: "u1", TokenHash: auth.HashToken("a"), ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.Create(ctx, &auth.RefreshToken{UserID: "u1", TokenHash: auth.HashToken("b"), ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.Create(ctx, &auth.RefreshToken{UserID: "u2", TokenHash: auth.HashToken("c"), ExpiresAt: now.Add(time.Hour)}))
+
+	require.NoError(t, repo.RevokeByUserID(ctx, "u1", now))
+	for _, raw := range []string{"a", "b"} {
+		found, err := repo.FindByHash(ctx, auth.HashToken(raw))
+		require.NoError(t, err)
+		assert.NotNil(t, found.RevokedAt)
+	}
+	found, err := repo.FindByHash(ctx, auth.HashToken("c"))
+	require.NoError(t, err)
+	assert.Nil(t, found.RevokedAt)
+}
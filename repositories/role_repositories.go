@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) user.RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) ListByUserID(ctx context.Context, userID string) ([]user.Role, error) {
+	return listRoles(conn(ctx, r.db), userID)
+}
+
+func (r *RoleRepository) Grant(ctx context.Context, userID string, role user.Role) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&db.UserRoleModel{UserID: userID, Role: string(role)}).Error
+}
+
+func (r *RoleRepository) Revoke(ctx context.Context, userID string, role user.Role) error {
+	return conn(ctx, r.db).Where("user_id = ? AND role = ?", userID, string(role)).Delete(&db.UserRoleModel{}).Error
+}
+
+func (r *RoleRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.UserRoleModel{}).Error
+}
+
+// listRoles はユーザーに付与されたロールを付与順に返す
+func listRoles(tx *gorm.DB, userID string) ([]user.Role, error) {
+	var models []db.UserRoleModel
+	if err := tx.Where("user_id = ?", userID).Order("created_at ASC, role ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+	roles := make([]user.Role, len(models))
+	for i, m := range models {
+		roles[i] = user.Role(m.Role)
+	}
+	return roles, nil
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) auth.SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *auth.Session) error {
+	if session.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		session.ID = id.String()
+	}
+	model := db.SessionFromDomain(session)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	session.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *SessionRepository) Touch(ctx context.Context, id string, client auth.ClientInfo, expiresAt, now time.Time) error {
+	updates := map[string]interface{}{
+		"last_used_at": now,
+		"expires_at":   expiresAt,
+		"user_agent":   client.UserAgent,
+		"ip_address":   client.IPAddress,
+	}
+	if client.DeviceLabel != "" {
+		updates["device_label"] = client.DeviceLabel
+	}
+	return conn(ctx, r.db).Model(&db.SessionModel{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *SessionRepository) ListActiveByUserID(ctx context.Context, userID string, now time.Time) ([]*auth.Session, error) {
+	var models []db.SessionModel
+	if err := conn(ctx, r.db).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+	sessions := make([]*auth.Session, len(models))
+	for i := range models {
+		sessions[i] = models[i].ToDomain()
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, userID, id string, now time.Time) error {
+	result := conn(ctx, r.db).Model(&db.SessionModel{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllByUserID(ctx context.Context, userID string, now time.Time) error {
+	return conn(ctx, r.db).Model(&db.SessionModel{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+func (r *SessionRepository) ListRevokedIDsSince(ctx context.Context, since time.Time) ([]string, error) {
+	var ids []string
+	if err := conn(ctx, r.db).Model(&db.SessionModel{}).
+		Where("revoked_at >= ?", since).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	if err := conn(ctx, r.db).Where("user_id = ? AND revoked_at IS NULL", userID).Delete(&db.SessionModel{}).Error; err != nil {
+		return err
+	}
+	return scrubRevokedSessions(conn(ctx, r.db), userID)
+}
+
+// scrubRevokedSessions は失効済みのセッションの端末の情報を消す（失効の判定に使うIDと失効日時は残す）
+func scrubRevokedSessions(tx *gorm.DB, userID string) error {
+	return tx.Model(&db.SessionModel{}).
+		Where("user_id = ? AND revoked_at IS NOT NULL", userID).
+		Updates(map[string]interface{}{"device_label": "", "user_agent": "", "ip_address": ""}).Error
+}
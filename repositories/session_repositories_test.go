@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupSessionTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.SessionModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestSessionRepository(t *testing.T) {
+	repo := NewSessionRepository(setupSessionTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	phone := &auth.Session{UserID: "u1", DeviceLabel: "iPhone", LastUsedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)}
+	laptop := &auth.Session{UserID: "u1", DeviceLabel: "PC", LastUsedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}
+	expired := &auth.Session{UserID: "u1", LastUsedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	other := &auth.Session{UserID: "u2", LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+	for _, s := range []*auth.Session{phone, laptop, expired, other} {
+		require.NoError(t, repo.Create(ctx, s))
+	}
+
+	sessions, err := repo.ListActiveByUserID(ctx, "u1", now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, laptop.ID, sessions[0].ID)
+
+	// 最終利用日時を更新すると並び順が変わる
+	require.NoError(t, repo.Touch(ctx, phone.ID, auth.ClientInfo{UserAgent: "Safari", IPAddress: "192.0.2.1"}, now.Add(2*time.Hour), now))
+	sessions, err = repo.ListActiveByUserID(ctx, "u1", now)
+	require.NoError(t, err)
+	assert.Equal(t, phone.ID, sessions[0].ID)
+	assert.Equal(t, "iPhone", sessions[0].DeviceLabel)
+	assert.Equal(t, "Safari", sessions[0].UserAgent)
+	assert.Equal(t, "192.0.2.1", sessions[0].IPAddress)
+
+	t.Run("異常系: 他のユーザーのセッションは失効できない", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Revoke(ctx, "u2", phone.ID, now), auth.ErrSessionNotFound)
+	})
+
+	require.NoError(t, repo.Revoke(ctx, "u1", phone.ID, now))
+	assert.ErrorIs(t, repo.Revoke(ctx, "u1", phone.ID, now), auth.ErrSessionNotFound)
+	sessions, err = repo.ListActiveByUserID(ctx, "u1", now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	require.NoError(t, repo.RevokeAllByUserID(ctx, "u1", now.Add(time.Minute)))
+	sessions, err = repo.ListActiveByUserID(ctx, "u1", now)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	ids, err := repo.ListRevokedIDsSince(ctx, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{laptop.ID, expired.ID}, ids)
+
+	t.Run("削除しても失効済みのセッションは失効の判定のために残す", func(t *testing.T) {
+		require.NoError(t, repo.DeleteByUserID(ctx, "u1"))
+		require.NoError(t, repo.DeleteByUserID(ctx, "u2"))
+		ids, err := repo.ListRevokedIDsSince(ctx, now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{phone.ID, laptop.ID, expired.ID}, ids)
+		var kept db.SessionModel
+		require.NoError(t, repo.(*SessionRepository).db.First(&kept, "id = ?", phone.ID).Error)
+		assert.Empty(t, kept.UserAgent, "端末の情報は消す")
+		assert.Empty(t, kept.IPAddress)
+		assert.Empty(t, kept.DeviceLabel)
+		// 失効していないセッションはそのまま削除する
+		err = repo.(*SessionRepository).db.First(&kept, "id = ?", other.ID).Error
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
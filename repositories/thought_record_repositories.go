@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"tofunote-backend/domain/thoughtrecord"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type ThoughtRecordRepository struct {
+	db *gorm.DB
+}
+
+func NewThoughtRecordRepository(db *gorm.DB) thoughtrecord.Repository {
+	return &ThoughtRecordRepository{db: db}
+}
+
+func (r *ThoughtRecordRepository) FindByUserID(ctx context.Context, userID string) ([]thoughtrecord.ThoughtRecord, error) {
+	var models []db.ThoughtRecordModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainThoughtRecords(models), nil
+}
+
+func (r *ThoughtRecordRepository) FindByUserIDAndDate(ctx context.Context, userID string, date string) ([]thoughtrecord.ThoughtRecord, error) {
+	var models []db.ThoughtRecordModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND date = ?", userID, date).Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainThoughtRecords(models), nil
+}
+
+func (r *ThoughtRecordRepository) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]thoughtrecord.ThoughtRecord, error) {
+	var models []db.ThoughtRecordModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).
+		Order("date").Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toDomainThoughtRecords(models), nil
+}
+
+func (r *ThoughtRecordRepository) FindByID(ctx context.Context, userID string, id string) (*thoughtrecord.ThoughtRecord, error) {
+	var model db.ThoughtRecordModel
+	if err := conn(ctx, r.db).Where("user_id = ? AND id = ?", userID, id).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("指定された思考記録が見つかりません")
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *ThoughtRecordRepository) Create(ctx context.Context, record *thoughtrecord.ThoughtRecord) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	record.ID = id.String()
+	model := db.ThoughtRecordFromDomain(record)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	record.CreatedAt, record.UpdatedAt = model.CreatedAt, model.UpdatedAt
+	return nil
+}
+
+func (r *ThoughtRecordRepository) Update(ctx context.Context, record *thoughtrecord.ThoughtRecord) error {
+	model := db.ThoughtRecordFromDomain(record)
+	// 日記との紐づけ解除や空欄への変更も反映するため、ゼロ値を含めて更新する
+	result := conn(ctx, r.db).Model(&db.ThoughtRecordModel{}).
+		Where("user_id = ? AND id = ?", record.UserID, record.ID).
+		Select("date", "situation", "automatic_thought", "emotions", "evidence_for", "evidence_against", "balanced_thought", "updated_at").
+		Updates(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された思考記録が見つかりません")
+	}
+	record.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (r *ThoughtRecordRepository) Delete(ctx context.Context, userID string, id string) error {
+	result := conn(ctx, r.db).Where("user_id = ? AND id = ?", userID, id).Delete(&db.ThoughtRecordModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("指定された思考記録が見つかりません")
+	}
+	return nil
+}
+
+// 指定ユーザーの全思考記録を削除
+func (r *ThoughtRecordRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.ThoughtRecordModel{}).Error
+}
+
+func toDomainThoughtRecords(models []db.ThoughtRecordModel) []thoughtrecord.ThoughtRecord {
+	records := make([]thoughtrecord.ThoughtRecord, 0, len(models))
+	for _, model := range models {
+		records = append(records, *model.ToDomain())
+	}
+	return records
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"tofunote-backend/domain/thoughtrecord"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupThoughtRecordTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.ThoughtRecordModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestThoughtRecordRepository_CRUD(t *testing.T) {
+	repo := NewThoughtRecordRepository(setupThoughtRecordTestDB(t))
+	ctx := context.Background()
+	after := 30
+
+	record := &thoughtrecord.ThoughtRecord{
+		UserID:           "u1",
+		Date:             "2025-05-01",
+		Situation:        "状況",
+		AutomaticThought: "自動思考",
+		Emotions:         []thoughtrecord.EmotionRating{{Key: "anxiety", Before: 80, After: &after}},
+	}
+	assert.NoError(t, repo.Create(ctx, record))
+	assert.NotEmpty(t, record.ID)
+
+	found, err := repo.FindByID(ctx, "u1", record.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-05-01", found.Date)
+	assert.Equal(t, record.Emotions, found.Emotions)
+
+	byDate, err := repo.FindByUserIDAndDate(ctx, "u1", "2025-05-01")
+	assert.NoError(t, err)
+	assert.Len(t, byDate, 1)
+
+	// 日記との紐づけを解除できる
+	record.Date = ""
+	record.BalancedThought = "バランス思考"
+	assert.NoError(t, repo.Update(ctx, record))
+	found, err = repo.FindByID(ctx, "u1", record.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "", found.Date)
+	assert.Equal(t, "バランス思考", found.BalancedThought)
+
+	// 他のユーザーの思考記録は操作できない
+	_, err = repo.FindByID(ctx, "u2", record.ID)
+	assert.EqualError(t, err, "指定された思考記録が見つかりません")
+	assert.EqualError(t, repo.Delete(ctx, "u2", record.ID), "指定された思考記録が見つかりません")
+
+	assert.NoError(t, repo.Delete(ctx, "u1", record.ID))
+	all, err := repo.FindByUserID(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Empty(t, all)
+}
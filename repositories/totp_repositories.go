@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TOTPRepository struct {
+	db *gorm.DB
+}
+
+func NewTOTPRepository(db *gorm.DB) auth.TOTPRepository {
+	return &TOTPRepository{db: db}
+}
+
+func (r *TOTPRepository) FindByUserID(ctx context.Context, userID string) (*auth.TOTPFactor, error) {
+	var model db.TOTPFactorModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *TOTPRepository) Save(ctx context.Context, factor *auth.TOTPFactor) error {
+	model := db.TOTPFactorFromDomain(factor)
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled_at", "last_used_step", "failed_attempts", "locked_until"}),
+	}).Create(model).Error
+	if err != nil {
+		return err
+	}
+	factor.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// UseStep は条件付きのUPDATEで記録するため、同じコードで同時にログインしても成功するのは1回だけになる
+func (r *TOTPRepository) UseStep(ctx context.Context, userID string, step int64) error {
+	result := conn(ctx, r.db).Model(&db.TOTPFactorModel{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Updates(map[string]interface{}{"last_used_step": step, "failed_attempts": 0, "locked_until": nil})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+func (r *TOTPRepository) RecordFailure(ctx context.Context, userID string, maxAttempts int, lockedUntil time.Time) error {
+	// SETの右辺は更新前の値を参照するため、両方の列で同じ条件を使える
+	return conn(ctx, r.db).Model(&db.TOTPFactorModel{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END", maxAttempts),
+			"locked_until":    gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE locked_until END", maxAttempts, lockedUntil),
+		}).Error
+}
+
+func (r *TOTPRepository) ResetFailures(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Model(&db.TOTPFactorModel{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": nil}).Error
+}
+
+func (r *TOTPRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.TOTPFactorModel{}).Error
+}
+
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) auth.RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+func (r *RecoveryCodeRepository) ReplaceByUserID(ctx context.Context, userID string, codes []*auth.RecoveryCode) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&db.RecoveryCodeModel{}).Error; err != nil {
+			return err
+		}
+		for _, code := range codes {
+			if code.ID == "" {
+				id, err := uuid.NewV7()
+				if err != nil {
+					return err
+				}
+				code.ID = id.String()
+			}
+			code.UserID = userID
+			model := db.RecoveryCodeFromDomain(code)
+			if err := tx.Create(model).Error; err != nil {
+				return err
+			}
+			code.CreatedAt = model.CreatedAt
+		}
+		return nil
+	})
+}
+
+// Consume は条件付きのUPDATEで使用済みにするため、同じコードは同時に使っても1回しか成功しない
+func (r *RecoveryCodeRepository) Consume(ctx context.Context, userID, codeHash string, now time.Time) error {
+	result := conn(ctx, r.db).Model(&db.RecoveryCodeModel{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+func (r *RecoveryCodeRepository) CountUnused(ctx context.Context, userID string) (int, error) {
+	var count int64
+	if err := conn(ctx, r.db).Model(&db.RecoveryCodeModel{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *RecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.RecoveryCodeModel{}).Error
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTOTPTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.TOTPFactorModel{}, &db.RecoveryCodeModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestTOTPRepository(t *testing.T) {
+	repo := NewTOTPRepository(setupTOTPTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	found, err := repo.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	require.NoError(t, repo.Save(ctx, &auth.TOTPFactor{UserID: "u1", Secret: []byte("secret-1")}))
+	// 設定のやり直しでは同じユーザーの行を上書きする
+	require.NoError(t, repo.Save(ctx, &auth.TOTPFactor{UserID: "u1", Secret: []byte("secret-2"), EnabledAt: &now, FailedAttempts: 3}))
+	found, err = repo.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret-2"), found.Secret)
+	assert.True(t, found.IsEnabled())
+	assert.Equal(t, 3, found.FailedAttempts)
+
+	require.NoError(t, repo.UseStep(ctx, "u1", 100))
+	found, err = repo.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), found.LastUsedStep)
+	assert.Equal(t, 0, found.FailedAttempts, "成功すると失敗回数を戻す")
+
+	t.Run("異常系: 同じか古い時間ステップのコードは再利用できない", func(t *testing.T) {
+		assert.ErrorIs(t, repo.UseStep(ctx, "u1", 100), auth.ErrInvalidTOTPCode)
+		assert.ErrorIs(t, repo.UseStep(ctx, "u1", 99), auth.ErrInvalidTOTPCode)
+	})
+
+	t.Run("失敗の記録はコードの使用の記録を上書きしない", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			require.NoError(t, repo.RecordFailure(ctx, "u1", 5, now.Add(15*time.Minute)))
+		}
+		found, err := repo.FindByUserID(ctx, "u1")
+		require.NoError(t, err)
+		assert.Equal(t, 4, found.FailedAttempts)
+		assert.False(t, found.IsLocked(now))
+		assert.Equal(t, int64(100), found.LastUsedStep)
+		assert.Equal(t, []byte("secret-2"), found.Secret)
+
+		// 上限に達するとロックして回数を戻す
+		require.NoError(t, repo.RecordFailure(ctx, "u1", 5, now.Add(15*time.Minute)))
+		found, err = repo.FindByUserID(ctx, "u1")
+		require.NoError(t, err)
+		assert.Equal(t, 0, found.FailedAttempts)
+		assert.True(t, found.IsLocked(now))
+
+		require.NoError(t, repo.ResetFailures(ctx, "u1"))
+		found, err = repo.FindByUserID(ctx, "u1")
+		require.NoError(t, err)
+		assert.False(t, found.IsLocked(now))
+		assert.Equal(t, int64(100), found.LastUsedStep)
+	})
+
+	require.NoError(t, repo.DeleteByUserID(ctx, "u1"))
+	found, err = repo.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestRecoveryCodeRepository(t *testing.T) {
+	repo := NewRecoveryCodeRepository(setupTOTPTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.ReplaceByUserID(ctx, "u1", []*auth.RecoveryCode{{CodeHash: auth.HashToken("old")}}))
+	require.NoError(t, repo.ReplaceByUserID(ctx, "u1", []*auth.RecoveryCode{{CodeHash: auth.HashToken("a")}, {CodeHash: auth.HashToken("b")}}))
+	count, err := repo.CountUnused(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.ErrorIs(t, repo.Consume(ctx, "u1", auth.HashToken("old"), now), auth.ErrInvalidTOTPCode, "置き換え前のコードは使えない")
+	assert.ErrorIs(t, repo.Consume(ctx, "u2", auth.HashToken("a"), now), auth.ErrInvalidTOTPCode, "他のユーザーのコードは使えない")
+	require.NoError(t, repo.Consume(ctx, "u1", auth.HashToken("a"), now))
+	assert.ErrorIs(t, repo.Consume(ctx, "u1", auth.HashToken("a"), now), auth.ErrInvalidTOTPCode, "一度しか使えない")
+
+	count, err = repo.CountUnused(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
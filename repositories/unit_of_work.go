@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"tofunote-backend/domain/transaction"
+
+	"gorm.io/gorm"
+)
+
+// txKey はUnitOfWorkのトランザクションをコンテキストに保持するためのキー
+type txKey struct{}
+
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) transaction.UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	// 既にトランザクションの中で呼ばれた場合はセーブポイントで入れ子にする
+	return conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn はコンテキストにUnitOfWorkのトランザクションがある場合はそれを、ない場合はdbを返す（各リポジトリで共通）
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupUnitOfWorkTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&user.User{}, &db.UserRoleModel{}, &db.DiaryModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestUnitOfWork(t *testing.T) {
+	gormDB := setupUnitOfWorkTestDB(t)
+	uow := NewUnitOfWork(gormDB)
+	users := NewUserRepository(gormDB)
+	diaries := NewDiaryRepository(gormDB)
+	ctx := context.Background()
+	require.NoError(t, users.Create(ctx, &user.User{ID: "u1"}))
+	require.NoError(t, diaries.Create(ctx, &diary.Diary{UserID: "u1", Date: "2025-05-01", Mental: 5}))
+
+	t.Run("エラーの場合は全ての変更を取り消す", func(t *testing.T) {
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			require.NoError(t, diaries.DeleteByUserID(ctx, "u1"))
+			require.NoError(t, users.DeleteByID(ctx, "u1"))
+			return errors.New("failed halfway")
+		})
+		assert.EqualError(t, err, "failed halfway")
+		found, err := users.FindByID(ctx, "u1")
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+		remaining, err := diaries.FindByUserID(ctx, "u1")
+		require.NoError(t, err)
+		assert.Len(t, remaining, 1)
+	})
+
+	t.Run("成功した場合はまとめて確定する", func(t *testing.T) {
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			if err := diaries.DeleteByUserID(ctx, "u1"); err != nil {
+				return err
+			}
+			return users.DeleteByID(ctx, "u1")
+		})
+		require.NoError(t, err)
+		found, err := users.FindByID(ctx, "u1")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+		remaining, err := diaries.FindByUserID(ctx, "u1")
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+	})
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserPreferencesRepository struct {
+	db *gorm.DB
+}
+
+func NewUserPreferencesRepository(db *gorm.DB) user.PreferencesRepository {
+	return &UserPreferencesRepository{db: db}
+}
+
+func (r *UserPreferencesRepository) FindByUserID(ctx context.Context, userID string) (*user.Preferences, error) {
+	var model db.UserPreferencesModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *UserPreferencesRepository) FindRemindable(ctx context.Context) ([]user.RemindableUser, error) {
+	var models []db.UserPreferencesModel
+	// 退会を申請したユーザーには猶予期間中も送らない
+	withdrawn := conn(ctx, r.db).Model(&db.UserModel{}).Select("id").Where("withdrawn_at IS NOT NULL")
+	if err := conn(ctx, r.db).Where("reminder_times <> '' AND user_id NOT IN (?)", withdrawn).Order("user_id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	users := make([]user.RemindableUser, len(models))
+	for i := range models {
+		users[i] = user.RemindableUser{UserID: models[i].UserID, Preferences: *models[i].ToDomain()}
+	}
+	return users, nil
+}
+
+func (r *UserPreferencesRepository) FindAnalysisOptedInUserIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	if err := conn(ctx, r.db).Model(&db.UserPreferencesModel{}).Where("analysis_opt_in = ?", true).Order("user_id").Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *UserPreferencesRepository) Save(ctx context.Context, userID string, p *user.Preferences) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timezone", "locale", "first_day_of_week", "mood_scale", "reminder_times", "analysis_opt_in", "theme", "reminder_weekdays", "notification_channels", "updated_at"}),
+	}).Create(db.UserPreferencesFromDomain(userID, p)).Error
+}
+
+func (r *UserPreferencesRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.UserPreferencesModel{}).Error
+}
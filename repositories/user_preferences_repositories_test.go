@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupUserPreferencesTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.UserPreferencesModel{}, &db.UserModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestUserPreferencesRepository(t *testing.T) {
+	gormDB := setupUserPreferencesTestDB(t)
+	repo := NewUserPreferencesRepository(gormDB)
+	ctx := context.Background()
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	// 保存していない場合はnil
+	got, err := repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	prefs := user.DefaultPreferences()
+	require.NoError(t, repo.Save(ctx, userID, &prefs))
+	got, err = repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, &prefs, got)
+
+	// 保存済みの場合は上書きする
+	prefs.Timezone = "Europe/London"
+	prefs.FirstDayOfWeek = time.Monday
+	prefs.MoodScale = 5
+	prefs.ReminderTimes = []string{"08:00", "21:30"}
+	prefs.AnalysisOptIn = true
+	prefs.Theme = user.ThemeDark
+	prefs.ReminderWeekdays = []time.Weekday{time.Monday, time.Friday}
+	prefs.NotificationChannels = []notification.Channel{notification.ChannelEmail, notification.ChannelWebhook}
+	require.NoError(t, repo.Save(ctx, userID, &prefs))
+	got, err = repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, &prefs, got)
+
+	// 通知チャネルを空にした場合も既定値に戻さない
+	prefs.NotificationChannels = []notification.Channel{}
+	require.NoError(t, repo.Save(ctx, userID, &prefs))
+	got, err = repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, &prefs, got)
+
+	// リマインダーの時刻を設定しているユーザーのみ返す
+	other := user.DefaultPreferences()
+	require.NoError(t, repo.Save(ctx, "22222222-2222-2222-2222-222222222222", &other))
+	remindable, err := repo.FindRemindable(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []user.RemindableUser{{UserID: userID, Preferences: prefs}}, remindable)
+
+	optedIn, err := repo.FindAnalysisOptedInUserIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{userID}, optedIn, "AIによる分析に同意したユーザーのみ返す")
+
+	// 退会を申請したユーザーには送らない
+	withdrawnAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, gormDB.Create(&db.UserModel{ID: userID, WithdrawnAt: &withdrawnAt}).Error)
+	remindable, err = repo.FindRemindable(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, remindable)
+
+	require.NoError(t, repo.DeleteByUserID(ctx, userID))
+	got, err = repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
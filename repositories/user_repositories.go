@@ -18,39 +18,49 @@ func NewUserRepository(db *gorm.DB) user.Repository {
 
 func (r *UserRepository) FindByProviderId(ctx context.Context, provider, providerId string) (*user.User, error) {
 	var u user.User
-	if err := r.db.WithContext(ctx).Where("provider = ? AND provider_id = ?", provider, providerId).First(&u).Error; err != nil {
+	if err := conn(ctx, r.db).Where("provider = ? AND provider_id = ?", provider, providerId).First(&u).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &u, nil
+	return r.withRoles(ctx, &u)
 }
 
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*user.User, error) {
 	var u user.User
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&u).Error; err != nil {
+	if err := conn(ctx, r.db).Where("id = ?", id).First(&u).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &u, nil
+	return r.withRoles(ctx, &u)
 }
 
-func (r *UserRepository) FindByRefreshToken(ctx context.Context, refreshToken string) (*user.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*user.User, error) {
 	var u user.User
-	if err := r.db.WithContext(ctx).Where("refresh_token = ?", refreshToken).First(&u).Error; err != nil {
+	if err := conn(ctx, r.db).Where("email = ?", email).First(&u).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &u, nil
+	return r.withRoles(ctx, &u)
+}
+
+// withRoles はユーザーに付与されたロールを読み込む
+func (r *UserRepository) withRoles(ctx context.Context, u *user.User) (*user.User, error) {
+	roles, err := listRoles(conn(ctx, r.db), u.ID)
+	if err != nil {
+		return nil, err
+	}
+	u.Roles = roles
+	return u, nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
-	return r.db.WithContext(ctx).Save(u).Error
+	return conn(ctx, r.db).Save(u).Error
 }
 
 func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
@@ -61,9 +71,9 @@ func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
 		}
 		u.ID = id.String()
 	}
-	return r.db.WithContext(ctx).Create(u).Error
+	return conn(ctx, r.db).Create(u).Error
 }
 
 func (r *UserRepository) DeleteByID(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&user.User{}).Error
+	return conn(ctx, r.db).Unscoped().Where("id = ?", id).Delete(&user.User{}).Error
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/db"
 
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -11,14 +12,14 @@ import (
 )
 
 func setupUserTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open test db: %v", err)
 	}
-	if err := db.AutoMigrate(&user.User{}); err != nil {
+	if err := gormDB.AutoMigrate(&user.User{}, &db.UserRoleModel{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
-	return db
+	return gormDB
 }
 
 func TestUserRepository_Create(t *testing.T) {
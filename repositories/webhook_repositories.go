@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tofunote-backend/domain/webhook"
+	"tofunote-backend/infra/db"
+
+	"github.com/cmackenzie1/go-uuid"
+	"gorm.io/gorm"
+)
+
+type WebhookEndpointRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookEndpointRepository(db *gorm.DB) webhook.EndpointRepository {
+	return &WebhookEndpointRepository{db: db}
+}
+
+func (r *WebhookEndpointRepository) Create(ctx context.Context, endpoint *webhook.Endpoint) error {
+	if endpoint.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		endpoint.ID = id.String()
+	}
+	model := db.WebhookEndpointFromDomain(endpoint)
+	if err := conn(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	endpoint.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *WebhookEndpointRepository) FindByUserID(ctx context.Context, userID string) ([]webhook.Endpoint, error) {
+	var models []db.WebhookEndpointModel
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	endpoints := make([]webhook.Endpoint, len(models))
+	for i := range models {
+		endpoints[i] = models[i].ToDomain()
+	}
+	return endpoints, nil
+}
+
+func (r *WebhookEndpointRepository) FindByID(ctx context.Context, userID, id string) (*webhook.Endpoint, error) {
+	var model db.WebhookEndpointModel
+	err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, webhook.ErrEndpointNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	endpoint := model.ToDomain()
+	return &endpoint, nil
+}
+
+func (r *WebhookEndpointRepository) Delete(ctx context.Context, userID, id string) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&db.WebhookEndpointModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return webhook.ErrEndpointNotFound
+	}
+	// 送信待ちの配送も送らないよう合わせて削除する（PostgreSQLでは外部キーでも削除される）
+	return conn(ctx, r.db).Where("endpoint_id = ? AND user_id = ?", id, userID).Delete(&db.WebhookDeliveryModel{}).Error
+}
+
+func (r *WebhookEndpointRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.WebhookEndpointModel{}).Error
+}
+
+type WebhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) webhook.DeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+func (r *WebhookDeliveryRepository) Enqueue(ctx context.Context, deliveries []*webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	models := make([]*db.WebhookDeliveryModel, len(deliveries))
+	for i, d := range deliveries {
+		if d.ID == "" {
+			id, err := uuid.NewV7()
+			if err != nil {
+				return err
+			}
+			d.ID = id.String()
+		}
+		models[i] = db.WebhookDeliveryFromDomain(d)
+	}
+	if err := conn(ctx, r.db).Create(&models).Error; err != nil {
+		return err
+	}
+	for i, m := range models {
+		deliveries[i].CreatedAt = m.CreatedAt
+	}
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now, lease time.Time, limit int) ([]*webhook.Delivery, error) {
+	var models []db.WebhookDeliveryModel
+	err := conn(ctx, r.db).
+		Where("status = ? AND next_attempt_at <= ?", string(webhook.DeliveryPending), now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	claimed := make([]*webhook.Delivery, 0, len(models))
+	for i := range models {
+		// 送信日時を lease に進められた配送のみ取得する（同時に実行した他のジョブが取得した配送は除く）
+		result := conn(ctx, r.db).Model(&db.WebhookDeliveryModel{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", models[i].ID, string(webhook.DeliveryPending), models[i].NextAttemptAt).
+			Update("next_attempt_at", lease)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			models[i].NextAttemptAt = lease
+			claimed = append(claimed, models[i].ToDomain())
+		}
+	}
+	return claimed, nil
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery *webhook.Delivery) error {
+	return conn(ctx, r.db).Model(&db.WebhookDeliveryModel{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+		"status":           string(delivery.Status),
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"delivered_at":     delivery.DeliveredAt,
+	}).Error
+}
+
+func (r *WebhookDeliveryRepository) FindByEndpoint(ctx context.Context, userID, endpointID string, limit, offset int) ([]*webhook.Delivery, error) {
+	var models []db.WebhookDeliveryModel
+	err := conn(ctx, r.db).
+		Where("endpoint_id = ? AND user_id = ?", endpointID, userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*webhook.Delivery, len(models))
+	for i := range models {
+		deliveries[i] = models[i].ToDomain()
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookDeliveryRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("status <> ? AND created_at < ?", string(webhook.DeliveryPending), before).
+		Delete(&db.WebhookDeliveryModel{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *WebhookDeliveryRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Where("user_id = ?", userID).Delete(&db.WebhookDeliveryModel{}).Error
+}
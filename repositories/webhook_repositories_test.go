@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/webhook"
+	"tofunote-backend/infra/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupWebhookTestDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&db.WebhookEndpointModel{}, &db.WebhookDeliveryModel{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestWebhookEndpointRepository(t *testing.T) {
+	gormDB := setupWebhookTestDB(t)
+	endpoints := NewWebhookEndpointRepository(gormDB)
+	deliveries := NewWebhookDeliveryRepository(gormDB)
+	ctx := context.Background()
+
+	endpoint := &webhook.Endpoint{UserID: "u1", URL: "https://hooks.example.com/1", Secret: "secret", Events: []webhook.EventType{webhook.EventDiaryCreated, webhook.EventDiaryDeleted}}
+	require.NoError(t, endpoints.Create(ctx, endpoint))
+	assert.NotEmpty(t, endpoint.ID)
+
+	got, err := endpoints.FindByID(ctx, "u1", endpoint.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []webhook.EventType{webhook.EventDiaryCreated, webhook.EventDiaryDeleted}, got.Events)
+	assert.Equal(t, "secret", got.Secret)
+	_, err = endpoints.FindByID(ctx, "u2", endpoint.ID)
+	assert.ErrorIs(t, err, webhook.ErrEndpointNotFound)
+
+	require.NoError(t, deliveries.Enqueue(ctx, []*webhook.Delivery{
+		{EndpointID: endpoint.ID, UserID: "u1", EventID: "e1", EventType: webhook.EventDiaryCreated, Payload: []byte("{}"), Status: webhook.DeliveryPending, NextAttemptAt: time.Now()},
+	}))
+
+	assert.ErrorIs(t, endpoints.Delete(ctx, "u2", endpoint.ID), webhook.ErrEndpointNotFound)
+	require.NoError(t, endpoints.Delete(ctx, "u1", endpoint.ID))
+	list, err := endpoints.FindByUserID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	logs, err := deliveries.FindByEndpoint(ctx, "u1", endpoint.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, logs)
+}
+
+func TestWebhookDeliveryRepository_ClaimDue(t *testing.T) {
+	gormDB := setupWebhookTestDB(t)
+	repo := NewWebhookDeliveryRepository(gormDB)
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	due := &webhook.Delivery{EndpointID: "ep1", UserID: "u1", EventID: "e1", EventType: webhook.EventDiaryCreated, Payload: []byte(`{"a":1}`), Status: webhook.DeliveryPending, NextAttemptAt: now.Add(-time.Minute)}
+	later := &webhook.Delivery{EndpointID: "ep1", UserID: "u1", EventID: "e2", EventType: webhook.EventDiaryUpdated, Payload: []byte(`{}`), Status: webhook.DeliveryPending, NextAttemptAt: now.Add(time.Minute)}
+	done := &webhook.Delivery{EndpointID: "ep1", UserID: "u1", EventID: "e3", EventType: webhook.EventDiaryDeleted, Payload: []byte(`{}`), Status: webhook.DeliverySucceeded, NextAttemptAt: now.Add(-time.Hour)}
+	require.NoError(t, repo.Enqueue(ctx, []*webhook.Delivery{due, later, done}))
+
+	claimed, err := repo.ClaimDue(ctx, now, now.Add(5*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].ID)
+	assert.Equal(t, `{"a":1}`, string(claimed[0].Payload))
+
+	// 取得した配送は lease まで再び取得されない
+	claimed2, err := repo.ClaimDue(ctx, now, now.Add(5*time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed2)
+
+	claimed[0].RecordAttempt(now, 500, nil)
+	require.NoError(t, repo.Update(ctx, claimed[0]))
+	logs, err := repo.FindByEndpoint(ctx, "u1", "ep1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 3)
+	var updated *webhook.Delivery
+	for _, l := range logs {
+		if l.ID == due.ID {
+			updated = l
+		}
+	}
+	require.NotNil(t, updated)
+	assert.Equal(t, 1, updated.Attempts)
+	assert.Equal(t, 500, updated.LastStatusCode)
+
+	deleted, err := repo.DeleteFinishedBefore(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
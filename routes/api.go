@@ -5,6 +5,8 @@ import (
 	"os"
 	"time"
 	"tofunote-backend/api/controllers"
+	authdomain "tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
 
 	"tofunote-backend/routes/middleware"
 
@@ -13,7 +15,7 @@ import (
 )
 
 // SetupAPIEndpoints APIエンドポイントを設定
-func SetupAPIEndpoints(router *gin.Engine, diaryController *controllers.DiaryController, diaryAnalysisController *controllers.DiaryAnalysisController, userController *controllers.UserController) {
+func SetupAPIEndpoints(router *gin.Engine, diaryController *controllers.DiaryController, diaryAnalysisController *controllers.DiaryAnalysisController, userController *controllers.UserController, emotionController *controllers.EmotionController, diaryStatsController *controllers.DiaryStatsController, factorController *controllers.FactorController, medicationController *controllers.MedicationController, thoughtRecordController *controllers.ThoughtRecordController, exportController *controllers.ExportController, gratitudeController *controllers.GratitudeController, oidcController *controllers.OIDCController, accountLinkController *controllers.AccountLinkController, passwordAuthController *controllers.PasswordAuthController, sessionController *controllers.SessionController, passkeyController *controllers.PasskeyController, mfaController *controllers.MFAController, adminController *controllers.AdminController, personalAccessTokenController *controllers.PersonalAccessTokenController, auditController *controllers.AuditController, pushSubscriptionController *controllers.PushSubscriptionController, webhookController *controllers.WebhookController) {
 	// ヘルスチェックエンドポイント
 	router.GET("/ping", func(c *gin.Context) {
 		log.Printf("[DEBUG] Ping endpoint called - returning pong message")
@@ -57,22 +59,116 @@ func SetupAPIEndpoints(router *gin.Engine, diaryController *controllers.DiaryCon
 	})
 
 	api := router.Group("/api")
+	api.Use(middleware.RequestContext())
 	{
 		api.POST("/guest-login", userController.GuestLogin)
+		api.POST("/guest-login/challenge", userController.GuestChallenge)
 		api.POST("/refresh-token", userController.RefreshToken)
+		api.POST("/logout", userController.Logout)
+		api.GET("/auth/oidc/providers", oidcController.Providers)
+		api.POST("/auth/oidc/:provider/start", oidcController.Start)
+		api.POST("/auth/oidc/:provider/callback", oidcController.Callback)
+		api.POST("/auth/oidc/:provider/form-post", oidcController.FormPost)
+		api.POST("/register", passwordAuthController.Register)
+		api.POST("/login", passwordAuthController.Login)
+		api.POST("/email/verify", passwordAuthController.VerifyEmail)
+		api.POST("/password/forgot", passwordAuthController.ForgotPassword)
+		api.POST("/password/reset", passwordAuthController.ResetPassword)
+		api.POST("/passkeys/login/options", passkeyController.BeginLogin)
+		api.POST("/passkeys/login", passkeyController.FinishLogin)
+		api.POST("/mfa/verify", mfaController.VerifyLogin)
+		api.GET("/push/vapid-public-key", pushSubscriptionController.PublicKey)
 
-		// 認証が必要なグループ
+		// 個人用アクセストークンでも利用できるAPI（ルートごとに必要なスコープを指定する）
+		scoped := api.Group("")
+		scoped.GET("/me/diaries", middleware.ScopedAuthMiddleware(authdomain.ScopeDiariesRead), diaryController.FindAll)
+		scoped.GET("/me/diaries/range", middleware.ScopedAuthMiddleware(authdomain.ScopeDiariesRead), diaryController.FindByUserIDAndDateRange)
+		scoped.GET("/me/diaries/:date", middleware.ScopedAuthMiddleware(authdomain.ScopeDiariesRead), diaryController.FindByUserIDAndDate)
+		scoped.POST("/me/diaries", middleware.ScopedAuthMiddleware(authdomain.ScopeDiariesWrite), diaryController.Create)
+		scoped.PUT("/me/diaries/:date", middleware.ScopedAuthMiddleware(authdomain.ScopeDiariesWrite), diaryController.Update)
+		scoped.DELETE("/me/diaries/:date", middleware.ScopedAuthMiddleware(authdomain.ScopeDiariesWrite), diaryController.Delete)
+		scoped.GET("/me/analyze-diaries", middleware.ScopedAuthMiddleware(authdomain.ScopeAnalysisRun), diaryAnalysisController.AnalyzeAllDiariesHandler)
+
+		// 認証が必要なグループ（ログインで発行したアクセストークンのみ）
 		auth := api.Group("")
 		auth.Use(middleware.JWTAuthMiddleware())
-		auth.GET("/me/diaries", diaryController.FindAll)
-		auth.GET("/me/diaries/range", diaryController.FindByUserIDAndDateRange)
-		auth.GET("/me/diaries/:date", diaryController.FindByUserIDAndDate)
-		auth.POST("/me/diaries", diaryController.Create)
-		auth.PUT("/me/diaries/:date", diaryController.Update)
-		auth.DELETE("/me/diaries/:date", diaryController.Delete)
-		auth.GET("/me/analyze-diaries", diaryAnalysisController.AnalyzeAllDiariesHandler)
+		auth.GET("/me/emotions", emotionController.FindAll)
+		auth.POST("/me/emotions", emotionController.Create)
+		auth.DELETE("/me/emotions/:key", emotionController.Delete)
+		auth.GET("/me/stats/emotions", diaryStatsController.EmotionFrequencies)
+		auth.GET("/me/stats/correlations", diaryStatsController.FactorCorrelations)
+		auth.GET("/me/factors/range", factorController.FindByDateRange)
+		auth.GET("/me/factors/:date", factorController.FindByDate)
+		auth.PUT("/me/factors/:date", factorController.Save)
+		auth.DELETE("/me/factors/:date", factorController.Delete)
+		auth.GET("/me/factor-definitions", factorController.FindDefinitions)
+		auth.POST("/me/factor-definitions", factorController.CreateDefinition)
+		auth.DELETE("/me/factor-definitions/:key", factorController.DeleteDefinition)
+		auth.GET("/me/medications", medicationController.FindAll)
+		auth.POST("/me/medications", medicationController.Create)
+		auth.GET("/me/medications/:id", medicationController.FindByID)
+		auth.PUT("/me/medications/:id", medicationController.Update)
+		auth.DELETE("/me/medications/:id", medicationController.Delete)
+		auth.PUT("/me/medications/:id/doses", medicationController.RecordDose)
+		auth.DELETE("/me/medications/:id/doses", medicationController.DeleteDose)
+		auth.GET("/me/medication-doses", medicationController.FindDoses)
+		auth.GET("/me/stats/adherence", medicationController.Adherence)
+		auth.GET("/me/thought-records", thoughtRecordController.FindAll)
+		auth.POST("/me/thought-records", thoughtRecordController.Create)
+		auth.GET("/me/thought-records/:id", thoughtRecordController.FindByID)
+		auth.PUT("/me/thought-records/:id", thoughtRecordController.Update)
+		auth.DELETE("/me/thought-records/:id", thoughtRecordController.Delete)
+		auth.GET("/me/highlights/range", gratitudeController.FindByDateRange)
+		auth.GET("/me/highlights/:date", gratitudeController.FindByDate)
+		auth.PUT("/me/highlights/:date", gratitudeController.Save)
+		auth.DELETE("/me/highlights/:date", gratitudeController.Delete)
+		auth.GET("/me/gratitude/random", gratitudeController.RandomPast)
+		auth.GET("/me/export", exportController.Export)
+		auth.POST("/me/link", accountLinkController.Link)
+		auth.POST("/me/link/merge", accountLinkController.Merge)
+		auth.POST("/me/email/verification", passwordAuthController.ResendVerification)
+		auth.GET("/me/sessions", sessionController.FindAll)
+		auth.DELETE("/me/sessions", sessionController.DeleteAll)
+		auth.DELETE("/me/sessions/:id", sessionController.Delete)
+		auth.GET("/me/passkeys", passkeyController.FindAll)
+		auth.POST("/me/passkeys/options", passkeyController.BeginRegistration)
+		auth.POST("/me/passkeys", passkeyController.FinishRegistration)
+		auth.PATCH("/me/passkeys/:id", passkeyController.Rename)
+		auth.DELETE("/me/passkeys/:id", passkeyController.Delete)
+		auth.GET("/me/mfa", mfaController.Status)
+		auth.POST("/me/mfa/totp/enroll", mfaController.EnrollTOTP)
+		auth.POST("/me/mfa/totp/verify", mfaController.VerifyTOTP)
+		auth.POST("/me/mfa/totp/disable", mfaController.DisableTOTP)
+		auth.POST("/me/mfa/recovery-codes", mfaController.RegenerateRecoveryCodes)
+		auth.GET("/me/personal-access-tokens", personalAccessTokenController.FindAll)
+		auth.POST("/me/personal-access-tokens", personalAccessTokenController.Create)
+		auth.DELETE("/me/personal-access-tokens/:id", personalAccessTokenController.Delete)
+		auth.GET("/me/audit-log", auditController.FindMine)
+		auth.GET("/me/push-subscriptions", pushSubscriptionController.FindAll)
+		auth.POST("/me/push-subscriptions", pushSubscriptionController.Register)
+		auth.DELETE("/me/push-subscriptions/:id", pushSubscriptionController.Delete)
+		auth.GET("/me/webhooks", webhookController.FindAll)
+		auth.POST("/me/webhooks", webhookController.Create)
+		auth.DELETE("/me/webhooks/:id", webhookController.Delete)
+		auth.GET("/me/webhooks/:id/deliveries", webhookController.FindDeliveries)
+		auth.POST("/me/webhooks/:id/ping", webhookController.Ping)
 		auth.DELETE("/me", userController.DeleteMe)
+		auth.POST("/me/restore", userController.RestoreMe)
 		auth.GET("/me", userController.GetMe)
 		auth.PATCH("/me", userController.PatchMe)
+
+		// 管理者のみ利用できるグループ（日記の本文を返すのは監査ログに理由を記録するbreak-glassのみ）
+		adminGroup := api.Group("/admin")
+		adminGroup.Use(middleware.JWTAuthMiddleware(), middleware.RequireRole(string(user.RoleAdmin)))
+		adminGroup.GET("/users", adminController.SearchUsers)
+		adminGroup.DELETE("/users/:id", adminController.DeleteUser)
+		adminGroup.PUT("/users/:id/roles/:role", adminController.GrantRole)
+		adminGroup.DELETE("/users/:id/roles/:role", adminController.RevokeRole)
+		adminGroup.POST("/users/:id/diaries/break-glass", adminController.BreakGlassDiaries)
+		adminGroup.GET("/stats", adminController.Stats)
+		adminGroup.POST("/analysis", adminController.RunGlobalAnalysis)
+		adminGroup.GET("/action-logs", adminController.ListActionLogs)
+		adminGroup.GET("/audit-log", auditController.Search)
+		adminGroup.GET("/guest-creations", userController.GuestCreationMetrics)
 	}
 }
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupClientIP クライアントのIPアドレスの判定を設定
+// ゲストの作成数の制限・監査ログ・セッションに使うため、クライアントが書き換えられるX-Forwarded-Forは
+// TRUSTED_PROXIES（カンマ区切りのIPアドレス・CIDR）に含まれるプロキシから届いた場合のみ信頼する。
+// TRUSTED_PLATFORM（cloudflare・google・ヘッダー名）を設定した場合は、そのプラットフォームが付けるヘッダーを使う
+func SetupClientIP(router *gin.Engine) error {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	// 空の場合はどのプロキシも信頼しない（Ginの既定では全てのプロキシを信頼する）
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		return fmt.Errorf("TRUSTED_PROXIESが不正です: %w", err)
+	}
+
+	switch platform := strings.TrimSpace(os.Getenv("TRUSTED_PLATFORM")); strings.ToLower(platform) {
+	case "":
+	case "cloudflare":
+		router.TrustedPlatform = gin.PlatformCloudflare
+	case "google":
+		router.TrustedPlatform = gin.PlatformGoogleAppEngine
+	default:
+		router.TrustedPlatform = platform
+	}
+
+	// API Gatewayのプロキシ統合は接続元のアドレスをポートなしでRemoteAddrに設定し、Ginが判定できないためポートを補う
+	router.Use(func(c *gin.Context) {
+		if _, _, err := net.SplitHostPort(c.Request.RemoteAddr); err != nil && net.ParseIP(c.Request.RemoteAddr) != nil {
+			c.Request.RemoteAddr = net.JoinHostPort(c.Request.RemoteAddr, "0")
+		}
+		c.Next()
+	})
+	return nil
+}
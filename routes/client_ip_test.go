@@ -0,0 +1,110 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tofunote-backend/api/controllers"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/usecases"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryGuestCreationRepo struct {
+	creations []user.GuestCreation
+}
+
+func (m *memoryGuestCreationRepo) Record(ctx context.Context, creation *user.GuestCreation) error {
+	m.creations = append(m.creations, *creation)
+	return nil
+}
+func (m *memoryGuestCreationRepo) CountSince(ctx context.Context, since time.Time) (int64, error) {
+	return int64(len(m.creations)), nil
+}
+func (m *memoryGuestCreationRepo) CountByIPSince(ctx context.Context, ipHash string, since time.Time) (int64, error) {
+	var count int64
+	for _, c := range m.creations {
+		if c.IPHash == ipHash {
+			count++
+		}
+	}
+	return count, nil
+}
+func (m *memoryGuestCreationRepo) CountDistinctIPsSince(ctx context.Context, since time.Time) (int64, error) {
+	return 0, nil
+}
+func (m *memoryGuestCreationRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type memoryUserRepo struct {
+	user.Repository
+}
+
+func (m *memoryUserRepo) Create(ctx context.Context, u *user.User) error { return nil }
+
+type fakeRefreshTokenUsecase struct {
+	usecases.IRefreshTokenUsecase
+}
+
+func (f *fakeRefreshTokenUsecase) Issue(ctx context.Context, userID string, client auth.ClientInfo) (string, *auth.RefreshToken, error) {
+	return "refresh", &auth.RefreshToken{UserID: userID, FamilyID: "family"}, nil
+}
+
+func TestSetupClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies string
+		remoteAddr     string
+		forwardedFor   []string
+		wantStatus     []int
+	}{
+		{
+			name:         "X-Forwarded-Forを書き換えてもIPアドレスごとの上限を超えられない",
+			remoteAddr:   "203.0.113.10:54321",
+			forwardedFor: []string{"198.51.100.1", "198.51.100.2"},
+			wantStatus:   []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:         "API Gatewayのポートのない接続元のアドレスで数える",
+			remoteAddr:   "203.0.113.10",
+			forwardedFor: []string{"198.51.100.1", "198.51.100.2"},
+			wantStatus:   []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:           "信頼するプロキシからのX-Forwarded-Forは使う",
+			trustedProxies: "10.0.0.0/8",
+			remoteAddr:     "10.0.0.1:54321",
+			forwardedFor:   []string{"198.51.100.1", "198.51.100.2"},
+			wantStatus:     []int{http.StatusOK, http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", tt.trustedProxies)
+			t.Setenv("TRUSTED_PLATFORM", "")
+			guests := usecases.NewGuestAdmissionUsecase(&memoryGuestCreationRepo{}, nil, usecases.GuestAdmissionConfig{Window: time.Hour, PerIPLimit: 1})
+			uc := controllers.NewUserController(&memoryUserRepo{}, nil, &fakeRefreshTokenUsecase{}, guests, nil, nil)
+			r := gin.New()
+			require.NoError(t, SetupClientIP(r))
+			r.POST("/api/guest-login", uc.GuestLogin)
+			for i, forwardedFor := range tt.forwardedFor {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/api/guest-login", nil)
+				req.RemoteAddr = tt.remoteAddr
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+				r.ServeHTTP(w, req)
+				assert.Equal(t, tt.wantStatus[i], w.Code, "%d回目", i+1)
+			}
+		})
+	}
+}
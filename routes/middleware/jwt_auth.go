@@ -1,50 +1,194 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
-	"tofunote-backend/domain/user"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/domain/auth"
 	"tofunote-backend/infra"
-	"tofunote-backend/repositories"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-var dbInstance *gorm.DB
-var userRepo user.Repository
+var sessionRevocations SessionRevocationChecker
 
-func SetAuthDB(db *gorm.DB) {
-	dbInstance = db
-	userRepo = repositories.NewUserRepository(db)
+// SessionRevocationChecker はアクセストークンのセッションが失効済みかどうかを判定する
+type SessionRevocationChecker interface {
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
 }
 
+// SetSessionRevocations は失効したセッションのアクセストークンを拒否するための判定を設定する
+func SetSessionRevocations(checker SessionRevocationChecker) {
+	sessionRevocations = checker
+}
+
+var personalAccessTokens PersonalAccessTokenAuthenticator
+
+// PersonalAccessTokenAuthenticator は個人用アクセストークンを検証する
+type PersonalAccessTokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*auth.PersonalAccessToken, error)
+}
+
+// SetPersonalAccessTokens はScopedAuthMiddlewareで個人用アクセストークンを受け付けるための検証を設定する
+func SetPersonalAccessTokens(authenticator PersonalAccessTokenAuthenticator) {
+	personalAccessTokens = authenticator
+}
+
+var lastSeen LastSeenRecorder
+
+// LastSeenRecorder はユーザーの最終利用日時を記録する（利用のないゲストの自動削除に使う）
+type LastSeenRecorder interface {
+	RecordLastSeen(ctx context.Context, userID string) error
+}
+
+// SetLastSeenRecorder は認証したリクエストごとに最終利用日時を記録するための設定を行う
+func SetLastSeenRecorder(recorder LastSeenRecorder) {
+	lastSeen = recorder
+}
+
+// recordLastSeen は最終利用日時を記録する（失敗してもリクエストは続ける）
+func recordLastSeen(c *gin.Context, userID string) {
+	if lastSeen == nil {
+		return
+	}
+	if err := lastSeen.RecordLastSeen(c.Request.Context(), userID); err != nil {
+		log.Printf("[WARN] AuthMiddleware: 最終利用日時の記録に失敗しました user_id=%s: %v", userID, err)
+	}
+}
+
+var authFailures AuthFailureRecorder
+
+// AuthFailureRecorder は受け付けなかったトークンを監査ログに記録する
+type AuthFailureRecorder interface {
+	RecordAuthFailure(ctx context.Context, reason string)
+}
+
+// SetAuthFailureRecorder は認証に失敗したリクエストを監査ログに記録するための設定を行う
+func SetAuthFailureRecorder(recorder AuthFailureRecorder) {
+	authFailures = recorder
+}
+
+// recordAuthFailure は認証の失敗を記録する（トークンがない・期限切れのリクエストは通常の利用でも起きるため記録しない）
+func recordAuthFailure(c *gin.Context, reason string) {
+	if authFailures == nil {
+		return
+	}
+	authFailures.RecordAuthFailure(c.Request.Context(), reason)
+}
+
+// authenticated は認証したユーザーをリクエストのコンテキストに設定する（ユースケースが記録する監査ログの操作者になる）
+func authenticated(c *gin.Context, userID string) {
+	c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), userID))
+	recordLastSeen(c, userID)
+}
+
+// JWTAuthMiddleware はログインで発行したアクセストークン（JWT）のみを受け付ける（個人用アクセストークンは拒否する）
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		header := c.GetHeader("Authorization")
-		if header == "" || !strings.HasPrefix(header, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "認証トークンが必要です"})
-			c.Abort()
+		tokenString, ok := bearerToken(c)
+		if !ok {
 			return
 		}
-		tokenString := strings.TrimPrefix(header, "Bearer ")
-		userID, err := infra.ParseToken(tokenString)
-		if err != nil {
+		if authenticateJWT(c, tokenString) {
+			c.Next()
+		}
+	}
+}
+
+// ScopedAuthMiddleware はアクセストークン（JWT）に加えて、scopeを含む個人用アクセストークンも受け付ける
+func ScopedAuthMiddleware(scope auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := bearerToken(c)
+		if !ok {
+			return
+		}
+		if !auth.IsPersonalAccessToken(tokenString) {
+			if authenticateJWT(c, tokenString) {
+				c.Next()
+			}
+			return
+		}
+		if personalAccessTokens == nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "無効なトークンです"})
 			c.Abort()
 			return
 		}
-		// userIDからユーザー情報取得
-		var isGuest bool
-		if userRepo != nil {
-			u, err := userRepo.FindByID(c.Request.Context(), userID)
-			if err == nil && u != nil {
-				isGuest = u.IsGuest
+		token, err := personalAccessTokens.Authenticate(c.Request.Context(), tokenString)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidPersonalAccessToken) {
+				recordAuthFailure(c, "invalid_personal_access_token")
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			} else {
+				log.Printf("[ERROR] ScopedAuthMiddleware: アクセストークンの確認に失敗しました: %v", err)
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "認証状態の確認に失敗しました"})
 			}
+			c.Abort()
+			return
+		}
+		if !token.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": auth.ErrInsufficientScope.Error(), "required_scope": string(scope)})
+			c.Abort()
+			return
 		}
-		c.Set("userID", userID)
-		c.Set("isGuest", isGuest)
+		// ゲストは個人用アクセストークンを発行できず、ロールは個人用アクセストークンには引き継がない
+		c.Set("userID", token.UserID)
+		c.Set("sessionID", "")
+		c.Set("isGuest", false)
+		c.Set("roles", []string(nil))
+		c.Set("personalAccessTokenID", token.ID)
+		authenticated(c, token.UserID)
 		c.Next()
 	}
 }
+
+// bearerToken はAuthorizationヘッダからBearerトークンを取り出す（ない場合は401を返して中断する）
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	if header == "" || !strings.HasPrefix(header, "Bearer ") {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "認証トークンが必要です"})
+		c.Abort()
+		return "", false
+	}
+	return strings.TrimPrefix(header, "Bearer "), true
+}
+
+// authenticateJWT はアクセストークン（JWT）を検証してユーザーの情報をコンテキストに設定する（失敗した場合は応答して中断する）
+func authenticateJWT(c *gin.Context, tokenString string) bool {
+	claims, err := infra.ParseTokenClaims(tokenString)
+	if err != nil {
+		if !errors.Is(err, jwt.ErrTokenExpired) {
+			recordAuthFailure(c, "invalid_access_token")
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "無効なトークンです"})
+		c.Abort()
+		return false
+	}
+	userID := claims.UserID()
+	if claims.SessionID != "" && sessionRevocations != nil {
+		revoked, err := sessionRevocations.IsRevoked(c.Request.Context(), claims.SessionID)
+		if err != nil {
+			log.Printf("[ERROR] JWTAuthMiddleware: セッションの失効状態の確認に失敗しました: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "認証状態の確認に失敗しました"})
+			c.Abort()
+			return false
+		}
+		if revoked {
+			recordAuthFailure(c, "revoked_session")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "セッションは失効しています。再度ログインしてください"})
+			c.Abort()
+			return false
+		}
+	}
+	// ゲストかどうか・ロールはトークンのクレームから判定する（リクエストごとにDBを参照しない）
+	c.Set("userID", userID)
+	c.Set("sessionID", claims.SessionID)
+	c.Set("isGuest", claims.Guest)
+	c.Set("roles", claims.Roles)
+	authenticated(c, userID)
+	return true
+}
@@ -5,58 +5,42 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"tofunote-backend/domain/user"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/domain/auth"
 	"tofunote-backend/infra"
+	"tofunote-backend/infra/authtoken"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
-type mockUserRepo struct {
-	userByID *user.User
-}
-
-func (m *mockUserRepo) FindByID(ctx context.Context, id string) (*user.User, error) {
-	return m.userByID, nil
-}
-func (m *mockUserRepo) FindByProviderId(ctx context.Context, provider, providerId string) (*user.User, error) {
-	return nil, nil
-}
-func (m *mockUserRepo) Create(ctx context.Context, u *user.User) error { return nil }
-func (m *mockUserRepo) Update(ctx context.Context, u *user.User) error { return nil }
-func (m *mockUserRepo) FindByRefreshToken(ctx context.Context, refreshToken string) (*user.User, error) {
-	return nil, nil
-}
-func (m *mockUserRepo) DeleteByID(ctx context.Context, id string) error {
-	return nil
-}
-
 func TestJWTAuthMiddleware_TableDriven(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	testUUID := "test-uuid" // Changed from uuid.New().String()
-	testToken, _ := infra.GenerateToken(testUUID)
+	guestToken, _ := infra.GenerateToken(infra.AccessTokenSubject{UserID: testUUID, Guest: true})
+	memberToken, _ := infra.GenerateToken(infra.AccessTokenSubject{UserID: testUUID})
+	// 二段階認証の確認トークンはJWT_SECRETで署名される場合でもアクセストークンとしては使えない
+	ticketSecret, _ := authtoken.SecretFromEnv()
+	mfaPendingToken, _ := authtoken.NewSignedMFAChallengeStore(ticketSecret, authtoken.DefaultMFAChallengeTTL).Issue(auth.MFAChallenge{UserID: testUUID})
 
 	tests := []struct {
 		name       string
 		header     string
-		mockUser   *user.User
 		wantStatus int
 		wantGuest  *bool // nilならisGuestチェックしない
 		comment    string
 	}{
 		{
 			name:       "有効なトークン(ゲスト)",
-			header:     "Bearer " + testToken,
-			mockUser:   &user.User{ID: testUUID, IsGuest: true},
+			header:     "Bearer " + guestToken,
 			wantStatus: 200,
 			wantGuest:  ptr(true),
 			comment:    "ゲストユーザーで認証通過",
 		},
 		{
 			name:       "有効なトークン(正式ユーザー)",
-			header:     "Bearer " + testToken,
-			mockUser:   &user.User{ID: testUUID, IsGuest: false},
+			header:     "Bearer " + memberToken,
 			wantStatus: 200,
 			wantGuest:  ptr(false),
 			comment:    "正式ユーザーで認証通過",
@@ -64,15 +48,20 @@ func TestJWTAuthMiddleware_TableDriven(t *testing.T) {
 		{
 			name:       "トークンなし",
 			header:     "",
-			mockUser:   nil,
 			wantStatus: 401,
 			wantGuest:  nil,
 			comment:    "トークンなしで401",
 		},
+		{
+			name:       "二段階認証の確認トークン",
+			header:     "Bearer " + mfaPendingToken,
+			wantStatus: 401,
+			wantGuest:  nil,
+			comment:    "二段階認証が済んでいないため401",
+		},
 		{
 			name:       "無効なトークン",
 			header:     "Bearer invalidtoken",
-			mockUser:   nil,
 			wantStatus: 401,
 			wantGuest:  nil,
 			comment:    "不正なトークンで401",
@@ -81,9 +70,6 @@ func TestJWTAuthMiddleware_TableDriven(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			SetAuthDB(nil)
-			userRepo = &mockUserRepo{userByID: tt.mockUser}
-
 			r := gin.New()
 			r.Use(JWTAuthMiddleware())
 			r.GET("/protected", func(c *gin.Context) {
@@ -105,6 +91,132 @@ func TestJWTAuthMiddleware_TableDriven(t *testing.T) {
 	}
 }
 
+type fakeRevocations struct {
+	revoked map[string]bool
+}
+
+func (f *fakeRevocations) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	return f.revoked[sessionID], nil
+}
+
+func TestJWTAuthMiddleware_RevokedSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetSessionRevocations(&fakeRevocations{revoked: map[string]bool{"revoked-session": true}})
+	defer SetSessionRevocations(nil)
+
+	r := gin.New()
+	r.Use(JWTAuthMiddleware())
+	r.GET("/protected", func(c *gin.Context) {
+		sessionID, _ := c.Get("sessionID")
+		c.JSON(200, gin.H{"sessionID": sessionID})
+	})
+
+	for _, tt := range []struct {
+		name       string
+		sessionID  string
+		wantStatus int
+	}{
+		{name: "有効なセッション", sessionID: "active-session", wantStatus: 200},
+		{name: "失効したセッション", sessionID: "revoked-session", wantStatus: 401},
+		{name: "セッションなしのトークン", sessionID: "", wantStatus: 200},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := infra.GenerateToken(infra.AccessTokenSubject{UserID: "u1", SessionID: tt.sessionID})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == 200 {
+				assert.Contains(t, w.Body.String(), `"sessionID":"`+tt.sessionID+`"`)
+			}
+		})
+	}
+}
+
+type fakeLastSeenRecorder struct {
+	userIDs []string
+}
+
+func (f *fakeLastSeenRecorder) RecordLastSeen(ctx context.Context, userID string) error {
+	f.userIDs = append(f.userIDs, userID)
+	return nil
+}
+
+func TestJWTAuthMiddleware_RecordsLastSeen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &fakeLastSeenRecorder{}
+	SetLastSeenRecorder(recorder)
+	defer SetLastSeenRecorder(nil)
+
+	r := gin.New()
+	r.Use(JWTAuthMiddleware())
+	r.GET("/protected", func(c *gin.Context) { c.Status(200) })
+
+	token, _ := infra.GenerateToken(infra.AccessTokenSubject{UserID: "u1", Guest: true})
+	for _, header := range []string{"Bearer " + token, "Bearer invalid"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", header)
+		r.ServeHTTP(w, req)
+	}
+	assert.Equal(t, []string{"u1"}, recorder.userIDs, "認証に失敗したリクエストは記録しない")
+}
+
+type fakePersonalAccessTokens struct {
+	tokens map[string]*auth.PersonalAccessToken
+}
+
+func (f *fakePersonalAccessTokens) Authenticate(ctx context.Context, token string) (*auth.PersonalAccessToken, error) {
+	t, ok := f.tokens[token]
+	if !ok {
+		return nil, auth.ErrInvalidPersonalAccessToken
+	}
+	return t, nil
+}
+
+func TestScopedAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetPersonalAccessTokens(&fakePersonalAccessTokens{tokens: map[string]*auth.PersonalAccessToken{
+		"tfn_pat_read": {ID: "pat1", UserID: "u1", Scopes: []auth.Scope{auth.ScopeDiariesRead}},
+	}})
+	defer SetPersonalAccessTokens(nil)
+	memberToken, _ := infra.GenerateToken(infra.AccessTokenSubject{UserID: "u1"})
+
+	r := gin.New()
+	r.GET("/diaries", ScopedAuthMiddleware(auth.ScopeDiariesRead), func(c *gin.Context) {
+		c.JSON(200, gin.H{"userID": c.GetString("userID")})
+	})
+	r.POST("/diaries", ScopedAuthMiddleware(auth.ScopeDiariesWrite), func(c *gin.Context) {
+		c.Status(200)
+	})
+	r.GET("/me", JWTAuthMiddleware(), func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	for _, tt := range []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "スコープを含むトークン", method: "GET", path: "/diaries", token: "tfn_pat_read", wantStatus: 200},
+		{name: "スコープを含まないトークン", method: "POST", path: "/diaries", token: "tfn_pat_read", wantStatus: 403},
+		{name: "取り消し済み・期限切れのトークン", method: "GET", path: "/diaries", token: "tfn_pat_revoked", wantStatus: 401},
+		{name: "スコープを指定していないAPI", method: "GET", path: "/me", token: "tfn_pat_read", wantStatus: 401},
+		{name: "ログインのアクセストークンは全てのスコープを持つ", method: "POST", path: "/diaries", token: memberToken, wantStatus: 200},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
 func ptr(b bool) *bool { return &b }
 func boolStr(b bool) string {
 	if b {
@@ -112,3 +224,41 @@ func boolStr(b bool) string {
 	}
 	return "false"
 }
+
+type fakeAuthFailureRecorder struct {
+	reasons []string
+}
+
+func (f *fakeAuthFailureRecorder) RecordAuthFailure(ctx context.Context, reason string) {
+	f.reasons = append(f.reasons, reason)
+}
+
+func TestJWTAuthMiddleware_RecordsAuthFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &fakeAuthFailureRecorder{}
+	SetAuthFailureRecorder(recorder)
+	defer SetAuthFailureRecorder(nil)
+	SetSessionRevocations(&fakeRevocations{revoked: map[string]bool{"revoked-session": true}})
+	defer SetSessionRevocations(nil)
+
+	r := gin.New()
+	r.Use(JWTAuthMiddleware())
+	var actor string
+	r.GET("/protected", func(c *gin.Context) {
+		actor = audit.RequestInfoFrom(c.Request.Context()).ActorUserID
+		c.Status(200)
+	})
+
+	valid, _ := infra.GenerateToken(infra.AccessTokenSubject{UserID: "u1"})
+	revoked, _ := infra.GenerateToken(infra.AccessTokenSubject{UserID: "u1", SessionID: "revoked-session"})
+	for _, header := range []string{"", "Bearer invalid", "Bearer " + revoked, "Bearer " + valid} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		r.ServeHTTP(w, req)
+	}
+	assert.Equal(t, []string{"invalid_access_token", "revoked_session"}, recorder.reasons, "トークンがないリクエストは記録しない")
+	assert.Equal(t, "u1", actor, "認証したユーザーを監査ログの操作者としてコンテキストに設定する")
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"regexp"
+
+	"tofunote-backend/domain/audit"
+
+	"github.com/cmackenzie1/go-uuid"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader はリクエストIDを受け渡すヘッダ（問い合わせ時に監査ログ・アプリのログと突き合わせる）
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID はクライアント・API Gatewayから受け取るリクエストIDの形式（ログに出力するため文字種と長さを制限する）
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestContext はリクエストID・IPアドレス・User-Agentを監査ログ用にリクエストのコンテキストへ設定する
+// （X-Request-IDがない、または形式が不正な場合は新しく採番し、応答のヘッダにも返す）
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = ""
+			if id, err := uuid.NewV7(); err == nil {
+				requestID = id.String()
+			}
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Set("requestID", requestID)
+		ctx := audit.WithRequestInfo(c.Request.Context(), audit.RequestInfo{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: requestID,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
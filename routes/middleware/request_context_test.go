@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tofunote-backend/domain/audit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RequestContext())
+	var got audit.RequestInfo
+	r.GET("/", func(c *gin.Context) {
+		got = audit.RequestInfoFrom(c.Request.Context())
+		c.Status(200)
+	})
+
+	t.Run("受け取ったリクエストIDを引き継ぐ", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+		req.Header.Set("User-Agent", "test-agent")
+		req.RemoteAddr = "203.0.113.1:12345"
+		r.ServeHTTP(w, req)
+		assert.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, audit.RequestInfo{IPAddress: "203.0.113.1", UserAgent: "test-agent", RequestID: "abc-123"}, got)
+	})
+
+	for _, header := range []string{"", "改行\nを含む", string(make([]byte, 200))} {
+		t.Run("ない・不正な場合は採番する", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set(RequestIDHeader, header)
+			r.ServeHTTP(w, req)
+			assert.Len(t, got.RequestID, 36)
+			assert.NotEqual(t, header, got.RequestID)
+			assert.Equal(t, got.RequestID, w.Header().Get(RequestIDHeader))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var roleVerifier RoleVerifier
+
+// RoleVerifier はユーザーに現在ロールが付与されているかどうかをDBで確認する
+type RoleVerifier interface {
+	HasAnyRole(ctx context.Context, userID string, roles ...string) (bool, error)
+}
+
+// SetRoleVerifier はトークン発行後に取り消されたロールを拒否するための確認を設定する
+// （アクセストークンの有効期限内はクレームのロールが古い可能性があるため）
+func SetRoleVerifier(verifier RoleVerifier) {
+	roleVerifier = verifier
+}
+
+// RequireRole はJWTAuthMiddlewareの後に使い、指定したロールのいずれかを持つユーザーのみ通す
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claimed, _ := c.Get("roles")
+		claimedRoles, _ := claimed.([]string)
+		if !containsAny(claimedRoles, roles) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "この操作を行う権限がありません"})
+			c.Abort()
+			return
+		}
+		if roleVerifier != nil {
+			ok, err := roleVerifier.HasAnyRole(c.Request.Context(), c.GetString("userID"), roles...)
+			if err != nil {
+				log.Printf("[ERROR] RequireRole: ロールの確認に失敗しました: %v", err)
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "権限の確認に失敗しました"})
+				c.Abort()
+				return
+			}
+			if !ok {
+				c.JSON(http.StatusForbidden, gin.H{"error": "この操作を行う権限がありません"})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+func containsAny(values, targets []string) bool {
+	for _, v := range values {
+		for _, t := range targets {
+			if v == t {
+				return true
+			}
+		}
+	}
+	return false
+}
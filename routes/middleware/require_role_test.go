@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRoleVerifier struct {
+	granted map[string]bool
+}
+
+func (f fakeRoleVerifier) HasAnyRole(ctx context.Context, userID string, roles ...string) (bool, error) {
+	return f.granted[userID], nil
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { SetRoleVerifier(nil) })
+
+	tests := []struct {
+		name       string
+		userID     string
+		roles      []string
+		verifier   RoleVerifier
+		wantStatus int
+	}{
+		{name: "ロールあり", userID: "u1", roles: []string{"admin"}, wantStatus: http.StatusOK},
+		{name: "ロールなし", userID: "u1", roles: nil, wantStatus: http.StatusForbidden},
+		{name: "別のロール", userID: "u1", roles: []string{"support"}, wantStatus: http.StatusForbidden},
+		{name: "DBで付与を確認できる", userID: "u1", roles: []string{"admin"}, verifier: fakeRoleVerifier{granted: map[string]bool{"u1": true}}, wantStatus: http.StatusOK},
+		{name: "トークン発行後に取り消された", userID: "u2", roles: []string{"admin"}, verifier: fakeRoleVerifier{granted: map[string]bool{"u1": true}}, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRoleVerifier(tt.verifier)
+			r := gin.New()
+			r.GET("/admin", func(c *gin.Context) {
+				c.Set("userID", tt.userID)
+				c.Set("roles", tt.roles)
+			}, RequireRole("admin"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
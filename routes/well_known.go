@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"net/http"
+	"tofunote-backend/infra/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupWellKnownEndpoints JWT検証用の公開鍵（JWKS）のエンドポイントを設定
+func SetupWellKnownEndpoints(router *gin.Engine, keys *jwtkeys.Manager) {
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		// 鍵のローテーションが反映されるよう、キャッシュは短めにする
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keys.JWKS())
+	})
+}
@@ -64,10 +64,18 @@ func main() {
 			continue
 		}
 
+		// 日付を検証（アプリからの作成と同じく、存在しない日付は取り込まない）
+		date, err := diary.NewDate(entry.Date)
+		if err != nil {
+			log.Printf("日付が無効です (日付: %s): %v", entry.Date, err)
+			errorCount++
+			continue
+		}
+
 		// 日記エンティティを作成
 		diaryEntry := &diary.Diary{
 			UserID: userID,
-			Date:   entry.Date,
+			Date:   date,
 			Mental: mental,
 			Diary:  entry.Content,
 		}
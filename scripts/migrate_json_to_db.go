@@ -58,16 +58,24 @@ func main() {
 			continue
 		}
 
+		// 日付を検証（アプリからの作成と同じく、存在しない日付は取り込まない）
+		date, err := diary.NewDate(entry.Date)
+		if err != nil {
+			log.Printf("日付が無効です (日付: %s): %v", entry.Date, err)
+			errorCount++
+			continue
+		}
+
 		// 日記エンティティを作成
 		diaryEntry := &diary.Diary{
 			UserID: userID,
-			Date:   entry.Date,
+			Date:   date,
 			Mental: mental,
 			Diary:  entry.Content,
 		}
 
 		// 既存の日記を確認
-		existingDiary, err := diaryRepo.FindByUserIDAndDate(userID, entry.Date)
+		existingDiary, err := diaryRepo.FindByUserIDAndDate(userID, date)
 		if err != nil {
 			// エラーが発生した場合（データが見つからない場合など）は新規作成を試行
 			err = diaryRepo.Create(diaryEntry)
@@ -83,7 +91,7 @@ func main() {
 			existingDiary.Mental = mental
 			existingDiary.Diary = entry.Content
 
-			err = diaryRepo.Update(userID, entry.Date, existingDiary)
+			err = diaryRepo.Update(userID, date, existingDiary)
 			if err != nil {
 				log.Printf("日記の更新に失敗しました (日付: %s): %v", entry.Date, err)
 				errorCount++
@@ -0,0 +1,195 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+)
+
+var (
+	ErrNotGuest     = errors.New("ゲストユーザーのみアカウントを連携できます")
+	ErrUserNotFound = errors.New("ユーザーが見つかりません")
+	// ErrMergeMFARequired は統合先で二段階認証が有効なのに認証コードが指定されていないことを表す
+	ErrMergeMFARequired = errors.New("統合先のアカウントは二段階認証が有効なため、認証コードまたはリカバリーコードを指定してください")
+)
+
+// LinkResult はゲストアカウントへの外部アカウント連携の結果
+type LinkResult struct {
+	// User は連携後のユーザー（統合が必要な場合はnil）
+	User *user.User
+	// MergeTicket は外部アカウントが既に別ユーザーのものだった場合に、統合を確定するためのトークン
+	MergeTicket string
+	// MFARequired は統合先で二段階認証が有効なため、統合の際に認証コードが必要なことを表す
+	MFARequired bool
+}
+
+// NeedsMerge は連携先が既存ユーザーのため統合の確認が必要かどうかを返す
+func (r *LinkResult) NeedsMerge() bool {
+	return r.MergeTicket != ""
+}
+
+type IAccountLinkUsecase interface {
+	LinkOIDC(ctx context.Context, guestID, providerName, code, state, flowToken string) (*LinkResult, error)
+	LinkPassword(ctx context.Context, guestID, email, password string) (*LinkResult, error)
+	// Merge は統合先で二段階認証が有効な場合、mfaCode（認証コードまたはリカバリーコード）を確認してから統合する
+	Merge(ctx context.Context, guestID, mergeTicket, mfaCode string, policy diary.ConflictPolicy) (*user.User, *user.MergeResult, error)
+}
+
+type AccountLinkUsecase struct {
+	oidcLogin      IOIDCLoginUsecase
+	passwordAuth   IPasswordAuthUsecase
+	userRepository user.Repository
+	merger         user.AccountMerger
+	tickets        auth.MergeTicketStore
+	mfa            ITOTPUsecase
+	audits         IAuditUsecase
+	now            func() time.Time
+}
+
+func NewAccountLinkUsecase(oidcLogin IOIDCLoginUsecase, passwordAuth IPasswordAuthUsecase, userRepository user.Repository, merger user.AccountMerger, tickets auth.MergeTicketStore, mfa ITOTPUsecase, audits IAuditUsecase) IAccountLinkUsecase {
+	return &AccountLinkUsecase{
+		oidcLogin:      oidcLogin,
+		passwordAuth:   passwordAuth,
+		userRepository: userRepository,
+		merger:         merger,
+		tickets:        tickets,
+		mfa:            mfa,
+		audits:         audits,
+		now:            time.Now,
+	}
+}
+
+func (u *AccountLinkUsecase) findGuest(ctx context.Context, guestID string) (*user.User, error) {
+	guest, err := u.userRepository.FindByID(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+	if guest == nil {
+		return nil, ErrUserNotFound
+	}
+	if !guest.IsGuest {
+		return nil, ErrNotGuest
+	}
+	return guest, nil
+}
+
+// LinkOIDC はゲストユーザーに外部アカウントを連携して通常のユーザーにする。
+// 外部アカウントが既に別のユーザーに連携されている場合は、統合を確定するためのトークンを返す
+func (u *AccountLinkUsecase) LinkOIDC(ctx context.Context, guestID, providerName, code, state, flowToken string) (*LinkResult, error) {
+	guest, err := u.findGuest(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := u.oidcLogin.Authenticate(ctx, providerName, code, state, flowToken)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := u.userRepository.FindByProviderId(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		return u.issueMergeTicket(ctx, guest.ID, owner.ID)
+	}
+
+	guest.Provider = identity.Provider
+	guest.ProviderID = identity.Subject
+	guest.IsGuest = false
+	if guest.Nickname == "ゲスト" && identity.Name != "" {
+		guest.Nickname = identity.Name
+	}
+	if err := u.userRepository.Update(ctx, guest); err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, u.audits, audit.Event{ActorUserID: guest.ID, Action: audit.ActionAccountLink, TargetUserID: guest.ID, Detail: identity.Provider})
+	return &LinkResult{User: guest}, nil
+}
+
+// LinkPassword はゲストユーザーにメールアドレスとパスワードを設定して通常のユーザーにする。
+// メールアドレスが既に別のユーザーのものだった場合は、そのユーザーのパスワードで認証できたときに限り統合用のトークンを返す
+func (u *AccountLinkUsecase) LinkPassword(ctx context.Context, guestID, email, password string) (*LinkResult, error) {
+	guest, err := u.findGuest(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+	err = u.passwordAuth.SetCredentials(ctx, guest, email, password)
+	if err == nil {
+		recordAudit(ctx, u.audits, audit.Event{ActorUserID: guest.ID, Action: audit.ActionAccountLink, TargetUserID: guest.ID, Detail: "password"})
+		return &LinkResult{User: guest}, nil
+	}
+	if !errors.Is(err, ErrEmailAlreadyRegistered) {
+		return nil, err
+	}
+	owner, err := u.passwordAuth.Login(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+	return u.issueMergeTicket(ctx, guest.ID, owner.ID)
+}
+
+// issueMergeTicket は統合用のトークンを発行し、統合の際に二段階認証の認証コードが必要かどうかを合わせて返す
+func (u *AccountLinkUsecase) issueMergeTicket(ctx context.Context, guestID, targetID string) (*LinkResult, error) {
+	mfaRequired, err := u.requiresMFA(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	ticket, err := u.tickets.Issue(auth.MergeTicket{GuestUserID: guestID, TargetUserID: targetID})
+	if err != nil {
+		return nil, err
+	}
+	return &LinkResult{MergeTicket: ticket, MFARequired: mfaRequired}, nil
+}
+
+func (u *AccountLinkUsecase) requiresMFA(ctx context.Context, userID string) (bool, error) {
+	status, err := u.mfa.Status(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return status.TOTPEnabled, nil
+}
+
+// Merge はLinkOIDC・LinkPasswordで発行したトークンを検証し、ゲストのデータを既存ユーザーへ統合してゲストを削除する。
+// トークンはパスワード・外部アカウントのみで発行するため、統合先で二段階認証が有効な場合は統合の前に認証コードを確認する
+func (u *AccountLinkUsecase) Merge(ctx context.Context, guestID, mergeTicket, mfaCode string, policy diary.ConflictPolicy) (*user.User, *user.MergeResult, error) {
+	ticket, err := u.tickets.Verify(mergeTicket)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ticket.GuestUserID != guestID {
+		return nil, nil, auth.ErrInvalidMergeTicket
+	}
+	if _, err := u.findGuest(ctx, guestID); err != nil {
+		return nil, nil, err
+	}
+	target, err := u.userRepository.FindByID(ctx, ticket.TargetUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target == nil {
+		return nil, nil, ErrUserNotFound
+	}
+	mfaRequired, err := u.requiresMFA(ctx, target.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mfaRequired {
+		if strings.TrimSpace(mfaCode) == "" {
+			return nil, nil, ErrMergeMFARequired
+		}
+		if err := u.mfa.Verify(ctx, target.ID, mfaCode); err != nil {
+			return nil, nil, err
+		}
+	}
+	result, err := u.merger.MergeGuestInto(ctx, guestID, target.ID, policy, u.now())
+	if err != nil {
+		return nil, nil, err
+	}
+	// 統合後はゲストが削除されるため、統合先のユーザーの監査ログとして残す
+	recordAudit(ctx, u.audits, audit.Event{ActorUserID: guestID, Action: audit.ActionAccountMerge, TargetUserID: target.ID, Detail: "guest_user_id=" + guestID})
+	return target, result, nil
+}
@@ -0,0 +1,182 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOIDCLogin struct {
+	IOIDCLoginUsecase
+	identity *auth.Identity
+	err      error
+}
+
+func (f *fakeOIDCLogin) Authenticate(ctx context.Context, providerName, code, state, flowToken string) (*auth.Identity, error) {
+	return f.identity, f.err
+}
+
+type memoryUserRepo struct {
+	mockUserRepo
+	users map[string]*user.User
+}
+
+func (m *memoryUserRepo) FindByID(ctx context.Context, id string) (*user.User, error) {
+	return m.users[id], nil
+}
+func (m *memoryUserRepo) FindByProviderId(ctx context.Context, provider, providerId string) (*user.User, error) {
+	for _, u := range m.users {
+		if u.Provider == provider && u.ProviderID == providerId {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+func (m *memoryUserRepo) Update(ctx context.Context, u *user.User) error {
+	m.users[u.ID] = u
+	return nil
+}
+
+type fakeAccountMerger struct {
+	guestID, targetID string
+	policy            diary.ConflictPolicy
+}
+
+func (f *fakeAccountMerger) MergeGuestInto(ctx context.Context, guestID, targetID string, policy diary.ConflictPolicy, now time.Time) (*user.MergeResult, error) {
+	f.guestID, f.targetID, f.policy = guestID, targetID, policy
+	return &user.MergeResult{MovedDiaries: 3, ConflictedDiaries: 1}, nil
+}
+
+type memoryMergeTicketStore struct {
+	tickets map[string]auth.MergeTicket
+}
+
+func (s *memoryMergeTicketStore) Issue(ticket auth.MergeTicket) (string, error) {
+	token := "ticket-" + ticket.GuestUserID
+	s.tickets[token] = ticket
+	return token, nil
+}
+func (s *memoryMergeTicketStore) Verify(token string) (*auth.MergeTicket, error) {
+	ticket, ok := s.tickets[token]
+	if !ok {
+		return nil, auth.ErrInvalidMergeTicket
+	}
+	return &ticket, nil
+}
+
+// fakeStepUpMFA は enabled のユーザーのみ二段階認証が有効で、code だけを正しい認証コードとして受け付ける
+type fakeStepUpMFA struct {
+	ITOTPUsecase
+	enabled map[string]bool
+	code    string
+}
+
+func (f *fakeStepUpMFA) Status(ctx context.Context, userID string) (*MFAStatus, error) {
+	return &MFAStatus{TOTPEnabled: f.enabled[userID]}, nil
+}
+func (f *fakeStepUpMFA) Verify(ctx context.Context, userID, code string) error {
+	if !f.enabled[userID] {
+		return auth.ErrTOTPNotEnabled
+	}
+	if code != f.code {
+		return auth.ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+func newAccountLinkTestUsecase(identity *auth.Identity) (IAccountLinkUsecase, *memoryUserRepo, *fakeAccountMerger) {
+	repo := &memoryUserRepo{users: map[string]*user.User{
+		"guest":  {ID: "guest", Nickname: "ゲスト", IsGuest: true},
+		"member": {ID: "member", Nickname: "本人", Provider: "google", ProviderID: "taken"},
+	}}
+	merger := &fakeAccountMerger{}
+	uc := NewAccountLinkUsecase(&fakeOIDCLogin{identity: identity}, nil, repo, merger, &memoryMergeTicketStore{tickets: map[string]auth.MergeTicket{}}, &fakeStepUpMFA{}, nil)
+	return uc, repo, merger
+}
+
+func TestAccountLinkUsecase_LinkOIDC(t *testing.T) {
+	t.Run("正常系: 未使用の外部アカウントを連携", func(t *testing.T) {
+		uc, repo, _ := newAccountLinkTestUsecase(&auth.Identity{Provider: "google", Subject: "new", Name: "山田"})
+		result, err := uc.LinkOIDC(context.Background(), "guest", "google", "code", "state", "flow")
+		require.NoError(t, err)
+		assert.False(t, result.NeedsMerge())
+		assert.False(t, repo.users["guest"].IsGuest)
+		assert.Equal(t, "google", repo.users["guest"].Provider)
+		assert.Equal(t, "new", repo.users["guest"].ProviderID)
+		assert.Equal(t, "山田", repo.users["guest"].Nickname)
+	})
+	t.Run("正常系: 既に別ユーザーの外部アカウントなら統合用トークンを返す", func(t *testing.T) {
+		uc, repo, _ := newAccountLinkTestUsecase(&auth.Identity{Provider: "google", Subject: "taken"})
+		result, err := uc.LinkOIDC(context.Background(), "guest", "google", "code", "state", "flow")
+		require.NoError(t, err)
+		assert.True(t, result.NeedsMerge())
+		assert.True(t, repo.users["guest"].IsGuest)
+	})
+	t.Run("異常系: ゲスト以外は連携できない", func(t *testing.T) {
+		uc, _, _ := newAccountLinkTestUsecase(&auth.Identity{Provider: "google", Subject: "new"})
+		_, err := uc.LinkOIDC(context.Background(), "member", "google", "code", "state", "flow")
+		assert.ErrorIs(t, err, ErrNotGuest)
+	})
+}
+
+func TestAccountLinkUsecase_Merge(t *testing.T) {
+	uc, _, merger := newAccountLinkTestUsecase(&auth.Identity{Provider: "google", Subject: "taken"})
+	link, err := uc.LinkOIDC(context.Background(), "guest", "google", "code", "state", "flow")
+	require.NoError(t, err)
+
+	t.Run("異常系: 別のユーザーのトークン", func(t *testing.T) {
+		_, _, err := uc.Merge(context.Background(), "other-guest", link.MergeTicket, "", diary.ConflictKeepGuest)
+		assert.ErrorIs(t, err, auth.ErrInvalidMergeTicket)
+	})
+	t.Run("異常系: 不正なトークン", func(t *testing.T) {
+		_, _, err := uc.Merge(context.Background(), "guest", "forged", "", diary.ConflictKeepGuest)
+		assert.ErrorIs(t, err, auth.ErrInvalidMergeTicket)
+	})
+	t.Run("正常系: 統合先ユーザーへ統合", func(t *testing.T) {
+		target, result, err := uc.Merge(context.Background(), "guest", link.MergeTicket, "", diary.ConflictKeepGuest)
+		require.NoError(t, err)
+		assert.Equal(t, "member", target.ID)
+		assert.Equal(t, &user.MergeResult{MovedDiaries: 3, ConflictedDiaries: 1}, result)
+		assert.Equal(t, "guest", merger.guestID)
+		assert.Equal(t, "member", merger.targetID)
+		assert.Equal(t, diary.ConflictKeepGuest, merger.policy)
+	})
+}
+
+func TestAccountLinkUsecase_MergeRequiresMFA(t *testing.T) {
+	repo := &memoryUserRepo{users: map[string]*user.User{
+		"guest":  {ID: "guest", Nickname: "ゲスト", IsGuest: true},
+		"member": {ID: "member", Nickname: "本人", Provider: "google", ProviderID: "taken"},
+	}}
+	merger := &fakeAccountMerger{}
+	mfa := &fakeStepUpMFA{enabled: map[string]bool{"member": true}, code: "123456"}
+	uc := NewAccountLinkUsecase(&fakeOIDCLogin{identity: &auth.Identity{Provider: "google", Subject: "taken"}}, nil, repo, merger, &memoryMergeTicketStore{tickets: map[string]auth.MergeTicket{}}, mfa, nil)
+
+	link, err := uc.LinkOIDC(context.Background(), "guest", "google", "code", "state", "flow")
+	require.NoError(t, err)
+	assert.True(t, link.NeedsMerge())
+	assert.True(t, link.MFARequired)
+
+	t.Run("異常系: 認証コードなしでは統合しない", func(t *testing.T) {
+		_, _, err := uc.Merge(context.Background(), "guest", link.MergeTicket, "", diary.ConflictKeepGuest)
+		assert.ErrorIs(t, err, ErrMergeMFARequired)
+		assert.Empty(t, merger.targetID)
+	})
+	t.Run("異常系: 認証コードの誤り", func(t *testing.T) {
+		_, _, err := uc.Merge(context.Background(), "guest", link.MergeTicket, "000000", diary.ConflictKeepGuest)
+		assert.ErrorIs(t, err, auth.ErrInvalidTOTPCode)
+		assert.Empty(t, merger.targetID)
+	})
+	t.Run("正常系: 認証コードを確認して統合", func(t *testing.T) {
+		target, _, err := uc.Merge(context.Background(), "guest", link.MergeTicket, "123456", diary.ConflictKeepGuest)
+		require.NoError(t, err)
+		assert.Equal(t, "member", target.ID)
+		assert.Equal(t, "member", merger.targetID)
+	})
+}
@@ -0,0 +1,248 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"tofunote-backend/domain/admin"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+	"unicode/utf8"
+)
+
+var (
+	ErrAdminReasonRequired = errors.New("操作の理由を10文字以上で入力してください")
+	ErrCannotDeleteSelf    = errors.New("自分自身のアカウントは管理APIから削除できません")
+	ErrCannotRevokeOwnRole = errors.New("自分自身の管理者ロールは取り消せません")
+)
+
+const (
+	// minAdminReasonLength は監査ログに残す操作理由の最低文字数
+	minAdminReasonLength = 10
+	// adminStatsWindow は新規ユーザー数・アクティブユーザー数を集計する期間
+	adminStatsWindow  = 30 * 24 * time.Hour
+	defaultAdminLimit = 50
+	maxAdminListLimit = 200
+)
+
+// GlobalDiaryAnalyzer は全ユーザーの日記を横断して分析する
+type GlobalDiaryAnalyzer interface {
+	AnalyzeAllDiaries(ctx context.Context) (string, error)
+}
+
+// AdminStats は管理画面に表示する集計（人数が少ない項目は非表示にする）
+type AdminStats struct {
+	Since time.Time
+	Users admin.UserStats
+	Diary admin.DiaryStats
+	// Suppressed は人数が少ないため非表示にした項目があるかどうか
+	Suppressed bool
+}
+
+type IAdminUsecase interface {
+	SearchUsers(ctx context.Context, query user.SearchQuery) ([]*user.Summary, int64, error)
+	Stats(ctx context.Context) (*AdminStats, error)
+	// ForceDeleteUser は規約違反などのアカウントを日記を含めて削除する
+	ForceDeleteUser(ctx context.Context, adminUserID, targetUserID, reason string) error
+	GrantRole(ctx context.Context, adminUserID, targetUserID string, role user.Role) error
+	RevokeRole(ctx context.Context, adminUserID, targetUserID string, role user.Role) error
+	// BreakGlassDiaries は理由を監査ログに記録したうえでユーザーの日記の本文を返す（管理APIで本文を返すのはこの操作のみ）
+	BreakGlassDiaries(ctx context.Context, adminUserID, targetUserID, reason string) ([]diary.Diary, error)
+	RunGlobalAnalysis(ctx context.Context, adminUserID string) (string, error)
+	ListActionLogs(ctx context.Context, targetUserID string, limit, offset int) ([]*admin.ActionLog, error)
+	// HasAnyRole はユーザーに現在いずれかのロールが付与されているかどうかを返す（管理APIの呼び出しごとに確認する）
+	HasAnyRole(ctx context.Context, userID string, roles ...string) (bool, error)
+}
+
+type AdminUsecase struct {
+	directory       user.Directory
+	userRepository  user.Repository
+	roleRepository  user.RoleRepository
+	stats           admin.StatsRepository
+	actionLogs      admin.ActionLogRepository
+	diaryRepository diary.DiaryRepository
+	withdraw        *UserWithdrawUsecase
+	analyzer        GlobalDiaryAnalyzer
+	audits          IAuditUsecase
+	now             func() time.Time
+}
+
+func NewAdminUsecase(directory user.Directory, userRepository user.Repository, roleRepository user.RoleRepository, stats admin.StatsRepository, actionLogs admin.ActionLogRepository, diaryRepository diary.DiaryRepository, withdraw *UserWithdrawUsecase, analyzer GlobalDiaryAnalyzer, audits IAuditUsecase) IAdminUsecase {
+	return &AdminUsecase{
+		directory:       directory,
+		userRepository:  userRepository,
+		roleRepository:  roleRepository,
+		stats:           stats,
+		actionLogs:      actionLogs,
+		diaryRepository: diaryRepository,
+		withdraw:        withdraw,
+		analyzer:        analyzer,
+		audits:          audits,
+		now:             time.Now,
+	}
+}
+
+func (u *AdminUsecase) SearchUsers(ctx context.Context, query user.SearchQuery) ([]*user.Summary, int64, error) {
+	query.Limit = clampAdminLimit(query.Limit)
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+	u.access(ctx, "", "search_users", "")
+	return u.directory.Search(ctx, query)
+}
+
+func (u *AdminUsecase) Stats(ctx context.Context) (*AdminStats, error) {
+	since := u.now().Add(-adminStatsWindow)
+	u.access(ctx, "", "stats", "")
+	users, err := u.stats.UserStats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	diaries, err := u.stats.DiaryStats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	var byProviderSuppressed, mentalSuppressed bool
+	users.ByProvider, byProviderSuppressed = admin.SuppressSmallCohorts(users.ByProvider)
+	diaries.MentalScoreUsers, mentalSuppressed = admin.SuppressSmallCohorts(diaries.MentalScoreUsers)
+	return &AdminStats{
+		Since:      since,
+		Users:      *users,
+		Diary:      *diaries,
+		Suppressed: byProviderSuppressed || mentalSuppressed,
+	}, nil
+}
+
+func (u *AdminUsecase) ForceDeleteUser(ctx context.Context, adminUserID, targetUserID, reason string) error {
+	if adminUserID == targetUserID {
+		return ErrCannotDeleteSelf
+	}
+	reason, err := normalizeAdminReason(reason)
+	if err != nil {
+		return err
+	}
+	if err := u.requireUser(ctx, targetUserID); err != nil {
+		return err
+	}
+	// 操作に失敗しても試みた記録が残るよう、監査ログは操作の前に書く
+	if err := u.record(ctx, adminUserID, admin.ActionForceDeleteUser, targetUserID, reason); err != nil {
+		return err
+	}
+	// 管理者による削除は猶予期間を置かずに完全に削除する
+	return u.withdraw.Purge(ctx, targetUserID)
+}
+
+func (u *AdminUsecase) GrantRole(ctx context.Context, adminUserID, targetUserID string, role user.Role) error {
+	if err := u.requireUser(ctx, targetUserID); err != nil {
+		return err
+	}
+	if err := u.record(ctx, adminUserID, admin.ActionGrantRole, targetUserID, string(role)); err != nil {
+		return err
+	}
+	return u.roleRepository.Grant(ctx, targetUserID, role)
+}
+
+func (u *AdminUsecase) RevokeRole(ctx context.Context, adminUserID, targetUserID string, role user.Role) error {
+	// 管理者が1人もいなくなる事態を避けるため、自分の管理者ロールは他の管理者に取り消してもらう
+	if adminUserID == targetUserID && role == user.RoleAdmin {
+		return ErrCannotRevokeOwnRole
+	}
+	if err := u.requireUser(ctx, targetUserID); err != nil {
+		return err
+	}
+	if err := u.record(ctx, adminUserID, admin.ActionRevokeRole, targetUserID, string(role)); err != nil {
+		return err
+	}
+	return u.roleRepository.Revoke(ctx, targetUserID, role)
+}
+
+func (u *AdminUsecase) BreakGlassDiaries(ctx context.Context, adminUserID, targetUserID, reason string) ([]diary.Diary, error) {
+	reason, err := normalizeAdminReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.requireUser(ctx, targetUserID); err != nil {
+		return nil, err
+	}
+	if err := u.record(ctx, adminUserID, admin.ActionBreakGlassDiaries, targetUserID, reason); err != nil {
+		return nil, err
+	}
+	return u.diaryRepository.FindByUserID(ctx, targetUserID)
+}
+
+func (u *AdminUsecase) RunGlobalAnalysis(ctx context.Context, adminUserID string) (string, error) {
+	if err := u.record(ctx, adminUserID, admin.ActionGlobalAnalysis, "", ""); err != nil {
+		return "", err
+	}
+	return u.analyzer.AnalyzeAllDiaries(ctx)
+}
+
+func (u *AdminUsecase) ListActionLogs(ctx context.Context, targetUserID string, limit, offset int) ([]*admin.ActionLog, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	u.access(ctx, "", "list_action_logs", targetUserID)
+	return u.actionLogs.List(ctx, targetUserID, clampAdminLimit(limit), offset)
+}
+
+func (u *AdminUsecase) HasAnyRole(ctx context.Context, userID string, roles ...string) (bool, error) {
+	granted, err := u.roleRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, g := range granted {
+		for _, r := range roles {
+			if string(g) == r {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (u *AdminUsecase) requireUser(ctx context.Context, userID string) error {
+	target, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// access は管理APIの利用を全ユーザー共通の監査ログに記録する（adminUserIDが空の場合はリクエストの認証情報から補う）
+func (u *AdminUsecase) access(ctx context.Context, adminUserID, operation, targetUserID string) {
+	recordAudit(ctx, u.audits, audit.Event{ActorUserID: adminUserID, Action: audit.ActionAdminAccess, TargetUserID: targetUserID, Detail: operation})
+}
+
+// record は管理者の操作を理由とともに記録する（監査ログにも管理APIの利用として記録する）
+func (u *AdminUsecase) record(ctx context.Context, adminUserID string, action admin.Action, targetUserID, reason string) error {
+	u.access(ctx, adminUserID, string(action), targetUserID)
+	return u.actionLogs.Create(ctx, &admin.ActionLog{
+		AdminUserID:  adminUserID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Reason:       reason,
+	})
+}
+
+func normalizeAdminReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) < minAdminReasonLength {
+		return "", ErrAdminReasonRequired
+	}
+	return reason, nil
+}
+
+func clampAdminLimit(limit int) int {
+	if limit <= 0 {
+		return defaultAdminLimit
+	}
+	if limit > maxAdminListLimit {
+		return maxAdminListLimit
+	}
+	return limit
+}
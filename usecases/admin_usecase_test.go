@@ -0,0 +1,166 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tofunote-backend/domain/admin"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryActionLogRepo struct {
+	logs []*admin.ActionLog
+}
+
+func (m *memoryActionLogRepo) Create(ctx context.Context, log *admin.ActionLog) error {
+	m.logs = append(m.logs, log)
+	return nil
+}
+func (m *memoryActionLogRepo) List(ctx context.Context, targetUserID string, limit, offset int) ([]*admin.ActionLog, error) {
+	return m.logs, nil
+}
+
+type memoryRoleRepo struct {
+	roles map[string][]user.Role
+}
+
+func (m *memoryRoleRepo) ListByUserID(ctx context.Context, userID string) ([]user.Role, error) {
+	return m.roles[userID], nil
+}
+func (m *memoryRoleRepo) Grant(ctx context.Context, userID string, role user.Role) error {
+	m.roles[userID] = append(m.roles[userID], role)
+	return nil
+}
+func (m *memoryRoleRepo) Revoke(ctx context.Context, userID string, role user.Role) error {
+	delete(m.roles, userID)
+	return nil
+}
+func (m *memoryRoleRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	delete(m.roles, userID)
+	return nil
+}
+
+type fakeStatsRepo struct{}
+
+func (fakeStatsRepo) UserStats(ctx context.Context, since time.Time) (*admin.UserStats, error) {
+	return &admin.UserStats{Total: 40, Guests: 10, Members: 30, ByProvider: map[string]int64{"google": 27, "email": 3}}, nil
+}
+func (fakeStatsRepo) DiaryStats(ctx context.Context, since time.Time) (*admin.DiaryStats, error) {
+	return &admin.DiaryStats{Total: 100, MentalScoreUsers: map[int]int64{5: 8, 1: 1}}, nil
+}
+
+type diariesByUserRepo struct {
+	mockDiaryRepo
+	diaries []diary.Diary
+}
+
+func (m *diariesByUserRepo) FindByUserID(ctx context.Context, userID string) ([]diary.Diary, error) {
+	return m.diaries, nil
+}
+
+type fakeGlobalAnalyzer struct{}
+
+func (fakeGlobalAnalyzer) AnalyzeAllDiaries(ctx context.Context) (string, error) {
+	return "全体的に安定しています", nil
+}
+
+func newAdminTestUsecase() (*AdminUsecase, *memoryActionLogRepo, *mockDiaryRepo) {
+	users := &memoryUserRepo{users: map[string]*user.User{
+		"admin":  {ID: "admin", Nickname: "管理者", Roles: []user.Role{user.RoleAdmin}},
+		"target": {ID: "target", Nickname: "対象"},
+	}}
+	logs := &memoryActionLogRepo{}
+	diaries := &diariesByUserRepo{diaries: []diary.Diary{{ID: "d1", UserID: "target", Diary: "本文"}}}
+	withdrawDiaries := &mockDiaryRepo{}
+	sessions, _, _ := newSessionTestUsecase()
+	u := NewAdminUsecase(nil, users, &memoryRoleRepo{roles: map[string][]user.Role{}}, fakeStatsRepo{}, logs, diaries,
+		NewUserWithdrawUsecase(&directUnitOfWork{}, users, nil, sessions, nil, nil, withdrawDiaries), fakeGlobalAnalyzer{}, nil).(*AdminUsecase)
+	return u, logs, withdrawDiaries
+}
+
+func TestAdminUsecase_Stats(t *testing.T) {
+	u, _, _ := newAdminTestUsecase()
+	stats, err := u.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"google": 27}, stats.Users.ByProvider)
+	assert.Equal(t, map[int]int64{5: 8}, stats.Diary.MentalScoreUsers)
+	assert.True(t, stats.Suppressed)
+}
+
+func TestAdminUsecase_BreakGlassDiaries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("異常系: 理由がない場合は本文を返さず記録もしない", func(t *testing.T) {
+		u, logs, _ := newAdminTestUsecase()
+		_, err := u.BreakGlassDiaries(ctx, "admin", "target", "確認")
+		assert.ErrorIs(t, err, ErrAdminReasonRequired)
+		assert.Empty(t, logs.logs)
+	})
+
+	t.Run("異常系: 存在しないユーザー", func(t *testing.T) {
+		u, _, _ := newAdminTestUsecase()
+		_, err := u.BreakGlassDiaries(ctx, "admin", "missing", "通報された投稿内容の確認のため")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("正常系: 理由を記録してから本文を返す", func(t *testing.T) {
+		u, logs, _ := newAdminTestUsecase()
+		diaries, err := u.BreakGlassDiaries(ctx, "admin", "target", " 通報された投稿内容の確認のため ")
+		require.NoError(t, err)
+		assert.Len(t, diaries, 1)
+		require.Len(t, logs.logs, 1)
+		assert.Equal(t, &admin.ActionLog{AdminUserID: "admin", Action: admin.ActionBreakGlassDiaries, TargetUserID: "target", Reason: "通報された投稿内容の確認のため"}, logs.logs[0])
+	})
+}
+
+func TestAdminUsecase_ForceDeleteUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("異常系: 自分自身は削除できない", func(t *testing.T) {
+		u, _, _ := newAdminTestUsecase()
+		assert.ErrorIs(t, u.ForceDeleteUser(ctx, "admin", "admin", "テスト用の理由を入力します"), ErrCannotDeleteSelf)
+	})
+
+	t.Run("削除に失敗しても監査ログは残る", func(t *testing.T) {
+		u, logs, diaries := newAdminTestUsecase()
+		diaries.deleteByUserIDErr = errors.New("diary error")
+		err := u.ForceDeleteUser(ctx, "admin", "target", "スパム投稿を繰り返しているため")
+		assert.EqualError(t, err, "diary error")
+		require.Len(t, logs.logs, 1)
+		assert.Equal(t, admin.ActionForceDeleteUser, logs.logs[0].Action)
+	})
+}
+
+func TestAdminUsecase_Roles(t *testing.T) {
+	ctx := context.Background()
+	u, logs, _ := newAdminTestUsecase()
+
+	require.NoError(t, u.GrantRole(ctx, "admin", "target", user.RoleAdmin))
+	roles, _ := u.roleRepository.ListByUserID(ctx, "target")
+	assert.Equal(t, []user.Role{user.RoleAdmin}, roles)
+	assert.ErrorIs(t, u.RevokeRole(ctx, "admin", "admin", user.RoleAdmin), ErrCannotRevokeOwnRole)
+	require.NoError(t, u.RevokeRole(ctx, "admin", "target", user.RoleAdmin))
+	assert.Len(t, logs.logs, 2)
+
+	_, err := u.RunGlobalAnalysis(ctx, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, admin.ActionGlobalAnalysis, logs.logs[2].Action)
+}
+
+func TestAdminUsecase_HasAnyRole(t *testing.T) {
+	ctx := context.Background()
+	u, _, _ := newAdminTestUsecase()
+	require.NoError(t, u.GrantRole(ctx, "admin", "target", user.RoleAdmin))
+
+	ok, err := u.HasAnyRole(ctx, "target", string(user.RoleAdmin))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = u.HasAnyRole(ctx, "admin", string(user.RoleAdmin))
+	require.NoError(t, err)
+	assert.False(t, ok, "ロールの付与はトークンではなくDBで確認する")
+}
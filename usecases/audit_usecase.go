@@ -0,0 +1,85 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"time"
+	"tofunote-backend/domain/audit"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+type IAuditUsecase interface {
+	// Record は監査ログを記録する（リクエストの情報はコンテキストから補う）。
+	// 記録に失敗しても元の操作は失敗させず、エラーをログに出力する
+	Record(ctx context.Context, event audit.Event)
+	// RecordAuthFailure はAPIの呼び出しで提示されたトークンを受け付けなかったことを記録する
+	RecordAuthFailure(ctx context.Context, reason string)
+	// ListForUser はユーザー自身が操作した、または対象となった監査ログを新しい順に返す
+	ListForUser(ctx context.Context, userID string, limit, offset int) ([]*audit.Event, error)
+	// Search は管理者向けに全ユーザーの監査ログを検索する
+	Search(ctx context.Context, query audit.Query) ([]*audit.Event, error)
+}
+
+type AuditUsecase struct {
+	repository audit.Repository
+	now        func() time.Time
+}
+
+func NewAuditUsecase(repository audit.Repository) IAuditUsecase {
+	return &AuditUsecase{repository: repository, now: time.Now}
+}
+
+func (u *AuditUsecase) Record(ctx context.Context, event audit.Event) {
+	info := audit.RequestInfoFrom(ctx)
+	if event.ActorUserID == "" {
+		event.ActorUserID = info.ActorUserID
+	}
+	event.IPAddress = info.IPAddress
+	event.UserAgent = info.UserAgent
+	event.RequestID = info.RequestID
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = u.now()
+	}
+	if err := u.repository.Append(ctx, &event); err != nil {
+		log.Printf("[ERROR] Audit: 監査ログの記録に失敗しました action=%s actor=%s target=%s: %v", event.Action, event.ActorUserID, event.TargetUserID, err)
+	}
+}
+
+func (u *AuditUsecase) RecordAuthFailure(ctx context.Context, reason string) {
+	u.Record(ctx, audit.Event{Action: audit.ActionAuthFailed, Detail: reason})
+}
+
+func (u *AuditUsecase) ListForUser(ctx context.Context, userID string, limit, offset int) ([]*audit.Event, error) {
+	return u.list(ctx, audit.Query{UserID: userID, Limit: limit, Offset: offset})
+}
+
+// Search は他のユーザーの監査ログの閲覧自体も管理APIの利用として記録する
+func (u *AuditUsecase) Search(ctx context.Context, query audit.Query) ([]*audit.Event, error) {
+	u.Record(ctx, audit.Event{Action: audit.ActionAdminAccess, TargetUserID: query.UserID, Detail: "search_audit_log"})
+	return u.list(ctx, query)
+}
+
+func (u *AuditUsecase) list(ctx context.Context, query audit.Query) ([]*audit.Event, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultAuditLimit
+	}
+	if query.Limit > maxAuditLimit {
+		query.Limit = maxAuditLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+	return u.repository.List(ctx, query)
+}
+
+// recordAudit は監査ログを記録する（監査ログを使わない構成・テストではauditsがnil）
+func recordAudit(ctx context.Context, audits IAuditUsecase, event audit.Event) {
+	if audits == nil {
+		return
+	}
+	audits.Record(ctx, event)
+}
@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/domain/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAuditRepo struct {
+	events  []*audit.Event
+	queries []audit.Query
+	failErr error
+}
+
+func (r *memoryAuditRepo) Append(ctx context.Context, event *audit.Event) error {
+	if r.failErr != nil {
+		return r.failErr
+	}
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memoryAuditRepo) List(ctx context.Context, query audit.Query) ([]*audit.Event, error) {
+	r.queries = append(r.queries, query)
+	return r.events, nil
+}
+
+func (r *memoryAuditRepo) actions() []audit.Action {
+	actions := make([]audit.Action, len(r.events))
+	for i, e := range r.events {
+		actions[i] = e.Action
+	}
+	return actions
+}
+
+func TestAuditUsecase_Record(t *testing.T) {
+	repo := &memoryAuditRepo{}
+	uc := NewAuditUsecase(repo).(*AuditUsecase)
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	ctx := audit.WithRequestInfo(context.Background(), audit.RequestInfo{IPAddress: "203.0.113.1", UserAgent: "test-agent", RequestID: "req-1"})
+	ctx = audit.WithActor(ctx, "u1")
+
+	t.Run("リクエストの情報と操作者をコンテキストから補う", func(t *testing.T) {
+		uc.Record(ctx, audit.Event{Action: audit.ActionDataExport, TargetUserID: "u1"})
+		require.Len(t, repo.events, 1)
+		assert.Equal(t, &audit.Event{
+			ActorUserID:  "u1",
+			Action:       audit.ActionDataExport,
+			TargetUserID: "u1",
+			IPAddress:    "203.0.113.1",
+			UserAgent:    "test-agent",
+			RequestID:    "req-1",
+			CreatedAt:    now,
+		}, repo.events[0])
+	})
+
+	t.Run("操作者を指定した場合はそのまま記録する", func(t *testing.T) {
+		uc.Record(ctx, audit.Event{ActorUserID: "u2", Action: audit.ActionLogin, TargetUserID: "u2"})
+		assert.Equal(t, "u2", repo.events[1].ActorUserID)
+	})
+
+	t.Run("記録に失敗しても呼び出し元には返さない", func(t *testing.T) {
+		failing := NewAuditUsecase(&memoryAuditRepo{failErr: errors.New("db down")})
+		assert.NotPanics(t, func() { failing.RecordAuthFailure(ctx, "invalid_access_token") })
+	})
+}
+
+func TestAuditUsecase_List(t *testing.T) {
+	repo := &memoryAuditRepo{}
+	uc := NewAuditUsecase(repo)
+	ctx := audit.WithActor(context.Background(), "admin1")
+
+	_, err := uc.ListForUser(ctx, "u1", 0, -1)
+	require.NoError(t, err)
+	assert.Equal(t, audit.Query{UserID: "u1", Limit: defaultAuditLimit}, repo.queries[0])
+	assert.Empty(t, repo.events, "自分の監査ログの閲覧は記録しない")
+
+	_, err = uc.Search(ctx, audit.Query{UserID: "u1", Action: audit.ActionLogin, Limit: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, maxAuditLimit, repo.queries[1].Limit)
+	require.Len(t, repo.events, 1, "他のユーザーの監査ログの閲覧は管理APIの利用として記録する")
+	assert.Equal(t, audit.ActionAdminAccess, repo.events[0].Action)
+	assert.Equal(t, "admin1", repo.events[0].ActorUserID)
+	assert.Equal(t, "u1", repo.events[0].TargetUserID)
+}
+
+func TestRefreshTokenUsecase_RecordsAudit(t *testing.T) {
+	repo := newMemoryRefreshTokenRepo()
+	audits := &memoryAuditRepo{}
+	uc := NewRefreshTokenUsecase(repo, NewSessionUsecase(newMemorySessionRepo(), repo, 24*time.Hour, time.Minute), NewAuditUsecase(audits), time.Hour)
+	ctx := context.Background()
+
+	first, _, err := uc.Issue(ctx, "u1", auth.ClientInfo{DeviceLabel: "iPhone"})
+	require.NoError(t, err)
+	_, _, err = uc.Rotate(ctx, first, auth.ClientInfo{})
+	require.NoError(t, err)
+	_, _, err = uc.Rotate(ctx, first, auth.ClientInfo{})
+	require.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+	_, _, err = uc.Rotate(ctx, "unknown", auth.ClientInfo{})
+	require.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+
+	assert.Equal(t, []audit.Action{audit.ActionLogin, audit.ActionTokenRefresh, audit.ActionTokenReuseDetected, audit.ActionAuthFailed}, audits.actions())
+	assert.Equal(t, "iPhone", audits.events[0].Detail)
+	assert.Equal(t, "u1", audits.events[2].TargetUserID)
+}
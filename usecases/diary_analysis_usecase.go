@@ -11,15 +11,33 @@ import (
 	"strings"
 
 	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/thoughtrecord"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/domain/webhook"
+)
+
+var (
+	// ErrAnalysisConsentRequired はAIによる分析に同意していないユーザーの日記を分析しようとした場合のエラー
+	ErrAnalysisConsentRequired = errors.New("日記の分析には設定でAIによる分析への同意が必要です")
+	// ErrNoAnalyzableDiaries はAIによる分析に同意したユーザーの日記がない場合のエラー
+	ErrNoAnalyzableDiaries = errors.New("分析に使える日記がありません")
 )
 
 type DiaryAnalysisUsecase struct {
-	DiaryRepository diary.DiaryRepository
+	DiaryRepository         diary.DiaryRepository
+	ThoughtRecordRepository thoughtrecord.Repository
+	// Preferences はAIによる分析への同意（analysis_opt_in）の確認に使う
+	Preferences user.PreferencesRepository
+	// Webhooks はユーザーの日記の分析の完了を外部連携に通知する（nilの場合は通知しない）
+	Webhooks IWebhookPublisher
 }
 
-func NewDiaryAnalysisUsecase(diaryRepository diary.DiaryRepository) *DiaryAnalysisUsecase {
+func NewDiaryAnalysisUsecase(diaryRepository diary.DiaryRepository, thoughtRecordRepository thoughtrecord.Repository, preferences user.PreferencesRepository, webhooks IWebhookPublisher) *DiaryAnalysisUsecase {
 	return &DiaryAnalysisUsecase{
-		DiaryRepository: diaryRepository,
+		DiaryRepository:         diaryRepository,
+		ThoughtRecordRepository: thoughtRecordRepository,
+		Preferences:             preferences,
+		Webhooks:                webhooks,
 	}
 }
 
@@ -39,27 +57,29 @@ type AnalysisResponse struct {
 	} `json:"choices"`
 }
 
-// AnalyzeUserDiaries は特定のユーザーの日記を分析する
+// AnalyzeUserDiaries は特定のユーザーの日記を分析する（AIによる分析に同意していない場合はErrAnalysisConsentRequired）
 func (u *DiaryAnalysisUsecase) AnalyzeUserDiaries(ctx context.Context, userID string) (string, error) {
+	prefs, err := u.Preferences.FindByUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if prefs == nil || !prefs.AnalysisOptIn {
+		return "", ErrAnalysisConsentRequired
+	}
 	diaries, err := u.DiaryRepository.FindByUserID(ctx, userID)
 	if err != nil {
 		return "", err
 	}
-
-	// 日記の内容を結合
-	var diaryContents []string
-	for _, diary := range diaries {
-		// 各フィールドを結合
-		entry := strings.Join([]string{
-			"ID: " + diary.ID,
-			"UserID: " + diary.UserID,
-			"Date: " + diary.Date,
-			"Mental: " + strconv.Itoa(int(diary.Mental)),
-			"Diary: " + diary.Diary,
-		}, "\n")
-		diaryContents = append(diaryContents, entry)
+	var records []thoughtrecord.ThoughtRecord
+	if u.ThoughtRecordRepository != nil {
+		records, err = u.ThoughtRecordRepository.FindByUserID(ctx, userID)
+		if err != nil {
+			return "", err
+		}
 	}
-	combinedContent := strings.Join(diaryContents, "\n\n")
+
+	// 日記と思考記録の内容を結合
+	combinedContent := buildUserAnalysisContent(diaries, records)
 
 	// APIリクエストデータの作成
 	requestBody := AnalysisRequest{
@@ -68,8 +88,8 @@ func (u *DiaryAnalysisUsecase) AnalyzeUserDiaries(ctx context.Context, userID st
 			Role    string `json:"role"`
 			Content string `json:"content"`
 		}{
-			{Role: "system", Content: "あなたはユーザーの日記とメンタルスコア（1〜10）をもとに、感情の傾向を分析し、やさしく前向きなアドバイスを行うメンタルサポートAIです。\n\nユーザーのメンタルスコアは1〜10の10段階で記録されており、1が最も調子が悪く、10が最も調子が良いことを表します。\n\nスコアと日記の内容を組み合わせて、感情の傾向を読み取り、簡潔に100文字以内で説明してください。"},
-			{Role: "user", Content: "以下はユーザーの日記とメンタルスコアです。\n\n" + combinedContent + "\n\nこの内容を分析して、感情の傾向を読み取り、わかりやすく丁寧に説明してください。"},
+			{Role: "system", Content: "あなたはユーザーの日記とメンタルスコア（1〜10）をもとに、感情の傾向を分析し、やさしく前向きなアドバイスを行うメンタルサポートAIです。\n\nユーザーのメンタルスコアは1〜10の10段階で記録されており、1が最も調子が悪く、10が最も調子が良いことを表します。\n\n認知行動療法の思考記録（状況・自動思考・感情の強さ・根拠・反証・バランス思考）がある場合は、考え方の癖や感情の強さの変化も踏まえてください。\n\nスコアと日記の内容を組み合わせて、感情の傾向を読み取り、簡潔に100文字以内で説明してください。"},
+			{Role: "user", Content: "以下はユーザーの日記とメンタルスコア、思考記録です。\n\n" + combinedContent + "\n\nこの内容を分析して、感情の傾向を読み取り、わかりやすく丁寧に説明してください。"},
 		},
 	}
 
@@ -116,30 +136,23 @@ func (u *DiaryAnalysisUsecase) AnalyzeUserDiaries(ctx context.Context, userID st
 		return "", errors.New("分析結果が空です")
 	}
 
-	return analysisResponse.Choices[0].Message.Content, nil
+	result := analysisResponse.Choices[0].Message.Content
+	publishWebhook(ctx, u.Webhooks, userID, webhook.EventAnalysisCompleted, map[string]string{"result": result})
+	return result, nil
 }
 
-// AnalyzeDiary は日記の内容を分析する
+// AnalyzeAllDiaries は全ユーザーを横断して日記の傾向を分析する（AIによる分析に同意したユーザーの日記のみ使う）。
+// 管理者に返す結果から個人の日記が読めないよう、LLMには本文を渡さず匿名の集計値のみ渡す
 func (u *DiaryAnalysisUsecase) AnalyzeAllDiaries(ctx context.Context) (string, error) {
-	diaries, err := u.DiaryRepository.FindAll(ctx)
+	diaries, err := u.analyzableDiaries(ctx)
 	if err != nil {
 		return "", err
 	}
-
-	// 日記の内容を結合
-	var diaryContents []string
-	for _, diary := range diaries {
-		// 各フィールドを結合
-		entry := strings.Join([]string{
-			"ID: " + diary.ID,
-			"UserID: " + diary.UserID,
-			"Date: " + diary.Date,
-			"Mental: " + strconv.Itoa(int(diary.Mental)),
-			"Diary: " + diary.Diary,
-		}, "\n")
-		diaryContents = append(diaryContents, entry)
+	if len(diaries) == 0 {
+		return "", ErrNoAnalyzableDiaries
 	}
-	combinedContent := strings.Join(diaryContents, "\n\n")
+
+	combinedContent := buildGlobalAnalysisContent(diaries)
 
 	// APIリクエストデータの作成
 	requestBody := AnalysisRequest{
@@ -148,8 +161,8 @@ func (u *DiaryAnalysisUsecase) AnalyzeAllDiaries(ctx context.Context) (string, e
 			Role    string `json:"role"`
 			Content string `json:"content"`
 		}{
-			{Role: "system", Content: "あなたはユーザーの日記とメンタルスコア（1〜10）をもとに、感情の傾向を分析し、やさしく前向きなアドバイスを行うメンタルサポートAIです。\n\nユーザーのメンタルスコアは1〜10の10段階で記録されており、1が最も調子が悪く、10が最も調子が良いことを表します。\n\nスコアと日記の内容を組み合わせて、感情の傾向を読み取り、簡潔に100文字以内で説明してください。"},
-			{Role: "user", Content: "以下はユーザーの日記とメンタルスコアです。\n\n" + combinedContent + "\n\nこの内容を分析して、感情の傾向を読み取り、わかりやすく丁寧に説明してください。"},
+			{Role: "system", Content: "あなたはサービス全体の日記の集計値（件数・メンタルスコアの分布・感情ラベルの出現頻度）をもとに、利用者全体の感情の傾向を分析するメンタルサポートAIです。\n\nメンタルスコアは1〜10の10段階で記録されており、1が最も調子が悪く、10が最も調子が良いことを表します。\n\n集計値から全体の傾向を読み取り、簡潔に100文字以内で説明してください。"},
+			{Role: "user", Content: "以下はサービス全体の日記の集計値です。\n\n" + combinedContent + "\n\nこの内容を分析して、感情の傾向を読み取り、わかりやすく丁寧に説明してください。"},
 		},
 	}
 
@@ -198,3 +211,104 @@ func (u *DiaryAnalysisUsecase) AnalyzeAllDiaries(ctx context.Context) (string, e
 
 	return analysisResponse.Choices[0].Message.Content, nil
 }
+
+// analyzableDiaries はAIによる分析に同意したユーザーの日記を返す
+func (u *DiaryAnalysisUsecase) analyzableDiaries(ctx context.Context) ([]diary.Diary, error) {
+	userIDs, err := u.Preferences.FindAnalysisOptedInUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	optedIn := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		optedIn[id] = true
+	}
+	diaries, err := u.DiaryRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	analyzable := make([]diary.Diary, 0, len(diaries))
+	for _, d := range diaries {
+		if optedIn[d.UserID] {
+			analyzable = append(analyzable, d)
+		}
+	}
+	return analyzable, nil
+}
+
+// buildGlobalAnalysisContent は全ユーザーを横断する分析でLLMに渡す匿名の集計値を整形する。
+// 日記の本文・ユーザーID・日付は含めず、ユーザー定義の感情ラベルは名前から個人を推測できるため「その他」にまとめる
+func buildGlobalAnalysisContent(diaries []diary.Diary) string {
+	users := make(map[string]bool)
+	var mentalSum int
+	var mentalCounts [10]int
+	for _, d := range diaries {
+		users[d.UserID] = true
+		v := d.Mental.Value()
+		mentalSum += v
+		if v >= 1 && v <= 10 {
+			mentalCounts[v-1]++
+		}
+	}
+
+	lines := []string{
+		"日記の件数: " + strconv.Itoa(len(diaries)),
+		"ユーザー数: " + strconv.Itoa(len(users)),
+		"メンタルスコアの平均: " + strconv.FormatFloat(float64(mentalSum)/float64(len(diaries)), 'f', 1, 64),
+		"メンタルスコアの分布:",
+	}
+	for i, count := range mentalCounts {
+		lines = append(lines, "  "+strconv.Itoa(i+1)+": "+strconv.Itoa(count)+"件")
+	}
+
+	taxonomy := diary.NewTaxonomy(nil)
+	customCount := 0
+	var emotionLines []string
+	for _, stat := range diary.EmotionFrequencies(diaries) {
+		label, ok := taxonomy.Lookup(stat.Key)
+		if !ok {
+			customCount += stat.Count
+			continue
+		}
+		emotionLines = append(emotionLines, "  "+label.Name(diary.LocaleJa)+": "+strconv.Itoa(stat.Count)+"件（メンタルスコアの平均 "+strconv.FormatFloat(stat.AverageMental, 'f', 1, 64)+"）")
+	}
+	if customCount > 0 {
+		emotionLines = append(emotionLines, "  その他: "+strconv.Itoa(customCount)+"件")
+	}
+	if len(emotionLines) > 0 {
+		lines = append(lines, "感情ラベルの出現頻度:")
+		lines = append(lines, emotionLines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// buildUserAnalysisContent は日記と思考記録をLLMに渡すテキストに整形する
+func buildUserAnalysisContent(diaries []diary.Diary, records []thoughtrecord.ThoughtRecord) string {
+	var diaryContents []string
+	for _, diary := range diaries {
+		// 各フィールドを結合
+		entry := strings.Join([]string{
+			"ID: " + diary.ID,
+			"UserID: " + diary.UserID,
+			"Date: " + diary.Date.String(),
+			"Mental: " + strconv.Itoa(int(diary.Mental)),
+			"Diary: " + diary.Diary,
+		}, "\n")
+		diaryContents = append(diaryContents, entry)
+	}
+	content := strings.Join(diaryContents, "\n\n")
+	if len(records) == 0 {
+		return content
+	}
+
+	// 思考記録の感情名は組み込みラベルで表示する（ユーザー定義ラベルはキーのまま）
+	taxonomy := diary.NewTaxonomy(nil)
+	recordContents := make([]string, 0, len(records))
+	for _, r := range records {
+		header := "思考記録"
+		if r.Date != "" {
+			header += "（" + r.Date + "）"
+		}
+		recordContents = append(recordContents, header+"\n"+r.Summary(taxonomy))
+	}
+	return content + "\n\n" + strings.Join(recordContents, "\n\n")
+}
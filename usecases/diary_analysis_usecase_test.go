@@ -0,0 +1,70 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allDiariesRepo はFindAllで全ユーザーの日記を返す
+type allDiariesRepo struct {
+	mockDiaryRepo
+	diaries []diary.Diary
+}
+
+func (m *allDiariesRepo) FindAll(ctx context.Context) ([]diary.Diary, error) {
+	return m.diaries, nil
+}
+
+func newAnalysisTestUsecase() *DiaryAnalysisUsecase {
+	optedIn := user.DefaultPreferences()
+	optedIn.AnalysisOptIn = true
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{
+		"opted-in":  optedIn,
+		"opted-out": user.DefaultPreferences(),
+	}}
+	diaries := &allDiariesRepo{diaries: []diary.Diary{
+		{ID: "d1", UserID: "opted-in", Diary: "同意した日記"},
+		{ID: "d2", UserID: "opted-out", Diary: "同意していない日記"},
+		{ID: "d3", UserID: "no-preferences", Diary: "設定を保存していない日記"},
+	}}
+	return NewDiaryAnalysisUsecase(diaries, nil, prefs, nil)
+}
+
+func TestDiaryAnalysisUsecase_AnalyzeUserDiaries_RequiresConsent(t *testing.T) {
+	u := newAnalysisTestUsecase()
+	for _, userID := range []string{"opted-out", "no-preferences"} {
+		_, err := u.AnalyzeUserDiaries(context.Background(), userID)
+		assert.ErrorIs(t, err, ErrAnalysisConsentRequired, userID)
+	}
+}
+
+func TestDiaryAnalysisUsecase_AnalyzableDiaries(t *testing.T) {
+	diaries, err := newAnalysisTestUsecase().analyzableDiaries(context.Background())
+	require.NoError(t, err)
+	require.Len(t, diaries, 1, "全体の分析には同意したユーザーの日記のみ使う")
+	assert.Equal(t, "d1", diaries[0].ID)
+}
+
+func TestBuildGlobalAnalysisContent_OnlyAggregates(t *testing.T) {
+	m3, _ := diary.NewMental(3)
+	m7, _ := diary.NewMental(7)
+	content := buildGlobalAnalysisContent([]diary.Diary{
+		{ID: "d1", UserID: "u1", Mental: m3, Diary: "職場の田中さんと喧嘩した", Emotions: []diary.Emotion{{Key: "anger"}}},
+		{ID: "d2", UserID: "u2", Mental: m7, Diary: "通院の帰りに散歩した", Emotions: []diary.Emotion{{Key: "calm"}, {Key: "my_secret_label"}}},
+	})
+
+	assert.Contains(t, content, "日記の件数: 2")
+	assert.Contains(t, content, "ユーザー数: 2")
+	assert.Contains(t, content, "メンタルスコアの平均: 5.0")
+	assert.Contains(t, content, "怒り: 1件")
+	assert.Contains(t, content, "その他: 1件")
+	// 本文・ID・ユーザー定義ラベルのキーはLLMに渡さない
+	for _, secret := range []string{"田中", "通院", "d1", "u1", "my_secret_label"} {
+		assert.NotContains(t, content, secret)
+	}
+}
@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/reminder"
+	"tofunote-backend/domain/user"
+)
+
+type IDiaryReminderUsecase interface {
+	SendDueReminders(ctx context.Context, from, to time.Time) (int, error)
+}
+
+type DiaryReminderUsecase struct {
+	preferences     user.PreferencesRepository
+	diaryRepository diary.DiaryRepository
+	notifier        notification.Notifier
+}
+
+func NewDiaryReminderUsecase(preferences user.PreferencesRepository, diaryRepository diary.DiaryRepository, notifier notification.Notifier) IDiaryReminderUsecase {
+	return &DiaryReminderUsecase{
+		preferences:     preferences,
+		diaryRepository: diaryRepository,
+		notifier:        notifier,
+	}
+}
+
+// SendDueReminders は (from, to] の間にリマインダーの時刻を迎え、その日（ユーザーのタイムゾーンでの今日）の日記をまだ書いていない
+// ユーザーにリマインダーを送信し、送信件数を返す。一部のユーザーへの送信に失敗しても残りのユーザーへの送信は続ける
+func (u *DiaryReminderUsecase) SendDueReminders(ctx context.Context, from, to time.Time) (int, error) {
+	users, err := u.preferences.FindRemindable(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, r := range reminder.DueReminders(users, from, to) {
+		_, err := u.diaryRepository.FindByUserIDAndDate(ctx, r.UserID, r.Date)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, diary.ErrDiaryNotFound) {
+			log.Printf("[ERROR] DiaryReminder: 日記の取得に失敗しました user_id=%s date=%s: %v", r.UserID, r.Date, err)
+			continue
+		}
+		err = u.notifier.Notify(ctx, r.Message())
+		if errors.Is(err, notification.ErrNoRecipient) {
+			continue
+		}
+		if err != nil {
+			log.Printf("[ERROR] DiaryReminder: リマインダーの送信に失敗しました user_id=%s date=%s time=%s: %v", r.UserID, r.Date, r.Time, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
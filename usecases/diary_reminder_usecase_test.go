@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writtenDiaryRepo は書いた日記（ユーザーID・日付）だけを返す日記リポジトリ
+type writtenDiaryRepo struct {
+	mockDiaryRepo
+	written map[string]string
+	// errs はユーザーごとに日記の取得で返すエラー
+	errs map[string]error
+}
+
+func (m *writtenDiaryRepo) FindByUserIDAndDate(ctx context.Context, userID string, date diary.Date) (*diary.Diary, error) {
+	if err := m.errs[userID]; err != nil {
+		return nil, err
+	}
+	if m.written[userID] == date.String() {
+		return &diary.Diary{UserID: userID, Date: date}, nil
+	}
+	return nil, diary.ErrDiaryNotFound
+}
+
+func remindAt(times ...string) user.Preferences {
+	p := user.DefaultPreferences()
+	p.ReminderTimes = times
+	return p
+}
+
+func TestDiaryReminderUsecase_SendDueReminders(t *testing.T) {
+	newYork := remindAt("21:00")
+	newYork.Timezone = "America/New_York"
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{
+		"u1": remindAt("21:00"),
+		"u2": remindAt("21:00"), // 今日の日記を書いている
+		"u3": remindAt("08:00"),
+		"u4": newYork,
+	}}
+	// 2025-05-01 21:00 JST（ニューヨークでは 08:00）
+	to := time.Date(2025, 5, 1, 12, 5, 0, 0, time.UTC)
+	diaries := &writtenDiaryRepo{written: map[string]string{"u2": "2025-05-01"}}
+	notifier := &mockNotifier{}
+	usecase := NewDiaryReminderUsecase(prefs, diaries, notifier)
+
+	sent, err := usecase.SendDueReminders(context.Background(), to.Add(-15*time.Minute), to)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, notifier.messages, 1)
+	assert.Equal(t, "u1", notifier.messages[0].UserID)
+	assert.Equal(t, notification.KindDiaryReminder, notifier.messages[0].Kind)
+	assert.Equal(t, "2025-05-01", notifier.messages[0].Data["date"])
+}
+
+func TestDiaryReminderUsecase_SkipsUserWithoutRecipient(t *testing.T) {
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{"u1": remindAt("21:00")}}
+	to := time.Date(2025, 5, 1, 12, 5, 0, 0, time.UTC)
+	usecase := NewDiaryReminderUsecase(prefs, &writtenDiaryRepo{}, &mockNotifier{err: notification.ErrNoRecipient})
+
+	sent, err := usecase.SendDueReminders(context.Background(), to.Add(-15*time.Minute), to)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestDiaryReminderUsecase_DiaryLookupError(t *testing.T) {
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{
+		"u1": remindAt("21:00"),
+		"u2": remindAt("21:00"),
+	}}
+	to := time.Date(2025, 5, 1, 12, 5, 0, 0, time.UTC)
+	notifier := &mockNotifier{}
+	diaries := &writtenDiaryRepo{errs: map[string]error{"u1": errors.New("db error")}}
+	usecase := NewDiaryReminderUsecase(prefs, diaries, notifier)
+
+	// 日記を取得できなかったユーザーには送らず、残りのユーザーへの送信は続ける
+	sent, err := usecase.SendDueReminders(context.Background(), to.Add(-15*time.Minute), to)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, notifier.messages, 1)
+	assert.Equal(t, "u2", notifier.messages[0].UserID)
+}
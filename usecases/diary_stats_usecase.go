@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"context"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/factor"
+)
+
+type IDiaryStatsUsecase interface {
+	EmotionFrequencies(ctx context.Context, userID string, startDate, endDate string) ([]diary.EmotionStat, error)
+	FactorCorrelations(ctx context.Context, userID string, startDate, endDate string) ([]factor.Insight, error)
+}
+
+type DiaryStatsUsecase struct {
+	diaryRepository            diary.DiaryRepository
+	factorRepository           factor.Repository
+	factorDefinitionRepository factor.DefinitionRepository
+}
+
+func NewDiaryStatsUsecase(diaryRepository diary.DiaryRepository, factorRepository factor.Repository, factorDefinitionRepository factor.DefinitionRepository) IDiaryStatsUsecase {
+	return &DiaryStatsUsecase{
+		diaryRepository:            diaryRepository,
+		factorRepository:           factorRepository,
+		factorDefinitionRepository: factorDefinitionRepository,
+	}
+}
+
+// EmotionFrequencies は期間内（未指定の場合は全期間）の感情ラベルの出現頻度を集計する
+func (u *DiaryStatsUsecase) EmotionFrequencies(ctx context.Context, userID string, startDate, endDate string) ([]diary.EmotionStat, error) {
+	diaries, err := u.findDiaries(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return diary.EmotionFrequencies(diaries), nil
+}
+
+// FactorCorrelations は期間内（未指定の場合は全期間）の生活因子とメンタルスコアの相関を分析する
+func (u *DiaryStatsUsecase) FactorCorrelations(ctx context.Context, userID string, startDate, endDate string) ([]factor.Insight, error) {
+	diaries, err := u.findDiaries(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	var records []factor.DailyFactors
+	if startDate != "" && endDate != "" {
+		records, err = u.factorRepository.FindByUserIDAndDateRange(ctx, userID, startDate, endDate)
+	} else {
+		records, err = u.factorRepository.FindByUserID(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	custom, err := u.factorDefinitionRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	moods := make(map[string]float64, len(diaries))
+	for _, d := range diaries {
+		moods[d.Date.String()] = float64(d.Mental.Value())
+	}
+	return factor.Correlate(moods, records, factor.AllDefinitions(custom)), nil
+}
+
+func (u *DiaryStatsUsecase) findDiaries(ctx context.Context, userID string, startDate, endDate string) ([]diary.Diary, error) {
+	if startDate != "" && endDate != "" {
+		return u.diaryRepository.FindByUserIDAndDateRange(ctx, userID, diary.Date(startDate), diary.Date(endDate))
+	}
+	return u.diaryRepository.FindByUserID(ctx, userID)
+}
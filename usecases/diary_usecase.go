@@ -2,26 +2,50 @@ package usecases
 
 import (
 	"context"
+	"time"
 	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/domain/webhook"
 )
 
 type IDiaryUsecase interface {
 	FindAll(ctx context.Context) ([]diary.Diary, error)
 	FindByUserID(ctx context.Context, userID string) ([]diary.Diary, error)
-	FindByUserIDAndDate(ctx context.Context, userID string, date string) (*diary.Diary, error)
-	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]diary.Diary, error)
+	FindByUserIDAndDate(ctx context.Context, userID string, date diary.Date) (*diary.Diary, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate diary.Date) ([]diary.Diary, error)
+	// Create は日記を作成する（日付を指定しない場合はユーザーのタイムゾーンでの今日の日記にする）
 	Create(ctx context.Context, diary *diary.Diary) error
-	Update(ctx context.Context, userID string, date string, diary *diary.Diary) error
-	Delete(ctx context.Context, userID string, date string) error
+	Update(ctx context.Context, userID string, date diary.Date, diary *diary.Diary) error
+	Delete(ctx context.Context, userID string, date diary.Date) error
 	DeleteByUserID(ctx context.Context, userID string) error
+	// Today はユーザーが設定したタイムゾーンでの今日の日付を返す
+	Today(ctx context.Context, userID string) (diary.Date, error)
 }
 
 type DiaryUsecase struct {
-	repository diary.DiaryRepository
+	repository      diary.DiaryRepository
+	labelRepository diary.EmotionLabelRepository
+	// preferences は「今日」を決めるタイムゾーンの取得に使う（nilの場合は既定のタイムゾーン）
+	preferences user.PreferencesRepository
+	// webhooks は日記の作成・更新・削除を外部連携に通知する（nilの場合は通知しない）
+	webhooks IWebhookPublisher
+	now      func() time.Time
 }
 
-func NewDiaryUsecase(repository diary.DiaryRepository) IDiaryUsecase {
-	return &DiaryUsecase{repository: repository}
+func NewDiaryUsecase(repository diary.DiaryRepository, labelRepository diary.EmotionLabelRepository, preferences user.PreferencesRepository, webhooks IWebhookPublisher) IDiaryUsecase {
+	return &DiaryUsecase{repository: repository, labelRepository: labelRepository, preferences: preferences, webhooks: webhooks, now: time.Now}
+}
+
+// diaryWebhookData はWebhookで送る日記（diary.deletedの場合は日付のみ）
+type diaryWebhookData struct {
+	Date     diary.Date      `json:"date"`
+	Mental   int             `json:"mental,omitempty"`
+	Diary    string          `json:"diary,omitempty"`
+	Emotions []diary.Emotion `json:"emotions,omitempty"`
+}
+
+func toDiaryWebhookData(d *diary.Diary) diaryWebhookData {
+	return diaryWebhookData{Date: d.Date, Mental: int(d.Mental), Diary: d.Diary, Emotions: d.Emotions}
 }
 
 func (s *DiaryUsecase) FindAll(ctx context.Context) ([]diary.Diary, error) {
@@ -32,26 +56,89 @@ func (s *DiaryUsecase) FindByUserID(ctx context.Context, userID string) ([]diary
 	return s.repository.FindByUserID(ctx, userID)
 }
 
-func (s *DiaryUsecase) FindByUserIDAndDate(ctx context.Context, userID string, date string) (*diary.Diary, error) {
+func (s *DiaryUsecase) FindByUserIDAndDate(ctx context.Context, userID string, date diary.Date) (*diary.Diary, error) {
 	return s.repository.FindByUserIDAndDate(ctx, userID, date)
 }
 
-func (s *DiaryUsecase) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]diary.Diary, error) {
+func (s *DiaryUsecase) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate diary.Date) ([]diary.Diary, error) {
+	if endDate.Before(startDate) {
+		return nil, diary.ErrInvalidDateRange
+	}
 	return s.repository.FindByUserIDAndDateRange(ctx, userID, startDate, endDate)
 }
 
-func (s *DiaryUsecase) Create(ctx context.Context, diary *diary.Diary) error {
-	return s.repository.Create(ctx, diary)
+func (s *DiaryUsecase) Create(ctx context.Context, d *diary.Diary) error {
+	if d.Date == "" {
+		today, err := s.Today(ctx, d.UserID)
+		if err != nil {
+			return err
+		}
+		d.Date = today
+	} else if _, err := diary.NewDate(d.Date.String()); err != nil {
+		return err
+	}
+	if err := s.validateEmotions(ctx, d.UserID, d.Emotions); err != nil {
+		return err
+	}
+	if err := s.repository.Create(ctx, d); err != nil {
+		return err
+	}
+	publishWebhook(ctx, s.webhooks, d.UserID, webhook.EventDiaryCreated, toDiaryWebhookData(d))
+	return nil
 }
 
-func (s *DiaryUsecase) Update(ctx context.Context, userID string, date string, diary *diary.Diary) error {
-	return s.repository.Update(ctx, userID, date, diary)
+func (s *DiaryUsecase) Update(ctx context.Context, userID string, date diary.Date, diary *diary.Diary) error {
+	if err := s.validateEmotions(ctx, userID, diary.Emotions); err != nil {
+		return err
+	}
+	if err := s.repository.Update(ctx, userID, date, diary); err != nil {
+		return err
+	}
+	data := toDiaryWebhookData(diary)
+	data.Date = date
+	publishWebhook(ctx, s.webhooks, userID, webhook.EventDiaryUpdated, data)
+	return nil
 }
 
-func (s *DiaryUsecase) Delete(ctx context.Context, userID string, date string) error {
-	return s.repository.Delete(ctx, userID, date)
+func (s *DiaryUsecase) Delete(ctx context.Context, userID string, date diary.Date) error {
+	if err := s.repository.Delete(ctx, userID, date); err != nil {
+		return err
+	}
+	publishWebhook(ctx, s.webhooks, userID, webhook.EventDiaryDeleted, diaryWebhookData{Date: date})
+	return nil
 }
 
 func (s *DiaryUsecase) DeleteByUserID(ctx context.Context, userID string) error {
 	return s.repository.DeleteByUserID(ctx, userID)
 }
+
+func (s *DiaryUsecase) Today(ctx context.Context, userID string) (diary.Date, error) {
+	return userToday(ctx, s.preferences, userID, s.now())
+}
+
+// userToday はユーザーの設定のタイムゾーンでの今日の日付を返す（設定を保存していない場合は既定のタイムゾーン）
+func userToday(ctx context.Context, preferences user.PreferencesRepository, userID string, now time.Time) (diary.Date, error) {
+	prefs := user.DefaultPreferences()
+	if preferences != nil {
+		saved, err := preferences.FindByUserID(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		if saved != nil {
+			prefs = *saved
+		}
+	}
+	return diary.Today(now, prefs.Location()), nil
+}
+
+// validateEmotions はユーザー定義ラベルを含む感情ラベル体系に沿っているかを検証する
+func (s *DiaryUsecase) validateEmotions(ctx context.Context, userID string, emotions []diary.Emotion) error {
+	if len(emotions) == 0 {
+		return nil
+	}
+	taxonomy, err := loadTaxonomy(ctx, s.labelRepository, userID)
+	if err != nil {
+		return err
+	}
+	return taxonomy.Validate(emotions)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/user"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
@@ -38,7 +40,7 @@ func (m *mockDiaryRepository) FindByUserID(ctx context.Context, userID string) (
 	return userDiaries, nil
 }
 
-func (m *mockDiaryRepository) FindByUserIDAndDate(ctx context.Context, userID string, date string) (*diary.Diary, error) {
+func (m *mockDiaryRepository) FindByUserIDAndDate(ctx context.Context, userID string, date diary.Date) (*diary.Diary, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -50,7 +52,7 @@ func (m *mockDiaryRepository) FindByUserIDAndDate(ctx context.Context, userID st
 	return nil, errors.New("指定された日付の日記が見つかりません")
 }
 
-func (m *mockDiaryRepository) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]diary.Diary, error) {
+func (m *mockDiaryRepository) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate diary.Date) ([]diary.Diary, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -72,11 +74,11 @@ func (m *mockDiaryRepository) Create(ctx context.Context, diary *diary.Diary) er
 	return m.err
 }
 
-func (m *mockDiaryRepository) Update(ctx context.Context, userID string, date string, diary *diary.Diary) error {
+func (m *mockDiaryRepository) Update(ctx context.Context, userID string, date diary.Date, diary *diary.Diary) error {
 	return m.err
 }
 
-func (m *mockDiaryRepository) Delete(ctx context.Context, userID string, date string) error {
+func (m *mockDiaryRepository) Delete(ctx context.Context, userID string, date diary.Date) error {
 	return m.err
 }
 
@@ -131,7 +133,7 @@ func TestDiaryUsecase_FindAll(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := tt.setupMock()
-			usecase := NewDiaryUsecase(mock)
+			usecase := NewDiaryUsecase(mock, nil, nil, nil)
 
 			result, err := usecase.FindAll(context.Background())
 
@@ -205,7 +207,7 @@ func TestDiaryUsecase_FindByUserID(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := tt.setupMock()
-			usecase := NewDiaryUsecase(mock)
+			usecase := NewDiaryUsecase(mock, nil, nil, nil)
 
 			result, err := usecase.FindByUserID(context.Background(), tt.userID)
 
@@ -227,7 +229,7 @@ func TestDiaryUsecase_FindByUserIDAndDate(t *testing.T) {
 	tests := []struct {
 		name      string
 		userID    string
-		date      string
+		date      diary.Date
 		setupMock func() *mockDiaryRepository
 		expected  *diary.Diary
 		hasError  bool
@@ -280,7 +282,7 @@ func TestDiaryUsecase_FindByUserIDAndDate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := tt.setupMock()
-			usecase := NewDiaryUsecase(mock)
+			usecase := NewDiaryUsecase(mock, nil, nil, nil)
 
 			result, err := usecase.FindByUserIDAndDate(context.Background(), tt.userID, tt.date)
 
@@ -340,7 +342,7 @@ func TestDiaryUsecase_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := tt.setupMock()
-			usecase := NewDiaryUsecase(mock)
+			usecase := NewDiaryUsecase(mock, nil, nil, nil)
 
 			err := usecase.Create(context.Background(), tt.diary)
 
@@ -353,6 +355,119 @@ func TestDiaryUsecase_Create(t *testing.T) {
 	}
 }
 
+func TestDiaryUsecase_CreateDefaultsToToday(t *testing.T) {
+	// UTCでは5月3日だが、日本時間では5月4日になる時刻
+	now := time.Date(2025, 5, 3, 16, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		timezone string
+		want     diary.Date
+	}{
+		{name: "設定していない場合は既定のタイムゾーン（Asia/Tokyo）", want: "2025-05-04"},
+		{name: "設定したタイムゾーンでの今日", timezone: "America/Los_Angeles", want: "2025-05-03"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{}}
+			if tt.timezone != "" {
+				p := user.DefaultPreferences()
+				p.Timezone = tt.timezone
+				prefs.prefs["1"] = p
+			}
+			uc := NewDiaryUsecase(&mockDiaryRepository{}, nil, prefs, nil).(*DiaryUsecase)
+			uc.now = func() time.Time { return now }
+
+			m5, _ := diary.NewMental(5)
+			d := &diary.Diary{UserID: "1", Mental: m5, Diary: "日付を省略した日記"}
+			assert.NoError(t, uc.Create(context.Background(), d))
+			assert.Equal(t, tt.want, d.Date)
+		})
+	}
+}
+
+func TestDiaryUsecase_CreateRejectsInvalidDate(t *testing.T) {
+	uc := NewDiaryUsecase(&mockDiaryRepository{}, nil, nil, nil)
+	m5, _ := diary.NewMental(5)
+	err := uc.Create(context.Background(), &diary.Diary{UserID: "1", Date: "2025-02-29", Mental: m5})
+	assert.ErrorIs(t, err, diary.ErrInvalidDate)
+}
+
+// モック感情ラベルリポジトリ（domain/diary.EmotionLabelRepository の簡易実装）
+type mockEmotionLabelRepository struct {
+	labels []diary.EmotionLabel
+	err    error
+}
+
+func (m *mockEmotionLabelRepository) FindByUserID(ctx context.Context, userID string) ([]diary.EmotionLabel, error) {
+	return m.labels, m.err
+}
+
+func (m *mockEmotionLabelRepository) Create(ctx context.Context, label *diary.EmotionLabel) error {
+	return m.err
+}
+
+func (m *mockEmotionLabelRepository) Delete(ctx context.Context, userID string, key string) error {
+	return m.err
+}
+
+func (m *mockEmotionLabelRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return m.err
+}
+
+func TestDiaryUsecase_CreateWithEmotions(t *testing.T) {
+	m5, _ := diary.NewMental(5)
+
+	tests := []struct {
+		name      string
+		emotions  []diary.Emotion
+		labelRepo *mockEmotionLabelRepository
+		wantErr   string
+	}{
+		{
+			name:      "正常系：組み込みラベル",
+			emotions:  []diary.Emotion{{Key: "anxiety", Intensity: 3}, {Key: "fatigue"}},
+			labelRepo: &mockEmotionLabelRepository{},
+		},
+		{
+			name:     "正常系：ユーザー定義ラベル",
+			emotions: []diary.Emotion{{Key: "homesick"}},
+			labelRepo: &mockEmotionLabelRepository{labels: []diary.EmotionLabel{
+				{Key: "homesick", UserID: "1", Category: "sadness", NameJa: "ホームシック"},
+			}},
+		},
+		{
+			name:      "異常系：未登録のラベル",
+			emotions:  []diary.Emotion{{Key: "homesick"}},
+			labelRepo: &mockEmotionLabelRepository{},
+			wantErr:   "未登録の感情ラベルです: homesick",
+		},
+		{
+			name:      "異常系：ラベル取得に失敗",
+			emotions:  []diary.Emotion{{Key: "joy"}},
+			labelRepo: &mockEmotionLabelRepository{err: errors.New("DBエラー")},
+			wantErr:   "DBエラー",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := NewDiaryUsecase(&mockDiaryRepository{}, tt.labelRepo, nil, nil)
+			err := usecase.Create(context.Background(), &diary.Diary{
+				UserID:   "1",
+				Date:     "2025-05-03",
+				Mental:   m5,
+				Diary:    "新しい日記",
+				Emotions: tt.emotions,
+			})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestDiaryUsecase_Update(t *testing.T) {
 	m4, _ := diary.NewMental(4)
 	updateDiary := &diary.Diary{
@@ -365,7 +480,7 @@ func TestDiaryUsecase_Update(t *testing.T) {
 	tests := []struct {
 		name      string
 		userID    string
-		date      string
+		date      diary.Date
 		diary     *diary.Diary
 		setupMock func() *mockDiaryRepository
 		hasError  bool
@@ -401,7 +516,7 @@ func TestDiaryUsecase_Update(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := tt.setupMock()
-			usecase := NewDiaryUsecase(mock)
+			usecase := NewDiaryUsecase(mock, nil, nil, nil)
 
 			err := usecase.Update(context.Background(), tt.userID, tt.date, tt.diary)
 
@@ -418,7 +533,7 @@ func TestDiaryUsecase_Delete(t *testing.T) {
 	tests := []struct {
 		name      string
 		userID    string
-		date      string
+		date      diary.Date
 		setupMock func() *mockDiaryRepository
 		hasError  bool
 	}{
@@ -451,7 +566,7 @@ func TestDiaryUsecase_Delete(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := tt.setupMock()
-			usecase := NewDiaryUsecase(mock)
+			usecase := NewDiaryUsecase(mock, nil, nil, nil)
 
 			err := usecase.Delete(context.Background(), tt.userID, tt.date)
 
@@ -468,8 +583,8 @@ func TestDiaryUsecase_FindByUserIDAndDateRange(t *testing.T) {
 	tests := []struct {
 		name      string
 		userID    string
-		startDate string
-		endDate   string
+		startDate diary.Date
+		endDate   diary.Date
 		setupMock func() *mockDiaryRepository
 		expected  []diary.Diary
 		hasError  bool
@@ -528,7 +643,7 @@ func TestDiaryUsecase_FindByUserIDAndDateRange(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mock := tt.setupMock()
-			usecase := NewDiaryUsecase(mock)
+			usecase := NewDiaryUsecase(mock, nil, nil, nil)
 
 			result, err := usecase.FindByUserIDAndDateRange(context.Background(), tt.userID, tt.startDate, tt.endDate)
 
@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+	"tofunote-backend/domain/diary"
+)
+
+type IEmotionUsecase interface {
+	GetTaxonomy(ctx context.Context, userID string) (*diary.Taxonomy, error)
+	CreateLabel(ctx context.Context, label *diary.EmotionLabel) error
+	DeleteLabel(ctx context.Context, userID string, key string) error
+}
+
+type EmotionUsecase struct {
+	labelRepository diary.EmotionLabelRepository
+}
+
+func NewEmotionUsecase(labelRepository diary.EmotionLabelRepository) IEmotionUsecase {
+	return &EmotionUsecase{labelRepository: labelRepository}
+}
+
+// GetTaxonomy は組み込みラベルとユーザー定義ラベルを合わせた感情ラベル体系を返す
+func (u *EmotionUsecase) GetTaxonomy(ctx context.Context, userID string) (*diary.Taxonomy, error) {
+	custom, err := u.labelRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return diary.NewTaxonomy(custom), nil
+}
+
+func (u *EmotionUsecase) CreateLabel(ctx context.Context, label *diary.EmotionLabel) error {
+	return u.labelRepository.Create(ctx, label)
+}
+
+func (u *EmotionUsecase) DeleteLabel(ctx context.Context, userID string, key string) error {
+	return u.labelRepository.Delete(ctx, userID, key)
+}
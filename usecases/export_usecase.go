@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"context"
+	"time"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/factor"
+	"tofunote-backend/domain/gratitude"
+	"tofunote-backend/domain/medication"
+	"tofunote-backend/domain/thoughtrecord"
+)
+
+// UserExport はユーザーが記録した全データ
+type UserExport struct {
+	ExportedAt     time.Time
+	Diaries        []diary.Diary
+	EmotionLabels  []diary.EmotionLabel
+	Factors        []factor.DailyFactors
+	Medications    []medication.Medication
+	DoseLogs       []medication.DoseLog
+	ThoughtRecords []thoughtrecord.ThoughtRecord
+	Highlights     []gratitude.DailyHighlights
+}
+
+type IExportUsecase interface {
+	Export(ctx context.Context, userID string) (*UserExport, error)
+}
+
+type ExportUsecase struct {
+	diaryRepository         diary.DiaryRepository
+	emotionLabelRepository  diary.EmotionLabelRepository
+	factorRepository        factor.Repository
+	medicationRepository    medication.Repository
+	doseLogRepository       medication.DoseLogRepository
+	thoughtRecordRepository thoughtrecord.Repository
+	gratitudeRepository     gratitude.Repository
+	audits                  IAuditUsecase
+}
+
+func NewExportUsecase(
+	diaryRepository diary.DiaryRepository,
+	emotionLabelRepository diary.EmotionLabelRepository,
+	factorRepository factor.Repository,
+	medicationRepository medication.Repository,
+	doseLogRepository medication.DoseLogRepository,
+	thoughtRecordRepository thoughtrecord.Repository,
+	gratitudeRepository gratitude.Repository,
+	audits IAuditUsecase,
+) IExportUsecase {
+	return &ExportUsecase{
+		diaryRepository:         diaryRepository,
+		emotionLabelRepository:  emotionLabelRepository,
+		factorRepository:        factorRepository,
+		medicationRepository:    medicationRepository,
+		doseLogRepository:       doseLogRepository,
+		thoughtRecordRepository: thoughtRecordRepository,
+		gratitudeRepository:     gratitudeRepository,
+		audits:                  audits,
+	}
+}
+
+// Export は指定ユーザーの日記・思考記録などの全データを集める
+func (u *ExportUsecase) Export(ctx context.Context, userID string) (*UserExport, error) {
+	export := &UserExport{ExportedAt: time.Now()}
+	var err error
+	if export.Diaries, err = u.diaryRepository.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.EmotionLabels, err = u.emotionLabelRepository.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Factors, err = u.factorRepository.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Medications, err = u.medicationRepository.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.DoseLogs, err = u.doseLogRepository.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.ThoughtRecords, err = u.thoughtRecordRepository.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Highlights, err = u.gratitudeRepository.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, u.audits, audit.Event{ActorUserID: userID, Action: audit.ActionDataExport, TargetUserID: userID})
+	return export, nil
+}
@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"tofunote-backend/domain/factor"
+)
+
+type IFactorUsecase interface {
+	FindByUserIDAndDate(ctx context.Context, userID string, date string) (*factor.DailyFactors, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]factor.DailyFactors, error)
+	Save(ctx context.Context, factors *factor.DailyFactors) error
+	Delete(ctx context.Context, userID string, date string) error
+	ListDefinitions(ctx context.Context, userID string) ([]factor.Definition, error)
+	CreateDefinition(ctx context.Context, def *factor.Definition) error
+	DeleteDefinition(ctx context.Context, userID string, key string) error
+}
+
+type FactorUsecase struct {
+	repository           factor.Repository
+	definitionRepository factor.DefinitionRepository
+}
+
+func NewFactorUsecase(repository factor.Repository, definitionRepository factor.DefinitionRepository) IFactorUsecase {
+	return &FactorUsecase{repository: repository, definitionRepository: definitionRepository}
+}
+
+func (u *FactorUsecase) FindByUserIDAndDate(ctx context.Context, userID string, date string) (*factor.DailyFactors, error) {
+	return u.repository.FindByUserIDAndDate(ctx, userID, date)
+}
+
+func (u *FactorUsecase) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]factor.DailyFactors, error) {
+	return u.repository.FindByUserIDAndDateRange(ctx, userID, startDate, endDate)
+}
+
+// Save はユーザー定義因子を含めて検証した上で、指定日付の因子記録を作成または上書きする
+func (u *FactorUsecase) Save(ctx context.Context, factors *factor.DailyFactors) error {
+	custom, err := u.definitionRepository.FindByUserID(ctx, factors.UserID)
+	if err != nil {
+		return err
+	}
+	if err := factors.Validate(custom); err != nil {
+		return err
+	}
+	return u.repository.Upsert(ctx, factors)
+}
+
+func (u *FactorUsecase) Delete(ctx context.Context, userID string, date string) error {
+	return u.repository.Delete(ctx, userID, date)
+}
+
+// ListDefinitions は組み込み因子とユーザー定義因子の一覧を返す
+func (u *FactorUsecase) ListDefinitions(ctx context.Context, userID string) ([]factor.Definition, error) {
+	custom, err := u.definitionRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return factor.AllDefinitions(custom), nil
+}
+
+func (u *FactorUsecase) CreateDefinition(ctx context.Context, def *factor.Definition) error {
+	custom, err := u.definitionRepository.FindByUserID(ctx, def.UserID)
+	if err != nil {
+		return err
+	}
+	if len(custom) >= factor.MaxCustomDefinitions {
+		return fmt.Errorf("因子は%d個まで登録できます", factor.MaxCustomDefinitions)
+	}
+	return u.definitionRepository.Create(ctx, def)
+}
+
+func (u *FactorUsecase) DeleteDefinition(ctx context.Context, userID string, key string) error {
+	return u.definitionRepository.Delete(ctx, userID, key)
+}
@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"tofunote-backend/domain/factor"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockFactorRepository struct {
+	records  []factor.DailyFactors
+	upserted *factor.DailyFactors
+	err      error
+}
+
+func (m *mockFactorRepository) FindByUserID(ctx context.Context, userID string) ([]factor.DailyFactors, error) {
+	return m.records, m.err
+}
+func (m *mockFactorRepository) FindByUserIDAndDate(ctx context.Context, userID, date string) (*factor.DailyFactors, error) {
+	return nil, m.err
+}
+func (m *mockFactorRepository) FindByUserIDAndDateRange(ctx context.Context, userID, startDate, endDate string) ([]factor.DailyFactors, error) {
+	return m.records, m.err
+}
+func (m *mockFactorRepository) Upsert(ctx context.Context, f *factor.DailyFactors) error {
+	m.upserted = f
+	return m.err
+}
+func (m *mockFactorRepository) Delete(ctx context.Context, userID, date string) error { return m.err }
+func (m *mockFactorRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return m.err
+}
+
+type mockFactorDefinitionRepository struct {
+	defs []factor.Definition
+	err  error
+}
+
+func (m *mockFactorDefinitionRepository) FindByUserID(ctx context.Context, userID string) ([]factor.Definition, error) {
+	return m.defs, m.err
+}
+func (m *mockFactorDefinitionRepository) Create(ctx context.Context, def *factor.Definition) error {
+	return m.err
+}
+func (m *mockFactorDefinitionRepository) Delete(ctx context.Context, userID, key string) error {
+	return m.err
+}
+func (m *mockFactorDefinitionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return m.err
+}
+
+func TestFactorUsecase_Save(t *testing.T) {
+	sleep, tooMuchSleep, cups := 7.0, 30.0, 2.0
+
+	tests := []struct {
+		name       string
+		factors    *factor.DailyFactors
+		defRepo    *mockFactorDefinitionRepository
+		wantErr    string
+		wantUpsert bool
+	}{
+		{
+			name:       "正常系：組み込み因子を保存",
+			factors:    &factor.DailyFactors{UserID: "1", Date: "2025-05-01", SleepHours: &sleep},
+			defRepo:    &mockFactorDefinitionRepository{},
+			wantUpsert: true,
+		},
+		{
+			name:    "正常系：ユーザー定義因子を保存",
+			factors: &factor.DailyFactors{UserID: "1", Date: "2025-05-01", Custom: []factor.Value{{Key: "caffeine_cups", Number: &cups}}},
+			defRepo: &mockFactorDefinitionRepository{defs: []factor.Definition{
+				{Key: "caffeine_cups", UserID: "1", Name: "カフェイン", Type: factor.TypeNumeric, Min: 0, Max: 10},
+			}},
+			wantUpsert: true,
+		},
+		{
+			name:    "異常系：範囲外の値は保存しない",
+			factors: &factor.DailyFactors{UserID: "1", Date: "2025-05-01", SleepHours: &tooMuchSleep},
+			defRepo: &mockFactorDefinitionRepository{},
+			wantErr: "睡眠時間は0〜24の範囲で指定してください",
+		},
+		{
+			name:    "異常系：定義の取得に失敗",
+			factors: &factor.DailyFactors{UserID: "1", Date: "2025-05-01", SleepHours: &sleep},
+			defRepo: &mockFactorDefinitionRepository{err: errors.New("DBエラー")},
+			wantErr: "DBエラー",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockFactorRepository{}
+			usecase := NewFactorUsecase(repo, tt.defRepo)
+			err := usecase.Save(context.Background(), tt.factors)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantUpsert, repo.upserted != nil)
+		})
+	}
+}
+
+func TestFactorUsecase_CreateDefinition_Limit(t *testing.T) {
+	defs := make([]factor.Definition, factor.MaxCustomDefinitions)
+	for i := range defs {
+		defs[i] = factor.Definition{Key: fmt.Sprintf("f%d", i), UserID: "1", Type: factor.TypeBoolean}
+	}
+	usecase := NewFactorUsecase(&mockFactorRepository{}, &mockFactorDefinitionRepository{defs: defs})
+
+	def, _ := factor.NewCustomDefinition("1", "meditation", "瞑想", factor.TypeBoolean, "", 0, 0)
+	err := usecase.CreateDefinition(context.Background(), &def)
+	assert.EqualError(t, err, "因子は20個まで登録できます")
+}
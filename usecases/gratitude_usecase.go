@@ -0,0 +1,106 @@
+package usecases
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/gratitude"
+	"tofunote-backend/domain/user"
+)
+
+// ResurfacedGratitude は調子が悪い日に振り返る過去の良かったこと
+type ResurfacedGratitude struct {
+	// Triggered は指定日のメンタルスコアが低く、振り返りの対象になったかどうか
+	Triggered bool
+	// Mental は指定日の日記のメンタルスコア（日記がない場合はnil）
+	Mental *int
+	// Memory は選ばれた過去の良かったこと（対象外・記録がない場合はnil）
+	Memory *gratitude.Memory
+}
+
+type IGratitudeUsecase interface {
+	FindByUserIDAndDate(ctx context.Context, userID string, date string) (*gratitude.DailyHighlights, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]gratitude.DailyHighlights, error)
+	Save(ctx context.Context, highlights *gratitude.DailyHighlights) error
+	Delete(ctx context.Context, userID string, date string) error
+	// RandomPastGratitude はdateが空の場合、ユーザーの設定のタイムゾーンでの今日を対象にする
+	RandomPastGratitude(ctx context.Context, userID string, date string) (*ResurfacedGratitude, error)
+}
+
+type GratitudeUsecase struct {
+	repository      gratitude.Repository
+	diaryRepository diary.DiaryRepository
+	preferences     user.PreferencesRepository
+	randIntn        func(n int) int
+	now             func() time.Time
+}
+
+func NewGratitudeUsecase(repository gratitude.Repository, diaryRepository diary.DiaryRepository, preferences user.PreferencesRepository) IGratitudeUsecase {
+	return &GratitudeUsecase{
+		repository:      repository,
+		diaryRepository: diaryRepository,
+		preferences:     preferences,
+		randIntn:        rand.Intn,
+		now:             time.Now,
+	}
+}
+
+func (u *GratitudeUsecase) FindByUserIDAndDate(ctx context.Context, userID string, date string) (*gratitude.DailyHighlights, error) {
+	return u.repository.FindByUserIDAndDate(ctx, userID, date)
+}
+
+func (u *GratitudeUsecase) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]gratitude.DailyHighlights, error) {
+	return u.repository.FindByUserIDAndDateRange(ctx, userID, startDate, endDate)
+}
+
+// Save は入力を整えて検証した上で、指定日付の記録を作成または上書きする
+func (u *GratitudeUsecase) Save(ctx context.Context, highlights *gratitude.DailyHighlights) error {
+	highlights.Normalize()
+	if err := highlights.Validate(); err != nil {
+		return err
+	}
+	return u.repository.Upsert(ctx, highlights)
+}
+
+func (u *GratitudeUsecase) Delete(ctx context.Context, userID string, date string) error {
+	return u.repository.Delete(ctx, userID, date)
+}
+
+// RandomPastGratitude は指定日の日記のメンタルスコアが低い場合に、それより前の良かったことを1つ無作為に選ぶ
+func (u *GratitudeUsecase) RandomPastGratitude(ctx context.Context, userID string, date string) (*ResurfacedGratitude, error) {
+	if date == "" {
+		today, err := userToday(ctx, u.preferences, userID, u.now())
+		if err != nil {
+			return nil, err
+		}
+		date = today.String()
+	}
+	result := &ResurfacedGratitude{}
+	d, err := u.diaryRepository.FindByUserIDAndDate(ctx, userID, diary.Date(date))
+	if err != nil {
+		if strings.Contains(err.Error(), "指定された日付の日記が見つかりません") {
+			return result, nil
+		}
+		return nil, err
+	}
+	mental := d.Mental.Value()
+	result.Mental = &mental
+	if !gratitude.IsLowMental(mental) {
+		return result, nil
+	}
+	result.Triggered = true
+
+	records, err := u.repository.FindBefore(ctx, userID, date)
+	if err != nil {
+		return nil, err
+	}
+	memories := gratitude.Memories(records)
+	if len(memories) == 0 {
+		return result, nil
+	}
+	memory := memories[u.randIntn(len(memories))]
+	result.Memory = &memory
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/gratitude"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockGratitudeRepository struct {
+	records  []gratitude.DailyHighlights
+	upserted *gratitude.DailyHighlights
+	err      error
+}
+
+func (m *mockGratitudeRepository) FindByUserID(ctx context.Context, userID string) ([]gratitude.DailyHighlights, error) {
+	return m.records, m.err
+}
+func (m *mockGratitudeRepository) FindByUserIDAndDate(ctx context.Context, userID, date string) (*gratitude.DailyHighlights, error) {
+	return nil, m.err
+}
+func (m *mockGratitudeRepository) FindByUserIDAndDateRange(ctx context.Context, userID, startDate, endDate string) ([]gratitude.DailyHighlights, error) {
+	return m.records, m.err
+}
+func (m *mockGratitudeRepository) FindBefore(ctx context.Context, userID, date string) ([]gratitude.DailyHighlights, error) {
+	var records []gratitude.DailyHighlights
+	for _, h := range m.records {
+		if h.Date < date {
+			records = append(records, h)
+		}
+	}
+	return records, m.err
+}
+func (m *mockGratitudeRepository) Upsert(ctx context.Context, h *gratitude.DailyHighlights) error {
+	m.upserted = h
+	return m.err
+}
+func (m *mockGratitudeRepository) Delete(ctx context.Context, userID, date string) error {
+	return m.err
+}
+func (m *mockGratitudeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return m.err
+}
+
+func TestGratitudeUsecase_Save(t *testing.T) {
+	repo := &mockGratitudeRepository{}
+	usecase := NewGratitudeUsecase(repo, &mockDiaryRepository{}, nil)
+
+	h := &gratitude.DailyHighlights{UserID: "1", Date: "2025-05-01", GoodThings: []string{" 散歩した ", ""}}
+	assert.NoError(t, usecase.Save(context.Background(), h))
+	assert.Equal(t, []string{"散歩した"}, repo.upserted.GoodThings)
+
+	// 空白のみの入力は保存しない
+	repo.upserted = nil
+	err := usecase.Save(context.Background(), &gratitude.DailyHighlights{UserID: "1", Date: "2025-05-01", GoodThings: []string{" "}})
+	assert.Error(t, err)
+	assert.Nil(t, repo.upserted)
+}
+
+func TestGratitudeUsecase_RandomPastGratitude(t *testing.T) {
+	repo := &mockGratitudeRepository{records: []gratitude.DailyHighlights{
+		{Date: "2025-05-01", GoodThings: []string{"散歩した", "友人と話した"}},
+		{Date: "2025-05-10", GoodThings: []string{"今日の良かったこと"}},
+	}}
+	diaryRepo := &mockDiaryRepository{diaries: []diary.Diary{
+		{UserID: "1", Date: "2025-05-09", Mental: 8},
+		{UserID: "1", Date: "2025-05-10", Mental: 3},
+	}}
+	p := user.DefaultPreferences()
+	p.Timezone = "America/Los_Angeles"
+	usecase := &GratitudeUsecase{
+		repository:      repo,
+		diaryRepository: diaryRepo,
+		preferences:     &memoryPreferencesRepo{prefs: map[string]user.Preferences{"1": p}},
+		// UTCでは5月11日だが、ロサンゼルスでは5月10日になる時刻
+		now:      func() time.Time { return time.Date(2025, 5, 11, 3, 0, 0, 0, time.UTC) },
+		randIntn: func(n int) int { return n - 1 },
+	}
+
+	tests := []struct {
+		name          string
+		date          string
+		wantTriggered bool
+		wantMental    *int
+		wantMemory    *gratitude.Memory
+	}{
+		{
+			name:          "メンタルスコアが低い日は指定日より前の良かったことを返す",
+			date:          "2025-05-10",
+			wantTriggered: true,
+			wantMental:    intPtr(3),
+			wantMemory:    &gratitude.Memory{Date: "2025-05-01", Text: "友人と話した"},
+		},
+		{
+			name:       "メンタルスコアが高い日は返さない",
+			date:       "2025-05-09",
+			wantMental: intPtr(8),
+		},
+		{
+			name: "日記がない日は返さない",
+			date: "2025-05-08",
+		},
+		{
+			name:          "日付を省略した場合はユーザーのタイムゾーンでの今日",
+			wantTriggered: true,
+			wantMental:    intPtr(3),
+			wantMemory:    &gratitude.Memory{Date: "2025-05-01", Text: "友人と話した"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := usecase.RandomPastGratitude(context.Background(), "1", tt.date)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTriggered, result.Triggered)
+			assert.Equal(t, tt.wantMental, result.Mental)
+			assert.Equal(t, tt.wantMemory, result.Memory)
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,204 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+)
+
+var ErrGuestRateLimited = errors.New("ゲストの作成が集中しています。しばらくしてから再度お試しください")
+
+// ゲストの作成を制限した単位
+const (
+	GuestLimitScopeIP     = "ip"
+	GuestLimitScopeGlobal = "global"
+)
+
+// GuestRateLimitError はゲストの作成数の上限を超えた場合のエラー（errors.IsでErrGuestRateLimitedと判定できる）
+type GuestRateLimitError struct {
+	Scope string
+	// RetryAfter は再試行までの目安の待ち時間
+	RetryAfter time.Duration
+}
+
+func (e *GuestRateLimitError) Error() string { return ErrGuestRateLimited.Error() }
+func (e *GuestRateLimitError) Unwrap() error { return ErrGuestRateLimited }
+
+// GuestAdmissionConfig はゲストの作成の制限の設定（上限・難易度が0の場合はその制限を行わない）
+type GuestAdmissionConfig struct {
+	Window time.Duration
+	// PerIPLimit はWindowの間に1つのIPアドレスから作成できるゲストの数
+	PerIPLimit int
+	// GlobalLimit はWindowの間に全体で作成できるゲストの数
+	GlobalLimit int
+	// ProofOfWorkDifficulty は作成前に解かせる計算課題の難易度（0の場合は課題を求めない）
+	ProofOfWorkDifficulty int
+}
+
+func DefaultGuestAdmissionConfig() GuestAdmissionConfig {
+	return GuestAdmissionConfig{Window: time.Hour, PerIPLimit: 10, GlobalLimit: 1000}
+}
+
+// GuestAdmissionConfigFromEnv は既定値を GUEST_LOGIN_IP_LIMIT・GUEST_LOGIN_GLOBAL_LIMIT・GUEST_POW_DIFFICULTY で上書きする
+func GuestAdmissionConfigFromEnv() (GuestAdmissionConfig, error) {
+	config := DefaultGuestAdmissionConfig()
+	for _, v := range []struct {
+		env    string
+		target *int
+		max    int
+	}{
+		{env: "GUEST_LOGIN_IP_LIMIT", target: &config.PerIPLimit},
+		{env: "GUEST_LOGIN_GLOBAL_LIMIT", target: &config.GlobalLimit},
+		{env: "GUEST_POW_DIFFICULTY", target: &config.ProofOfWorkDifficulty, max: auth.MaxProofOfWorkDifficulty},
+	} {
+		raw := os.Getenv(v.env)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || (v.max > 0 && n > v.max) {
+			return DefaultGuestAdmissionConfig(), fmt.Errorf("%sの値が不正です: %s", v.env, raw)
+		}
+		*v.target = n
+	}
+	return config, nil
+}
+
+// GuestChallenge はゲストの作成前に解く計算課題（Requiredがfalseの場合は課題を求めない）
+type GuestChallenge struct {
+	Required   bool
+	Challenge  string
+	Difficulty int
+	ExpiresAt  time.Time
+}
+
+// GuestProof はクライアントが計算課題を解いた結果
+type GuestProof struct {
+	Challenge string
+	Nonce     string
+}
+
+// GuestCreationMetrics はゲストの作成数の集計
+type GuestCreationMetrics struct {
+	LastHour            int64
+	LastDay             int64
+	DistinctIPsLastHour int64
+	Config              GuestAdmissionConfig
+}
+
+type IGuestAdmissionUsecase interface {
+	IssueChallenge(ctx context.Context) (*GuestChallenge, error)
+	// Admit はレート制限と計算課題を確認し、ゲストの作成を記録する（制限を超えた場合はGuestRateLimitError）
+	Admit(ctx context.Context, ipAddress string, proof *GuestProof) error
+	Metrics(ctx context.Context) (*GuestCreationMetrics, error)
+}
+
+type GuestAdmissionUsecase struct {
+	repository user.GuestCreationRepository
+	challenges auth.ProofOfWorkChallengeStore
+	config     GuestAdmissionConfig
+	now        func() time.Time
+}
+
+func NewGuestAdmissionUsecase(repository user.GuestCreationRepository, challenges auth.ProofOfWorkChallengeStore, config GuestAdmissionConfig) IGuestAdmissionUsecase {
+	if config.Window <= 0 {
+		config.Window = DefaultGuestAdmissionConfig().Window
+	}
+	return &GuestAdmissionUsecase{repository: repository, challenges: challenges, config: config, now: time.Now}
+}
+
+func (u *GuestAdmissionUsecase) IssueChallenge(ctx context.Context) (*GuestChallenge, error) {
+	if u.config.ProofOfWorkDifficulty == 0 {
+		return &GuestChallenge{}, nil
+	}
+	id, err := auth.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	challenge := auth.ProofOfWorkChallenge{ID: id, Difficulty: u.config.ProofOfWorkDifficulty}
+	token, err := u.challenges.Issue(challenge)
+	if err != nil {
+		return nil, err
+	}
+	issued, err := u.challenges.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return &GuestChallenge{Required: true, Challenge: token, Difficulty: issued.Difficulty, ExpiresAt: issued.ExpiresAt}, nil
+}
+
+func (u *GuestAdmissionUsecase) Admit(ctx context.Context, ipAddress string, proof *GuestProof) error {
+	challengeID, err := u.verifyProof(proof)
+	if err != nil {
+		return err
+	}
+	now := u.now()
+	since := now.Add(-u.config.Window)
+	// IPアドレスは照合にのみ使うため、ハッシュ化して保存する
+	ipHash := auth.HashToken(ipAddress)
+	if u.config.PerIPLimit > 0 {
+		count, err := u.repository.CountByIPSince(ctx, ipHash, since)
+		if err != nil {
+			return err
+		}
+		if count >= int64(u.config.PerIPLimit) {
+			return &GuestRateLimitError{Scope: GuestLimitScopeIP, RetryAfter: u.config.Window}
+		}
+	}
+	if u.config.GlobalLimit > 0 {
+		count, err := u.repository.CountSince(ctx, since)
+		if err != nil {
+			return err
+		}
+		if count >= int64(u.config.GlobalLimit) {
+			return &GuestRateLimitError{Scope: GuestLimitScopeGlobal, RetryAfter: u.config.Window}
+		}
+	}
+	// 数えてから記録するまでの間に同時に作成された分だけ上限を超える場合があるが、大量作成の抑止には十分とする
+	err = u.repository.Record(ctx, &user.GuestCreation{IPHash: ipHash, ChallengeID: challengeID, CreatedAt: now})
+	if errors.Is(err, user.ErrGuestChallengeUsed) {
+		return auth.ErrInvalidProofOfWork
+	}
+	return err
+}
+
+// verifyProof は計算課題を求める設定の場合に解答を検証し、課題のIDを返す
+func (u *GuestAdmissionUsecase) verifyProof(proof *GuestProof) (string, error) {
+	if u.config.ProofOfWorkDifficulty == 0 {
+		return "", nil
+	}
+	if proof == nil || proof.Challenge == "" || proof.Nonce == "" {
+		return "", auth.ErrProofOfWorkRequired
+	}
+	challenge, err := u.challenges.Verify(proof.Challenge)
+	if err != nil {
+		return "", err
+	}
+	// 課題の発行後に難易度を上げた場合は、古い難易度の課題を受け付けない
+	if challenge.Difficulty < u.config.ProofOfWorkDifficulty || !auth.SolvesProofOfWork(proof.Challenge, proof.Nonce, challenge.Difficulty) {
+		return "", auth.ErrInvalidProofOfWork
+	}
+	return challenge.ID, nil
+}
+
+func (u *GuestAdmissionUsecase) Metrics(ctx context.Context) (*GuestCreationMetrics, error) {
+	now := u.now()
+	lastHour, err := u.repository.CountSince(ctx, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	lastDay, err := u.repository.CountSince(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	distinctIPs, err := u.repository.CountDistinctIPsSince(ctx, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	return &GuestCreationMetrics{LastHour: lastHour, LastDay: lastDay, DistinctIPsLastHour: distinctIPs, Config: u.config}, nil
+}
@@ -0,0 +1,167 @@
+package usecases
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryGuestCreationRepo struct {
+	creations []*user.GuestCreation
+}
+
+func (m *memoryGuestCreationRepo) Record(ctx context.Context, creation *user.GuestCreation) error {
+	for _, c := range m.creations {
+		if creation.ChallengeID != "" && c.ChallengeID == creation.ChallengeID {
+			return user.ErrGuestChallengeUsed
+		}
+	}
+	m.creations = append(m.creations, creation)
+	return nil
+}
+func (m *memoryGuestCreationRepo) CountSince(ctx context.Context, since time.Time) (int64, error) {
+	return m.count(func(c *user.GuestCreation) bool { return !c.CreatedAt.Before(since) }), nil
+}
+func (m *memoryGuestCreationRepo) CountByIPSince(ctx context.Context, ipHash string, since time.Time) (int64, error) {
+	return m.count(func(c *user.GuestCreation) bool { return c.IPHash == ipHash && !c.CreatedAt.Before(since) }), nil
+}
+func (m *memoryGuestCreationRepo) CountDistinctIPsSince(ctx context.Context, since time.Time) (int64, error) {
+	ips := map[string]bool{}
+	for _, c := range m.creations {
+		if !c.CreatedAt.Before(since) {
+			ips[c.IPHash] = true
+		}
+	}
+	return int64(len(ips)), nil
+}
+func (m *memoryGuestCreationRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	kept := m.creations[:0]
+	for _, c := range m.creations {
+		if !c.CreatedAt.Before(before) {
+			kept = append(kept, c)
+		}
+	}
+	deleted := int64(len(m.creations) - len(kept))
+	m.creations = kept
+	return deleted, nil
+}
+func (m *memoryGuestCreationRepo) count(match func(*user.GuestCreation) bool) int64 {
+	var n int64
+	for _, c := range m.creations {
+		if match(c) {
+			n++
+		}
+	}
+	return n
+}
+
+// memoryProofOfWorkStore は課題を「pow:ID:難易度」の文字列として発行する
+type memoryProofOfWorkStore struct{}
+
+func (memoryProofOfWorkStore) Issue(challenge auth.ProofOfWorkChallenge) (string, error) {
+	return "pow:" + challenge.ID + ":" + strconv.Itoa(challenge.Difficulty), nil
+}
+func (memoryProofOfWorkStore) Verify(token string) (*auth.ProofOfWorkChallenge, error) {
+	parts := strings.Split(token, ":")
+	if len(parts) != 3 || parts[0] != "pow" {
+		return nil, auth.ErrInvalidProofOfWork
+	}
+	difficulty, _ := strconv.Atoi(parts[2])
+	return &auth.ProofOfWorkChallenge{ID: parts[1], Difficulty: difficulty}, nil
+}
+
+func solveProofOfWork(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		if nonce := strconv.Itoa(i); auth.SolvesProofOfWork(challenge, nonce, difficulty) {
+			return nonce
+		}
+	}
+}
+
+func newGuestAdmissionTestUsecase(config GuestAdmissionConfig, now *time.Time) (*GuestAdmissionUsecase, *memoryGuestCreationRepo) {
+	repo := &memoryGuestCreationRepo{}
+	u := NewGuestAdmissionUsecase(repo, memoryProofOfWorkStore{}, config).(*GuestAdmissionUsecase)
+	u.now = func() time.Time { return *now }
+	return u, repo
+}
+
+func TestGuestAdmissionUsecase_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	u, repo := newGuestAdmissionTestUsecase(GuestAdmissionConfig{Window: time.Hour, PerIPLimit: 2, GlobalLimit: 3}, &now)
+
+	require.NoError(t, u.Admit(ctx, "192.0.2.1", nil))
+	require.NoError(t, u.Admit(ctx, "192.0.2.1", nil))
+	err := u.Admit(ctx, "192.0.2.1", nil)
+	assert.ErrorIs(t, err, ErrGuestRateLimited)
+	var limitErr *GuestRateLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, GuestLimitScopeIP, limitErr.Scope)
+	assert.NotEqual(t, "192.0.2.1", repo.creations[0].IPHash, "IPアドレスはそのまま保存しない")
+
+	require.NoError(t, u.Admit(ctx, "192.0.2.2", nil))
+	err = u.Admit(ctx, "192.0.2.3", nil)
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, GuestLimitScopeGlobal, limitErr.Scope)
+
+	now = now.Add(time.Hour + time.Minute)
+	assert.NoError(t, u.Admit(ctx, "192.0.2.1", nil), "期間を過ぎると再び作成できる")
+
+	metrics, err := u.Metrics(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), metrics.LastHour)
+	assert.Equal(t, int64(4), metrics.LastDay)
+	assert.Equal(t, int64(1), metrics.DistinctIPsLastHour)
+}
+
+func TestGuestAdmissionUsecase_ProofOfWork(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	u, _ := newGuestAdmissionTestUsecase(GuestAdmissionConfig{Window: time.Hour, ProofOfWorkDifficulty: 8}, &now)
+
+	challenge, err := u.IssueChallenge(ctx)
+	require.NoError(t, err)
+	require.True(t, challenge.Required)
+	assert.Equal(t, 8, challenge.Difficulty)
+
+	assert.ErrorIs(t, u.Admit(ctx, "192.0.2.1", nil), auth.ErrProofOfWorkRequired)
+	nonce := solveProofOfWork(challenge.Challenge, challenge.Difficulty)
+	wrongNonce := nonce + "x"
+	if !auth.SolvesProofOfWork(challenge.Challenge, wrongNonce, challenge.Difficulty) {
+		assert.ErrorIs(t, u.Admit(ctx, "192.0.2.1", &GuestProof{Challenge: challenge.Challenge, Nonce: wrongNonce}), auth.ErrInvalidProofOfWork)
+	}
+	require.NoError(t, u.Admit(ctx, "192.0.2.1", &GuestProof{Challenge: challenge.Challenge, Nonce: nonce}))
+	assert.ErrorIs(t, u.Admit(ctx, "192.0.2.2", &GuestProof{Challenge: challenge.Challenge, Nonce: nonce}), auth.ErrInvalidProofOfWork, "同じ解答は使い回せない")
+
+	t.Run("難易度を上げる前の課題は受け付けない", func(t *testing.T) {
+		u.config.ProofOfWorkDifficulty = 12
+		easy := "pow:old:8"
+		assert.ErrorIs(t, u.Admit(ctx, "192.0.2.3", &GuestProof{Challenge: easy, Nonce: solveProofOfWork(easy, 8)}), auth.ErrInvalidProofOfWork)
+	})
+
+	t.Run("課題を求めない設定", func(t *testing.T) {
+		u, _ := newGuestAdmissionTestUsecase(DefaultGuestAdmissionConfig(), &now)
+		challenge, err := u.IssueChallenge(ctx)
+		require.NoError(t, err)
+		assert.False(t, challenge.Required)
+	})
+}
+
+func TestGuestAdmissionConfigFromEnv(t *testing.T) {
+	t.Setenv("GUEST_LOGIN_IP_LIMIT", "3")
+	t.Setenv("GUEST_POW_DIFFICULTY", "18")
+	config, err := GuestAdmissionConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, GuestAdmissionConfig{Window: time.Hour, PerIPLimit: 3, GlobalLimit: 1000, ProofOfWorkDifficulty: 18}, config)
+
+	t.Setenv("GUEST_POW_DIFFICULTY", "64")
+	_, err = GuestAdmissionConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+)
+
+const (
+	// lastSeenTouchInterval より短い間隔では最終利用日時を更新しない（リクエストごとの書き込みを避ける）
+	lastSeenTouchInterval = time.Hour
+	// guestCreationRetention はゲストの作成の記録（レート制限・集計用）を保持する期間
+	guestCreationRetention = 7 * 24 * time.Hour
+)
+
+// GuestRetentionConfig は利用のないゲストアカウントの自動削除の設定
+type GuestRetentionConfig struct {
+	user.GuestRetentionPolicy
+	// BatchSize は1回の実行で予告・削除するゲストのそれぞれの上限
+	BatchSize int
+}
+
+func DefaultGuestRetentionConfig() GuestRetentionConfig {
+	return GuestRetentionConfig{
+		GuestRetentionPolicy: user.GuestRetentionPolicy{InactiveAfter: 90 * 24 * time.Hour, NoticePeriod: 14 * 24 * time.Hour},
+		BatchSize:            500,
+	}
+}
+
+// GuestRetentionConfigFromEnv は既定値を GUEST_INACTIVE_DAYS・GUEST_DELETION_NOTICE_DAYS（日数）で上書きする
+func GuestRetentionConfigFromEnv() (GuestRetentionConfig, error) {
+	config := DefaultGuestRetentionConfig()
+	for _, v := range []struct {
+		env    string
+		target *time.Duration
+	}{
+		{env: "GUEST_INACTIVE_DAYS", target: &config.InactiveAfter},
+		{env: "GUEST_DELETION_NOTICE_DAYS", target: &config.NoticePeriod},
+	} {
+		raw := os.Getenv(v.env)
+		if raw == "" {
+			continue
+		}
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 1 {
+			return DefaultGuestRetentionConfig(), fmt.Errorf("%sの値が不正です: %s", v.env, raw)
+		}
+		*v.target = time.Duration(days) * 24 * time.Hour
+	}
+	return config, nil
+}
+
+// GuestRetentionReport は自動削除の実行結果（ドライランの場合は予告・削除する予定のゲスト）
+type GuestRetentionReport struct {
+	DryRun bool
+	// Noticed は削除を予告したゲストのID
+	Noticed []string
+	// Deleted は日記と合わせて削除したゲストのID
+	Deleted []string
+	// Failed は削除に失敗したゲストのID（次回の実行で再度削除する）
+	Failed []string
+	// PrunedGuestCreations は保持期間を過ぎて削除したゲストの作成の記録の件数
+	PrunedGuestCreations int64
+}
+
+type IGuestRetentionUsecase interface {
+	// Run は利用のないゲストに削除を予告し、猶予期間を過ぎても利用のないゲストを削除する（dryRunの場合は対象を返すのみ）
+	Run(ctx context.Context, dryRun bool) (*GuestRetentionReport, error)
+	// RecordLastSeen は最終利用日時を記録し、削除の予告を取り消す（一定間隔より短い場合は更新しない）
+	RecordLastSeen(ctx context.Context, userID string) error
+	// DeletionScheduledAt は削除を予告したゲストの削除予定日時を返す（予告していない場合はnil）
+	DeletionScheduledAt(u *user.User) *time.Time
+}
+
+type GuestRetentionUsecase struct {
+	repository      user.RetentionRepository
+	guestCreations  user.GuestCreationRepository
+	withdrawUsecase *UserWithdrawUsecase
+	notifier        notification.Notifier
+	config          GuestRetentionConfig
+	now             func() time.Time
+}
+
+func NewGuestRetentionUsecase(repository user.RetentionRepository, guestCreations user.GuestCreationRepository, withdrawUsecase *UserWithdrawUsecase, notifier notification.Notifier, config GuestRetentionConfig) IGuestRetentionUsecase {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultGuestRetentionConfig().BatchSize
+	}
+	return &GuestRetentionUsecase{
+		repository:      repository,
+		guestCreations:  guestCreations,
+		withdrawUsecase: withdrawUsecase,
+		notifier:        notifier,
+		config:          config,
+		now:             time.Now,
+	}
+}
+
+func (u *GuestRetentionUsecase) Run(ctx context.Context, dryRun bool) (*GuestRetentionReport, error) {
+	now := u.now()
+	report := &GuestRetentionReport{DryRun: dryRun, Noticed: []string{}, Deleted: []string{}, Failed: []string{}}
+
+	// 猶予期間を過ぎたゲストを削除してから、新たに利用のなくなったゲストに予告する
+	toDelete, err := u.repository.FindGuestsToDelete(ctx, now.Add(-u.config.NoticePeriod), u.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, guest := range toDelete {
+		if dryRun {
+			report.Deleted = append(report.Deleted, guest.ID)
+			continue
+		}
+		if err := u.withdrawUsecase.Purge(ctx, guest.ID); err != nil {
+			log.Printf("[ERROR] GuestRetention: ゲストの削除に失敗しました user_id=%s: %v", guest.ID, err)
+			report.Failed = append(report.Failed, guest.ID)
+			continue
+		}
+		report.Deleted = append(report.Deleted, guest.ID)
+	}
+
+	inactiveBefore := now.Add(-u.config.InactiveAfter)
+	candidates, err := u.repository.FindGuestsToNotify(ctx, inactiveBefore, u.config.BatchSize)
+	if err != nil {
+		return report, err
+	}
+	for _, guest := range candidates {
+		if dryRun {
+			report.Noticed = append(report.Noticed, guest.ID)
+			continue
+		}
+		noticed, err := u.repository.MarkDeletionNoticed(ctx, guest.ID, now, inactiveBefore)
+		if err != nil {
+			return report, err
+		}
+		if !noticed {
+			continue
+		}
+		report.Noticed = append(report.Noticed, guest.ID)
+		// 予告はアプリ内（GET /me の削除予定日時）で必ず表示されるため、通知の失敗では中断しない
+		if err := u.notifier.Notify(ctx, u.noticeMessage(guest.ID, now)); err != nil {
+			log.Printf("[ERROR] GuestRetention: 削除の予告の通知に失敗しました user_id=%s: %v", guest.ID, err)
+		}
+	}
+
+	if !dryRun && u.guestCreations != nil {
+		pruned, err := u.guestCreations.DeleteBefore(ctx, now.Add(-guestCreationRetention))
+		if err != nil {
+			return report, err
+		}
+		report.PrunedGuestCreations = pruned
+	}
+	return report, nil
+}
+
+func (u *GuestRetentionUsecase) noticeMessage(userID string, now time.Time) notification.Message {
+	scheduled := now.Add(u.config.NoticePeriod)
+	return notification.Message{
+		UserID: userID,
+		Kind:   notification.KindGuestDeletionNotice,
+		Title:  "ゲストアカウントの削除予定のお知らせ",
+		Body:   fmt.Sprintf("しばらく利用がないため、%sにゲストアカウントと日記を削除します。引き続き利用する場合はログインしてください。", scheduled.Format("2006-01-02")),
+		Data:   map[string]string{"deletion_scheduled_at": scheduled.Format(time.RFC3339)},
+	}
+}
+
+func (u *GuestRetentionUsecase) RecordLastSeen(ctx context.Context, userID string) error {
+	now := u.now()
+	return u.repository.TouchLastSeen(ctx, userID, now, now.Add(-lastSeenTouchInterval))
+}
+
+func (u *GuestRetentionUsecase) DeletionScheduledAt(account *user.User) *time.Time {
+	return u.config.DeletionScheduledAt(account)
+}
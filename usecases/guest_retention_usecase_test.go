@@ -0,0 +1,188 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRetentionRepo struct {
+	users map[string]*user.User
+}
+
+func (m *memoryRetentionRepo) TouchLastSeen(ctx context.Context, userID string, now, staleBefore time.Time) error {
+	u, ok := m.users[userID]
+	if !ok {
+		return nil
+	}
+	if u.LastSeenAt == nil || u.LastSeenAt.Before(staleBefore) || u.DeletionNoticeAt != nil {
+		u.LastSeenAt, u.DeletionNoticeAt = &now, nil
+	}
+	return nil
+}
+func (m *memoryRetentionRepo) FindGuestsToNotify(ctx context.Context, inactiveBefore time.Time, limit int) ([]*user.User, error) {
+	return m.find(limit, func(u *user.User) bool {
+		return u.DeletionNoticeAt == nil && u.LastActiveAt().Before(inactiveBefore)
+	}), nil
+}
+func (m *memoryRetentionRepo) FindGuestsToDelete(ctx context.Context, noticedBefore time.Time, limit int) ([]*user.User, error) {
+	return m.find(limit, func(u *user.User) bool {
+		return u.DeletionNoticeAt != nil && u.DeletionNoticeAt.Before(noticedBefore)
+	}), nil
+}
+func (m *memoryRetentionRepo) MarkDeletionNoticed(ctx context.Context, userID string, now, inactiveBefore time.Time) (bool, error) {
+	u, ok := m.users[userID]
+	if !ok || !u.IsGuest || u.DeletionNoticeAt != nil || !u.LastActiveAt().Before(inactiveBefore) {
+		return false, nil
+	}
+	u.DeletionNoticeAt = &now
+	return true, nil
+}
+func (m *memoryRetentionRepo) FindWithdrawnBefore(ctx context.Context, withdrawnBefore time.Time, limit int) ([]*user.User, error) {
+	var found []*user.User
+	for _, u := range m.users {
+		if u.WithdrawnAt != nil && u.WithdrawnAt.Before(withdrawnBefore) {
+			found = append(found, u)
+		}
+	}
+	return found, nil
+}
+func (m *memoryRetentionRepo) find(limit int, match func(u *user.User) bool) []*user.User {
+	var found []*user.User
+	for _, u := range m.users {
+		if u.IsGuest && match(u) {
+			found = append(found, u)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found
+}
+
+// retentionUserRepo は退会処理で削除したユーザーをmemoryRetentionRepoから取り除く
+type retentionUserRepo struct {
+	mockUserRepo
+	retention *memoryRetentionRepo
+	failFor   string
+}
+
+func (m *retentionUserRepo) DeleteByID(ctx context.Context, id string) error {
+	if id == m.failFor {
+		return errors.New("delete error")
+	}
+	delete(m.retention.users, id)
+	return nil
+}
+
+func newGuestRetentionTestUsecase(now *time.Time, failFor string) (*GuestRetentionUsecase, *memoryRetentionRepo, *mockNotifier, *memoryGuestCreationRepo) {
+	daysAgo := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	repo := &memoryRetentionRepo{users: map[string]*user.User{
+		"g-inactive":  {ID: "g-inactive", IsGuest: true, CreatedAt: *daysAgo(200), LastSeenAt: daysAgo(100)},
+		"g-never":     {ID: "g-never", IsGuest: true, CreatedAt: *daysAgo(95)},
+		"g-active":    {ID: "g-active", IsGuest: true, CreatedAt: *daysAgo(200), LastSeenAt: daysAgo(3)},
+		"member-idle": {ID: "member-idle", CreatedAt: *daysAgo(400)},
+	}}
+	sessions, _, _ := newSessionTestUsecase()
+	withdraw := NewUserWithdrawUsecase(&directUnitOfWork{}, &retentionUserRepo{retention: repo, failFor: failFor}, repo, sessions, nil, nil, &mockDiaryRepo{})
+	notifier := &mockNotifier{}
+	guestCreations := &memoryGuestCreationRepo{creations: []*user.GuestCreation{
+		{IPHash: "ip1", CreatedAt: now.AddDate(0, 0, -30)},
+		{IPHash: "ip1", CreatedAt: now.AddDate(0, 0, -8)},
+		{IPHash: "ip2", CreatedAt: now.Add(-time.Hour)},
+	}}
+	u := NewGuestRetentionUsecase(repo, guestCreations, withdraw, notifier, DefaultGuestRetentionConfig()).(*GuestRetentionUsecase)
+	u.now = func() time.Time { return *now }
+	return u, repo, notifier, guestCreations
+}
+
+func TestGuestRetentionUsecase_Run(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 3, 0, 0, 0, time.UTC)
+	u, repo, notifier, guestCreations := newGuestRetentionTestUsecase(&now, "")
+
+	t.Run("ドライランでは何も変更しない", func(t *testing.T) {
+		report, err := u.Run(ctx, true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, []string{"g-inactive", "g-never"}, report.Noticed)
+		assert.Empty(t, report.Deleted)
+		assert.Nil(t, repo.users["g-inactive"].DeletionNoticeAt)
+		assert.Empty(t, notifier.messages)
+		assert.Len(t, guestCreations.creations, 3)
+	})
+
+	report, err := u.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g-inactive", "g-never"}, report.Noticed)
+	assert.Empty(t, report.Deleted, "予告したその日には削除しない")
+	assert.Equal(t, int64(2), report.PrunedGuestCreations)
+	assert.Len(t, guestCreations.creations, 1, "保持期間を過ぎた作成の記録を削除する")
+	require.Len(t, notifier.messages, 2)
+	assert.Equal(t, notification.KindGuestDeletionNotice, notifier.messages[0].Kind)
+	assert.Equal(t, "2025-05-15", notifier.messages[0].Data["deletion_scheduled_at"][:10])
+	assert.Equal(t, now.AddDate(0, 0, 14), *u.DeletionScheduledAt(repo.users["g-never"]))
+	assert.Nil(t, u.DeletionScheduledAt(repo.users["g-active"]))
+
+	// 予告を受けたゲストが利用を再開すると予告を取り消す
+	now = now.AddDate(0, 0, 1)
+	require.NoError(t, u.RecordLastSeen(ctx, "g-never"))
+	assert.Nil(t, repo.users["g-never"].DeletionNoticeAt)
+
+	t.Run("ドライランで削除の対象を返す", func(t *testing.T) {
+		now = now.AddDate(0, 0, 14)
+		report, err := u.Run(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"g-inactive"}, report.Deleted)
+		assert.Contains(t, repo.users, "g-inactive")
+	})
+
+	report, err = u.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g-inactive"}, report.Deleted)
+	assert.NotContains(t, repo.users, "g-inactive")
+	assert.Contains(t, repo.users, "g-never", "利用を再開したゲストは削除しない")
+	assert.Contains(t, repo.users, "member-idle", "アカウントを連携したユーザーは対象外")
+}
+
+func TestGuestRetentionUsecase_RunContinuesAfterDeleteFailure(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 3, 0, 0, 0, time.UTC)
+	u, repo, _, _ := newGuestRetentionTestUsecase(&now, "g-inactive")
+	_, err := u.Run(ctx, false)
+	require.NoError(t, err)
+
+	now = now.AddDate(0, 0, 15)
+	report, err := u.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g-inactive"}, report.Failed)
+	assert.Equal(t, []string{"g-never"}, report.Deleted)
+	assert.Contains(t, repo.users, "g-inactive", "次回の実行で再度削除する")
+}
+
+func TestGuestRetentionUsecase_RecordLastSeenThrottles(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 3, 0, 0, 0, time.UTC)
+	u, repo, _, _ := newGuestRetentionTestUsecase(&now, "")
+	require.NoError(t, u.RecordLastSeen(ctx, "g-active"))
+	first := now
+
+	now = now.Add(lastSeenTouchInterval / 2)
+	require.NoError(t, u.RecordLastSeen(ctx, "g-active"))
+	assert.Equal(t, first, *repo.users["g-active"].LastSeenAt)
+
+	now = first.Add(lastSeenTouchInterval + time.Second)
+	require.NoError(t, u.RecordLastSeen(ctx, "g-active"))
+	assert.Equal(t, now, *repo.users["g-active"].LastSeenAt)
+}
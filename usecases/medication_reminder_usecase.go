@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"tofunote-backend/domain/medication"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+)
+
+type IMedicationReminderUsecase interface {
+	SendDueReminders(ctx context.Context, from, to time.Time) (int, error)
+}
+
+type MedicationReminderUsecase struct {
+	repository        medication.Repository
+	doseLogRepository medication.DoseLogRepository
+	preferences       user.PreferencesRepository
+	notifier          notification.Notifier
+}
+
+func NewMedicationReminderUsecase(repository medication.Repository, doseLogRepository medication.DoseLogRepository, preferences user.PreferencesRepository, notifier notification.Notifier) IMedicationReminderUsecase {
+	return &MedicationReminderUsecase{
+		repository:        repository,
+		doseLogRepository: doseLogRepository,
+		preferences:       preferences,
+		notifier:          notifier,
+	}
+}
+
+// SendDueReminders は (from, to] の間に服用時刻を迎えた未記録の服用についてリマインダーを送信し、送信件数を返す。
+// 服用時刻は薬を登録したユーザーの設定のタイムゾーンで解釈する。
+// 一部のユーザーへの送信に失敗しても残りのユーザーへの送信は続ける
+func (u *MedicationReminderUsecase) SendDueReminders(ctx context.Context, from, to time.Time) (int, error) {
+	meds, err := u.repository.FindRemindable(ctx)
+	if err != nil {
+		return 0, err
+	}
+	byUser := make(map[string][]medication.Medication)
+	var userIDs []string
+	for _, m := range meds {
+		if _, ok := byUser[m.UserID]; !ok {
+			userIDs = append(userIDs, m.UserID)
+		}
+		byUser[m.UserID] = append(byUser[m.UserID], m)
+	}
+
+	sent := 0
+	for _, userID := range userIDs {
+		prefs, err := u.preferences.FindByUserID(ctx, userID)
+		if err != nil {
+			log.Printf("[ERROR] MedicationReminder: 設定の取得に失敗しました user_id=%s: %v", userID, err)
+			continue
+		}
+		if prefs == nil {
+			defaults := user.DefaultPreferences()
+			prefs = &defaults
+		}
+		loc := prefs.Location()
+		localFrom, localTo := from.In(loc), to.In(loc)
+		logs, err := u.doseLogRepository.FindByUserIDAndDateRange(ctx, userID, localFrom.Format("2006-01-02"), localTo.Format("2006-01-02"))
+		if err != nil {
+			log.Printf("[ERROR] MedicationReminder: 服用の記録の取得に失敗しました user_id=%s: %v", userID, err)
+			continue
+		}
+		for _, r := range medication.DueReminders(byUser[userID], logs, localFrom, localTo) {
+			err := u.notifier.Notify(ctx, r.Message())
+			if errors.Is(err, notification.ErrNoRecipient) {
+				continue
+			}
+			if err != nil {
+				log.Printf("[ERROR] MedicationReminder: 通知の送信に失敗しました user_id=%s medication_id=%s: %v", userID, r.Medication.ID, err)
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
@@ -0,0 +1,149 @@
+package usecases
+
+import (
+	"context"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/medication"
+	"tofunote-backend/domain/user"
+)
+
+type IMedicationUsecase interface {
+	FindAll(ctx context.Context, userID string) ([]medication.Medication, error)
+	FindByID(ctx context.Context, userID string, id string) (*medication.Medication, error)
+	Create(ctx context.Context, m *medication.Medication) error
+	Update(ctx context.Context, m *medication.Medication) error
+	Delete(ctx context.Context, userID string, id string) error
+	RecordDose(ctx context.Context, userID string, medicationID string, date, scheduledTime string, status medication.DoseStatus, takenAt *time.Time, note string) (*medication.DoseLog, error)
+	DeleteDose(ctx context.Context, userID string, medicationID string, date, scheduledTime string) error
+	FindDoses(ctx context.Context, userID string, startDate, endDate string) ([]medication.DoseLog, error)
+	// Adherence は期間を指定しない場合、ユーザーの設定のタイムゾーンでの今日までの30日間を対象にする
+	Adherence(ctx context.Context, userID string, medicationID string, startDate, endDate string) (*medication.AdherenceReport, error)
+}
+
+type MedicationUsecase struct {
+	repository        medication.Repository
+	doseLogRepository medication.DoseLogRepository
+	diaryRepository   diary.DiaryRepository
+	preferences       user.PreferencesRepository
+	now               func() time.Time
+}
+
+func NewMedicationUsecase(repository medication.Repository, doseLogRepository medication.DoseLogRepository, diaryRepository diary.DiaryRepository, preferences user.PreferencesRepository) IMedicationUsecase {
+	return &MedicationUsecase{
+		repository:        repository,
+		doseLogRepository: doseLogRepository,
+		diaryRepository:   diaryRepository,
+		preferences:       preferences,
+		now:               time.Now,
+	}
+}
+
+func (u *MedicationUsecase) FindAll(ctx context.Context, userID string) ([]medication.Medication, error) {
+	return u.repository.FindByUserID(ctx, userID)
+}
+
+func (u *MedicationUsecase) FindByID(ctx context.Context, userID string, id string) (*medication.Medication, error) {
+	return u.repository.FindByID(ctx, userID, id)
+}
+
+func (u *MedicationUsecase) Create(ctx context.Context, m *medication.Medication) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	return u.repository.Create(ctx, m)
+}
+
+func (u *MedicationUsecase) Update(ctx context.Context, m *medication.Medication) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	return u.repository.Update(ctx, m)
+}
+
+// Delete は薬とその服用記録を削除する
+func (u *MedicationUsecase) Delete(ctx context.Context, userID string, id string) error {
+	if _, err := u.repository.FindByID(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := u.doseLogRepository.DeleteByMedicationID(ctx, userID, id); err != nil {
+		return err
+	}
+	return u.repository.Delete(ctx, userID, id)
+}
+
+// RecordDose は予定された服用を服用済み・スキップとして記録する（同じ服用の記録は上書きする）
+func (u *MedicationUsecase) RecordDose(ctx context.Context, userID string, medicationID string, date, scheduledTime string, status medication.DoseStatus, takenAt *time.Time, note string) (*medication.DoseLog, error) {
+	m, err := u.repository.FindByID(ctx, userID, medicationID)
+	if err != nil {
+		return nil, err
+	}
+	if status == medication.DoseTaken && takenAt == nil {
+		now := u.now()
+		takenAt = &now
+	}
+	log, err := medication.NewDoseLog(*m, date, scheduledTime, status, takenAt, note)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.doseLogRepository.Upsert(ctx, &log); err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (u *MedicationUsecase) DeleteDose(ctx context.Context, userID string, medicationID string, date, scheduledTime string) error {
+	return u.doseLogRepository.Delete(ctx, userID, medicationID, date, scheduledTime)
+}
+
+func (u *MedicationUsecase) FindDoses(ctx context.Context, userID string, startDate, endDate string) ([]medication.DoseLog, error) {
+	return u.doseLogRepository.FindByUserIDAndDateRange(ctx, userID, startDate, endDate)
+}
+
+// Adherence は期間内の服用率の推移をメンタルスコアと重ねて返す（medicationIDが空の場合は全ての薬を対象にする）。
+// 今日より先の日付は未服用として数えないよう、終了日は今日までに切り詰める
+func (u *MedicationUsecase) Adherence(ctx context.Context, userID string, medicationID string, startDate, endDate string) (*medication.AdherenceReport, error) {
+	today, err := userToday(ctx, u.preferences, userID, u.now())
+	if err != nil {
+		return nil, err
+	}
+	if startDate == "" && endDate == "" {
+		endDate = today.String()
+		startDate = today.AddDays(-29).String()
+	}
+	if endDate > today.String() {
+		endDate = today.String()
+	}
+	var meds []medication.Medication
+	if medicationID != "" {
+		m, err := u.repository.FindByID(ctx, userID, medicationID)
+		if err != nil {
+			return nil, err
+		}
+		meds = []medication.Medication{*m}
+	} else {
+		meds, err = u.repository.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	logs, err := u.doseLogRepository.FindByUserIDAndDateRange(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	report, err := medication.ComputeAdherence(meds, logs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	diaries, err := u.diaryRepository.FindByUserIDAndDateRange(ctx, userID, diary.Date(startDate), diary.Date(endDate))
+	if err != nil {
+		return nil, err
+	}
+	moods := make(map[string]int, len(diaries))
+	for _, d := range diaries {
+		moods[d.Date.String()] = d.Mental.Value()
+	}
+	report.OverlayMood(moods)
+	return report, nil
+}
@@ -0,0 +1,193 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/medication"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockMedicationRepository struct {
+	meds []medication.Medication
+	err  error
+}
+
+func (m *mockMedicationRepository) FindByUserID(ctx context.Context, userID string) ([]medication.Medication, error) {
+	return m.meds, m.err
+}
+func (m *mockMedicationRepository) FindByID(ctx context.Context, userID, id string) (*medication.Medication, error) {
+	for _, med := range m.meds {
+		if med.UserID == userID && med.ID == id {
+			return &med, nil
+		}
+	}
+	return nil, errors.New("指定された薬が見つかりません")
+}
+func (m *mockMedicationRepository) FindRemindable(ctx context.Context) ([]medication.Medication, error) {
+	return m.meds, m.err
+}
+func (m *mockMedicationRepository) Create(ctx context.Context, med *medication.Medication) error {
+	return m.err
+}
+func (m *mockMedicationRepository) Update(ctx context.Context, med *medication.Medication) error {
+	return m.err
+}
+func (m *mockMedicationRepository) Delete(ctx context.Context, userID, id string) error { return m.err }
+func (m *mockMedicationRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return m.err
+}
+
+type mockDoseLogRepository struct {
+	logs     []medication.DoseLog
+	upserted *medication.DoseLog
+	err      error
+}
+
+func (m *mockDoseLogRepository) FindByUserID(ctx context.Context, userID string) ([]medication.DoseLog, error) {
+	return m.logs, m.err
+}
+func (m *mockDoseLogRepository) FindByUserIDAndDateRange(ctx context.Context, userID, startDate, endDate string) ([]medication.DoseLog, error) {
+	var logs []medication.DoseLog
+	for _, l := range m.logs {
+		if l.UserID == userID && l.Date >= startDate && l.Date <= endDate {
+			logs = append(logs, l)
+		}
+	}
+	return logs, m.err
+}
+func (m *mockDoseLogRepository) Upsert(ctx context.Context, l *medication.DoseLog) error {
+	m.upserted = l
+	return m.err
+}
+func (m *mockDoseLogRepository) Delete(ctx context.Context, userID, medicationID, date, scheduledTime string) error {
+	return m.err
+}
+func (m *mockDoseLogRepository) DeleteByMedicationID(ctx context.Context, userID, medicationID string) error {
+	return m.err
+}
+func (m *mockDoseLogRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return m.err
+}
+
+type mockNotifier struct {
+	messages []notification.Message
+	err      error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, msg notification.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func TestMedicationUsecase_RecordDose(t *testing.T) {
+	medRepo := &mockMedicationRepository{meds: []medication.Medication{
+		{ID: "m1", UserID: "1", Name: "薬A", Schedule: medication.Schedule{Times: []string{"08:00"}}, StartDate: "2025-05-01"},
+	}}
+	doseRepo := &mockDoseLogRepository{}
+	usecase := NewMedicationUsecase(medRepo, doseRepo, &mockDiaryRepository{}, nil)
+
+	log, err := usecase.RecordDose(context.Background(), "1", "m1", "2025-05-01", "08:00", medication.DoseTaken, nil, "")
+	assert.NoError(t, err)
+	assert.NotNil(t, log.TakenAt, "服用日時が未指定の場合は現在時刻を記録する")
+	assert.Equal(t, log, doseRepo.upserted)
+
+	// 他のユーザーの薬は記録できない
+	_, err = usecase.RecordDose(context.Background(), "2", "m1", "2025-05-01", "08:00", medication.DoseTaken, nil, "")
+	assert.EqualError(t, err, "指定された薬が見つかりません")
+}
+
+func TestMedicationUsecase_Adherence(t *testing.T) {
+	medRepo := &mockMedicationRepository{meds: []medication.Medication{
+		{ID: "m1", UserID: "1", Schedule: medication.Schedule{Times: []string{"08:00"}}, StartDate: "2025-05-01"},
+	}}
+	doseRepo := &mockDoseLogRepository{logs: []medication.DoseLog{
+		{UserID: "1", MedicationID: "m1", Date: "2025-05-01", ScheduledTime: "08:00", Status: medication.DoseTaken},
+	}}
+	diaryRepo := &mockDiaryRepository{diaries: []diary.Diary{
+		{UserID: "1", Date: "2025-05-01", Mental: 7},
+		{UserID: "1", Date: "2025-05-02", Mental: 3},
+	}}
+	usecase := &MedicationUsecase{
+		repository:        medRepo,
+		doseLogRepository: doseRepo,
+		diaryRepository:   diaryRepo,
+		now:               func() time.Time { return time.Date(2025, 5, 2, 12, 0, 0, 0, time.UTC) },
+	}
+
+	report, err := usecase.Adherence(context.Background(), "1", "", "2025-05-01", "2025-05-31")
+	assert.NoError(t, err)
+	// 今日（5/2）より先は集計しない
+	assert.Equal(t, "2025-05-02", report.EndDate)
+	assert.Len(t, report.Days, 2)
+	assert.Equal(t, 100.0, *report.Days[0].Rate)
+	assert.Equal(t, 7, *report.Days[0].Mental)
+	assert.Equal(t, 0.0, *report.Days[1].Rate)
+	assert.Equal(t, 3, *report.Days[1].Mental)
+	assert.Equal(t, 50.0, *report.Rate)
+
+	// 期間を指定しない場合はユーザーのタイムゾーンでの今日までの30日間
+	p := user.DefaultPreferences()
+	p.Timezone = "America/Los_Angeles"
+	usecase.preferences = &memoryPreferencesRepo{prefs: map[string]user.Preferences{"1": p}}
+	usecase.now = func() time.Time { return time.Date(2025, 5, 2, 3, 0, 0, 0, time.UTC) }
+	report, err = usecase.Adherence(context.Background(), "1", "", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-04-02", report.StartDate)
+	assert.Equal(t, "2025-05-01", report.EndDate)
+}
+
+func TestMedicationReminderUsecase_SendDueReminders(t *testing.T) {
+	medRepo := &mockMedicationRepository{meds: []medication.Medication{
+		{ID: "m1", UserID: "1", Name: "薬A", Schedule: medication.Schedule{Times: []string{"08:00"}}, StartDate: "2025-05-01", RemindersEnabled: true},
+		{ID: "m2", UserID: "2", Name: "薬B", Schedule: medication.Schedule{Times: []string{"08:00"}}, StartDate: "2025-05-01", RemindersEnabled: true},
+		{ID: "m3", UserID: "3", Name: "薬C", Schedule: medication.Schedule{Times: []string{"08:00"}}, StartDate: "2025-04-30", RemindersEnabled: true},
+	}}
+	// ユーザー2は服用済み
+	doseRepo := &mockDoseLogRepository{logs: []medication.DoseLog{
+		{UserID: "2", MedicationID: "m2", Date: "2025-05-01", ScheduledTime: "08:00", Status: medication.DoseTaken},
+	}}
+	// ユーザー1・2は既定のタイムゾーン（Asia/Tokyo）、ユーザー3はロサンゼルス
+	la := user.DefaultPreferences()
+	la.Timezone = "America/Los_Angeles"
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{"3": la}}
+	notifier := &mockNotifier{}
+	usecase := NewMedicationReminderUsecase(medRepo, doseRepo, prefs, notifier)
+
+	// 日本時間の5月1日8:10（ロサンゼルスでは4月30日16:10）
+	to := time.Date(2025, 4, 30, 23, 10, 0, 0, time.UTC)
+	sent, err := usecase.SendDueReminders(context.Background(), to.Add(-15*time.Minute), to)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, notifier.messages, 1)
+	assert.Equal(t, "1", notifier.messages[0].UserID)
+	assert.Equal(t, notification.KindMedicationReminder, notifier.messages[0].Kind)
+
+	// ロサンゼルスの5月1日8:05
+	notifier.messages = nil
+	laTo := time.Date(2025, 5, 1, 15, 5, 0, 0, time.UTC)
+	sent, err = usecase.SendDueReminders(context.Background(), laTo.Add(-15*time.Minute), laTo)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "3", notifier.messages[0].UserID)
+
+	// 通知に失敗しても処理は続け、送信件数には含めない
+	failing := NewMedicationReminderUsecase(medRepo, doseRepo, prefs, &mockNotifier{err: errors.New("送信失敗")})
+	sent, err = failing.SendDueReminders(context.Background(), to.Add(-15*time.Minute), to)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// 通知チャネルの宛先がないユーザーには送らない
+	noRecipient := NewMedicationReminderUsecase(medRepo, doseRepo, prefs, &mockNotifier{err: notification.ErrNoRecipient})
+	sent, err = noRecipient.SendDueReminders(context.Background(), to.Add(-15*time.Minute), to)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
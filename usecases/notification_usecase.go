@@ -0,0 +1,63 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+)
+
+// NotificationUsecase はユーザーが設定で選んだ通知チャネルで通知を配送するNotifier
+type NotificationUsecase struct {
+	preferences user.PreferencesRepository
+	senders     map[notification.Channel]notification.Sender
+}
+
+// NewNotificationUsecase は配送に使えるチャネルのSenderを受け取る（Senderのないチャネルはユーザーが選んでいても送らない）
+func NewNotificationUsecase(preferences user.PreferencesRepository, senders ...notification.Sender) notification.Notifier {
+	byChannel := make(map[notification.Channel]notification.Sender, len(senders))
+	for _, s := range senders {
+		byChannel[s.Channel()] = s
+	}
+	return &NotificationUsecase{preferences: preferences, senders: byChannel}
+}
+
+// Notify は選んだ全てのチャネルで通知を送る。一部のチャネルへの送信に失敗しても、いずれかのチャネルで送れた場合は成功とする。
+// いずれのチャネルにも宛先がない場合はErrNoRecipientを返す
+func (u *NotificationUsecase) Notify(ctx context.Context, msg notification.Message) error {
+	prefs, err := u.preferences.FindByUserID(ctx, msg.UserID)
+	if err != nil {
+		return err
+	}
+	if prefs == nil {
+		defaults := user.DefaultPreferences()
+		prefs = &defaults
+	}
+	delivered := false
+	var errs []error
+	for _, channel := range prefs.NotificationChannels {
+		sender, ok := u.senders[channel]
+		if !ok {
+			continue
+		}
+		err := sender.Send(ctx, msg)
+		if errors.Is(err, notification.ErrNoRecipient) {
+			continue
+		}
+		if err != nil {
+			log.Printf("[ERROR] Notification: 通知の送信に失敗しました user_id=%s channel=%s: %v", msg.UserID, channel, err)
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+			continue
+		}
+		delivered = true
+	}
+	if delivered {
+		return nil
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return notification.ErrNoRecipient
+}
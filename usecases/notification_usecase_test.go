@@ -0,0 +1,70 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubSender struct {
+	channel notification.Channel
+	err     error
+	sent    []notification.Message
+}
+
+func (s *stubSender) Channel() notification.Channel { return s.channel }
+
+func (s *stubSender) Send(ctx context.Context, msg notification.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestNotificationUsecase_SendsToSelectedChannels(t *testing.T) {
+	p := user.DefaultPreferences()
+	p.NotificationChannels = []notification.Channel{notification.ChannelEmail, notification.ChannelWebhook}
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{"u1": p}}
+	push := &stubSender{channel: notification.ChannelWebPush}
+	email := &stubSender{channel: notification.ChannelEmail}
+	webhook := &stubSender{channel: notification.ChannelWebhook, err: errors.New("timeout")}
+	usecase := NewNotificationUsecase(prefs, push, email, webhook)
+
+	// 一部のチャネルへの送信に失敗しても、いずれかに送れれば成功
+	err := usecase.Notify(context.Background(), notification.Message{UserID: "u1"})
+	assert.NoError(t, err)
+	assert.Empty(t, push.sent)
+	assert.Len(t, email.sent, 1)
+}
+
+func TestNotificationUsecase_DefaultsToWebPush(t *testing.T) {
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{}}
+	push := &stubSender{channel: notification.ChannelWebPush}
+	usecase := NewNotificationUsecase(prefs, push)
+
+	assert.NoError(t, usecase.Notify(context.Background(), notification.Message{UserID: "u1"}))
+	assert.Len(t, push.sent, 1)
+}
+
+func TestNotificationUsecase_NoRecipientOrAllFailed(t *testing.T) {
+	p := user.DefaultPreferences()
+	p.NotificationChannels = []notification.Channel{notification.ChannelWebPush, notification.ChannelEmail}
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{"u1": p}}
+
+	noRecipient := NewNotificationUsecase(prefs,
+		&stubSender{channel: notification.ChannelWebPush, err: notification.ErrNoRecipient},
+		&stubSender{channel: notification.ChannelEmail, err: notification.ErrNoRecipient})
+	assert.ErrorIs(t, noRecipient.Notify(context.Background(), notification.Message{UserID: "u1"}), notification.ErrNoRecipient)
+
+	failed := NewNotificationUsecase(prefs,
+		&stubSender{channel: notification.ChannelWebPush, err: notification.ErrNoRecipient},
+		&stubSender{channel: notification.ChannelEmail, err: errors.New("smtp error")})
+	err := failed.Notify(context.Background(), notification.Message{UserID: "u1"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, notification.ErrNoRecipient)
+}
@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"sort"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+)
+
+// OIDCAuthorization は認可リクエストの開始時にクライアントへ返す値
+type OIDCAuthorization struct {
+	// AuthorizationURL はユーザーをリダイレクトさせるプロバイダの認可URL
+	AuthorizationURL string
+	// FlowToken はコールバック時にそのまま送り返してもらうトークン（state・nonce・code_verifierを含む）
+	FlowToken string
+}
+
+type IOIDCLoginUsecase interface {
+	Providers() []string
+	Start(ctx context.Context, providerName string) (*OIDCAuthorization, error)
+	Authenticate(ctx context.Context, providerName, code, state, flowToken string) (*auth.Identity, error)
+	Callback(ctx context.Context, providerName, code, state, flowToken string) (*user.User, error)
+}
+
+type OIDCLoginUsecase struct {
+	providers      map[string]auth.OIDCProvider
+	flowStore      auth.FlowStore
+	userRepository user.Repository
+}
+
+func NewOIDCLoginUsecase(providers []auth.OIDCProvider, flowStore auth.FlowStore, userRepository user.Repository) IOIDCLoginUsecase {
+	m := make(map[string]auth.OIDCProvider, len(providers))
+	for _, p := range providers {
+		m[p.Name()] = p
+	}
+	return &OIDCLoginUsecase{providers: m, flowStore: flowStore, userRepository: userRepository}
+}
+
+// Providers は利用可能なプロバイダ名の一覧を返す
+func (u *OIDCLoginUsecase) Providers() []string {
+	names := make([]string, 0, len(u.providers))
+	for name := range u.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start はstate・nonce・PKCEのcode_verifierを生成し、認可URLとフロー用トークンを返す
+func (u *OIDCLoginUsecase) Start(ctx context.Context, providerName string) (*OIDCAuthorization, error) {
+	provider, ok := u.providers[providerName]
+	if !ok {
+		return nil, auth.ErrUnknownProvider
+	}
+	state, err := auth.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := auth.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := auth.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, auth.CodeChallengeS256(verifier))
+	if err != nil {
+		return nil, err
+	}
+	flowToken, err := u.flowStore.Issue(auth.FlowState{
+		Provider:     providerName,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCAuthorization{AuthorizationURL: authURL, FlowToken: flowToken}, nil
+}
+
+// Authenticate はフロー用トークンとstateを検証し、認可コードを検証済みの外部アカウントに交換する
+func (u *OIDCLoginUsecase) Authenticate(ctx context.Context, providerName, code, state, flowToken string) (*auth.Identity, error) {
+	provider, ok := u.providers[providerName]
+	if !ok {
+		return nil, auth.ErrUnknownProvider
+	}
+	flow, err := u.flowStore.Verify(flowToken)
+	if err != nil {
+		return nil, err
+	}
+	if flow.Provider != providerName || flow.State != state || code == "" {
+		return nil, auth.ErrInvalidFlow
+	}
+	return provider.Exchange(ctx, code, flow.CodeVerifier, flow.Nonce)
+}
+
+// Callback は認可コードを検証済みの外部アカウントに交換し、対応するユーザーを取得または作成する
+func (u *OIDCLoginUsecase) Callback(ctx context.Context, providerName, code, state, flowToken string) (*user.User, error) {
+	identity, err := u.Authenticate(ctx, providerName, code, state, flowToken)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := u.userRepository.FindByProviderId(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	nickname := identity.Name
+	if nickname == "" {
+		nickname = "ユーザー"
+	}
+	created := &user.User{
+		Nickname:   nickname,
+		Provider:   identity.Provider,
+		ProviderID: identity.Subject,
+		IsGuest:    false,
+	}
+	if err := u.userRepository.Create(ctx, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
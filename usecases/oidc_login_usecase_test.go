@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOIDCProvider struct {
+	name        string
+	identity    *auth.Identity
+	exchangeErr error
+	gotVerifier string
+	gotNonce    string
+}
+
+func (p *fakeOIDCProvider) Name() string { return p.name }
+func (p *fakeOIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	q := url.Values{"state": {state}, "nonce": {nonce}, "code_challenge": {codeChallenge}}
+	return "https://idp.example.com/authorize?" + q.Encode(), nil
+}
+func (p *fakeOIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*auth.Identity, error) {
+	p.gotVerifier, p.gotNonce = codeVerifier, nonce
+	return p.identity, p.exchangeErr
+}
+
+// memoryFlowStore はFlowStateをそのままメモリに保持するテスト用のFlowStore
+type memoryFlowStore struct {
+	states map[string]auth.FlowState
+}
+
+func (s *memoryFlowStore) Issue(state auth.FlowState) (string, error) {
+	token := "flow-" + state.State
+	s.states[token] = state
+	return token, nil
+}
+func (s *memoryFlowStore) Verify(token string) (*auth.FlowState, error) {
+	state, ok := s.states[token]
+	if !ok {
+		return nil, auth.ErrInvalidFlow
+	}
+	return &state, nil
+}
+
+type providerUserRepo struct {
+	mockUserRepo
+	users   []*user.User
+	created *user.User
+}
+
+func (m *providerUserRepo) FindByProviderId(ctx context.Context, provider, providerId string) (*user.User, error) {
+	for _, u := range m.users {
+		if u.Provider == provider && u.ProviderID == providerId {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+func (m *providerUserRepo) Create(ctx context.Context, u *user.User) error {
+	u.ID = "new-user"
+	m.created = u
+	return nil
+}
+
+func TestOIDCLoginUsecase_StartAndCallback(t *testing.T) {
+	identity := &auth.Identity{Provider: "google", Subject: "sub-1", Name: "山田"}
+	tests := []struct {
+		name        string
+		users       []*user.User
+		identity    *auth.Identity
+		wantID      string
+		wantCreated bool
+	}{
+		{
+			name:     "正常系: 既存ユーザーでログイン",
+			users:    []*user.User{{ID: "existing", Provider: "google", ProviderID: "sub-1"}},
+			identity: identity,
+			wantID:   "existing",
+		},
+		{
+			name:        "正常系: 初回ログインでユーザー作成",
+			identity:    identity,
+			wantID:      "new-user",
+			wantCreated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeOIDCProvider{name: "google", identity: tt.identity}
+			repo := &providerUserRepo{users: tt.users}
+			uc := NewOIDCLoginUsecase([]auth.OIDCProvider{provider}, &memoryFlowStore{states: map[string]auth.FlowState{}}, repo)
+
+			start, err := uc.Start(context.Background(), "google")
+			require.NoError(t, err)
+			authURL, _ := url.Parse(start.AuthorizationURL)
+			q := authURL.Query()
+
+			u, err := uc.Callback(context.Background(), "google", "code", q.Get("state"), start.FlowToken)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, u.ID)
+			assert.Equal(t, q.Get("nonce"), provider.gotNonce)
+			assert.Equal(t, q.Get("code_challenge"), auth.CodeChallengeS256(provider.gotVerifier))
+			if tt.wantCreated {
+				require.NotNil(t, repo.created)
+				assert.False(t, repo.created.IsGuest)
+				assert.Equal(t, "google", repo.created.Provider)
+				assert.Equal(t, "sub-1", repo.created.ProviderID)
+				assert.Equal(t, "山田", repo.created.Nickname)
+			} else {
+				assert.Nil(t, repo.created)
+			}
+		})
+	}
+}
+
+func TestOIDCLoginUsecase_CallbackErrors(t *testing.T) {
+	newUsecase := func(exchangeErr error) (IOIDCLoginUsecase, *OIDCAuthorization, string) {
+		provider := &fakeOIDCProvider{name: "google", identity: &auth.Identity{Provider: "google", Subject: "s"}, exchangeErr: exchangeErr}
+		line := &fakeOIDCProvider{name: "line"}
+		uc := NewOIDCLoginUsecase([]auth.OIDCProvider{provider, line}, &memoryFlowStore{states: map[string]auth.FlowState{}}, &providerUserRepo{})
+		start, err := uc.Start(context.Background(), "google")
+		require.NoError(t, err)
+		authURL, _ := url.Parse(start.AuthorizationURL)
+		return uc, start, authURL.Query().Get("state")
+	}
+
+	t.Run("異常系: 未対応のプロバイダ", func(t *testing.T) {
+		uc, _, _ := newUsecase(nil)
+		_, err := uc.Start(context.Background(), "facebook")
+		assert.ErrorIs(t, err, auth.ErrUnknownProvider)
+	})
+	t.Run("異常系: stateが一致しない", func(t *testing.T) {
+		uc, start, _ := newUsecase(nil)
+		_, err := uc.Callback(context.Background(), "google", "code", "other-state", start.FlowToken)
+		assert.ErrorIs(t, err, auth.ErrInvalidFlow)
+	})
+	t.Run("異常系: 別プロバイダのフロー", func(t *testing.T) {
+		uc, start, state := newUsecase(nil)
+		_, err := uc.Callback(context.Background(), "line", "code", state, start.FlowToken)
+		assert.ErrorIs(t, err, auth.ErrInvalidFlow)
+	})
+	t.Run("異常系: 不正なフロー用トークン", func(t *testing.T) {
+		uc, _, state := newUsecase(nil)
+		_, err := uc.Callback(context.Background(), "google", "code", state, "forged")
+		assert.ErrorIs(t, err, auth.ErrInvalidFlow)
+	})
+	t.Run("異常系: IDトークンの検証失敗", func(t *testing.T) {
+		uc, start, state := newUsecase(auth.ErrInvalidIDToken)
+		_, err := uc.Callback(context.Background(), "google", "code", state, start.FlowToken)
+		assert.True(t, errors.Is(err, auth.ErrInvalidIDToken))
+	})
+	t.Run("正常系: プロバイダ一覧", func(t *testing.T) {
+		uc, _, _ := newUsecase(nil)
+		assert.Equal(t, []string{"google", "line"}, uc.Providers())
+	})
+}
@@ -0,0 +1,229 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+)
+
+var (
+	// ErrPasskeyRequiresAccount はゲストユーザーがパスキーを登録しようとした場合のエラー
+	ErrPasskeyRequiresAccount = errors.New("パスキーはアカウントを連携したユーザーのみ登録できます")
+	ErrInvalidPasskeyName     = errors.New("パスキーの名前は100文字以内で入力してください")
+)
+
+const (
+	// DefaultPasskeyChallengeTTL は登録・ログインの開始から完了までの有効期限
+	DefaultPasskeyChallengeTTL = 5 * time.Minute
+
+	defaultPasskeyName    = "パスキー"
+	maxPasskeyNameLength  = 100
+	passkeyChallengeBytes = 32
+)
+
+// PasskeyRegistrationOptions は登録の開始時に認証器へ渡す内容
+type PasskeyRegistrationOptions struct {
+	ChallengeID  string
+	Challenge    []byte
+	RelyingParty auth.RelyingParty
+	// UserHandle は認証器に保存するユーザーの識別子（ユーザーIDを使い、個人情報は含めない）
+	UserHandle      []byte
+	UserName        string
+	UserDisplayName string
+	// ExcludeCredentials は登録済みのパスキー（同じ認証器を二重に登録させない）
+	ExcludeCredentials []*auth.Passkey
+	Timeout            time.Duration
+}
+
+// PasskeyLoginOptions はログインの開始時に認証器へ渡す内容（ユーザーを指定しないdiscoverable credentialでのログイン）
+type PasskeyLoginOptions struct {
+	ChallengeID string
+	Challenge   []byte
+	RPID        string
+	Timeout     time.Duration
+}
+
+type IPasskeyUsecase interface {
+	BeginRegistration(ctx context.Context, userID string) (*PasskeyRegistrationOptions, error)
+	FinishRegistration(ctx context.Context, userID, challengeID, name string, attestation *auth.PasskeyAttestation) (*auth.Passkey, error)
+	BeginLogin(ctx context.Context) (*PasskeyLoginOptions, error)
+	// FinishLogin は署名を検証してログインしたユーザーを返す
+	FinishLogin(ctx context.Context, challengeID string, assertion *auth.PasskeyAssertion) (*user.User, error)
+	List(ctx context.Context, userID string) ([]*auth.Passkey, error)
+	Rename(ctx context.Context, userID, id, name string) error
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type PasskeyUsecase struct {
+	passkeyRepository   auth.PasskeyRepository
+	challengeRepository auth.PasskeyChallengeRepository
+	userRepository      user.Repository
+	verifier            auth.PasskeyVerifier
+	challengeTTL        time.Duration
+	now                 func() time.Time
+}
+
+func NewPasskeyUsecase(passkeyRepository auth.PasskeyRepository, challengeRepository auth.PasskeyChallengeRepository, userRepository user.Repository, verifier auth.PasskeyVerifier, challengeTTL time.Duration) IPasskeyUsecase {
+	return &PasskeyUsecase{
+		passkeyRepository:   passkeyRepository,
+		challengeRepository: challengeRepository,
+		userRepository:      userRepository,
+		verifier:            verifier,
+		challengeTTL:        challengeTTL,
+		now:                 time.Now,
+	}
+}
+
+func (u *PasskeyUsecase) issueChallenge(ctx context.Context, ceremony auth.PasskeyCeremony, userID string) (*auth.PasskeyChallenge, error) {
+	raw := make([]byte, passkeyChallengeBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	challenge := &auth.PasskeyChallenge{Ceremony: ceremony, UserID: userID, Challenge: raw, ExpiresAt: u.now().Add(u.challengeTTL)}
+	if err := u.challengeRepository.Create(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (u *PasskeyUsecase) BeginRegistration(ctx context.Context, userID string) (*PasskeyRegistrationOptions, error) {
+	account, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrUserNotFound
+	}
+	if account.IsGuest {
+		return nil, ErrPasskeyRequiresAccount
+	}
+	existing, err := u.passkeyRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := u.issueChallenge(ctx, auth.PasskeyRegistration, userID)
+	if err != nil {
+		return nil, err
+	}
+	name := account.Email
+	if name == "" {
+		name = account.Nickname
+	}
+	return &PasskeyRegistrationOptions{
+		ChallengeID:        challenge.ID,
+		Challenge:          challenge.Challenge,
+		RelyingParty:       u.verifier.RelyingParty(),
+		UserHandle:         []byte(account.ID),
+		UserName:           name,
+		UserDisplayName:    account.Nickname,
+		ExcludeCredentials: existing,
+		Timeout:            u.challengeTTL,
+	}, nil
+}
+
+func (u *PasskeyUsecase) FinishRegistration(ctx context.Context, userID, challengeID, name string, attestation *auth.PasskeyAttestation) (*auth.Passkey, error) {
+	name, err := normalizePasskeyName(name)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := u.challengeRepository.Consume(ctx, challengeID, auth.PasskeyRegistration, u.now())
+	if err != nil {
+		return nil, err
+	}
+	if challenge.UserID != userID {
+		return nil, auth.ErrInvalidPasskeyChallenge
+	}
+	credential, err := u.verifier.VerifyRegistration(challenge.Challenge, attestation)
+	if err != nil {
+		return nil, err
+	}
+	passkey := &auth.Passkey{
+		UserID:       userID,
+		Name:         name,
+		CredentialID: credential.CredentialID,
+		PublicKey:    credential.PublicKey,
+		Algorithm:    credential.Algorithm,
+		SignCount:    credential.SignCount,
+		Transports:   attestation.Transports,
+	}
+	if err := u.passkeyRepository.Create(ctx, passkey); err != nil {
+		return nil, err
+	}
+	return passkey, nil
+}
+
+func (u *PasskeyUsecase) BeginLogin(ctx context.Context) (*PasskeyLoginOptions, error) {
+	challenge, err := u.issueChallenge(ctx, auth.PasskeyLogin, "")
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyLoginOptions{
+		ChallengeID: challenge.ID,
+		Challenge:   challenge.Challenge,
+		RPID:        u.verifier.RelyingParty().ID,
+		Timeout:     u.challengeTTL,
+	}, nil
+}
+
+func (u *PasskeyUsecase) FinishLogin(ctx context.Context, challengeID string, assertion *auth.PasskeyAssertion) (*user.User, error) {
+	now := u.now()
+	challenge, err := u.challengeRepository.Consume(ctx, challengeID, auth.PasskeyLogin, now)
+	if err != nil {
+		return nil, err
+	}
+	passkey, err := u.passkeyRepository.FindByCredentialID(ctx, assertion.CredentialID)
+	if errors.Is(err, auth.ErrPasskeyNotFound) {
+		// 登録されていないパスキーかどうかを区別させない
+		return nil, auth.ErrInvalidPasskey
+	}
+	if err != nil {
+		return nil, err
+	}
+	signCount, err := u.verifier.VerifyAssertion(challenge.Challenge, passkey, assertion)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.passkeyRepository.UpdateSignCount(ctx, passkey.ID, signCount, now); err != nil {
+		return nil, err
+	}
+	account, err := u.userRepository.FindByID(ctx, passkey.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, auth.ErrInvalidPasskey
+	}
+	return account, nil
+}
+
+func (u *PasskeyUsecase) List(ctx context.Context, userID string) ([]*auth.Passkey, error) {
+	return u.passkeyRepository.ListByUserID(ctx, userID)
+}
+
+func (u *PasskeyUsecase) Rename(ctx context.Context, userID, id, name string) error {
+	name, err := normalizePasskeyName(name)
+	if err != nil {
+		return err
+	}
+	return u.passkeyRepository.Rename(ctx, userID, id, name)
+}
+
+func (u *PasskeyUsecase) Delete(ctx context.Context, userID, id string) error {
+	return u.passkeyRepository.Delete(ctx, userID, id)
+}
+
+// normalizePasskeyName は前後の空白を除き、空の場合は既定の名前にする
+func normalizePasskeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return defaultPasskeyName, nil
+	}
+	if len([]rune(name)) > maxPasskeyNameLength {
+		return "", ErrInvalidPasskeyName
+	}
+	return name, nil
+}
@@ -0,0 +1,264 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+	"tofunote-backend/infra/webauthn"
+	"tofunote-backend/infra/webauthn/webauthntest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryPasskeyRepo struct {
+	passkeys []*auth.Passkey
+}
+
+func (m *memoryPasskeyRepo) Create(ctx context.Context, passkey *auth.Passkey) error {
+	for _, p := range m.passkeys {
+		if bytes.Equal(p.CredentialID, passkey.CredentialID) {
+			return auth.ErrPasskeyAlreadyRegistered
+		}
+	}
+	passkey.ID = "passkey-" + string(rune('a'+len(m.passkeys)))
+	m.passkeys = append(m.passkeys, passkey)
+	return nil
+}
+func (m *memoryPasskeyRepo) FindByCredentialID(ctx context.Context, credentialID []byte) (*auth.Passkey, error) {
+	for _, p := range m.passkeys {
+		if bytes.Equal(p.CredentialID, credentialID) {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, auth.ErrPasskeyNotFound
+}
+func (m *memoryPasskeyRepo) ListByUserID(ctx context.Context, userID string) ([]*auth.Passkey, error) {
+	var list []*auth.Passkey
+	for _, p := range m.passkeys {
+		if p.UserID == userID {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+func (m *memoryPasskeyRepo) UpdateSignCount(ctx context.Context, id string, signCount uint32, now time.Time) error {
+	for _, p := range m.passkeys {
+		if p.ID == id {
+			p.SignCount = signCount
+			p.LastUsedAt = &now
+		}
+	}
+	return nil
+}
+func (m *memoryPasskeyRepo) Rename(ctx context.Context, userID, id, name string) error {
+	for _, p := range m.passkeys {
+		if p.ID == id && p.UserID == userID {
+			p.Name = name
+			return nil
+		}
+	}
+	return auth.ErrPasskeyNotFound
+}
+func (m *memoryPasskeyRepo) Delete(ctx context.Context, userID, id string) error {
+	for i, p := range m.passkeys {
+		if p.ID == id && p.UserID == userID {
+			m.passkeys = append(m.passkeys[:i], m.passkeys[i+1:]...)
+			return nil
+		}
+	}
+	return auth.ErrPasskeyNotFound
+}
+func (m *memoryPasskeyRepo) DeleteByUserID(ctx context.Context, userID string) error { return nil }
+
+type memoryPasskeyChallengeRepo struct {
+	challenges map[string]*auth.PasskeyChallenge
+}
+
+func (m *memoryPasskeyChallengeRepo) Create(ctx context.Context, challenge *auth.PasskeyChallenge) error {
+	challenge.ID = "challenge-" + string(rune('a'+len(m.challenges)))
+	m.challenges[challenge.ID] = challenge
+	return nil
+}
+func (m *memoryPasskeyChallengeRepo) Consume(ctx context.Context, id string, ceremony auth.PasskeyCeremony, now time.Time) (*auth.PasskeyChallenge, error) {
+	c, ok := m.challenges[id]
+	if !ok || c.Ceremony != ceremony || c.UsedAt != nil || !now.Before(c.ExpiresAt) {
+		return nil, auth.ErrInvalidPasskeyChallenge
+	}
+	c.UsedAt = &now
+	return c, nil
+}
+func (m *memoryPasskeyChallengeRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	return nil
+}
+
+const (
+	passkeyTestRPID   = "tofunote.example.com"
+	passkeyTestOrigin = "https://tofunote.example.com"
+)
+
+func newPasskeyTestUsecase() (*PasskeyUsecase, *memoryPasskeyRepo) {
+	passkeys := &memoryPasskeyRepo{}
+	users := &memoryUserRepo{users: map[string]*user.User{
+		"member": {ID: "member", Nickname: "たこ", Email: "tako@example.com"},
+		"guest":  {ID: "guest", Nickname: "ゲスト", IsGuest: true},
+	}}
+	verifier := webauthn.NewVerifier(webauthn.Config{RPID: passkeyTestRPID, RPName: "Tofunote", Origins: []string{passkeyTestOrigin}, RequireUserVerification: true})
+	uc := NewPasskeyUsecase(passkeys, &memoryPasskeyChallengeRepo{challenges: map[string]*auth.PasskeyChallenge{}}, users, verifier, DefaultPasskeyChallengeTTL).(*PasskeyUsecase)
+	return uc, passkeys
+}
+
+// registerPasskey はソフトウェア認証器でパスキーを登録する
+func registerPasskey(t *testing.T, uc *PasskeyUsecase, userID string) *webauthntest.Authenticator {
+	t.Helper()
+	ctx := context.Background()
+	authenticator, err := webauthntest.New(passkeyTestRPID, passkeyTestOrigin)
+	require.NoError(t, err)
+	options, err := uc.BeginRegistration(ctx, userID)
+	require.NoError(t, err)
+	attestation, err := authenticator.Register(options.Challenge, options.UserHandle)
+	require.NoError(t, err)
+	_, err = uc.FinishRegistration(ctx, userID, options.ChallengeID, "", attestation)
+	require.NoError(t, err)
+	return authenticator
+}
+
+func TestPasskeyUsecase_RegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	uc, passkeys := newPasskeyTestUsecase()
+	authenticator := registerPasskey(t, uc, "member")
+
+	require.Len(t, passkeys.passkeys, 1)
+	assert.Equal(t, "パスキー", passkeys.passkeys[0].Name)
+	assert.Equal(t, []string{"internal"}, passkeys.passkeys[0].Transports)
+
+	options, err := uc.BeginRegistration(ctx, "member")
+	require.NoError(t, err)
+	assert.Len(t, options.ExcludeCredentials, 1, "登録済みのパスキーを除外対象として返す")
+	assert.Equal(t, "tako@example.com", options.UserName)
+
+	for i := 1; i <= 2; i++ {
+		login, err := uc.BeginLogin(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, passkeyTestRPID, login.RPID)
+		assertion, err := authenticator.Login(login.Challenge)
+		require.NoError(t, err)
+		u, err := uc.FinishLogin(ctx, login.ChallengeID, assertion)
+		require.NoError(t, err)
+		assert.Equal(t, "member", u.ID)
+		assert.Equal(t, uint32(i), passkeys.passkeys[0].SignCount)
+		assert.NotNil(t, passkeys.passkeys[0].LastUsedAt)
+	}
+}
+
+func TestPasskeyUsecase_LoginRejected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("異常系: 同じチャレンジは2回使えない", func(t *testing.T) {
+		uc, _ := newPasskeyTestUsecase()
+		authenticator := registerPasskey(t, uc, "member")
+		login, err := uc.BeginLogin(ctx)
+		require.NoError(t, err)
+		assertion, err := authenticator.Login(login.Challenge)
+		require.NoError(t, err)
+		_, err = uc.FinishLogin(ctx, login.ChallengeID, assertion)
+		require.NoError(t, err)
+		_, err = uc.FinishLogin(ctx, login.ChallengeID, assertion)
+		assert.ErrorIs(t, err, auth.ErrInvalidPasskeyChallenge)
+	})
+
+	t.Run("異常系: 期限切れのチャレンジ", func(t *testing.T) {
+		uc, _ := newPasskeyTestUsecase()
+		authenticator := registerPasskey(t, uc, "member")
+		login, err := uc.BeginLogin(ctx)
+		require.NoError(t, err)
+		uc.now = func() time.Time { return time.Now().Add(DefaultPasskeyChallengeTTL + time.Second) }
+		assertion, err := authenticator.Login(login.Challenge)
+		require.NoError(t, err)
+		_, err = uc.FinishLogin(ctx, login.ChallengeID, assertion)
+		assert.ErrorIs(t, err, auth.ErrInvalidPasskeyChallenge)
+	})
+
+	t.Run("異常系: 未登録の認証器", func(t *testing.T) {
+		uc, _ := newPasskeyTestUsecase()
+		unknown, err := webauthntest.New(passkeyTestRPID, passkeyTestOrigin)
+		require.NoError(t, err)
+		login, err := uc.BeginLogin(ctx)
+		require.NoError(t, err)
+		assertion, err := unknown.Login(login.Challenge)
+		require.NoError(t, err)
+		_, err = uc.FinishLogin(ctx, login.ChallengeID, assertion)
+		assert.ErrorIs(t, err, auth.ErrInvalidPasskey)
+	})
+
+	t.Run("異常系: 複製された認証器（署名カウンタの巻き戻り）", func(t *testing.T) {
+		uc, _ := newPasskeyTestUsecase()
+		authenticator := registerPasskey(t, uc, "member")
+		cloned := *authenticator
+		for _, a := range []*webauthntest.Authenticator{authenticator, &cloned} {
+			login, err := uc.BeginLogin(ctx)
+			require.NoError(t, err)
+			assertion, err := a.Login(login.Challenge)
+			require.NoError(t, err)
+			_, err = uc.FinishLogin(ctx, login.ChallengeID, assertion)
+			if a == authenticator {
+				require.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, auth.ErrPasskeySignCount)
+			}
+		}
+	})
+}
+
+func TestPasskeyUsecase_RegistrationRejected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("異常系: ゲストは登録できない", func(t *testing.T) {
+		uc, _ := newPasskeyTestUsecase()
+		_, err := uc.BeginRegistration(ctx, "guest")
+		assert.ErrorIs(t, err, ErrPasskeyRequiresAccount)
+	})
+
+	t.Run("異常系: 他のユーザーのチャレンジでは登録できない", func(t *testing.T) {
+		uc, _ := newPasskeyTestUsecase()
+		uc.userRepository.(*memoryUserRepo).users["other"] = &user.User{ID: "other", Nickname: "other"}
+		options, err := uc.BeginRegistration(ctx, "member")
+		require.NoError(t, err)
+		authenticator, err := webauthntest.New(passkeyTestRPID, passkeyTestOrigin)
+		require.NoError(t, err)
+		attestation, err := authenticator.Register(options.Challenge, options.UserHandle)
+		require.NoError(t, err)
+		_, err = uc.FinishRegistration(ctx, "other", options.ChallengeID, "", attestation)
+		assert.ErrorIs(t, err, auth.ErrInvalidPasskeyChallenge)
+	})
+
+	t.Run("異常系: 長すぎる名前", func(t *testing.T) {
+		uc, _ := newPasskeyTestUsecase()
+		_, err := uc.FinishRegistration(ctx, "member", "challenge-a", strings.Repeat("あ", 101), &auth.PasskeyAttestation{})
+		assert.ErrorIs(t, err, ErrInvalidPasskeyName)
+	})
+}
+
+func TestPasskeyUsecase_Manage(t *testing.T) {
+	ctx := context.Background()
+	uc, passkeys := newPasskeyTestUsecase()
+	registerPasskey(t, uc, "member")
+	id := passkeys.passkeys[0].ID
+
+	require.NoError(t, uc.Rename(ctx, "member", id, "  MacBook  "))
+	list, err := uc.List(ctx, "member")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "MacBook", list[0].Name)
+
+	assert.ErrorIs(t, uc.Delete(ctx, "guest", id), auth.ErrPasskeyNotFound)
+	require.NoError(t, uc.Delete(ctx, "member", id))
+	list, err = uc.List(ctx, "member")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
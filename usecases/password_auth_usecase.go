@@ -0,0 +1,307 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+)
+
+var (
+	ErrEmailAlreadyRegistered = errors.New("このメールアドレスは既に登録されています")
+	ErrEmailAlreadyVerified   = errors.New("メールアドレスは確認済みです")
+	ErrNoEmail                = errors.New("メールアドレスが登録されていません")
+)
+
+// PasswordAuthConfig はメールに記載するURLとトークンの有効期限
+type PasswordAuthConfig struct {
+	// VerifyEmailURL はメールアドレス確認画面のURL（tokenクエリを付けて送る）
+	VerifyEmailURL string
+	// ResetPasswordURL はパスワード再設定画面のURL（tokenクエリを付けて送る）
+	ResetPasswordURL string
+	VerificationTTL  time.Duration
+	ResetTTL         time.Duration
+}
+
+// DefaultPasswordAuthConfig はトークンの有効期限の既定値（確認は24時間、再設定は1時間）
+func DefaultPasswordAuthConfig(frontendURL string) PasswordAuthConfig {
+	return PasswordAuthConfig{
+		VerifyEmailURL:   frontendURL + "/verify-email",
+		ResetPasswordURL: frontendURL + "/reset-password",
+		VerificationTTL:  24 * time.Hour,
+		ResetTTL:         time.Hour,
+	}
+}
+
+type IPasswordAuthUsecase interface {
+	Register(ctx context.Context, email, password, nickname string) (*user.User, error)
+	Login(ctx context.Context, email, password string) (*user.User, error)
+	// SetCredentials は既存ユーザー（ゲストの連携時等）にメールアドレスとパスワードを設定し、確認メールを送る
+	SetCredentials(ctx context.Context, u *user.User, email, password string) error
+	SendVerification(ctx context.Context, userID string) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type PasswordAuthUsecase struct {
+	userRepository  user.Repository
+	tokenRepository auth.OneTimeTokenRepository
+	sessions        ISessionUsecase
+	hasher          auth.PasswordHasher
+	mailer          notification.Mailer
+	config          PasswordAuthConfig
+	audits          IAuditUsecase
+	now             func() time.Time
+}
+
+func NewPasswordAuthUsecase(userRepository user.Repository, tokenRepository auth.OneTimeTokenRepository, sessions ISessionUsecase, hasher auth.PasswordHasher, mailer notification.Mailer, config PasswordAuthConfig, audits IAuditUsecase) IPasswordAuthUsecase {
+	return &PasswordAuthUsecase{
+		userRepository:  userRepository,
+		tokenRepository: tokenRepository,
+		sessions:        sessions,
+		hasher:          hasher,
+		mailer:          mailer,
+		config:          config,
+		audits:          audits,
+		now:             time.Now,
+	}
+}
+
+// Register はメールアドレスとパスワードで新しいユーザーを作成し、確認メールを送る
+func (u *PasswordAuthUsecase) Register(ctx context.Context, email, password, nickname string) (*user.User, error) {
+	if nickname == "" {
+		nickname = "ユーザー"
+	}
+	created := &user.User{Nickname: nickname, IsGuest: false}
+	if err := u.setCredentials(ctx, created, email, password); err != nil {
+		return nil, err
+	}
+	if err := u.userRepository.Create(ctx, created); err != nil {
+		return nil, err
+	}
+	if err := u.sendVerification(ctx, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (u *PasswordAuthUsecase) SetCredentials(ctx context.Context, target *user.User, email, password string) error {
+	if err := u.setCredentials(ctx, target, email, password); err != nil {
+		return err
+	}
+	target.IsGuest = false
+	if err := u.userRepository.Update(ctx, target); err != nil {
+		return err
+	}
+	return u.sendVerification(ctx, target)
+}
+
+func (u *PasswordAuthUsecase) setCredentials(ctx context.Context, target *user.User, email, password string) error {
+	normalized, err := user.NormalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	if err := auth.ValidatePassword(password); err != nil {
+		return err
+	}
+	existing, err := u.userRepository.FindByEmail(ctx, normalized)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != target.ID {
+		return ErrEmailAlreadyRegistered
+	}
+	hash, err := u.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if target.Email != normalized {
+		target.EmailVerifiedAt = nil
+	}
+	target.Email = normalized
+	target.PasswordHash = hash
+	return nil
+}
+
+// Login はメールアドレスとパスワードを照合する（ユーザーの有無が応答から分からないよう、失敗理由は区別しない）
+func (u *PasswordAuthUsecase) Login(ctx context.Context, email, password string) (*user.User, error) {
+	normalized, err := user.NormalizeEmail(email)
+	if err != nil {
+		return nil, auth.ErrInvalidCredentials
+	}
+	found, err := u.userRepository.FindByEmail(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil || !found.HasPassword() {
+		// 存在しないユーザーでも同程度の時間がかかるようにハッシュ計算を行う
+		_, _ = u.hasher.Hash(password)
+		recordAudit(ctx, u.audits, audit.Event{Action: audit.ActionLoginFailed, Detail: "password"})
+		return nil, auth.ErrInvalidCredentials
+	}
+	ok, needsRehash, err := u.hasher.Verify(password, found.PasswordHash)
+	if err != nil || !ok {
+		recordAudit(ctx, u.audits, audit.Event{Action: audit.ActionLoginFailed, TargetUserID: found.ID, Detail: "password"})
+		return nil, auth.ErrInvalidCredentials
+	}
+	if needsRehash {
+		if hash, err := u.hasher.Hash(password); err == nil {
+			found.PasswordHash = hash
+			if err := u.userRepository.Update(ctx, found); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return found, nil
+}
+
+// SendVerification は確認メールを再送する（以前に送ったトークンは無効になる）
+func (u *PasswordAuthUsecase) SendVerification(ctx context.Context, userID string) error {
+	found, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if found == nil {
+		return ErrUserNotFound
+	}
+	if found.Email == "" {
+		return ErrNoEmail
+	}
+	if found.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	return u.sendVerification(ctx, found)
+}
+
+func (u *PasswordAuthUsecase) sendVerification(ctx context.Context, target *user.User) error {
+	raw, err := u.issueToken(ctx, target.ID, auth.PurposeVerifyEmail, u.config.VerificationTTL)
+	if err != nil {
+		return err
+	}
+	return u.mailer.Send(ctx, notification.Mail{
+		To:      target.Email,
+		Subject: "【TOFU NOTE】メールアドレスの確認",
+		Body: fmt.Sprintf("TOFU NOTEへのご登録ありがとうございます。\n\n以下のURLを開いて、メールアドレスの確認を完了してください（有効期限: %s）。\n\n%s\n\nお心当たりがない場合は、このメールを破棄してください。\n",
+			formatTTL(u.config.VerificationTTL), withToken(u.config.VerifyEmailURL, raw)),
+	})
+}
+
+// VerifyEmail は確認メールのトークンを検証してメールアドレスを確認済みにする
+func (u *PasswordAuthUsecase) VerifyEmail(ctx context.Context, token string) error {
+	consumed, err := u.tokenRepository.Consume(ctx, auth.PurposeVerifyEmail, auth.HashToken(token), u.now())
+	if err != nil {
+		return err
+	}
+	found, err := u.userRepository.FindByID(ctx, consumed.UserID)
+	if err != nil {
+		return err
+	}
+	if found == nil {
+		return auth.ErrInvalidOneTimeToken
+	}
+	verifiedAt := u.now()
+	found.EmailVerifiedAt = &verifiedAt
+	return u.userRepository.Update(ctx, found)
+}
+
+// RequestPasswordReset はパスワード再設定メールを送る（登録の有無が分からないよう、未登録でもエラーにしない）
+func (u *PasswordAuthUsecase) RequestPasswordReset(ctx context.Context, email string) error {
+	normalized, err := user.NormalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	found, err := u.userRepository.FindByEmail(ctx, normalized)
+	if err != nil {
+		return err
+	}
+	if found == nil || !found.HasPassword() {
+		return nil
+	}
+	raw, err := u.issueToken(ctx, found.ID, auth.PurposeResetPassword, u.config.ResetTTL)
+	if err != nil {
+		return err
+	}
+	return u.mailer.Send(ctx, notification.Mail{
+		To:      found.Email,
+		Subject: "【TOFU NOTE】パスワードの再設定",
+		Body: fmt.Sprintf("パスワード再設定のリクエストを受け付けました。\n\n以下のURLを開いて、新しいパスワードを設定してください（有効期限: %s）。\n\n%s\n\nお心当たりがない場合は、このメールを破棄してください。パスワードは変更されません。\n",
+			formatTTL(u.config.ResetTTL), withToken(u.config.ResetPasswordURL, raw)),
+	})
+}
+
+// ResetPassword は再設定メールのトークンを検証して新しいパスワードを設定する。
+// 再設定後は全てのセッションを失効させ、他の端末のログインを解除する
+func (u *PasswordAuthUsecase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := auth.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+	consumed, err := u.tokenRepository.Consume(ctx, auth.PurposeResetPassword, auth.HashToken(token), u.now())
+	if err != nil {
+		return err
+	}
+	found, err := u.userRepository.FindByID(ctx, consumed.UserID)
+	if err != nil {
+		return err
+	}
+	if found == nil {
+		return auth.ErrInvalidOneTimeToken
+	}
+	hash, err := u.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	found.PasswordHash = hash
+	// 再設定メールを受け取れたことはメールアドレスの確認にもなる
+	if !found.IsEmailVerified() {
+		verifiedAt := u.now()
+		found.EmailVerifiedAt = &verifiedAt
+	}
+	if err := u.userRepository.Update(ctx, found); err != nil {
+		return err
+	}
+	return u.sessions.RevokeAll(ctx, found.ID)
+}
+
+func (u *PasswordAuthUsecase) issueToken(ctx context.Context, userID string, purpose auth.TokenPurpose, ttl time.Duration) (string, error) {
+	now := u.now()
+	if err := u.tokenRepository.InvalidateByUserID(ctx, userID, purpose, now); err != nil {
+		return "", err
+	}
+	raw, err := auth.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := u.tokenRepository.Create(ctx, &auth.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: auth.HashToken(raw),
+		ExpiresAt: now.Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func withToken(baseURL, token string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return baseURL + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d時間", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%d分", int(d/time.Minute))
+}
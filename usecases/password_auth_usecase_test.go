@@ -0,0 +1,238 @@
+package usecases
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/notification"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainHasher はテスト用にハッシュ計算を省略したPasswordHasher
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) (string, error) { return "plain:" + password, nil }
+func (plainHasher) Verify(password, encoded string) (bool, bool, error) {
+	return encoded == "plain:"+password, false, nil
+}
+
+type memoryOneTimeTokenRepo struct {
+	tokens []*auth.OneTimeToken
+}
+
+func (r *memoryOneTimeTokenRepo) Create(ctx context.Context, token *auth.OneTimeToken) error {
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+func (r *memoryOneTimeTokenRepo) Consume(ctx context.Context, purpose auth.TokenPurpose, tokenHash string, now time.Time) (*auth.OneTimeToken, error) {
+	for _, t := range r.tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash && t.UsedAt == nil && now.Before(t.ExpiresAt) {
+			t.UsedAt = &now
+			return t, nil
+		}
+	}
+	return nil, auth.ErrInvalidOneTimeToken
+}
+func (r *memoryOneTimeTokenRepo) InvalidateByUserID(ctx context.Context, userID string, purpose auth.TokenPurpose, now time.Time) error {
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+func (r *memoryOneTimeTokenRepo) DeleteByUserID(ctx context.Context, userID string) error { return nil }
+
+type memoryMailer struct {
+	sent []notification.Mail
+}
+
+func (m *memoryMailer) Send(ctx context.Context, mail notification.Mail) error {
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+// lastToken は最後に送ったメールのURLからトークンを取り出す
+func (m *memoryMailer) lastToken(t *testing.T) string {
+	require.NotEmpty(t, m.sent)
+	link := regexp.MustCompile(`https://\S+`).FindString(m.sent[len(m.sent)-1].Body)
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+type emailUserRepo struct {
+	memoryUserRepo
+}
+
+func (m *emailUserRepo) Create(ctx context.Context, u *user.User) error {
+	if u.ID == "" {
+		u.ID = "user-" + strings.Split(u.Email, "@")[0]
+	}
+	m.users[u.ID] = u
+	return nil
+}
+func (m *emailUserRepo) FindByEmail(ctx context.Context, email string) (*user.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func newPasswordAuthTestUsecase() (*PasswordAuthUsecase, *emailUserRepo, *memoryMailer) {
+	repo := &emailUserRepo{memoryUserRepo{users: map[string]*user.User{}}}
+	mailer := &memoryMailer{}
+	refreshTokens := newMemoryRefreshTokenRepo()
+	sessions := NewSessionUsecase(newMemorySessionRepo(), refreshTokens, 24*time.Hour, time.Minute)
+	uc := NewPasswordAuthUsecase(repo, &memoryOneTimeTokenRepo{}, sessions, plainHasher{}, mailer, DefaultPasswordAuthConfig("https://app.example.com"), nil).(*PasswordAuthUsecase)
+	return uc, repo, mailer
+}
+
+func TestPasswordAuthUsecase_RegisterAndLogin(t *testing.T) {
+	uc, repo, mailer := newPasswordAuthTestUsecase()
+	ctx := context.Background()
+
+	created, err := uc.Register(ctx, " Taro@Example.com ", "correct-horse", "")
+	require.NoError(t, err)
+	assert.Equal(t, "taro@example.com", created.Email)
+	assert.Equal(t, "ユーザー", created.Nickname)
+	assert.False(t, created.IsEmailVerified())
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, "taro@example.com", mailer.sent[0].To)
+	assert.Contains(t, mailer.sent[0].Body, "https://app.example.com/verify-email?token=")
+
+	t.Run("異常系: 登録済みのメールアドレス", func(t *testing.T) {
+		_, err := uc.Register(ctx, "taro@example.com", "another-pass", "")
+		assert.ErrorIs(t, err, ErrEmailAlreadyRegistered)
+	})
+	t.Run("異常系: 短いパスワード", func(t *testing.T) {
+		_, err := uc.Register(ctx, "jiro@example.com", "short", "")
+		assert.ErrorIs(t, err, auth.ErrInvalidPassword)
+	})
+	t.Run("異常系: 不正なメールアドレス", func(t *testing.T) {
+		_, err := uc.Register(ctx, "not-an-email", "correct-horse", "")
+		assert.ErrorIs(t, err, user.ErrInvalidEmail)
+	})
+	t.Run("正常系: 大文字小文字を区別せずログイン", func(t *testing.T) {
+		u, err := uc.Login(ctx, "TARO@example.com", "correct-horse")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, u.ID)
+	})
+	t.Run("異常系: パスワード違いと未登録は同じエラー", func(t *testing.T) {
+		_, err := uc.Login(ctx, "taro@example.com", "wrong-password")
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+		_, err = uc.Login(ctx, "nobody@example.com", "correct-horse")
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	})
+	assert.Len(t, repo.users, 1)
+}
+
+func TestPasswordAuthUsecase_VerifyEmail(t *testing.T) {
+	uc, repo, mailer := newPasswordAuthTestUsecase()
+	ctx := context.Background()
+	created, err := uc.Register(ctx, "taro@example.com", "correct-horse", "太郎")
+	require.NoError(t, err)
+	first := mailer.lastToken(t)
+
+	// 再送すると以前のトークンは使えなくなる
+	require.NoError(t, uc.SendVerification(ctx, created.ID))
+	second := mailer.lastToken(t)
+	assert.ErrorIs(t, uc.VerifyEmail(ctx, first), auth.ErrInvalidOneTimeToken)
+
+	require.NoError(t, uc.VerifyEmail(ctx, second))
+	assert.True(t, repo.users[created.ID].IsEmailVerified())
+
+	t.Run("異常系: トークンは一度しか使えない", func(t *testing.T) {
+		assert.ErrorIs(t, uc.VerifyEmail(ctx, second), auth.ErrInvalidOneTimeToken)
+	})
+	t.Run("異常系: 確認済みなら再送しない", func(t *testing.T) {
+		assert.ErrorIs(t, uc.SendVerification(ctx, created.ID), ErrEmailAlreadyVerified)
+	})
+}
+
+func TestPasswordAuthUsecase_VerifyEmail_Expired(t *testing.T) {
+	uc, _, mailer := newPasswordAuthTestUsecase()
+	ctx := context.Background()
+	_, err := uc.Register(ctx, "taro@example.com", "correct-horse", "")
+	require.NoError(t, err)
+	uc.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	assert.ErrorIs(t, uc.VerifyEmail(ctx, mailer.lastToken(t)), auth.ErrInvalidOneTimeToken)
+}
+
+func TestPasswordAuthUsecase_ResetPassword(t *testing.T) {
+	uc, repo, mailer := newPasswordAuthTestUsecase()
+	ctx := context.Background()
+	created, err := uc.Register(ctx, "taro@example.com", "correct-horse", "")
+	require.NoError(t, err)
+
+	t.Run("正常系: 未登録のメールアドレスでもエラーにせずメールも送らない", func(t *testing.T) {
+		before := len(mailer.sent)
+		require.NoError(t, uc.RequestPasswordReset(ctx, "nobody@example.com"))
+		assert.Len(t, mailer.sent, before)
+	})
+
+	require.NoError(t, uc.RequestPasswordReset(ctx, "taro@example.com"))
+	token := mailer.lastToken(t)
+	assert.Contains(t, mailer.sent[len(mailer.sent)-1].Body, "https://app.example.com/reset-password?token=")
+
+	t.Run("異常系: 短いパスワードではトークンを消費しない", func(t *testing.T) {
+		assert.ErrorIs(t, uc.ResetPassword(ctx, token, "short"), auth.ErrInvalidPassword)
+	})
+
+	require.NoError(t, uc.ResetPassword(ctx, token, "new-password"))
+	updated := repo.users[created.ID]
+	// 既存のログインは全て無効になる
+	assert.Equal(t, []string{created.ID}, uc.sessions.(*SessionUsecase).refreshTokenRepository.(*memoryRefreshTokenRepo).revokedUsers)
+	assert.True(t, updated.IsEmailVerified())
+	_, err = uc.Login(ctx, "taro@example.com", "correct-horse")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	_, err = uc.Login(ctx, "taro@example.com", "new-password")
+	assert.NoError(t, err)
+
+	t.Run("異常系: トークンは一度しか使えない", func(t *testing.T) {
+		assert.ErrorIs(t, uc.ResetPassword(ctx, token, "another-password"), auth.ErrInvalidOneTimeToken)
+	})
+}
+
+func TestAccountLinkUsecase_LinkPassword(t *testing.T) {
+	newUsecase := func() (IAccountLinkUsecase, IPasswordAuthUsecase, *emailUserRepo) {
+		passwordAuth, repo, _ := newPasswordAuthTestUsecase()
+		repo.users["guest"] = &user.User{ID: "guest", Nickname: "ゲスト", IsGuest: true}
+		uc := NewAccountLinkUsecase(nil, passwordAuth, repo, &fakeAccountMerger{}, &memoryMergeTicketStore{tickets: map[string]auth.MergeTicket{}}, &fakeStepUpMFA{}, nil)
+		return uc, passwordAuth, repo
+	}
+	t.Run("正常系: 未登録のメールアドレスを連携", func(t *testing.T) {
+		uc, _, repo := newUsecase()
+		result, err := uc.LinkPassword(context.Background(), "guest", "guest@example.com", "correct-horse")
+		require.NoError(t, err)
+		assert.False(t, result.NeedsMerge())
+		assert.False(t, repo.users["guest"].IsGuest)
+		assert.Equal(t, "guest@example.com", repo.users["guest"].Email)
+	})
+	t.Run("正常系: 登録済みのメールアドレスはパスワードが合えば統合用トークンを返す", func(t *testing.T) {
+		uc, passwordAuth, repo := newUsecase()
+		_, err := passwordAuth.Register(context.Background(), "taro@example.com", "correct-horse", "")
+		require.NoError(t, err)
+		result, err := uc.LinkPassword(context.Background(), "guest", "taro@example.com", "correct-horse")
+		require.NoError(t, err)
+		assert.True(t, result.NeedsMerge())
+		assert.True(t, repo.users["guest"].IsGuest)
+		assert.Empty(t, repo.users["guest"].Email)
+	})
+	t.Run("異常系: 登録済みのメールアドレスでパスワード違い", func(t *testing.T) {
+		uc, passwordAuth, _ := newUsecase()
+		_, err := passwordAuth.Register(context.Background(), "taro@example.com", "correct-horse", "")
+		require.NoError(t, err)
+		_, err = uc.LinkPassword(context.Background(), "guest", "taro@example.com", "wrong-password")
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	})
+}
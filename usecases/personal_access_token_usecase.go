@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+	"unicode/utf8"
+)
+
+var (
+	ErrPersonalAccessTokenRequiresAccount = errors.New("アクセストークンはアカウントを連携したユーザーのみ発行できます")
+	ErrInvalidPersonalAccessTokenName     = errors.New("アクセストークンの名前は1〜100文字で入力してください")
+	ErrInvalidPersonalAccessTokenExpiry   = errors.New("アクセストークンの有効期限は1〜365日で指定してください")
+	ErrTooManyPersonalAccessTokens        = errors.New("発行できるアクセストークンの上限に達しています。不要なトークンを取り消してください")
+)
+
+const (
+	// DefaultPersonalAccessTokenTTL は有効期限を指定しなかった場合の有効期限
+	DefaultPersonalAccessTokenTTL = 90 * 24 * time.Hour
+	maxPersonalAccessTokenTTL     = 365 * 24 * time.Hour
+	maxPersonalAccessTokens       = 20
+	maxPersonalAccessTokenName    = 100
+	// personalAccessTokenTouchInterval より短い間隔の利用では最終利用日時を更新しない
+	personalAccessTokenTouchInterval = time.Minute
+)
+
+type IPersonalAccessTokenUsecase interface {
+	// Create はトークンを発行し、平文のトークンを返す（平文を返すのはこの1回のみ）
+	Create(ctx context.Context, userID, name string, scopes []auth.Scope, ttl time.Duration) (string, *auth.PersonalAccessToken, error)
+	List(ctx context.Context, userID string) ([]*auth.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, id string) error
+	// Authenticate はAPIの呼び出しに使われたトークンを検証し、最終利用日時を記録する
+	Authenticate(ctx context.Context, token string) (*auth.PersonalAccessToken, error)
+}
+
+type PersonalAccessTokenUsecase struct {
+	repository     auth.PersonalAccessTokenRepository
+	userRepository user.Repository
+	now            func() time.Time
+}
+
+func NewPersonalAccessTokenUsecase(repository auth.PersonalAccessTokenRepository, userRepository user.Repository) IPersonalAccessTokenUsecase {
+	return &PersonalAccessTokenUsecase{repository: repository, userRepository: userRepository, now: time.Now}
+}
+
+func (u *PersonalAccessTokenUsecase) Create(ctx context.Context, userID, name string, scopes []auth.Scope, ttl time.Duration) (string, *auth.PersonalAccessToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxPersonalAccessTokenName {
+		return "", nil, ErrInvalidPersonalAccessTokenName
+	}
+	if len(scopes) == 0 {
+		return "", nil, auth.ErrInvalidScope
+	}
+	if ttl == 0 {
+		ttl = DefaultPersonalAccessTokenTTL
+	}
+	if ttl < 24*time.Hour || ttl > maxPersonalAccessTokenTTL {
+		return "", nil, ErrInvalidPersonalAccessTokenExpiry
+	}
+	account, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if account == nil {
+		return "", nil, ErrUserNotFound
+	}
+	// ゲストは端末を失うとアカウントごと失われるため、長期間有効なトークンは発行しない
+	if account.IsGuest {
+		return "", nil, ErrPersonalAccessTokenRequiresAccount
+	}
+	existing, err := u.repository.ListByUserID(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(existing) >= maxPersonalAccessTokens {
+		return "", nil, ErrTooManyPersonalAccessTokens
+	}
+	secret, err := auth.RandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	raw := auth.PersonalAccessTokenPrefix + secret
+	token := &auth.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: auth.HashToken(raw),
+		Scopes:    scopes,
+		ExpiresAt: u.now().Add(ttl),
+	}
+	if err := u.repository.Create(ctx, token); err != nil {
+		return "", nil, err
+	}
+	return raw, token, nil
+}
+
+func (u *PersonalAccessTokenUsecase) List(ctx context.Context, userID string) ([]*auth.PersonalAccessToken, error) {
+	return u.repository.ListByUserID(ctx, userID)
+}
+
+func (u *PersonalAccessTokenUsecase) Revoke(ctx context.Context, userID, id string) error {
+	return u.repository.Delete(ctx, userID, id)
+}
+
+func (u *PersonalAccessTokenUsecase) Authenticate(ctx context.Context, raw string) (*auth.PersonalAccessToken, error) {
+	if !auth.IsPersonalAccessToken(raw) {
+		return nil, auth.ErrInvalidPersonalAccessToken
+	}
+	token, err := u.repository.FindByHash(ctx, auth.HashToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	now := u.now()
+	if token.IsExpired(now) {
+		return nil, auth.ErrInvalidPersonalAccessToken
+	}
+	// 退会を申請したユーザーのトークンは猶予期間中も使えない（申請時に削除するが、念のため確認する）
+	account, err := u.userRepository.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.IsWithdrawn() {
+		return nil, auth.ErrInvalidPersonalAccessToken
+	}
+	if err := u.repository.TouchLastUsed(ctx, token.ID, now, now.Add(-personalAccessTokenTouchInterval)); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
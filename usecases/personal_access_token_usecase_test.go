@@ -0,0 +1,126 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryPersonalAccessTokenRepo struct {
+	tokens  map[string]*auth.PersonalAccessToken
+	touched int
+}
+
+func (m *memoryPersonalAccessTokenRepo) Create(ctx context.Context, token *auth.PersonalAccessToken) error {
+	token.ID = token.TokenHash[:8]
+	m.tokens[token.ID] = token
+	return nil
+}
+func (m *memoryPersonalAccessTokenRepo) FindByHash(ctx context.Context, tokenHash string) (*auth.PersonalAccessToken, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, auth.ErrInvalidPersonalAccessToken
+}
+func (m *memoryPersonalAccessTokenRepo) ListByUserID(ctx context.Context, userID string) ([]*auth.PersonalAccessToken, error) {
+	var tokens []*auth.PersonalAccessToken
+	for _, t := range m.tokens {
+		if t.UserID == userID {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
+func (m *memoryPersonalAccessTokenRepo) TouchLastUsed(ctx context.Context, id string, now, staleBefore time.Time) error {
+	t := m.tokens[id]
+	if t.LastUsedAt == nil || t.LastUsedAt.Before(staleBefore) {
+		t.LastUsedAt = &now
+		m.touched++
+	}
+	return nil
+}
+func (m *memoryPersonalAccessTokenRepo) Delete(ctx context.Context, userID, id string) error {
+	t, ok := m.tokens[id]
+	if !ok || t.UserID != userID {
+		return auth.ErrPersonalAccessTokenNotFound
+	}
+	delete(m.tokens, id)
+	return nil
+}
+func (m *memoryPersonalAccessTokenRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	for id, t := range m.tokens {
+		if t.UserID == userID {
+			delete(m.tokens, id)
+		}
+	}
+	return nil
+}
+
+func TestPersonalAccessTokenUsecase(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := &memoryPersonalAccessTokenRepo{tokens: map[string]*auth.PersonalAccessToken{}}
+	users := &memoryUserRepo{users: map[string]*user.User{
+		"guest":  {ID: "guest", IsGuest: true},
+		"member": {ID: "member", Email: "member@example.com"},
+	}}
+	u := NewPersonalAccessTokenUsecase(repo, users).(*PersonalAccessTokenUsecase)
+	u.now = func() time.Time { return now }
+	scopes := []auth.Scope{auth.ScopeDiariesRead}
+
+	t.Run("異常系: 発行できない条件", func(t *testing.T) {
+		_, _, err := u.Create(ctx, "guest", "script", scopes, 0)
+		assert.ErrorIs(t, err, ErrPersonalAccessTokenRequiresAccount)
+		_, _, err = u.Create(ctx, "member", " ", scopes, 0)
+		assert.ErrorIs(t, err, ErrInvalidPersonalAccessTokenName)
+		_, _, err = u.Create(ctx, "member", "script", nil, 0)
+		assert.ErrorIs(t, err, auth.ErrInvalidScope)
+		_, _, err = u.Create(ctx, "member", "script", scopes, 400*24*time.Hour)
+		assert.ErrorIs(t, err, ErrInvalidPersonalAccessTokenExpiry)
+	})
+
+	raw, token, err := u.Create(ctx, "member", "script", scopes, 0)
+	require.NoError(t, err)
+	assert.True(t, auth.IsPersonalAccessToken(raw))
+	assert.Equal(t, auth.HashToken(raw), token.TokenHash, "平文は保存しない")
+	assert.Equal(t, now.Add(DefaultPersonalAccessTokenTTL), token.ExpiresAt)
+
+	t.Run("利用すると最終利用日時を記録する", func(t *testing.T) {
+		found, err := u.Authenticate(ctx, raw)
+		require.NoError(t, err)
+		assert.Equal(t, "member", found.UserID)
+		_, err = u.Authenticate(ctx, raw)
+		require.NoError(t, err)
+		assert.Equal(t, 1, repo.touched, "短い間隔の利用では更新しない")
+	})
+
+	t.Run("異常系: 期限切れ・不正なトークン", func(t *testing.T) {
+		_, err := u.Authenticate(ctx, "tfn_pat_unknown")
+		assert.ErrorIs(t, err, auth.ErrInvalidPersonalAccessToken)
+		u.now = func() time.Time { return token.ExpiresAt }
+		_, err = u.Authenticate(ctx, raw)
+		assert.ErrorIs(t, err, auth.ErrInvalidPersonalAccessToken)
+		u.now = func() time.Time { return now }
+	})
+
+	t.Run("退会を申請したユーザーのトークンは使えない", func(t *testing.T) {
+		users.users["member"].WithdrawnAt = &now
+		defer func() { users.users["member"].WithdrawnAt = nil }()
+		_, err := u.Authenticate(ctx, raw)
+		assert.ErrorIs(t, err, auth.ErrInvalidPersonalAccessToken)
+	})
+
+	t.Run("取り消したトークンは使えない", func(t *testing.T) {
+		assert.ErrorIs(t, u.Revoke(ctx, "other", token.ID), auth.ErrPersonalAccessTokenNotFound)
+		require.NoError(t, u.Revoke(ctx, "member", token.ID))
+		_, err := u.Authenticate(ctx, raw)
+		assert.ErrorIs(t, err, auth.ErrInvalidPersonalAccessToken)
+	})
+}
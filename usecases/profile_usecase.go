@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"tofunote-backend/domain/transaction"
+	"tofunote-backend/domain/user"
+)
+
+// ErrEmptyProfilePatch は変更する項目を1つも指定していないことを表す
+var ErrEmptyProfilePatch = errors.New("更新可能な項目がありません")
+
+type IProfileUsecase interface {
+	// Get はユーザーのプロフィールと設定を返す（設定を保存していない場合は既定値）
+	Get(ctx context.Context, userID string) (*user.Profile, error)
+	// Update はJSON Merge Patchの変更内容を反映し、更新後のプロフィールと設定を返す
+	Update(ctx context.Context, userID string, patch user.ProfilePatch) (*user.Profile, error)
+	// Preferences はユーザーの設定を返す（設定を保存していない場合は既定値）
+	Preferences(ctx context.Context, userID string) (user.Preferences, error)
+}
+
+type ProfileUsecase struct {
+	unitOfWork  transaction.UnitOfWork
+	users       user.Repository
+	preferences user.PreferencesRepository
+}
+
+func NewProfileUsecase(unitOfWork transaction.UnitOfWork, users user.Repository, preferences user.PreferencesRepository) IProfileUsecase {
+	return &ProfileUsecase{unitOfWork: unitOfWork, users: users, preferences: preferences}
+}
+
+func (u *ProfileUsecase) Get(ctx context.Context, userID string) (*user.Profile, error) {
+	account, err := u.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrUserNotFound
+	}
+	prefs, err := u.Preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &user.Profile{Nickname: account.Nickname, Preferences: prefs}, nil
+}
+
+func (u *ProfileUsecase) Preferences(ctx context.Context, userID string) (user.Preferences, error) {
+	prefs, err := u.preferences.FindByUserID(ctx, userID)
+	if err != nil {
+		return user.Preferences{}, err
+	}
+	if prefs == nil {
+		return user.DefaultPreferences(), nil
+	}
+	return *prefs, nil
+}
+
+// Update は変更した項目だけを保存する（ニックネームと設定の保存は1つのトランザクションで行う）
+func (u *ProfileUsecase) Update(ctx context.Context, userID string, patch user.ProfilePatch) (*user.Profile, error) {
+	if patch.IsEmpty() {
+		return nil, ErrEmptyProfilePatch
+	}
+	var profile *user.Profile
+	err := u.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		account, err := u.users.FindByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return ErrUserNotFound
+		}
+		prefs, err := u.Preferences(ctx, userID)
+		if err != nil {
+			return err
+		}
+		profile = &user.Profile{Nickname: account.Nickname, Preferences: prefs}
+		if err := profile.Apply(patch); err != nil {
+			return err
+		}
+		if patch.Nickname.Set && profile.Nickname != account.Nickname {
+			account.Nickname = profile.Nickname
+			if err := u.users.Update(ctx, account); err != nil {
+				return err
+			}
+		}
+		if patch.Preferences.Set {
+			return u.preferences.Save(ctx, userID, &profile.Preferences)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPreferencesRepo は設定をメモリ上に保存するPreferencesRepository
+type memoryPreferencesRepo struct {
+	prefs map[string]user.Preferences
+	saves int
+}
+
+func (m *memoryPreferencesRepo) FindByUserID(ctx context.Context, userID string) (*user.Preferences, error) {
+	p, ok := m.prefs[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (m *memoryPreferencesRepo) Save(ctx context.Context, userID string, p *user.Preferences) error {
+	m.saves++
+	m.prefs[userID] = *p
+	return nil
+}
+
+func (m *memoryPreferencesRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	delete(m.prefs, userID)
+	return nil
+}
+
+func (m *memoryPreferencesRepo) FindAnalysisOptedInUserIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	for id, p := range m.prefs {
+		if p.AnalysisOptIn {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+func (m *memoryPreferencesRepo) FindRemindable(ctx context.Context) ([]user.RemindableUser, error) {
+	var users []user.RemindableUser
+	for userID, p := range m.prefs {
+		if len(p.ReminderTimes) > 0 {
+			users = append(users, user.RemindableUser{UserID: userID, Preferences: p})
+		}
+	}
+	return users, nil
+}
+
+func newProfileTestUsecase() (IProfileUsecase, *memoryUserRepo, *memoryPreferencesRepo) {
+	users := &memoryUserRepo{users: map[string]*user.User{"u1": {ID: "u1", Nickname: "旧名"}}}
+	prefs := &memoryPreferencesRepo{prefs: map[string]user.Preferences{}}
+	return NewProfileUsecase(&directUnitOfWork{}, users, prefs), users, prefs
+}
+
+func decodeProfilePatch(t *testing.T, body string) user.ProfilePatch {
+	t.Helper()
+	var patch user.ProfilePatch
+	require.NoError(t, json.Unmarshal([]byte(body), &patch))
+	return patch
+}
+
+func TestProfileUsecase_GetReturnsDefaults(t *testing.T) {
+	uc, _, _ := newProfileTestUsecase()
+	profile, err := uc.Get(context.Background(), "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "旧名", profile.Nickname)
+	assert.Equal(t, user.DefaultPreferences(), profile.Preferences)
+
+	_, err = uc.Get(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestProfileUsecase_Update(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ニックネームだけの変更では設定を保存しない", func(t *testing.T) {
+		uc, users, prefs := newProfileTestUsecase()
+		profile, err := uc.Update(ctx, "u1", decodeProfilePatch(t, `{"nickname":"新しい名"}`))
+		require.NoError(t, err)
+		assert.Equal(t, "新しい名", profile.Nickname)
+		assert.Equal(t, "新しい名", users.users["u1"].Nickname)
+		assert.Equal(t, 0, prefs.saves)
+	})
+
+	t.Run("設定の変更は保存済みの設定に重ねる", func(t *testing.T) {
+		uc, _, prefs := newProfileTestUsecase()
+		saved := user.DefaultPreferences()
+		saved.Theme = user.ThemeDark
+		prefs.prefs["u1"] = saved
+		profile, err := uc.Update(ctx, "u1", decodeProfilePatch(t, `{"preferences":{"reminder_times":["21:00","07:30"],"analysis_opt_in":true}}`))
+		require.NoError(t, err)
+		assert.Equal(t, user.ThemeDark, profile.Preferences.Theme)
+		assert.Equal(t, []string{"07:30", "21:00"}, profile.Preferences.ReminderTimes)
+		assert.True(t, profile.Preferences.AnalysisOptIn)
+		assert.Equal(t, profile.Preferences, prefs.prefs["u1"])
+	})
+
+	t.Run("検証に失敗した場合は保存しない", func(t *testing.T) {
+		uc, users, prefs := newProfileTestUsecase()
+		_, err := uc.Update(ctx, "u1", decodeProfilePatch(t, `{"nickname":"新しい名","preferences":{"timezone":"Nowhere/City"}}`))
+		var perr *user.ProfileError
+		require.ErrorAs(t, err, &perr)
+		assert.Equal(t, "preferences.timezone", perr.Field)
+		assert.Equal(t, "旧名", users.users["u1"].Nickname)
+		assert.Equal(t, 0, prefs.saves)
+	})
+
+	t.Run("変更する項目がない", func(t *testing.T) {
+		uc, _, _ := newProfileTestUsecase()
+		_, err := uc.Update(ctx, "u1", decodeProfilePatch(t, `{"unknown":1}`))
+		assert.ErrorIs(t, err, ErrEmptyProfilePatch)
+	})
+
+	t.Run("ユーザーが見つからない", func(t *testing.T) {
+		uc, _, _ := newProfileTestUsecase()
+		_, err := uc.Update(ctx, "unknown", decodeProfilePatch(t, `{"nickname":"x"}`))
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
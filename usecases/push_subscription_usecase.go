@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"tofunote-backend/domain/notification"
+)
+
+var ErrTooManyPushSubscriptions = errors.New("通知を受け取る端末の登録数の上限に達しています。使っていない端末の登録を解除してください")
+
+// maxPushSubscriptions はユーザーごとに登録できる端末の数
+const maxPushSubscriptions = 20
+
+type IPushSubscriptionUsecase interface {
+	// Register は端末のブラウザの購読を登録する（同じブラウザの再登録は上書きする）
+	Register(ctx context.Context, userID, endpoint, p256dh, auth, userAgent string) (*notification.PushSubscription, error)
+	List(ctx context.Context, userID string) ([]notification.PushSubscription, error)
+	Unregister(ctx context.Context, userID, id string) error
+}
+
+type PushSubscriptionUsecase struct {
+	repository notification.PushSubscriptionRepository
+}
+
+func NewPushSubscriptionUsecase(repository notification.PushSubscriptionRepository) IPushSubscriptionUsecase {
+	return &PushSubscriptionUsecase{repository: repository}
+}
+
+func (u *PushSubscriptionUsecase) Register(ctx context.Context, userID, endpoint, p256dh, auth, userAgent string) (*notification.PushSubscription, error) {
+	subscription, err := notification.NewPushSubscription(userID, endpoint, p256dh, auth, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := u.repository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	registered := false
+	for _, s := range existing {
+		if s.Endpoint == endpoint {
+			registered = true
+			break
+		}
+	}
+	if !registered && len(existing) >= maxPushSubscriptions {
+		return nil, ErrTooManyPushSubscriptions
+	}
+	if err := u.repository.Save(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (u *PushSubscriptionUsecase) List(ctx context.Context, userID string) ([]notification.PushSubscription, error) {
+	return u.repository.FindByUserID(ctx, userID)
+}
+
+func (u *PushSubscriptionUsecase) Unregister(ctx context.Context, userID, id string) error {
+	return u.repository.Delete(ctx, userID, id)
+}
@@ -0,0 +1,129 @@
+package usecases
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"tofunote-backend/domain/notification"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryPushSubscriptionRepo struct {
+	subscriptions []notification.PushSubscription
+}
+
+func (m *memoryPushSubscriptionRepo) FindByUserID(ctx context.Context, userID string) ([]notification.PushSubscription, error) {
+	var found []notification.PushSubscription
+	for _, s := range m.subscriptions {
+		if s.UserID == userID {
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+func (m *memoryPushSubscriptionRepo) Save(ctx context.Context, s *notification.PushSubscription) error {
+	for i := range m.subscriptions {
+		if m.subscriptions[i].Endpoint == s.Endpoint {
+			s.ID = m.subscriptions[i].ID
+			m.subscriptions[i] = *s
+			return nil
+		}
+	}
+	s.ID = fmt.Sprintf("s%d", len(m.subscriptions)+1)
+	m.subscriptions = append(m.subscriptions, *s)
+	return nil
+}
+
+func (m *memoryPushSubscriptionRepo) Delete(ctx context.Context, userID, id string) error {
+	for i, s := range m.subscriptions {
+		if s.ID == id && s.UserID == userID {
+			m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return notification.ErrPushSubscriptionNotFound
+}
+
+func (m *memoryPushSubscriptionRepo) DeleteByEndpoint(ctx context.Context, endpoint string) error {
+	return nil
+}
+
+func (m *memoryPushSubscriptionRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	return nil
+}
+
+func newBrowserKeys(t *testing.T) (string, string) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	secret := make([]byte, 16)
+	_, err = rand.Read(secret)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), base64.RawURLEncoding.EncodeToString(secret)
+}
+
+func TestPushSubscriptionUsecase_Register(t *testing.T) {
+	repo := &memoryPushSubscriptionRepo{}
+	usecase := NewPushSubscriptionUsecase(repo)
+	ctx := context.Background()
+	p256dh, auth := newBrowserKeys(t)
+
+	s, err := usecase.Register(ctx, "u1", "https://push.example.com/1", p256dh, auth, "Mozilla/5.0")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", s.ID)
+	assert.Equal(t, "Mozilla/5.0", s.UserAgent)
+
+	// 同じブラウザの再登録は上書きする
+	s, err = usecase.Register(ctx, "u1", "https://push.example.com/1", p256dh, auth, "")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", s.ID)
+	assert.Len(t, repo.subscriptions, 1)
+
+	require.NoError(t, usecase.Unregister(ctx, "u1", "s1"))
+	assert.ErrorIs(t, usecase.Unregister(ctx, "u1", "s1"), notification.ErrPushSubscriptionNotFound)
+}
+
+func TestPushSubscriptionUsecase_RegisterInvalid(t *testing.T) {
+	usecase := NewPushSubscriptionUsecase(&memoryPushSubscriptionRepo{})
+	p256dh, auth := newBrowserKeys(t)
+
+	tests := []struct {
+		name     string
+		endpoint string
+		p256dh   string
+		auth     string
+	}{
+		{"httpのエンドポイント", "http://push.example.com/1", p256dh, auth},
+		{"公開鍵が曲線上の点でない", "https://push.example.com/1", base64.RawURLEncoding.EncodeToString(make([]byte, 65)), auth},
+		{"公開鍵の形式が不正", "https://push.example.com/1", "not base64!", auth},
+		{"認証用の秘密の長さが不正", "https://push.example.com/1", p256dh, base64.RawURLEncoding.EncodeToString(make([]byte, 8))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := usecase.Register(context.Background(), "u1", tt.endpoint, tt.p256dh, tt.auth, "")
+			assert.ErrorIs(t, err, notification.ErrInvalidPushSubscription)
+		})
+	}
+}
+
+func TestPushSubscriptionUsecase_RegisterLimit(t *testing.T) {
+	repo := &memoryPushSubscriptionRepo{}
+	usecase := NewPushSubscriptionUsecase(repo)
+	ctx := context.Background()
+	p256dh, auth := newBrowserKeys(t)
+	for i := 0; i < maxPushSubscriptions; i++ {
+		_, err := usecase.Register(ctx, "u1", fmt.Sprintf("https://push.example.com/%d", i), p256dh, auth, "")
+		require.NoError(t, err)
+	}
+
+	_, err := usecase.Register(ctx, "u1", "https://push.example.com/new", p256dh, auth, "")
+	assert.ErrorIs(t, err, ErrTooManyPushSubscriptions)
+	// 登録済みのブラウザの再登録は上限に関わらずできる
+	_, err = usecase.Register(ctx, "u1", "https://push.example.com/0", p256dh, auth, "")
+	assert.NoError(t, err)
+}
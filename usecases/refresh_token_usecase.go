@@ -0,0 +1,126 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tofunote-backend/domain/audit"
+	"tofunote-backend/domain/auth"
+)
+
+// DefaultRefreshTokenTTL はリフレッシュトークンの有効期限（ローテーションのたびに延長される）
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+type IRefreshTokenUsecase interface {
+	// Issue はログイン時に新しいファミリーのリフレッシュトークンを発行し、平文のトークンを返す
+	Issue(ctx context.Context, userID string, client auth.ClientInfo) (string, *auth.RefreshToken, error)
+	// Rotate はリフレッシュトークンを新しいものと交換する。交換済みのトークンが使われた場合はファミリーごと失効させる
+	Rotate(ctx context.Context, token string, client auth.ClientInfo) (string, *auth.RefreshToken, error)
+	// Revoke はログアウト時にリフレッシュトークンを失効させる
+	Revoke(ctx context.Context, token string) error
+}
+
+type RefreshTokenUsecase struct {
+	repository auth.RefreshTokenRepository
+	sessions   ISessionUsecase
+	audits     IAuditUsecase
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+func NewRefreshTokenUsecase(repository auth.RefreshTokenRepository, sessions ISessionUsecase, audits IAuditUsecase, ttl time.Duration) IRefreshTokenUsecase {
+	return &RefreshTokenUsecase{repository: repository, sessions: sessions, audits: audits, ttl: ttl, now: time.Now}
+}
+
+// Issue はセッションを作成し、そのIDをファミリーIDとするリフレッシュトークンを発行する（ログイン方法によらずここでログインを監査ログに記録する）
+func (u *RefreshTokenUsecase) Issue(ctx context.Context, userID string, client auth.ClientInfo) (string, *auth.RefreshToken, error) {
+	session, err := u.sessions.Start(ctx, userID, client, u.now().Add(u.ttl))
+	if err != nil {
+		return "", nil, err
+	}
+	raw, token, err := u.create(ctx, &auth.RefreshToken{UserID: userID, FamilyID: session.ID, DeviceLabel: client.DeviceLabel}, "")
+	if err != nil {
+		return "", nil, err
+	}
+	recordAudit(ctx, u.audits, audit.Event{ActorUserID: userID, Action: audit.ActionLogin, TargetUserID: userID, Detail: client.DeviceLabel})
+	return raw, token, nil
+}
+
+func (u *RefreshTokenUsecase) create(ctx context.Context, token *auth.RefreshToken, rotateFrom string) (string, *auth.RefreshToken, error) {
+	raw, err := auth.RandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	now := u.now()
+	token.TokenHash = auth.HashToken(raw)
+	token.ExpiresAt = now.Add(u.ttl)
+	if rotateFrom == "" {
+		err = u.repository.Create(ctx, token)
+	} else {
+		err = u.repository.Rotate(ctx, rotateFrom, token, now)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, token, nil
+}
+
+func (u *RefreshTokenUsecase) Rotate(ctx context.Context, token string, client auth.ClientInfo) (string, *auth.RefreshToken, error) {
+	current, err := u.repository.FindByHash(ctx, auth.HashToken(token))
+	if errors.Is(err, auth.ErrInvalidRefreshToken) {
+		recordAudit(ctx, u.audits, audit.Event{Action: audit.ActionAuthFailed, Detail: "unknown_refresh_token"})
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if current.RevokedAt != nil || current.IsExpired(u.now()) {
+		recordAudit(ctx, u.audits, audit.Event{Action: audit.ActionAuthFailed, TargetUserID: current.UserID, Detail: "invalid_refresh_token"})
+		return "", nil, auth.ErrInvalidRefreshToken
+	}
+	if current.RotatedAt != nil {
+		return "", nil, u.revokeReused(ctx, current)
+	}
+	label := current.DeviceLabel
+	if client.DeviceLabel != "" {
+		label = client.DeviceLabel
+	}
+	raw, next, err := u.create(ctx, &auth.RefreshToken{UserID: current.UserID, FamilyID: current.FamilyID, DeviceLabel: label}, current.ID)
+	if errors.Is(err, auth.ErrRefreshTokenReused) {
+		// 同じトークンで同時に交換された場合も再利用とみなす
+		return "", nil, u.revokeReused(ctx, current)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if err := u.sessions.Touch(ctx, next.FamilyID, client, next.ExpiresAt); err != nil {
+		return "", nil, err
+	}
+	recordAudit(ctx, u.audits, audit.Event{ActorUserID: next.UserID, Action: audit.ActionTokenRefresh, TargetUserID: next.UserID})
+	return raw, next, nil
+}
+
+func (u *RefreshTokenUsecase) revokeReused(ctx context.Context, current *auth.RefreshToken) error {
+	if err := u.revokeSession(ctx, current); err != nil {
+		return err
+	}
+	recordAudit(ctx, u.audits, audit.Event{Action: audit.ActionTokenReuseDetected, TargetUserID: current.UserID})
+	return auth.ErrRefreshTokenReused
+}
+
+// revokeSession はトークンのセッションを失効させる（セッションが既に失効済みでもファミリーのトークンは必ず失効させる）
+func (u *RefreshTokenUsecase) revokeSession(ctx context.Context, token *auth.RefreshToken) error {
+	err := u.sessions.Revoke(ctx, token.UserID, token.FamilyID)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return u.repository.RevokeFamily(ctx, token.FamilyID, u.now())
+	}
+	return err
+}
+
+// Revoke はトークンのセッションを失効させる（ローテーション前の古いトークンも含めてその端末のログインを終了する）
+func (u *RefreshTokenUsecase) Revoke(ctx context.Context, token string) error {
+	current, err := u.repository.FindByHash(ctx, auth.HashToken(token))
+	if err != nil {
+		return err
+	}
+	return u.revokeSession(ctx, current)
+}
@@ -0,0 +1,129 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRefreshTokenRepo struct {
+	tokens       map[string]*auth.RefreshToken
+	revokedUsers []string
+}
+
+func newMemoryRefreshTokenRepo() *memoryRefreshTokenRepo {
+	return &memoryRefreshTokenRepo{tokens: map[string]*auth.RefreshToken{}}
+}
+
+func (r *memoryRefreshTokenRepo) Create(ctx context.Context, token *auth.RefreshToken) error {
+	token.ID = fmt.Sprintf("rt-%d", len(r.tokens)+1)
+	if token.FamilyID == "" {
+		token.FamilyID = token.ID
+	}
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+func (r *memoryRefreshTokenRepo) FindByHash(ctx context.Context, tokenHash string) (*auth.RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+	copied := *token
+	return &copied, nil
+}
+func (r *memoryRefreshTokenRepo) Rotate(ctx context.Context, currentID string, next *auth.RefreshToken, now time.Time) error {
+	for _, token := range r.tokens {
+		if token.ID == currentID {
+			if token.RotatedAt != nil || token.RevokedAt != nil {
+				return auth.ErrRefreshTokenReused
+			}
+			token.RotatedAt = &now
+			return r.Create(ctx, next)
+		}
+	}
+	return auth.ErrInvalidRefreshToken
+}
+func (r *memoryRefreshTokenRepo) RevokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+func (r *memoryRefreshTokenRepo) RevokeByUserID(ctx context.Context, userID string, now time.Time) error {
+	r.revokedUsers = append(r.revokedUsers, userID)
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+func (r *memoryRefreshTokenRepo) DeleteByUserID(ctx context.Context, userID string) error { return nil }
+
+func TestRefreshTokenUsecase_Rotate(t *testing.T) {
+	repo := newMemoryRefreshTokenRepo()
+	uc := NewRefreshTokenUsecase(repo, NewSessionUsecase(newMemorySessionRepo(), repo, 24*time.Hour, time.Minute), nil, time.Hour)
+	ctx := context.Background()
+
+	first, issued, err := uc.Issue(ctx, "u1", auth.ClientInfo{DeviceLabel: "iPhone"})
+	require.NoError(t, err)
+	// 平文のトークンは保存しない
+	_, stored := repo.tokens[first]
+	assert.False(t, stored)
+	assert.Equal(t, auth.HashToken(first), issued.TokenHash)
+
+	second, rotated, err := uc.Rotate(ctx, first, auth.ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, issued.FamilyID, rotated.FamilyID)
+	assert.Equal(t, "iPhone", rotated.DeviceLabel)
+
+	// 別の端末でログインした別のファミリー
+	other, _, err := uc.Issue(ctx, "u1", auth.ClientInfo{DeviceLabel: "PC"})
+	require.NoError(t, err)
+
+	t.Run("異常系: 交換済みのトークンの再利用でファミリーごと失効する", func(t *testing.T) {
+		_, _, err := uc.Rotate(ctx, first, auth.ClientInfo{})
+		assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+		_, _, err = uc.Rotate(ctx, second, auth.ClientInfo{})
+		assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+	t.Run("正常系: 別のファミリーは影響を受けない", func(t *testing.T) {
+		_, _, err := uc.Rotate(ctx, other, auth.ClientInfo{})
+		assert.NoError(t, err)
+	})
+	t.Run("異常系: 存在しないトークン", func(t *testing.T) {
+		_, _, err := uc.Rotate(ctx, "unknown", auth.ClientInfo{})
+		assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+}
+
+func TestRefreshTokenUsecase_Expired(t *testing.T) {
+	repo := newMemoryRefreshTokenRepo()
+	uc := NewRefreshTokenUsecase(repo, NewSessionUsecase(newMemorySessionRepo(), repo, 24*time.Hour, time.Minute), nil, time.Hour).(*RefreshTokenUsecase)
+	ctx := context.Background()
+	token, _, err := uc.Issue(ctx, "u1", auth.ClientInfo{})
+	require.NoError(t, err)
+	uc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, _, err = uc.Rotate(ctx, token, auth.ClientInfo{})
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+}
+
+func TestRefreshTokenUsecase_Revoke(t *testing.T) {
+	repo := newMemoryRefreshTokenRepo()
+	uc := NewRefreshTokenUsecase(repo, NewSessionUsecase(newMemorySessionRepo(), repo, 24*time.Hour, time.Minute), nil, time.Hour)
+	ctx := context.Background()
+	token, _, err := uc.Issue(ctx, "u1", auth.ClientInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, uc.Revoke(ctx, token))
+	_, _, err = uc.Rotate(ctx, token, auth.ClientInfo{})
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+}
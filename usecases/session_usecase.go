@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"time"
+	"tofunote-backend/domain/auth"
+)
+
+// DefaultRevocationRefreshInterval は失効済みセッションの一覧をDBから読み直す間隔
+// （他のインスタンスで失効したセッションはこの間隔以内に拒否されるようになる）
+const DefaultRevocationRefreshInterval = 30 * time.Second
+
+type ISessionUsecase interface {
+	// Start はログイン時に新しいセッションを作成する
+	Start(ctx context.Context, userID string, client auth.ClientInfo, expiresAt time.Time) (*auth.Session, error)
+	// Touch はリフレッシュトークンの更新時にセッションの最終利用日時を更新する
+	Touch(ctx context.Context, sessionID string, client auth.ClientInfo, expiresAt time.Time) error
+	List(ctx context.Context, userID string) ([]*auth.Session, error)
+	// Revoke はセッションとそのリフレッシュトークンを失効させる
+	Revoke(ctx context.Context, userID, sessionID string) error
+	// RevokeAll はユーザーの全てのセッションを失効させる（全端末からのログアウト）
+	RevokeAll(ctx context.Context, userID string) error
+	// IsRevoked はアクセストークンのセッションが失効済みかどうかを返す（キャッシュした失効済みセッションの一覧で判定する）
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+type SessionUsecase struct {
+	sessionRepository      auth.SessionRepository
+	refreshTokenRepository auth.RefreshTokenRepository
+	// accessTokenTTL より前に失効したセッションのアクセストークンは期限切れのため、キャッシュに保持しない
+	accessTokenTTL  time.Duration
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu       sync.Mutex
+	revoked  map[string]struct{}
+	loadedAt time.Time
+}
+
+func NewSessionUsecase(sessionRepository auth.SessionRepository, refreshTokenRepository auth.RefreshTokenRepository, accessTokenTTL, refreshInterval time.Duration) ISessionUsecase {
+	return &SessionUsecase{
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		accessTokenTTL:         accessTokenTTL,
+		refreshInterval:        refreshInterval,
+		now:                    time.Now,
+	}
+}
+
+func (u *SessionUsecase) Start(ctx context.Context, userID string, client auth.ClientInfo, expiresAt time.Time) (*auth.Session, error) {
+	now := u.now()
+	session := &auth.Session{
+		UserID:      userID,
+		DeviceLabel: client.DeviceLabel,
+		UserAgent:   client.UserAgent,
+		IPAddress:   client.IPAddress,
+		LastUsedAt:  now,
+		ExpiresAt:   expiresAt,
+	}
+	if err := u.sessionRepository.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (u *SessionUsecase) Touch(ctx context.Context, sessionID string, client auth.ClientInfo, expiresAt time.Time) error {
+	return u.sessionRepository.Touch(ctx, sessionID, client, expiresAt, u.now())
+}
+
+func (u *SessionUsecase) List(ctx context.Context, userID string) ([]*auth.Session, error) {
+	return u.sessionRepository.ListActiveByUserID(ctx, userID, u.now())
+}
+
+func (u *SessionUsecase) Revoke(ctx context.Context, userID, sessionID string) error {
+	now := u.now()
+	if err := u.sessionRepository.Revoke(ctx, userID, sessionID, now); err != nil {
+		return err
+	}
+	if err := u.refreshTokenRepository.RevokeFamily(ctx, sessionID, now); err != nil {
+		return err
+	}
+	u.markRevoked(sessionID)
+	return nil
+}
+
+func (u *SessionUsecase) RevokeAll(ctx context.Context, userID string) error {
+	now := u.now()
+	sessions, err := u.sessionRepository.ListActiveByUserID(ctx, userID, now)
+	if err != nil {
+		return err
+	}
+	if err := u.sessionRepository.RevokeAllByUserID(ctx, userID, now); err != nil {
+		return err
+	}
+	if err := u.refreshTokenRepository.RevokeByUserID(ctx, userID, now); err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		u.markRevoked(s.ID)
+	}
+	return nil
+}
+
+// markRevoked はこのインスタンスで失効させたセッションを、次の読み直しを待たずにキャッシュへ反映する
+func (u *SessionUsecase) markRevoked(sessionID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.revoked != nil {
+		u.revoked[sessionID] = struct{}{}
+	}
+}
+
+func (u *SessionUsecase) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := u.now()
+	if u.revoked == nil || now.Sub(u.loadedAt) >= u.refreshInterval {
+		ids, err := u.sessionRepository.ListRevokedIDsSince(ctx, now.Add(-u.accessTokenTTL))
+		if err != nil {
+			return false, err
+		}
+		revoked := make(map[string]struct{}, len(ids))
+		for _, id := range ids {
+			revoked[id] = struct{}{}
+		}
+		u.revoked = revoked
+		u.loadedAt = now
+	}
+	_, ok := u.revoked[sessionID]
+	return ok, nil
+}
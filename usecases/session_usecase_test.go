@@ -0,0 +1,152 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySessionRepo struct {
+	sessions    map[string]*auth.Session
+	revokedList int
+}
+
+func newMemorySessionRepo() *memorySessionRepo {
+	return &memorySessionRepo{sessions: map[string]*auth.Session{}}
+}
+
+func (r *memorySessionRepo) Create(ctx context.Context, session *auth.Session) error {
+	session.ID = fmt.Sprintf("session-%d", len(r.sessions)+1)
+	session.CreatedAt = session.LastUsedAt
+	r.sessions[session.ID] = session
+	return nil
+}
+func (r *memorySessionRepo) Touch(ctx context.Context, id string, client auth.ClientInfo, expiresAt, now time.Time) error {
+	if s, ok := r.sessions[id]; ok {
+		s.LastUsedAt, s.ExpiresAt, s.UserAgent, s.IPAddress = now, expiresAt, client.UserAgent, client.IPAddress
+	}
+	return nil
+}
+func (r *memorySessionRepo) ListActiveByUserID(ctx context.Context, userID string, now time.Time) ([]*auth.Session, error) {
+	var active []*auth.Session
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil && now.Before(s.ExpiresAt) {
+			active = append(active, s)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+	return active, nil
+}
+func (r *memorySessionRepo) Revoke(ctx context.Context, userID, id string, now time.Time) error {
+	s, ok := r.sessions[id]
+	if !ok || s.UserID != userID || s.RevokedAt != nil {
+		return auth.ErrSessionNotFound
+	}
+	s.RevokedAt = &now
+	return nil
+}
+func (r *memorySessionRepo) RevokeAllByUserID(ctx context.Context, userID string, now time.Time) error {
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+		}
+	}
+	return nil
+}
+func (r *memorySessionRepo) ListRevokedIDsSince(ctx context.Context, since time.Time) ([]string, error) {
+	r.revokedList++
+	var ids []string
+	for _, s := range r.sessions {
+		if s.RevokedAt != nil && !s.RevokedAt.Before(since) {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids, nil
+}
+func (r *memorySessionRepo) DeleteByUserID(ctx context.Context, userID string) error { return nil }
+
+func newSessionTestUsecase() (*SessionUsecase, *memorySessionRepo, *memoryRefreshTokenRepo) {
+	sessions := newMemorySessionRepo()
+	refreshTokens := newMemoryRefreshTokenRepo()
+	uc := NewSessionUsecase(sessions, refreshTokens, 24*time.Hour, time.Minute).(*SessionUsecase)
+	return uc, sessions, refreshTokens
+}
+
+func TestSessionUsecase_RevokeAndList(t *testing.T) {
+	uc, _, _ := newSessionTestUsecase()
+	refresh := NewRefreshTokenUsecase(uc.refreshTokenRepository, uc, nil, time.Hour)
+	ctx := context.Background()
+
+	phoneToken, phone, err := refresh.Issue(ctx, "u1", auth.ClientInfo{DeviceLabel: "iPhone", UserAgent: "Safari", IPAddress: "192.0.2.1"})
+	require.NoError(t, err)
+	_, laptop, err := refresh.Issue(ctx, "u1", auth.ClientInfo{DeviceLabel: "PC"})
+	require.NoError(t, err)
+
+	sessions, err := uc.List(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, phone.FamilyID, sessions[0].ID)
+	assert.Equal(t, "iPhone", sessions[0].DeviceLabel)
+	assert.Equal(t, "Safari", sessions[0].UserAgent)
+
+	t.Run("異常系: 他のユーザーのセッション", func(t *testing.T) {
+		assert.ErrorIs(t, uc.Revoke(ctx, "u2", phone.FamilyID), auth.ErrSessionNotFound)
+	})
+
+	require.NoError(t, uc.Revoke(ctx, "u1", phone.FamilyID))
+	// 失効したセッションのリフレッシュトークンは使えない
+	_, _, err = refresh.Rotate(ctx, phoneToken, auth.ClientInfo{})
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	revoked, err := uc.IsRevoked(ctx, phone.FamilyID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = uc.IsRevoked(ctx, laptop.FamilyID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, uc.RevokeAll(ctx, "u1"))
+	sessions, err = uc.List(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	revoked, err = uc.IsRevoked(ctx, laptop.FamilyID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestSessionUsecase_IsRevoked_Cache(t *testing.T) {
+	uc, sessions, _ := newSessionTestUsecase()
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	session, err := uc.Start(ctx, "u1", auth.ClientInfo{}, now.Add(time.Hour))
+	require.NoError(t, err)
+	revoked, err := uc.IsRevoked(ctx, session.ID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// 他のインスタンスで失効した場合は、キャッシュを読み直すまで反映されない
+	require.NoError(t, sessions.Revoke(ctx, "u1", session.ID, now))
+	revoked, err = uc.IsRevoked(ctx, session.ID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.Equal(t, 1, sessions.revokedList)
+
+	now = now.Add(time.Minute)
+	revoked, err = uc.IsRevoked(ctx, session.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.Equal(t, 2, sessions.revokedList)
+
+	// アクセストークンの有効期限より前に失効したセッションはキャッシュから外れる
+	now = now.Add(25 * time.Hour)
+	revoked, err = uc.IsRevoked(ctx, session.ID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
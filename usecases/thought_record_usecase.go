@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/thoughtrecord"
+)
+
+type IThoughtRecordUsecase interface {
+	FindByUserID(ctx context.Context, userID string) ([]thoughtrecord.ThoughtRecord, error)
+	FindByUserIDAndDate(ctx context.Context, userID string, date string) ([]thoughtrecord.ThoughtRecord, error)
+	FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]thoughtrecord.ThoughtRecord, error)
+	FindByID(ctx context.Context, userID string, id string) (*thoughtrecord.ThoughtRecord, error)
+	Create(ctx context.Context, record *thoughtrecord.ThoughtRecord) error
+	Update(ctx context.Context, record *thoughtrecord.ThoughtRecord) error
+	Delete(ctx context.Context, userID string, id string) error
+}
+
+type ThoughtRecordUsecase struct {
+	repository      thoughtrecord.Repository
+	labelRepository diary.EmotionLabelRepository
+}
+
+func NewThoughtRecordUsecase(repository thoughtrecord.Repository, labelRepository diary.EmotionLabelRepository) IThoughtRecordUsecase {
+	return &ThoughtRecordUsecase{repository: repository, labelRepository: labelRepository}
+}
+
+func (u *ThoughtRecordUsecase) FindByUserID(ctx context.Context, userID string) ([]thoughtrecord.ThoughtRecord, error) {
+	return u.repository.FindByUserID(ctx, userID)
+}
+
+func (u *ThoughtRecordUsecase) FindByUserIDAndDate(ctx context.Context, userID string, date string) ([]thoughtrecord.ThoughtRecord, error) {
+	return u.repository.FindByUserIDAndDate(ctx, userID, date)
+}
+
+func (u *ThoughtRecordUsecase) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate string) ([]thoughtrecord.ThoughtRecord, error) {
+	return u.repository.FindByUserIDAndDateRange(ctx, userID, startDate, endDate)
+}
+
+func (u *ThoughtRecordUsecase) FindByID(ctx context.Context, userID string, id string) (*thoughtrecord.ThoughtRecord, error) {
+	return u.repository.FindByID(ctx, userID, id)
+}
+
+func (u *ThoughtRecordUsecase) Create(ctx context.Context, record *thoughtrecord.ThoughtRecord) error {
+	if err := u.validate(ctx, record); err != nil {
+		return err
+	}
+	return u.repository.Create(ctx, record)
+}
+
+func (u *ThoughtRecordUsecase) Update(ctx context.Context, record *thoughtrecord.ThoughtRecord) error {
+	existing, err := u.repository.FindByID(ctx, record.UserID, record.ID)
+	if err != nil {
+		return err
+	}
+	if err := u.validate(ctx, record); err != nil {
+		return err
+	}
+	record.CreatedAt = existing.CreatedAt
+	return u.repository.Update(ctx, record)
+}
+
+func (u *ThoughtRecordUsecase) Delete(ctx context.Context, userID string, id string) error {
+	return u.repository.Delete(ctx, userID, id)
+}
+
+// validate はユーザー定義の感情ラベルを含む体系で思考記録を検証する
+func (u *ThoughtRecordUsecase) validate(ctx context.Context, record *thoughtrecord.ThoughtRecord) error {
+	taxonomy, err := loadTaxonomy(ctx, u.labelRepository, record.UserID)
+	if err != nil {
+		return err
+	}
+	return record.Validate(taxonomy)
+}
+
+// loadTaxonomy はユーザーの感情ラベル体系を返す（ラベルのリポジトリがない場合は組み込みラベルのみ）
+func loadTaxonomy(ctx context.Context, labelRepository diary.EmotionLabelRepository, userID string) (*diary.Taxonomy, error) {
+	if labelRepository == nil {
+		return diary.NewTaxonomy(nil), nil
+	}
+	custom, err := labelRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return diary.NewTaxonomy(custom), nil
+}
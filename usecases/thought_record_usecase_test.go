@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/thoughtrecord"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockThoughtRecordRepository struct {
+	records []thoughtrecord.ThoughtRecord
+	saved   *thoughtrecord.ThoughtRecord
+	err     error
+}
+
+func (m *mockThoughtRecordRepository) FindByUserID(ctx context.Context, userID string) ([]thoughtrecord.ThoughtRecord, error) {
+	return m.records, m.err
+}
+func (m *mockThoughtRecordRepository) FindByUserIDAndDate(ctx context.Context, userID, date string) ([]thoughtrecord.ThoughtRecord, error) {
+	return m.records, m.err
+}
+func (m *mockThoughtRecordRepository) FindByUserIDAndDateRange(ctx context.Context, userID, startDate, endDate string) ([]thoughtrecord.ThoughtRecord, error) {
+	return m.records, m.err
+}
+func (m *mockThoughtRecordRepository) FindByID(ctx context.Context, userID, id string) (*thoughtrecord.ThoughtRecord, error) {
+	for _, r := range m.records {
+		if r.UserID == userID && r.ID == id {
+			return &r, nil
+		}
+	}
+	return nil, errors.New("指定された思考記録が見つかりません")
+}
+func (m *mockThoughtRecordRepository) Create(ctx context.Context, r *thoughtrecord.ThoughtRecord) error {
+	m.saved = r
+	return m.err
+}
+func (m *mockThoughtRecordRepository) Update(ctx context.Context, r *thoughtrecord.ThoughtRecord) error {
+	m.saved = r
+	return m.err
+}
+func (m *mockThoughtRecordRepository) Delete(ctx context.Context, userID, id string) error {
+	return m.err
+}
+func (m *mockThoughtRecordRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return m.err
+}
+
+func TestThoughtRecordUsecase_Create(t *testing.T) {
+	labelRepo := &mockEmotionLabelRepository{labels: []diary.EmotionLabel{
+		{Key: "impatience", UserID: "1", Category: "anger", NameJa: "焦り"},
+	}}
+
+	tests := []struct {
+		name     string
+		emotions []thoughtrecord.EmotionRating
+		wantErr  string
+	}{
+		{name: "正常系：組み込みの感情", emotions: []thoughtrecord.EmotionRating{{Key: "anxiety", Before: 70}}},
+		{name: "正常系：ユーザー定義の感情", emotions: []thoughtrecord.EmotionRating{{Key: "impatience", Before: 50}}},
+		{name: "異常系：未登録の感情は保存しない", emotions: []thoughtrecord.EmotionRating{{Key: "unknown", Before: 50}}, wantErr: "未登録の感情ラベルです: unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockThoughtRecordRepository{}
+			usecase := NewThoughtRecordUsecase(repo, labelRepo)
+			record := &thoughtrecord.ThoughtRecord{UserID: "1", Situation: "状況", AutomaticThought: "自動思考", Emotions: tt.emotions}
+			err := usecase.Create(context.Background(), record)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, repo.saved)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, record, repo.saved)
+		})
+	}
+}
+
+func TestThoughtRecordUsecase_Update(t *testing.T) {
+	createdAt := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	repo := &mockThoughtRecordRepository{records: []thoughtrecord.ThoughtRecord{
+		{ID: "r1", UserID: "1", CreatedAt: createdAt},
+	}}
+	usecase := NewThoughtRecordUsecase(repo, nil)
+
+	record := &thoughtrecord.ThoughtRecord{ID: "r1", UserID: "1", Situation: "状況", AutomaticThought: "自動思考",
+		Emotions: []thoughtrecord.EmotionRating{{Key: "sadness", Before: 60}}}
+	assert.NoError(t, usecase.Update(context.Background(), record))
+	assert.Equal(t, createdAt, repo.saved.CreatedAt)
+
+	// 他のユーザーの思考記録は更新できない
+	record.UserID = "2"
+	assert.EqualError(t, usecase.Update(context.Background(), record), "指定された思考記録が見つかりません")
+}
+
+func TestBuildUserAnalysisContent(t *testing.T) {
+	diaries := []diary.Diary{{ID: "d1", UserID: "1", Date: "2025-05-01", Mental: 4, Diary: "疲れた"}}
+	after := 30
+	records := []thoughtrecord.ThoughtRecord{{
+		Date: "2025-05-01", Situation: "残業", AutomaticThought: "自分は要領が悪い",
+		Emotions: []thoughtrecord.EmotionRating{{Key: "shame", Before: 70, After: &after}},
+	}}
+
+	content := buildUserAnalysisContent(diaries, records)
+	assert.Contains(t, content, "Diary: 疲れた")
+	assert.Contains(t, content, "思考記録（2025-05-01）")
+	assert.Contains(t, content, "感情: 恥ずかしさ 70%→30%")
+
+	// 思考記録がない場合は日記のみ
+	assert.NotContains(t, buildUserAnalysisContent(diaries, nil), "思考記録")
+}
@@ -0,0 +1,297 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+)
+
+var ErrTOTPRequiresAccount = errors.New("二段階認証はアカウントを連携したユーザーのみ設定できます")
+
+const (
+	// DefaultTOTPIssuer は認証アプリに表示するサービス名
+	DefaultTOTPIssuer = "Tofunote"
+
+	recoveryCodeCount = 10
+	// totpSkewSteps は認証アプリとの時刻のずれとして許容する前後の時間ステップ数
+	totpSkewSteps = 1
+	// maxTOTPFailedAttempts 回続けて失敗すると totpLockDuration の間は入力を受け付けない
+	maxTOTPFailedAttempts = 5
+	totpLockDuration      = 15 * time.Minute
+)
+
+// TOTPEnrollment は認証アプリに登録するための情報
+type TOTPEnrollment struct {
+	// Secret は手入力用のBase32の共有鍵
+	Secret string
+	// ProvisioningURI はQRコードに埋め込むotpauth URI
+	ProvisioningURI string
+}
+
+// MFAStatus は二段階認証の設定状況
+type MFAStatus struct {
+	TOTPEnabled            bool
+	RecoveryCodesRemaining int
+}
+
+type ITOTPUsecase interface {
+	Status(ctx context.Context, userID string) (*MFAStatus, error)
+	// Enroll は共有鍵を発行する（Activateで認証コードを確認するまでは有効にならない）
+	Enroll(ctx context.Context, userID string) (*TOTPEnrollment, error)
+	// Activate は認証アプリのコードを確認して二段階認証を有効にし、リカバリーコードを返す（平文を返すのはこの1回のみ）
+	Activate(ctx context.Context, userID, code string) ([]string, error)
+	// Disable は認証コードまたはリカバリーコードを確認して二段階認証を無効にする
+	Disable(ctx context.Context, userID, code string) error
+	// RegenerateRecoveryCodes は認証コードを確認してリカバリーコードを作り直す（以前のコードは使えなくなる）
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	// BeginLogin は二段階認証が有効なユーザーの場合にログインを保留し、確認トークンを返す（無効な場合は空文字）
+	BeginLogin(ctx context.Context, u *user.User) (string, error)
+	// FinishLogin は確認トークンと認証コード（またはリカバリーコード）を検証してログインしたユーザーを返す
+	FinishLogin(ctx context.Context, mfaToken, code string) (*user.User, error)
+	// Verify は二段階認証が有効なユーザーの認証コード（またはリカバリーコード）を確認する（アカウントの統合などの追加の確認用）
+	Verify(ctx context.Context, userID, code string) error
+}
+
+type TOTPUsecase struct {
+	totpRepository         auth.TOTPRepository
+	recoveryCodeRepository auth.RecoveryCodeRepository
+	userRepository         user.Repository
+	challenges             auth.MFAChallengeStore
+	issuer                 string
+	now                    func() time.Time
+}
+
+func NewTOTPUsecase(totpRepository auth.TOTPRepository, recoveryCodeRepository auth.RecoveryCodeRepository, userRepository user.Repository, challenges auth.MFAChallengeStore, issuer string) ITOTPUsecase {
+	if issuer == "" {
+		issuer = DefaultTOTPIssuer
+	}
+	return &TOTPUsecase{
+		totpRepository:         totpRepository,
+		recoveryCodeRepository: recoveryCodeRepository,
+		userRepository:         userRepository,
+		challenges:             challenges,
+		issuer:                 issuer,
+		now:                    time.Now,
+	}
+}
+
+func (u *TOTPUsecase) Status(ctx context.Context, userID string) (*MFAStatus, error) {
+	factor, err := u.totpRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !factor.IsEnabled() {
+		return &MFAStatus{}, nil
+	}
+	remaining, err := u.recoveryCodeRepository.CountUnused(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{TOTPEnabled: true, RecoveryCodesRemaining: remaining}, nil
+}
+
+func (u *TOTPUsecase) Enroll(ctx context.Context, userID string) (*TOTPEnrollment, error) {
+	account, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrUserNotFound
+	}
+	if account.IsGuest {
+		return nil, ErrTOTPRequiresAccount
+	}
+	factor, err := u.totpRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor.IsEnabled() {
+		return nil, auth.ErrTOTPAlreadyEnabled
+	}
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	// 設定をやり直す場合は、前回発行した共有鍵を新しいものに置き換える
+	if err := u.totpRepository.Save(ctx, &auth.TOTPFactor{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	accountName := account.Email
+	if accountName == "" {
+		accountName = account.Nickname
+	}
+	return &TOTPEnrollment{
+		Secret:          auth.EncodeTOTPSecret(secret),
+		ProvisioningURI: auth.TOTPProvisioningURI(u.issuer, accountName, secret),
+	}, nil
+}
+
+func (u *TOTPUsecase) Activate(ctx context.Context, userID, code string) ([]string, error) {
+	factor, err := u.totpRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, auth.ErrTOTPNotEnrolled
+	}
+	if factor.IsEnabled() {
+		return nil, auth.ErrTOTPAlreadyEnabled
+	}
+	if err := u.verifyCode(ctx, factor, code, false); err != nil {
+		return nil, err
+	}
+	codes, err := u.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := u.now()
+	factor.EnabledAt = &now
+	if err := u.totpRepository.Save(ctx, factor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (u *TOTPUsecase) Disable(ctx context.Context, userID, code string) error {
+	factor, err := u.enabledFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := u.verifyCode(ctx, factor, code, true); err != nil {
+		return err
+	}
+	if err := u.recoveryCodeRepository.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	return u.totpRepository.DeleteByUserID(ctx, userID)
+}
+
+func (u *TOTPUsecase) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	factor, err := u.enabledFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// 残りのリカバリーコードで作り直すと漏えい時に使い回せるため、認証アプリのコードに限る
+	if err := u.verifyCode(ctx, factor, code, false); err != nil {
+		return nil, err
+	}
+	return u.replaceRecoveryCodes(ctx, userID)
+}
+
+func (u *TOTPUsecase) BeginLogin(ctx context.Context, account *user.User) (string, error) {
+	factor, err := u.totpRepository.FindByUserID(ctx, account.ID)
+	if err != nil {
+		return "", err
+	}
+	if !factor.IsEnabled() {
+		return "", nil
+	}
+	return u.challenges.Issue(auth.MFAChallenge{UserID: account.ID})
+}
+
+func (u *TOTPUsecase) FinishLogin(ctx context.Context, mfaToken, code string) (*user.User, error) {
+	challenge, err := u.challenges.Verify(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	factor, err := u.totpRepository.FindByUserID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !factor.IsEnabled() {
+		// ログインの途中で二段階認証が無効にされた場合は、もう一度ログインからやり直してもらう
+		return nil, auth.ErrInvalidMFAChallenge
+	}
+	if err := u.verifyCode(ctx, factor, code, true); err != nil {
+		return nil, err
+	}
+	account, err := u.userRepository.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrUserNotFound
+	}
+	return account, nil
+}
+
+func (u *TOTPUsecase) Verify(ctx context.Context, userID, code string) error {
+	factor, err := u.enabledFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return u.verifyCode(ctx, factor, code, true)
+}
+
+func (u *TOTPUsecase) enabledFactor(ctx context.Context, userID string) (*auth.TOTPFactor, error) {
+	factor, err := u.totpRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !factor.IsEnabled() {
+		return nil, auth.ErrTOTPNotEnabled
+	}
+	return factor, nil
+}
+
+// verifyCode は認証アプリのコード（allowRecoveryがtrueの場合はリカバリーコードも）を確認する。
+// 続けて失敗した場合は一定時間ロックする
+func (u *TOTPUsecase) verifyCode(ctx context.Context, factor *auth.TOTPFactor, code string, allowRecovery bool) error {
+	now := u.now()
+	if factor.IsLocked(now) {
+		return auth.ErrTOTPLocked
+	}
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	var err error
+	if step, ok := auth.MatchTOTPCode(factor.Secret, code, now, totpSkewSteps); ok {
+		if err = u.totpRepository.UseStep(ctx, factor.UserID, step); err == nil {
+			factor.LastUsedStep = step
+		}
+	} else if allowRecovery && len(code) > auth.TOTPDigits {
+		if err = u.recoveryCodeRepository.Consume(ctx, factor.UserID, auth.HashToken(auth.NormalizeRecoveryCode(code)), now); err == nil && factor.FailedAttempts > 0 {
+			err = u.resetFailures(ctx, factor)
+		}
+	} else {
+		err = auth.ErrInvalidTOTPCode
+	}
+	if errors.Is(err, auth.ErrInvalidTOTPCode) {
+		if recordErr := u.recordFailure(ctx, factor, now); recordErr != nil {
+			return recordErr
+		}
+		return auth.ErrInvalidTOTPCode
+	}
+	if err != nil {
+		return err
+	}
+	factor.FailedAttempts = 0
+	factor.LockedUntil = nil
+	return nil
+}
+
+func (u *TOTPUsecase) recordFailure(ctx context.Context, factor *auth.TOTPFactor, now time.Time) error {
+	return u.totpRepository.RecordFailure(ctx, factor.UserID, maxTOTPFailedAttempts, now.Add(totpLockDuration))
+}
+
+func (u *TOTPUsecase) resetFailures(ctx context.Context, factor *auth.TOTPFactor) error {
+	return u.totpRepository.ResetFailures(ctx, factor.UserID)
+}
+
+func (u *TOTPUsecase) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]*auth.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := auth.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = &auth.RecoveryCode{UserID: userID, CodeHash: auth.HashToken(auth.NormalizeRecoveryCode(code))}
+	}
+	if err := u.recoveryCodeRepository.ReplaceByUserID(ctx, userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,249 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
+	"tofunote-backend/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTOTPRepo struct {
+	factors map[string]*auth.TOTPFactor
+}
+
+func (m *memoryTOTPRepo) FindByUserID(ctx context.Context, userID string) (*auth.TOTPFactor, error) {
+	f, ok := m.factors[userID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *f
+	return &copied, nil
+}
+func (m *memoryTOTPRepo) Save(ctx context.Context, factor *auth.TOTPFactor) error {
+	copied := *factor
+	m.factors[factor.UserID] = &copied
+	return nil
+}
+func (m *memoryTOTPRepo) UseStep(ctx context.Context, userID string, step int64) error {
+	f, ok := m.factors[userID]
+	if !ok || f.LastUsedStep >= step {
+		return auth.ErrInvalidTOTPCode
+	}
+	f.LastUsedStep, f.FailedAttempts, f.LockedUntil = step, 0, nil
+	return nil
+}
+func (m *memoryTOTPRepo) RecordFailure(ctx context.Context, userID string, maxAttempts int, lockedUntil time.Time) error {
+	f := m.factors[userID]
+	f.FailedAttempts++
+	if f.FailedAttempts >= maxAttempts {
+		f.FailedAttempts, f.LockedUntil = 0, &lockedUntil
+	}
+	return nil
+}
+func (m *memoryTOTPRepo) ResetFailures(ctx context.Context, userID string) error {
+	f := m.factors[userID]
+	f.FailedAttempts, f.LockedUntil = 0, nil
+	return nil
+}
+func (m *memoryTOTPRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	delete(m.factors, userID)
+	return nil
+}
+
+type memoryRecoveryCodeRepo struct {
+	codes map[string]*auth.RecoveryCode
+}
+
+func (m *memoryRecoveryCodeRepo) ReplaceByUserID(ctx context.Context, userID string, codes []*auth.RecoveryCode) error {
+	m.codes = map[string]*auth.RecoveryCode{}
+	for _, c := range codes {
+		m.codes[c.CodeHash] = c
+	}
+	return nil
+}
+func (m *memoryRecoveryCodeRepo) Consume(ctx context.Context, userID, codeHash string, now time.Time) error {
+	c, ok := m.codes[codeHash]
+	if !ok || c.UserID != userID || c.UsedAt != nil {
+		return auth.ErrInvalidTOTPCode
+	}
+	c.UsedAt = &now
+	return nil
+}
+func (m *memoryRecoveryCodeRepo) CountUnused(ctx context.Context, userID string) (int, error) {
+	count := 0
+	for _, c := range m.codes {
+		if c.UserID == userID && c.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+func (m *memoryRecoveryCodeRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	m.codes = map[string]*auth.RecoveryCode{}
+	return nil
+}
+
+type memoryMFAChallengeStore struct{}
+
+func (memoryMFAChallengeStore) Issue(challenge auth.MFAChallenge) (string, error) {
+	return "mfa-" + challenge.UserID, nil
+}
+func (memoryMFAChallengeStore) Verify(token string) (*auth.MFAChallenge, error) {
+	if len(token) <= 4 || token[:4] != "mfa-" {
+		return nil, auth.ErrInvalidMFAChallenge
+	}
+	return &auth.MFAChallenge{UserID: token[4:]}, nil
+}
+
+func newTOTPTestUsecase(now *time.Time) (*TOTPUsecase, *memoryTOTPRepo) {
+	totpRepo := &memoryTOTPRepo{factors: map[string]*auth.TOTPFactor{}}
+	userRepo := &memoryUserRepo{users: map[string]*user.User{
+		"guest":  {ID: "guest", Nickname: "ゲスト", IsGuest: true},
+		"member": {ID: "member", Nickname: "本人", Email: "member@example.com"},
+	}}
+	u := NewTOTPUsecase(totpRepo, &memoryRecoveryCodeRepo{codes: map[string]*auth.RecoveryCode{}}, userRepo, memoryMFAChallengeStore{}, "").(*TOTPUsecase)
+	u.now = func() time.Time { return *now }
+	return u, totpRepo
+}
+
+// enrollTOTP は二段階認証を有効にし、共有鍵とリカバリーコードを返す
+func enrollTOTP(t *testing.T, u *TOTPUsecase, repo *memoryTOTPRepo, now *time.Time) ([]byte, []string) {
+	t.Helper()
+	ctx := context.Background()
+	enrollment, err := u.Enroll(ctx, "member")
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/Tofunote:member@example.com?")
+	secret := repo.factors["member"].Secret
+	assert.Equal(t, auth.EncodeTOTPSecret(secret), enrollment.Secret)
+
+	codes, err := u.Activate(ctx, "member", auth.TOTPCode(secret, auth.TOTPStep(*now)))
+	require.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	*now = now.Add(auth.TOTPPeriod)
+	return secret, codes
+}
+
+func TestTOTPUsecase_EnrollAndLogin(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	u, repo := newTOTPTestUsecase(&now)
+
+	t.Run("異常系: ゲストは設定できない", func(t *testing.T) {
+		_, err := u.Enroll(ctx, "guest")
+		assert.ErrorIs(t, err, ErrTOTPRequiresAccount)
+	})
+
+	t.Run("有効にする前はログインを保留しない", func(t *testing.T) {
+		_, err := u.Enroll(ctx, "member")
+		require.NoError(t, err)
+		token, err := u.BeginLogin(ctx, &user.User{ID: "member"})
+		require.NoError(t, err)
+		assert.Empty(t, token)
+	})
+
+	t.Run("異常系: 誤ったコードでは有効にならない", func(t *testing.T) {
+		_, err := u.Activate(ctx, "member", "000000")
+		assert.ErrorIs(t, err, auth.ErrInvalidTOTPCode)
+		status, err := u.Status(ctx, "member")
+		require.NoError(t, err)
+		assert.False(t, status.TOTPEnabled)
+	})
+
+	secret, codes := enrollTOTP(t, u, repo, &now)
+	status, err := u.Status(ctx, "member")
+	require.NoError(t, err)
+	assert.Equal(t, &MFAStatus{TOTPEnabled: true, RecoveryCodesRemaining: recoveryCodeCount}, status)
+
+	_, err = u.Enroll(ctx, "member")
+	assert.ErrorIs(t, err, auth.ErrTOTPAlreadyEnabled)
+
+	token, err := u.BeginLogin(ctx, &user.User{ID: "member"})
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	code := auth.TOTPCode(secret, auth.TOTPStep(now))
+	account, err := u.FinishLogin(ctx, token, code)
+	require.NoError(t, err)
+	assert.Equal(t, "member", account.ID)
+
+	t.Run("異常系: 同じコードは再利用できない", func(t *testing.T) {
+		_, err := u.FinishLogin(ctx, token, code)
+		assert.ErrorIs(t, err, auth.ErrInvalidTOTPCode)
+	})
+
+	t.Run("リカバリーコードは一度だけ使える", func(t *testing.T) {
+		account, err := u.FinishLogin(ctx, token, codes[0])
+		require.NoError(t, err)
+		assert.Equal(t, "member", account.ID)
+		_, err = u.FinishLogin(ctx, token, codes[0])
+		assert.ErrorIs(t, err, auth.ErrInvalidTOTPCode)
+		status, err := u.Status(ctx, "member")
+		require.NoError(t, err)
+		assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+	})
+
+	t.Run("異常系: 不正な確認トークン", func(t *testing.T) {
+		_, err := u.FinishLogin(ctx, "forged", code)
+		assert.ErrorIs(t, err, auth.ErrInvalidMFAChallenge)
+	})
+}
+
+func TestTOTPUsecase_Lockout(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	u, repo := newTOTPTestUsecase(&now)
+	secret, _ := enrollTOTP(t, u, repo, &now)
+	token, err := u.BeginLogin(ctx, &user.User{ID: "member"})
+	require.NoError(t, err)
+
+	for i := 0; i < maxTOTPFailedAttempts; i++ {
+		_, err := u.FinishLogin(ctx, token, "000000")
+		assert.ErrorIs(t, err, auth.ErrInvalidTOTPCode)
+	}
+	_, err = u.FinishLogin(ctx, token, auth.TOTPCode(secret, auth.TOTPStep(now)))
+	assert.ErrorIs(t, err, auth.ErrTOTPLocked, "ロック中は正しいコードも受け付けない")
+
+	now = now.Add(totpLockDuration)
+	_, err = u.FinishLogin(ctx, token, auth.TOTPCode(secret, auth.TOTPStep(now)))
+	assert.NoError(t, err)
+}
+
+func TestTOTPUsecase_DisableAndRegenerate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	u, repo := newTOTPTestUsecase(&now)
+	secret, codes := enrollTOTP(t, u, repo, &now)
+
+	t.Run("異常系: リカバリーコードでは作り直せない", func(t *testing.T) {
+		_, err := u.RegenerateRecoveryCodes(ctx, "member", codes[0])
+		assert.ErrorIs(t, err, auth.ErrInvalidTOTPCode)
+	})
+
+	regenerated, err := u.RegenerateRecoveryCodes(ctx, "member", auth.TOTPCode(secret, auth.TOTPStep(now)))
+	require.NoError(t, err)
+	assert.NotEqual(t, codes, regenerated)
+
+	require.NoError(t, u.Disable(ctx, "member", regenerated[0]))
+	status, err := u.Status(ctx, "member")
+	require.NoError(t, err)
+	assert.False(t, status.TOTPEnabled)
+	assert.ErrorIs(t, u.Disable(ctx, "member", "000000"), auth.ErrTOTPNotEnabled)
+}
+
+func TestTOTPUsecase_Verify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	u, repo := newTOTPTestUsecase(&now)
+
+	assert.ErrorIs(t, u.Verify(ctx, "member", "123456"), auth.ErrTOTPNotEnabled)
+
+	secret, codes := enrollTOTP(t, u, repo, &now)
+	assert.ErrorIs(t, u.Verify(ctx, "member", "000000"), auth.ErrInvalidTOTPCode)
+	assert.NoError(t, u.Verify(ctx, "member", auth.TOTPCode(secret, auth.TOTPStep(now))))
+	// リカバリーコードでも確認できる
+	assert.NoError(t, u.Verify(ctx, "member", codes[0]))
+}
@@ -2,31 +2,200 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"log"
+	"time"
+	"tofunote-backend/domain/audit"
 	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/transaction"
 	"tofunote-backend/domain/user"
 )
 
+var (
+	ErrNotWithdrawn = errors.New("退会の申請はありません")
+	// ErrWithdrawalExpired は猶予期間を過ぎた退会の申請を取り消そうとした場合のエラー（完全な削除を待つのみ）
+	ErrWithdrawalExpired = errors.New("退会の猶予期間を過ぎているため取り消せません")
+)
+
+const (
+	// WithdrawalGracePeriod は退会を申請してから完全に削除するまでの猶予（この間はログインして取り消せる）
+	WithdrawalGracePeriod = 30 * 24 * time.Hour
+	// withdrawalPurgeBatchSize は1回の実行で完全に削除するユーザーの上限
+	withdrawalPurgeBatchSize = 500
+)
+
+// UserDataDeleter はユーザーに紐づくデータを一括削除できるリポジトリ
+type UserDataDeleter interface {
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// Withdrawal は退会の申請の結果
+type Withdrawal struct {
+	// Purged は猶予期間を置かずに削除したことを表す（ゲストは再びログインできないため即時に削除する）
+	Purged bool
+	// PurgeScheduledAt は完全に削除する予定日時（Purgedの場合はnil）
+	PurgeScheduledAt *time.Time
+}
+
+// WithdrawalPurgeReport は猶予期間を過ぎたユーザーの削除の実行結果（ドライランの場合は削除する予定のユーザー）
+type WithdrawalPurgeReport struct {
+	DryRun bool
+	Purged []string
+	// Failed は削除に失敗したユーザーのID（次回の実行で再度削除する）
+	Failed []string
+}
+
 type UserWithdrawUsecase struct {
+	UnitOfWork      transaction.UnitOfWork
 	UserRepository  user.Repository
 	DiaryRepository diary.DiaryRepository
+	// Accounts は猶予期間を過ぎたユーザーの検索に使う
+	Accounts user.RetentionRepository
+	// Sessions は退会の申請時に全端末からログアウトさせるために使う
+	Sessions ISessionUsecase
+	// PersonalAccessTokens は退会の申請時にアクセストークンを取り消すために使う
+	PersonalAccessTokens UserDataDeleter
+	// Audits は退会の申請・取り消し・完全な削除を監査ログに記録する（nilの場合は記録しない）
+	Audits IAuditUsecase
+	// 日記以外にユーザーに紐づくデータ（感情ラベル等）のリポジトリ
+	RelatedRepositories []UserDataDeleter
+	now                 func() time.Time
 }
 
-func NewUserWithdrawUsecase(userRepo user.Repository, diaryRepo diary.DiaryRepository) *UserWithdrawUsecase {
+func NewUserWithdrawUsecase(unitOfWork transaction.UnitOfWork, userRepo user.Repository, accounts user.RetentionRepository, sessions ISessionUsecase, personalAccessTokens UserDataDeleter, audits IAuditUsecase, diaryRepo diary.DiaryRepository, relatedRepos ...UserDataDeleter) *UserWithdrawUsecase {
 	return &UserWithdrawUsecase{
-		UserRepository:  userRepo,
-		DiaryRepository: diaryRepo,
+		UnitOfWork:           unitOfWork,
+		UserRepository:       userRepo,
+		DiaryRepository:      diaryRepo,
+		Accounts:             accounts,
+		Sessions:             sessions,
+		PersonalAccessTokens: personalAccessTokens,
+		Audits:               audits,
+		RelatedRepositories:  relatedRepos,
+		now:                  time.Now,
+	}
+}
+
+// Withdraw: 退会を申請し、猶予期間の後に完全に削除する（ゲストは即時に削除する）。申請済みの場合は以前の申請のまま
+func (u *UserWithdrawUsecase) Withdraw(ctx context.Context, userID string) (*Withdrawal, error) {
+	account, err := u.UserRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
+	if account == nil {
+		return nil, ErrUserNotFound
+	}
+	if account.IsGuest {
+		if err := u.Purge(ctx, userID); err != nil {
+			return nil, err
+		}
+		recordAudit(ctx, u.Audits, audit.Event{ActorUserID: userID, Action: audit.ActionWithdraw, TargetUserID: userID, Detail: "guest"})
+		return &Withdrawal{Purged: true}, nil
+	}
+	if !account.IsWithdrawn() {
+		now := u.now()
+		account.WithdrawnAt = &now
+		err := u.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			if err := u.UserRepository.Update(ctx, account); err != nil {
+				return err
+			}
+			if err := u.Sessions.RevokeAll(ctx, userID); err != nil {
+				return err
+			}
+			// アクセストークンは取り消しの状態を持たないため削除する（退会を取り消した場合は再度発行する）
+			return u.PersonalAccessTokens.DeleteByUserID(ctx, userID)
+		})
+		if err != nil {
+			return nil, err
+		}
+		recordAudit(ctx, u.Audits, audit.Event{ActorUserID: userID, Action: audit.ActionWithdraw, TargetUserID: userID})
+	}
+	return &Withdrawal{PurgeScheduledAt: u.PurgeScheduledAt(account)}, nil
 }
 
-// Withdraw: 指定ユーザーの全日記とアカウントを削除
-func (u *UserWithdrawUsecase) Withdraw(ctx context.Context, userID string) error {
-	// 1. 日記全削除
-	if err := u.DiaryRepository.DeleteByUserID(ctx, userID); err != nil {
+// Restore は猶予期間中の退会の申請を取り消す（猶予期間を過ぎた場合はErrWithdrawalExpired）。
+// 完全な削除と競合しないよう、猶予期間の確認と更新は1つのトランザクションで行う
+func (u *UserWithdrawUsecase) Restore(ctx context.Context, userID string) error {
+	err := u.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		account, err := u.UserRepository.FindByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return ErrUserNotFound
+		}
+		if !account.IsWithdrawn() {
+			return ErrNotWithdrawn
+		}
+		if !u.now().Before(*u.PurgeScheduledAt(account)) {
+			return ErrWithdrawalExpired
+		}
+		account.WithdrawnAt = nil
+		return u.UserRepository.Update(ctx, account)
+	})
+	if err != nil {
 		return err
 	}
-	// 2. ユーザー削除
-	if err := u.UserRepository.DeleteByID(ctx, userID); err != nil {
+	recordAudit(ctx, u.Audits, audit.Event{ActorUserID: userID, Action: audit.ActionWithdrawCancel, TargetUserID: userID})
+	return nil
+}
+
+// PurgeScheduledAt は退会を申請したユーザーを完全に削除する予定日時を返す（申請していない場合はnil）
+func (u *UserWithdrawUsecase) PurgeScheduledAt(account *user.User) *time.Time {
+	if !account.IsWithdrawn() {
+		return nil
+	}
+	scheduled := account.WithdrawnAt.Add(WithdrawalGracePeriod)
+	return &scheduled
+}
+
+// Purge: 指定ユーザーの全日記・関連データとアカウントを1つのトランザクションで削除する（途中で失敗した場合は何も削除しない）
+func (u *UserWithdrawUsecase) Purge(ctx context.Context, userID string) error {
+	err := u.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		// 0. 発行済みのアクセストークンを拒否できるよう、セッションを失効させてから削除する
+		if err := u.Sessions.RevokeAll(ctx, userID); err != nil {
+			return err
+		}
+		// 1. 日記全削除
+		if err := u.DiaryRepository.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		// 2. 日記以外の関連データ削除
+		for _, repo := range u.RelatedRepositories {
+			if err := repo.DeleteByUserID(ctx, userID); err != nil {
+				return err
+			}
+		}
+		// 3. ユーザー削除
+		return u.UserRepository.DeleteByID(ctx, userID)
+	})
+	if err != nil {
 		return err
 	}
+	// 監査ログは削除したユーザーのIDのみ残し、ユーザーのデータとともには削除しない
+	recordAudit(ctx, u.Audits, audit.Event{Action: audit.ActionAccountPurge, TargetUserID: userID})
 	return nil
 }
+
+// PurgeExpired は猶予期間を過ぎたユーザーを完全に削除する（dryRunの場合は対象を返すのみ）。
+// 一部のユーザーの削除に失敗しても残りのユーザーの削除は続ける
+func (u *UserWithdrawUsecase) PurgeExpired(ctx context.Context, dryRun bool) (*WithdrawalPurgeReport, error) {
+	expired, err := u.Accounts.FindWithdrawnBefore(ctx, u.now().Add(-WithdrawalGracePeriod), withdrawalPurgeBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	report := &WithdrawalPurgeReport{DryRun: dryRun, Purged: []string{}, Failed: []string{}}
+	for _, account := range expired {
+		if dryRun {
+			report.Purged = append(report.Purged, account.ID)
+			continue
+		}
+		if err := u.Purge(ctx, account.ID); err != nil {
+			log.Printf("[ERROR] WithdrawalPurge: ユーザーの削除に失敗しました user_id=%s: %v", account.ID, err)
+			report.Failed = append(report.Failed, account.ID)
+			continue
+		}
+		report.Purged = append(report.Purged, account.ID)
+	}
+	return report, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
+	"tofunote-backend/domain/auth"
 	"tofunote-backend/domain/diary"
 	"tofunote-backend/domain/user"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockDiaryRepo struct {
@@ -21,17 +24,17 @@ func (m *mockDiaryRepo) FindAll(ctx context.Context) ([]diary.Diary, error) { re
 func (m *mockDiaryRepo) FindByUserID(ctx context.Context, userID string) ([]diary.Diary, error) {
 	return nil, nil
 }
-func (m *mockDiaryRepo) FindByUserIDAndDate(ctx context.Context, userID, date string) (*diary.Diary, error) {
+func (m *mockDiaryRepo) FindByUserIDAndDate(ctx context.Context, userID string, date diary.Date) (*diary.Diary, error) {
 	return nil, nil
 }
-func (m *mockDiaryRepo) FindByUserIDAndDateRange(ctx context.Context, userID, startDate, endDate string) ([]diary.Diary, error) {
+func (m *mockDiaryRepo) FindByUserIDAndDateRange(ctx context.Context, userID string, startDate, endDate diary.Date) ([]diary.Diary, error) {
 	return nil, nil
 }
 func (m *mockDiaryRepo) Create(ctx context.Context, d *diary.Diary) error { return nil }
-func (m *mockDiaryRepo) Update(ctx context.Context, userID, date string, diary *diary.Diary) error {
+func (m *mockDiaryRepo) Update(ctx context.Context, userID string, date diary.Date, diary *diary.Diary) error {
 	return nil
 }
-func (m *mockDiaryRepo) Delete(ctx context.Context, userID, date string) error { return nil }
+func (m *mockDiaryRepo) Delete(ctx context.Context, userID string, date diary.Date) error { return nil }
 
 // 他のIDiaryRepositoryメソッドは未使用なので省略
 
@@ -45,7 +48,7 @@ func (m *mockUserRepo) DeleteByID(ctx context.Context, id string) error {
 func (m *mockUserRepo) FindByProviderId(ctx context.Context, provider, providerId string) (*user.User, error) {
 	return nil, nil
 }
-func (m *mockUserRepo) FindByRefreshToken(ctx context.Context, refreshToken string) (*user.User, error) {
+func (m *mockUserRepo) FindByEmail(ctx context.Context, email string) (*user.User, error) {
 	return nil, nil
 }
 func (m *mockUserRepo) Create(ctx context.Context, u *user.User) error              { return nil }
@@ -54,7 +57,17 @@ func (m *mockUserRepo) Update(ctx context.Context, u *user.User) error
 
 // 他のuser.Repositoryメソッドは未使用なので省略
 
-func TestUserWithdrawUsecase_Withdraw(t *testing.T) {
+// directUnitOfWork はトランザクションを使わずにfnを実行するUnitOfWork（呼び出し回数を記録する）
+type directUnitOfWork struct {
+	calls int
+}
+
+func (u *directUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	u.calls++
+	return fn(ctx)
+}
+
+func TestUserWithdrawUsecase_Purge(t *testing.T) {
 	tests := []struct {
 		name              string
 		diaryErr          error
@@ -88,8 +101,12 @@ func TestUserWithdrawUsecase_Withdraw(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			diaryRepo := &mockDiaryRepo{deleteByUserIDErr: tt.diaryErr}
 			userRepo := &mockUserRepo{deleteByIDErr: tt.userErr}
-			usecase := NewUserWithdrawUsecase(userRepo, diaryRepo)
-			err := usecase.Withdraw(context.Background(), "test-user")
+			uow := &directUnitOfWork{}
+			sessionUsecase, _, _ := newSessionTestUsecase()
+			usecase := NewUserWithdrawUsecase(uow, userRepo, nil, sessionUsecase, nil, nil, diaryRepo)
+			err := usecase.Purge(context.Background(), "test-user")
+			// 途中で失敗した場合に取り消せるよう、削除は全て1つのUnitOfWorkの中で行う
+			assert.Equal(t, 1, uow.calls)
 			if tt.expectError {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectErrorString, err.Error())
@@ -99,3 +116,105 @@ func TestUserWithdrawUsecase_Withdraw(t *testing.T) {
 		})
 	}
 }
+
+// withdrawUserRepo は削除したユーザーをmemoryUserRepoから取り除く
+type withdrawUserRepo struct {
+	memoryUserRepo
+}
+
+func (m *withdrawUserRepo) DeleteByID(ctx context.Context, id string) error {
+	delete(m.users, id)
+	return nil
+}
+
+func newWithdrawTestUsecase(now *time.Time) (*UserWithdrawUsecase, *withdrawUserRepo, *memorySessionRepo) {
+	users := &withdrawUserRepo{memoryUserRepo{users: map[string]*user.User{
+		"member": {ID: "member", Email: "member@example.com"},
+		"guest":  {ID: "guest", IsGuest: true},
+	}}}
+	sessionUsecase, sessions, _ := newSessionTestUsecase()
+	sessionUsecase.now = func() time.Time { return *now }
+	tokens := &memoryPersonalAccessTokenRepo{tokens: map[string]*auth.PersonalAccessToken{}}
+	u := NewUserWithdrawUsecase(&directUnitOfWork{}, users, &memoryRetentionRepo{users: users.users}, sessionUsecase, tokens, nil, &mockDiaryRepo{})
+	u.now = func() time.Time { return *now }
+	return u, users, sessions
+}
+
+func TestUserWithdrawUsecase_WithdrawAndRestore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	u, users, sessions := newWithdrawTestUsecase(&now)
+	session, err := u.Sessions.Start(ctx, "member", auth.ClientInfo{}, now.Add(time.Hour))
+	require.NoError(t, err)
+	tokens := u.PersonalAccessTokens.(*memoryPersonalAccessTokenRepo)
+	require.NoError(t, tokens.Create(ctx, &auth.PersonalAccessToken{UserID: "member", TokenHash: "hash-of-token"}))
+
+	t.Run("ゲストは即時に削除する", func(t *testing.T) {
+		guestSession, err := u.Sessions.Start(ctx, "guest", auth.ClientInfo{}, now.Add(time.Hour))
+		require.NoError(t, err)
+		withdrawal, err := u.Withdraw(ctx, "guest")
+		require.NoError(t, err)
+		assert.True(t, withdrawal.Purged)
+		assert.NotContains(t, users.users, "guest")
+		// 削除したゲストのアクセストークンは有効期限まで使えないようにする
+		revoked, err := u.Sessions.IsRevoked(ctx, guestSession.ID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	withdrawal, err := u.Withdraw(ctx, "member")
+	require.NoError(t, err)
+	assert.False(t, withdrawal.Purged)
+	assert.Equal(t, now.Add(WithdrawalGracePeriod), *withdrawal.PurgeScheduledAt)
+	assert.True(t, users.users["member"].IsWithdrawn())
+	assert.NotNil(t, sessions.sessions[session.ID].RevokedAt, "全端末からログアウトさせる")
+	assert.Empty(t, tokens.tokens, "アクセストークンを取り消す")
+
+	t.Run("申請済みの場合は以前の申請のまま", func(t *testing.T) {
+		later := now
+		now = now.Add(time.Hour)
+		defer func() { now = later }()
+		again, err := u.Withdraw(ctx, "member")
+		require.NoError(t, err)
+		assert.Equal(t, *withdrawal.PurgeScheduledAt, *again.PurgeScheduledAt)
+	})
+
+	require.NoError(t, u.Restore(ctx, "member"))
+	assert.False(t, users.users["member"].IsWithdrawn())
+	assert.ErrorIs(t, u.Restore(ctx, "member"), ErrNotWithdrawn)
+	assert.ErrorIs(t, u.Restore(ctx, "unknown"), ErrUserNotFound)
+
+	t.Run("猶予期間を過ぎた申請は取り消せない", func(t *testing.T) {
+		_, err := u.Withdraw(ctx, "member")
+		require.NoError(t, err)
+		later := now
+		now = now.Add(WithdrawalGracePeriod)
+		defer func() { now = later }()
+		assert.ErrorIs(t, u.Restore(ctx, "member"), ErrWithdrawalExpired)
+		assert.True(t, users.users["member"].IsWithdrawn())
+	})
+}
+
+func TestUserWithdrawUsecase_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	u, users, _ := newWithdrawTestUsecase(&now)
+	_, err := u.Withdraw(ctx, "member")
+	require.NoError(t, err)
+
+	now = now.Add(WithdrawalGracePeriod - time.Second)
+	report, err := u.PurgeExpired(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Purged, "猶予期間中は削除しない")
+
+	now = now.Add(2 * time.Second)
+	report, err = u.PurgeExpired(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"member"}, report.Purged)
+	assert.Contains(t, users.users, "member", "ドライランでは削除しない")
+
+	report, err = u.PurgeExpired(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"member"}, report.Purged)
+	assert.NotContains(t, users.users, "member")
+}
@@ -0,0 +1,272 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"tofunote-backend/domain/webhook"
+	"unicode/utf8"
+
+	"github.com/cmackenzie1/go-uuid"
+)
+
+var (
+	ErrInvalidWebhookDescription = errors.New("Webhookの説明は100文字以内で入力してください")
+	ErrTooManyWebhooks           = errors.New("登録できるWebhookの上限に達しています。不要なWebhookを削除してください")
+)
+
+const (
+	maxWebhooks              = 10
+	maxWebhookDescription    = 100
+	defaultWebhookDeliveries = 50
+	maxWebhookDeliveries     = 200
+	// webhookDeliveryLease は配送を送信中として他のジョブに取得させない時間（送信のタイムアウトより長くする）
+	webhookDeliveryLease = 5 * time.Minute
+	// webhookDeliveryRetention は送信を終えた配送の記録を残す期間
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+	webhookSecretPrefix      = "whsec_"
+)
+
+// IWebhookPublisher は日記の作成などのイベントを、購読しているWebhookの配送キューに追加する
+type IWebhookPublisher interface {
+	Publish(ctx context.Context, userID string, event webhook.EventType, data interface{}) error
+}
+
+type IWebhookUsecase interface {
+	IWebhookPublisher
+	// Create はWebhookを登録する（署名の鍵を平文で返すのはこの1回のみ）
+	Create(ctx context.Context, userID, url string, events []webhook.EventType, description string) (*webhook.Endpoint, error)
+	List(ctx context.Context, userID string) ([]webhook.Endpoint, error)
+	Delete(ctx context.Context, userID, id string) error
+	// ListDeliveries は配送の記録を新しい順に返す
+	ListDeliveries(ctx context.Context, userID, endpointID string, limit, offset int) ([]*webhook.Delivery, error)
+	// Ping は受信側の確認のためにpingイベントをその場で1回だけ送り、結果を返す
+	Ping(ctx context.Context, userID, endpointID string) (*webhook.Delivery, error)
+	// DeliverDue は送信日時を迎えた配送を送り、送信に成功した件数を返す（失敗した配送は指数バックオフで再送する）
+	DeliverDue(ctx context.Context, limit int) (int, error)
+	// PruneDeliveries は送信を終えてから保存期間を過ぎた配送の記録を削除する
+	PruneDeliveries(ctx context.Context) (int64, error)
+}
+
+type WebhookUsecase struct {
+	endpoints  webhook.EndpointRepository
+	deliveries webhook.DeliveryRepository
+	transport  webhook.Transport
+	now        func() time.Time
+}
+
+func NewWebhookUsecase(endpoints webhook.EndpointRepository, deliveries webhook.DeliveryRepository, transport webhook.Transport) IWebhookUsecase {
+	return &WebhookUsecase{endpoints: endpoints, deliveries: deliveries, transport: transport, now: time.Now}
+}
+
+// webhookEvent は受信側に送るJSON
+type webhookEvent struct {
+	ID        string            `json:"id"`
+	Type      webhook.EventType `json:"type"`
+	CreatedAt time.Time         `json:"created_at"`
+	Data      interface{}       `json:"data"`
+}
+
+func (u *WebhookUsecase) Create(ctx context.Context, userID, url string, events []webhook.EventType, description string) (*webhook.Endpoint, error) {
+	if err := webhook.ValidateURL(url); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, webhook.ErrInvalidEventType
+	}
+	description = strings.TrimSpace(description)
+	if utf8.RuneCountInString(description) > maxWebhookDescription {
+		return nil, ErrInvalidWebhookDescription
+	}
+	existing, err := u.endpoints.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhooks {
+		return nil, ErrTooManyWebhooks
+	}
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	endpoint := &webhook.Endpoint{
+		UserID:      userID,
+		URL:         url,
+		Secret:      webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(secret),
+		Events:      events,
+		Description: description,
+	}
+	if err := u.endpoints.Create(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (u *WebhookUsecase) List(ctx context.Context, userID string) ([]webhook.Endpoint, error) {
+	return u.endpoints.FindByUserID(ctx, userID)
+}
+
+func (u *WebhookUsecase) Delete(ctx context.Context, userID, id string) error {
+	return u.endpoints.Delete(ctx, userID, id)
+}
+
+func (u *WebhookUsecase) ListDeliveries(ctx context.Context, userID, endpointID string, limit, offset int) ([]*webhook.Delivery, error) {
+	if _, err := u.endpoints.FindByID(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultWebhookDeliveries
+	}
+	if limit > maxWebhookDeliveries {
+		limit = maxWebhookDeliveries
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return u.deliveries.FindByEndpoint(ctx, userID, endpointID, limit, offset)
+}
+
+// Publish は購読している全ての送信先に同じイベントIDで配送を追加する（送信はDeliverDueで行う）
+func (u *WebhookUsecase) Publish(ctx context.Context, userID string, event webhook.EventType, data interface{}) error {
+	endpoints, err := u.endpoints.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var subscribed []webhook.Endpoint
+	for _, e := range endpoints {
+		if e.Subscribes(event) {
+			subscribed = append(subscribed, e)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+	eventID, payload, err := u.newEvent(event, data)
+	if err != nil {
+		return err
+	}
+	now := u.now()
+	deliveries := make([]*webhook.Delivery, len(subscribed))
+	for i, e := range subscribed {
+		deliveries[i] = &webhook.Delivery{
+			EndpointID:    e.ID,
+			UserID:        userID,
+			EventID:       eventID,
+			EventType:     event,
+			Payload:       payload,
+			Status:        webhook.DeliveryPending,
+			NextAttemptAt: now,
+		}
+	}
+	return u.deliveries.Enqueue(ctx, deliveries)
+}
+
+func (u *WebhookUsecase) Ping(ctx context.Context, userID, endpointID string) (*webhook.Delivery, error) {
+	endpoint, err := u.endpoints.FindByID(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	eventID, payload, err := u.newEvent(webhook.EventPing, map[string]string{"message": "TOFU NOTEからのテスト送信です"})
+	if err != nil {
+		return nil, err
+	}
+	delivery := &webhook.Delivery{
+		EndpointID:    endpoint.ID,
+		UserID:        userID,
+		EventID:       eventID,
+		EventType:     webhook.EventPing,
+		Payload:       payload,
+		Status:        webhook.DeliveryPending,
+		NextAttemptAt: u.now().Add(webhookDeliveryLease),
+	}
+	if err := u.deliveries.Enqueue(ctx, []*webhook.Delivery{delivery}); err != nil {
+		return nil, err
+	}
+	u.send(ctx, endpoint, delivery)
+	// 確認のための送信は再送しない
+	if delivery.Status == webhook.DeliveryPending {
+		delivery.Status = webhook.DeliveryFailed
+	}
+	if err := u.deliveries.Update(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (u *WebhookUsecase) DeliverDue(ctx context.Context, limit int) (int, error) {
+	now := u.now()
+	due, err := u.deliveries.ClaimDue(ctx, now, now.Add(webhookDeliveryLease), limit)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, d := range due {
+		endpoint, err := u.endpoints.FindByID(ctx, d.UserID, d.EndpointID)
+		if errors.Is(err, webhook.ErrEndpointNotFound) {
+			d.Status = webhook.DeliveryFailed
+			d.LastError = err.Error()
+		} else if err != nil {
+			// 送信先を取得できない場合は、取得した配送を lease の後に再び送る
+			log.Printf("[ERROR] WebhookDelivery: 送信先の取得に失敗しました delivery_id=%s: %v", d.ID, err)
+			continue
+		} else {
+			u.send(ctx, endpoint, d)
+		}
+		if err := u.deliveries.Update(ctx, d); err != nil {
+			log.Printf("[ERROR] WebhookDelivery: 配送の結果の保存に失敗しました delivery_id=%s: %v", d.ID, err)
+			continue
+		}
+		if d.Status == webhook.DeliverySucceeded {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (u *WebhookUsecase) PruneDeliveries(ctx context.Context) (int64, error) {
+	return u.deliveries.DeleteFinishedBefore(ctx, u.now().Add(-webhookDeliveryRetention))
+}
+
+// send は署名したペイロードを送り、結果を配送に記録する
+func (u *WebhookUsecase) send(ctx context.Context, endpoint *webhook.Endpoint, d *webhook.Delivery) {
+	now := u.now()
+	status, err := u.transport.Post(ctx, webhook.Request{
+		URL: endpoint.URL,
+		Headers: map[string]string{
+			"Content-Type":          "application/json",
+			"User-Agent":            "TOFU-NOTE-Webhook/1.0",
+			webhook.SignatureHeader: webhook.Sign(endpoint.Secret, now, d.Payload),
+			webhook.EventHeader:     string(d.EventType),
+			webhook.DeliveryHeader:  d.EventID,
+		},
+		Body: d.Payload,
+	})
+	d.RecordAttempt(now, status, err)
+}
+
+func (u *WebhookUsecase) newEvent(event webhook.EventType, data interface{}) (string, []byte, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", nil, err
+	}
+	payload, err := json.Marshal(webhookEvent{ID: id.String(), Type: event, CreatedAt: u.now().UTC(), Data: data})
+	if err != nil {
+		return "", nil, err
+	}
+	return id.String(), payload, nil
+}
+
+// publishWebhook はイベントを配送キューに追加する。追加に失敗しても元の操作は失敗させず、エラーログを出力する
+func publishWebhook(ctx context.Context, webhooks IWebhookPublisher, userID string, event webhook.EventType, data interface{}) {
+	if webhooks == nil {
+		return
+	}
+	if err := webhooks.Publish(ctx, userID, event, data); err != nil {
+		log.Printf("[ERROR] Webhook: イベントの配送キューへの追加に失敗しました user_id=%s event=%s: %v", userID, event, err)
+	}
+}
@@ -0,0 +1,297 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+	"tofunote-backend/domain/diary"
+	"tofunote-backend/domain/webhook"
+	webhooktransport "tofunote-backend/infra/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryWebhookEndpointRepo struct {
+	endpoints []webhook.Endpoint
+}
+
+func (m *memoryWebhookEndpointRepo) Create(ctx context.Context, e *webhook.Endpoint) error {
+	e.ID = fmt.Sprintf("ep%d", len(m.endpoints)+1)
+	m.endpoints = append(m.endpoints, *e)
+	return nil
+}
+
+func (m *memoryWebhookEndpointRepo) FindByUserID(ctx context.Context, userID string) ([]webhook.Endpoint, error) {
+	var found []webhook.Endpoint
+	for _, e := range m.endpoints {
+		if e.UserID == userID {
+			found = append(found, e)
+		}
+	}
+	return found, nil
+}
+
+func (m *memoryWebhookEndpointRepo) FindByID(ctx context.Context, userID, id string) (*webhook.Endpoint, error) {
+	for _, e := range m.endpoints {
+		if e.ID == id && e.UserID == userID {
+			return &e, nil
+		}
+	}
+	return nil, webhook.ErrEndpointNotFound
+}
+
+func (m *memoryWebhookEndpointRepo) Delete(ctx context.Context, userID, id string) error {
+	for i, e := range m.endpoints {
+		if e.ID == id && e.UserID == userID {
+			m.endpoints = append(m.endpoints[:i], m.endpoints[i+1:]...)
+			return nil
+		}
+	}
+	return webhook.ErrEndpointNotFound
+}
+
+func (m *memoryWebhookEndpointRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	return nil
+}
+
+type memoryWebhookDeliveryRepo struct {
+	deliveries []*webhook.Delivery
+}
+
+func (m *memoryWebhookDeliveryRepo) Enqueue(ctx context.Context, deliveries []*webhook.Delivery) error {
+	for _, d := range deliveries {
+		d.ID = fmt.Sprintf("d%d", len(m.deliveries)+1)
+		copied := *d
+		m.deliveries = append(m.deliveries, &copied)
+	}
+	return nil
+}
+
+func (m *memoryWebhookDeliveryRepo) ClaimDue(ctx context.Context, now, lease time.Time, limit int) ([]*webhook.Delivery, error) {
+	var due []*webhook.Delivery
+	for _, d := range m.deliveries {
+		if d.Status == webhook.DeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			d.NextAttemptAt = lease
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryWebhookDeliveryRepo) Update(ctx context.Context, delivery *webhook.Delivery) error {
+	for i, d := range m.deliveries {
+		if d.ID == delivery.ID {
+			copied := *delivery
+			m.deliveries[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *memoryWebhookDeliveryRepo) FindByEndpoint(ctx context.Context, userID, endpointID string, limit, offset int) ([]*webhook.Delivery, error) {
+	var found []*webhook.Delivery
+	for _, d := range m.deliveries {
+		if d.EndpointID == endpointID && d.UserID == userID {
+			found = append(found, d)
+		}
+	}
+	return found, nil
+}
+
+func (m *memoryWebhookDeliveryRepo) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *memoryWebhookDeliveryRepo) DeleteByUserID(ctx context.Context, userID string) error {
+	return nil
+}
+
+// webhookReceiver は受け取ったリクエストを記録し、statuses の順に応答するhttptestの受信側
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	received []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	r := &webhookReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.received = append(r.received, receivedWebhook{header: req.Header.Clone(), body: body})
+		status := http.StatusNoContent
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func newTestWebhookUsecase(receiver *webhookReceiver) (*WebhookUsecase, *memoryWebhookEndpointRepo, *memoryWebhookDeliveryRepo) {
+	endpoints := &memoryWebhookEndpointRepo{}
+	deliveries := &memoryWebhookDeliveryRepo{}
+	uc := NewWebhookUsecase(endpoints, deliveries, webhooktransport.NewHTTPTransport(receiver.Client())).(*WebhookUsecase)
+	return uc, endpoints, deliveries
+}
+
+func TestWebhookUsecase_PublishAndDeliver(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	uc, endpoints, _ := newTestWebhookUsecase(receiver)
+	ctx := context.Background()
+	// httptestの受信側はhttpのため、登録の検証を通さずに直接保存する
+	endpoints.endpoints = []webhook.Endpoint{
+		{ID: "ep1", UserID: "u1", URL: receiver.URL, Secret: "whsec_1", Events: []webhook.EventType{webhook.EventDiaryCreated}},
+		{ID: "ep2", UserID: "u1", URL: receiver.URL, Secret: "whsec_2", Events: []webhook.EventType{webhook.EventDiaryDeleted}},
+		{ID: "ep3", UserID: "u2", URL: receiver.URL, Secret: "whsec_3", Events: []webhook.EventType{webhook.EventDiaryCreated}},
+	}
+
+	diaryUsecase := NewDiaryUsecase(&mockDiaryRepository{}, nil, nil, uc)
+	require.NoError(t, diaryUsecase.Create(ctx, &diary.Diary{UserID: "u1", Date: "2025-06-01", Mental: 7, Diary: "散歩した"}))
+
+	delivered, err := uc.DeliverDue(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	require.Len(t, receiver.received, 1)
+
+	got := receiver.received[0]
+	assert.Equal(t, "diary.created", got.header.Get(webhook.EventHeader))
+	assert.True(t, webhook.Verify("whsec_1", got.header.Get(webhook.SignatureHeader), got.body, time.Now(), 5*time.Minute))
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Date   string `json:"date"`
+			Mental int    `json:"mental"`
+			Diary  string `json:"diary"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(got.body, &event))
+	assert.Equal(t, got.header.Get(webhook.DeliveryHeader), event.ID)
+	assert.Equal(t, "diary.created", event.Type)
+	assert.Equal(t, "2025-06-01", event.Data.Date)
+	assert.Equal(t, 7, event.Data.Mental)
+	assert.Equal(t, "散歩した", event.Data.Diary)
+
+	// 送信済みの配送は再び送らない
+	delivered, err = uc.DeliverDue(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, receiver.received, 1)
+}
+
+func TestWebhookUsecase_RetriesWithBackoff(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	uc, endpoints, deliveries := newTestWebhookUsecase(receiver)
+	ctx := context.Background()
+	endpoints.endpoints = []webhook.Endpoint{{ID: "ep1", UserID: "u1", URL: receiver.URL, Secret: "whsec_1", Events: []webhook.EventType{webhook.EventAnalysisCompleted}}}
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	require.NoError(t, uc.Publish(ctx, "u1", webhook.EventAnalysisCompleted, map[string]string{"result": "安定しています"}))
+
+	delivered, err := uc.DeliverDue(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	d := deliveries.deliveries[0]
+	assert.Equal(t, webhook.DeliveryPending, d.Status)
+	assert.Equal(t, 500, d.LastStatusCode)
+	assert.Equal(t, now.Add(30*time.Second), d.NextAttemptAt)
+
+	// 再送の日時を迎えるまでは送らない
+	now = now.Add(10 * time.Second)
+	delivered, err = uc.DeliverDue(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, receiver.received, 1)
+
+	now = now.Add(20 * time.Second)
+	_, err = uc.DeliverDue(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), deliveries.deliveries[0].NextAttemptAt)
+
+	now = now.Add(time.Minute)
+	delivered, err = uc.DeliverDue(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	d = deliveries.deliveries[0]
+	assert.Equal(t, webhook.DeliverySucceeded, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+
+	// 再送しても同じイベントID・ペイロードを送る
+	require.Len(t, receiver.received, 3)
+	assert.Equal(t, receiver.received[0].body, receiver.received[2].body)
+	assert.Equal(t, receiver.received[0].header.Get(webhook.DeliveryHeader), receiver.received[2].header.Get(webhook.DeliveryHeader))
+}
+
+func TestWebhookUsecase_Ping(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusOK, http.StatusNotFound)
+	uc, endpoints, deliveries := newTestWebhookUsecase(receiver)
+	ctx := context.Background()
+	endpoints.endpoints = []webhook.Endpoint{{ID: "ep1", UserID: "u1", URL: receiver.URL, Secret: "whsec_1", Events: []webhook.EventType{webhook.EventDiaryDeleted}}}
+
+	d, err := uc.Ping(ctx, "u1", "ep1")
+	require.NoError(t, err)
+	assert.Equal(t, webhook.DeliverySucceeded, d.Status)
+	assert.Equal(t, "ping", receiver.received[0].header.Get(webhook.EventHeader))
+
+	// 確認のための送信は失敗しても再送しない
+	d, err = uc.Ping(ctx, "u1", "ep1")
+	require.NoError(t, err)
+	assert.Equal(t, webhook.DeliveryFailed, d.Status)
+	assert.Equal(t, 404, d.LastStatusCode)
+	delivered, err := uc.DeliverDue(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, receiver.received, 2)
+
+	logs, err := uc.ListDeliveries(ctx, "u1", "ep1", 0, 0)
+	require.NoError(t, err)
+	statuses := []string{string(logs[0].Status), string(logs[1].Status)}
+	sort.Strings(statuses)
+	assert.Equal(t, []string{"failed", "succeeded"}, statuses)
+	assert.Len(t, deliveries.deliveries, 2)
+
+	_, err = uc.Ping(ctx, "u2", "ep1")
+	assert.ErrorIs(t, err, webhook.ErrEndpointNotFound)
+}
+
+func TestWebhookUsecase_Create(t *testing.T) {
+	uc := NewWebhookUsecase(&memoryWebhookEndpointRepo{}, &memoryWebhookDeliveryRepo{}, nil)
+	ctx := context.Background()
+	events := []webhook.EventType{webhook.EventDiaryCreated}
+
+	endpoint, err := uc.Create(ctx, "u1", "https://hooks.example.com/tofunote", events, " Slack ")
+	require.NoError(t, err)
+	assert.Regexp(t, `^whsec_[A-Za-z0-9_-]{32}$`, endpoint.Secret)
+	assert.Equal(t, "Slack", endpoint.Description)
+
+	_, err = uc.Create(ctx, "u1", "http://hooks.example.com/tofunote", events, "")
+	assert.ErrorIs(t, err, webhook.ErrInvalidURL)
+	_, err = uc.Create(ctx, "u1", "https://hooks.example.com/tofunote", nil, "")
+	assert.ErrorIs(t, err, webhook.ErrInvalidEventType)
+
+	for i := 1; i < maxWebhooks; i++ {
+		_, err := uc.Create(ctx, "u1", "https://hooks.example.com/tofunote", events, "")
+		require.NoError(t, err)
+	}
+	_, err = uc.Create(ctx, "u1", "https://hooks.example.com/tofunote", events, "")
+	assert.ErrorIs(t, err, ErrTooManyWebhooks)
+}